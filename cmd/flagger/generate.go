@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/lint"
+	"github.com/fluxcd/flagger/pkg/scaffold"
+)
+
+// runGenerate prints a canary manifest for a workload running in the cluster
+func runGenerate(args []string) int {
+	fs := flag.NewFlagSet("generate canary", flag.ExitOnError)
+	opts := scaffold.Options{}
+	var kubeconfig, masterURL, selectorLabels string
+	fs.StringVar(&opts.Target, "target", "", "Target workload in the kind/name format, e.g. deploy/podinfo.")
+	fs.StringVar(&opts.Namespace, "namespace", "default", "Namespace of the target workload.")
+	fs.StringVar(&opts.Provider, "provider", "", "Service mesh or ingress provider of the canary, defaults to the -mesh-provider of the controller.")
+	fs.StringVar(&selectorLabels, "selector-labels", "app,name,app.kubernetes.io/name", "List of pod labels that Flagger uses to create pod selectors.")
+	fs.StringVar(&kubeconfig, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to a kubeconfig.")
+	fs.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: flagger generate canary --target deploy/NAME [flags]\n")
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "canary" {
+		fs.Usage()
+		return 2
+	}
+	fs.Parse(args[1:])
+	if opts.Target == "" {
+		fs.Usage()
+		return 2
+	}
+	opts.SelectorLabels = strings.Split(selectorLabels, ",")
+
+	cfg, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error building kubeconfig: %v\n", err)
+		return 1
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error building kubernetes clientset: %v\n", err)
+		return 1
+	}
+
+	cd, warnings, err := scaffold.GenerateCanary(context.Background(), kubeClient, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating canary: %v\n", err)
+		return 1
+	}
+
+	// report the invalid options such as an unknown provider
+	manifests := &lint.Manifests{Canaries: []*flaggerv1.Canary{cd}}
+	for _, issue := range manifests.Lint() {
+		warnings = append(warnings, issue.String())
+	}
+
+	data, err := scaffold.Marshal(cd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating canary: %v\n", err)
+		return 1
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	fmt.Print(string(data))
+	return 0
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fluxcd/flagger/pkg/lint"
+)
+
+// runLint validates the Canary, MetricTemplate, AlertProvider and CanaryWave manifests
+// found in the files and directories given as arguments,
+// it returns a non-zero exit code when an issue is found
+func runLint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: flagger lint [FILE|DIR]...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var files []string
+	for _, path := range fs.Args() {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := strings.ToLower(filepath.Ext(file))
+			if !info.IsDir() && (file == path || ext == ".yaml" || ext == ".yml" || ext == ".json") {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+			return 2
+		}
+	}
+
+	manifests := &lint.Manifests{}
+	var issues []lint.Issue
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", file, err)
+			return 2
+		}
+		decodeIssues, err := manifests.Decode(file, f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error decoding %s: %v\n", file, err)
+			return 2
+		}
+		issues = append(issues, decodeIssues...)
+	}
+	issues = append(issues, manifests.Lint()...)
+
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		return 1
+	}
+	fmt.Printf("%d canaries, %d metric templates and %d alert providers are valid\n",
+		len(manifests.Canaries), len(manifests.MetricTemplates), len(manifests.AlertProviders))
+	return 0
+}
@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -30,6 +31,8 @@ import (
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/tools/cache"
@@ -40,16 +43,20 @@ import (
 	_ "k8s.io/code-generator/cmd/client-gen/generators"
 	"k8s.io/klog/v2"
 
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/fluxcd/flagger/pkg/canary"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 	informers "github.com/fluxcd/flagger/pkg/client/informers/externalversions"
 	"github.com/fluxcd/flagger/pkg/controller"
 	"github.com/fluxcd/flagger/pkg/logger"
 	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/mtls"
 	"github.com/fluxcd/flagger/pkg/notifier"
+	"github.com/fluxcd/flagger/pkg/report"
 	"github.com/fluxcd/flagger/pkg/router"
 	"github.com/fluxcd/flagger/pkg/server"
 	"github.com/fluxcd/flagger/pkg/signals"
+	"github.com/fluxcd/flagger/pkg/tracing"
 	"github.com/fluxcd/flagger/pkg/version"
 )
 
@@ -68,6 +75,7 @@ var (
 	slackUser                string
 	slackChannel             string
 	eventWebhook             string
+	eventSinks               string
 	threadiness              int
 	zapReplaceGlobals        bool
 	zapEncoding              string
@@ -81,6 +89,36 @@ var (
 	enableConfigTracking     bool
 	ver                      bool
 	kubeconfigServiceMesh    string
+	otlpEndpoint             string
+	otlpInsecure             bool
+	apiToken                 string
+	reportStore              string
+	reportFormat             string
+	reportRegistry           string
+	enableDebugEndpoints     bool
+	settingsConfigMap        string
+	analysisInterval         time.Duration
+	webhookTLSCertFile       string
+	webhookTLSKeyFile        string
+	webhookTLSCAFile         string
+	enabledProviders         string
+	enabledTargetKinds       string
+	includeNamespaces        string
+	excludeNamespaces        string
+	namespaceSelector        string
+	tenantSecretNamespaces   string
+	istioReporter            string
+	istioLabelMatchers       string
+	istioRequestsMetric      string
+	istioRequestDuration     string
+	defaultWebhooks          string
+	finalizerMaxAttempts     int
+	specExcludePaths         string
+	openCostURL              string
+	fluxEventsURL            string
+	statusHistoryLimit       int
+	crossNamespaceTargets    bool
+	primarySuffix            string
 )
 
 func init() {
@@ -96,13 +134,15 @@ func init() {
 	flag.StringVar(&slackUser, "slack-user", "flagger", "Slack user name.")
 	flag.StringVar(&slackChannel, "slack-channel", "", "Slack channel.")
 	flag.StringVar(&eventWebhook, "event-webhook", "", "Webhook for publishing flagger events")
+	flag.StringVar(&eventSinks, "event-sinks", "kubernetes,log,http", "List of sinks that receive the canary events, can be kubernetes, log or http.")
 	flag.StringVar(&msteamsURL, "msteams-url", "", "MS Teams incoming webhook URL.")
 	flag.StringVar(&includeLabelPrefix, "include-label-prefix", "", "List of prefixes of labels that are copied when creating primary deployments or daemonsets. Use * to include all.")
 	flag.IntVar(&threadiness, "threadiness", 2, "Worker concurrency.")
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
 	flag.StringVar(&namespace, "namespace", "", "Namespace that flagger would watch canary object.")
-	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be istio, linkerd, appmesh, contour, gloo, nginx, skipper or traefik.")
+	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be istio, linkerd, appmesh, contour, gloo, nginx, skipper, traefik or gatewayapi.")
+	flag.StringVar(&primarySuffix, "primary-suffix", flaggerv1.DefaultPrimarySuffix, "Suffix appended to the names and the selector label values of the primary objects, the canaries can override it with spec.primarySuffix.")
 	flag.StringVar(&selectorLabels, "selector-labels", "app,name,app.kubernetes.io/name", "List of pod labels that Flagger uses to create pod selectors.")
 	flag.StringVar(&ingressAnnotationsPrefix, "ingress-annotations-prefix", "nginx.ingress.kubernetes.io", "Annotations prefix for NGINX ingresses.")
 	flag.StringVar(&ingressClass, "ingress-class", "", "Ingress class used for annotating HTTPProxy objects.")
@@ -111,9 +151,49 @@ func init() {
 	flag.BoolVar(&enableConfigTracking, "enable-config-tracking", true, "Enable secrets and configmaps tracking.")
 	flag.BoolVar(&ver, "version", false, "Print version")
 	flag.StringVar(&kubeconfigServiceMesh, "kubeconfig-service-mesh", "", "Path to a kubeconfig for the service mesh control plane cluster.")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "", "OTLP/HTTP collector address (host:port) for exporting analysis traces. Tracing is disabled when empty.")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "Disable TLS when exporting traces to the OTLP collector.")
+	flag.StringVar(&apiToken, "api-token", "", "Bearer token for the read-only canaries HTTP API. The API is disabled when empty.")
+	flag.StringVar(&reportStore, "report-store", "", "Object storage address for the analysis reports, can be s3://bucket/prefix, gs://bucket/prefix or azblob://account/container/prefix. Reports are disabled when empty.")
+	flag.StringVar(&reportFormat, "report-format", "json", "Analysis reports format, can be json or html.")
+	flag.StringVar(&reportRegistry, "report-registry", "", "OCI repository the analysis reports are pushed to as artifacts tagged with the target image digest, in the format oci://registry/repository.")
+	flag.BoolVar(&enableDebugEndpoints, "enable-debug-endpoints", false, "Enable the pprof, work queue and goroutine dump endpoints, requires an API token.")
+	flag.DurationVar(&analysisInterval, "analysis-interval", flaggerv1.AnalysisInterval, "Analysis interval of the canaries that don't set one.")
+	flag.StringVar(&settingsConfigMap, "settings-configmap", "", "ConfigMap in the namespace/name format with the settings that are reloaded without a restart, they override the equivalent flags.")
+	flag.StringVar(&webhookTLSCertFile, "webhook-tls-cert-file", "", "Client certificate presented to the webhooks, the file is reloaded on rotation.")
+	flag.StringVar(&webhookTLSKeyFile, "webhook-tls-key-file", "", "Private key of the webhooks client certificate.")
+	flag.StringVar(&webhookTLSCAFile, "webhook-tls-ca-file", "", "CA bundle used to verify the webhook servers, defaults to the system roots.")
+	flag.StringVar(&enabledProviders, "enabled-providers", "", "List of routing providers that the canaries can use, all providers are enabled when empty.")
+	flag.StringVar(&enabledTargetKinds, "enabled-target-kinds", "", "List of target kinds that the canaries can use (Deployment, DaemonSet, Service), all kinds are enabled when empty.")
+	flag.StringVar(&includeNamespaces, "include-namespaces", "", "List of namespaces that Flagger acts on, all namespaces are included when empty.")
+	flag.StringVar(&excludeNamespaces, "exclude-namespaces", "", "List of namespaces that Flagger never acts on, takes precedence over the included namespaces and the selector.")
+	flag.StringVar(&namespaceSelector, "namespace-selector", "", "Label selector of the namespaces that Flagger acts on, e.g. flagger.app/enabled=true.")
+	flag.StringVar(&istioReporter, "istio-reporter", "", "Reporter label value of the builtin Istio queries, defaults to destination.")
+	flag.StringVar(&istioLabelMatchers, "istio-label-matchers", "", "List of PromQL label matchers added to the builtin Istio queries, e.g. source_cluster=\"west\".")
+	flag.StringVar(&istioRequestsMetric, "istio-requests-metric", "", "Name of the requests counter of the builtin Istio queries, defaults to istio_requests_total.")
+	flag.StringVar(&istioRequestDuration, "istio-request-duration-metric", "", "Name of the request duration histogram of the builtin Istio queries, defaults to istio_request_duration_milliseconds.")
+	flag.StringVar(&tenantSecretNamespaces, "tenant-secret-namespaces", "", "List of namespaces whose canaries can override the metric and alert provider credentials with their own secrets, * allows all namespaces.")
+	flag.StringVar(&defaultWebhooks, "default-webhooks", "", "Path to a YAML file with the webhooks appended to the analysis of every canary, the canaries opt out with the flagger.app/skip-default-webhooks annotation.")
+	flag.IntVar(&finalizerMaxAttempts, "finalizer-max-attempts", 0, "Number of failed cleanup attempts after which the finalizer is removed from a deleted canary without reverting it, zero retries forever.")
+	flag.StringVar(&openCostURL, "opencost-url", "", "OpenCost or Kubecost cost model URL queried by the builtin resource-cost metric.")
+	flag.StringVar(&fluxEventsURL, "flux-events-url", "", "Flux notification-controller events API address, e.g. http://notification-controller.flux-system/, an event about the HelmRelease or Kustomization of the target is posted after each promotion.")
+	flag.IntVar(&statusHistoryLimit, "status-history-limit", 0, "Number of significant events kept in the status history of the canaries, zero disables the history.")
+	flag.BoolVar(&crossNamespaceTargets, "enable-cross-namespace-targets", false, "Allow the canaries to target the workloads of other namespaces, the canary objects are created in the target namespace.")
+	flag.StringVar(&specExcludePaths, "spec-exclude-paths", "", "List of pod template field paths ignored when detecting the target changes, e.g. metadata.annotations[policies.kyverno.io/last-applied-patches].")
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "generate":
+			os.Exit(runGenerate(os.Args[2:]))
+		case "simulate":
+			os.Exit(runSimulate(os.Args[2:]))
+		}
+	}
+
 	klog.InitFlags(nil)
 	flag.Parse()
 
@@ -138,6 +218,21 @@ func main() {
 
 	logger.Infof("Starting flagger version %s revision %s mesh provider %s", version.VERSION, version.REVISION, meshProvider)
 
+	shutdownTracing, err := tracing.Setup(context.Background(), otlpEndpoint, otlpInsecure, version.VERSION)
+	if err != nil {
+		logger.Fatalf("Error setting up tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Errorf("Error shutting down tracing: %v", err)
+		}
+	}()
+	if otlpEndpoint != "" {
+		logger.Infof("Exporting traces to %s", otlpEndpoint)
+	}
+
 	cfg, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
 	if err != nil {
 		logger.Fatalf("Error building kubeconfig: %v", err)
@@ -156,6 +251,11 @@ func main() {
 		logger.Fatalf("Error building flagger clientset: %s", err.Error())
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		logger.Fatalf("Error building dynamic client: %v", err)
+	}
+
 	// use a remote cluster for routing if a service mesh kubeconfig is specified
 	if kubeconfigServiceMesh == "" {
 		kubeconfigServiceMesh = kubeconfig
@@ -182,6 +282,13 @@ func main() {
 		logger.Fatalf("At least one selector label is required")
 	}
 
+	if primarySuffix != flaggerv1.DefaultPrimarySuffix {
+		if errs := validation.IsDNS1123Label(strings.TrimPrefix(primarySuffix, "-")); len(errs) > 0 {
+			logger.Fatalf("Invalid primary suffix %q: %s", primarySuffix, strings.Join(errs, ", "))
+		}
+		logger.Infof("Primary objects suffix %s", primarySuffix)
+	}
+
 	if namespace != "" {
 		logger.Infof("Watching namespace %s", namespace)
 	}
@@ -198,13 +305,32 @@ func main() {
 		logger.Errorf("Metrics server %s unreachable %v", metricsServer, err)
 	}
 
-	// setup Slack or MS Teams notifications
-	notifierClient := initNotifier(logger)
+	// the defaults of the settings that can be reloaded from a ConfigMap
+	settings := controller.Settings{
+		MetricsServer:      metricsServer,
+		IncludeLabelPrefix: strings.Split(includeLabelPrefix, ","),
+		SlackURL:           fromEnv("SLACK_URL", slackURL),
+		SlackUser:          slackUser,
+		SlackChannel:       slackChannel,
+		MSTeamsURL:         fromEnv("MSTEAMS_URL", msteamsURL),
+		AnalysisInterval:   analysisInterval,
 
-	// start HTTP server
-	go server.ListenAndServe(port, 3*time.Second, logger, stopCh)
+		IstioReporter:              istioReporter,
+		IstioLabelMatchers:         splitList(istioLabelMatchers),
+		IstioRequestsMetric:        istioRequestsMetric,
+		IstioRequestDurationMetric: istioRequestDuration,
+	}
+	if err := settings.IstioOptions().Validate(); err != nil {
+		logger.Fatalf("Error parsing the Istio queries flags: %v", err)
+	}
+	observerFactory.Istio = settings.IstioOptions()
+
+	// setup Slack or MS Teams notifications
+	notifierClient := initNotifier(settings, logger)
 
 	routerFactory := router.NewFactory(cfg, kubeClient, flaggerClient, ingressAnnotationsPrefix, ingressClass, logger, meshClient)
+	routerFactory.SetDynamicClient(dynamicClient)
+	routerFactory.SetPrimarySuffix(primarySuffix)
 
 	var configTracker canary.Tracker
 	if enableConfigTracking {
@@ -217,9 +343,65 @@ func main() {
 		configTracker = &canary.NopTracker{}
 	}
 
-	includeLabelPrefixArray := strings.Split(includeLabelPrefix, ",")
+	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, labels, settings.IncludeLabelPrefix, logger)
+	canaryFactory.SetDynamicClient(dynamicClient)
+	canaryFactory.SetPrimarySuffix(primarySuffix)
+	if specExcludePaths != "" {
+		paths := splitList(specExcludePaths)
+		for _, path := range paths {
+			if _, err := canary.ParseExcludePath(path); err != nil {
+				logger.Fatalf("Error parsing the spec exclude paths: %v", err)
+			}
+		}
+		canaryFactory.SetExcludePaths(paths)
+		logger.Infof("Ignoring the pod template fields %s when detecting changes", specExcludePaths)
+	}
+
+	var reporter *report.Exporter
+	if reportStore != "" || reportRegistry != "" {
+		var store report.Store
+		var err error
+		if reportStore != "" {
+			store, err = report.NewStore(reportStore)
+			if err != nil {
+				logger.Fatalf("Error creating report store: %v", err)
+			}
+			logger.Infof("Exporting analysis reports to %s", reportStore)
+		}
+		reporter, err = report.NewExporter(store, reportFormat, logger)
+		if err != nil {
+			logger.Fatalf("Error creating report exporter: %v", err)
+		}
+		if reportRegistry != "" {
+			registry, err := report.NewRegistry(reportRegistry)
+			if err != nil {
+				logger.Fatalf("Error creating report registry: %v", err)
+			}
+			reporter.WithRegistry(registry)
+			logger.Infof("Pushing analysis reports to %s", reportRegistry)
+		}
+	}
+
+	var sinks []string
+	if eventSinks != "" {
+		sinks = strings.Split(eventSinks, ",")
+	}
+	if err := controller.ValidateEventSinks(sinks); err != nil {
+		logger.Fatalf("Error parsing event sinks: %v", err)
+	}
 
-	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, labels, includeLabelPrefixArray, logger)
+	webhookTLS := mtls.Config{
+		CertFile: webhookTLSCertFile,
+		KeyFile:  webhookTLSKeyFile,
+		CAFile:   webhookTLSCAFile,
+	}
+	if err := webhookTLS.Validate(); err != nil {
+		logger.Fatalf("Error loading the webhooks TLS certificates: %v", err)
+	}
+	controller.SetWebhookTLS(webhookTLS)
+	if webhookTLS.Enabled() {
+		logger.Infof("Presenting the client certificate %s to the webhooks", webhookTLSCertFile)
+	}
 
 	c := controller.NewController(
 		kubeClient,
@@ -234,7 +416,99 @@ func main() {
 		meshProvider,
 		version.VERSION,
 		fromEnv("EVENT_WEBHOOK_URL", eventWebhook),
+		sinks,
+		reporter,
 	)
+	c.SetPrimarySuffix(primarySuffix)
+
+	// limit the providers and the target kinds to the API groups allowed by RBAC
+	if enabledProviders != "" {
+		if err := c.SetEnabledProviders(strings.Split(enabledProviders, ",")); err != nil {
+			logger.Fatalf("Error parsing enabled providers: %v", err)
+		}
+		logger.Infof("Enabled providers %s", enabledProviders)
+	}
+	if enabledTargetKinds != "" {
+		if err := c.SetEnabledTargetKinds(strings.Split(enabledTargetKinds, ",")); err != nil {
+			logger.Fatalf("Error parsing enabled target kinds: %v", err)
+		}
+		logger.Infof("Enabled target kinds %s", enabledTargetKinds)
+	}
+	if includeNamespaces != "" || excludeNamespaces != "" || namespaceSelector != "" {
+		if err := c.SetNamespaceFilter(splitList(includeNamespaces), splitList(excludeNamespaces), namespaceSelector); err != nil {
+			logger.Fatalf("Error parsing the namespace filter: %v", err)
+		}
+		logger.Infof("Namespace filter include: [%s] exclude: [%s] selector: %q", includeNamespaces, excludeNamespaces, namespaceSelector)
+	}
+	if tenantSecretNamespaces != "" {
+		c.SetTenantSecretNamespaces(splitList(tenantSecretNamespaces))
+		logger.Infof("Tenant secrets enabled for namespaces %s", tenantSecretNamespaces)
+	}
+	if defaultWebhooks != "" {
+		webhooks, err := controller.LoadDefaultWebhooks(defaultWebhooks)
+		if err != nil {
+			logger.Fatalf("Error loading the default webhooks: %v", err)
+		}
+		c.SetDefaultWebhooks(webhooks)
+		logger.Infof("Appending %d default webhooks to the canaries", len(webhooks))
+	}
+	if finalizerMaxAttempts > 0 {
+		c.SetFinalizerMaxAttempts(finalizerMaxAttempts)
+	}
+	if statusHistoryLimit > 0 {
+		c.SetStatusHistoryLimit(statusHistoryLimit)
+	}
+	c.SetDefaultAnalysisInterval(settings.AnalysisInterval)
+	if crossNamespaceTargets {
+		c.SetCrossNamespaceTargets(true)
+		logger.Infof("Cross-namespace targets enabled")
+	}
+	if openCostURL != "" {
+		c.SetOpenCostURL(openCostURL)
+		logger.Infof("Querying the resource cost metrics from %s", openCostURL)
+	}
+	if fluxEventsURL != "" {
+		c.SetFluxEventsURL(fluxEventsURL)
+		logger.Infof("Posting the promotion events of the Flux owned targets to %s", fluxEventsURL)
+	}
+
+	if settingsConfigMap != "" {
+		parts := strings.Split(settingsConfigMap, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			logger.Fatalf("Settings ConfigMap %s must be in the namespace/name format", settingsConfigMap)
+		}
+		c.WatchSettings(parts[0], parts[1], settings, stopCh)
+		logger.Infof("Watching settings ConfigMap %s", settingsConfigMap)
+	}
+
+	// expose the canaries state and the debug endpoints when an API token is set
+	handlers := make(map[string]http.Handler)
+	token := fromEnv("API_TOKEN", apiToken)
+	if token != "" {
+		handlers["/api/canaries"] = server.BearerAuth(token, c.CanariesHandler())
+		handlers["/api/timeline"] = server.BearerAuth(token, c.TimelineHandler())
+		handlers["/api/drift"] = server.BearerAuth(token, c.DriftHandler())
+		handlers["/api/rerun"] = server.BearerAuth(token, c.RerunHandler())
+	}
+	// the gates accept the API token or the token of the canary gate secret,
+	// the gate check is called by the canary confirm webhooks without credentials
+	c.SetGateToken(token)
+	handlers["/gate/check"] = c.GateCheckHandler()
+	handlers["/gate/open"] = c.GateOpenHandler()
+	handlers["/gate/close"] = c.GateCloseHandler()
+	if enableDebugEndpoints {
+		if token == "" {
+			logger.Fatalf("The debug endpoints require an API token")
+		}
+		for pattern, handler := range server.DebugHandlers() {
+			handlers[pattern] = server.BearerAuth(token, handler)
+		}
+		handlers["/debug/queue"] = server.BearerAuth(token, c.QueueHandler())
+		logger.Warn("Debug endpoints enabled")
+	}
+
+	// start HTTP server
+	go server.ListenAndServe(port, 3*time.Second, logger, handlers, stopCh)
 
 	// leader election context
 	ctx, cancel := context.WithCancel(context.Background())
@@ -292,10 +566,26 @@ func startInformers(flaggerClient clientset.Interface, logger *zap.SugaredLogger
 		logger.Fatalf("failed to wait for cache to sync")
 	}
 
+	logger.Info("Waiting for canary wave informer cache to sync")
+	waveInformer := flaggerInformerFactory.Flagger().V1beta1().CanaryWaves()
+	go waveInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, waveInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
+	logger.Info("Waiting for canary class informer cache to sync")
+	classInformer := flaggerInformerFactory.Flagger().V1beta1().CanaryClasses()
+	go classInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, classInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
 	return controller.Informers{
 		CanaryInformer: canaryInformer,
 		MetricInformer: metricInformer,
 		AlertInformer:  alertInformer,
+		WaveInformer:   waveInformer,
+		ClassInformer:  classInformer,
 	}
 }
 
@@ -346,23 +636,21 @@ func startLeaderElection(ctx context.Context, run func(), ns string, kubeClient
 	})
 }
 
-func initNotifier(logger *zap.SugaredLogger) (client notifier.Interface) {
-	provider := "slack"
-	notifierURL := fromEnv("SLACK_URL", slackURL)
-	if msteamsURL != "" || os.Getenv("MSTEAMS_URL") != "" {
-		provider = "msteams"
-		notifierURL = fromEnv("MSTEAMS_URL", msteamsURL)
-	}
-	notifierFactory := notifier.NewFactory(notifierURL, slackUser, slackChannel)
-
-	var err error
-	client, err = notifierFactory.Notifier(provider)
+func initNotifier(settings controller.Settings, logger *zap.SugaredLogger) notifier.Interface {
+	client, err := settings.Notifier()
 	if err != nil {
 		logger.Errorf("Notifier %v", err)
-	} else if len(notifierURL) > 30 {
+		return client
+	}
+
+	notifierURL := settings.SlackURL
+	if settings.MSTeamsURL != "" {
+		notifierURL = settings.MSTeamsURL
+	}
+	if len(notifierURL) > 30 {
 		logger.Infof("Notifications enabled for %s", notifierURL[0:30])
 	}
-	return
+	return client
 }
 
 func fromEnv(envVar string, defaultVal string) string {
@@ -387,6 +675,16 @@ func verifyCRDs(flaggerClient clientset.Interface, logger *zap.SugaredLogger) {
 	if err != nil {
 		logger.Fatalf("AlertProvider CRD is not registered %v", err)
 	}
+
+	_, err = flaggerClient.FlaggerV1beta1().CanaryWaves(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		logger.Fatalf("CanaryWave CRD is not registered %v", err)
+	}
+
+	_, err = flaggerClient.FlaggerV1beta1().CanaryClasses().List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		logger.Fatalf("CanaryClass CRD is not registered %v", err)
+	}
 }
 
 func verifyKubernetesVersion(kubeClient kubernetes.Interface, logger *zap.SugaredLogger) {
@@ -417,3 +715,11 @@ func verifyKubernetesVersion(kubeClient kubernetes.Interface, logger *zap.Sugare
 
 	logger.Infof("Connected to Kubernetes API %s", ver)
 }
+
+// splitList returns the items of a comma separated flag, or nil when the flag is empty
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
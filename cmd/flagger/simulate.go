@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/controller"
+	"github.com/fluxcd/flagger/pkg/lint"
+)
+
+// runSimulate replays a recorded metrics dataset through the analysis of a canary manifest,
+// it returns a non-zero exit code when the canary would be rolled back
+func runSimulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	var canaryFile, metricsFile, name, output string
+	fs.StringVar(&canaryFile, "f", "", "Canary manifest.")
+	fs.StringVar(&metricsFile, "metrics", "", "Recorded metric values by metric name, in YAML or JSON.")
+	fs.StringVar(&name, "canary", "", "Name of the canary when the manifest contains more than one.")
+	fs.StringVar(&output, "o", "table", "Output format, table or json.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: flagger simulate -f CANARY -metrics DATASET [flags]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if canaryFile == "" || metricsFile == "" {
+		fs.Usage()
+		return 2
+	}
+
+	cd, err := readSimulationCanary(canaryFile, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", canaryFile, err)
+		return 2
+	}
+	data, err := ioutil.ReadFile(metricsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", metricsFile, err)
+		return 2
+	}
+	var dataset controller.SimulationDataset
+	if err := yaml.Unmarshal(data, &dataset); err != nil {
+		fmt.Fprintf(os.Stderr, "Error decoding %s: %v\n", metricsFile, err)
+		return 2
+	}
+
+	result, err := controller.Simulate(cd, dataset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error simulating the analysis: %v\n", err)
+		return 2
+	}
+
+	switch output {
+	case "json":
+		b, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(b))
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ITERATION\tWEIGHT\tFAILED CHECKS\tMETRICS")
+		for _, step := range result.Steps {
+			var metrics []string
+			for _, m := range step.Metrics {
+				check := "ok"
+				if !m.Passed {
+					check = "failed"
+				}
+				metrics = append(metrics, fmt.Sprintf("%s=%s (%s %s)", m.Name, m.Value, m.Threshold, check))
+			}
+			weight := fmt.Sprintf("%d", step.CanaryWeight)
+			if step.Mirrored {
+				weight += " mirrored"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", step.Iteration, weight, step.FailedChecks, strings.Join(metrics, ", "))
+		}
+		w.Flush()
+		fmt.Println(result.Message)
+	}
+
+	if result.Phase == flaggerv1.CanaryPhaseFailed {
+		return 1
+	}
+	return 0
+}
+
+// readSimulationCanary returns the canary of the manifest, the name is required
+// when the manifest contains more than one canary
+func readSimulationCanary(file string, name string) (*flaggerv1.Canary, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	manifests := &lint.Manifests{}
+	if _, err := manifests.Decode(file, f); err != nil {
+		return nil, err
+	}
+	var canaries []*flaggerv1.Canary
+	for _, cd := range manifests.Canaries {
+		if name == "" || cd.Name == name {
+			canaries = append(canaries, cd)
+		}
+	}
+	switch {
+	case len(canaries) == 0:
+		return nil, fmt.Errorf("canary %s not found", name)
+	case len(canaries) > 1:
+		return nil, fmt.Errorf("the manifest contains %d canaries, select one with -canary", len(canaries))
+	}
+
+	// apply the defaults of the canary class when it's defined in the manifest
+	cd := canaries[0]
+	for _, class := range manifests.CanaryClasses {
+		if class.Name == cd.Spec.ClassName {
+			return class.ApplyTo(cd), nil
+		}
+	}
+	return cd, nil
+}
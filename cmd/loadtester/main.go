@@ -17,12 +17,15 @@ limitations under the License.
 package main
 
 import (
+	"crypto/tls"
 	"flag"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/fluxcd/flagger/pkg/loadtester"
 	"github.com/fluxcd/flagger/pkg/logger"
+	"github.com/fluxcd/flagger/pkg/mtls"
 	"github.com/fluxcd/flagger/pkg/signals"
 	"go.uber.org/zap"
 )
@@ -34,6 +37,16 @@ var (
 	timeout           time.Duration
 	zapReplaceGlobals bool
 	zapEncoding       string
+	tlsCertFile       string
+	tlsKeyFile        string
+	tlsClientCAFile   string
+	tlsClientSANs     string
+	maxConcurrent     int
+	maxPerNamespace   int
+	maxQueue          int
+	artifactsURL      string
+	artifactsEndpoint string
+	artifactsRegion   string
 )
 
 func init() {
@@ -42,6 +55,16 @@ func init() {
 	flag.DurationVar(&timeout, "timeout", time.Hour, "Load test exec timeout.")
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
+	flag.StringVar(&tlsCertFile, "tls-cert-file", "", "Server certificate, enables mutual TLS when set together with the key and the client CA.")
+	flag.StringVar(&tlsKeyFile, "tls-key-file", "", "Private key of the server certificate.")
+	flag.StringVar(&tlsClientCAFile, "tls-client-ca-file", "", "CA bundle used to verify the client certificates.")
+	flag.IntVar(&maxConcurrent, "max-concurrent", 0, "Max number of tasks running at the same time, zero means unlimited.")
+	flag.IntVar(&maxPerNamespace, "max-concurrent-per-namespace", 0, "Max number of tasks running at the same time for the canaries of a namespace, zero means unlimited.")
+	flag.IntVar(&maxQueue, "max-queue", 0, "Max number of tasks waiting for a slot, the tasks are rejected with 429 when the queue is full, zero means unlimited.")
+	flag.StringVar(&artifactsURL, "artifacts-url", "", "Bucket address where the tasks output is uploaded, e.g. s3://my-bucket/loadtester or gs://my-bucket/loadtester.")
+	flag.StringVar(&artifactsEndpoint, "artifacts-endpoint", "", "S3 compatible endpoint of the artifacts bucket, defaults to AWS S3 or GCS based on the bucket address.")
+	flag.StringVar(&artifactsRegion, "artifacts-region", "", "Region of the artifacts bucket.")
+	flag.StringVar(&tlsClientSANs, "tls-client-sans", "", "List of DNS names or URIs accepted for the client certificates, e.g. spiffe://cluster.local/ns/flagger-system/sa/flagger.")
 }
 
 func main() {
@@ -60,11 +83,40 @@ func main() {
 	stopCh := signals.SetupSignalHandler()
 
 	taskRunner := loadtester.NewTaskRunner(logger, timeout)
+	taskRunner.SetLimits(loadtester.Limits{
+		MaxConcurrent:   maxConcurrent,
+		MaxPerNamespace: maxPerNamespace,
+		MaxQueue:        maxQueue,
+	})
+	if artifactsURL != "" {
+		store, err := loadtester.NewArtifactStore(artifactsURL, artifactsEndpoint, artifactsRegion)
+		if err != nil {
+			logger.Fatalf("Error creating the artifacts store: %v", err)
+		}
+		taskRunner.SetArtifactStore(store)
+	}
 
 	go taskRunner.Start(100*time.Millisecond, stopCh)
 
 	logger.Infof("Starting load tester v%s API on port %s", VERSION, port)
 
+	var tlsConfig *tls.Config
+	if tlsCertFile != "" || tlsKeyFile != "" {
+		config := mtls.Config{CertFile: tlsCertFile, KeyFile: tlsKeyFile, CAFile: tlsClientCAFile}
+		if tlsClientCAFile == "" {
+			logger.Fatalf("The client CA is required to enable mutual TLS")
+		}
+		if err := config.Validate(); err != nil {
+			logger.Fatalf("Error loading the TLS certificates: %v", err)
+		}
+		var sans []string
+		if tlsClientSANs != "" {
+			sans = strings.Split(tlsClientSANs, ",")
+		}
+		tlsConfig = config.ServerConfig(sans)
+		logger.Infof("Mutual TLS enabled with the client CA %s", tlsClientCAFile)
+	}
+
 	gateStorage := loadtester.NewGateStorage("in-memory")
-	loadtester.ListenAndServe(port, time.Minute, logger, taskRunner, gateStorage, stopCh)
+	loadtester.ListenAndServe(port, time.Minute, logger, taskRunner, gateStorage, tlsConfig, stopCh)
 }
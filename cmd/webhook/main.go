@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+	"github.com/fluxcd/flagger/pkg/logger"
+	"github.com/fluxcd/flagger/pkg/signals"
+	"github.com/fluxcd/flagger/pkg/version"
+	"github.com/fluxcd/flagger/pkg/webhook"
+)
+
+var (
+	masterURL         string
+	kubeconfig        string
+	logLevel          string
+	port              string
+	tlsCertFile       string
+	tlsKeyFile        string
+	primarySuffix     string
+	zapReplaceGlobals bool
+	zapEncoding       string
+)
+
+func init() {
+	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	flag.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	flag.StringVar(&logLevel, "log-level", "info", "Log level can be: debug, info, warning, error.")
+	flag.StringVar(&port, "port", "9443", "Port to listen on.")
+	flag.StringVar(&tlsCertFile, "tls-cert-file", "", "Server certificate of the admission webhook.")
+	flag.StringVar(&tlsKeyFile, "tls-key-file", "", "Private key of the server certificate.")
+	flag.StringVar(&primarySuffix, "primary-suffix", flaggerv1.DefaultPrimarySuffix, "Suffix of the primary workloads set with the Flagger -primary-suffix flag.")
+	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
+	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
+}
+
+func main() {
+	flag.Parse()
+
+	logger, err := logger.NewLoggerWithEncoding(logLevel, zapEncoding)
+	if err != nil {
+		log.Fatalf("Error creating logger: %v", err)
+	}
+	if zapReplaceGlobals {
+		zap.ReplaceGlobals(logger.Desugar())
+	}
+
+	defer logger.Sync()
+
+	if tlsCertFile == "" || tlsKeyFile == "" {
+		logger.Fatalf("The admission webhook requires the -tls-cert-file and -tls-key-file flags")
+	}
+
+	if errs := validation.IsDNS1123Label(strings.TrimPrefix(primarySuffix, "-")); len(errs) > 0 {
+		logger.Fatalf("Invalid primary suffix %q: %s", primarySuffix, strings.Join(errs, ", "))
+	}
+
+	cfg, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
+	if err != nil {
+		logger.Fatalf("Error building kubeconfig: %v", err)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		logger.Fatalf("Error building kubernetes clientset: %v", err)
+	}
+
+	flaggerClient, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		logger.Fatalf("Error building flagger clientset: %s", err.Error())
+	}
+
+	stopCh := signals.SetupSignalHandler()
+
+	validator := webhook.NewValidator(kubeClient, flaggerClient, primarySuffix, logger)
+	logger.Infof("Starting the canary admission webhook v%s on port %s", version.VERSION, port)
+	webhook.ListenAndServe(port, tlsCertFile, tlsKeyFile, validator, logger, stopCh)
+}
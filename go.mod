@@ -7,9 +7,14 @@ require (
 	github.com/aws/aws-sdk-go v1.37.32
 	github.com/davecgh/go-spew v1.1.1
 	github.com/go-logr/zapr v0.3.0
-	github.com/google/go-cmp v0.5.2
+	github.com/google/go-cmp v0.5.6
 	github.com/prometheus/client_golang v1.9.0
+	github.com/prometheus/client_model v0.2.0
 	github.com/stretchr/testify v1.7.0
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
 	go.uber.org/zap v1.14.1
 	golang.org/x/tools v0.1.0 // indirect
 	gopkg.in/h2non/gock.v1 v1.0.15
@@ -18,4 +23,5 @@ require (
 	k8s.io/client-go v0.20.4
 	k8s.io/code-generator v0.20.4
 	k8s.io/klog/v2 v2.4.0
+	sigs.k8s.io/yaml v1.2.0
 )
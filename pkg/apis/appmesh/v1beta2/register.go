@@ -35,6 +35,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&VirtualRouterList{},
 		&VirtualNode{},
 		&VirtualNodeList{},
+		&GatewayRoute{},
+		&GatewayRouteList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
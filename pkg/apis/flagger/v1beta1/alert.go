@@ -67,6 +67,14 @@ type AlertProviderSpec struct {
 	// Secret reference containing the provider webhook URL
 	// +optional
 	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Secret key containing the provider webhook URL, overrides the secretRef address
+	// +optional
+	AddressSecretKeyRef *corev1.SecretKeySelector `json:"addressSecretKeyRef,omitempty"`
+
+	// Secret key containing the provider API token, overrides the secretRef token
+	// +optional
+	TokenSecretKeyRef *corev1.SecretKeySelector `json:"tokenSecretKeyRef,omitempty"`
 }
 
 type AlertProviderStatus struct {
@@ -18,18 +18,37 @@ package v1beta1
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	"github.com/fluxcd/flagger/pkg/apis/kruise"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
 	CanaryKind              = "Canary"
+	KruiseDaemonSetKind     = "KruiseDaemonSet"
+	ScaleSubresourceKind    = "ScaleSubresource"
+	KnativeServiceKind      = "KnativeService"
 	ProgressDeadlineSeconds = 600
 	AnalysisInterval        = 60 * time.Second
 	MetricInterval          = "1m"
+	TotalWeight             = 100
+	ReadyThreshold          = 100
+
+	// KnativeServingGroup is the API group of the Knative Serving Services
+	KnativeServingGroup = "serving.knative.dev"
+
+	// CanaryOwnerAnnotation holds the namespace/name of the canary that generated an object
+	// in the target namespace when the canary is in another namespace
+	CanaryOwnerAnnotation = "flagger.app/canary"
+
+	// DefaultPrimarySuffix is appended to the names and the label values of the primary objects
+	DefaultPrimarySuffix = "-primary"
 )
 
 // +genclient
@@ -66,9 +85,23 @@ type CanarySpec struct {
 	// +optional
 	MetricsServer string `json:"metricsServer,omitempty"`
 
+	// ClassName references the CanaryClass that holds the defaults of this canary
+	// +optional
+	ClassName string `json:"className,omitempty"`
+
 	// TargetRef references a target resource
 	TargetRef CrossNamespaceObjectReference `json:"targetRef"`
 
+	// TargetRefs references the workloads that are analyzed and promoted together with the target,
+	// the traffic is routed to the target while the primary workloads are generated for all of them
+	// +optional
+	TargetRefs []CrossNamespaceObjectReference `json:"targetRefs,omitempty"`
+
+	// SidecarSetRefs references the OpenKruise SidecarSets that inject containers in the target pods,
+	// a change of the sidecar images triggers a new analysis like a change of the tracked configs
+	// +optional
+	SidecarSetRefs []corev1.LocalObjectReference `json:"sidecarSetRefs,omitempty"`
+
 	// AutoscalerRef references an autoscaling resource
 	// +optional
 	AutoscalerRef *CrossNamespaceObjectReference `json:"autoscalerRef,omitempty"`
@@ -80,6 +113,21 @@ type CanarySpec struct {
 	// Service defines how ClusterIP services, service mesh or ingress routing objects are generated
 	Service CanaryService `json:"service"`
 
+	// TargetCluster where the target workload is deployed instead of the local cluster,
+	// the primary workload is generated in that cluster while the routing objects are managed locally
+	// +optional
+	TargetCluster *CanaryRemoteCluster `json:"targetCluster,omitempty"`
+
+	// RemoteClusters where the target workload is also deployed,
+	// the primary workloads and the ClusterIP services are managed in each cluster
+	// +optional
+	RemoteClusters []CanaryRemoteCluster `json:"remoteClusters,omitempty"`
+
+	// Propagation references the Karmada policies that propagate the target to the member clusters,
+	// the generated objects are added to the policies instead of being propagated separately
+	// +optional
+	Propagation *CanaryPropagation `json:"propagation,omitempty"`
+
 	// Analysis defines the validation process of a release
 	Analysis *CanaryAnalysis `json:"analysis,omitempty"`
 
@@ -91,13 +139,78 @@ type CanarySpec struct {
 	// +optional
 	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
 
+	// PrimarySuffix is appended to the names and the label values of the primary objects,
+	// defaults to the controller primary suffix
+	// +optional
+	PrimarySuffix string `json:"primarySuffix,omitempty"`
+
 	// SkipAnalysis promotes the canary without analysing it
 	// +optional
 	SkipAnalysis bool `json:"skipAnalysis,omitempty"`
 
+	// Suspend holds the canary analysis at the current step,
+	// the analysis continues from the same step when resumed
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// SuspendFluxDuringAnalysis suspends the Flux Kustomization or HelmRelease that owns the target
+	// while the analysis is running, the Flux object is resumed when the canary is promoted or rolled back
+	// +optional
+	SuspendFluxDuringAnalysis bool `json:"suspendFluxDuringAnalysis,omitempty"`
+
 	// revert canary mutation on deletion of canary resource
 	// +optional
 	RevertOnDeletion bool `json:"revertOnDeletion,omitempty"`
+
+	// RevertPolicy controls what the finalizer restores when revertOnDeletion is enabled,
+	// can be Restore, RestorePrimary, KeepServices or Delete (default Restore)
+	// +optional
+	RevertPolicy RevertPolicy `json:"revertPolicy,omitempty"`
+
+	// GateSecretRef references a secret in the canary namespace with the token
+	// accepted by the controller gates of this canary in the token key
+	// +optional
+	GateSecretRef *corev1.LocalObjectReference `json:"gateSecretRef,omitempty"`
+}
+
+// RevertPolicy defines how the target and the generated objects are handled on deletion
+type RevertPolicy string
+
+const (
+	// RevertPolicyRestore scales the target back up and routes the apex service to it,
+	// the generated objects are garbage collected
+	RevertPolicyRestore RevertPolicy = "Restore"
+	// RevertPolicyRestorePrimary restores the target with the pod template of the primary,
+	// so that the last promoted revision keeps running under the original name
+	RevertPolicyRestorePrimary RevertPolicy = "RestorePrimary"
+	// RevertPolicyKeepServices restores the target and keeps the services generated by Flagger
+	RevertPolicyKeepServices RevertPolicy = "KeepServices"
+	// RevertPolicyDelete deletes the target and leaves the generated objects to the garbage collector
+	RevertPolicyDelete RevertPolicy = "Delete"
+)
+
+// CanaryRemoteCluster defines a cluster connected to the mesh of the canary
+type CanaryRemoteCluster struct {
+	// Name of the cluster
+	Name string `json:"name"`
+
+	// SecretRef references a secret in the canary namespace
+	// containing the kubeconfig of the cluster under the kubeconfig key
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+}
+
+// CanaryPropagation defines the Karmada policies of the target workload
+type CanaryPropagation struct {
+	// PropagationPolicyRef references the PropagationPolicy of the target in the canary namespace,
+	// the primary workload and the generated services are added to its resource selectors
+	// +optional
+	PropagationPolicyRef *corev1.LocalObjectReference `json:"propagationPolicyRef,omitempty"`
+
+	// OverridePolicyRef references the OverridePolicy with the per-cluster overrides of the target
+	// in the canary namespace, the overrides are moved from the target to the primary workload
+	// so that they don't scale up the target that Flagger scaled to zero
+	// +optional
+	OverridePolicyRef *corev1.LocalObjectReference `json:"overridePolicyRef,omitempty"`
 }
 
 // CanaryService defines how ClusterIP services, service mesh or ingress routing objects are generated
@@ -115,6 +228,10 @@ type CanaryService struct {
 	// +optional
 	PortName string `json:"portName,omitempty"`
 
+	// AppProtocol of the generated Kubernetes service port, e.g. http, h2c or grpc
+	// +optional
+	AppProtocol string `json:"appProtocol,omitempty"`
+
 	// Target port number or name of the generated Kubernetes service
 	// Defaults to CanaryService.Port
 	// +optional
@@ -123,6 +240,35 @@ type CanaryService struct {
 	// PortDiscovery adds all container ports to the generated Kubernetes service
 	PortDiscovery bool `json:"portDiscovery"`
 
+	// PortDiscoveryRules filters and remaps the discovered container ports
+	// +optional
+	PortDiscoveryRules *PortDiscoveryRules `json:"portDiscoveryRules,omitempty"`
+
+	// Headless generates the Kubernetes services without a cluster IP,
+	// for clients that load balance the pod addresses themselves
+	// +optional
+	Headless bool `json:"headless,omitempty"`
+
+	// InternalTrafficPolicy of the generated Kubernetes services, can be Cluster or Local
+	// +optional
+	InternalTrafficPolicy string `json:"internalTrafficPolicy,omitempty"`
+
+	// TrafficDistribution of the generated Kubernetes services, e.g. PreferClose
+	// +optional
+	TrafficDistribution string `json:"trafficDistribution,omitempty"`
+
+	// TopologyMode enables the topology aware routing of the generated Kubernetes services, e.g. Auto
+	// +optional
+	TopologyMode string `json:"topologyMode,omitempty"`
+
+	// ServiceMonitor generates Prometheus Operator service monitors for the canary and primary services
+	// +optional
+	ServiceMonitor *ServiceMonitor `json:"serviceMonitor,omitempty"`
+
+	// NetworkPolicy generates a Kubernetes network policy that restricts the sources allowed to reach the canary pods
+	// +optional
+	NetworkPolicy *CanaryNetworkPolicy `json:"networkPolicy,omitempty"`
+
 	// Timeout of the HTTP or gRPC request
 	// +optional
 	Timeout string `json:"timeout,omitempty"`
@@ -137,12 +283,32 @@ type CanaryService struct {
 	// +optional
 	Hosts []string `json:"hosts,omitempty"`
 
+	// GatewayRefs attach the generated Istio virtual service to distinct host and gateway pairs,
+	// each pair gets its own HTTP routes scoped to its gateways
+	// +optional
+	GatewayRefs []GatewayRef `json:"gatewayRefs,omitempty"`
+
+	// PortWeights shift the requests to a service port at their own pace,
+	// e.g. to move the gRPC clients of a mixed HTTP and gRPC service slower than the HTTP ones
+	// +optional
+	PortWeights []CanaryPortWeight `json:"portWeights,omitempty"`
+
 	// If enabled, Flagger would generate Istio VirtualServices without hosts and gateway,
 	// making the service compatible with Istio delegation. Note that pilot env
 	// `PILOT_ENABLE_VIRTUAL_SERVICE_DELEGATE` must also be set.
 	// +optional
 	Delegation bool `json:"delegation,omitempty"`
 
+	// VirtualService merges the canary routes into an existing Istio virtual service
+	// under a named HTTP route instead of generating a virtual service for the canary
+	// +optional
+	VirtualService *VirtualServiceRoute `json:"virtualService,omitempty"`
+
+	// If enabled, Flagger copies the Istio EnvoyFilters and Sidecars that select
+	// the target pods and changes their workload selector to the primary pods
+	// +optional
+	CopyProxyConfig bool `json:"copyProxyConfig,omitempty"`
+
 	// TrafficPolicy attached to the generated Istio destination rules
 	// +optional
 	TrafficPolicy *istiov1alpha3.TrafficPolicy `json:"trafficPolicy,omitempty"`
@@ -175,6 +341,25 @@ type CanaryService struct {
 	// +optional
 	Backends []string `json:"backends,omitempty"`
 
+	// GatewayRoute of the generated App Mesh virtual service,
+	// the ingress traffic of the virtual gateway follows the canary routes
+	// +optional
+	GatewayRoute *AppMeshGatewayRoute `json:"gatewayRoute,omitempty"`
+
+	// RouteTable merges the canary routes into an existing Gloo route table
+	// delegated by a virtual service instead of generating a route table for the canary
+	// +optional
+	RouteTable *GlooRouteTableRoute `json:"routeTable,omitempty"`
+
+	// ApexMode controls how the apex service is managed, can be Managed, Unmanaged or ExternalName
+	// Defaults to Managed
+	// +optional
+	ApexMode ApexMode `json:"apexMode,omitempty"`
+
+	// ExternalName is the DNS name the apex service points to when the apex mode is ExternalName
+	// +optional
+	ExternalName string `json:"externalName,omitempty"`
+
 	// Apex is metadata to add to the apex service
 	// +optional
 	Apex *CustomMetadata `json:"apex,omitempty"`
@@ -188,6 +373,18 @@ type CanaryService struct {
 	Canary *CustomMetadata `json:"canary,omitempty"`
 }
 
+// ApexMode defines how Flagger manages the apex service
+type ApexMode string
+
+const (
+	// ApexModeManaged means the apex service is created and updated by Flagger
+	ApexModeManaged ApexMode = "Managed"
+	// ApexModeUnmanaged means the apex service is owned by another system and left untouched
+	ApexModeUnmanaged ApexMode = "Unmanaged"
+	// ApexModeExternalName means the apex service is created as an ExternalName service
+	ApexModeExternalName ApexMode = "ExternalName"
+)
+
 // CanaryAnalysis is used to describe how the analysis should be done
 type CanaryAnalysis struct {
 	// Schedule interval for this canary analysis
@@ -205,6 +402,12 @@ type CanaryAnalysis struct {
 	// +optional
 	MirrorWeight int `json:"mirrorWeight,omitempty"`
 
+	// Total traffic weight that the max and step weights are relative to,
+	// values other than 100 are only supported by the NGINX ingress and ignored for the other providers
+	// Defaults to 100
+	// +optional
+	TotalWeight int `json:"totalWeight,omitempty"`
+
 	// Max traffic weight routed to canary
 	// +optional
 	MaxWeight int `json:"maxWeight,omitempty"`
@@ -221,6 +424,10 @@ type CanaryAnalysis struct {
 	// +optional
 	StepWeightPromotion int `json:"stepWeightPromotion,omitempty"`
 
+	// Number of checks to run at the max weight before the promotion
+	// +optional
+	SoakIterations int `json:"soakIterations,omitempty"`
+
 	// Max number of failed checks before the canary is terminated
 	Threshold int `json:"threshold"`
 
@@ -235,9 +442,57 @@ type CanaryAnalysis struct {
 	// +optional
 	Webhooks []CanaryWebhook `json:"webhooks,omitempty"`
 
+	// Policy checks of the canary pod spec evaluated before routing traffic to the canary
+	// +optional
+	Policies []CanaryPolicy `json:"policies,omitempty"`
+
 	// A/B testing HTTP header match conditions
 	// +optional
 	Match []istiov1alpha3.HTTPMatchRequest `json:"match,omitempty"`
+
+	// SessionAffinity pins the users to the version they were first routed to
+	// +optional
+	SessionAffinity *SessionAffinity `json:"sessionAffinity,omitempty"`
+
+	// MaxDuration of the analysis after which an unfinished canary is rolled back or paused
+	// +optional
+	MaxDuration string `json:"maxDuration,omitempty"`
+
+	// MaxDurationAction is taken when the analysis runs longer than the max duration (default Rollback)
+	// +optional
+	MaxDurationAction MaxDurationAction `json:"maxDurationAction,omitempty"`
+
+	// PrimaryReadyThreshold is the percentage of the primary pods that must be available
+	// for the primary workload to be considered ready (default 100)
+	// +optional
+	PrimaryReadyThreshold *int `json:"primaryReadyThreshold,omitempty"`
+
+	// CanaryReadyThreshold is the percentage of the canary pods that must be available
+	// for the canary workload to be considered ready (default 100)
+	// +optional
+	CanaryReadyThreshold *int `json:"canaryReadyThreshold,omitempty"`
+}
+
+// MaxDurationAction defines what happens to a canary that runs longer than the max analysis duration
+type MaxDurationAction string
+
+const (
+	// MaxDurationActionRollback rolls back the canary
+	MaxDurationActionRollback MaxDurationAction = "Rollback"
+	// MaxDurationActionPause holds the canary at the current step and alerts
+	// until the operator aborts or promotes it, or a new revision restarts the analysis
+	MaxDurationActionPause MaxDurationAction = "Pause"
+)
+
+// SessionAffinity holds the cookie settings used to keep the users on the same version
+type SessionAffinity struct {
+	// CookieName is the name of the cookie that holds the assigned version
+	CookieName string `json:"cookieName"`
+
+	// MaxAge is the number of seconds until the cookie expires,
+	// defaults to a session cookie
+	// +optional
+	MaxAge int `json:"maxAge,omitempty"`
 }
 
 // CanaryMetric holds the reference to metrics used for canary analysis
@@ -262,6 +517,11 @@ type CanaryMetric struct {
 	// TemplateRef references a metric template object
 	// +optional
 	TemplateRef *CrossNamespaceObjectReference `json:"templateRef,omitempty"`
+
+	// SecretRef references a secret in the canary namespace
+	// that overrides the provider credentials of the metric template
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
 }
 
 // CanaryThresholdRange defines the range used for metrics validation
@@ -284,6 +544,154 @@ const (
 	SeverityError AlertSeverity = "error"
 )
 
+// VirtualServiceRoute references a named HTTP route of an existing Istio virtual service
+type VirtualServiceRoute struct {
+	// Name of the virtual service in the canary namespace
+	Name string `json:"name"`
+
+	// Route is the name of the HTTP route managed by Flagger,
+	// the A/B testing route is named after it with the -canary suffix
+	Route string `json:"route"`
+}
+
+// PortDiscoveryRules selects the discovered container ports by name
+// and maps them to the ports of the generated services
+type PortDiscoveryRules struct {
+	// Include the ports with names matching the glob patterns, all the ports are included when empty
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude the ports with names matching the glob patterns
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// Mappings of the discovered container ports to service ports
+	// +optional
+	Mappings []PortMapping `json:"mappings,omitempty"`
+}
+
+// PortMapping exposes a discovered container port under another service port number or name
+type PortMapping struct {
+	// ContainerPort number or name of the discovered port
+	ContainerPort intstr.IntOrString `json:"containerPort"`
+
+	// Port number of the service, defaults to the container port
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Name of the service port, defaults to the container port name
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// AppProtocol of the service port, e.g. http, h2c or grpc
+	// +optional
+	AppProtocol string `json:"appProtocol,omitempty"`
+}
+
+// ServiceMonitor defines how the canary and primary services are scraped by the Prometheus Operator,
+// the samples are labeled with the version of the workload
+type ServiceMonitor struct {
+	// Port name of the metrics endpoint, defaults to the service port name
+	// +optional
+	Port string `json:"port,omitempty"`
+
+	// Path of the metrics endpoint, defaults to /metrics
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Interval at which the metrics are scraped
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// VersionLabel is the name of the label set to canary or primary, defaults to version
+	// +optional
+	VersionLabel string `json:"versionLabel,omitempty"`
+
+	// Labels added to the service monitors, e.g. to match the Prometheus service monitor selector
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// CanaryNetworkPolicy defines the sources allowed to reach the canary pods,
+// e.g. the ingress gateway and the load tester
+type CanaryNetworkPolicy struct {
+	// From lists the pods, namespaces or IP blocks allowed to reach the canary pods
+	From []networkingv1.NetworkPolicyPeer `json:"from"`
+}
+
+// GatewayRef binds hosts to Istio gateways with their own match conditions
+type GatewayRef struct {
+	// Gateways the routes are bound to, use mesh for the internal traffic
+	Gateways []string `json:"gateways"`
+
+	// Hosts exposed on the gateways
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+
+	// Port of the gateway listener matched by the routes
+	// +optional
+	Port uint32 `json:"port,omitempty"`
+
+	// Match conditions of the routes, defaults to the service match conditions
+	// +optional
+	Match []istiov1alpha3.HTTPMatchRequest `json:"match,omitempty"`
+}
+
+// CanaryPortWeight is the canary weight of the requests to a service port
+type CanaryPortWeight struct {
+	// Port of the apex service matched by the route
+	Port int32 `json:"port"`
+
+	// WeightPercent is the percentage of the analysis canary weight routed to the canary on this port
+	WeightPercent int `json:"weightPercent"`
+
+	// MaxWeight caps the canary weight of this port
+	// +optional
+	MaxWeight int `json:"maxWeight,omitempty"`
+}
+
+// Weight returns the canary weight of the port for the analysis canary weight
+func (pw CanaryPortWeight) Weight(canaryWeight int) int {
+	weight := canaryWeight * pw.WeightPercent / 100
+	if pw.MaxWeight > 0 && weight > pw.MaxWeight {
+		weight = pw.MaxWeight
+	}
+	return weight
+}
+
+// GlooRouteTableRoute references a named route of an existing Gloo route table
+type GlooRouteTableRoute struct {
+	// Name of the route table delegated by the Gloo virtual service
+	Name string `json:"name"`
+
+	// Namespace of the route table, defaults to the canary namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Route is the name of the route managed by Flagger,
+	// the A/B testing route is named after it with the -canary suffix
+	Route string `json:"route"`
+}
+
+// AppMeshGatewayRoute is the route of an App Mesh virtual gateway to the apex virtual service
+type AppMeshGatewayRoute struct {
+	// Labels of the gateway route, must match the gatewayRouteSelector of the virtual gateway
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Prefix of the request path, defaults to the canary service URI prefix or /
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Hostname of the requests, defaults to any hostname
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// Priority of the gateway route between 0 and 1000, defaults to the lowest priority
+	// +optional
+	Priority *int64 `json:"priority,omitempty"`
+}
+
 // CanaryAlert defines an alert for this canary
 type CanaryAlert struct {
 	// Name of the alert
@@ -294,6 +702,50 @@ type CanaryAlert struct {
 
 	// Alert provider reference
 	ProviderRef CrossNamespaceObjectReference `json:"providerRef"`
+
+	// SecretRef references a secret in the canary namespace
+	// that overrides the address and token of the alert provider
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// PolicyProvider evaluates the canary policy checks
+type PolicyProvider string
+
+const (
+	// AdmissionPolicyProvider dry-runs the canary pod through the admission controllers
+	// such as OPA Gatekeeper or Kyverno
+	AdmissionPolicyProvider PolicyProvider = "admission"
+	// OPAPolicyProvider queries the deny rules of an OPA server
+	OPAPolicyProvider PolicyProvider = "opa"
+)
+
+// CanaryPolicy holds a policy check of the canary pod spec,
+// a violation fails the canary before any traffic is routed to it
+type CanaryPolicy struct {
+	// Name of the policy check
+	Name string `json:"name"`
+
+	// Provider of the policy check, defaults to admission
+	// +optional
+	Provider PolicyProvider `json:"provider,omitempty"`
+
+	// Address of the OPA server
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// Rego module uploaded to the OPA server before the query
+	// +optional
+	Rego string `json:"rego,omitempty"`
+
+	// Path of the OPA rule returning the violations,
+	// defaults to the deny rule of the Rego module package
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Timeout of the policy check
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
 }
 
 // HookType can be pre, post or during rollout
@@ -335,8 +787,63 @@ type CanaryWebhook struct {
 	// Metadata (key-value pairs) for this webhook
 	// +optional
 	Metadata *map[string]string `json:"metadata,omitempty"`
+
+	// TLS verification of the webhook server, requires a https URL
+	// +optional
+	TLS *CanaryWebhookTLS `json:"tls,omitempty"`
+
+	// SigningSecretRef references a secret in the canary namespace with the signingKey
+	// used to sign the payload with HMAC-SHA256
+	// +optional
+	SigningSecretRef *corev1.LocalObjectReference `json:"signingSecretRef,omitempty"`
+
+	// MetadataFrom sets metadata values from secrets in the canary namespace,
+	// the secrets are read on each call
+	// +optional
+	MetadataFrom []CanaryWebhookMetadataSource `json:"metadataFrom,omitempty"`
 }
 
+// CanaryWebhookMetadataSource sets a webhook metadata value from a secret key
+type CanaryWebhookMetadataSource struct {
+	// Name of the metadata key
+	Name string `json:"name"`
+
+	// SecretKeyRef selects the secret key holding the value
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+}
+
+// CanaryWebhookTLS holds the identities accepted for the webhook server
+type CanaryWebhookTLS struct {
+	// SubjectAltNames accepted for the webhook server certificate, the DNS names or URIs
+	// such as SPIFFE IDs, the server name is verified when the list is empty
+	// +optional
+	SubjectAltNames []string `json:"subjectAltNames,omitempty"`
+}
+
+// ApproverHeader is set by the confirm webhooks with the identity of who opened the gate
+const ApproverHeader = "X-Flagger-Approver"
+
+// ApprovalSourceHeader is set by the confirm webhooks with the source of the approver identity,
+// the source defaults to webhook
+const ApprovalSourceHeader = "X-Flagger-Approval-Source"
+
+// ApproverWithClaim returns the approver identity recorded for a gate, the authenticated identity
+// followed by the user name claimed by the client that couldn't be verified
+func ApproverWithClaim(identity string, claim string) string {
+	switch {
+	case claim == "" || claim == identity:
+		return identity
+	case identity == "":
+		return fmt.Sprintf("unverified: %s", claim)
+	default:
+		return fmt.Sprintf("%s (unverified: %s)", identity, claim)
+	}
+}
+
+// ArtifactURLHeader is set by the webhooks with the URL of the raw output of the task they ran,
+// such as a load test report
+const ArtifactURLHeader = "X-Flagger-Artifact-URL"
+
 // CanaryWebhookPayload holds the deployment info and metadata sent to webhooks
 type CanaryWebhookPayload struct {
 	// Name of the canary
@@ -348,6 +855,9 @@ type CanaryWebhookPayload struct {
 	// Phase of the canary analysis
 	Phase CanaryPhase `json:"phase"`
 
+	// RunID of the canary analysis
+	RunID string `json:"runID,omitempty"`
+
 	// Metadata (key-value pairs) for this webhook
 	Metadata map[string]string `json:"metadata,omitempty"`
 }
@@ -377,17 +887,35 @@ type CustomMetadata struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
-// GetServiceNames returns the apex, primary and canary Kubernetes service names
-func (c *Canary) GetServiceNames() (apexName, primaryName, canaryName string) {
+// GetServiceNames returns the apex, primary and canary Kubernetes service names,
+// the primary suffix applies to the canaries that don't set spec.primarySuffix
+func (c *Canary) GetServiceNames(primarySuffix string) (apexName, primaryName, canaryName string) {
 	apexName = c.Spec.TargetRef.Name
 	if c.Spec.Service.Name != "" {
 		apexName = c.Spec.Service.Name
 	}
-	primaryName = fmt.Sprintf("%s-primary", apexName)
+	primaryName = c.GetPrimaryName(apexName, primarySuffix)
 	canaryName = fmt.Sprintf("%s-canary", apexName)
 	return
 }
 
+// GetPrimarySuffix returns the suffix of the primary objects, spec.primarySuffix takes precedence
+// over the suffix configured for the controller (default -primary)
+func (c *Canary) GetPrimarySuffix(primarySuffix string) string {
+	if c.Spec.PrimarySuffix != "" {
+		return c.Spec.PrimarySuffix
+	}
+	if primarySuffix != "" {
+		return primarySuffix
+	}
+	return DefaultPrimarySuffix
+}
+
+// GetPrimaryName returns the name or the label value of the primary copy of an object
+func (c *Canary) GetPrimaryName(name string, primarySuffix string) string {
+	return name + c.GetPrimarySuffix(primarySuffix)
+}
+
 // GetProgressDeadlineSeconds returns the progress deadline (default 600s)
 func (c *Canary) GetProgressDeadlineSeconds() int {
 	if c.Spec.ProgressDeadlineSeconds != nil {
@@ -408,13 +936,25 @@ func (c *Canary) GetAnalysis() *CanaryAnalysis {
 
 // GetAnalysisInterval returns the canary analysis interval (default 60s)
 func (c *Canary) GetAnalysisInterval() time.Duration {
+	return c.GetAnalysisIntervalOrDefault(AnalysisInterval)
+}
+
+// GetAnalysisIntervalOrDefault returns the canary analysis interval,
+// the default interval is used when the canary doesn't set a valid one
+func (c *Canary) GetAnalysisIntervalOrDefault(defaultInterval time.Duration) time.Duration {
+	if defaultInterval <= 0 {
+		defaultInterval = AnalysisInterval
+	}
+	if defaultInterval < 10*time.Second {
+		defaultInterval = 10 * time.Second
+	}
 	if c.GetAnalysis().Interval == "" {
-		return AnalysisInterval
+		return defaultInterval
 	}
 
 	interval, err := time.ParseDuration(c.GetAnalysis().Interval)
 	if err != nil {
-		return AnalysisInterval
+		return defaultInterval
 	}
 
 	if interval < 10*time.Second {
@@ -424,6 +964,104 @@ func (c *Canary) GetAnalysisInterval() time.Duration {
 	return interval
 }
 
+// GetRevertPolicy returns the revert policy of the finalizer (default Restore)
+func (c *Canary) GetRevertPolicy() RevertPolicy {
+	if c.Spec.RevertPolicy == "" {
+		return RevertPolicyRestore
+	}
+	return c.Spec.RevertPolicy
+}
+
+// GetTargetNamespace returns the namespace of the target, defaults to the canary namespace
+func (c *Canary) GetTargetNamespace() string {
+	if c.Spec.TargetRef.Namespace != "" {
+		return c.Spec.TargetRef.Namespace
+	}
+	return c.Namespace
+}
+
+// GetOwnerReferences returns the owner references of the objects generated in the target namespace,
+// the objects are not owned by the canary when the target is in another namespace
+// since Kubernetes doesn't allow cross-namespace owner references
+func (c *Canary) GetOwnerReferences() []metav1.OwnerReference {
+	if c.GetTargetNamespace() != c.Namespace {
+		return nil
+	}
+	return []metav1.OwnerReference{
+		*metav1.NewControllerRef(c, SchemeGroupVersion.WithKind(CanaryKind)),
+	}
+}
+
+// GetOwnerAnnotations returns a copy of the annotations of a generated object,
+// with the owner annotation when the target is in another namespace than the canary
+func (c *Canary) GetOwnerAnnotations(annotations map[string]string) map[string]string {
+	if c.GetTargetNamespace() == c.Namespace {
+		return annotations
+	}
+	res := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		res[k] = v
+	}
+	res[CanaryOwnerAnnotation] = fmt.Sprintf("%s/%s", c.Namespace, c.Name)
+	return res
+}
+
+// IsOwnerOf returns true if the object was generated for the canary,
+// either with an owner reference or with the owner annotation
+func (c *Canary) IsOwnerOf(obj metav1.Object) bool {
+	if obj.GetAnnotations()[CanaryOwnerAnnotation] == fmt.Sprintf("%s/%s", c.Namespace, c.Name) {
+		return true
+	}
+	ref := metav1.GetControllerOf(obj)
+	return ref != nil && ref.Kind == CanaryKind && ref.Name == c.Name
+}
+
+// GetTargetKind returns the kind of the controller that manages the target,
+// the OpenKruise DaemonSet has the same kind as the apps/v1 one and is told apart by the API group,
+// the other custom resources are managed through their scale subresource
+func (c *Canary) GetTargetKind() string {
+	return TargetKind(c.Spec.TargetRef)
+}
+
+// TargetKind returns the kind of the controller that manages the referenced workload
+func TargetKind(ref CrossNamespaceObjectReference) string {
+	if ref.Kind == "DaemonSet" && strings.HasPrefix(ref.APIVersion, kruise.GroupName+"/") {
+		return KruiseDaemonSetKind
+	}
+	if ref.Kind == "Service" && strings.HasPrefix(ref.APIVersion, KnativeServingGroup+"/") {
+		return KnativeServiceKind
+	}
+	if i := strings.Index(ref.APIVersion, "/"); i > 0 {
+		switch ref.APIVersion[:i] {
+		case "core", "apps", "extensions":
+		default:
+			return ScaleSubresourceKind
+		}
+	}
+	return ref.Kind
+}
+
+// GetAnalysisMaxDuration returns the max duration of the analysis, zero when not set
+func (c *Canary) GetAnalysisMaxDuration() time.Duration {
+	if c.GetAnalysis() == nil || c.GetAnalysis().MaxDuration == "" {
+		return 0
+	}
+
+	duration, err := time.ParseDuration(c.GetAnalysis().MaxDuration)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
+
+// GetAnalysisMaxDurationAction returns the action taken after the max duration (default Rollback)
+func (c *Canary) GetAnalysisMaxDurationAction() MaxDurationAction {
+	if c.GetAnalysis() == nil || c.GetAnalysis().MaxDurationAction == "" {
+		return MaxDurationActionRollback
+	}
+	return c.GetAnalysis().MaxDurationAction
+}
+
 // GetAnalysisThreshold returns the canary threshold (default 1)
 func (c *Canary) GetAnalysisThreshold() int {
 	if c.GetAnalysis().Threshold > 0 {
@@ -432,6 +1070,32 @@ func (c *Canary) GetAnalysisThreshold() int {
 	return 1
 }
 
+// GetAnalysisPrimaryReadyThreshold returns the percentage of the primary pods
+// that must be available (default 100)
+func (c *Canary) GetAnalysisPrimaryReadyThreshold() int {
+	if c.GetAnalysis() != nil && c.GetAnalysis().PrimaryReadyThreshold != nil {
+		return *c.GetAnalysis().PrimaryReadyThreshold
+	}
+	return ReadyThreshold
+}
+
+// GetAnalysisCanaryReadyThreshold returns the percentage of the canary pods
+// that must be available (default 100)
+func (c *Canary) GetAnalysisCanaryReadyThreshold() int {
+	if c.GetAnalysis() != nil && c.GetAnalysis().CanaryReadyThreshold != nil {
+		return *c.GetAnalysis().CanaryReadyThreshold
+	}
+	return ReadyThreshold
+}
+
+// GetAnalysisTotalWeight returns the total traffic weight (default 100)
+func (c *Canary) GetAnalysisTotalWeight() int {
+	if c.GetAnalysis() != nil && c.GetAnalysis().TotalWeight > 0 {
+		return c.GetAnalysis().TotalWeight
+	}
+	return TotalWeight
+}
+
 // GetMetricInterval returns the metric interval default value (1m)
 func (c *Canary) GetMetricInterval() string {
 	return MetricInterval
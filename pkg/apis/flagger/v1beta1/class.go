@@ -0,0 +1,168 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CanaryClassKind = "CanaryClass"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryClass holds the cluster wide defaults of the canaries that reference it by className
+type CanaryClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CanaryClassSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryClassList is a list of canary class resources
+type CanaryClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []CanaryClass `json:"items"`
+}
+
+// CanaryClassSpec is the specification of the defaults applied to the canaries of the class
+type CanaryClassSpec struct {
+	// Provider of the canaries that don't set one
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// MetricsServer of the canaries that don't set one
+	// +optional
+	MetricsServer string `json:"metricsServer,omitempty"`
+
+	// Analysis defaults, the interval, threshold and traffic steps are used when the canary doesn't set them,
+	// the metrics, webhooks and alerts are added to those of the canary unless it defines one with the same name
+	// +optional
+	Analysis *CanaryAnalysis `json:"analysis,omitempty"`
+}
+
+// ApplyTo returns a copy of the canary with the fields it doesn't set filled with the class defaults,
+// the class metrics, webhooks and alerts are added when the canary doesn't define one with the same name
+func (class *CanaryClass) ApplyTo(cd *Canary) *Canary {
+	cd = cd.DeepCopy()
+	if cd.Spec.Provider == "" {
+		cd.Spec.Provider = class.Spec.Provider
+	}
+	if cd.Spec.MetricsServer == "" {
+		cd.Spec.MetricsServer = class.Spec.MetricsServer
+	}
+
+	defaults := class.Spec.Analysis
+	if defaults == nil {
+		return cd
+	}
+	analysis := cd.GetAnalysis()
+	if analysis == nil {
+		cd.Spec.Analysis = defaults.DeepCopy()
+		return cd
+	}
+
+	if analysis.Interval == "" {
+		analysis.Interval = defaults.Interval
+	}
+	if analysis.Threshold == 0 {
+		analysis.Threshold = defaults.Threshold
+	}
+	if analysis.SoakIterations == 0 {
+		analysis.SoakIterations = defaults.SoakIterations
+	}
+	if analysis.PrimaryReadyThreshold == nil && defaults.PrimaryReadyThreshold != nil {
+		threshold := *defaults.PrimaryReadyThreshold
+		analysis.PrimaryReadyThreshold = &threshold
+	}
+	if analysis.CanaryReadyThreshold == nil && defaults.CanaryReadyThreshold != nil {
+		threshold := *defaults.CanaryReadyThreshold
+		analysis.CanaryReadyThreshold = &threshold
+	}
+	if analysis.MaxDuration == "" {
+		analysis.MaxDuration = defaults.MaxDuration
+		if analysis.MaxDurationAction == "" {
+			analysis.MaxDurationAction = defaults.MaxDurationAction
+		}
+	}
+
+	// the traffic steps are inherited as a whole so that the rollout strategy isn't mixed
+	if analysis.Iterations == 0 && analysis.StepWeight == 0 && len(analysis.StepWeights) == 0 && analysis.MaxWeight == 0 {
+		analysis.Iterations = defaults.Iterations
+		analysis.StepWeight = defaults.StepWeight
+		analysis.StepWeights = append([]int(nil), defaults.StepWeights...)
+		analysis.MaxWeight = defaults.MaxWeight
+		if analysis.StepWeightPromotion == 0 {
+			analysis.StepWeightPromotion = defaults.StepWeightPromotion
+		}
+		if !analysis.Mirror {
+			analysis.Mirror = defaults.Mirror
+			analysis.MirrorWeight = defaults.MirrorWeight
+		}
+	}
+
+	for _, metric := range defaults.Metrics {
+		if !hasMetric(analysis.Metrics, metric.Name) {
+			analysis.Metrics = append(analysis.Metrics, *metric.DeepCopy())
+		}
+	}
+	for _, webhook := range defaults.Webhooks {
+		if !hasWebhook(analysis.Webhooks, webhook.Name) {
+			analysis.Webhooks = append(analysis.Webhooks, *webhook.DeepCopy())
+		}
+	}
+	for _, alert := range defaults.Alerts {
+		if !hasAlert(analysis.Alerts, alert.Name) {
+			analysis.Alerts = append(analysis.Alerts, *alert.DeepCopy())
+		}
+	}
+	return cd
+}
+
+func hasMetric(metrics []CanaryMetric, name string) bool {
+	for _, m := range metrics {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasWebhook(webhooks []CanaryWebhook, name string) bool {
+	for _, w := range webhooks {
+		if w.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAlert(alerts []CanaryAlert, name string) bool {
+	for _, a := range alerts {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
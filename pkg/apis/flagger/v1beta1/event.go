@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// EventReason is a stable machine-readable code set as the reason of the
+// Kubernetes events and in the event webhook and alert payloads
+type EventReason string
+
+const (
+	// ReasonInitialized is used when the primary workload has been created
+	ReasonInitialized EventReason = "Initialized"
+	// ReasonNewRevisionDetected is used when a change of the target workload triggers a new analysis
+	ReasonNewRevisionDetected EventReason = "NewRevisionDetected"
+	// ReasonAnalysisStarted is used when the canary analysis begins
+	ReasonAnalysisStarted EventReason = "AnalysisStarted"
+	// ReasonAnalysisSkipped is used when the canary is promoted without analysis
+	ReasonAnalysisSkipped EventReason = "AnalysisSkipped"
+	// ReasonUnsupportedAnalysis is used when the analysis type is not supported by the provider
+	ReasonUnsupportedAnalysis EventReason = "UnsupportedAnalysis"
+	// ReasonAdvanced is used when the canary weight or iteration is advanced
+	ReasonAdvanced EventReason = "Advanced"
+	// ReasonPromoting is used when the canary spec is copied to the primary and traffic is routed to primary
+	ReasonPromoting EventReason = "Promoting"
+	// ReasonPromoted is used when the promotion has finished
+	ReasonPromoted EventReason = "Promoted"
+	// ReasonFailed is used when the canary has been rolled back
+	ReasonFailed EventReason = "Failed"
+	// ReasonTerminating is used when the canary finalizer reverts the target and routing
+	ReasonTerminating EventReason = "Terminating"
+	// ReasonTerminated is used when the canary finalizer has completed
+	ReasonTerminated EventReason = "Terminated"
+	// ReasonTeardown is used to report what the finalizer restored, kept or deleted
+	ReasonTeardown EventReason = "Teardown"
+	// ReasonTeardownPreview is used to report what the finalizer would change on deletion
+	ReasonTeardownPreview EventReason = "TeardownPreview"
+	// ReasonDetached is used when the finalizer is removed after too many failed cleanup attempts
+	ReasonDetached EventReason = "Detached"
+	// ReasonSuspended is used when the canary analysis is held by spec.suspend
+	ReasonSuspended EventReason = "Suspended"
+	// ReasonResumed is used when the canary analysis continues after being suspended
+	ReasonResumed EventReason = "Resumed"
+	// ReasonSyncFailed is used when reconciling the workloads or the routing objects fails
+	ReasonSyncFailed EventReason = "SyncFailed"
+
+	// ReasonProgressDeadlineExceeded is used when the canary or primary did not become ready in time
+	ReasonProgressDeadlineExceeded EventReason = "ProgressDeadlineExceeded"
+	// ReasonFailedChecksThresholdReached is used when the number of failed checks reaches the analysis threshold
+	ReasonFailedChecksThresholdReached EventReason = "FailedChecksThresholdReached"
+	// ReasonManualRollback is used when a rollback webhook signals a rollback
+	ReasonManualRollback EventReason = "ManualRollback"
+
+	// ReasonMetricProvidersAvailable is used when all the metric providers are reachable
+	ReasonMetricProvidersAvailable EventReason = "MetricProvidersAvailable"
+	// ReasonMetricProviderUnavailable is used when a metric provider can't be reached or created
+	ReasonMetricProviderUnavailable EventReason = "MetricProviderUnavailable"
+	// ReasonMetricTemplateInvalid is used when a metric template can't be found, read or rendered
+	ReasonMetricTemplateInvalid EventReason = "MetricTemplateInvalid"
+	// ReasonMetricQueryFailed is used when a metric query returns an error
+	ReasonMetricQueryFailed EventReason = "MetricQueryFailed"
+	// ReasonMetricNoValuesFound is used when a metric query returns no values
+	ReasonMetricNoValuesFound EventReason = "MetricNoValuesFound"
+	// ReasonMetricThresholdBreached is used when a metric value is outside the threshold range
+	ReasonMetricThresholdBreached EventReason = "MetricThresholdBreached"
+
+	// ReasonStepSkipped is used when an operator skips the analysis of the current step
+	ReasonStepSkipped EventReason = "StepSkipped"
+	// ReasonPromotionForced is used when an operator skips the remaining steps and promotes the canary
+	ReasonPromotionForced EventReason = "PromotionForced"
+	// ReasonAborted is used when an operator aborts the analysis and the canary is rolled back
+	ReasonAborted EventReason = "Aborted"
+	// ReasonRerunRequested is used when the analysis of the current revision is requested by the operator
+	ReasonRerunRequested EventReason = "RerunRequested"
+	// ReasonActionIgnored is used when an operator action is invalid or can't be applied in the current phase
+	ReasonActionIgnored EventReason = "ActionIgnored"
+
+	// ReasonWebhookPassed is used when a webhook check succeeds
+	ReasonWebhookPassed EventReason = "WebhookPassed"
+	// ReasonWebhookFailed is used when a webhook check returns an error
+	ReasonWebhookFailed EventReason = "WebhookFailed"
+	// ReasonWebhookTimeout is used when a webhook check didn't respond within its timeout
+	ReasonWebhookTimeout EventReason = "WebhookTimeout"
+	// ReasonWaitingForApproval is used when a confirm webhook halts the advancement
+	ReasonWaitingForApproval EventReason = "WaitingForApproval"
+	// ReasonApproved is used when a confirm webhook gate is opened, the event records the approver
+	ReasonApproved EventReason = "Approved"
+	// ReasonWaitingForWave is used when a canary waits for the previous canaries of its wave to be promoted
+	ReasonWaitingForWave EventReason = "WaitingForWave"
+
+	// ReasonPolicyPassed is used when the canary pod spec passes a policy check
+	ReasonPolicyPassed EventReason = "PolicyPassed"
+	// ReasonPolicyViolation is used when the canary pod spec violates a policy
+	ReasonPolicyViolation EventReason = "PolicyViolation"
+	// ReasonPolicyCheckFailed is used when a policy check can't be evaluated
+	ReasonPolicyCheckFailed EventReason = "PolicyCheckFailed"
+
+	// ReasonMaxDurationExceeded is used when the analysis runs longer than its max duration
+	ReasonMaxDurationExceeded EventReason = "MaxDurationExceeded"
+
+	// ReasonFluxSuspended is used when the Flux owner of the target is suspended for the analysis
+	ReasonFluxSuspended EventReason = "FluxSuspended"
+	// ReasonFluxResumed is used when the Flux owner of the target is resumed after the analysis
+	ReasonFluxResumed EventReason = "FluxResumed"
+)
@@ -11,4 +11,6 @@ const (
 	KubernetesProvider string = "kubernetes"
 	SkipperProvider    string = "skipper"
 	TraefikProvider    string = "traefik"
+	GatewayAPIProvider string = "gatewayapi"
+	KnativeProvider    string = "knative"
 )
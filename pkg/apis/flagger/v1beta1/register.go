@@ -51,6 +51,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&MetricTemplateList{},
 		&AlertProvider{},
 		&AlertProviderList{},
+		&CanaryWave{},
+		&CanaryWaveList{},
+		&CanaryClass{},
+		&CanaryClassList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -11,6 +11,11 @@ type CanaryConditionType string
 const (
 	// PromotedType refers to the result of the last canary analysis
 	PromotedType CanaryConditionType = "Promoted"
+	// SuspendedType refers to the suspension of the canary analysis
+	SuspendedType CanaryConditionType = "Suspended"
+	// PromotionCompleteType refers to the completion of the canary analysis,
+	// it's true once the primary runs the promoted revision and the canary is scaled to zero
+	PromotionCompleteType CanaryConditionType = "PromotionComplete"
 )
 
 // CanaryCondition is a status condition for a Canary
@@ -75,12 +80,161 @@ type CanaryStatus struct {
 	Iterations   int         `json:"iterations"`
 	// +optional
 	TrackedConfigs *map[string]string `json:"trackedConfigs,omitempty"`
+	// TrackedTargets holds the spec hash of each workload listed in the canary targetRefs
+	// +optional
+	TrackedTargets *map[string]string `json:"trackedTargets,omitempty"`
+	// OriginalTargets holds the state of the workloads before Flagger scaled them
+	// or held their rolling update, keyed by kind/name
+	// +optional
+	OriginalTargets map[string]CanaryTargetState `json:"originalTargets,omitempty"`
 	// +optional
 	LastAppliedSpec string `json:"lastAppliedSpec,omitempty"`
 	// +optional
 	LastPromotedSpec string `json:"lastPromotedSpec,omitempty"`
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// CompletionTime is when the last analysis completed, it's kept while the phase
+	// doesn't change and cleared when a new analysis starts
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// StartTime is when the analysis of the current run started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// RunID identifies the analysis of the current revision,
+	// it's set when a new revision is detected
+	// +optional
+	RunID string `json:"runID,omitempty"`
 	// +optional
 	Conditions []CanaryCondition `json:"conditions,omitempty"`
+	// Metrics are the results of the last checks of the analysis metrics
+	// +optional
+	Metrics []CanaryMetricStatus `json:"metrics,omitempty"`
+	// Webhooks are the results of the last calls of the analysis webhooks
+	// +optional
+	Webhooks []CanaryWebhookStatus `json:"webhooks,omitempty"`
+	// Approvals of the confirm webhooks in the current run
+	// +optional
+	Approvals []CanaryApproval `json:"approvals,omitempty"`
+	// History of the last significant events of the canary, the oldest first
+	// +optional
+	History []CanaryHistoryEntry `json:"history,omitempty"`
+}
+
+// CanaryTargetState is the state of a workload recorded before Flagger changes it,
+// it's used to restore the workload when it's scaled from zero or finalized
+type CanaryTargetState struct {
+	// Replicas of the workload before it was scaled to zero
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// UpdateStrategy type of the workload before its rolling update was held
+	// +optional
+	UpdateStrategy string `json:"updateStrategy,omitempty"`
+
+	// Partition of the rolling update before it was held
+	// +optional
+	Partition *int32 `json:"partition,omitempty"`
+}
+
+// CanaryHistoryEntry is a significant event of the canary kept in the status,
+// such as the phase changes, the rollbacks and the approvals
+type CanaryHistoryEntry struct {
+	// Type of the event, Normal or Warning
+	Type string `json:"type"`
+
+	// Reason of the event
+	Reason EventReason `json:"reason"`
+
+	// Message of the event
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// RunID of the analysis the event belongs to
+	// +optional
+	RunID string `json:"runID,omitempty"`
+
+	// Time of the event
+	Time metav1.Time `json:"time,omitempty"`
+}
+
+// ApprovalSource is where the approver identity was read from
+type ApprovalSource string
+
+const (
+	// ApprovalSourceWebhook is used when the confirm webhook returned the approver
+	ApprovalSourceWebhook ApprovalSource = "webhook"
+	// ApprovalSourceAnnotation is used when the approver is the field manager of the gate annotation
+	ApprovalSourceAnnotation ApprovalSource = "annotation"
+	// ApprovalSourceAPI is used when the approver is the caller of the gate API
+	ApprovalSourceAPI ApprovalSource = "api"
+)
+
+// CanaryApproval records who opened a confirm webhook gate
+type CanaryApproval struct {
+	// Name of the confirm webhook
+	Webhook string `json:"webhook"`
+
+	// Type of the confirm webhook
+	Type HookType `json:"type"`
+
+	// Approver identity, unknown when the webhook didn't return one
+	Approver string `json:"approver"`
+
+	// Source of the approver identity
+	// +optional
+	Source ApprovalSource `json:"source,omitempty"`
+
+	// RunID of the approved analysis
+	// +optional
+	RunID string `json:"runID,omitempty"`
+
+	// ApprovalTime is when the gate was first found open
+	ApprovalTime metav1.Time `json:"approvalTime,omitempty"`
+}
+
+// CanaryMetricStatus is the result of the last check of a metric
+type CanaryMetricStatus struct {
+	// Name of the metric
+	Name string `json:"name"`
+
+	// Value returned by the metric query, empty when the query failed
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// Threshold is the range of the accepted values
+	// +optional
+	Threshold string `json:"threshold,omitempty"`
+
+	// Passed is true when the value is within the threshold range
+	Passed bool `json:"passed"`
+
+	// Message describes why the check failed
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastCheckTime of this metric
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+// CanaryWebhookStatus is the result of the last call of a webhook
+type CanaryWebhookStatus struct {
+	// Name of the webhook
+	Name string `json:"name"`
+
+	// Type of the webhook
+	Type HookType `json:"type,omitempty"`
+
+	// Passed is true when the webhook returned a HTTP 2xx response
+	Passed bool `json:"passed"`
+
+	// Message describes why the call failed
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// ArtifactURL is the address of the raw output of the task run by the webhook
+	// +optional
+	ArtifactURL string `json:"artifactURL,omitempty"`
+
+	// LastCallTime of this webhook
+	LastCallTime metav1.Time `json:"lastCallTime,omitempty"`
 }
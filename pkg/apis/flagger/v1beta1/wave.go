@@ -0,0 +1,95 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CanaryWaveKind = "CanaryWave"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryWave advances the same release across an ordered list of canaries,
+// usually one canary per target cluster
+type CanaryWave struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CanaryWaveSpec   `json:"spec"`
+	Status CanaryWaveStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryWaveList is a list of canary wave resources
+type CanaryWaveList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []CanaryWave `json:"items"`
+}
+
+// CanaryWaveSpec is the specification of the desired behavior of the CanaryWave
+type CanaryWaveSpec struct {
+	// Canaries in the wave namespace ordered by rollout,
+	// the analysis of a canary starts only after all the previous canaries are promoted
+	Canaries []corev1.LocalObjectReference `json:"canaries"`
+
+	// Abort rolls back the canary under analysis and holds the next canaries
+	// +optional
+	Abort bool `json:"abort,omitempty"`
+}
+
+// CanaryWaveStatus is the aggregated status of the canaries of a wave
+type CanaryWaveStatus struct {
+	// Phase of the wave, it's progressing while one of the canaries is under analysis
+	Phase CanaryPhase `json:"phase"`
+
+	// Current is the name of the canary under analysis or waiting for the previous ones
+	// +optional
+	Current string `json:"current,omitempty"`
+
+	// Canaries are the statuses of the wave canaries in the rollout order
+	// +optional
+	Canaries []CanaryWaveMemberStatus `json:"canaries,omitempty"`
+
+	// LastTransitionTime of the wave phase
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// CanaryWaveMemberStatus is the status of a canary of a wave
+type CanaryWaveMemberStatus struct {
+	// Name of the canary
+	Name string `json:"name"`
+
+	// Cluster that runs the canary target, empty for the local cluster
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+
+	// Phase of the canary
+	// +optional
+	Phase CanaryPhase `json:"phase,omitempty"`
+
+	// CanaryWeight of the canary
+	// +optional
+	CanaryWeight int `json:"canaryWeight,omitempty"`
+}
@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 /*
@@ -23,6 +24,7 @@ package v1beta1
 import (
 	v1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -113,6 +115,16 @@ func (in *AlertProviderSpec) DeepCopyInto(out *AlertProviderSpec) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.AddressSecretKeyRef != nil {
+		in, out := &in.AddressSecretKeyRef, &out.AddressSecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TokenSecretKeyRef != nil {
+		in, out := &in.TokenSecretKeyRef, &out.TokenSecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -149,6 +161,34 @@ func (in *AlertProviderStatus) DeepCopy() *AlertProviderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppMeshGatewayRoute) DeepCopyInto(out *AppMeshGatewayRoute) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppMeshGatewayRoute.
+func (in *AppMeshGatewayRoute) DeepCopy() *AppMeshGatewayRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(AppMeshGatewayRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Canary) DeepCopyInto(out *Canary) {
 	*out = *in
@@ -181,6 +221,11 @@ func (in *Canary) DeepCopyObject() runtime.Object {
 func (in *CanaryAlert) DeepCopyInto(out *CanaryAlert) {
 	*out = *in
 	out.ProviderRef = in.ProviderRef
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	return
 }
 
@@ -205,7 +250,9 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 	if in.Alerts != nil {
 		in, out := &in.Alerts, &out.Alerts
 		*out = make([]CanaryAlert, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Metrics != nil {
 		in, out := &in.Metrics, &out.Metrics
@@ -221,6 +268,11 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]CanaryPolicy, len(*in))
+		copy(*out, *in)
+	}
 	if in.Match != nil {
 		in, out := &in.Match, &out.Match
 		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
@@ -228,6 +280,21 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SessionAffinity != nil {
+		in, out := &in.SessionAffinity, &out.SessionAffinity
+		*out = new(SessionAffinity)
+		**out = **in
+	}
+	if in.PrimaryReadyThreshold != nil {
+		in, out := &in.PrimaryReadyThreshold, &out.PrimaryReadyThreshold
+		*out = new(int)
+		**out = **in
+	}
+	if in.CanaryReadyThreshold != nil {
+		in, out := &in.CanaryReadyThreshold, &out.CanaryReadyThreshold
+		*out = new(int)
+		**out = **in
+	}
 	return
 }
 
@@ -241,6 +308,104 @@ func (in *CanaryAnalysis) DeepCopy() *CanaryAnalysis {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryApproval) DeepCopyInto(out *CanaryApproval) {
+	*out = *in
+	in.ApprovalTime.DeepCopyInto(&out.ApprovalTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryApproval.
+func (in *CanaryApproval) DeepCopy() *CanaryApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryClass) DeepCopyInto(out *CanaryClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryClass.
+func (in *CanaryClass) DeepCopy() *CanaryClass {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryClassList) DeepCopyInto(out *CanaryClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CanaryClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryClassList.
+func (in *CanaryClassList) DeepCopy() *CanaryClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryClassSpec) DeepCopyInto(out *CanaryClassSpec) {
+	*out = *in
+	if in.Analysis != nil {
+		in, out := &in.Analysis, &out.Analysis
+		*out = new(CanaryAnalysis)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryClassSpec.
+func (in *CanaryClassSpec) DeepCopy() *CanaryClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryCondition) DeepCopyInto(out *CanaryCondition) {
 	*out = *in
@@ -259,6 +424,23 @@ func (in *CanaryCondition) DeepCopy() *CanaryCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryHistoryEntry) DeepCopyInto(out *CanaryHistoryEntry) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryHistoryEntry.
+func (in *CanaryHistoryEntry) DeepCopy() *CanaryHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryList) DeepCopyInto(out *CanaryList) {
 	*out = *in
@@ -305,6 +487,11 @@ func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
 		*out = new(CrossNamespaceObjectReference)
 		**out = **in
 	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	return
 }
 
@@ -318,10 +505,140 @@ func (in *CanaryMetric) DeepCopy() *CanaryMetric {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricStatus) DeepCopyInto(out *CanaryMetricStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricStatus.
+func (in *CanaryMetricStatus) DeepCopy() *CanaryMetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryNetworkPolicy) DeepCopyInto(out *CanaryNetworkPolicy) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryNetworkPolicy.
+func (in *CanaryNetworkPolicy) DeepCopy() *CanaryNetworkPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryNetworkPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryPolicy) DeepCopyInto(out *CanaryPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPolicy.
+func (in *CanaryPolicy) DeepCopy() *CanaryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryPortWeight) DeepCopyInto(out *CanaryPortWeight) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPortWeight.
+func (in *CanaryPortWeight) DeepCopy() *CanaryPortWeight {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryPortWeight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryPropagation) DeepCopyInto(out *CanaryPropagation) {
+	*out = *in
+	if in.PropagationPolicyRef != nil {
+		in, out := &in.PropagationPolicyRef, &out.PropagationPolicyRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.OverridePolicyRef != nil {
+		in, out := &in.OverridePolicyRef, &out.OverridePolicyRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPropagation.
+func (in *CanaryPropagation) DeepCopy() *CanaryPropagation {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryPropagation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryRemoteCluster) DeepCopyInto(out *CanaryRemoteCluster) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRemoteCluster.
+func (in *CanaryRemoteCluster) DeepCopy() *CanaryRemoteCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryRemoteCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 	*out = *in
 	out.TargetPort = in.TargetPort
+	if in.PortDiscoveryRules != nil {
+		in, out := &in.PortDiscoveryRules, &out.PortDiscoveryRules
+		*out = new(PortDiscoveryRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceMonitor != nil {
+		in, out := &in.ServiceMonitor, &out.ServiceMonitor
+		*out = new(ServiceMonitor)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(CanaryNetworkPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Gateways != nil {
 		in, out := &in.Gateways, &out.Gateways
 		*out = make([]string, len(*in))
@@ -332,6 +649,23 @@ func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.GatewayRefs != nil {
+		in, out := &in.GatewayRefs, &out.GatewayRefs
+		*out = make([]GatewayRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PortWeights != nil {
+		in, out := &in.PortWeights, &out.PortWeights
+		*out = make([]CanaryPortWeight, len(*in))
+		copy(*out, *in)
+	}
+	if in.VirtualService != nil {
+		in, out := &in.VirtualService, &out.VirtualService
+		*out = new(VirtualServiceRoute)
+		**out = **in
+	}
 	if in.TrafficPolicy != nil {
 		in, out := &in.TrafficPolicy, &out.TrafficPolicy
 		*out = new(v1alpha3.TrafficPolicy)
@@ -369,6 +703,16 @@ func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.GatewayRoute != nil {
+		in, out := &in.GatewayRoute, &out.GatewayRoute
+		*out = new(AppMeshGatewayRoute)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RouteTable != nil {
+		in, out := &in.RouteTable, &out.RouteTable
+		*out = new(GlooRouteTableRoute)
+		**out = **in
+	}
 	if in.Apex != nil {
 		in, out := &in.Apex, &out.Apex
 		*out = new(CustomMetadata)
@@ -401,6 +745,16 @@ func (in *CanaryService) DeepCopy() *CanaryService {
 func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 	*out = *in
 	out.TargetRef = in.TargetRef
+	if in.TargetRefs != nil {
+		in, out := &in.TargetRefs, &out.TargetRefs
+		*out = make([]CrossNamespaceObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.SidecarSetRefs != nil {
+		in, out := &in.SidecarSetRefs, &out.SidecarSetRefs
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.AutoscalerRef != nil {
 		in, out := &in.AutoscalerRef, &out.AutoscalerRef
 		*out = new(CrossNamespaceObjectReference)
@@ -412,6 +766,21 @@ func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 		**out = **in
 	}
 	in.Service.DeepCopyInto(&out.Service)
+	if in.TargetCluster != nil {
+		in, out := &in.TargetCluster, &out.TargetCluster
+		*out = new(CanaryRemoteCluster)
+		**out = **in
+	}
+	if in.RemoteClusters != nil {
+		in, out := &in.RemoteClusters, &out.RemoteClusters
+		*out = make([]CanaryRemoteCluster, len(*in))
+		copy(*out, *in)
+	}
+	if in.Propagation != nil {
+		in, out := &in.Propagation, &out.Propagation
+		*out = new(CanaryPropagation)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Analysis != nil {
 		in, out := &in.Analysis, &out.Analysis
 		*out = new(CanaryAnalysis)
@@ -427,6 +796,11 @@ func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.GateSecretRef != nil {
+		in, out := &in.GateSecretRef, &out.GateSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	return
 }
 
@@ -454,12 +828,66 @@ func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
 			}
 		}
 	}
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]CanaryCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.TrackedTargets != nil {
+		in, out := &in.TrackedTargets, &out.TrackedTargets
+		*out = new(map[string]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make(map[string]string, len(*in))
+			for key, val := range *in {
+				(*out)[key] = val
+			}
+		}
+	}
+	if in.OriginalTargets != nil {
+		in, out := &in.OriginalTargets, &out.OriginalTargets
+		*out = make(map[string]CanaryTargetState, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]CanaryCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetricStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]CanaryWebhookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Approvals != nil {
+		in, out := &in.Approvals, &out.Approvals
+		*out = make([]CanaryApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]CanaryHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	return
@@ -475,6 +903,32 @@ func (in *CanaryStatus) DeepCopy() *CanaryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTargetState) DeepCopyInto(out *CanaryTargetState) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTargetState.
+func (in *CanaryTargetState) DeepCopy() *CanaryTargetState {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTargetState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryThresholdRange) DeepCopyInto(out *CanaryThresholdRange) {
 	*out = *in
@@ -501,6 +955,126 @@ func (in *CanaryThresholdRange) DeepCopy() *CanaryThresholdRange {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWave) DeepCopyInto(out *CanaryWave) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWave.
+func (in *CanaryWave) DeepCopy() *CanaryWave {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWave)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryWave) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWaveList) DeepCopyInto(out *CanaryWaveList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CanaryWave, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWaveList.
+func (in *CanaryWaveList) DeepCopy() *CanaryWaveList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWaveList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryWaveList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWaveMemberStatus) DeepCopyInto(out *CanaryWaveMemberStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWaveMemberStatus.
+func (in *CanaryWaveMemberStatus) DeepCopy() *CanaryWaveMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWaveMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWaveSpec) DeepCopyInto(out *CanaryWaveSpec) {
+	*out = *in
+	if in.Canaries != nil {
+		in, out := &in.Canaries, &out.Canaries
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWaveSpec.
+func (in *CanaryWaveSpec) DeepCopy() *CanaryWaveSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWaveSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWaveStatus) DeepCopyInto(out *CanaryWaveStatus) {
+	*out = *in
+	if in.Canaries != nil {
+		in, out := &in.Canaries, &out.Canaries
+		*out = make([]CanaryWaveMemberStatus, len(*in))
+		copy(*out, *in)
+	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWaveStatus.
+func (in *CanaryWaveStatus) DeepCopy() *CanaryWaveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWaveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryWebhook) DeepCopyInto(out *CanaryWebhook) {
 	*out = *in
@@ -515,6 +1089,23 @@ func (in *CanaryWebhook) DeepCopyInto(out *CanaryWebhook) {
 			}
 		}
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(CanaryWebhookTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SigningSecretRef != nil {
+		in, out := &in.SigningSecretRef, &out.SigningSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.MetadataFrom != nil {
+		in, out := &in.MetadataFrom, &out.MetadataFrom
+		*out = make([]CanaryWebhookMetadataSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -528,6 +1119,23 @@ func (in *CanaryWebhook) DeepCopy() *CanaryWebhook {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWebhookMetadataSource) DeepCopyInto(out *CanaryWebhookMetadataSource) {
+	*out = *in
+	in.SecretKeyRef.DeepCopyInto(&out.SecretKeyRef)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWebhookMetadataSource.
+func (in *CanaryWebhookMetadataSource) DeepCopy() *CanaryWebhookMetadataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWebhookMetadataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryWebhookPayload) DeepCopyInto(out *CanaryWebhookPayload) {
 	*out = *in
@@ -551,6 +1159,44 @@ func (in *CanaryWebhookPayload) DeepCopy() *CanaryWebhookPayload {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWebhookStatus) DeepCopyInto(out *CanaryWebhookStatus) {
+	*out = *in
+	in.LastCallTime.DeepCopyInto(&out.LastCallTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWebhookStatus.
+func (in *CanaryWebhookStatus) DeepCopy() *CanaryWebhookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWebhookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWebhookTLS) DeepCopyInto(out *CanaryWebhookTLS) {
+	*out = *in
+	if in.SubjectAltNames != nil {
+		in, out := &in.SubjectAltNames, &out.SubjectAltNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWebhookTLS.
+func (in *CanaryWebhookTLS) DeepCopy() *CanaryWebhookTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWebhookTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrossNamespaceObjectReference) DeepCopyInto(out *CrossNamespaceObjectReference) {
 	*out = *in
@@ -597,6 +1243,55 @@ func (in *CustomMetadata) DeepCopy() *CustomMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRef) DeepCopyInto(out *GatewayRef) {
+	*out = *in
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRef.
+func (in *GatewayRef) DeepCopy() *GatewayRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlooRouteTableRoute) DeepCopyInto(out *GlooRouteTableRoute) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlooRouteTableRoute.
+func (in *GlooRouteTableRoute) DeepCopy() *GlooRouteTableRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(GlooRouteTableRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricTemplate) DeepCopyInto(out *MetricTemplate) {
 	*out = *in
@@ -752,3 +1447,106 @@ func (in *MetricTemplateStatus) DeepCopy() *MetricTemplateStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortDiscoveryRules) DeepCopyInto(out *PortDiscoveryRules) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Mappings != nil {
+		in, out := &in.Mappings, &out.Mappings
+		*out = make([]PortMapping, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortDiscoveryRules.
+func (in *PortDiscoveryRules) DeepCopy() *PortDiscoveryRules {
+	if in == nil {
+		return nil
+	}
+	out := new(PortDiscoveryRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortMapping) DeepCopyInto(out *PortMapping) {
+	*out = *in
+	out.ContainerPort = in.ContainerPort
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortMapping.
+func (in *PortMapping) DeepCopy() *PortMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(PortMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceMonitor) DeepCopyInto(out *ServiceMonitor) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMonitor.
+func (in *ServiceMonitor) DeepCopy() *ServiceMonitor {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionAffinity) DeepCopyInto(out *SessionAffinity) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionAffinity.
+func (in *SessionAffinity) DeepCopy() *SessionAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualServiceRoute) DeepCopyInto(out *VirtualServiceRoute) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualServiceRoute.
+func (in *VirtualServiceRoute) DeepCopy() *VirtualServiceRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualServiceRoute)
+	in.DeepCopyInto(out)
+	return out
+}
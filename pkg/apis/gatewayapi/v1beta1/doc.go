@@ -0,0 +1,6 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1beta1 is the v1beta1 version of the API.
+// +groupName=gateway.networking.k8s.io
+// +groupGoName=GatewayAPI
+package v1beta1
@@ -20,12 +20,16 @@ type RouteTableSpec struct {
 }
 
 type Route struct {
+	Name                    string      `json:"name,omitempty"`
 	Matchers                []Matcher   `json:"matchers,omitempty"`
 	Action                  RouteAction `json:"routeAction,omitempty"`
 	InheritablePathMatchers bool        `json:"inheritablePathMatchers,omitempty"`
 }
 
 type Matcher struct {
+	Prefix                 string                  `json:"prefix,omitempty"`
+	Exact                  string                  `json:"exact,omitempty"`
+	Regex                  string                  `json:"regex,omitempty"`
 	Headers                []HeaderMatcher         `json:"headers,omitempty"`
 	QueryParameterMatchers []QueryParameterMatcher `json:"queryParameters,omitempty"`
 	Methods                []string                `json:"methods,omitempty"`
@@ -0,0 +1,5 @@
+package helm
+
+const (
+	GroupName = "helm.toolkit.fluxcd.io"
+)
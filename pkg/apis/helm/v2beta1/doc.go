@@ -0,0 +1,6 @@
+// +k8s:deepcopy-gen=package
+
+// Package v2beta1 is the v2beta1 version of the API.
+// +groupName=helm.toolkit.fluxcd.io
+// +groupGoName=Helm
+package v2beta1
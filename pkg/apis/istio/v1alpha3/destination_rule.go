@@ -243,6 +243,63 @@ type LoadBalancerSettings struct {
 	// Simple or ConsistentHash
 	Simple         SimpleLB          `json:"simple,omitempty"`
 	ConsistentHash *ConsistentHashLB `json:"consistentHash,omitempty"`
+
+	// Locality load balancer settings, this will override mesh wide settings in entirety,
+	// meaning no merging would be performed between this object and the object one in MeshConfig
+	LocalityLbSetting *LocalityLoadBalancerSetting `json:"localityLbSetting,omitempty"`
+}
+
+// Locality-weighted load balancing allows administrators to control the
+// distribution of traffic to endpoints based on the localities of where the
+// traffic originates and where it will terminate. These localities are
+// specified using arbitrary labels that designate a hierarchy of localities in
+// {region}/{zone}/{sub-zone} form.
+//
+// The failover settings require the outlier detection to be enabled
+// on the destination rule, so that unhealthy hosts are ejected.
+type LocalityLoadBalancerSetting struct {
+	// Optional: only one of distribute, failover or failoverPriority can be set.
+	// Explicitly specify loadbalancing weight across different zones and geographical locations.
+	// If empty, the locality weight is set according to the endpoints number within it.
+	Distribute []LocalityLoadBalancerSettingDistribute `json:"distribute,omitempty"`
+
+	// Optional: only one of distribute, failover or failoverPriority can be set.
+	// Explicitly specify the region traffic will land on when endpoints in local region becomes unhealthy.
+	// Should be used together with OutlierDetection to detect unhealthy endpoints.
+	Failover []LocalityLoadBalancerSettingFailover `json:"failover,omitempty"`
+
+	// failoverPriority is an ordered list of labels used to sort endpoints to do priority based load balancing.
+	// This is to support traffic failover across different groups of endpoints.
+	FailoverPriority []string `json:"failoverPriority,omitempty"`
+
+	// enable locality load balancing, this is DestinationRule-level and will override mesh wide settings in entirety.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// Describes how traffic originating in the 'from' zone or sub-zone is
+// distributed over a set of 'to' zones.
+type LocalityLoadBalancerSettingDistribute struct {
+	// Originating locality, '/' separated, e.g. 'region/zone/sub_zone'.
+	From string `json:"from,omitempty"`
+
+	// Map of upstream localities to traffic distribution weights. The sum of
+	// all weights should be 100. Any locality not present will
+	// receive no traffic.
+	To map[string]uint32 `json:"to,omitempty"`
+}
+
+// Specify the traffic failover policy across regions. Since zone and sub-zone
+// failover is supported by default this only needs to be specified for
+// regions when the operator needs to constrain traffic failover so that
+// the default behavior of failing over to any endpoint globally does not
+// apply.
+type LocalityLoadBalancerSettingFailover struct {
+	// Originating region.
+	From string `json:"from,omitempty"`
+
+	// Destination region the traffic will fail over to when endpoints in
+	// the 'from' region becomes unhealthy.
+	To string `json:"to,omitempty"`
 }
 
 // Standard load balancing algorithms that require no tuning.
@@ -0,0 +1,95 @@
+// proto: https://github.com/istio/api/blob/master/networking/v1alpha3/envoy_filter.proto
+// proto: https://github.com/istio/api/blob/master/networking/v1alpha3/sidecar.proto
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// EnvoyFilter
+type EnvoyFilter struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              EnvoyFilterSpec `json:"spec"`
+}
+
+// EnvoyFilter provides a mechanism to customize the Envoy configuration
+// generated by Istio Pilot. The patches are kept as raw JSON since
+// Flagger only copies them from one workload to another.
+type EnvoyFilterSpec struct {
+	// Criteria used to select the specific set of pods/VMs on which
+	// this patch configuration should be applied. If omitted, the set
+	// of patches in this configuration will be applied to all workload
+	// instances in the same namespace.
+	WorkloadSelector *WorkloadSelector `json:"workloadSelector,omitempty"`
+
+	// One or more patches with match conditions.
+	ConfigPatches []runtime.RawExtension `json:"configPatches,omitempty"`
+
+	// Priority defines the order in which patch sets are applied
+	// within a context.
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// WorkloadSelector specifies the criteria used to determine if a policy can be applied
+// to a proxy. The matching criteria includes the metadata associated with a proxy,
+// workload instance info such as labels attached to the pod/VM, or any other info
+// that the proxy provides to Istio during the initial handshake.
+type WorkloadSelector struct {
+	// One or more labels that indicate a specific set of pods/VMs
+	// on which this sidecar configuration should be applied. The scope of
+	// label search is restricted to the configuration namespace in which the
+	// the resource is present.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// EnvoyFilterList is a list of EnvoyFilter resources
+type EnvoyFilterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []EnvoyFilter `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// Sidecar
+type Sidecar struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              SidecarSpec `json:"spec"`
+}
+
+// Sidecar describes the configuration of the sidecar proxy that mediates
+// inbound and outbound communication to the workload instance it is attached to.
+// The listeners are kept as raw JSON since Flagger only copies them
+// from one workload to another.
+type SidecarSpec struct {
+	// Criteria used to select the specific set of pods/VMs on which this
+	// `Sidecar` configuration should be applied. If omitted, the `Sidecar`
+	// configuration will be applied to all workload instances in the same namespace.
+	WorkloadSelector *WorkloadSelector `json:"workloadSelector,omitempty"`
+
+	// Ingress specifies the configuration of the sidecar for processing
+	// inbound traffic to the attached workload instance.
+	Ingress []runtime.RawExtension `json:"ingress,omitempty"`
+
+	// Egress specifies the configuration of the sidecar for processing
+	// outbound traffic from the attached workload instance to other
+	// services in the mesh.
+	Egress []runtime.RawExtension `json:"egress,omitempty"`
+
+	// Configuration for the outbound traffic policy.
+	OutboundTrafficPolicy *runtime.RawExtension `json:"outboundTrafficPolicy,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// SidecarList is a list of Sidecar resources
+type SidecarList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []Sidecar `json:"items"`
+}
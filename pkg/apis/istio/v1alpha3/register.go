@@ -32,6 +32,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&VirtualServiceList{},
 		&DestinationRule{},
 		&DestinationRuleList{},
+		&EnvoyFilter{},
+		&EnvoyFilterList{},
+		&Sidecar{},
+		&SidecarList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -286,6 +286,12 @@ type Destination struct {
 // Describes match conditions and actions for routing HTTP/1.1, HTTP2, and
 // gRPC traffic. See VirtualService for usage examples.
 type HTTPRoute struct {
+	// The name assigned to the route for debugging purposes. The
+	// route's name will be concatenated with the match's name and will
+	// be logged in the access logs for requests matching this
+	// route/match.
+	Name string `json:"name,omitempty"`
+
 	// Match conditions to be satisfied for the rule to be
 	// activated. All conditions inside a single match block have AND
 	// semantics, while the list of match blocks have OR semantics. The rule
@@ -245,6 +245,94 @@ func (in *DestinationWeight) DeepCopy() *DestinationWeight {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyFilter) DeepCopyInto(out *EnvoyFilter) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyFilter.
+func (in *EnvoyFilter) DeepCopy() *EnvoyFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EnvoyFilter) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyFilterList) DeepCopyInto(out *EnvoyFilterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EnvoyFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyFilterList.
+func (in *EnvoyFilterList) DeepCopy() *EnvoyFilterList {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyFilterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EnvoyFilterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyFilterSpec) DeepCopyInto(out *EnvoyFilterSpec) {
+	*out = *in
+	if in.WorkloadSelector != nil {
+		in, out := &in.WorkloadSelector, &out.WorkloadSelector
+		*out = new(WorkloadSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigPatches != nil {
+		in, out := &in.ConfigPatches, &out.ConfigPatches
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyFilterSpec.
+func (in *EnvoyFilterSpec) DeepCopy() *EnvoyFilterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyFilterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPCookie) DeepCopyInto(out *HTTPCookie) {
 	*out = *in
@@ -619,6 +707,11 @@ func (in *LoadBalancerSettings) DeepCopyInto(out *LoadBalancerSettings) {
 		*out = new(ConsistentHashLB)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LocalityLbSetting != nil {
+		in, out := &in.LocalityLbSetting, &out.LocalityLbSetting
+		*out = new(LocalityLoadBalancerSetting)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -632,6 +725,83 @@ func (in *LoadBalancerSettings) DeepCopy() *LoadBalancerSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalityLoadBalancerSetting) DeepCopyInto(out *LocalityLoadBalancerSetting) {
+	*out = *in
+	if in.Distribute != nil {
+		in, out := &in.Distribute, &out.Distribute
+		*out = make([]LocalityLoadBalancerSettingDistribute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]LocalityLoadBalancerSettingFailover, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailoverPriority != nil {
+		in, out := &in.FailoverPriority, &out.FailoverPriority
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalityLoadBalancerSetting.
+func (in *LocalityLoadBalancerSetting) DeepCopy() *LocalityLoadBalancerSetting {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalityLoadBalancerSetting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalityLoadBalancerSettingDistribute) DeepCopyInto(out *LocalityLoadBalancerSettingDistribute) {
+	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make(map[string]uint32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalityLoadBalancerSettingDistribute.
+func (in *LocalityLoadBalancerSettingDistribute) DeepCopy() *LocalityLoadBalancerSettingDistribute {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalityLoadBalancerSettingDistribute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalityLoadBalancerSettingFailover) DeepCopyInto(out *LocalityLoadBalancerSettingFailover) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalityLoadBalancerSettingFailover.
+func (in *LocalityLoadBalancerSettingFailover) DeepCopy() *LocalityLoadBalancerSettingFailover {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalityLoadBalancerSettingFailover)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OutlierDetection) DeepCopyInto(out *OutlierDetection) {
 	*out = *in
@@ -727,6 +897,106 @@ func (in *PortTrafficPolicy) DeepCopy() *PortTrafficPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Sidecar) DeepCopyInto(out *Sidecar) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Sidecar.
+func (in *Sidecar) DeepCopy() *Sidecar {
+	if in == nil {
+		return nil
+	}
+	out := new(Sidecar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Sidecar) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SidecarList) DeepCopyInto(out *SidecarList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Sidecar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarList.
+func (in *SidecarList) DeepCopy() *SidecarList {
+	if in == nil {
+		return nil
+	}
+	out := new(SidecarList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SidecarList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SidecarSpec) DeepCopyInto(out *SidecarSpec) {
+	*out = *in
+	if in.WorkloadSelector != nil {
+		in, out := &in.WorkloadSelector, &out.WorkloadSelector
+		*out = new(WorkloadSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OutboundTrafficPolicy != nil {
+		in, out := &in.OutboundTrafficPolicy, &out.OutboundTrafficPolicy
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSpec.
+func (in *SidecarSpec) DeepCopy() *SidecarSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SidecarSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Subset) DeepCopyInto(out *Subset) {
 	*out = *in
@@ -958,3 +1228,26 @@ func (in *VirtualServiceSpec) DeepCopy() *VirtualServiceSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadSelector) DeepCopyInto(out *WorkloadSelector) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSelector.
+func (in *WorkloadSelector) DeepCopy() *WorkloadSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadSelector)
+	in.DeepCopyInto(out)
+	return out
+}
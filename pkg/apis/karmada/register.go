@@ -0,0 +1,5 @@
+package karmada
+
+const (
+	GroupName = "policy.karmada.io"
+)
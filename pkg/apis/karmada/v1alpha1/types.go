@@ -0,0 +1,79 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PropagationPolicy represents the policy that propagates a group of resources to one or more clusters,
+// only the resource selectors are declared as the placement is managed by the Karmada users.
+type PropagationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PropagationSpec `json:"spec"`
+}
+
+// PropagationSpec represents the desired behavior of PropagationPolicy.
+type PropagationSpec struct {
+	// ResourceSelectors used to select resources.
+	ResourceSelectors []ResourceSelector `json:"resourceSelectors"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PropagationPolicyList contains a list of PropagationPolicy.
+type PropagationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PropagationPolicy `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OverridePolicy represents the policy that overrides a group of resources to one or more clusters,
+// only the resource selectors are declared as the overriders are managed by the Karmada users.
+type OverridePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec OverrideSpec `json:"spec"`
+}
+
+// OverrideSpec defines the desired behavior of OverridePolicy.
+type OverrideSpec struct {
+	// ResourceSelectors restricts resource types that this override policy applies to.
+	ResourceSelectors []ResourceSelector `json:"resourceSelectors,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OverridePolicyList is a collection of OverridePolicy.
+type OverridePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []OverridePolicy `json:"items"`
+}
+
+// ResourceSelector the resources will be selected.
+type ResourceSelector struct {
+	// APIVersion represents the API version of the target resources.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind represents the Kind of the target resources.
+	Kind string `json:"kind"`
+
+	// Namespace of the target resource.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the target resource.
+	Name string `json:"name,omitempty"`
+
+	// A label query over a set of resources.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
@@ -0,0 +1,124 @@
+package v1alpha1
+
+import (
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PausedReplicasAnnotation scales the target to the annotation value and pauses the autoscaling
+const PausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScaledObject is the KEDA specification for the event driven autoscaling of a workload,
+// only the spec is declared as the status is managed by the KEDA operator.
+type ScaledObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ScaledObjectSpec `json:"spec"`
+}
+
+// ScaledObjectSpec is the spec for a ScaledObject resource.
+type ScaledObjectSpec struct {
+	// ScaleTargetRef references the scaled workload.
+	ScaleTargetRef *ScaleTarget `json:"scaleTargetRef"`
+
+	// +optional
+	PollingInterval *int32 `json:"pollingInterval,omitempty"`
+
+	// +optional
+	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
+
+	// +optional
+	IdleReplicaCount *int32 `json:"idleReplicaCount,omitempty"`
+
+	// +optional
+	MinReplicaCount *int32 `json:"minReplicaCount,omitempty"`
+
+	// +optional
+	MaxReplicaCount *int32 `json:"maxReplicaCount,omitempty"`
+
+	// +optional
+	Advanced *AdvancedConfig `json:"advanced,omitempty"`
+
+	Triggers []ScaleTriggers `json:"triggers"`
+
+	// +optional
+	Fallback *Fallback `json:"fallback,omitempty"`
+}
+
+// ScaleTarget holds the reference to the scale target object.
+type ScaleTarget struct {
+	Name string `json:"name"`
+
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// +optional
+	EnvSourceContainerName string `json:"envSourceContainerName,omitempty"`
+}
+
+// AdvancedConfig specifies the advanced scaling options.
+type AdvancedConfig struct {
+	// +optional
+	HorizontalPodAutoscalerConfig *HorizontalPodAutoscalerConfig `json:"horizontalPodAutoscalerConfig,omitempty"`
+
+	// +optional
+	RestoreToOriginalReplicaCount bool `json:"restoreToOriginalReplicaCount,omitempty"`
+}
+
+// HorizontalPodAutoscalerConfig specifies the horizontal scale config.
+type HorizontalPodAutoscalerConfig struct {
+	// +optional
+	Behavior *autoscalingv2beta2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// ScaleTriggers reference the scaler that will be used.
+type ScaleTriggers struct {
+	Type string `json:"type"`
+
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	Metadata map[string]string `json:"metadata"`
+
+	// +optional
+	AuthenticationRef *AuthenticationRef `json:"authenticationRef,omitempty"`
+
+	// +optional
+	MetricType autoscalingv2beta2.MetricTargetType `json:"metricType,omitempty"`
+}
+
+// AuthenticationRef points to the TriggerAuthentication or ClusterTriggerAuthentication object that
+// is used to authenticate the scaler with the environment.
+type AuthenticationRef struct {
+	Name string `json:"name"`
+
+	// Kind of the resource being referred to. Defaults to TriggerAuthentication.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// Fallback is the spec for fallback options.
+type Fallback struct {
+	FailureThreshold int32 `json:"failureThreshold"`
+	Replicas         int32 `json:"replicas"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScaledObjectList is a list of ScaledObject resources.
+type ScaledObjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ScaledObject `json:"items"`
+}
@@ -0,0 +1,5 @@
+package kruise
+
+const (
+	GroupName = "apps.kruise.io"
+)
@@ -0,0 +1,177 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DaemonSet is the OpenKruise Advanced DaemonSet, only the fields used to
+// run a canary with the partition of the rolling update are declared.
+type DaemonSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DaemonSetSpec   `json:"spec,omitempty"`
+	Status DaemonSetStatus `json:"status,omitempty"`
+}
+
+// DaemonSetSpec defines the desired state of DaemonSet.
+type DaemonSetSpec struct {
+	// A label query over pods that are managed by the daemon set.
+	Selector *metav1.LabelSelector `json:"selector"`
+
+	// An object that describes the pod that will be created.
+	Template corev1.PodTemplateSpec `json:"template"`
+
+	// An update strategy to replace existing DaemonSet pods with new pods.
+	// +optional
+	UpdateStrategy DaemonSetUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// The minimum number of seconds for which a newly created DaemonSet pod should
+	// be ready without any of its container crashing, for it to be considered available.
+	// +optional
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+
+	// The number of old history to retain to allow rollback.
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+}
+
+// DaemonSetUpdateStrategy indicates the strategy that the DaemonSet
+// controller will use to perform updates.
+type DaemonSetUpdateStrategy struct {
+	// Type of daemon set update. Can be "RollingUpdate" or "OnDelete". Default is RollingUpdate.
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Rolling update config params. Present only if type = "RollingUpdate".
+	// +optional
+	RollingUpdate *RollingUpdateDaemonSet `json:"rollingUpdate,omitempty"`
+}
+
+// RollingUpdateDaemonSet is the spec to control the desired behavior of daemon set rolling update.
+type RollingUpdateDaemonSet struct {
+	// Type is to specify the rolling update type, Standard, Surging or InPlaceIfPossible.
+	// +optional
+	Type string `json:"rollingUpdateType,omitempty"`
+
+	// The maximum number of DaemonSet pods that can be unavailable during the update.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// The maximum number of nodes with an existing available DaemonSet pod that
+	// can have an updated DaemonSet pod during during an update.
+	// +optional
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// A label query over nodes that are managed by the daemon set RollingUpdate.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// The number of DaemonSet pods remained to be old version.
+	// +optional
+	Partition *int32 `json:"partition,omitempty"`
+
+	// Indicates that the daemon set is paused and will not be processed by the daemon set controller.
+	// +optional
+	Paused *bool `json:"paused,omitempty"`
+}
+
+// DaemonSetStatus defines the observed state of DaemonSet.
+type DaemonSetStatus struct {
+	// The number of nodes that are running at least 1 daemon pod and are supposed to run the daemon pod.
+	CurrentNumberScheduled int32 `json:"currentNumberScheduled"`
+
+	// The number of nodes that are running the daemon pod, but are not supposed to run the daemon pod.
+	NumberMisscheduled int32 `json:"numberMisscheduled"`
+
+	// The total number of nodes that should be running the daemon pod.
+	DesiredNumberScheduled int32 `json:"desiredNumberScheduled"`
+
+	// The number of nodes that should be running the daemon pod and have one
+	// or more of the daemon pod running and ready.
+	NumberReady int32 `json:"numberReady"`
+
+	// The most recent generation observed by the daemon set controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The total number of nodes that are running updated daemon pod.
+	// +optional
+	UpdatedNumberScheduled int32 `json:"updatedNumberScheduled"`
+
+	// The number of nodes that should be running the daemon pod and have one or more of the daemon pod
+	// running and available.
+	// +optional
+	NumberAvailable int32 `json:"numberAvailable"`
+
+	// The number of nodes that should be running the daemon pod and have none of the daemon pod
+	// running and available.
+	// +optional
+	NumberUnavailable int32 `json:"numberUnavailable"`
+
+	// DaemonSetHash is the controller-revision-hash, which represents the latest version of the DaemonSet.
+	// +optional
+	DaemonSetHash string `json:"daemonSetHash,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DaemonSetList contains a list of DaemonSet.
+type DaemonSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []DaemonSet `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SidecarSet is the OpenKruise SidecarSet that injects sidecar containers in the selected pods,
+// only the fields used to track the sidecar changes are declared.
+type SidecarSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SidecarSetSpec `json:"spec,omitempty"`
+}
+
+// SidecarSetSpec defines the desired state of SidecarSet.
+type SidecarSetSpec struct {
+	// A label query over the pods that should be injected with the sidecars.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// The namespace of the pods that should be injected, all the namespaces when empty.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// The init containers injected in the selected pods.
+	// +optional
+	InitContainers []SidecarContainer `json:"initContainers,omitempty"`
+
+	// The sidecar containers injected in the selected pods.
+	// +optional
+	Containers []SidecarContainer `json:"containers,omitempty"`
+}
+
+// SidecarContainer defines a container injected by a SidecarSet.
+type SidecarContainer struct {
+	corev1.Container `json:",inline"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SidecarSetList contains a list of SidecarSet.
+type SidecarSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []SidecarSet `json:"items"`
+}
@@ -0,0 +1,5 @@
+package kustomize
+
+const (
+	GroupName = "kustomize.toolkit.fluxcd.io"
+)
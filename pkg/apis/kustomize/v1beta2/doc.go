@@ -0,0 +1,6 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1beta2 is the v1beta2 version of the API.
+// +groupName=kustomize.toolkit.fluxcd.io
+// +groupGoName=Kustomize
+package v1beta2
@@ -0,0 +1,5 @@
+package linkerd
+
+const (
+	GroupName = "policy.linkerd.io"
+)
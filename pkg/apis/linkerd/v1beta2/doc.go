@@ -0,0 +1,5 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1beta2 is the v1beta2 version of the API.
+// +groupName=policy.linkerd.io
+package v1beta2
@@ -0,0 +1,173 @@
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HTTPRoute provides a way to route HTTP requests. This includes the capability
+// to match requests by hostname, path, header, or query param. Linkerd applies
+// the routes that have a Service as parent to the meshed clients of the service.
+type HTTPRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec HTTPRouteSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HTTPRouteList is a list of HTTPRoute resources.
+type HTTPRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []HTTPRoute `json:"items"`
+}
+
+// HTTPRouteSpec defines the desired state of HTTPRoute
+type HTTPRouteSpec struct {
+	// ParentRefs references the resources (usually Services) that the route
+	// wants to be attached to.
+	ParentRefs []ParentReference `json:"parentRefs,omitempty"`
+
+	// Hostnames defines a set of hostname that should match against the HTTP
+	// Host header to select a HTTPRoute to process the request.
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// Rules are a list of HTTP matchers and actions.
+	Rules []HTTPRouteRule `json:"rules,omitempty"`
+}
+
+// ParentReference identifies an API object (usually a Service) that the
+// route is attached to.
+type ParentReference struct {
+	// Group is the group of the referent, core for a Service.
+	Group string `json:"group,omitempty"`
+
+	// Kind is kind of the referent, e.g. Service.
+	Kind string `json:"kind,omitempty"`
+
+	// Namespace is the namespace of the referent.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the name of the referent.
+	Name string `json:"name"`
+
+	// Port is the network port this Route targets.
+	Port *int32 `json:"port,omitempty"`
+}
+
+// HTTPRouteRule defines semantics for matching an HTTP request based on
+// conditions (matches) and forwarding the request to the backends.
+type HTTPRouteRule struct {
+	// Matches define conditions used for matching the rule against incoming
+	// HTTP requests. Each match is independent, i.e. this rule will be matched
+	// if **any** one of the matches is satisfied.
+	Matches []HTTPRouteMatch `json:"matches,omitempty"`
+
+	// BackendRefs defines the backend(s) where matching requests should be
+	// sent.
+	BackendRefs []HTTPBackendRef `json:"backendRefs,omitempty"`
+}
+
+// HTTPRouteMatch defines the predicate used to match requests to a given
+// action. Multiple match types are ANDed together, i.e. the match will
+// evaluate to true only if all conditions are satisfied.
+type HTTPRouteMatch struct {
+	// Path specifies a HTTP request path matcher.
+	Path *HTTPPathMatch `json:"path,omitempty"`
+
+	// Headers specifies HTTP request header matchers. Multiple match values are
+	// ANDed together, meaning, a request must match all the specified headers
+	// to select the route.
+	Headers []HTTPHeaderMatch `json:"headers,omitempty"`
+
+	// QueryParams specifies HTTP query parameter matchers. Multiple match
+	// values are ANDed together, meaning, a request must match all the
+	// specified query parameters to select the route.
+	QueryParams []HTTPQueryParamMatch `json:"queryParams,omitempty"`
+
+	// Method specifies HTTP method matcher.
+	Method string `json:"method,omitempty"`
+}
+
+// PathMatchType specifies the semantics of how HTTP paths should be compared.
+// Valid PathMatchType values are Exact, PathPrefix and RegularExpression.
+type PathMatchType string
+
+const (
+	PathMatchExact             PathMatchType = "Exact"
+	PathMatchPathPrefix        PathMatchType = "PathPrefix"
+	PathMatchRegularExpression PathMatchType = "RegularExpression"
+)
+
+// HTTPPathMatch describes how to select a HTTP route by matching the HTTP request path.
+type HTTPPathMatch struct {
+	// Type specifies how to match against the path Value.
+	Type PathMatchType `json:"type,omitempty"`
+
+	// Value of the HTTP path to match against.
+	Value string `json:"value,omitempty"`
+}
+
+// MatchType specifies the semantics of how HTTP header and query param values
+// should be compared. Valid MatchType values are Exact and RegularExpression.
+type MatchType string
+
+const (
+	MatchExact             MatchType = "Exact"
+	MatchRegularExpression MatchType = "RegularExpression"
+)
+
+// HTTPHeaderMatch describes how to select a HTTP route by matching HTTP request
+// headers.
+type HTTPHeaderMatch struct {
+	// Type specifies how to match against the value of the header.
+	Type MatchType `json:"type,omitempty"`
+
+	// Name is the name of the HTTP Header to be matched. Name matching is
+	// case insensitive.
+	Name string `json:"name"`
+
+	// Value is the value of HTTP Header to be matched.
+	Value string `json:"value"`
+}
+
+// HTTPQueryParamMatch describes how to select a HTTP route by matching HTTP
+// query parameters.
+type HTTPQueryParamMatch struct {
+	// Type specifies how to match against the value of the query parameter.
+	Type MatchType `json:"type,omitempty"`
+
+	// Name is the name of the HTTP query param to be matched.
+	Name string `json:"name"`
+
+	// Value is the value of HTTP query param to be matched.
+	Value string `json:"value"`
+}
+
+// HTTPBackendRef defines how a HTTPRoute should forward an HTTP request.
+type HTTPBackendRef struct {
+	// Group is the group of the referent, empty for a Service.
+	Group string `json:"group,omitempty"`
+
+	// Kind is kind of the referent, e.g. Service.
+	Kind string `json:"kind,omitempty"`
+
+	// Name is the name of the referent.
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the backend.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Port specifies the destination port number to use for this resource.
+	Port *int32 `json:"port,omitempty"`
+
+	// Weight specifies the proportion of requests forwarded to the referenced
+	// backend. Weight is not a percentage and the sum of weights does not need
+	// to equal 100.
+	Weight *int32 `json:"weight,omitempty"`
+}
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceMonitor defines monitoring for a set of services.
+type ServiceMonitor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec ServiceMonitorSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceMonitorList is a list of ServiceMonitor resources.
+type ServiceMonitorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ServiceMonitor `json:"items"`
+}
+
+// ServiceMonitorSpec contains specification parameters for a ServiceMonitor.
+type ServiceMonitorSpec struct {
+	// JobLabel selects the label from the associated Kubernetes service
+	// which will be used as the job label for all metrics.
+	JobLabel string `json:"jobLabel,omitempty"`
+
+	// TargetLabels transfers labels from the Kubernetes service onto the created metrics.
+	TargetLabels []string `json:"targetLabels,omitempty"`
+
+	// Endpoints is a list of endpoints allowed as part of this ServiceMonitor.
+	Endpoints []Endpoint `json:"endpoints"`
+
+	// Selector to select the Endpoints objects.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// NamespaceSelector selects the namespaces the Endpoints objects are discovered from.
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+}
+
+// NamespaceSelector is a selector for selecting either all namespaces or a
+// list of namespaces.
+type NamespaceSelector struct {
+	// Any selects all namespaces.
+	Any bool `json:"any,omitempty"`
+
+	// MatchNames is a list of namespace names.
+	MatchNames []string `json:"matchNames,omitempty"`
+}
+
+// Endpoint defines a scrapeable endpoint serving Prometheus metrics.
+type Endpoint struct {
+	// Port is the name of the service port this endpoint refers to.
+	Port string `json:"port,omitempty"`
+
+	// Path is the HTTP path to scrape for metrics.
+	Path string `json:"path,omitempty"`
+
+	// Scheme is the HTTP scheme to use for scraping.
+	Scheme string `json:"scheme,omitempty"`
+
+	// Interval at which metrics should be scraped.
+	Interval string `json:"interval,omitempty"`
+
+	// ScrapeTimeout is the timeout after which the scrape is ended.
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+
+	// RelabelConfigs are applied to the samples before scraping.
+	RelabelConfigs []RelabelConfig `json:"relabelings,omitempty"`
+}
+
+// RelabelConfig allows dynamic rewriting of the label set, being applied to
+// samples before ingestion.
+type RelabelConfig struct {
+	// SourceLabels select values from existing labels.
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+
+	// Separator placed between concatenated source label values.
+	Separator string `json:"separator,omitempty"`
+
+	// TargetLabel to which the resulting value is written in a replace action.
+	TargetLabel string `json:"targetLabel,omitempty"`
+
+	// Regex against which the extracted value is matched.
+	Regex string `json:"regex,omitempty"`
+
+	// Replacement value against which a regex replace is performed.
+	Replacement string `json:"replacement,omitempty"`
+
+	// Action to perform based on regex matching, defaults to replace.
+	Action string `json:"action,omitempty"`
+}
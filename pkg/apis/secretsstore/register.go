@@ -0,0 +1,5 @@
+package secretsstore
+
+const (
+	GroupName = "secrets-store.csi.x-k8s.io"
+)
@@ -0,0 +1,6 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1 is the v1 version of the API.
+// +groupName=secrets-store.csi.x-k8s.io
+// +groupGoName=SecretsStore
+package v1
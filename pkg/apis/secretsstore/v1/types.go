@@ -0,0 +1,77 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CSIDriverName is the name of the Secrets Store CSI driver
+	CSIDriverName = "secrets-store.csi.k8s.io"
+
+	// SecretProviderClassAttribute is the CSI volume attribute referencing the SecretProviderClass
+	SecretProviderClassAttribute = "secretProviderClass"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SecretProviderClass is the specification of the external secrets mounted by the Secrets Store CSI driver,
+// only the spec is declared as the status is managed by the driver.
+type SecretProviderClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SecretProviderClassSpec `json:"spec,omitempty"`
+}
+
+// SecretProviderClassSpec is the spec for a SecretProviderClass resource.
+type SecretProviderClassSpec struct {
+	// Provider is the name of the secrets store provider, e.g. vault or azure.
+	Provider string `json:"provider,omitempty"`
+
+	// Parameters are the provider specific options.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// SecretObjects are the Kubernetes secrets synced with the mounted content.
+	// +optional
+	SecretObjects []*SecretObject `json:"secretObjects,omitempty"`
+}
+
+// SecretObject defines the desired state of a synced Kubernetes secret.
+type SecretObject struct {
+	// SecretName is the name of the Kubernetes secret.
+	SecretName string `json:"secretName,omitempty"`
+
+	// Type is the type of the Kubernetes secret.
+	Type string `json:"type,omitempty"`
+
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Data holds the mapping of the mounted objects to the secret keys.
+	// +optional
+	Data []*SecretObjectData `json:"data,omitempty"`
+}
+
+// SecretObjectData defines a key of a synced Kubernetes secret.
+type SecretObjectData struct {
+	// ObjectName is the name of the mounted object.
+	ObjectName string `json:"objectName,omitempty"`
+
+	// Key is the key of the Kubernetes secret.
+	Key string `json:"key,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SecretProviderClassList is a list of SecretProviderClass resources.
+type SecretProviderClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SecretProviderClass `json:"items"`
+}
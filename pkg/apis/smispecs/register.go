@@ -0,0 +1,5 @@
+package smispecs
+
+const (
+	GroupName = "specs.smi-spec.io"
+)
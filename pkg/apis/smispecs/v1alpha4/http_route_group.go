@@ -0,0 +1,52 @@
+package v1alpha4
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HTTPRouteGroup is used to describe HTTP/1 and HTTP/2 traffic.
+// It enumerates the routes that can be served by an application.
+type HTTPRouteGroup struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HTTPRouteGroupSpec `json:"spec"`
+}
+
+// HTTPRouteGroupSpec is the specification for a HTTPRouteGroup
+type HTTPRouteGroupSpec struct {
+	// Routes for inbound traffic
+	Matches []HTTPMatch `json:"matches,omitempty"`
+}
+
+// HTTPMatch defines an individual route for HTTP traffic
+type HTTPMatch struct {
+	// Name is the name of the match for referencing in a TrafficTarget
+	Name string `json:"name,omitempty"`
+
+	// Methods for inbound traffic as defined in RFC 7231
+	// https://tools.ietf.org/html/rfc7231#section-4
+	Methods []string `json:"methods,omitempty"`
+
+	// PathRegex is a regular expression defining the route
+	PathRegex string `json:"pathRegex,omitempty"`
+
+	// Headers is a list of headers used to match HTTP traffic,
+	// the header values are regular expressions
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HTTPRouteGroupList satisfy K8s code gen requirements
+type HTTPRouteGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []HTTPRouteGroup `json:"items"`
+}
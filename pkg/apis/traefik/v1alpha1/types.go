@@ -37,6 +37,21 @@ type ServiceSpec struct {
 // WeightedRoundRobin defines a load-balancer of services.
 type WeightedRoundRobin struct {
 	Services []Service `json:"services,omitempty"`
+	Sticky   *Sticky   `json:"sticky,omitempty"`
+}
+
+// Sticky holds the sticky configuration.
+type Sticky struct {
+	Cookie *Cookie `json:"cookie,omitempty"`
+}
+
+// Cookie holds the sticky configuration based on cookie.
+type Cookie struct {
+	Name     string `json:"name,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	SameSite string `json:"sameSite,omitempty"`
+	MaxAge   int    `json:"maxAge,omitempty"`
 }
 
 type Service struct {
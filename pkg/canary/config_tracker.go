@@ -27,14 +27,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	secretsstorev1 "github.com/fluxcd/flagger/pkg/apis/secretsstore/v1"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 )
 
-// ConfigTracker is managing the operations for Kubernetes ConfigMaps and Secrets
+// ConfigTracker is managing the operations for Kubernetes ConfigMaps, Secrets
+// and the SecretProviderClasses of the Secrets Store CSI driver
 type ConfigTracker struct {
 	KubeClient    kubernetes.Interface
 	FlaggerClient clientset.Interface
@@ -44,8 +45,17 @@ type ConfigTracker struct {
 type ConfigRefType string
 
 const (
-	ConfigRefMap    ConfigRefType = "configmap"
-	ConfigRefSecret ConfigRefType = "secret"
+	ConfigRefMap                 ConfigRefType = "configmap"
+	ConfigRefSecret              ConfigRefType = "secret"
+	ConfigRefSecretProviderClass ConfigRefType = "secretproviderclass"
+
+	// ConfigRefSyncedSecret is a Secret synced by the CSI driver from a tracked SecretProviderClass,
+	// the primary Secret is synced from the primary SecretProviderClass instead of being copied
+	ConfigRefSyncedSecret ConfigRefType = "syncedsecret"
+
+	// ConfigRefSidecarSet is an OpenKruise SidecarSet that injects containers in the target pods,
+	// it is tracked for the changes of the sidecar images but not copied for the primary
+	ConfigRefSidecarSet ConfigRefType = "sidecarset"
 
 	configTrackingDisabledAnnotationKey = "flagger.app/config-tracking"
 )
@@ -120,8 +130,51 @@ func (ct *ConfigTracker) getRefFromSecret(name string, namespace string) (*Confi
 	}, nil
 }
 
-// GetTargetConfigs scans the target deployment for Kubernetes ConfigMaps and Secretes
-// and returns a list of config references
+// getRefFromSecretProviderClass transforms a SecretProviderClass into a ConfigRef
+// and computes the checksum of its spec
+func (ct *ConfigTracker) getRefFromSecretProviderClass(name string, namespace string) (*ConfigRef, error) {
+	spc, err := ct.FlaggerClient.SecretsStoreV1().SecretProviderClasses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("secretproviderclass %s.%s get query error: %w", name, namespace, err)
+	}
+
+	if configIsDisabled(spc.GetAnnotations()) {
+		return nil, nil
+	}
+
+	return &ConfigRef{
+		Name:     spc.Name,
+		Type:     ConfigRefSecretProviderClass,
+		Checksum: checksum(spc.Spec),
+	}, nil
+}
+
+// getRefFromSidecarSet transforms an OpenKruise SidecarSet into a ConfigRef
+// and computes the checksum of the images of its containers
+func (ct *ConfigTracker) getRefFromSidecarSet(name string) (*ConfigRef, error) {
+	sidecarSet, err := ct.FlaggerClient.KruiseV1alpha1().SidecarSets().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("sidecarset %s get query error: %w", name, err)
+	}
+
+	if configIsDisabled(sidecarSet.GetAnnotations()) {
+		return nil, nil
+	}
+
+	images := make(map[string]string)
+	for _, container := range append(sidecarSet.Spec.InitContainers, sidecarSet.Spec.Containers...) {
+		images[container.Name] = container.Image
+	}
+
+	return &ConfigRef{
+		Name:     sidecarSet.Name,
+		Type:     ConfigRefSidecarSet,
+		Checksum: checksum(images),
+	}, nil
+}
+
+// GetTargetConfigs scans the target deployment for Kubernetes ConfigMaps, Secretes
+// and SecretProviderClasses and returns a list of config references
 func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]ConfigRef, error) {
 	targetName := cd.Spec.TargetRef.Name
 	var vs []corev1.Volume
@@ -129,25 +182,33 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 
 	switch cd.Spec.TargetRef.Kind {
 	case "Deployment":
-		targetDep, err := ct.KubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+		targetDep, err := ct.KubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 		if err != nil {
-			return nil, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+			return nil, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 		}
 		vs = targetDep.Spec.Template.Spec.Volumes
-		cs = targetDep.Spec.Template.Spec.Containers
+		cs = append(targetDep.Spec.Template.Spec.InitContainers, targetDep.Spec.Template.Spec.Containers...)
 	case "DaemonSet":
-		targetDae, err := ct.KubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+		targetDae, err := ct.KubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 		if err != nil {
-			return nil, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
+			return nil, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 		}
 		vs = targetDae.Spec.Template.Spec.Volumes
-		cs = targetDae.Spec.Template.Spec.Containers
+		cs = append(targetDae.Spec.Template.Spec.InitContainers, targetDae.Spec.Template.Spec.Containers...)
+	case "StatefulSet":
+		targetSts, err := ct.KubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
+		}
+		vs = targetSts.Spec.Template.Spec.Volumes
+		cs = append(targetSts.Spec.Template.Spec.InitContainers, targetSts.Spec.Template.Spec.Containers...)
 	default:
 		return nil, fmt.Errorf("TargetRef.Kind invalid: %s", cd.Spec.TargetRef.Kind)
 	}
 
 	secretNames := make(map[string]bool)
 	configMapNames := make(map[string]bool)
+	providerClassNames := make(map[string]bool)
 
 	// scan volumes
 	for _, volume := range vs {
@@ -172,6 +233,15 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 				}
 			}
 		}
+
+		if csi := volume.CSI; csi != nil {
+			if name := csi.VolumeAttributes[secretsstorev1.SecretProviderClassAttribute]; csi.Driver == secretsstorev1.CSIDriverName && name != "" {
+				providerClassNames[name] = true
+			}
+			if ref := csi.NodePublishSecretRef; ref != nil {
+				secretNames[ref.Name] = true
+			}
+		}
 	}
 	// scan containers
 	for _, container := range cs {
@@ -203,13 +273,37 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 
 	res := make(map[string]ConfigRef)
 
+	for providerClassName := range providerClassNames {
+		spc, err := ct.getRefFromSecretProviderClass(providerClassName, cd.GetTargetNamespace())
+		if err != nil {
+			return nil, err
+		}
+		if spc == nil {
+			continue
+		}
+		res[spc.GetName()] = *spc
+
+		// the synced secrets may not exist yet, they are created when a pod mounts the volume
+		syncedSecrets, err := ct.getSyncedSecrets(providerClassName, cd.GetTargetNamespace())
+		if err != nil {
+			return nil, err
+		}
+		for _, secretName := range syncedSecrets {
+			if _, ok := secretNames[secretName]; ok {
+				delete(secretNames, secretName)
+				synced := ConfigRef{Name: secretName, Type: ConfigRefSyncedSecret, Checksum: spc.Checksum}
+				res[synced.GetName()] = synced
+			}
+		}
+	}
+
 	for configMapName, required := range configMapNames {
-		config, err := ct.getRefFromConfigMap(configMapName, cd.Namespace)
+		config, err := ct.getRefFromConfigMap(configMapName, cd.GetTargetNamespace())
 		if err != nil {
 			if required {
-				return nil, fmt.Errorf("configmap %s.%s get query error: %w", configMapName, cd.Namespace, err)
+				return nil, fmt.Errorf("configmap %s.%s get query error: %w", configMapName, cd.GetTargetNamespace(), err)
 			}
-			ct.Logger.Errorf("configmap %s.%s get query failed: %w", configMapName, cd.Namespace, err)
+			ct.Logger.Errorf("configmap %s.%s get query failed: %w", configMapName, cd.GetTargetNamespace(), err)
 			continue
 		}
 		if config != nil {
@@ -218,12 +312,12 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 	}
 
 	for secretName, required := range secretNames {
-		secret, err := ct.getRefFromSecret(secretName, cd.Namespace)
+		secret, err := ct.getRefFromSecret(secretName, cd.GetTargetNamespace())
 		if err != nil {
 			if required {
-				return nil, fmt.Errorf("secret %s.%s get query error: %v", secretName, cd.Namespace, err)
+				return nil, fmt.Errorf("secret %s.%s get query error: %v", secretName, cd.GetTargetNamespace(), err)
 			}
-			ct.Logger.Errorf("secret %s.%s get query failed: %v", secretName, cd.Namespace, err)
+			ct.Logger.Errorf("secret %s.%s get query failed: %v", secretName, cd.GetTargetNamespace(), err)
 			continue
 		}
 		if secret != nil {
@@ -231,9 +325,34 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 		}
 	}
 
+	for _, ref := range cd.Spec.SidecarSetRefs {
+		sidecarSet, err := ct.getRefFromSidecarSet(ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		if sidecarSet != nil {
+			res[sidecarSet.GetName()] = *sidecarSet
+		}
+	}
+
 	return res, nil
 }
 
+// getSyncedSecrets returns the names of the Kubernetes secrets synced from a SecretProviderClass
+func (ct *ConfigTracker) getSyncedSecrets(name string, namespace string) ([]string, error) {
+	spc, err := ct.FlaggerClient.SecretsStoreV1().SecretProviderClasses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("secretproviderclass %s.%s get query error: %w", name, namespace, err)
+	}
+	var names []string
+	for _, obj := range spc.Spec.SecretObjects {
+		if obj != nil && obj.SecretName != "" {
+			names = append(names, obj.SecretName)
+		}
+	}
+	return names, nil
+}
+
 func fieldIsMandatory(p *bool) bool {
 	if p == nil {
 		return false
@@ -290,108 +409,153 @@ func (ct *ConfigTracker) HasConfigChanged(cd *flaggerv1.Canary) (bool, error) {
 }
 
 // CreatePrimaryConfigs syncs the primary Kubernetes ConfigMaps and Secretes
-// with those found in the target deployment
-func (ct *ConfigTracker) CreatePrimaryConfigs(cd *flaggerv1.Canary, refs map[string]ConfigRef, includeLabelPrefix []string) error {
+// with those found in the target deployment, the primary copies are named with the given suffix
+func (ct *ConfigTracker) CreatePrimaryConfigs(cd *flaggerv1.Canary, refs map[string]ConfigRef, includeLabelPrefix []string, suffix string) error {
 	for _, ref := range refs {
 		switch ref.Type {
 		case ConfigRefMap:
-			config, err := ct.KubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+			config, err := ct.KubeClient.CoreV1().ConfigMaps(cd.GetTargetNamespace()).Get(context.TODO(), ref.Name, metav1.GetOptions{})
 			if err != nil {
 				return fmt.Errorf("configmap %s.%s get query failed : %w", ref.Name, cd.Name, err)
 			}
-			primaryName := fmt.Sprintf("%s-primary", config.GetName())
+			primaryName := config.GetName() + suffix
 			labels := includeLabelsByPrefix(config.Labels, includeLabelPrefix)
 			primaryConfigMap := &corev1.ConfigMap{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      primaryName,
-					Namespace: cd.Namespace,
-					Labels:    labels,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(cd, schema.GroupVersionKind{
-							Group:   flaggerv1.SchemeGroupVersion.Group,
-							Version: flaggerv1.SchemeGroupVersion.Version,
-							Kind:    flaggerv1.CanaryKind,
-						}),
-					},
+					Name:            primaryName,
+					Namespace:       cd.GetTargetNamespace(),
+					Labels:          labels,
+					Annotations:     cd.GetOwnerAnnotations(nil),
+					OwnerReferences: cd.GetOwnerReferences(),
 				},
 				Data: config.Data,
 			}
 
 			// update or insert primary ConfigMap
-			_, err = ct.KubeClient.CoreV1().ConfigMaps(cd.Namespace).Update(context.TODO(), primaryConfigMap, metav1.UpdateOptions{})
+			_, err = ct.KubeClient.CoreV1().ConfigMaps(cd.GetTargetNamespace()).Update(context.TODO(), primaryConfigMap, metav1.UpdateOptions{})
 			if err != nil {
 				if errors.IsNotFound(err) {
-					_, err = ct.KubeClient.CoreV1().ConfigMaps(cd.Namespace).Create(context.TODO(), primaryConfigMap, metav1.CreateOptions{})
+					_, err = ct.KubeClient.CoreV1().ConfigMaps(cd.GetTargetNamespace()).Create(context.TODO(), primaryConfigMap, metav1.CreateOptions{})
 					if err != nil {
-						return fmt.Errorf("creating configmap %s.%s failed: %w", primaryConfigMap.Name, cd.Namespace, err)
+						return fmt.Errorf("creating configmap %s.%s failed: %w", primaryConfigMap.Name, cd.GetTargetNamespace(), err)
 					}
 				} else {
-					return fmt.Errorf("updating configmap %s.%s failed: %w", primaryConfigMap.Name, cd.Namespace, err)
+					return fmt.Errorf("updating configmap %s.%s failed: %w", primaryConfigMap.Name, cd.GetTargetNamespace(), err)
 				}
 			}
 
 			ct.Logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
 				Infof("ConfigMap %s synced", primaryConfigMap.GetName())
 		case ConfigRefSecret:
-			secret, err := ct.KubeClient.CoreV1().Secrets(cd.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+			secret, err := ct.KubeClient.CoreV1().Secrets(cd.GetTargetNamespace()).Get(context.TODO(), ref.Name, metav1.GetOptions{})
 			if err != nil {
 				return fmt.Errorf("secret %s.%s get query failed : %w", ref.Name, cd.Name, err)
 			}
-			primaryName := fmt.Sprintf("%s-primary", secret.GetName())
+			primaryName := secret.GetName() + suffix
 			labels := includeLabelsByPrefix(secret.Labels, includeLabelPrefix)
 			primarySecret := &corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      primaryName,
-					Namespace: cd.Namespace,
-					Labels:    labels,
-					OwnerReferences: []metav1.OwnerReference{
-						*metav1.NewControllerRef(cd, schema.GroupVersionKind{
-							Group:   flaggerv1.SchemeGroupVersion.Group,
-							Version: flaggerv1.SchemeGroupVersion.Version,
-							Kind:    flaggerv1.CanaryKind,
-						}),
-					},
+					Name:            primaryName,
+					Namespace:       cd.GetTargetNamespace(),
+					Labels:          labels,
+					Annotations:     cd.GetOwnerAnnotations(nil),
+					OwnerReferences: cd.GetOwnerReferences(),
 				},
 				Type: secret.Type,
 				Data: secret.Data,
 			}
 
 			// update or insert primary Secret
-			_, err = ct.KubeClient.CoreV1().Secrets(cd.Namespace).Update(context.TODO(), primarySecret, metav1.UpdateOptions{})
+			_, err = ct.KubeClient.CoreV1().Secrets(cd.GetTargetNamespace()).Update(context.TODO(), primarySecret, metav1.UpdateOptions{})
 			if err != nil {
 				if errors.IsNotFound(err) {
-					_, err = ct.KubeClient.CoreV1().Secrets(cd.Namespace).Create(context.TODO(), primarySecret, metav1.CreateOptions{})
+					_, err = ct.KubeClient.CoreV1().Secrets(cd.GetTargetNamespace()).Create(context.TODO(), primarySecret, metav1.CreateOptions{})
 					if err != nil {
-						return fmt.Errorf("creating secret %s.%s failed: %w", primarySecret.Name, cd.Namespace, err)
+						return fmt.Errorf("creating secret %s.%s failed: %w", primarySecret.Name, cd.GetTargetNamespace(), err)
 					}
 				} else {
-					return fmt.Errorf("updating secret %s.%s failed: %w", primarySecret.Name, cd.Namespace, err)
+					return fmt.Errorf("updating secret %s.%s failed: %w", primarySecret.Name, cd.GetTargetNamespace(), err)
 				}
 			}
 
 			ct.Logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
 				Infof("Secret %s synced", primarySecret.GetName())
+		case ConfigRefSecretProviderClass:
+			if err := ct.createPrimarySecretProviderClass(cd, ref.Name, includeLabelPrefix, suffix); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// createPrimarySecretProviderClass syncs the primary SecretProviderClass with the target one,
+// the secrets synced by the CSI driver for the primary pods are named with the primary suffix
+func (ct *ConfigTracker) createPrimarySecretProviderClass(cd *flaggerv1.Canary, name string, includeLabelPrefix []string, suffix string) error {
+	client := ct.FlaggerClient.SecretsStoreV1().SecretProviderClasses(cd.GetTargetNamespace())
+	spc, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("secretproviderclass %s.%s get query failed : %w", name, cd.Name, err)
+	}
+
+	primarySpec := spc.Spec.DeepCopy()
+	for _, obj := range primarySpec.SecretObjects {
+		if obj != nil && obj.SecretName != "" {
+			obj.SecretName = obj.SecretName + suffix
 		}
 	}
 
+	primaryName := spc.GetName() + suffix
+	primary, err := client.Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		primary = &secretsstorev1.SecretProviderClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            primaryName,
+				Namespace:       cd.GetTargetNamespace(),
+				Labels:          includeLabelsByPrefix(spc.Labels, includeLabelPrefix),
+				Annotations:     cd.GetOwnerAnnotations(nil),
+				OwnerReferences: cd.GetOwnerReferences(),
+			},
+			Spec: *primarySpec,
+		}
+		_, err = client.Create(context.TODO(), primary, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating secretproviderclass %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("secretproviderclass %s.%s get query failed : %w", primaryName, cd.Name, err)
+	} else {
+		primaryCopy := primary.DeepCopy()
+		primaryCopy.Labels = includeLabelsByPrefix(spc.Labels, includeLabelPrefix)
+		primaryCopy.Spec = *primarySpec
+		_, err = client.Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("updating secretproviderclass %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+		}
+	}
+
+	ct.Logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("SecretProviderClass %s synced", primaryName)
 	return nil
 }
 
-// ApplyPrimaryConfigs appends the primary suffix to all ConfigMaps and Secretes found in the PodSpec
-func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[string]ConfigRef) corev1.PodSpec {
+// ApplyPrimaryConfigs appends the primary suffix to all ConfigMaps, Secretes
+// and SecretProviderClasses found in the PodSpec
+func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[string]ConfigRef, suffix string) corev1.PodSpec {
 	// update volumes
 	for i, volume := range spec.Volumes {
 		if cmv := volume.ConfigMap; cmv != nil {
 			name := fmt.Sprintf("%s/%s", ConfigRefMap, cmv.Name)
 			if _, exists := refs[name]; exists {
-				spec.Volumes[i].ConfigMap.Name += "-primary"
+				spec.Volumes[i].ConfigMap.Name += suffix
 			}
 		}
 
 		if sv := volume.Secret; sv != nil {
 			name := fmt.Sprintf("%s/%s", ConfigRefSecret, sv.SecretName)
 			if _, exists := refs[name]; exists {
-				spec.Volumes[i].Secret.SecretName += "-primary"
+				spec.Volumes[i].Secret.SecretName += suffix
 			}
 		}
 
@@ -400,22 +564,37 @@ func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[strin
 				if cmv := source.ConfigMap; cmv != nil {
 					name := fmt.Sprintf("%s/%s", ConfigRefMap, cmv.Name)
 					if _, exists := refs[name]; exists {
-						spec.Volumes[i].Projected.Sources[s].ConfigMap.Name += "-primary"
+						spec.Volumes[i].Projected.Sources[s].ConfigMap.Name += suffix
 					}
 				}
 
 				if sv := source.Secret; sv != nil {
 					name := fmt.Sprintf("%s/%s", ConfigRefSecret, sv.Name)
 					if _, exists := refs[name]; exists {
-						spec.Volumes[i].Projected.Sources[s].Secret.Name += "-primary"
+						spec.Volumes[i].Projected.Sources[s].Secret.Name += suffix
 					}
 				}
 			}
 		}
+
+		if csi := volume.CSI; csi != nil {
+			name := fmt.Sprintf("%s/%s", ConfigRefSecretProviderClass, csi.VolumeAttributes[secretsstorev1.SecretProviderClassAttribute])
+			if _, exists := refs[name]; exists && csi.Driver == secretsstorev1.CSIDriverName {
+				attributes := make(map[string]string, len(csi.VolumeAttributes))
+				for k, v := range csi.VolumeAttributes {
+					attributes[k] = v
+				}
+				attributes[secretsstorev1.SecretProviderClassAttribute] += suffix
+				spec.Volumes[i].CSI.VolumeAttributes = attributes
+			}
+			if ref := csi.NodePublishSecretRef; ref != nil && hasSecretRef(refs, ref.Name) {
+				spec.Volumes[i].CSI.NodePublishSecretRef.Name += suffix
+			}
+		}
 	}
 
-	// update containers
-	for _, container := range spec.Containers {
+	// update init containers and containers
+	for _, container := range append(spec.InitContainers, spec.Containers...) {
 		// update env
 		for i, env := range container.Env {
 			if env.ValueFrom != nil {
@@ -423,12 +602,11 @@ func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[strin
 				case env.ValueFrom.ConfigMapKeyRef != nil:
 					name := fmt.Sprintf("%s/%s", ConfigRefMap, env.ValueFrom.ConfigMapKeyRef.Name)
 					if _, exists := refs[name]; exists {
-						container.Env[i].ValueFrom.ConfigMapKeyRef.Name += "-primary"
+						container.Env[i].ValueFrom.ConfigMapKeyRef.Name += suffix
 					}
 				case env.ValueFrom.SecretKeyRef != nil:
-					name := fmt.Sprintf("%s/%s", ConfigRefSecret, env.ValueFrom.SecretKeyRef.Name)
-					if _, exists := refs[name]; exists {
-						container.Env[i].ValueFrom.SecretKeyRef.Name += "-primary"
+					if hasSecretRef(refs, env.ValueFrom.SecretKeyRef.Name) {
+						container.Env[i].ValueFrom.SecretKeyRef.Name += suffix
 					}
 				}
 			}
@@ -439,15 +617,120 @@ func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[strin
 			case envFrom.ConfigMapRef != nil:
 				name := fmt.Sprintf("%s/%s", ConfigRefMap, envFrom.ConfigMapRef.Name)
 				if _, exists := refs[name]; exists {
-					container.EnvFrom[i].ConfigMapRef.Name += "-primary"
+					container.EnvFrom[i].ConfigMapRef.Name += suffix
 				}
 			case envFrom.SecretRef != nil:
-				name := fmt.Sprintf("%s/%s", ConfigRefSecret, envFrom.SecretRef.Name)
-				if _, exists := refs[name]; exists {
-					container.EnvFrom[i].SecretRef.Name += "-primary"
+				if hasSecretRef(refs, envFrom.SecretRef.Name) {
+					container.EnvFrom[i].SecretRef.Name += suffix
+				}
+			}
+		}
+	}
+
+	return spec
+}
+
+// hasSecretRef returns true if the Secret is copied or synced for the primary
+func hasSecretRef(refs map[string]ConfigRef, name string) bool {
+	for _, refType := range []ConfigRefType{ConfigRefSecret, ConfigRefSyncedSecret} {
+		if _, exists := refs[fmt.Sprintf("%s/%s", refType, name)]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// generatedConfigs returns the ConfigMaps, Secrets and SecretProviderClasses copied for the primary,
+// the ones tracked in the canary status and the ones the tracker finds in the target
+func generatedConfigs(cd *flaggerv1.Canary, tracker Tracker) (map[string]bool, error) {
+	configs := make(map[string]bool)
+	if cd.Status.TrackedConfigs != nil {
+		for name := range *cd.Status.TrackedConfigs {
+			configs[name] = true
+		}
+	}
+	if tracker != nil {
+		refs, err := tracker.GetTargetConfigs(cd)
+		if err != nil {
+			return nil, fmt.Errorf("GetTargetConfigs failed: %w", err)
+		}
+		for name := range refs {
+			configs[name] = true
+		}
+	}
+	return configs, nil
+}
+
+// restoreConfigNames removes the primary suffix from the ConfigMaps, Secrets
+// and SecretProviderClasses found in the PodSpec that were copied for the primary,
+// the names of the other objects are kept even if they end with the suffix
+func restoreConfigNames(spec corev1.PodSpec, configs map[string]bool, suffix string) corev1.PodSpec {
+	restore := func(name *string, types ...ConfigRefType) {
+		trimmed := strings.TrimSuffix(*name, suffix)
+		if trimmed == *name {
+			return
+		}
+		for _, refType := range types {
+			if configs[fmt.Sprintf("%s/%s", refType, trimmed)] {
+				*name = trimmed
+				return
+			}
+		}
+	}
+
+	for i, volume := range spec.Volumes {
+		if cmv := volume.ConfigMap; cmv != nil {
+			restore(&spec.Volumes[i].ConfigMap.Name, ConfigRefMap)
+		}
+		if sv := volume.Secret; sv != nil {
+			restore(&spec.Volumes[i].Secret.SecretName, ConfigRefSecret)
+		}
+		if projected := volume.Projected; projected != nil {
+			for s, source := range projected.Sources {
+				if source.ConfigMap != nil {
+					restore(&spec.Volumes[i].Projected.Sources[s].ConfigMap.Name, ConfigRefMap)
+				}
+				if source.Secret != nil {
+					restore(&spec.Volumes[i].Projected.Sources[s].Secret.Name, ConfigRefSecret)
 				}
 			}
 		}
+		if csi := volume.CSI; csi != nil {
+			if name, ok := csi.VolumeAttributes[secretsstorev1.SecretProviderClassAttribute]; ok && csi.Driver == secretsstorev1.CSIDriverName {
+				restore(&name, ConfigRefSecretProviderClass)
+				attributes := make(map[string]string, len(csi.VolumeAttributes))
+				for k, v := range csi.VolumeAttributes {
+					attributes[k] = v
+				}
+				attributes[secretsstorev1.SecretProviderClassAttribute] = name
+				spec.Volumes[i].CSI.VolumeAttributes = attributes
+			}
+			if csi.NodePublishSecretRef != nil {
+				restore(&spec.Volumes[i].CSI.NodePublishSecretRef.Name, ConfigRefSecret, ConfigRefSyncedSecret)
+			}
+		}
+	}
+
+	for _, container := range append(spec.InitContainers, spec.Containers...) {
+		for i, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				restore(&container.Env[i].ValueFrom.ConfigMapKeyRef.Name, ConfigRefMap)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				restore(&container.Env[i].ValueFrom.SecretKeyRef.Name, ConfigRefSecret, ConfigRefSyncedSecret)
+			}
+		}
+		for i, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				restore(&container.EnvFrom[i].ConfigMapRef.Name, ConfigRefMap)
+			}
+			if envFrom.SecretRef != nil {
+				restore(&container.EnvFrom[i].SecretRef.Name, ConfigRefSecret, ConfigRefSyncedSecret)
+			}
+		}
 	}
 
 	return spec
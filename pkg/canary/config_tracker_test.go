@@ -23,10 +23,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8sTesting "k8s.io/client-go/testing"
+
+	kruisev1alpha1 "github.com/fluxcd/flagger/pkg/apis/kruise/v1alpha1"
+	secretsstorev1 "github.com/fluxcd/flagger/pkg/apis/secretsstore/v1"
 )
 
 func TestConfigIsDisabled(t *testing.T) {
@@ -307,6 +310,144 @@ func TestConfigTracker_Secrets(t *testing.T) {
 	})
 }
 
+func TestConfigTracker_SecretProviderClasses(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+
+	spc := &secretsstorev1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo-spc", Namespace: "default"},
+		Spec: secretsstorev1.SecretProviderClassSpec{
+			Provider:      "vault",
+			Parameters:    map[string]string{"roleName": "podinfo"},
+			SecretObjects: []*secretsstorev1.SecretObject{{SecretName: "podinfo-synced", Type: "Opaque"}},
+		},
+	}
+	_, err := mocks.flaggerClient.SecretsStoreV1().SecretProviderClasses("default").Create(context.TODO(), spc, metav1.CreateOptions{})
+	require.NoError(t, err)
+	for _, name := range []string{"podinfo-synced", "podinfo-csi-creds", "podinfo-init-env"} {
+		_, err = mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"apiKey": []byte(name)},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Template.Spec.InitContainers = []corev1.Container{{
+		Name:    "init",
+		EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "podinfo-init-env"}}}},
+		Env: []corev1.EnvVar{{
+			Name: "SYNCED",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "podinfo-synced"},
+				Key:                  "apiKey",
+			}},
+		}},
+	}}
+	dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "secrets-store",
+		VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{
+			Driver:               secretsstorev1.CSIDriverName,
+			VolumeAttributes:     map[string]string{secretsstorev1.SecretProviderClassAttribute: "podinfo-spc"},
+			NodePublishSecretRef: &corev1.LocalObjectReference{Name: "podinfo-csi-creds"},
+		}},
+	})
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	refs, err := mocks.controller.configTracker.GetTargetConfigs(mocks.canary)
+	require.NoError(t, err)
+	assert.Contains(t, refs, "secretproviderclass/podinfo-spc")
+	assert.Contains(t, refs, "syncedsecret/podinfo-synced")
+	assert.Contains(t, refs, "secret/podinfo-csi-creds")
+	assert.Contains(t, refs, "secret/podinfo-init-env")
+	assert.NotContains(t, refs, "secret/podinfo-synced")
+
+	mocks.initializeCanary(t)
+
+	spcPrimary, err := mocks.flaggerClient.SecretsStoreV1().SecretProviderClasses("default").Get(context.TODO(), "podinfo-spc-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-synced-primary", spcPrimary.Spec.SecretObjects[0].SecretName)
+	assert.Equal(t, "podinfo-synced", spc.Spec.SecretObjects[0].SecretName)
+
+	// the synced secret is created by the CSI driver for the primary pods
+	_, err = mocks.kubeClient.CoreV1().Secrets("default").Get(context.TODO(), "podinfo-synced-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = mocks.kubeClient.CoreV1().Secrets("default").Get(context.TODO(), "podinfo-init-env-primary", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	initContainer := depPrimary.Spec.Template.Spec.InitContainers[0]
+	assert.Equal(t, "podinfo-init-env-primary", initContainer.EnvFrom[0].SecretRef.Name)
+	assert.Equal(t, "podinfo-synced-primary", initContainer.Env[0].ValueFrom.SecretKeyRef.Name)
+
+	csi := depPrimary.Spec.Template.Spec.Volumes[len(depPrimary.Spec.Template.Spec.Volumes)-1].CSI
+	require.NotNil(t, csi)
+	assert.Equal(t, "podinfo-spc-primary", csi.VolumeAttributes[secretsstorev1.SecretProviderClassAttribute])
+	assert.Equal(t, "podinfo-csi-creds-primary", csi.NodePublishSecretRef.Name)
+
+	// the primary SecretProviderClass is updated on promotion
+	spc.Spec.Parameters["roleName"] = "podinfo-v2"
+	_, err = mocks.flaggerClient.SecretsStoreV1().SecretProviderClasses("default").Update(context.TODO(), spc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	refs, err = mocks.controller.configTracker.GetTargetConfigs(mocks.canary)
+	require.NoError(t, err)
+	require.NoError(t, mocks.controller.configTracker.CreatePrimaryConfigs(mocks.canary, refs, nil, "-primary"))
+	spcPrimary, err = mocks.flaggerClient.SecretsStoreV1().SecretProviderClasses("default").Get(context.TODO(), "podinfo-spc-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-v2", spcPrimary.Spec.Parameters["roleName"])
+}
+
+func TestConfigTracker_SidecarSets(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+
+	sidecarSet := &kruisev1alpha1.SidecarSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "log-agent"},
+		Spec: kruisev1alpha1.SidecarSetSpec{
+			Containers: []kruisev1alpha1.SidecarContainer{{Container: corev1.Container{Name: "agent", Image: "agent:1.0.0"}}},
+		},
+	}
+	_, err := mocks.flaggerClient.KruiseV1alpha1().SidecarSets().Create(context.TODO(), sidecarSet, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	cd := mocks.canary.DeepCopy()
+	cd.Spec.SidecarSetRefs = []corev1.LocalObjectReference{{Name: "log-agent"}}
+	configs, err := mocks.controller.configTracker.GetConfigRefs(cd)
+	require.NoError(t, err)
+	assert.Contains(t, *configs, "sidecarset/log-agent")
+	cd.Status.TrackedConfigs = configs
+
+	changed, err := mocks.controller.configTracker.HasConfigChanged(cd)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// the changes of the sidecars other than the images are ignored
+	sidecarSet.Spec.Containers[0].Args = []string{"--verbose"}
+	_, err = mocks.flaggerClient.KruiseV1alpha1().SidecarSets().Update(context.TODO(), sidecarSet, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	changed, err = mocks.controller.configTracker.HasConfigChanged(cd)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	sidecarSet.Spec.Containers[0].Image = "agent:1.1.0"
+	_, err = mocks.flaggerClient.KruiseV1alpha1().SidecarSets().Update(context.TODO(), sidecarSet, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	changed, err = mocks.controller.configTracker.HasConfigChanged(cd)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// the SidecarSets are not copied for the primary
+	refs, err := mocks.controller.configTracker.GetTargetConfigs(cd)
+	require.NoError(t, err)
+	require.NoError(t, mocks.controller.configTracker.CreatePrimaryConfigs(cd, refs, nil, "-primary"))
+	_, err = mocks.flaggerClient.KruiseV1alpha1().SidecarSets().Get(context.TODO(), "log-agent-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
 func TestConfigTracker_HasConfigChanged_ShouldReturnErrorWhenAPIServerIsDown(t *testing.T) {
 	t.Run("secret", func(t *testing.T) {
 		dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
@@ -332,3 +473,41 @@ func TestConfigTracker_HasConfigChanged_ShouldReturnErrorWhenAPIServerIsDown(t *
 		assert.Error(t, err)
 	})
 }
+
+func TestConfigTracker_RestoreConfigNames(t *testing.T) {
+	spec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{
+				Name: "tracked",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "podinfo-config-primary"}},
+				},
+			},
+			{
+				Name: "untracked",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "shared-primary"}},
+				},
+			},
+		},
+		Containers: []corev1.Container{{
+			Name: "podinfo",
+			EnvFrom: []corev1.EnvFromSource{
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "podinfo-secret-primary"}}},
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db-primary"}}},
+			},
+		}},
+	}
+	configs := map[string]bool{
+		"configmap/podinfo-config": true,
+		"secret/podinfo-secret":    true,
+		// a ConfigMap named as the Secret isn't a reason to rename the Secret
+		"configmap/db": true,
+	}
+
+	restored := restoreConfigNames(spec, configs, "-primary")
+	assert.Equal(t, "podinfo-config", restored.Volumes[0].ConfigMap.Name)
+	assert.Equal(t, "shared-primary", restored.Volumes[1].ConfigMap.Name)
+	assert.Equal(t, "podinfo-secret", restored.Containers[0].EnvFrom[0].SecretRef.Name)
+	assert.Equal(t, "db-primary", restored.Containers[0].EnvFrom[1].SecretRef.Name)
+}
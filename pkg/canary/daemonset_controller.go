@@ -25,8 +25,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
@@ -44,13 +44,15 @@ type DaemonSetController struct {
 	configTracker      Tracker
 	labels             []string
 	includeLabelPrefix []string
+	excludePaths       []string
+	primarySuffix      string
 }
 
 func (c *DaemonSetController) ScaleToZero(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
-	dae, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	dae, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	daeCopy := dae.DeepCopy()
@@ -73,9 +75,9 @@ func (c *DaemonSetController) ScaleToZero(cd *flaggerv1.Canary) error {
 
 func (c *DaemonSetController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
-	dep, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	dep, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("daemonset %s.%s query error: %w", targetName, cd.Namespace, err)
+		return fmt.Errorf("daemonset %s.%s query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	depCopy := dep.DeepCopy()
@@ -90,6 +92,40 @@ func (c *DaemonSetController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	return nil
 }
 
+// restorePrimaryTemplate sets the pod template of the target DaemonSet to the one of the primary
+func (c *DaemonSetController) restorePrimaryTemplate(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := cd.GetPrimaryName(targetName, c.primarySuffix)
+	primary, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("daemonset %s.%s query error: %w", primaryName, cd.GetTargetNamespace(), err)
+	}
+	target, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("daemonset %s.%s query error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+
+	label, labelValue, err := c.getSelectorLabel(target)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+	configs, err := generatedConfigs(cd, c.configTracker)
+	if err != nil {
+		return err
+	}
+	targetCopy := target.DeepCopy()
+	if !restorePrimaryTemplate(&targetCopy.Spec.Template, primary.Spec.Template, configs, c.labels, label, labelValue, cd.GetPrimarySuffix(c.primarySuffix)) {
+		return nil
+	}
+	if _, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Update(context.TODO(), targetCopy, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("daemonset %s.%s update error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+	return nil
+}
+
 // Initialize creates the primary DaemonSet, scales down the canary DaemonSet,
 // and returns the pod selector label and container ports
 func (c *DaemonSetController) Initialize(cd *flaggerv1.Canary) (err error) {
@@ -106,7 +142,7 @@ func (c *DaemonSetController) Initialize(cd *flaggerv1.Canary) (err error) {
 		}
 
 		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
-			Infof("Scaling down DaemonSet %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+			Infof("Scaling down DaemonSet %s.%s", cd.Spec.TargetRef.Name, cd.GetTargetNamespace())
 		if err := c.ScaleToZero(cd); err != nil {
 			return fmt.Errorf("ScaleToZero failed: %w", err)
 		}
@@ -116,32 +152,40 @@ func (c *DaemonSetController) Initialize(cd *flaggerv1.Canary) (err error) {
 
 // Promote copies the pod spec, secrets and config maps from canary to primary
 func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
+	promote, _, err := c.preparePromotion(cd)
+	if err != nil {
+		return err
+	}
+	return promote()
+}
+
+// preparePromotion gets the canary and primary daemonsets and computes the primary spec,
+// the returned functions write the primary configs and the primary daemonset
+// and restore the previous primary spec
+func (c *DaemonSetController) preparePromotion(cd *flaggerv1.Canary) (func() error, func() error, error) {
 	targetName := cd.Spec.TargetRef.Name
-	primaryName := fmt.Sprintf("%s-primary", targetName)
+	primaryName := cd.GetPrimaryName(targetName, c.primarySuffix)
 
-	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("damonset %s.%s get query error: %v", targetName, cd.Namespace, err)
+		return nil, nil, fmt.Errorf("damonset %s.%s get query error: %v", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	label, labelValue, err := c.getSelectorLabel(canary)
-	primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+	primaryLabelValue := cd.GetPrimaryName(labelValue, c.primarySuffix)
 	if err != nil {
-		return fmt.Errorf("getSelectorLabel failed: %w", err)
+		return nil, nil, fmt.Errorf("getSelectorLabel failed: %w", err)
 	}
 
-	primary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	primary, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("daemonset %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		return nil, nil, fmt.Errorf("daemonset %s.%s get query error: %w", primaryName, cd.GetTargetNamespace(), err)
 	}
 
 	// promote secrets and config maps
 	configRefs, err := c.configTracker.GetTargetConfigs(cd)
 	if err != nil {
-		return fmt.Errorf("GetTargetConfigs failed: %w", err)
-	}
-	if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix); err != nil {
-		return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+		return nil, nil, fmt.Errorf("GetTargetConfigs failed: %w", err)
 	}
 
 	primaryCopy := primary.DeepCopy()
@@ -150,7 +194,7 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 	primaryCopy.Spec.UpdateStrategy = canary.Spec.UpdateStrategy
 
 	// update spec with primary secrets and config maps
-	primaryCopy.Spec.Template.Spec = c.configTracker.ApplyPrimaryConfigs(canary.Spec.Template.Spec, configRefs)
+	primaryCopy.Spec.Template.Spec = makePrimaryPodSelectors(c.configTracker.ApplyPrimaryConfigs(canary.Spec.Template.Spec, configRefs, cd.GetPrimarySuffix(c.primarySuffix)), c.labels, labelValue, cd.GetPrimarySuffix(c.primarySuffix))
 
 	// ignore `daemonSetScaleDownNodeSelector` node selector
 	for key := range daemonSetScaleDownNodeSelector {
@@ -160,27 +204,51 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 	// update pod annotations to ensure a rolling update
 	annotations, err := makeAnnotations(canary.Spec.Template.Annotations)
 	if err != nil {
-		return fmt.Errorf("makeAnnotations failed: %w", err)
+		return nil, nil, fmt.Errorf("makeAnnotations failed: %w", err)
 	}
 
 	primaryCopy.Spec.Template.Annotations = annotations
 	primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryLabelValue, label)
 
-	// apply update
-	_, err = c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("updating daemonset %s.%s template spec failed: %w",
-			primaryCopy.GetName(), primaryCopy.Namespace, err)
-	}
-	return nil
+	return func() error {
+			if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix, cd.GetPrimarySuffix(c.primarySuffix)); err != nil {
+				return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+			}
+
+			// apply update
+			_, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("updating daemonset %s.%s template spec failed: %w",
+					primaryCopy.GetName(), primaryCopy.Namespace, err)
+			}
+			return nil
+		}, func() error {
+			// restore the primary spec read before the promotion
+			err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				current, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				current.Spec.MinReadySeconds = primary.Spec.MinReadySeconds
+				current.Spec.RevisionHistoryLimit = primary.Spec.RevisionHistoryLimit
+				current.Spec.UpdateStrategy = primary.Spec.UpdateStrategy
+				current.Spec.Template = primary.Spec.Template
+				_, err = c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Update(context.TODO(), current, metav1.UpdateOptions{})
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("restoring daemonset %s.%s template spec failed: %w", primaryName, cd.GetTargetNamespace(), err)
+			}
+			return nil
+		}, nil
 }
 
 // HasTargetChanged returns true if the canary DaemonSet pod spec has changed
 func (c *DaemonSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
-	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return false, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return false, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	// ignore `daemonSetScaleDownNodeSelector` node selector
@@ -193,16 +261,16 @@ func (c *DaemonSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, erro
 		canary.Spec.Template.Spec.NodeSelector = map[string]string{}
 	}
 
-	return hasSpecChanged(cd, canary.Spec.Template)
+	return hasSpecChanged(cd, excludePaths(canary.Spec.Template, c.excludePaths))
 }
 
 // GetMetadata returns the pod label selector and svc ports
 func (c *DaemonSetController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
 	targetName := cd.Spec.TargetRef.Name
 
-	canaryDae, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canaryDae, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return "", "", nil, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return "", "", nil, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	label, labelValue, err := c.getSelectorLabel(canaryDae)
@@ -219,36 +287,36 @@ func (c *DaemonSetController) GetMetadata(cd *flaggerv1.Canary) (string, string,
 
 func (c *DaemonSetController) createPrimaryDaemonSet(cd *flaggerv1.Canary, includeLabelPrefix []string) error {
 	targetName := cd.Spec.TargetRef.Name
-	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
 
-	canaryDae, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canaryDae, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	if canaryDae.Spec.UpdateStrategy.Type != "" &&
 		canaryDae.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
 		return fmt.Errorf("daemonset %s.%s must have RollingUpdate strategy but have %s",
-			targetName, cd.Namespace, canaryDae.Spec.UpdateStrategy.Type)
+			targetName, cd.GetTargetNamespace(), canaryDae.Spec.UpdateStrategy.Type)
 	}
 
 	// Create the labels map but filter unwanted labels
 	labels := includeLabelsByPrefix(canaryDae.Labels, includeLabelPrefix)
 
 	label, labelValue, err := c.getSelectorLabel(canaryDae)
-	primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+	primaryLabelValue := cd.GetPrimaryName(labelValue, c.primarySuffix)
 	if err != nil {
 		return fmt.Errorf("getSelectorLabel failed: %w", err)
 	}
 
-	primaryDae, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	primaryDae, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
 	if errors.IsNotFound(err) {
 		// create primary secrets and config maps
 		configRefs, err := c.configTracker.GetTargetConfigs(cd)
 		if err != nil {
 			return fmt.Errorf("GetTargetConfigs failed: %w", err)
 		}
-		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix); err != nil {
+		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix, cd.GetPrimarySuffix(c.primarySuffix)); err != nil {
 			return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
 		}
 		annotations, err := makeAnnotations(canaryDae.Spec.Template.Annotations)
@@ -259,17 +327,11 @@ func (c *DaemonSetController) createPrimaryDaemonSet(cd *flaggerv1.Canary, inclu
 		// create primary daemonset
 		primaryDae = &appsv1.DaemonSet{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:        primaryName,
-				Namespace:   cd.Namespace,
-				Labels:      makePrimaryLabels(labels, primaryLabelValue, label),
-				Annotations: canaryDae.Annotations,
-				OwnerReferences: []metav1.OwnerReference{
-					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
-						Group:   flaggerv1.SchemeGroupVersion.Group,
-						Version: flaggerv1.SchemeGroupVersion.Version,
-						Kind:    flaggerv1.CanaryKind,
-					}),
-				},
+				Name:            primaryName,
+				Namespace:       cd.GetTargetNamespace(),
+				Labels:          makePrimaryLabels(labels, primaryLabelValue, label),
+				Annotations:     cd.GetOwnerAnnotations(canaryDae.Annotations),
+				OwnerReferences: cd.GetOwnerReferences(),
 			},
 			Spec: appsv1.DaemonSetSpec{
 				MinReadySeconds:      canaryDae.Spec.MinReadySeconds,
@@ -286,17 +348,17 @@ func (c *DaemonSetController) createPrimaryDaemonSet(cd *flaggerv1.Canary, inclu
 						Annotations: annotations,
 					},
 					// update spec with the primary secrets and config maps
-					Spec: c.configTracker.ApplyPrimaryConfigs(canaryDae.Spec.Template.Spec, configRefs),
+					Spec: makePrimaryPodSelectors(c.configTracker.ApplyPrimaryConfigs(canaryDae.Spec.Template.Spec, configRefs, cd.GetPrimarySuffix(c.primarySuffix)), c.labels, labelValue, cd.GetPrimarySuffix(c.primarySuffix)),
 				},
 			},
 		}
 
-		_, err = c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Create(context.TODO(), primaryDae, metav1.CreateOptions{})
+		_, err = c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Create(context.TODO(), primaryDae, metav1.CreateOptions{})
 		if err != nil {
-			return fmt.Errorf("creating daemonset %s.%s failed: %w", primaryDae.Name, cd.Namespace, err)
+			return fmt.Errorf("creating daemonset %s.%s failed: %w", primaryDae.Name, cd.GetTargetNamespace(), err)
 		}
 
-		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("DaemonSet %s.%s created", primaryDae.GetName(), cd.Namespace)
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("DaemonSet %s.%s created", primaryDae.GetName(), cd.GetTargetNamespace())
 	}
 	return nil
 }
@@ -319,8 +381,20 @@ func (c *DaemonSetController) HaveDependenciesChanged(cd *flaggerv1.Canary) (boo
 	return c.configTracker.HasConfigChanged(cd)
 }
 
-//Finalize scale the reference instance from zero
+// Finalize scale the reference instance from zero
 func (c *DaemonSetController) Finalize(cd *flaggerv1.Canary) error {
+	switch cd.GetRevertPolicy() {
+	case flaggerv1.RevertPolicyDelete:
+		err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Delete(context.TODO(), cd.Spec.TargetRef.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("daemonset %s.%s delete error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
+		}
+		return nil
+	case flaggerv1.RevertPolicyRestorePrimary:
+		if err := c.restorePrimaryTemplate(cd); err != nil {
+			return err
+		}
+	}
 	if err := c.ScaleFromZero(cd); err != nil {
 		return fmt.Errorf("ScaleFromZero failed: %w", err)
 	}
@@ -30,15 +30,15 @@ import (
 // IsPrimaryReady checks the primary daemonset status and returns an error if
 // the daemonset is in the middle of a rolling update
 func (c *DaemonSetController) IsPrimaryReady(cd *flaggerv1.Canary) error {
-	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
-	primary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	primary, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("daemonset %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		return fmt.Errorf("daemonset %s.%s get query error: %w", primaryName, cd.GetTargetNamespace(), err)
 	}
 
-	_, err = c.isDaemonSetReady(cd, primary)
+	_, err = c.isDaemonSetReady(cd, primary, cd.GetAnalysisPrimaryReadyThreshold())
 	if err != nil {
-		return fmt.Errorf("primary daemonset %s.%s not ready: %w", primaryName, cd.Namespace, err)
+		return fmt.Errorf("primary daemonset %s.%s not ready: %w", primaryName, cd.GetTargetNamespace(), err)
 	}
 	return nil
 }
@@ -47,26 +47,28 @@ func (c *DaemonSetController) IsPrimaryReady(cd *flaggerv1.Canary) error {
 // the daemonset is in the middle of a rolling update
 func (c *DaemonSetController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
-	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return true, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return true, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
-	retryable, err := c.isDaemonSetReady(cd, canary)
+	retryable, err := c.isDaemonSetReady(cd, canary, cd.GetAnalysisCanaryReadyThreshold())
 	if err != nil {
 		return retryable, fmt.Errorf("canary damonset %s.%s not ready with retryable %v: %w",
-			targetName, cd.Namespace, retryable, err)
+			targetName, cd.GetTargetNamespace(), retryable, err)
 	}
 	return true, nil
 }
 
 // isDaemonSetReady determines if a daemonset is ready by checking the number of old version daemons
+// and the percentage of available daemons given by the ready threshold
 // reference: https://github.com/kubernetes/kubernetes/blob/5232ad4a00ec93942d0b2c6359ee6cd1201b46bc/pkg/kubectl/rollout_status.go#L110
-func (c *DaemonSetController) isDaemonSetReady(cd *flaggerv1.Canary, daemonSet *appsv1.DaemonSet) (bool, error) {
+func (c *DaemonSetController) isDaemonSetReady(cd *flaggerv1.Canary, daemonSet *appsv1.DaemonSet, readyThreshold int) (bool, error) {
 	if daemonSet.Generation <= daemonSet.Status.ObservedGeneration {
 		// calculate conditions
 		newCond := daemonSet.Status.UpdatedNumberScheduled < daemonSet.Status.DesiredNumberScheduled
-		availableCond := daemonSet.Status.NumberAvailable < daemonSet.Status.DesiredNumberScheduled
+		requiredAvailable := readyReplicas(daemonSet.Status.DesiredNumberScheduled, readyThreshold)
+		availableCond := daemonSet.Status.NumberAvailable < requiredAvailable
 		if !newCond && !availableCond {
 			return true, nil
 		}
@@ -83,8 +85,8 @@ func (c *DaemonSetController) isDaemonSetReady(cd *flaggerv1.Canary, daemonSet *
 			return true, fmt.Errorf("waiting for rollout to finish: %d out of %d new pods have been updated",
 				daemonSet.Status.UpdatedNumberScheduled, daemonSet.Status.DesiredNumberScheduled)
 		} else if availableCond {
-			return true, fmt.Errorf("waiting for rollout to finish: %d of %d updated pods are available",
-				daemonSet.Status.NumberAvailable, daemonSet.Status.DesiredNumberScheduled)
+			return true, fmt.Errorf("waiting for rollout to finish: %d of %d updated pods are available, %d required",
+				daemonSet.Status.NumberAvailable, daemonSet.Status.DesiredNumberScheduled, requiredAvailable)
 		}
 	}
 	return true, fmt.Errorf("waiting for rollout to finish: observed daemonset generation less then desired generation")
@@ -48,7 +48,7 @@ func TestDaemonSetController_isDaemonSetReady(t *testing.T) {
 	// observed generation is less than desired generation
 	ds := &appsv1.DaemonSet{Status: appsv1.DaemonSetStatus{}}
 	ds.Status.ObservedGeneration--
-	retryable, err := mocks.controller.isDaemonSetReady(cd, ds)
+	retryable, err := mocks.controller.isDaemonSetReady(cd, ds, 100)
 	require.Error(t, err)
 	require.True(t, retryable)
 
@@ -58,7 +58,7 @@ func TestDaemonSetController_isDaemonSetReady(t *testing.T) {
 		DesiredNumberScheduled: 1,
 		NumberAvailable:        1,
 	}}
-	retryable, err = mocks.controller.isDaemonSetReady(cd, ds)
+	retryable, err = mocks.controller.isDaemonSetReady(cd, ds, 100)
 	require.NoError(t, err)
 	require.True(t, retryable)
 
@@ -69,7 +69,7 @@ func TestDaemonSetController_isDaemonSetReady(t *testing.T) {
 	}}
 	cd.Status.LastTransitionTime = metav1.Now()
 	cd.Spec.ProgressDeadlineSeconds = int32p(-1e6)
-	retryable, err = mocks.controller.isDaemonSetReady(cd, ds)
+	retryable, err = mocks.controller.isDaemonSetReady(cd, ds, 100)
 	require.Error(t, err)
 	require.False(t, retryable)
 
@@ -80,7 +80,7 @@ func TestDaemonSetController_isDaemonSetReady(t *testing.T) {
 		NumberAvailable:        1,
 	}}
 	cd.Spec.ProgressDeadlineSeconds = int32p(1e6)
-	retryable, err = mocks.controller.isDaemonSetReady(cd, ds)
+	retryable, err = mocks.controller.isDaemonSetReady(cd, ds, 100)
 	require.Error(t, err)
 	require.True(t, retryable)
 	require.True(t, strings.Contains(err.Error(), "new pods"))
@@ -91,8 +91,35 @@ func TestDaemonSetController_isDaemonSetReady(t *testing.T) {
 		DesiredNumberScheduled: 1,
 		NumberAvailable:        0,
 	}}
-	retryable, err = mocks.controller.isDaemonSetReady(cd, ds)
+	retryable, err = mocks.controller.isDaemonSetReady(cd, ds, 100)
 	require.Error(t, err)
 	require.True(t, retryable)
 	require.True(t, strings.Contains(err.Error(), "available"))
 }
+
+func TestDaemonSetController_isDaemonSetReadyThreshold(t *testing.T) {
+	dc := daemonsetConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDaemonSetFixture(dc)
+	cd := &flaggerv1.Canary{}
+	cd.Status.LastTransitionTime = metav1.Now()
+	cd.Spec.ProgressDeadlineSeconds = int32p(1e6)
+
+	ds := &appsv1.DaemonSet{Status: appsv1.DaemonSetStatus{
+		UpdatedNumberScheduled: 10,
+		DesiredNumberScheduled: 10,
+		NumberAvailable:        9,
+	}}
+	_, err := mocks.controller.isDaemonSetReady(cd, ds, 90)
+	require.NoError(t, err)
+
+	retryable, err := mocks.controller.isDaemonSetReady(cd, ds, 95)
+	require.Error(t, err)
+	require.True(t, retryable)
+	require.True(t, strings.Contains(err.Error(), "10 required"))
+
+	// the threshold doesn't apply to the updated pods
+	ds.Status.UpdatedNumberScheduled = 9
+	_, err = mocks.controller.isDaemonSetReady(cd, ds, 90)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "new pods"))
+}
@@ -27,9 +27,9 @@ import (
 
 // SyncStatus encodes the canary pod spec and updates the canary status
 func (c *DaemonSetController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
-	dae, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	dae, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("daemonset %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+		return fmt.Errorf("daemonset %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
 	}
 
 	// ignore `daemonSetScaleDownNodeSelector` node selector
@@ -47,11 +47,33 @@ func (c *DaemonSetController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.
 		return fmt.Errorf("GetConfigRefs failed: %w", err)
 	}
 
-	return syncCanaryStatus(c.flaggerClient, cd, status, dae.Spec.Template, func(cdCopy *flaggerv1.Canary) {
+	return syncCanaryStatus(c.flaggerClient, cd, status, excludePaths(dae.Spec.Template, c.excludePaths), func(cdCopy *flaggerv1.Canary) {
 		cdCopy.Status.TrackedConfigs = configs
 	})
 }
 
+// targetSpec returns the pod spec and the config refs of a daemonset listed in the canary targetRefs
+func (c *DaemonSetController) targetSpec(cd *flaggerv1.Canary) (interface{}, error) {
+	dae, err := c.kubeClient.AppsV1().DaemonSets(cd.GetTargetNamespace()).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("daemonset %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
+	}
+
+	// ignore `daemonSetScaleDownNodeSelector` node selector
+	for key := range daemonSetScaleDownNodeSelector {
+		delete(dae.Spec.Template.Spec.NodeSelector, key)
+	}
+	if dae.Spec.Template.Spec.NodeSelector == nil {
+		dae.Spec.Template.Spec.NodeSelector = map[string]string{}
+	}
+
+	configs, err := c.configTracker.GetConfigRefs(cd)
+	if err != nil {
+		return nil, fmt.Errorf("GetConfigRefs failed: %w", err)
+	}
+	return []interface{}{excludePaths(dae.Spec.Template, c.excludePaths), configs}, nil
+}
+
 // SetStatusFailedChecks updates the canary failed checks counter
 func (c *DaemonSetController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
 	return setStatusFailedChecks(c.flaggerClient, cd, val)
@@ -27,8 +27,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
@@ -42,15 +42,21 @@ type DeploymentController struct {
 	configTracker      Tracker
 	labels             []string
 	includeLabelPrefix []string
+	excludePaths       []string
+	hpaVersion         *hpaVersion
+	primarySuffix      string
 }
 
 // Initialize creates the primary deployment, hpa,
 // scales to zero the canary deployment and returns the pod selector label and container ports
 func (c *DeploymentController) Initialize(cd *flaggerv1.Canary) (err error) {
-	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
 	if err := c.createPrimaryDeployment(cd, c.includeLabelPrefix); err != nil {
 		return fmt.Errorf("createPrimaryDeployment failed: %w", err)
 	}
+	if err := c.reconcilePrimaryPdbs(cd); err != nil {
+		return fmt.Errorf("reconcilePrimaryPdbs failed: %w", err)
+	}
 
 	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
 		if !cd.SkipAnalysis() {
@@ -60,9 +66,9 @@ func (c *DeploymentController) Initialize(cd *flaggerv1.Canary) (err error) {
 		}
 
 		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
-			Infof("Scaling down Deployment %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+			Infof("Scaling down Deployment %s.%s", cd.Spec.TargetRef.Name, cd.GetTargetNamespace())
 		if err := c.ScaleToZero(cd); err != nil {
-			return fmt.Errorf("scaling down canary deployment %s.%s failed: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+			return fmt.Errorf("scaling down canary deployment %s.%s failed: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
 		}
 	}
 
@@ -70,7 +76,12 @@ func (c *DeploymentController) Initialize(cd *flaggerv1.Canary) (err error) {
 		if cd.Spec.AutoscalerRef.Kind == "HorizontalPodAutoscaler" {
 			if err := c.reconcilePrimaryHpa(cd, true); err != nil {
 				return fmt.Errorf(
-					"initial reconcilePrimaryHpa for %s.%s failed: %w", primaryName, cd.Namespace, err)
+					"initial reconcilePrimaryHpa for %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+			}
+		} else if cd.Spec.AutoscalerRef.Kind == "ScaledObject" {
+			if err := c.reconcilePrimaryScaledObject(cd, true); err != nil {
+				return fmt.Errorf(
+					"initial reconcilePrimaryScaledObject for %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
 			}
 		} else {
 			return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
@@ -81,32 +92,40 @@ func (c *DeploymentController) Initialize(cd *flaggerv1.Canary) (err error) {
 
 // Promote copies the pod spec, secrets and config maps from canary to primary
 func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
+	promote, _, err := c.preparePromotion(cd)
+	if err != nil {
+		return err
+	}
+	return promote()
+}
+
+// preparePromotion gets the canary and primary deployments and computes the primary spec,
+// the returned functions write the primary configs, the primary deployment and its autoscaler
+// and restore the previous primary spec
+func (c *DeploymentController) preparePromotion(cd *flaggerv1.Canary) (func() error, func() error, error) {
 	targetName := cd.Spec.TargetRef.Name
-	primaryName := fmt.Sprintf("%s-primary", targetName)
+	primaryName := cd.GetPrimaryName(targetName, c.primarySuffix)
 
-	canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return nil, nil, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	label, labelValue, err := c.getSelectorLabel(canary)
-	primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+	primaryLabelValue := cd.GetPrimaryName(labelValue, c.primarySuffix)
 	if err != nil {
-		return fmt.Errorf("getSelectorLabel failed: %w", err)
+		return nil, nil, fmt.Errorf("getSelectorLabel failed: %w", err)
 	}
 
-	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	primary, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		return nil, nil, fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.GetTargetNamespace(), err)
 	}
 
 	// promote secrets and config maps
 	configRefs, err := c.configTracker.GetTargetConfigs(cd)
 	if err != nil {
-		return fmt.Errorf("GetTargetConfigs failed: %w", err)
-	}
-	if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix); err != nil {
-		return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+		return nil, nil, fmt.Errorf("GetTargetConfigs failed: %w", err)
 	}
 
 	primaryCopy := primary.DeepCopy()
@@ -116,55 +135,94 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 	primaryCopy.Spec.Strategy = canary.Spec.Strategy
 
 	// update spec with primary secrets and config maps
-	primaryCopy.Spec.Template.Spec = c.getPrimaryDeploymentTemplateSpec(canary, configRefs)
+	primaryCopy.Spec.Template.Spec = c.getPrimaryDeploymentTemplateSpec(canary, configRefs, labelValue, cd.GetPrimarySuffix(c.primarySuffix))
 
 	// update pod annotations to ensure a rolling update
 	annotations, err := makeAnnotations(canary.Spec.Template.Annotations)
 	if err != nil {
-		return fmt.Errorf("makeAnnotations failed: %w", err)
+		return nil, nil, fmt.Errorf("makeAnnotations failed: %w", err)
 	}
 
 	primaryCopy.Spec.Template.Annotations = annotations
 	primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryLabelValue, label)
 
-	// apply update
-	_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("updating deployment %s.%s template spec failed: %w",
-			primaryCopy.GetName(), primaryCopy.Namespace, err)
-	}
+	return func() error {
+			if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix, cd.GetPrimarySuffix(c.primarySuffix)); err != nil {
+				return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+			}
 
-	// update HPA
-	if cd.Spec.AutoscalerRef != nil {
-		if cd.Spec.AutoscalerRef.Kind == "HorizontalPodAutoscaler" {
-			if err := c.reconcilePrimaryHpa(cd, false); err != nil {
-				return fmt.Errorf(
-					"reconcilePrimaryHpa for %s.%s failed: %w", primaryName, cd.Namespace, err)
+			// apply update
+			_, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("updating deployment %s.%s template spec failed: %w",
+					primaryCopy.GetName(), primaryCopy.Namespace, err)
 			}
-		} else {
-			return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
-		}
-	}
-	return nil
+
+			// update HPA
+			if cd.Spec.AutoscalerRef != nil {
+				if cd.Spec.AutoscalerRef.Kind == "HorizontalPodAutoscaler" {
+					if err := c.reconcilePrimaryHpa(cd, false); err != nil {
+						return fmt.Errorf(
+							"reconcilePrimaryHpa for %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+					}
+				} else if cd.Spec.AutoscalerRef.Kind == "ScaledObject" {
+					if err := c.reconcilePrimaryScaledObject(cd, false); err != nil {
+						return fmt.Errorf(
+							"reconcilePrimaryScaledObject for %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+					}
+				} else {
+					return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
+				}
+			}
+			return nil
+		}, func() error {
+			// restore the primary spec read before the promotion
+			err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				current, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				current.Spec.ProgressDeadlineSeconds = primary.Spec.ProgressDeadlineSeconds
+				current.Spec.MinReadySeconds = primary.Spec.MinReadySeconds
+				current.Spec.RevisionHistoryLimit = primary.Spec.RevisionHistoryLimit
+				current.Spec.Strategy = primary.Spec.Strategy
+				current.Spec.Template = primary.Spec.Template
+				_, err = c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Update(context.TODO(), current, metav1.UpdateOptions{})
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("restoring deployment %s.%s template spec failed: %w", primaryName, cd.GetTargetNamespace(), err)
+			}
+			return nil
+		}, nil
 }
 
 // HasTargetChanged returns true if the canary deployment pod spec has changed
 func (c *DeploymentController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
-	canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return false, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return false, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
-	return hasSpecChanged(cd, canary.Spec.Template)
+	return hasSpecChanged(cd, excludePaths(canary.Spec.Template, c.excludePaths))
 }
 
 // Scale sets the canary deployment replicas
 func (c *DeploymentController) ScaleToZero(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
-	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+
+	if err := c.pauseScaledObject(cd, true); err != nil {
+		return fmt.Errorf("pauseScaledObject failed: %w", err)
+	}
+
+	// record the replicas so that they can be restored after a controller restart
+	if err := recordOriginalReplicas(c.flaggerClient, cd, int32Default(dep.Spec.Replicas)); err != nil {
+		return fmt.Errorf("recordOriginalReplicas failed: %w", err)
 	}
 
 	depCopy := dep.DeepCopy()
@@ -172,24 +230,25 @@ func (c *DeploymentController) ScaleToZero(cd *flaggerv1.Canary) error {
 
 	_, err = c.kubeClient.AppsV1().Deployments(dep.Namespace).Update(context.TODO(), depCopy, metav1.UpdateOptions{})
 	if err != nil {
-		return fmt.Errorf("deployment %s.%s update query error: %w", targetName, cd.Namespace, err)
+		return fmt.Errorf("deployment %s.%s update query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 	return nil
 }
 
 func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
-	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
-	replicas := int32p(1)
-	if dep.Spec.Replicas != nil && *dep.Spec.Replicas > 0 {
-		replicas = dep.Spec.Replicas
+	if err := c.pauseScaledObject(cd, false); err != nil {
+		return fmt.Errorf("pauseScaledObject failed: %w", err)
 	}
+
+	replicas := scaleFromZeroReplicas(cd, int32Default(dep.Spec.Replicas))
 	depCopy := dep.DeepCopy()
-	depCopy.Spec.Replicas = replicas
+	depCopy.Spec.Replicas = int32p(replicas)
 
 	_, err = c.kubeClient.AppsV1().Deployments(dep.Namespace).Update(context.TODO(), depCopy, metav1.UpdateOptions{})
 	if err != nil {
@@ -202,9 +261,9 @@ func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
 func (c *DeploymentController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
 	targetName := cd.Spec.TargetRef.Name
 
-	canaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canaryDep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return "", "", nil, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return "", "", nil, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	label, labelValue, err := c.getSelectorLabel(canaryDep)
@@ -221,30 +280,30 @@ func (c *DeploymentController) GetMetadata(cd *flaggerv1.Canary) (string, string
 }
 func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary, includeLabelPrefix []string) error {
 	targetName := cd.Spec.TargetRef.Name
-	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
 
-	canaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canaryDep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	// Create the labels map but filter unwanted labels
 	labels := includeLabelsByPrefix(canaryDep.Labels, includeLabelPrefix)
 
 	label, labelValue, err := c.getSelectorLabel(canaryDep)
-	primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+	primaryLabelValue := cd.GetPrimaryName(labelValue, c.primarySuffix)
 	if err != nil {
 		return fmt.Errorf("getSelectorLabel failed: %w", err)
 	}
 
-	primaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	primaryDep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
 	if errors.IsNotFound(err) {
 		// create primary secrets and config maps
 		configRefs, err := c.configTracker.GetTargetConfigs(cd)
 		if err != nil {
 			return fmt.Errorf("GetTargetConfigs failed: %w", err)
 		}
-		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix); err != nil {
+		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix, cd.GetPrimarySuffix(c.primarySuffix)); err != nil {
 			return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
 		}
 		annotations, err := makeAnnotations(canaryDep.Spec.Template.Annotations)
@@ -260,17 +319,11 @@ func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary, inc
 		// create primary deployment
 		primaryDep = &appsv1.Deployment{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:        primaryName,
-				Namespace:   cd.Namespace,
-				Labels:      makePrimaryLabels(labels, primaryLabelValue, label),
-				Annotations: canaryDep.Annotations,
-				OwnerReferences: []metav1.OwnerReference{
-					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
-						Group:   flaggerv1.SchemeGroupVersion.Group,
-						Version: flaggerv1.SchemeGroupVersion.Version,
-						Kind:    flaggerv1.CanaryKind,
-					}),
-				},
+				Name:            primaryName,
+				Namespace:       cd.GetTargetNamespace(),
+				Labels:          makePrimaryLabels(labels, primaryLabelValue, label),
+				Annotations:     cd.GetOwnerAnnotations(canaryDep.Annotations),
+				OwnerReferences: cd.GetOwnerReferences(),
 			},
 			Spec: appsv1.DeploymentSpec{
 				ProgressDeadlineSeconds: canaryDep.Spec.ProgressDeadlineSeconds,
@@ -289,29 +342,30 @@ func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary, inc
 						Annotations: annotations,
 					},
 					// update spec with the primary secrets and config maps
-					Spec: c.getPrimaryDeploymentTemplateSpec(canaryDep, configRefs),
+					Spec: c.getPrimaryDeploymentTemplateSpec(canaryDep, configRefs, labelValue, cd.GetPrimarySuffix(c.primarySuffix)),
 				},
 			},
 		}
 
-		_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Create(context.TODO(), primaryDep, metav1.CreateOptions{})
+		_, err = c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Create(context.TODO(), primaryDep, metav1.CreateOptions{})
 		if err != nil {
-			return fmt.Errorf("creating deployment %s.%s failed: %w", primaryDep.Name, cd.Namespace, err)
+			return fmt.Errorf("creating deployment %s.%s failed: %w", primaryDep.Name, cd.GetTargetNamespace(), err)
 		}
 
 		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
-			Infof("Deployment %s.%s created", primaryDep.GetName(), cd.Namespace)
+			Infof("Deployment %s.%s created", primaryDep.GetName(), cd.GetTargetNamespace())
 	}
 
 	return nil
 }
 
 func (c *DeploymentController) reconcilePrimaryHpa(cd *flaggerv1.Canary, init bool) error {
-	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
-	hpa, err := c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), cd.Spec.AutoscalerRef.Name, metav1.GetOptions{})
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	hpaClient := c.hpaClient(cd.GetTargetNamespace())
+	hpa, err := hpaClient.Get(context.TODO(), cd.Spec.AutoscalerRef.Name, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("HorizontalPodAutoscaler %s.%s get query error: %w",
-			cd.Spec.AutoscalerRef.Name, cd.Namespace, err)
+			cd.Spec.AutoscalerRef.Name, cd.GetTargetNamespace(), err)
 	}
 
 	hpaSpec := hpav2.HorizontalPodAutoscalerSpec{
@@ -326,34 +380,29 @@ func (c *DeploymentController) reconcilePrimaryHpa(cd *flaggerv1.Canary, init bo
 		Behavior:    hpa.Spec.Behavior,
 	}
 
-	primaryHpaName := fmt.Sprintf("%s-primary", cd.Spec.AutoscalerRef.Name)
-	primaryHpa, err := c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), primaryHpaName, metav1.GetOptions{})
+	primaryHpaName := cd.GetPrimaryName(cd.Spec.AutoscalerRef.Name, c.primarySuffix)
+	primaryHpa, err := hpaClient.Get(context.TODO(), primaryHpaName, metav1.GetOptions{})
 
 	// create HPA
 	if errors.IsNotFound(err) {
 		primaryHpa = &hpav2.HorizontalPodAutoscaler{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      primaryHpaName,
-				Namespace: cd.Namespace,
-				Labels:    hpa.Labels,
-				OwnerReferences: []metav1.OwnerReference{
-					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
-						Group:   flaggerv1.SchemeGroupVersion.Group,
-						Version: flaggerv1.SchemeGroupVersion.Version,
-						Kind:    flaggerv1.CanaryKind,
-					}),
-				},
+				Name:            primaryHpaName,
+				Namespace:       cd.GetTargetNamespace(),
+				Labels:          hpa.Labels,
+				Annotations:     cd.GetOwnerAnnotations(nil),
+				OwnerReferences: cd.GetOwnerReferences(),
 			},
 			Spec: hpaSpec,
 		}
 
-		_, err = c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Create(context.TODO(), primaryHpa, metav1.CreateOptions{})
+		_, err = hpaClient.Create(context.TODO(), primaryHpa, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("creating HorizontalPodAutoscaler %s.%s failed: %w",
 				primaryHpa.Name, primaryHpa.Namespace, err)
 		}
 		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof(
-			"HorizontalPodAutoscaler %s.%s created", primaryHpa.GetName(), cd.Namespace)
+			"HorizontalPodAutoscaler %s.%s created", primaryHpa.GetName(), cd.GetTargetNamespace())
 		return nil
 	} else if err != nil {
 		return fmt.Errorf("HorizontalPodAutoscaler %s.%s get query failed: %w",
@@ -372,13 +421,13 @@ func (c *DeploymentController) reconcilePrimaryHpa(cd *flaggerv1.Canary, init bo
 			hpaClone.Spec.Metrics = hpaSpec.Metrics
 			hpaClone.Spec.Behavior = hpaSpec.Behavior
 
-			_, err := c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Update(context.TODO(), hpaClone, metav1.UpdateOptions{})
+			_, err := hpaClient.Update(context.TODO(), hpaClone, metav1.UpdateOptions{})
 			if err != nil {
 				return fmt.Errorf("updating HorizontalPodAutoscaler %s.%s failed: %w",
 					hpaClone.Name, hpaClone.Namespace, err)
 			}
 			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
-				Infof("HorizontalPodAutoscaler %s.%s updated", primaryHpa.GetName(), cd.Namespace)
+				Infof("HorizontalPodAutoscaler %s.%s updated", primaryHpa.GetName(), cd.GetTargetNamespace())
 		}
 	}
 	return nil
@@ -406,16 +455,33 @@ func (c *DeploymentController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bo
 // during a delete to attempt to revert the deployment back to the original state.  Error is returned if unable
 // update the reference deployment replicas to the primary replicas
 func (c *DeploymentController) Finalize(cd *flaggerv1.Canary) error {
+	if err := c.deletePrimaryPdbs(cd); err != nil {
+		return fmt.Errorf("deletePrimaryPdbs failed: %w", err)
+	}
+
+	// with the Delete policy the target is removed instead of restored
+	if cd.GetRevertPolicy() == flaggerv1.RevertPolicyDelete {
+		err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Delete(context.TODO(), cd.Spec.TargetRef.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("deployment %s.%s delete error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
+		}
+		return nil
+	}
+
+	// give the autoscaling of the target back to KEDA
+	if err := c.pauseScaledObject(cd, false); err != nil {
+		return fmt.Errorf("pauseScaledObject failed: %w", err)
+	}
 
 	// get ref deployment
-	refDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	refDep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("deplyoment %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+		return fmt.Errorf("deplyoment %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
 	}
 
 	// get primary if possible, if not scale from zero
-	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
-	primaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	primaryDep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
 			if err := c.ScaleFromZero(cd); err != nil {
@@ -423,7 +489,26 @@ func (c *DeploymentController) Finalize(cd *flaggerv1.Canary) error {
 			}
 			return nil
 		}
-		return fmt.Errorf("deplyoment %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		return fmt.Errorf("deplyoment %s.%s get query error: %w", primaryName, cd.GetTargetNamespace(), err)
+	}
+
+	// run the last promoted revision under the original name
+	if cd.GetRevertPolicy() == flaggerv1.RevertPolicyRestorePrimary {
+		label, labelValue, err := c.getSelectorLabel(refDep)
+		if err != nil {
+			return fmt.Errorf("getSelectorLabel failed: %w", err)
+		}
+		configs, err := generatedConfigs(cd, c.configTracker)
+		if err != nil {
+			return err
+		}
+		refCopy := refDep.DeepCopy()
+		if restorePrimaryTemplate(&refCopy.Spec.Template, primaryDep.Spec.Template, configs, c.labels, label, labelValue, cd.GetPrimarySuffix(c.primarySuffix)) {
+			refDep, err = c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Update(context.TODO(), refCopy, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("deployment %s.%s update error: %w", refCopy.Name, cd.GetTargetNamespace(), err)
+			}
+		}
 	}
 
 	// if both ref and primary present update the replicas of the ref to match the primary
@@ -439,9 +524,9 @@ func (c *DeploymentController) Finalize(cd *flaggerv1.Canary) error {
 // Scale sets the canary deployment replicas
 func (c *DeploymentController) scale(cd *flaggerv1.Canary, replicas int32) error {
 	targetName := cd.Spec.TargetRef.Name
-	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("deployment %s.%s query error: %w", targetName, cd.Namespace, err)
+		return fmt.Errorf("deployment %s.%s query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	depCopy := dep.DeepCopy()
@@ -453,43 +538,9 @@ func (c *DeploymentController) scale(cd *flaggerv1.Canary, replicas int32) error
 	return nil
 }
 
-func (c *DeploymentController) getPrimaryDeploymentTemplateSpec(canaryDep *appsv1.Deployment, refs map[string]ConfigRef) corev1.PodSpec {
-	spec := c.configTracker.ApplyPrimaryConfigs(canaryDep.Spec.Template.Spec, refs)
-
-	// update TopologySpreadConstraints
-	for _, topologySpreadConstraint := range spec.TopologySpreadConstraints {
-		c.appendPrimarySuffixToValuesIfNeeded(topologySpreadConstraint.LabelSelector, canaryDep)
-	}
-
-	// update affinity
-	if affinity := spec.Affinity; affinity != nil {
-		if podAntiAffinity := affinity.PodAntiAffinity; podAntiAffinity != nil {
-			for _, preferredAntiAffinity := range podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
-				c.appendPrimarySuffixToValuesIfNeeded(preferredAntiAffinity.PodAffinityTerm.LabelSelector, canaryDep)
-			}
-
-			for _, requiredAntiAffinity := range podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
-				c.appendPrimarySuffixToValuesIfNeeded(requiredAntiAffinity.LabelSelector, canaryDep)
-			}
-		}
-	}
-
-	return spec
-}
-
-func (c *DeploymentController) appendPrimarySuffixToValuesIfNeeded(labelSelector *metav1.LabelSelector, canaryDep *appsv1.Deployment) {
-	if labelSelector != nil {
-		for _, matchExpression := range labelSelector.MatchExpressions {
-			if contains(c.labels, matchExpression.Key) {
-				for i := range matchExpression.Values {
-					if matchExpression.Values[i] == canaryDep.Name {
-						matchExpression.Values[i] += "-primary"
-						break
-					}
-				}
-			}
-		}
-	}
+func (c *DeploymentController) getPrimaryDeploymentTemplateSpec(canaryDep *appsv1.Deployment, refs map[string]ConfigRef, labelValue string, suffix string) corev1.PodSpec {
+	spec := c.configTracker.ApplyPrimaryConfigs(canaryDep.Spec.Template.Spec, refs, suffix)
+	return makePrimaryPodSelectors(spec, c.labels, labelValue, suffix)
 }
 
 func contains(slice []string, val string) bool {
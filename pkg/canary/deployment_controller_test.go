@@ -134,6 +134,109 @@ func TestDeploymentController_ScaleToZero(t *testing.T) {
 	assert.Equal(t, int32(0), *c.Spec.Replicas)
 }
 
+func TestDeploymentController_ScaleFromZeroOriginalReplicas(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Replicas = int32p(3)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.controller.ScaleToZero(mocks.canary))
+
+	// the canary is read again as after a controller restart
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, cd.Status.OriginalTargets["deployment/podinfo"].Replicas)
+	assert.Equal(t, int32(3), *cd.Status.OriginalTargets["deployment/podinfo"].Replicas)
+
+	require.NoError(t, mocks.controller.ScaleFromZero(cd))
+	c, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *c.Spec.Replicas)
+}
+
+func TestDeploymentController_CrossNamespaceTarget(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.canary.Namespace = "control"
+	mocks.canary.Spec.TargetRef.Namespace = "default"
+	_, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("control").Create(context.TODO(), mocks.canary, metav1.CreateOptions{})
+	require.NoError(t, err)
+	mocks.initializeCanary(t)
+
+	// the objects in the target namespace can't be owned by the canary
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, depPrimary.OwnerReferences)
+	assert.True(t, mocks.canary.IsOwnerOf(depPrimary))
+
+	configPrimary, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, configPrimary.OwnerReferences)
+
+	hpaPrimary, err := mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, depPrimary.Name, hpaPrimary.Spec.ScaleTargetRef.Name)
+
+	require.NoError(t, mocks.controller.ScaleToZero(mocks.canary))
+	c, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *c.Spec.Replicas)
+}
+
+func TestDeploymentController_PrimarySuffix(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.canary.Spec.PrimarySuffix = "-stable"
+	mocks.initializeCanary(t)
+
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-stable", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-stable", depPrimary.Spec.Selector.MatchLabels[dc.label])
+	assert.Equal(t, "podinfo-stable", depPrimary.Spec.Template.Labels[dc.label])
+
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-stable", metav1.GetOptions{})
+	require.NoError(t, err)
+	for _, env := range depPrimary.Spec.Template.Spec.Containers[0].EnvFrom {
+		if env.ConfigMapRef != nil {
+			assert.True(t, strings.HasSuffix(env.ConfigMapRef.Name, "-stable"), env.ConfigMapRef.Name)
+		}
+	}
+
+	hpaPrimary, err := mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo-stable", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-stable", hpaPrimary.Spec.ScaleTargetRef.Name)
+}
+
+func TestDeploymentController_DefaultPrimarySuffix(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.controller.primarySuffix = "-p"
+	mocks.initializeCanary(t)
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-p", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-p", depPrimary.Spec.Selector.MatchLabels[dc.label])
+
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-p", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// the canary suffix takes precedence over the one of the controller
+	mocks = newDeploymentFixture(dc)
+	mocks.controller.primarySuffix = "-p"
+	mocks.canary.Spec.PrimarySuffix = "-stable"
+	mocks.initializeCanary(t)
+
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-stable", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
 func TestDeploymentController_NoConfigTracking(t *testing.T) {
 	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
 	mocks := newDeploymentFixture(dc)
@@ -264,6 +367,47 @@ func TestDeploymentController_Finalize(t *testing.T) {
 	}
 }
 
+func TestDeploymentController_FinalizeRevertPolicy(t *testing.T) {
+	t.Run("RestorePrimary", func(t *testing.T) {
+		dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+		mocks := newDeploymentFixture(dc)
+		mocks.initializeCanary(t)
+
+		// the configs of the promoted revision are tracked in the canary status
+		tracked, err := mocks.controller.configTracker.GetConfigRefs(mocks.canary)
+		require.NoError(t, err)
+		mocks.canary.Status.TrackedConfigs = tracked
+
+		_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), newDeploymentControllerTestV2(), metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		mocks.canary.Spec.RevertPolicy = flaggerv1.RevertPolicyRestorePrimary
+		require.NoError(t, mocks.controller.Finalize(mocks.canary))
+
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.0", dep.Spec.Template.Spec.Containers[0].Image)
+		assert.Equal(t, int32(1), *dep.Spec.Replicas)
+
+		// the primary config names and label value are mapped back
+		original := newDeploymentControllerTest(dc)
+		assert.Equal(t, original.Spec.Template.Spec, dep.Spec.Template.Spec)
+		assert.Equal(t, original.Spec.Template.Labels, dep.Spec.Template.Labels)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+		mocks := newDeploymentFixture(dc)
+		mocks.initializeCanary(t)
+
+		mocks.canary.Spec.RevertPolicy = flaggerv1.RevertPolicyDelete
+		require.NoError(t, mocks.controller.Finalize(mocks.canary))
+
+		_, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
+
 func TestDeploymentController_AntiAffinityAndTopologySpreadConstraints(t *testing.T) {
 	t.Run("deployment", func(t *testing.T) {
 		dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
@@ -18,7 +18,6 @@ package canary
 
 import (
 	"context"
-	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -59,9 +58,9 @@ func (d deploymentControllerFixture) initializeCanary(t *testing.T) {
 	err := d.controller.Initialize(d.canary)
 	require.Error(t, err) // not ready yet
 
-	primaryName := fmt.Sprintf("%s-primary", d.canary.Spec.TargetRef.Name)
+	primaryName := d.canary.GetPrimaryName(d.canary.Spec.TargetRef.Name, d.controller.primarySuffix)
 	p, err := d.controller.kubeClient.AppsV1().
-		Deployments(d.canary.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		Deployments(d.canary.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
 	require.NoError(t, err)
 
 	p.Status = appsv1.DeploymentStatus{
@@ -71,7 +70,7 @@ func (d deploymentControllerFixture) initializeCanary(t *testing.T) {
 		AvailableReplicas: 1,
 	}
 
-	_, err = d.controller.kubeClient.AppsV1().Deployments(d.canary.Namespace).Update(context.TODO(), p, metav1.UpdateOptions{})
+	_, err = d.controller.kubeClient.AppsV1().Deployments(d.canary.GetTargetNamespace()).Update(context.TODO(), p, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
 	require.NoError(t, d.controller.Initialize(d.canary))
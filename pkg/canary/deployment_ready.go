@@ -31,15 +31,15 @@ import (
 // the deployment is in the middle of a rolling update or if the pods are unhealthy
 // it will return a non retryable error if the rolling update is stuck
 func (c *DeploymentController) IsPrimaryReady(cd *flaggerv1.Canary) error {
-	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
-	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	primary, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.GetTargetNamespace(), err)
 	}
 
 	_, err = c.isDeploymentReady(primary, cd.GetProgressDeadlineSeconds())
 	if err != nil {
-		return fmt.Errorf("%s.%s not ready: %w", primaryName, cd.Namespace, err)
+		return fmt.Errorf("%s.%s not ready: %w", primaryName, cd.GetTargetNamespace(), err)
 	}
 
 	if primary.Spec.Replicas == int32p(0) {
@@ -54,16 +54,16 @@ func (c *DeploymentController) IsPrimaryReady(cd *flaggerv1.Canary) error {
 // it will return a non retriable error if the rolling update is stuck
 func (c *DeploymentController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
-	canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
-		return true, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+		return true, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
 	}
 
 	retryable, err := c.isDeploymentReady(canary, cd.GetProgressDeadlineSeconds())
 	if err != nil {
 		return retryable, fmt.Errorf(
 			"canary deployment %s.%s not ready: %w",
-			targetName, cd.Namespace, err,
+			targetName, cd.GetTargetNamespace(), err,
 		)
 	}
 	return true, nil
@@ -27,9 +27,9 @@ import (
 
 // SyncStatus encodes the canary pod spec and updates the canary status
 func (c *DeploymentController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
-	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("deployment %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+		return fmt.Errorf("deployment %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
 	}
 
 	configs, err := c.configTracker.GetConfigRefs(cd)
@@ -37,11 +37,25 @@ func (c *DeploymentController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1
 		return fmt.Errorf("GetConfigRefs failed: %w", err)
 	}
 
-	return syncCanaryStatus(c.flaggerClient, cd, status, dep.Spec.Template, func(cdCopy *flaggerv1.Canary) {
+	return syncCanaryStatus(c.flaggerClient, cd, status, excludePaths(dep.Spec.Template, c.excludePaths), func(cdCopy *flaggerv1.Canary) {
 		cdCopy.Status.TrackedConfigs = configs
 	})
 }
 
+// targetSpec returns the pod spec and the config refs of a deployment listed in the canary targetRefs
+func (c *DeploymentController) targetSpec(cd *flaggerv1.Canary) (interface{}, error) {
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("deployment %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
+	}
+
+	configs, err := c.configTracker.GetConfigRefs(cd)
+	if err != nil {
+		return nil, fmt.Errorf("GetConfigRefs failed: %w", err)
+	}
+	return []interface{}{excludePaths(dep.Spec.Template, c.excludePaths), configs}, nil
+}
+
 // SetStatusFailedChecks updates the canary failed checks counter
 func (c *DeploymentController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
 	return setStatusFailedChecks(c.flaggerClient, cd, val)
@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -76,3 +77,39 @@ func TestDeploymentController_SetState(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, res.Status.Phase)
 }
+
+func TestDeploymentController_PromotionComplete(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	err := mocks.controller.SetStatusPhase(mocks.canary, flaggerv1.CanaryPhaseProgressing)
+	require.NoError(t, err)
+
+	res, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	condition := getStatusCondition(res.Status, flaggerv1.PromotionCompleteType)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	assert.Nil(t, res.Status.CompletionTime)
+
+	err = mocks.controller.SetStatusPhase(res, flaggerv1.CanaryPhaseSucceeded)
+	require.NoError(t, err)
+
+	res, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	condition = getStatusCondition(res.Status, flaggerv1.PromotionCompleteType)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, string(flaggerv1.CanaryPhaseSucceeded), condition.Reason)
+	require.NotNil(t, res.Status.CompletionTime)
+
+	// the completion time is stable while the phase doesn't change
+	completionTime := *res.Status.CompletionTime
+	err = mocks.controller.SetStatusPhase(res, flaggerv1.CanaryPhaseSucceeded)
+	require.NoError(t, err)
+
+	res, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, completionTime, *res.Status.CompletionTime)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ParseExcludePath splits a pod template field path into segments,
+// the segments are separated by dots or written in brackets when they contain dots,
+// e.g. metadata.annotations[policies.kyverno.io/last-applied-patches] or spec.containers[*].securityContext
+func ParseExcludePath(path string) ([]string, error) {
+	var segments []string
+	var current strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '.':
+			if current.Len() > 0 {
+				segments = append(segments, current.String())
+				current.Reset()
+			} else if i == 0 || path[i-1] != ']' {
+				return nil, fmt.Errorf("path %s has an empty segment", path)
+			}
+		case '[':
+			if current.Len() > 0 {
+				segments = append(segments, current.String())
+				current.Reset()
+			}
+			end := strings.IndexByte(path[i:], ']')
+			if end < 2 {
+				return nil, fmt.Errorf("path %s has an unterminated or empty bracket", path)
+			}
+			segments = append(segments, path[i+1:i+end])
+			i += end
+		default:
+			current.WriteByte(path[i])
+		}
+	}
+	if current.Len() > 0 {
+		segments = append(segments, current.String())
+	} else if len(path) == 0 || path[len(path)-1] == '.' {
+		return nil, fmt.Errorf("path %s has an empty segment", path)
+	}
+	if segments[0] != "metadata" && segments[0] != "spec" {
+		return nil, fmt.Errorf("path %s must start with metadata or spec", path)
+	}
+	return segments, nil
+}
+
+// removePath deletes the field at the path segments, the * segment matches all list items and map keys,
+// the maps left empty are removed like the empty fields omitted from the JSON encoding
+func removePath(obj interface{}, segments []string) bool {
+	if len(segments) == 0 {
+		return false
+	}
+	key, last := segments[0], len(segments) == 1
+	removed := false
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if key != "*" && k != key {
+				continue
+			}
+			if last {
+				delete(v, k)
+				removed = true
+			} else if removePath(child, segments[1:]) {
+				if m, ok := child.(map[string]interface{}); ok && len(m) == 0 {
+					delete(v, k)
+				}
+				removed = true
+			}
+		}
+	case []interface{}:
+		if key != "*" || last {
+			return false
+		}
+		for _, child := range v {
+			if removePath(child, segments[1:]) {
+				removed = true
+			}
+		}
+	}
+	return removed
+}
+
+// excludePaths returns a copy of the pod template without the excluded fields for the spec hash,
+// the template is decoded again even if none of the fields are set so that the hashes
+// with and without the excluded fields are computed from the same representation
+func excludePaths(template corev1.PodTemplateSpec, paths []string) corev1.PodTemplateSpec {
+	if len(paths) == 0 {
+		return template
+	}
+
+	data, err := json.Marshal(template)
+	if err != nil {
+		return template
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return template
+	}
+
+	for _, path := range paths {
+		segments, err := ParseExcludePath(path)
+		if err != nil {
+			continue
+		}
+		removePath(obj, segments)
+	}
+
+	if data, err = json.Marshal(obj); err != nil {
+		return template
+	}
+	var result corev1.PodTemplateSpec
+	if err := json.Unmarshal(data, &result); err != nil {
+		return template
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestParseExcludePath(t *testing.T) {
+	segments, err := ParseExcludePath("metadata.annotations[policies.kyverno.io/last-applied-patches]")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"metadata", "annotations", "policies.kyverno.io/last-applied-patches"}, segments)
+
+	segments, err = ParseExcludePath("spec.containers[*].securityContext")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spec", "containers", "*", "securityContext"}, segments)
+
+	for _, path := range []string{"", "spec..containers", "spec.", "spec.containers[", "spec[]", "status.phase"} {
+		_, err := ParseExcludePath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestExcludePaths(t *testing.T) {
+	template := newDeploymentControllerTest(deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}).Spec.Template
+	paths := []string{
+		"metadata.annotations[policies.kyverno.io/last-applied-patches]",
+		"spec.containers[*].securityContext",
+	}
+
+	assert.Equal(t, computeHash(template), computeHash(excludePaths(template, nil)))
+
+	mutated := template.DeepCopy()
+	if mutated.Annotations == nil {
+		mutated.Annotations = map[string]string{}
+	}
+	mutated.Annotations["policies.kyverno.io/last-applied-patches"] = "add-default-securitycontext"
+	runAsNonRoot := true
+	mutated.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{RunAsNonRoot: &runAsNonRoot}
+
+	result := excludePaths(*mutated, paths)
+	assert.NotContains(t, result.Annotations, "policies.kyverno.io/last-applied-patches")
+	assert.Nil(t, result.Spec.Containers[0].SecurityContext)
+	assert.NotNil(t, mutated.Spec.Containers[0].SecurityContext)
+
+	// the hash doesn't change when the excluded fields are set
+	assert.Equal(t, computeHash(excludePaths(template, paths)), computeHash(result))
+}
+
+func TestDeploymentController_HasTargetChangedExcludePaths(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.controller.excludePaths = []string{"metadata.annotations[policies.kyverno.io/last-applied-patches]"}
+	mocks.initializeCanary(t)
+
+	canary, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.controller.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitializing}))
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	depClone := dep.DeepCopy()
+	if depClone.Spec.Template.Annotations == nil {
+		depClone.Spec.Template.Annotations = map[string]string{}
+	}
+	depClone.Spec.Template.Annotations["policies.kyverno.io/last-applied-patches"] = "add-labels"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), depClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	canary, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	isNew, err := mocks.controller.HasTargetChanged(canary)
+	require.NoError(t, err)
+	assert.False(t, isNew)
+
+	depClone.Spec.Template.Annotations["prometheus.io/scrape"] = "true"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), depClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	isNew, err = mocks.controller.HasTargetChanged(canary)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+}
@@ -17,19 +17,28 @@ limitations under the License.
 package canary
 
 import (
+	"sync"
+
 	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 )
 
 type Factory struct {
 	kubeClient         kubernetes.Interface
+	dynamicClient      dynamic.Interface
 	flaggerClient      clientset.Interface
 	logger             *zap.SugaredLogger
 	configTracker      Tracker
 	labels             []string
 	includeLabelPrefix []string
+	excludePaths       []string
+	primarySuffix      string
+	hpaVersion         *hpaVersion
+	mu                 sync.RWMutex
 }
 
 func NewFactory(kubeClient kubernetes.Interface,
@@ -45,10 +54,45 @@ func NewFactory(kubeClient kubernetes.Interface,
 		configTracker:      configTracker,
 		labels:             labels,
 		includeLabelPrefix: includeLabelPrefix,
+		hpaVersion:         &hpaVersion{},
 	}
 }
 
+// SetIncludeLabelPrefix changes the label prefixes copied to the primary workloads and configs,
+// the change applies to the controllers returned by the next calls to Controller
+func (factory *Factory) SetIncludeLabelPrefix(includeLabelPrefix []string) {
+	factory.mu.Lock()
+	defer factory.mu.Unlock()
+	factory.includeLabelPrefix = includeLabelPrefix
+}
+
+// SetExcludePaths sets the pod template fields that are ignored when detecting the target changes,
+// such as the fields mutated by the admission policies
+func (factory *Factory) SetExcludePaths(paths []string) {
+	factory.mu.Lock()
+	defer factory.mu.Unlock()
+	factory.excludePaths = paths
+}
+
+// SetDynamicClient sets the client of the custom resources that are managed through their scale subresource
+// and of the Knative Services
+func (factory *Factory) SetDynamicClient(dynamicClient dynamic.Interface) {
+	factory.mu.Lock()
+	defer factory.mu.Unlock()
+	factory.dynamicClient = dynamicClient
+}
+
+// SetPrimarySuffix sets the suffix of the primary objects of the canaries that don't set spec.primarySuffix
+func (factory *Factory) SetPrimarySuffix(suffix string) {
+	factory.mu.Lock()
+	defer factory.mu.Unlock()
+	factory.primarySuffix = suffix
+}
+
 func (factory *Factory) Controller(kind string) Controller {
+	factory.mu.RLock()
+	defer factory.mu.RUnlock()
+
 	deploymentCtrl := &DeploymentController{
 		logger:             factory.logger,
 		kubeClient:         factory.kubeClient,
@@ -56,6 +100,9 @@ func (factory *Factory) Controller(kind string) Controller {
 		labels:             factory.labels,
 		configTracker:      factory.configTracker,
 		includeLabelPrefix: factory.includeLabelPrefix,
+		excludePaths:       factory.excludePaths,
+		primarySuffix:      factory.primarySuffix,
+		hpaVersion:         factory.hpaVersion,
 	}
 	daemonSetCtrl := &DaemonSetController{
 		logger:        factory.logger,
@@ -63,11 +110,46 @@ func (factory *Factory) Controller(kind string) Controller {
 		flaggerClient: factory.flaggerClient,
 		labels:        factory.labels,
 		configTracker: factory.configTracker,
+		excludePaths:  factory.excludePaths,
+		primarySuffix: factory.primarySuffix,
+	}
+	statefulSetCtrl := &StatefulSetController{
+		logger:             factory.logger,
+		kubeClient:         factory.kubeClient,
+		flaggerClient:      factory.flaggerClient,
+		labels:             factory.labels,
+		configTracker:      factory.configTracker,
+		includeLabelPrefix: factory.includeLabelPrefix,
+		excludePaths:       factory.excludePaths,
+		primarySuffix:      factory.primarySuffix,
+		hpaVersion:         factory.hpaVersion,
+	}
+	kruiseDaemonSetCtrl := &KruiseDaemonSetController{
+		logger:        factory.logger,
+		flaggerClient: factory.flaggerClient,
+		labels:        factory.labels,
+		excludePaths:  factory.excludePaths,
+	}
+	scaleSubresourceCtrl := &ScaleSubresourceController{
+		logger:        factory.logger,
+		kubeClient:    factory.kubeClient,
+		dynamicClient: factory.dynamicClient,
+		flaggerClient: factory.flaggerClient,
+		labels:        factory.labels,
+		excludePaths:  factory.excludePaths,
+		primarySuffix: factory.primarySuffix,
+		hpaVersion:    factory.hpaVersion,
+	}
+	knativeServiceCtrl := &KnativeServiceController{
+		logger:        factory.logger,
+		dynamicClient: factory.dynamicClient,
+		flaggerClient: factory.flaggerClient,
 	}
 	serviceCtrl := &ServiceController{
 		logger:        factory.logger,
 		kubeClient:    factory.kubeClient,
 		flaggerClient: factory.flaggerClient,
+		primarySuffix: factory.primarySuffix,
 	}
 
 	switch kind {
@@ -75,8 +157,16 @@ func (factory *Factory) Controller(kind string) Controller {
 		return daemonSetCtrl
 	case "Deployment":
 		return deploymentCtrl
+	case flaggerv1.KruiseDaemonSetKind:
+		return kruiseDaemonSetCtrl
+	case flaggerv1.ScaleSubresourceKind:
+		return scaleSubresourceCtrl
+	case flaggerv1.KnativeServiceKind:
+		return knativeServiceCtrl
 	case "Service":
 		return serviceCtrl
+	case "StatefulSet":
+		return statefulSetCtrl
 	default:
 		return deploymentCtrl
 	}
@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	hpav2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+const hpaV2GroupVersion = "autoscaling/v2"
+
+// hpaInterface is the subset of the HPA API used to reconcile the primary autoscaler,
+// the autoscaling/v2 objects are decoded in the v2beta2 types as both versions have the same schema
+type hpaInterface interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*hpav2.HorizontalPodAutoscaler, error)
+	Create(ctx context.Context, hpa *hpav2.HorizontalPodAutoscaler, opts metav1.CreateOptions) (*hpav2.HorizontalPodAutoscaler, error)
+	Update(ctx context.Context, hpa *hpav2.HorizontalPodAutoscaler, opts metav1.UpdateOptions) (*hpav2.HorizontalPodAutoscaler, error)
+}
+
+// hpaVersion caches whether the cluster serves autoscaling/v2 for all the controllers of a factory,
+// the discovery API is queried until it answers with the group version or with not found
+type hpaVersion struct {
+	mu       sync.Mutex
+	resolved bool
+	v2       bool
+}
+
+// servesV2 returns true if the cluster serves autoscaling/v2,
+// a nil cache queries the discovery API on every call
+func (v *hpaVersion) servesV2(kubeClient kubernetes.Interface) bool {
+	discover := func() (bool, bool) {
+		_, err := kubeClient.Discovery().ServerResourcesForGroupVersion(hpaV2GroupVersion)
+		if err == nil {
+			return true, true
+		}
+		// the transient errors are retried at the next call
+		return false, errors.IsNotFound(err)
+	}
+	if v == nil {
+		v2, _ := discover()
+		return v2
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.resolved {
+		v.v2, v.resolved = discover()
+	}
+	return v.v2
+}
+
+// hpaClient returns a client of autoscaling/v2 if the cluster serves it,
+// otherwise it falls back to autoscaling/v2beta2
+func (c *DeploymentController) hpaClient(namespace string) hpaInterface {
+	if c.hpaVersion.servesV2(c.kubeClient) {
+		return &hpaV2Client{client: c.kubeClient.AutoscalingV2beta2().RESTClient(), namespace: namespace}
+	}
+	return c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace)
+}
+
+// hpaV2Client calls the autoscaling/v2 endpoints with the JSON encoding of the v2beta2 types
+type hpaV2Client struct {
+	client    rest.Interface
+	namespace string
+}
+
+func (h *hpaV2Client) Get(ctx context.Context, name string, opts metav1.GetOptions) (*hpav2.HorizontalPodAutoscaler, error) {
+	b, err := h.client.Get().
+		AbsPath(h.path(name)...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		SetHeader("Accept", "application/json").
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+	return decodeHPA(b)
+}
+
+func (h *hpaV2Client) Create(ctx context.Context, hpa *hpav2.HorizontalPodAutoscaler, opts metav1.CreateOptions) (*hpav2.HorizontalPodAutoscaler, error) {
+	body, err := encodeHPA(hpa)
+	if err != nil {
+		return nil, err
+	}
+	b, err := h.client.Post().
+		AbsPath(h.path("")...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		SetHeader("Accept", "application/json").
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+	return decodeHPA(b)
+}
+
+func (h *hpaV2Client) Update(ctx context.Context, hpa *hpav2.HorizontalPodAutoscaler, opts metav1.UpdateOptions) (*hpav2.HorizontalPodAutoscaler, error) {
+	body, err := encodeHPA(hpa)
+	if err != nil {
+		return nil, err
+	}
+	b, err := h.client.Put().
+		AbsPath(h.path(hpa.Name)...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		SetHeader("Accept", "application/json").
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+	return decodeHPA(b)
+}
+
+func (h *hpaV2Client) path(name string) []string {
+	segments := []string{"/apis", hpaV2GroupVersion, "namespaces", h.namespace, "horizontalpodautoscalers"}
+	if name != "" {
+		segments = append(segments, name)
+	}
+	return segments
+}
+
+func encodeHPA(hpa *hpav2.HorizontalPodAutoscaler) ([]byte, error) {
+	obj := hpa.DeepCopy()
+	obj.APIVersion = hpaV2GroupVersion
+	obj.Kind = "HorizontalPodAutoscaler"
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling HorizontalPodAutoscaler: %w", err)
+	}
+	return b, nil
+}
+
+func decodeHPA(b []byte) (*hpav2.HorizontalPodAutoscaler, error) {
+	var hpa hpav2.HorizontalPodAutoscaler
+	if err := json.Unmarshal(b, &hpa); err != nil {
+		return nil, fmt.Errorf("error unmarshaling HorizontalPodAutoscaler: %w", err)
+	}
+	return &hpa, nil
+}
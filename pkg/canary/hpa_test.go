@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hpav2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+// newHPAv2Server serves the autoscaling/v2 API with the objects kept in the hpas map,
+// the discovery requests are counted in discoveries
+func newHPAv2Server(t *testing.T, hpas map[string]*hpav2.HorizontalPodAutoscaler, discoveries *int) *httptest.Server {
+	prefix := "/apis/autoscaling/v2/namespaces/default/horizontalpodautoscalers"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/apis/autoscaling/v2" {
+			*discoveries++
+			json.NewEncoder(w).Encode(metav1.APIResourceList{
+				GroupVersion: hpaV2GroupVersion,
+				APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers", Namespaced: true, Kind: "HorizontalPodAutoscaler"}},
+			})
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		switch r.Method {
+		case http.MethodGet:
+			hpa, ok := hpas[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(metav1.Status{
+					TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+					Status:   metav1.StatusFailure,
+					Reason:   metav1.StatusReasonNotFound,
+					Code:     http.StatusNotFound,
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(hpa)
+		case http.MethodPost, http.MethodPut:
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			hpa, err := decodeHPA(b)
+			require.NoError(t, err)
+			assert.Equal(t, hpaV2GroupVersion, hpa.APIVersion)
+			hpas[hpa.Name] = hpa
+			json.NewEncoder(w).Encode(hpa)
+		}
+	}))
+}
+
+func TestDeploymentController_ReconcilePrimaryHpaV2(t *testing.T) {
+	canaryHpa := newDeploymentControllerTestHPA()
+	canaryHpa.APIVersion = hpaV2GroupVersion
+	hpas := map[string]*hpav2.HorizontalPodAutoscaler{"podinfo": canaryHpa}
+	discoveries := 0
+	ts := newHPAv2Server(t, hpas, &discoveries)
+	defer ts.Close()
+
+	kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: ts.URL})
+	require.NoError(t, err)
+	logger, _ := logger.NewLogger("debug")
+	ctrl := &DeploymentController{kubeClient: kubeClient, logger: logger, hpaVersion: &hpaVersion{}}
+	cd := newDeploymentControllerTestCanary(canaryConfigs{targetName: "podinfo"})
+
+	require.NoError(t, ctrl.reconcilePrimaryHpa(cd, true))
+	require.Contains(t, hpas, "podinfo-primary")
+	assert.Equal(t, "podinfo-primary", hpas["podinfo-primary"].Spec.ScaleTargetRef.Name)
+	assert.Equal(t, int32(99), *hpas["podinfo-primary"].Spec.Metrics[0].Resource.Target.AverageUtilization)
+
+	canaryHpa.Spec.MaxReplicas = 8
+	canaryHpa.Spec.Metrics = append(canaryHpa.Spec.Metrics, hpav2.MetricSpec{
+		Type: hpav2.ContainerResourceMetricSourceType,
+		ContainerResource: &hpav2.ContainerResourceMetricSource{
+			Name:      "memory",
+			Container: "podinfo",
+			Target:    hpav2.MetricTarget{Type: hpav2.UtilizationMetricType, AverageUtilization: int32p(80)},
+		},
+	})
+	require.NoError(t, ctrl.reconcilePrimaryHpa(cd, false))
+	assert.Equal(t, int32(8), hpas["podinfo-primary"].Spec.MaxReplicas)
+	require.Len(t, hpas["podinfo-primary"].Spec.Metrics, 2)
+	assert.Equal(t, "podinfo", hpas["podinfo-primary"].Spec.Metrics[1].ContainerResource.Container)
+
+	// the autoscaling/v2 availability is resolved once
+	assert.Equal(t, 1, discoveries)
+}
+
+func TestHPAVersion_ServesV2(t *testing.T) {
+	status := http.StatusInternalServerError
+	discoveries := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		discoveries++
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: ts.URL})
+	require.NoError(t, err)
+	v := &hpaVersion{}
+
+	// the transient errors are not cached
+	assert.False(t, v.servesV2(kubeClient))
+	assert.False(t, v.servesV2(kubeClient))
+	assert.Equal(t, 2, discoveries)
+
+	// not found is cached
+	status = http.StatusNotFound
+	assert.False(t, v.servesV2(kubeClient))
+	assert.False(t, v.servesV2(kubeClient))
+	assert.Equal(t, 3, discoveries)
+}
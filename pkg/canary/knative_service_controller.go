@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+var (
+	knativeServiceResource  = schema.GroupVersionResource{Group: flaggerv1.KnativeServingGroup, Version: "v1", Resource: "services"}
+	knativeRevisionResource = schema.GroupVersionResource{Group: flaggerv1.KnativeServingGroup, Version: "v1", Resource: "revisions"}
+)
+
+const (
+	// knativePrimaryTag is the tag of the traffic target of the primary revision
+	knativePrimaryTag = "primary"
+	// knativeCanaryTag is the tag of the traffic target of the latest revision
+	knativeCanaryTag = "canary"
+)
+
+// KnativeServiceController is managing the operations for the Knative Serving Services,
+// instead of creating a primary copy the traffic is split with the route of the Service
+// between the primary revision and the latest ready revision
+type KnativeServiceController struct {
+	dynamicClient dynamic.Interface
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+}
+
+// Initialize pins the traffic of the Service to its latest ready revision,
+// the revisions created afterwards are routed with the canary weight
+func (c *KnativeServiceController) Initialize(cd *flaggerv1.Canary) error {
+	svc, err := c.get(cd)
+	if err != nil {
+		return err
+	}
+
+	if knativePrimaryRevision(svc) == "" {
+		revision, _, _ := unstructured.NestedString(svc.Object, "status", "latestReadyRevisionName")
+		if revision == "" {
+			return fmt.Errorf("service %s.%s has no ready revision", svc.GetName(), svc.GetNamespace())
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("Pinning the traffic of Knative Service %s.%s to revision %s", svc.GetName(), svc.GetNamespace(), revision)
+		if err := c.setTraffic(cd, revision, 0); err != nil {
+			return fmt.Errorf("setTraffic failed: %w", err)
+		}
+	}
+
+	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
+		if !cd.SkipAnalysis() {
+			if err := c.IsPrimaryReady(cd); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Promote pins the traffic of the Service to its latest ready revision
+func (c *KnativeServiceController) Promote(cd *flaggerv1.Canary) error {
+	svc, err := c.get(cd)
+	if err != nil {
+		return err
+	}
+
+	revision, _, _ := unstructured.NestedString(svc.Object, "status", "latestReadyRevisionName")
+	if revision == "" {
+		return fmt.Errorf("service %s.%s has no ready revision", svc.GetName(), svc.GetNamespace())
+	}
+	return c.setTraffic(cd, revision, 0)
+}
+
+// HasTargetChanged returns true if the Service revision template has changed
+func (c *KnativeServiceController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	svc, err := c.get(cd)
+	if err != nil {
+		return false, err
+	}
+	return hasSpecChanged(cd, knativeTemplate(svc))
+}
+
+// HaveDependenciesChanged returns false as the configs are not copied,
+// the revisions use the same config maps and secrets
+func (c *KnativeServiceController) HaveDependenciesChanged(_ *flaggerv1.Canary) (bool, error) {
+	return false, nil
+}
+
+// ScaleToZero is a no-op as Knative scales the revisions with their traffic
+func (c *KnativeServiceController) ScaleToZero(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// ScaleFromZero is a no-op as Knative scales the revisions with their traffic
+func (c *KnativeServiceController) ScaleFromZero(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// GetMetadata returns the label set by Knative on the pods of the Service revisions
+func (c *KnativeServiceController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
+	svc, err := c.get(cd)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return flaggerv1.KnativeServingGroup + "/service", svc.GetName(), nil, nil
+}
+
+// Finalize routes the traffic of the Service to its latest revision, or to the primary revision
+// with the RestorePrimary revert policy, with the Delete revert policy the Service is removed
+func (c *KnativeServiceController) Finalize(cd *flaggerv1.Canary) error {
+	client := c.dynamicClient.Resource(knativeServiceResource).Namespace(cd.Namespace)
+	targetName := cd.Spec.TargetRef.Name
+	if cd.GetRevertPolicy() == flaggerv1.RevertPolicyDelete {
+		err := client.Delete(context.TODO(), targetName, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("service %s.%s delete error: %w", targetName, cd.Namespace, err)
+		}
+		return nil
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		svc, err := c.get(cd)
+		if err != nil {
+			return err
+		}
+
+		target := map[string]interface{}{"latestRevision": true, "percent": int64(100)}
+		if revision := knativePrimaryRevision(svc); revision != "" && cd.GetRevertPolicy() == flaggerv1.RevertPolicyRestorePrimary {
+			target = map[string]interface{}{"revisionName": revision, "percent": int64(100)}
+		}
+		svcCopy := svc.DeepCopy()
+		if err := unstructured.SetNestedSlice(svcCopy.Object, []interface{}{target}, "spec", "traffic"); err != nil {
+			return fmt.Errorf("service %s.%s traffic is invalid: %w", targetName, cd.Namespace, err)
+		}
+		_, err = client.Update(context.TODO(), svcCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("restoring service %s.%s traffic failed: %w", targetName, cd.Namespace, err)
+	}
+	return nil
+}
+
+// setTraffic routes the canary weight to the latest ready revision and the rest to the primary revision,
+// the current primary revision is kept when none is given
+func (c *KnativeServiceController) setTraffic(cd *flaggerv1.Canary, primaryRevision string, weight int) error {
+	client := c.dynamicClient.Resource(knativeServiceResource).Namespace(cd.Namespace)
+	targetName := cd.Spec.TargetRef.Name
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		svc, err := c.get(cd)
+		if err != nil {
+			return err
+		}
+
+		revision := primaryRevision
+		if revision == "" {
+			revision = knativePrimaryRevision(svc)
+		}
+		if revision == "" {
+			return fmt.Errorf("service %s.%s has no primary revision", targetName, cd.Namespace)
+		}
+
+		traffic := []interface{}{
+			map[string]interface{}{"tag": knativePrimaryTag, "revisionName": revision, "percent": int64(100 - weight)},
+			map[string]interface{}{"tag": knativeCanaryTag, "latestRevision": true, "percent": int64(weight)},
+		}
+		current, _, _ := unstructured.NestedSlice(svc.Object, "spec", "traffic")
+		if reflect.DeepEqual(current, traffic) {
+			return nil
+		}
+
+		svcCopy := svc.DeepCopy()
+		if err := unstructured.SetNestedSlice(svcCopy.Object, traffic, "spec", "traffic"); err != nil {
+			return fmt.Errorf("service %s.%s traffic is invalid: %w", targetName, cd.Namespace, err)
+		}
+		_, err = client.Update(context.TODO(), svcCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("updating service %s.%s traffic to %d%% failed: %w", targetName, cd.Namespace, weight, err)
+	}
+	return nil
+}
+
+func (c *KnativeServiceController) get(cd *flaggerv1.Canary) (*unstructured.Unstructured, error) {
+	targetName := cd.Spec.TargetRef.Name
+	svc, err := c.dynamicClient.Resource(knativeServiceResource).Namespace(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("service %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+	return svc, nil
+}
+
+// knativeTemplate returns the revision template of the Service
+func knativeTemplate(svc *unstructured.Unstructured) map[string]interface{} {
+	template, _, _ := unstructured.NestedMap(svc.Object, "spec", "template")
+	return template
+}
+
+// knativePrimaryRevision returns the revision of the primary traffic target, empty when not pinned
+func knativePrimaryRevision(svc *unstructured.Unstructured) string {
+	traffic, _, _ := unstructured.NestedSlice(svc.Object, "spec", "traffic")
+	for _, t := range traffic {
+		target, ok := t.(map[string]interface{})
+		if !ok || target["tag"] != knativePrimaryTag {
+			continue
+		}
+		if revision, ok := target["revisionName"].(string); ok {
+			return revision
+		}
+	}
+	return ""
+}
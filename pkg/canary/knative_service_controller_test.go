@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+func newKnativeServiceFixture(t *testing.T) (*KnativeServiceController, *flaggerv1.Canary) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podinfo"},
+		Spec: flaggerv1.CanarySpec{
+			Provider: flaggerv1.KnativeProvider,
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "podinfo",
+				APIVersion: "serving.knative.dev/v1",
+				Kind:       "Service",
+			},
+			Analysis: &flaggerv1.CanaryAnalysis{StepWeight: 20, MaxWeight: 60},
+		},
+	}
+	require.Equal(t, flaggerv1.KnativeServiceKind, cd.GetTargetKind())
+
+	svc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "serving.knative.dev/v1",
+		"kind":       "Service",
+		"metadata": map[string]interface{}{
+			"name":       "podinfo",
+			"namespace":  "default",
+			"generation": int64(1),
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"image": "quay.io/stefanprodan/podinfo:1.2.0"},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"observedGeneration":        int64(1),
+			"latestCreatedRevisionName": "podinfo-00001",
+			"latestReadyRevisionName":   "podinfo-00001",
+		},
+	}}
+
+	logger, _ := logger.NewLogger("debug")
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), svc,
+		newKnativeTestRevision("podinfo-00001", "True"), newKnativeTestRevision("podinfo-00002", "True"))
+	return &KnativeServiceController{
+		dynamicClient: dynamicClient,
+		flaggerClient: fakeFlagger.NewSimpleClientset(cd),
+		logger:        logger,
+	}, cd
+}
+
+func newKnativeTestRevision(name string, ready string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "serving.knative.dev/v1",
+		"kind":       "Revision",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": ready},
+			},
+		},
+	}}
+}
+
+func knativeTestTraffic(t *testing.T, ctrl *KnativeServiceController) []interface{} {
+	svc, err := ctrl.dynamicClient.Resource(knativeServiceResource).Namespace("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	traffic, _, err := unstructured.NestedSlice(svc.Object, "spec", "traffic")
+	require.NoError(t, err)
+	return traffic
+}
+
+func TestKnativeServiceController_Traffic(t *testing.T) {
+	ctrl, cd := newKnativeServiceFixture(t)
+
+	// the traffic is pinned to the ready revision on initialization
+	require.NoError(t, ctrl.Initialize(cd))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"tag": "primary", "revisionName": "podinfo-00001", "percent": int64(100)},
+		map[string]interface{}{"tag": "canary", "latestRevision": true, "percent": int64(0)},
+	}, knativeTestTraffic(t, ctrl))
+
+	label, labelValue, _, err := ctrl.GetMetadata(cd)
+	require.NoError(t, err)
+	assert.Equal(t, "serving.knative.dev/service", label)
+	assert.Equal(t, "podinfo", labelValue)
+
+	// the weight is routed to the latest revision
+	cd.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	require.NoError(t, ctrl.SetStatusWeight(cd, 20))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"tag": "primary", "revisionName": "podinfo-00001", "percent": int64(80)},
+		map[string]interface{}{"tag": "canary", "latestRevision": true, "percent": int64(20)},
+	}, knativeTestTraffic(t, ctrl))
+
+	// the traffic is routed back to the primary revision on rollback
+	require.NoError(t, ctrl.SetStatusPhase(cd, flaggerv1.CanaryPhaseFailed))
+	assert.Equal(t, int64(100), knativeTestTraffic(t, ctrl)[0].(map[string]interface{})["percent"])
+
+	// the latest ready revision becomes the primary on promotion
+	svc, err := ctrl.get(cd)
+	require.NoError(t, err)
+	require.NoError(t, unstructured.SetNestedField(svc.Object, "podinfo-00002", "status", "latestReadyRevisionName"))
+	_, err = ctrl.dynamicClient.Resource(knativeServiceResource).Namespace("default").Update(context.TODO(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.Promote(cd))
+	assert.Equal(t, "podinfo-00002", knativeTestTraffic(t, ctrl)[0].(map[string]interface{})["revisionName"])
+
+	// the route is given back to Knative on finalization
+	require.NoError(t, ctrl.Finalize(cd))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"latestRevision": true, "percent": int64(100)},
+	}, knativeTestTraffic(t, ctrl))
+}
+
+func TestKnativeServiceController_IsReady(t *testing.T) {
+	ctrl, cd := newKnativeServiceFixture(t)
+	cd.Status.LastTransitionTime = metav1.Now()
+	require.NoError(t, ctrl.Initialize(cd))
+	require.NoError(t, ctrl.IsPrimaryReady(cd))
+
+	_, err := ctrl.IsCanaryReady(cd)
+	require.NoError(t, err)
+
+	// the latest created revision isn't ready yet
+	svc, err := ctrl.get(cd)
+	require.NoError(t, err)
+	require.NoError(t, unstructured.SetNestedField(svc.Object, "podinfo-00002", "status", "latestCreatedRevisionName"))
+	_, err = ctrl.dynamicClient.Resource(knativeServiceResource).Namespace("default").Update(context.TODO(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	retryable, err := ctrl.IsCanaryReady(cd)
+	require.Error(t, err)
+	assert.True(t, retryable)
+	assert.Contains(t, err.Error(), "podinfo-00002")
+
+	// deadline exceeded
+	cd.Spec.ProgressDeadlineSeconds = int32p(-1e6)
+	retryable, err = ctrl.IsCanaryReady(cd)
+	require.Error(t, err)
+	assert.False(t, retryable)
+}
+
+func TestKnativeServiceController_HasTargetChanged(t *testing.T) {
+	ctrl, cd := newKnativeServiceFixture(t)
+	require.NoError(t, ctrl.Initialize(cd))
+	require.NoError(t, ctrl.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized}))
+
+	cd, err := ctrl.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	changed, err := ctrl.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	svc, err := ctrl.get(cd)
+	require.NoError(t, err)
+	require.NoError(t, unstructured.SetNestedSlice(svc.Object, []interface{}{
+		map[string]interface{}{"image": "quay.io/stefanprodan/podinfo:1.2.1"},
+	}, "spec", "template", "spec", "containers"))
+	_, err = ctrl.dynamicClient.Resource(knativeServiceResource).Namespace("default").Update(context.TODO(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	changed, err = ctrl.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// IsPrimaryReady checks the Ready condition of the primary revision
+func (c *KnativeServiceController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	svc, err := c.get(cd)
+	if err != nil {
+		return err
+	}
+
+	name := knativePrimaryRevision(svc)
+	if name == "" {
+		return fmt.Errorf("service %s.%s has no primary revision", svc.GetName(), svc.GetNamespace())
+	}
+	revision, err := c.dynamicClient.Resource(knativeRevisionResource).Namespace(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("revision %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+	if status, message := knativeCondition(revision, "Ready"); status != "True" {
+		return fmt.Errorf("primary revision %s.%s not ready: %s", name, cd.Namespace, message)
+	}
+	return nil
+}
+
+// IsCanaryReady checks the Service status and returns an error if
+// the latest created revision isn't ready
+func (c *KnativeServiceController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	svc, err := c.get(cd)
+	if err != nil {
+		return true, err
+	}
+
+	retryable, err := c.isServiceReady(cd, svc)
+	if err != nil {
+		return retryable, fmt.Errorf("canary service %s.%s not ready with retryable %v: %w",
+			svc.GetName(), cd.Namespace, retryable, err)
+	}
+	return true, nil
+}
+
+// isServiceReady determines if the latest created revision of the Service is ready
+func (c *KnativeServiceController) isServiceReady(cd *flaggerv1.Canary, svc *unstructured.Unstructured) (bool, error) {
+	observed, _, _ := unstructured.NestedInt64(svc.Object, "status", "observedGeneration")
+	created, _, _ := unstructured.NestedString(svc.Object, "status", "latestCreatedRevisionName")
+	ready, _, _ := unstructured.NestedString(svc.Object, "status", "latestReadyRevisionName")
+	if observed >= svc.GetGeneration() && created != "" && created == ready {
+		return true, nil
+	}
+
+	// check if deadline exceeded
+	from := cd.Status.LastTransitionTime
+	delta := time.Duration(cd.GetProgressDeadlineSeconds()) * time.Second
+	if from.Add(delta).Before(time.Now()) {
+		return false, fmt.Errorf("exceeded its progressDeadlineSeconds: %d", cd.GetProgressDeadlineSeconds())
+	}
+
+	if observed < svc.GetGeneration() {
+		return true, fmt.Errorf("waiting for rollout to finish: observed service generation less then desired generation")
+	}
+	_, message := knativeCondition(svc, "ConfigurationsReady")
+	return true, fmt.Errorf("waiting for revision %s to be ready: %s", created, message)
+}
+
+// knativeCondition returns the status and the message of a status condition
+func knativeCondition(obj *unstructured.Unstructured, conditionType string) (string, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		message, _ := condition["message"].(string)
+		return status, message
+	}
+	return "Unknown", fmt.Sprintf("condition %s not found", conditionType)
+}
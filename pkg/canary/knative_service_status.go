@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// SyncStatus encodes the Service revision template and updates the canary status,
+// the traffic of the Service is set to the canary weight of the status
+func (c *KnativeServiceController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	svc, err := c.get(cd)
+	if err != nil {
+		return err
+	}
+
+	if err := c.setTraffic(cd, "", status.CanaryWeight); err != nil {
+		return fmt.Errorf("setTraffic failed: %w", err)
+	}
+	return syncCanaryStatus(c.flaggerClient, cd, status, knativeTemplate(svc), func(cdCopy *flaggerv1.Canary) {})
+}
+
+// SetStatusFailedChecks updates the canary failed checks counter
+func (c *KnativeServiceController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusFailedChecks(c.flaggerClient, cd, val)
+}
+
+// SetStatusWeight routes the weight to the latest ready revision and updates the canary status weight value
+func (c *KnativeServiceController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
+	if err := c.setTraffic(cd, "", val); err != nil {
+		return fmt.Errorf("setTraffic failed: %w", err)
+	}
+	return setStatusWeight(c.flaggerClient, cd, val)
+}
+
+// SetStatusIterations updates the canary status iterations value
+func (c *KnativeServiceController) SetStatusIterations(cd *flaggerv1.Canary, val int) error {
+	return setStatusIterations(c.flaggerClient, cd, val)
+}
+
+// SetStatusPhase updates the canary status phase, the traffic is routed
+// to the primary revision when the analysis is over
+func (c *KnativeServiceController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	switch phase {
+	case flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseFailed, flaggerv1.CanaryPhaseSucceeded:
+		if err := c.setTraffic(cd, "", 0); err != nil {
+			return fmt.Errorf("setTraffic failed: %w", err)
+		}
+	}
+	return setStatusPhase(c.flaggerClient, cd, phase)
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package canary
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	kruisev1alpha1 "github.com/fluxcd/flagger/pkg/apis/kruise/v1alpha1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// kruiseHoldPartition keeps all the pods of the DaemonSet on the current revision
+const kruiseHoldPartition = math.MaxInt32
+
+// KruiseDaemonSetController is managing the operations for the OpenKruise Advanced DaemonSet kind,
+// instead of creating a primary copy the canary pods are rolled out with the partition of the rolling update
+type KruiseDaemonSetController struct {
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+	labels        []string
+	excludePaths  []string
+}
+
+// Initialize holds the rolling update of the DaemonSet so that a change of the pod template
+// isn't rolled out to the nodes before the analysis starts
+func (c *KruiseDaemonSetController) Initialize(cd *flaggerv1.Canary) error {
+	ds, err := c.get(cd)
+	if err != nil {
+		return err
+	}
+
+	switch cd.Status.Phase {
+	case "", flaggerv1.CanaryPhaseInitializing:
+		if !cd.SkipAnalysis() {
+			if err := c.IsPrimaryReady(cd); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+	case flaggerv1.CanaryPhaseFailed:
+		// the pods of the failed revision are kept until the target is reverted
+	case flaggerv1.CanaryPhaseSucceeded:
+		// hold the next revision once the promoted one is rolled out to all nodes
+		if kruisePartition(ds) != 0 || ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	if kruisePartition(ds) == kruiseHoldPartition {
+		return nil
+	}
+
+	// record the update strategy of the user before the first hold
+	if getOriginalTarget(cd).UpdateStrategy == "" {
+		err := setStatusOriginalTarget(c.flaggerClient, cd, func(state *flaggerv1.CanaryTargetState) {
+			if state.UpdateStrategy != "" {
+				return
+			}
+			state.UpdateStrategy = ds.Spec.UpdateStrategy.Type
+			if state.UpdateStrategy == "" {
+				state.UpdateStrategy = "RollingUpdate"
+			}
+			if ru := ds.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+				state.Partition = int32p(*ru.Partition)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("setStatusOriginalTarget failed: %w", err)
+		}
+	}
+
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Holding the rolling update of DaemonSet %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+	return c.setPartition(cd, kruiseHoldPartition)
+}
+
+// Promote releases the partition so that all the pods are rolled out to the canary revision
+func (c *KruiseDaemonSetController) Promote(cd *flaggerv1.Canary) error {
+	return c.setPartition(cd, 0)
+}
+
+// HasTargetChanged returns true if the DaemonSet pod spec has changed
+func (c *KruiseDaemonSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	ds, err := c.get(cd)
+	if err != nil {
+		return false, err
+	}
+	return hasSpecChanged(cd, excludePaths(ds.Spec.Template, c.excludePaths))
+}
+
+// HaveDependenciesChanged returns false as the configs are not copied,
+// the pods of both revisions use the same config maps and secrets
+func (c *KruiseDaemonSetController) HaveDependenciesChanged(_ *flaggerv1.Canary) (bool, error) {
+	return false, nil
+}
+
+// ScaleToZero keeps the partition of the last step,
+// the pods that weren't updated stay on the current revision
+func (c *KruiseDaemonSetController) ScaleToZero(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// ScaleFromZero keeps the partition held by Initialize,
+// the canary pods are rolled out with the traffic weight
+func (c *KruiseDaemonSetController) ScaleFromZero(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// GetMetadata returns the pod label selector and svc ports
+func (c *KruiseDaemonSetController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
+	ds, err := c.get(cd)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	label, labelValue, err := c.getSelectorLabel(ds)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	var ports map[string]int32
+	if cd.Spec.Service.PortDiscovery {
+		ports = getPorts(cd, ds.Spec.Template.Spec.Containers)
+	}
+	return label, labelValue, ports, nil
+}
+
+// Finalize restores the update strategy recorded before the first hold, or releases the partition
+// when none was recorded, with the Delete revert policy the DaemonSet is removed
+func (c *KruiseDaemonSetController) Finalize(cd *flaggerv1.Canary) error {
+	if cd.GetRevertPolicy() == flaggerv1.RevertPolicyDelete {
+		err := c.flaggerClient.KruiseV1alpha1().DaemonSets(cd.Namespace).Delete(context.TODO(), cd.Spec.TargetRef.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("daemonset %s.%s delete error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+		}
+		return nil
+	}
+
+	original := getOriginalTarget(cd)
+	if original.UpdateStrategy == "" {
+		return c.setPartition(cd, 0)
+	}
+
+	targetName := cd.Spec.TargetRef.Name
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		ds, err := c.get(cd)
+		if err != nil {
+			return err
+		}
+
+		dsCopy := ds.DeepCopy()
+		dsCopy.Spec.UpdateStrategy.Type = original.UpdateStrategy
+		if dsCopy.Spec.UpdateStrategy.RollingUpdate != nil {
+			dsCopy.Spec.UpdateStrategy.RollingUpdate.Partition = original.Partition
+		}
+		_, err = c.flaggerClient.KruiseV1alpha1().DaemonSets(cd.Namespace).Update(context.TODO(), dsCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("restoring daemonset %s.%s update strategy failed: %w", targetName, cd.Namespace, err)
+	}
+	return nil
+}
+
+// partitionForWeight returns the number of pods kept on the current revision
+// so that the canary pods match the traffic weight, at least one pod is updated
+func (c *KruiseDaemonSetController) partitionForWeight(cd *flaggerv1.Canary, ds *kruisev1alpha1.DaemonSet, weight int) int32 {
+	desired := int(ds.Status.DesiredNumberScheduled)
+	if weight <= 0 || desired == 0 {
+		return kruiseHoldPartition
+	}
+	updated := int(math.Ceil(float64(desired*weight) / float64(flaggerv1.TotalWeight)))
+	if updated > desired {
+		updated = desired
+	}
+	return int32(desired - updated)
+}
+
+// setPartition updates the partition of the rolling update
+func (c *KruiseDaemonSetController) setPartition(cd *flaggerv1.Canary, partition int32) error {
+	targetName := cd.Spec.TargetRef.Name
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		ds, err := c.get(cd)
+		if err != nil {
+			return err
+		}
+		if kruisePartition(ds) == partition {
+			return nil
+		}
+
+		dsCopy := ds.DeepCopy()
+		dsCopy.Spec.UpdateStrategy.Type = "RollingUpdate"
+		if dsCopy.Spec.UpdateStrategy.RollingUpdate == nil {
+			dsCopy.Spec.UpdateStrategy.RollingUpdate = &kruisev1alpha1.RollingUpdateDaemonSet{}
+		}
+		dsCopy.Spec.UpdateStrategy.RollingUpdate.Partition = int32p(partition)
+		_, err = c.flaggerClient.KruiseV1alpha1().DaemonSets(cd.Namespace).Update(context.TODO(), dsCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("updating daemonset %s.%s partition to %d failed: %w", targetName, cd.Namespace, partition, err)
+	}
+	return nil
+}
+
+// getSelectorLabel returns the selector match label
+func (c *KruiseDaemonSetController) getSelectorLabel(ds *kruisev1alpha1.DaemonSet) (string, string, error) {
+	if ds.Spec.Selector != nil {
+		for _, l := range c.labels {
+			if _, ok := ds.Spec.Selector.MatchLabels[l]; ok {
+				return l, ds.Spec.Selector.MatchLabels[l], nil
+			}
+		}
+	}
+
+	return "", "", fmt.Errorf(
+		"daemonset %s.%s spec.selector.matchLabels must contain one of %v",
+		ds.Name, ds.Namespace, c.labels,
+	)
+}
+
+func (c *KruiseDaemonSetController) get(cd *flaggerv1.Canary) (*kruisev1alpha1.DaemonSet, error) {
+	targetName := cd.Spec.TargetRef.Name
+	ds, err := c.flaggerClient.KruiseV1alpha1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+	return ds, nil
+}
+
+// kruisePartition returns the partition of the rolling update, zero when not set
+func kruisePartition(ds *kruisev1alpha1.DaemonSet) int32 {
+	if ru := ds.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+		return *ru.Partition
+	}
+	return 0
+}
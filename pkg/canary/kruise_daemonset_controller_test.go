@@ -0,0 +1,202 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	kruisev1alpha1 "github.com/fluxcd/flagger/pkg/apis/kruise/v1alpha1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+func newKruiseDaemonSetFixture() (*KruiseDaemonSetController, *flaggerv1.Canary) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "node-agent"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "node-agent",
+				APIVersion: "apps.kruise.io/v1alpha1",
+				Kind:       "DaemonSet",
+			},
+			Analysis: &flaggerv1.CanaryAnalysis{StepWeight: 20, MaxWeight: 60},
+		},
+	}
+	ds := &kruisev1alpha1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "node-agent"},
+		Spec: kruisev1alpha1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "node-agent"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "node-agent"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "agent", Image: "ghcr.io/example/agent:1.0.0"}},
+				},
+			},
+		},
+		Status: kruisev1alpha1.DaemonSetStatus{
+			DesiredNumberScheduled: 10,
+			UpdatedNumberScheduled: 10,
+			NumberAvailable:        10,
+		},
+	}
+
+	flaggerClient := fakeFlagger.NewSimpleClientset(cd, ds)
+	logger, _ := logger.NewLogger("debug")
+	return &KruiseDaemonSetController{
+		flaggerClient: flaggerClient,
+		logger:        logger,
+		labels:        []string{"app", "name"},
+	}, cd
+}
+
+func TestKruiseDaemonSetController_Partition(t *testing.T) {
+	ctrl, cd := newKruiseDaemonSetFixture()
+	assert.Equal(t, flaggerv1.KruiseDaemonSetKind, cd.GetTargetKind())
+	partition := func() int32 {
+		ds, err := ctrl.flaggerClient.KruiseV1alpha1().DaemonSets("default").Get(context.TODO(), "node-agent", metav1.GetOptions{})
+		require.NoError(t, err)
+		return kruisePartition(ds)
+	}
+
+	// the rolling update is held on initialization
+	require.NoError(t, ctrl.Initialize(cd))
+	assert.Equal(t, int32(kruiseHoldPartition), partition())
+
+	label, labelValue, _, err := ctrl.GetMetadata(cd)
+	require.NoError(t, err)
+	assert.Equal(t, "app", label)
+	assert.Equal(t, "node-agent", labelValue)
+
+	// the updated pods follow the canary weight
+	cd.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	require.NoError(t, ctrl.SetStatusWeight(cd, 20))
+	assert.Equal(t, int32(8), partition())
+	require.NoError(t, ctrl.SetStatusWeight(cd, 50))
+	assert.Equal(t, int32(5), partition())
+
+	// the partition is never raised
+	require.NoError(t, ctrl.SetStatusWeight(cd, 20))
+	assert.Equal(t, int32(5), partition())
+	cd.Status.Phase = flaggerv1.CanaryPhasePromoting
+	require.NoError(t, ctrl.SetStatusWeight(cd, 0))
+	assert.Equal(t, int32(5), partition())
+
+	// all pods are updated on promotion and the next revision is held afterwards
+	require.NoError(t, ctrl.Promote(cd))
+	assert.Equal(t, int32(0), partition())
+	cd.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	require.NoError(t, ctrl.Initialize(cd))
+	assert.Equal(t, int32(kruiseHoldPartition), partition())
+
+	require.NoError(t, ctrl.Finalize(cd))
+	assert.Equal(t, int32(0), partition())
+}
+
+func TestKruiseDaemonSetController_FinalizeUpdateStrategy(t *testing.T) {
+	ctrl, cd := newKruiseDaemonSetFixture()
+	ds, err := ctrl.flaggerClient.KruiseV1alpha1().DaemonSets("default").Get(context.TODO(), "node-agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	ds.Spec.UpdateStrategy.RollingUpdate = &kruisev1alpha1.RollingUpdateDaemonSet{Partition: int32p(3)}
+	_, err = ctrl.flaggerClient.KruiseV1alpha1().DaemonSets("default").Update(context.TODO(), ds, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, ctrl.Initialize(cd))
+	require.NoError(t, ctrl.Promote(cd))
+
+	// the canary is read again as after a controller restart
+	cd, err = ctrl.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "node-agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	require.NoError(t, ctrl.Initialize(cd))
+
+	cd, err = ctrl.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "node-agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	state := cd.Status.OriginalTargets["daemonset/node-agent"]
+	assert.Equal(t, "RollingUpdate", state.UpdateStrategy)
+	require.NotNil(t, state.Partition)
+	assert.Equal(t, int32(3), *state.Partition)
+
+	require.NoError(t, ctrl.Finalize(cd))
+	ds, err = ctrl.flaggerClient.KruiseV1alpha1().DaemonSets("default").Get(context.TODO(), "node-agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), kruisePartition(ds))
+}
+
+func TestKruiseDaemonSetController_HasTargetChanged(t *testing.T) {
+	ctrl, cd := newKruiseDaemonSetFixture()
+	require.NoError(t, ctrl.Initialize(cd))
+	require.NoError(t, ctrl.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized}))
+
+	cd, err := ctrl.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "node-agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	isNew, err := ctrl.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.False(t, isNew)
+
+	ds, err := ctrl.flaggerClient.KruiseV1alpha1().DaemonSets("default").Get(context.TODO(), "node-agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	ds.Spec.Template.Spec.Containers[0].Image = "ghcr.io/example/agent:1.1.0"
+	_, err = ctrl.flaggerClient.KruiseV1alpha1().DaemonSets("default").Update(context.TODO(), ds, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	isNew, err = ctrl.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+}
+
+func TestKruiseDaemonSetController_isDaemonSetReady(t *testing.T) {
+	ctrl, _ := newKruiseDaemonSetFixture()
+	cd := &flaggerv1.Canary{}
+	cd.Status.LastTransitionTime = metav1.Now()
+
+	// the pods above the partition are updated
+	ds := &kruisev1alpha1.DaemonSet{Status: kruisev1alpha1.DaemonSetStatus{
+		DesiredNumberScheduled: 10,
+		UpdatedNumberScheduled: 2,
+		NumberAvailable:        10,
+	}}
+	ds.Spec.UpdateStrategy.RollingUpdate = &kruisev1alpha1.RollingUpdateDaemonSet{Partition: int32p(8)}
+	retryable, err := ctrl.isDaemonSetReady(cd, ds, 100)
+	require.NoError(t, err)
+	require.True(t, retryable)
+
+	// a pod isn't available yet
+	ds.Status.NumberAvailable = 9
+	_, err = ctrl.isDaemonSetReady(cd, ds, 100)
+	require.Error(t, err)
+	_, err = ctrl.isDaemonSetReady(cd, ds, 90)
+	require.NoError(t, err)
+	ds.Status.NumberAvailable = 10
+
+	// the rollout of the step is in progress
+	ds.Spec.UpdateStrategy.RollingUpdate.Partition = int32p(5)
+	retryable, err = ctrl.isDaemonSetReady(cd, ds, 100)
+	require.Error(t, err)
+	require.True(t, retryable)
+
+	// deadline exceeded
+	cd.Spec.ProgressDeadlineSeconds = int32p(-1e6)
+	retryable, err = ctrl.isDaemonSetReady(cd, ds, 100)
+	require.Error(t, err)
+	require.False(t, retryable)
+}
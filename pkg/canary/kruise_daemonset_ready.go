@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package canary
+
+import (
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	kruisev1alpha1 "github.com/fluxcd/flagger/pkg/apis/kruise/v1alpha1"
+)
+
+// IsPrimaryReady checks the DaemonSet status and returns an error if
+// the pods kept on the current revision are unavailable
+func (c *KruiseDaemonSetController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	ds, err := c.get(cd)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.isDaemonSetReady(cd, ds, cd.GetAnalysisPrimaryReadyThreshold())
+	if err != nil {
+		return fmt.Errorf("daemonset %s.%s not ready: %w", ds.Name, cd.Namespace, err)
+	}
+	return nil
+}
+
+// IsCanaryReady checks the DaemonSet status and returns an error if
+// the pods above the partition aren't updated and available
+func (c *KruiseDaemonSetController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	ds, err := c.get(cd)
+	if err != nil {
+		return true, err
+	}
+
+	retryable, err := c.isDaemonSetReady(cd, ds, cd.GetAnalysisCanaryReadyThreshold())
+	if err != nil {
+		return retryable, fmt.Errorf("canary daemonset %s.%s not ready with retryable %v: %w",
+			ds.Name, cd.Namespace, retryable, err)
+	}
+	return true, nil
+}
+
+// isDaemonSetReady determines if the rollout of the partition is finished
+// by checking the number of updated pods and the percentage of available pods given by the ready threshold
+func (c *KruiseDaemonSetController) isDaemonSetReady(cd *flaggerv1.Canary, ds *kruisev1alpha1.DaemonSet, readyThreshold int) (bool, error) {
+	if ds.Generation <= ds.Status.ObservedGeneration {
+		desired := ds.Status.DesiredNumberScheduled
+		wantUpdated := desired - kruisePartition(ds)
+		if wantUpdated < 0 {
+			wantUpdated = 0
+		}
+
+		// calculate conditions
+		newCond := ds.Status.UpdatedNumberScheduled < wantUpdated
+		requiredAvailable := readyReplicas(desired, readyThreshold)
+		availableCond := ds.Status.NumberAvailable < requiredAvailable
+		if !newCond && !availableCond {
+			return true, nil
+		}
+
+		// check if deadline exceeded
+		from := cd.Status.LastTransitionTime
+		delta := time.Duration(cd.GetProgressDeadlineSeconds()) * time.Second
+		if from.Add(delta).Before(time.Now()) {
+			return false, fmt.Errorf("exceeded its progressDeadlineSeconds: %d", cd.GetProgressDeadlineSeconds())
+		}
+
+		// retryable
+		if newCond {
+			return true, fmt.Errorf("waiting for rollout to finish: %d out of %d new pods have been updated",
+				ds.Status.UpdatedNumberScheduled, wantUpdated)
+		}
+		return true, fmt.Errorf("waiting for rollout to finish: %d of %d pods are available, %d required",
+			ds.Status.NumberAvailable, desired, requiredAvailable)
+	}
+	return true, fmt.Errorf("waiting for rollout to finish: observed daemonset generation less then desired generation")
+}
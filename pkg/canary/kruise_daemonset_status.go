@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package canary
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// SyncStatus encodes the DaemonSet pod spec and updates the canary status
+func (c *KruiseDaemonSetController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	ds, err := c.get(cd)
+	if err != nil {
+		return err
+	}
+
+	return syncCanaryStatus(c.flaggerClient, cd, status, excludePaths(ds.Spec.Template, c.excludePaths), func(cdCopy *flaggerv1.Canary) {})
+}
+
+// SetStatusFailedChecks updates the canary failed checks counter
+func (c *KruiseDaemonSetController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusFailedChecks(c.flaggerClient, cd, val)
+}
+
+// SetStatusWeight updates the canary status weight value,
+// while progressing the partition is lowered so that the updated pods match the weight
+func (c *KruiseDaemonSetController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
+	if cd.Status.Phase == flaggerv1.CanaryPhaseProgressing {
+		ds, err := c.get(cd)
+		if err != nil {
+			return err
+		}
+
+		// the partition is never raised as the updated pods are not reverted by Kruise
+		if partition := c.partitionForWeight(cd, ds, val); partition < kruisePartition(ds) {
+			if err := c.setPartition(cd, partition); err != nil {
+				return fmt.Errorf("setPartition failed: %w", err)
+			}
+		}
+	}
+	return setStatusWeight(c.flaggerClient, cd, val)
+}
+
+// SetStatusIterations updates the canary status iterations value
+func (c *KruiseDaemonSetController) SetStatusIterations(cd *flaggerv1.Canary, val int) error {
+	return setStatusIterations(c.flaggerClient, cd, val)
+}
+
+// SetStatusPhase updates the canary status phase
+func (c *KruiseDaemonSetController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	return setStatusPhase(c.flaggerClient, cd, phase)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// ForCluster returns a factory of controllers that manage the workloads of another cluster,
+// the canary status is still read and written with the Flagger client
+func (factory *Factory) ForCluster(kubeClient kubernetes.Interface) *Factory {
+	factory.mu.RLock()
+	defer factory.mu.RUnlock()
+
+	configTracker := factory.configTracker
+	if ct, ok := configTracker.(*ConfigTracker); ok {
+		configTracker = &ConfigTracker{
+			KubeClient:    kubeClient,
+			FlaggerClient: ct.FlaggerClient,
+			Logger:        ct.Logger,
+		}
+	}
+	remote := NewFactory(kubeClient, factory.flaggerClient, configTracker, factory.labels, factory.includeLabelPrefix, factory.logger)
+	remote.excludePaths = factory.excludePaths
+	remote.primarySuffix = factory.primarySuffix
+	return remote
+}
+
+// RemoteController is the controller of the canary workloads in a remote cluster
+type RemoteController struct {
+	Cluster    string
+	Controller Controller
+}
+
+// MultiClusterController manages the canary workloads of the local and the remote clusters,
+// the workload operations are applied to all clusters while the status, the metadata and the
+// change detection come from the local cluster
+type MultiClusterController struct {
+	Controller
+	remotes []RemoteController
+}
+
+// NewMultiClusterController returns a controller that applies the workload operations
+// of the local controller to the remote clusters
+func NewMultiClusterController(local Controller, remotes []RemoteController) *MultiClusterController {
+	return &MultiClusterController{Controller: local, remotes: remotes}
+}
+
+// IsPrimaryReady returns an error if the primary workload isn't ready in any of the clusters
+func (c *MultiClusterController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	if err := c.Controller.IsPrimaryReady(cd); err != nil {
+		return err
+	}
+	for _, r := range c.remotes {
+		if err := r.Controller.IsPrimaryReady(cd); err != nil {
+			return fmt.Errorf("cluster %s: %w", r.Cluster, err)
+		}
+	}
+	return nil
+}
+
+// IsCanaryReady returns false if the canary workload isn't ready in any of the clusters,
+// the retriable flag is the one of the first cluster that isn't ready
+func (c *MultiClusterController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	if retriable, err := c.Controller.IsCanaryReady(cd); err != nil {
+		return retriable, err
+	}
+	for _, r := range c.remotes {
+		if retriable, err := r.Controller.IsCanaryReady(cd); err != nil {
+			return retriable, fmt.Errorf("cluster %s: %w", r.Cluster, err)
+		}
+	}
+	return true, nil
+}
+
+// Initialize creates the primary workloads in all clusters
+func (c *MultiClusterController) Initialize(cd *flaggerv1.Canary) error {
+	return c.each(cd, Controller.Initialize)
+}
+
+// Promote copies the canary spec to the primary workloads in all clusters
+func (c *MultiClusterController) Promote(cd *flaggerv1.Canary) error {
+	return c.each(cd, Controller.Promote)
+}
+
+// ScaleToZero scales the canary workloads to zero in all clusters
+func (c *MultiClusterController) ScaleToZero(cd *flaggerv1.Canary) error {
+	return c.each(cd, Controller.ScaleToZero)
+}
+
+// ScaleFromZero scales up the canary workloads in all clusters
+func (c *MultiClusterController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	return c.each(cd, Controller.ScaleFromZero)
+}
+
+// Finalize reverts the canary workloads in all clusters
+func (c *MultiClusterController) Finalize(cd *flaggerv1.Canary) error {
+	return c.each(cd, Controller.Finalize)
+}
+
+// each applies the operation to all clusters, so that a cluster that isn't ready
+// doesn't hold the others, and returns the first error
+func (c *MultiClusterController) each(cd *flaggerv1.Canary, fn func(Controller, *flaggerv1.Canary) error) error {
+	err := fn(c.Controller, cd)
+	for _, r := range c.remotes {
+		if rerr := fn(r.Controller, cd); rerr != nil && err == nil {
+			err = fmt.Errorf("cluster %s: %w", r.Cluster, rerr)
+		}
+	}
+	return err
+}
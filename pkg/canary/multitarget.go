@@ -0,0 +1,263 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"fmt"
+	"strings"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// CanaryController returns the controller of the canary target,
+// the workloads listed in the canary targetRefs are managed together with the target
+func (factory *Factory) CanaryController(cd *flaggerv1.Canary) Controller {
+	ctrl := factory.Controller(cd.GetTargetKind())
+	if len(cd.Spec.TargetRefs) == 0 {
+		return ctrl
+	}
+
+	targets := make([]TargetController, 0, len(cd.Spec.TargetRefs))
+	for _, ref := range cd.Spec.TargetRefs {
+		targets = append(targets, TargetController{
+			TargetRef:  ref,
+			Controller: factory.Controller(flaggerv1.TargetKind(ref)),
+		})
+	}
+	return NewMultiTargetController(factory.flaggerClient, ctrl, targets)
+}
+
+// TargetController is the controller of a workload listed in the canary targetRefs
+type TargetController struct {
+	TargetRef  flaggerv1.CrossNamespaceObjectReference
+	Controller Controller
+}
+
+// targetSpecer is implemented by the controllers of the workloads that can be listed in the canary targetRefs
+type targetSpecer interface {
+	targetSpec(cd *flaggerv1.Canary) (interface{}, error)
+}
+
+// promotionPreparer is implemented by the controllers that can compute the primary spec
+// before writing it, the returned functions apply the promotion and restore the previous primary
+type promotionPreparer interface {
+	preparePromotion(cd *flaggerv1.Canary) (func() error, func() error, error)
+}
+
+// promotion applies the primary spec of a workload and restores the previous one
+type promotion struct {
+	target   string
+	apply    func() error
+	rollback func() error
+}
+
+// MultiTargetController manages the canary target together with the workloads of the canary targetRefs,
+// the status and the metadata come from the target while the workload operations are applied to all of them
+type MultiTargetController struct {
+	Controller
+	flaggerClient clientset.Interface
+	targets       []TargetController
+}
+
+// NewMultiTargetController returns a controller that applies the workload operations
+// of the target controller to the workloads of the canary targetRefs
+func NewMultiTargetController(flaggerClient clientset.Interface, target Controller, targets []TargetController) *MultiTargetController {
+	return &MultiTargetController{Controller: target, flaggerClient: flaggerClient, targets: targets}
+}
+
+// IsPrimaryReady returns an error if any of the primary workloads isn't ready
+func (c *MultiTargetController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	if err := c.Controller.IsPrimaryReady(cd); err != nil {
+		return err
+	}
+	for _, t := range c.targets {
+		if err := t.Controller.IsPrimaryReady(forTarget(cd, t.TargetRef)); err != nil {
+			return fmt.Errorf("target %s: %w", targetKey(t.TargetRef), err)
+		}
+	}
+	return nil
+}
+
+// IsCanaryReady returns false if any of the canary workloads isn't ready,
+// the retriable flag is the one of the first workload that isn't ready
+func (c *MultiTargetController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	if retriable, err := c.Controller.IsCanaryReady(cd); err != nil {
+		return retriable, err
+	}
+	for _, t := range c.targets {
+		if retriable, err := t.Controller.IsCanaryReady(forTarget(cd, t.TargetRef)); err != nil {
+			return retriable, fmt.Errorf("target %s: %w", targetKey(t.TargetRef), err)
+		}
+	}
+	return true, nil
+}
+
+// SyncStatus updates the canary status with the target spec and the specs of the targetRefs
+func (c *MultiTargetController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	tracked := make(map[string]string, len(c.targets))
+	for _, t := range c.targets {
+		spec, err := c.targetSpec(cd, t)
+		if err != nil {
+			return err
+		}
+		tracked[targetKey(t.TargetRef)] = computeHash(spec)
+	}
+
+	if err := c.Controller.SyncStatus(cd, status); err != nil {
+		return err
+	}
+	return setStatusTrackedTargets(c.flaggerClient, cd, tracked)
+}
+
+// HasTargetChanged returns true if the target or any of the targetRefs has changed
+// since the last status sync
+func (c *MultiTargetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	changed, err := c.Controller.HasTargetChanged(cd)
+	if err != nil || changed {
+		return changed, err
+	}
+
+	for _, t := range c.targets {
+		spec, err := c.targetSpec(cd, t)
+		if err != nil {
+			return false, err
+		}
+		if cd.Status.TrackedTargets == nil || (*cd.Status.TrackedTargets)[targetKey(t.TargetRef)] != computeHash(spec) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Initialize creates the primary workloads of the target and of the targetRefs
+func (c *MultiTargetController) Initialize(cd *flaggerv1.Canary) error {
+	return c.each(cd, Controller.Initialize)
+}
+
+// Promote copies the canary specs to the primary workloads, the promotion is halted
+// without changing any of the primary workloads when one of the canary workloads isn't ready
+// or when one of the primary specs can't be computed, the primary workloads already promoted
+// are restored when the promotion of a workload fails
+func (c *MultiTargetController) Promote(cd *flaggerv1.Canary) error {
+	if _, err := c.IsCanaryReady(cd); err != nil {
+		return fmt.Errorf("promotion halted: %w", err)
+	}
+
+	// get every primary and compute its spec before the first write
+	promotions := make([]promotion, 0, len(c.targets)+1)
+	if preparer, ok := c.Controller.(promotionPreparer); ok {
+		apply, rollback, err := preparer.preparePromotion(cd)
+		if err != nil {
+			return fmt.Errorf("promotion halted: %w", err)
+		}
+		promotions = append(promotions, promotion{apply: apply, rollback: rollback})
+	} else {
+		promotions = append(promotions, promotion{apply: func() error { return c.Controller.Promote(cd) }})
+	}
+	for _, t := range c.targets {
+		preparer, ok := t.Controller.(promotionPreparer)
+		if !ok {
+			return fmt.Errorf("target %s: kind %s not supported in targetRefs", targetKey(t.TargetRef), t.TargetRef.Kind)
+		}
+		apply, rollback, err := preparer.preparePromotion(forTarget(cd, t.TargetRef))
+		if err != nil {
+			return fmt.Errorf("promotion halted: target %s: %w", targetKey(t.TargetRef), err)
+		}
+		promotions = append(promotions, promotion{target: targetKey(t.TargetRef), apply: apply, rollback: rollback})
+	}
+
+	for i, p := range promotions {
+		if err := p.apply(); err != nil {
+			if p.target != "" {
+				err = fmt.Errorf("target %s: %w", p.target, err)
+			}
+			// the failed workload may have been partially promoted
+			return rollbackPromotions(promotions[:i+1], err)
+		}
+	}
+	return nil
+}
+
+// rollbackPromotions restores the primary workloads in the reverse order of the promotion
+func rollbackPromotions(promotions []promotion, err error) error {
+	for i := len(promotions) - 1; i >= 0; i-- {
+		p := promotions[i]
+		if p.rollback == nil {
+			continue
+		}
+		if rerr := p.rollback(); rerr != nil {
+			if p.target != "" {
+				rerr = fmt.Errorf("target %s: %w", p.target, rerr)
+			}
+			err = fmt.Errorf("%w, rollback failed: %v", err, rerr)
+		}
+	}
+	return err
+}
+
+// ScaleToZero scales the canary workloads of the target and of the targetRefs to zero
+func (c *MultiTargetController) ScaleToZero(cd *flaggerv1.Canary) error {
+	return c.each(cd, Controller.ScaleToZero)
+}
+
+// ScaleFromZero scales up the canary workloads of the target and of the targetRefs
+func (c *MultiTargetController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	return c.each(cd, Controller.ScaleFromZero)
+}
+
+// Finalize reverts the canary workloads of the target and of the targetRefs
+func (c *MultiTargetController) Finalize(cd *flaggerv1.Canary) error {
+	return c.each(cd, Controller.Finalize)
+}
+
+// each applies the operation to the target and to the targetRefs, so that a workload
+// that isn't ready doesn't hold the others, and returns the first error
+func (c *MultiTargetController) each(cd *flaggerv1.Canary, fn func(Controller, *flaggerv1.Canary) error) error {
+	err := fn(c.Controller, cd)
+	for _, t := range c.targets {
+		if terr := fn(t.Controller, forTarget(cd, t.TargetRef)); terr != nil && err == nil {
+			err = fmt.Errorf("target %s: %w", targetKey(t.TargetRef), terr)
+		}
+	}
+	return err
+}
+
+func (c *MultiTargetController) targetSpec(cd *flaggerv1.Canary, t TargetController) (interface{}, error) {
+	specer, ok := t.Controller.(targetSpecer)
+	if !ok {
+		return nil, fmt.Errorf("target %s: kind %s not supported in targetRefs", targetKey(t.TargetRef), t.TargetRef.Kind)
+	}
+	return specer.targetSpec(forTarget(cd, t.TargetRef))
+}
+
+// forTarget returns a copy of the canary that targets a workload of the targetRefs,
+// the autoscaler and the status of the target don't apply to the workload
+func forTarget(cd *flaggerv1.Canary, ref flaggerv1.CrossNamespaceObjectReference) *flaggerv1.Canary {
+	cdCopy := cd.DeepCopy()
+	cdCopy.Spec.TargetRef = ref
+	cdCopy.Spec.TargetRefs = nil
+	cdCopy.Spec.AutoscalerRef = nil
+	cdCopy.Status.TrackedConfigs = nil
+	cdCopy.Status.LastAppliedSpec = ""
+	cdCopy.Status.LastPromotedSpec = ""
+	return cdCopy
+}
+
+func targetKey(ref flaggerv1.CrossNamespaceObjectReference) string {
+	return fmt.Sprintf("%s/%s", strings.ToLower(ref.Kind), ref.Name)
+}
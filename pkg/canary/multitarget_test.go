@@ -0,0 +1,216 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newMultiTargetFixture(t *testing.T) (deploymentControllerFixture, *MultiTargetController) {
+	mocks := newDeploymentFixture(deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"})
+	sidecar := newDeploymentControllerTest(deploymentConfigs{name: "podinfo-sidecar", label: "name", labelValue: "podinfo-sidecar"})
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Create(context.TODO(), sidecar, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	ref := flaggerv1.CrossNamespaceObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "podinfo-sidecar"}
+	mocks.canary.Spec.TargetRefs = []flaggerv1.CrossNamespaceObjectReference{ref}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), mocks.canary, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	ctrl := NewMultiTargetController(mocks.flaggerClient, &mocks.controller, []TargetController{
+		{TargetRef: ref, Controller: &mocks.controller},
+	})
+	return mocks, ctrl
+}
+
+func initializeMultiTarget(t *testing.T, mocks deploymentControllerFixture, ctrl *MultiTargetController) {
+	require.Error(t, ctrl.Initialize(mocks.canary)) // not ready yet
+
+	for _, name := range []string{"podinfo-primary", "podinfo-sidecar-primary"} {
+		p, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		p.Status = appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			ReadyReplicas:     1,
+			AvailableReplicas: 1,
+		}
+		_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), p, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, ctrl.Initialize(mocks.canary))
+}
+
+func TestMultiTargetController_Initialize(t *testing.T) {
+	mocks, ctrl := newMultiTargetFixture(t)
+	initializeMultiTarget(t, mocks, ctrl)
+
+	for _, name := range []string{"podinfo-primary", "podinfo-sidecar-primary"} {
+		_, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		assert.NoError(t, err, name)
+	}
+
+	// the autoscaler of the target isn't applied to the targetRefs
+	_, err := mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo-sidecar-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestMultiTargetController_HasTargetChanged(t *testing.T) {
+	mocks, ctrl := newMultiTargetFixture(t)
+	initializeMultiTarget(t, mocks, ctrl)
+	require.NoError(t, ctrl.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized}))
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, cd.Status.TrackedTargets)
+	assert.Contains(t, *cd.Status.TrackedTargets, "deployment/podinfo-sidecar")
+	assert.NotEmpty(t, cd.Status.LastAppliedSpec)
+
+	changed, err := ctrl.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	sidecar, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-sidecar", metav1.GetOptions{})
+	require.NoError(t, err)
+	sidecar.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), sidecar, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	changed, err = ctrl.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
+
+func TestMultiTargetController_Promote(t *testing.T) {
+	mocks, ctrl := newMultiTargetFixture(t)
+	initializeMultiTarget(t, mocks, ctrl)
+
+	for _, name := range []string{"podinfo", "podinfo-sidecar"} {
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		dep.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+		_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	// the promotion is halted while the sidecar rollout is in progress
+	sidecar, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-sidecar", metav1.GetOptions{})
+	require.NoError(t, err)
+	sidecar.Status.ObservedGeneration = sidecar.Generation
+	sidecar.Status.Replicas = 1
+	sidecar.Status.UpdatedReplicas = 0
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").UpdateStatus(context.TODO(), sidecar, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = ctrl.Promote(mocks.canary)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "target deployment/podinfo-sidecar")
+	for _, name := range []string{"podinfo-primary", "podinfo-sidecar-primary"} {
+		primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.0", primary.Spec.Template.Spec.Containers[0].Image, name)
+	}
+
+	sidecar.Status.UpdatedReplicas = 1
+	sidecar.Status.AvailableReplicas = 1
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").UpdateStatus(context.TODO(), sidecar, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, ctrl.Promote(mocks.canary))
+	for _, name := range []string{"podinfo-primary", "podinfo-sidecar-primary"} {
+		primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.1", primary.Spec.Template.Spec.Containers[0].Image, name)
+	}
+}
+
+func TestMultiTargetController_PromoteAllOrNothing(t *testing.T) {
+	mocks, ctrl := newMultiTargetFixture(t)
+	initializeMultiTarget(t, mocks, ctrl)
+
+	// the primary of the second targetRef doesn't exist
+	worker := newDeploymentControllerTest(deploymentConfigs{name: "podinfo-worker", label: "name", labelValue: "podinfo-worker"})
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Create(context.TODO(), worker, metav1.CreateOptions{})
+	require.NoError(t, err)
+	ref := flaggerv1.CrossNamespaceObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "podinfo-worker"}
+	mocks.canary.Spec.TargetRefs = append(mocks.canary.Spec.TargetRefs, ref)
+	ctrl.targets = append(ctrl.targets, TargetController{TargetRef: ref, Controller: &mocks.controller})
+
+	for _, name := range []string{"podinfo", "podinfo-sidecar", "podinfo-worker"} {
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		dep.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+		_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	err = ctrl.Promote(mocks.canary)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "target deployment/podinfo-worker: deployment podinfo-worker-primary.default get query error")
+	for _, name := range []string{"podinfo-primary", "podinfo-sidecar-primary"} {
+		primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.0", primary.Spec.Template.Spec.Containers[0].Image, name)
+	}
+}
+
+func TestMultiTargetController_PromoteRollback(t *testing.T) {
+	mocks, ctrl := newMultiTargetFixture(t)
+	initializeMultiTarget(t, mocks, ctrl)
+
+	for _, name := range []string{"podinfo", "podinfo-sidecar"} {
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		dep.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+		_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	// the promotion of the second primary fails after the first one is promoted
+	failed := false
+	mocks.kubeClient.(*fake.Clientset).PrependReactor("update", "deployments", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		dep := action.(k8sTesting.UpdateAction).GetObject().(*appsv1.Deployment)
+		if dep.Name == "podinfo-sidecar-primary" && !failed {
+			failed = true
+			return true, nil, errors.New("server error")
+		}
+		return false, nil, nil
+	})
+
+	err := ctrl.Promote(mocks.canary)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "target deployment/podinfo-sidecar")
+	assert.NotContains(t, err.Error(), "rollback failed")
+	for _, name := range []string{"podinfo-primary", "podinfo-sidecar-primary"} {
+		primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.0", primary.Spec.Template.Spec.Containers[0].Image, name)
+	}
+}
@@ -37,10 +37,10 @@ func (nt *NopTracker) HasConfigChanged(*flaggerv1.Canary) (bool, error) {
 	return false, nil
 }
 
-func (nt *NopTracker) CreatePrimaryConfigs(*flaggerv1.Canary, map[string]ConfigRef, []string) error {
+func (nt *NopTracker) CreatePrimaryConfigs(*flaggerv1.Canary, map[string]ConfigRef, []string, string) error {
 	return nil
 }
 
-func (nt *NopTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, _ map[string]ConfigRef) corev1.PodSpec {
+func (nt *NopTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, _ map[string]ConfigRef, _ string) corev1.PodSpec {
 	return spec
 }
@@ -0,0 +1,158 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// reconcilePrimaryPdbs creates or updates a copy of the PodDisruptionBudgets selecting the canary pods
+// by the selector label, the copies select the primary pods and are named after the source PDB with the primary suffix
+func (c *DeploymentController) reconcilePrimaryPdbs(cd *flaggerv1.Canary) error {
+	canaryDep, err := c.kubeClient.AppsV1().Deployments(cd.GetTargetNamespace()).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
+	}
+	label, labelValue, err := c.getSelectorLabel(canaryDep)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	pdbs, err := c.kubeClient.PolicyV1beta1().PodDisruptionBudgets(cd.GetTargetNamespace()).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("PodDisruptionBudgets %s list query error: %w", cd.GetTargetNamespace(), err)
+	}
+
+	existing := make(map[string]policyv1beta1.PodDisruptionBudget)
+	for _, pdb := range pdbs.Items {
+		existing[pdb.Name] = pdb
+	}
+
+	for _, pdb := range pdbs.Items {
+		if cd.IsOwnerOf(&pdb) || pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(canaryDep.Spec.Template.Labels)) {
+			continue
+		}
+		// a copy of a PDB that doesn't select by the selector label would cover the same pods
+		if !selectsByLabel(pdb.Spec.Selector, label, labelValue) {
+			continue
+		}
+
+		primarySpec := pdb.Spec.DeepCopy()
+		primarySpec.Selector = primarySelector(pdb.Spec.Selector, label, labelValue, cd.GetPrimarySuffix(c.primarySuffix))
+		primaryName := cd.GetPrimaryName(pdb.Name, c.primarySuffix)
+
+		primaryPdb, ok := existing[primaryName]
+		if !ok {
+			primaryPdb := &policyv1beta1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            primaryName,
+					Namespace:       cd.GetTargetNamespace(),
+					Labels:          pdb.Labels,
+					Annotations:     cd.GetOwnerAnnotations(pdb.Annotations),
+					OwnerReferences: cd.GetOwnerReferences(),
+				},
+				Spec: *primarySpec,
+			}
+			_, err := c.kubeClient.PolicyV1beta1().PodDisruptionBudgets(cd.GetTargetNamespace()).Create(context.TODO(), primaryPdb, metav1.CreateOptions{})
+			if err != nil && !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("creating PodDisruptionBudget %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+			}
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Infof("PodDisruptionBudget %s.%s created", primaryName, cd.GetTargetNamespace())
+			continue
+		}
+
+		if !cd.IsOwnerOf(&primaryPdb) {
+			return fmt.Errorf("PodDisruptionBudget %s.%s already exists and is not owned by the canary", primaryName, cd.GetTargetNamespace())
+		}
+		if cmp.Diff(*primarySpec, primaryPdb.Spec) != "" {
+			pdbClone := primaryPdb.DeepCopy()
+			pdbClone.Spec = *primarySpec
+			_, err := c.kubeClient.PolicyV1beta1().PodDisruptionBudgets(cd.GetTargetNamespace()).Update(context.TODO(), pdbClone, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("updating PodDisruptionBudget %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+			}
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Infof("PodDisruptionBudget %s.%s updated", primaryName, cd.GetTargetNamespace())
+		}
+	}
+	return nil
+}
+
+// deletePrimaryPdbs removes the PodDisruptionBudgets of the primary pods
+// so that they don't outlive the primary deployment
+func (c *DeploymentController) deletePrimaryPdbs(cd *flaggerv1.Canary) error {
+	pdbs, err := c.kubeClient.PolicyV1beta1().PodDisruptionBudgets(cd.GetTargetNamespace()).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("PodDisruptionBudgets %s list query error: %w", cd.GetTargetNamespace(), err)
+	}
+	for _, pdb := range pdbs.Items {
+		if !cd.IsOwnerOf(&pdb) || !strings.HasSuffix(pdb.Name, cd.GetPrimarySuffix(c.primarySuffix)) {
+			continue
+		}
+		err := c.kubeClient.PolicyV1beta1().PodDisruptionBudgets(cd.GetTargetNamespace()).Delete(context.TODO(), pdb.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("PodDisruptionBudget %s.%s delete error: %w", pdb.Name, cd.GetTargetNamespace(), err)
+		}
+	}
+	return nil
+}
+
+// selectsByLabel returns true if the selector requires the selector label to have the label value,
+// either with a match label or with an In expression
+func selectsByLabel(selector *metav1.LabelSelector, label string, labelValue string) bool {
+	if selector.MatchLabels[label] == labelValue {
+		return true
+	}
+	for _, expr := range selector.MatchExpressions {
+		if expr.Key == label && expr.Operator == metav1.LabelSelectorOpIn && contains(expr.Values, labelValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// primarySelector returns a copy of the selector with the primary values of the selector label
+func primarySelector(selector *metav1.LabelSelector, label string, labelValue string, suffix string) *metav1.LabelSelector {
+	res := selector.DeepCopy()
+	if res.MatchLabels[label] == labelValue {
+		res.MatchLabels[label] = labelValue + suffix
+	}
+	for i, expr := range res.MatchExpressions {
+		if expr.Key != label || expr.Operator != metav1.LabelSelectorOpIn {
+			continue
+		}
+		for j, value := range expr.Values {
+			res.MatchExpressions[i].Values[j] = value + suffix
+		}
+	}
+	return res
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestDeploymentController_PrimaryPdb(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+
+	minAvailable := intstr.FromInt(1)
+	for name, selector := range map[string]*metav1.LabelSelector{
+		"podinfo": {MatchLabels: map[string]string{"name": "podinfo"}},
+		"other":   {MatchLabels: map[string]string{"name": "other"}},
+		"exists": {MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "name", Operator: metav1.LabelSelectorOpExists},
+		}},
+	} {
+		_, err := mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Create(context.TODO(), &policyv1beta1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       policyv1beta1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable, Selector: selector},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	mocks.initializeCanary(t)
+
+	primaryPdb, err := mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", primaryPdb.Spec.Selector.MatchLabels["name"])
+	assert.Equal(t, 1, primaryPdb.Spec.MinAvailable.IntValue())
+	assert.Equal(t, "Canary", primaryPdb.OwnerReferences[0].Kind)
+
+	// the PDBs that don't select the canary pods are not copied
+	_, err = mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Get(context.TODO(), "other-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	// the PDBs that don't select by the label value are not copied since the copy would select the same pods
+	_, err = mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Get(context.TODO(), "exists-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	// the copy follows the changes of the source PDB
+	pdb, err := mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	maxUnavailable := intstr.FromString("50%")
+	pdb.Spec.MinAvailable = nil
+	pdb.Spec.MaxUnavailable = &maxUnavailable
+	_, err = mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Update(context.TODO(), pdb, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, mocks.controller.Initialize(mocks.canary))
+	primaryPdb, err = mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, primaryPdb.Spec.MinAvailable)
+	assert.Equal(t, "50%", primaryPdb.Spec.MaxUnavailable.String())
+
+	require.NoError(t, mocks.controller.Finalize(mocks.canary))
+	_, err = mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,518 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// ScaleSubresourceController is managing the operations for the custom resources that expose
+// the scale subresource, such as Argo Rollouts, the resources are read with the dynamic client
+// and must have a pod template under spec.template and a label selector under spec.selector
+type ScaleSubresourceController struct {
+	kubeClient    kubernetes.Interface
+	dynamicClient dynamic.Interface
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+	labels        []string
+	excludePaths  []string
+	hpaVersion    *hpaVersion
+	primarySuffix string
+}
+
+// Initialize creates the primary resource and the autoscaler, scales the target to zero
+// and waits for the primary to become ready
+func (c *ScaleSubresourceController) Initialize(cd *flaggerv1.Canary) error {
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	if err := c.createPrimary(cd); err != nil {
+		return fmt.Errorf("createPrimary failed: %w", err)
+	}
+
+	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
+		if !cd.SkipAnalysis() {
+			if err := c.IsPrimaryReady(cd); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("Scaling down %s %s.%s", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
+		if err := c.ScaleToZero(cd); err != nil {
+			return fmt.Errorf("scaling down canary %s %s.%s failed: %w",
+				cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace, err)
+		}
+	}
+
+	if cd.Spec.AutoscalerRef != nil {
+		if err := c.reconcileAutoscaler(cd, true); err != nil {
+			return fmt.Errorf("initial reconcileAutoscaler for %s.%s failed: %w", primaryName, cd.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// Promote copies the spec of the target to the primary resource,
+// the replicas of the primary are kept as they are owned by the scale subresource
+func (c *ScaleSubresourceController) Promote(cd *flaggerv1.Canary) error {
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	canary, err := c.get(cd, cd.Spec.TargetRef.Name)
+	if err != nil {
+		return err
+	}
+	primary, err := c.get(cd, primaryName)
+	if err != nil {
+		return err
+	}
+
+	spec, err := c.primarySpec(cd, canary)
+	if err != nil {
+		return err
+	}
+	if replicas, found, _ := unstructured.NestedFieldCopy(primary.Object, "spec", "replicas"); found {
+		spec["replicas"] = replicas
+	} else {
+		delete(spec, "replicas")
+	}
+
+	primaryCopy := primary.DeepCopy()
+	primaryCopy.Object["spec"] = spec
+	client, err := c.client(cd)
+	if err != nil {
+		return err
+	}
+	_, err = client.Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("updating %s %s.%s spec failed: %w", primaryCopy.GetKind(), primaryName, cd.Namespace, err)
+	}
+
+	if cd.Spec.AutoscalerRef != nil {
+		if err := c.reconcileAutoscaler(cd, false); err != nil {
+			return fmt.Errorf("reconcileAutoscaler for %s.%s failed: %w", primaryName, cd.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// HasTargetChanged returns true if the pod template of the target has changed
+func (c *ScaleSubresourceController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	canary, err := c.get(cd, cd.Spec.TargetRef.Name)
+	if err != nil {
+		return false, err
+	}
+	template, err := podTemplateOf(canary)
+	if err != nil {
+		return false, err
+	}
+	return hasSpecChanged(cd, excludePaths(template, c.excludePaths))
+}
+
+// HaveDependenciesChanged returns false as the configs of the custom resources are not tracked,
+// the primary pods use the same config maps and secrets as the target ones
+func (c *ScaleSubresourceController) HaveDependenciesChanged(_ *flaggerv1.Canary) (bool, error) {
+	return false, nil
+}
+
+// ScaleToZero sets the replicas of the target scale subresource to zero
+func (c *ScaleSubresourceController) ScaleToZero(cd *flaggerv1.Canary) error {
+	if err := c.autoscalerCtrl().pauseScaledObject(cd, true); err != nil {
+		return fmt.Errorf("pauseScaledObject failed: %w", err)
+	}
+
+	// record the replicas so that they can be restored after a controller restart
+	scale, err := c.getScale(cd, cd.Spec.TargetRef.Name)
+	if err != nil {
+		return err
+	}
+	replicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	if err := recordOriginalReplicas(c.flaggerClient, cd, int32(replicas)); err != nil {
+		return fmt.Errorf("recordOriginalReplicas failed: %w", err)
+	}
+	return c.scale(cd, cd.Spec.TargetRef.Name, 0)
+}
+
+// ScaleFromZero restores the replicas of the target scale subresource if scaled to zero,
+// the target is scaled to one when no replicas were recorded
+func (c *ScaleSubresourceController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	if err := c.autoscalerCtrl().pauseScaledObject(cd, false); err != nil {
+		return fmt.Errorf("pauseScaledObject failed: %w", err)
+	}
+
+	scale, err := c.getScale(cd, cd.Spec.TargetRef.Name)
+	if err != nil {
+		return err
+	}
+	if replicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas"); replicas > 0 {
+		return nil
+	}
+	return c.scale(cd, cd.Spec.TargetRef.Name, scaleFromZeroReplicas(cd, 0))
+}
+
+// GetMetadata returns the pod label selector and svc ports
+func (c *ScaleSubresourceController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
+	canary, err := c.get(cd, cd.Spec.TargetRef.Name)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	label, labelValue, err := c.getSelectorLabel(canary)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	template, err := podTemplateOf(canary)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	var ports map[string]int32
+	if cd.Spec.Service.PortDiscovery {
+		ports = getPorts(cd, template.Spec.Containers)
+	}
+	return label, labelValue, ports, nil
+}
+
+// Finalize scales the target to the primary replicas and removes the pause of its autoscaler,
+// with the Delete revert policy the target is removed instead of restored
+func (c *ScaleSubresourceController) Finalize(cd *flaggerv1.Canary) error {
+	client, err := c.client(cd)
+	if err != nil {
+		return err
+	}
+
+	if cd.GetRevertPolicy() == flaggerv1.RevertPolicyDelete {
+		err := client.Delete(context.TODO(), cd.Spec.TargetRef.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("%s %s.%s delete error: %w", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace, err)
+		}
+		return nil
+	}
+
+	if err := c.autoscalerCtrl().pauseScaledObject(cd, false); err != nil {
+		return fmt.Errorf("pauseScaledObject failed: %w", err)
+	}
+
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	primaryScale, err := c.getScale(cd, primaryName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err := c.ScaleFromZero(cd); err != nil {
+				return fmt.Errorf("ScaleFromZero failed: %w", err)
+			}
+			return nil
+		}
+		return err
+	}
+
+	// run the last promoted revision under the original name
+	if cd.GetRevertPolicy() == flaggerv1.RevertPolicyRestorePrimary {
+		if err := c.restorePrimaryTemplate(cd, primaryName); err != nil {
+			return err
+		}
+	}
+
+	replicas, _, _ := unstructured.NestedInt64(primaryScale.Object, "spec", "replicas")
+	return c.scale(cd, cd.Spec.TargetRef.Name, int32(replicas))
+}
+
+// createPrimary creates the primary resource with the spec of the target
+// and the primary label value in the selector and the pod template
+func (c *ScaleSubresourceController) createPrimary(cd *flaggerv1.Canary) error {
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	client, err := c.client(cd)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("%s %s.%s get query error: %w", cd.Spec.TargetRef.Kind, primaryName, cd.Namespace, err)
+	}
+
+	canary, err := c.get(cd, cd.Spec.TargetRef.Name)
+	if err != nil {
+		return err
+	}
+	label, labelValue, err := c.getSelectorLabel(canary)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	spec, err := c.primarySpec(cd, canary)
+	if err != nil {
+		return err
+	}
+	if replicas, _, _ := unstructured.NestedInt64(spec, "replicas"); replicas < 1 {
+		spec["replicas"] = int64(1)
+	}
+
+	primary := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	primary.SetAPIVersion(canary.GetAPIVersion())
+	primary.SetKind(canary.GetKind())
+	primary.SetName(primaryName)
+	primary.SetNamespace(cd.Namespace)
+	primary.SetLabels(makePrimaryLabels(canary.GetLabels(), cd.GetPrimaryName(labelValue, c.primarySuffix), label))
+	primary.SetAnnotations(canary.GetAnnotations())
+	primary.SetOwnerReferences([]metav1.OwnerReference{
+		*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+			Group:   flaggerv1.SchemeGroupVersion.Group,
+			Version: flaggerv1.SchemeGroupVersion.Version,
+			Kind:    flaggerv1.CanaryKind,
+		}),
+	})
+
+	_, err = client.Create(context.TODO(), primary, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating %s %s.%s failed: %w", primary.GetKind(), primaryName, cd.Namespace, err)
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("%s %s.%s created", primary.GetKind(), primaryName, cd.Namespace)
+	return nil
+}
+
+// primarySpec returns a copy of the target spec with the primary label value
+// in the selector and in the pod template labels
+func (c *ScaleSubresourceController) primarySpec(cd *flaggerv1.Canary, canary *unstructured.Unstructured) (map[string]interface{}, error) {
+	label, labelValue, err := c.getSelectorLabel(canary)
+	if err != nil {
+		return nil, fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+	primaryLabelValue := cd.GetPrimaryName(labelValue, c.primarySuffix)
+
+	template, err := podTemplateOf(canary)
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := makeAnnotations(template.Annotations)
+	if err != nil {
+		return nil, fmt.Errorf("makeAnnotations failed: %w", err)
+	}
+	template.Annotations = annotations
+	template.Labels = makePrimaryLabels(template.Labels, primaryLabelValue, label)
+	template.Spec = makePrimaryPodSelectors(template.Spec, c.labels, labelValue, cd.GetPrimarySuffix(c.primarySuffix))
+	templateObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&template)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s.%s pod template conversion failed: %w",
+			canary.GetKind(), canary.GetName(), canary.GetNamespace(), err)
+	}
+
+	spec, _, err := unstructured.NestedMap(canary.Object, "spec")
+	if err != nil {
+		return nil, fmt.Errorf("%s %s.%s spec is invalid: %w", canary.GetKind(), canary.GetName(), canary.GetNamespace(), err)
+	}
+	spec["template"] = templateObj
+	if err := unstructured.SetNestedStringMap(spec, map[string]string{label: primaryLabelValue}, "selector", "matchLabels"); err != nil {
+		return nil, fmt.Errorf("%s %s.%s selector is invalid: %w", canary.GetKind(), canary.GetName(), canary.GetNamespace(), err)
+	}
+	unstructured.RemoveNestedField(spec, "selector", "matchExpressions")
+	return spec, nil
+}
+
+// restorePrimaryTemplate sets the pod template of the target to the one of the primary
+func (c *ScaleSubresourceController) restorePrimaryTemplate(cd *flaggerv1.Canary, primaryName string) error {
+	canary, err := c.get(cd, cd.Spec.TargetRef.Name)
+	if err != nil {
+		return err
+	}
+	primary, err := c.get(cd, primaryName)
+	if err != nil {
+		return err
+	}
+	template, err := podTemplateOf(canary)
+	if err != nil {
+		return err
+	}
+	primaryTemplate, err := podTemplateOf(primary)
+	if err != nil {
+		return err
+	}
+	label, labelValue, err := c.getSelectorLabel(canary)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+	// the configs aren't copied for the primary of the scale subresource targets
+	if !restorePrimaryTemplate(&template, primaryTemplate, nil, c.labels, label, labelValue, cd.GetPrimarySuffix(c.primarySuffix)) {
+		return nil
+	}
+
+	templateObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&template)
+	if err != nil {
+		return fmt.Errorf("%s %s.%s pod template conversion failed: %w", canary.GetKind(), canary.GetName(), cd.Namespace, err)
+	}
+	canaryCopy := canary.DeepCopy()
+	if err := unstructured.SetNestedMap(canaryCopy.Object, templateObj, "spec", "template"); err != nil {
+		return fmt.Errorf("%s %s.%s pod template is invalid: %w", canary.GetKind(), canary.GetName(), cd.Namespace, err)
+	}
+	client, err := c.client(cd)
+	if err != nil {
+		return err
+	}
+	if _, err := client.Update(context.TODO(), canaryCopy, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("%s %s.%s update error: %w", canary.GetKind(), canary.GetName(), cd.Namespace, err)
+	}
+	return nil
+}
+
+// reconcileAutoscaler creates or updates the primary autoscaler the same way as for deployments,
+// the scale target of the HPA and of the ScaledObject is the scale subresource of the primary
+func (c *ScaleSubresourceController) reconcileAutoscaler(cd *flaggerv1.Canary, init bool) error {
+	switch cd.Spec.AutoscalerRef.Kind {
+	case "HorizontalPodAutoscaler":
+		return c.autoscalerCtrl().reconcilePrimaryHpa(cd, init)
+	case "ScaledObject":
+		return c.autoscalerCtrl().reconcilePrimaryScaledObject(cd, init)
+	default:
+		return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
+	}
+}
+
+// autoscalerCtrl returns a deployment controller sharing the clients, the autoscaler
+// references don't depend on the kind of the scale target
+func (c *ScaleSubresourceController) autoscalerCtrl() *DeploymentController {
+	return &DeploymentController{kubeClient: c.kubeClient, flaggerClient: c.flaggerClient, logger: c.logger, hpaVersion: c.hpaVersion}
+}
+
+// resource finds the resource name of the target kind with the discovery API
+// and returns an error if the resource doesn't expose the scale subresource
+func (c *ScaleSubresourceController) resource(cd *flaggerv1.Canary) (schema.GroupVersionResource, error) {
+	ref := cd.Spec.TargetRef
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("targetRef.apiVersion %s is invalid: %w", ref.APIVersion, err)
+	}
+
+	resources, err := c.kubeClient.Discovery().ServerResourcesForGroupVersion(ref.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("discovery of %s failed: %w", ref.APIVersion, err)
+	}
+
+	name := ""
+	for _, r := range resources.APIResources {
+		if r.Kind == ref.Kind && !strings.Contains(r.Name, "/") {
+			name = r.Name
+		}
+	}
+	if name == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("kind %s not found in %s", ref.Kind, ref.APIVersion)
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == name+"/scale" {
+			return gv.WithResource(name), nil
+		}
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("%s %s doesn't expose the scale subresource", ref.APIVersion, ref.Kind)
+}
+
+func (c *ScaleSubresourceController) client(cd *flaggerv1.Canary) (dynamic.ResourceInterface, error) {
+	if c.dynamicClient == nil {
+		return nil, fmt.Errorf("the dynamic client is required for %s targets", cd.Spec.TargetRef.Kind)
+	}
+	gvr, err := c.resource(cd)
+	if err != nil {
+		return nil, err
+	}
+	return c.dynamicClient.Resource(gvr).Namespace(cd.Namespace), nil
+}
+
+func (c *ScaleSubresourceController) get(cd *flaggerv1.Canary, name string) (*unstructured.Unstructured, error) {
+	client, err := c.client(cd)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s.%s get query error: %w", cd.Spec.TargetRef.Kind, name, cd.Namespace, err)
+	}
+	return obj, nil
+}
+
+func (c *ScaleSubresourceController) getScale(cd *flaggerv1.Canary, name string) (*unstructured.Unstructured, error) {
+	client, err := c.client(cd)
+	if err != nil {
+		return nil, err
+	}
+	scale, err := client.Get(context.TODO(), name, metav1.GetOptions{}, "scale")
+	if err != nil {
+		return nil, fmt.Errorf("%s %s.%s scale get query error: %w", cd.Spec.TargetRef.Kind, name, cd.Namespace, err)
+	}
+	return scale, nil
+}
+
+func (c *ScaleSubresourceController) scale(cd *flaggerv1.Canary, name string, replicas int32) error {
+	client, err := c.client(cd)
+	if err != nil {
+		return err
+	}
+	scale, err := c.getScale(cd, name)
+	if err != nil {
+		return err
+	}
+
+	scaleCopy := scale.DeepCopy()
+	if err := unstructured.SetNestedField(scaleCopy.Object, int64(replicas), "spec", "replicas"); err != nil {
+		return fmt.Errorf("%s %s.%s scale is invalid: %w", cd.Spec.TargetRef.Kind, name, cd.Namespace, err)
+	}
+	_, err = client.Update(context.TODO(), scaleCopy, metav1.UpdateOptions{}, "scale")
+	if err != nil {
+		return fmt.Errorf("scaling %s %s.%s to %v failed: %w", cd.Spec.TargetRef.Kind, name, cd.Namespace, replicas, err)
+	}
+	return nil
+}
+
+// getSelectorLabel returns the selector match label
+func (c *ScaleSubresourceController) getSelectorLabel(obj *unstructured.Unstructured) (string, string, error) {
+	matchLabels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector", "matchLabels")
+	for _, l := range c.labels {
+		if _, ok := matchLabels[l]; ok {
+			return l, matchLabels[l], nil
+		}
+	}
+
+	return "", "", fmt.Errorf(
+		"%s %s.%s spec.selector.matchLabels must contain one of %v",
+		obj.GetKind(), obj.GetName(), obj.GetNamespace(), c.labels,
+	)
+}
+
+// podTemplateOf converts the spec.template of a custom resource
+func podTemplateOf(obj *unstructured.Unstructured) (corev1.PodTemplateSpec, error) {
+	var template corev1.PodTemplateSpec
+	m, found, err := unstructured.NestedMap(obj.Object, "spec", "template")
+	if err != nil || !found {
+		return template, fmt.Errorf("%s %s.%s spec.template not found", obj.GetKind(), obj.GetName(), obj.GetNamespace())
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &template); err != nil {
+		return template, fmt.Errorf("%s %s.%s spec.template is invalid: %w", obj.GetKind(), obj.GetName(), obj.GetNamespace(), err)
+	}
+	return template, nil
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+var rolloutResource = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+
+type scaleSubresourceFixture struct {
+	canary     *flaggerv1.Canary
+	kubeClient *fake.Clientset
+	tracker    k8stesting.ObjectTracker
+	controller ScaleSubresourceController
+}
+
+func newScaleSubresourceFixture(t *testing.T) scaleSubresourceFixture {
+	canary := newDeploymentControllerTestCanary(canaryConfigs{targetName: "podinfo"})
+	canary.Spec.TargetRef.APIVersion = "argoproj.io/v1alpha1"
+	canary.Spec.TargetRef.Kind = "Rollout"
+	canary.Spec.AutoscalerRef = nil
+	flaggerClient := fakeFlagger.NewSimpleClientset(canary)
+
+	kubeClient := fake.NewSimpleClientset()
+	kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "argoproj.io/v1alpha1",
+		APIResources: []metav1.APIResource{
+			{Name: "rollouts", Kind: "Rollout", Namespaced: true},
+			{Name: "rollouts/scale", Kind: "Scale", Namespaced: true},
+		},
+	}}
+
+	scheme := runtime.NewScheme()
+	tracker := k8stesting.NewObjectTracker(scheme, serializer.NewCodecFactory(scheme).UniversalDecoder())
+	require.NoError(t, tracker.Add(newScaleSubresourceTestRollout()))
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(scheme)
+	dynamicClient.PrependReactor("*", "*", k8stesting.ObjectReaction(tracker))
+	dynamicClient.PrependReactor("get", "rollouts", scaleGetReactor(tracker))
+	dynamicClient.PrependReactor("update", "rollouts", scaleUpdateReactor(tracker))
+
+	logger, _ := logger.NewLogger("debug")
+	return scaleSubresourceFixture{
+		canary:     canary,
+		kubeClient: kubeClient,
+		tracker:    tracker,
+		controller: ScaleSubresourceController{
+			kubeClient:    kubeClient,
+			dynamicClient: dynamicClient,
+			flaggerClient: flaggerClient,
+			logger:        logger,
+			labels:        []string{"app", "name"},
+		},
+	}
+}
+
+// scaleGetReactor serves the scale subresource from the replicas and the selector of the rollouts
+func scaleGetReactor(tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		obj, err := tracker.Get(rolloutResource, action.GetNamespace(), action.(k8stesting.GetAction).GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		rollout := obj.(*unstructured.Unstructured)
+		replicas, _, _ := unstructured.NestedInt64(rollout.Object, "spec", "replicas")
+		matchLabels, _, _ := unstructured.NestedStringMap(rollout.Object, "spec", "selector", "matchLabels")
+		return true, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "autoscaling/v1",
+			"kind":       "Scale",
+			"metadata":   map[string]interface{}{"name": rollout.GetName(), "namespace": rollout.GetNamespace()},
+			"spec":       map[string]interface{}{"replicas": replicas},
+			"status":     map[string]interface{}{"replicas": replicas, "selector": "name=" + matchLabels["name"]},
+		}}, nil
+	}
+}
+
+// scaleUpdateReactor sets the replicas of the rollouts from the scale subresource
+func scaleUpdateReactor(tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		scale := action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		obj, err := tracker.Get(rolloutResource, action.GetNamespace(), scale.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		rollout := obj.(*unstructured.Unstructured)
+		replicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+		if err := unstructured.SetNestedField(rollout.Object, replicas, "spec", "replicas"); err != nil {
+			return true, nil, err
+		}
+		return true, scale, tracker.Update(rolloutResource, rollout, action.GetNamespace())
+	}
+}
+
+func (f scaleSubresourceFixture) getRollout(t *testing.T, name string) *unstructured.Unstructured {
+	obj, err := f.tracker.Get(rolloutResource, "default", name)
+	require.NoError(t, err)
+	return obj.(*unstructured.Unstructured)
+}
+
+func (f scaleSubresourceFixture) addReadyPod(t *testing.T, labelValue string) {
+	_, err := f.kubeClient.CoreV1().Pods("default").Create(context.TODO(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: labelValue + "-0", Namespace: "default", Labels: map[string]string{"name": labelValue}},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+		}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+}
+
+func newScaleSubresourceTestRollout() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata": map[string]interface{}{
+			"name":      "podinfo",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"name": "podinfo"},
+			},
+			"strategy": map[string]interface{}{
+				"canary": map[string]interface{}{},
+			},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"name": "podinfo"},
+				},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "podinfo",
+							"image": "quay.io/stefanprodan/podinfo:1.2.0",
+							"ports": []interface{}{
+								map[string]interface{}{"name": "http", "containerPort": int64(9898)},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestScaleSubresourceController_Initialize(t *testing.T) {
+	mocks := newScaleSubresourceFixture(t)
+
+	err := mocks.controller.Initialize(mocks.canary)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "primary Rollout podinfo-primary.default not ready")
+
+	primary := mocks.getRollout(t, "podinfo-primary")
+	matchLabels, _, _ := unstructured.NestedStringMap(primary.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]string{"name": "podinfo-primary"}, matchLabels)
+	templateLabels, _, _ := unstructured.NestedStringMap(primary.Object, "spec", "template", "metadata", "labels")
+	assert.Equal(t, "podinfo-primary", templateLabels["name"])
+	_, found, _ := unstructured.NestedMap(primary.Object, "spec", "strategy", "canary")
+	assert.True(t, found)
+	assert.Equal(t, "Canary", primary.GetOwnerReferences()[0].Kind)
+
+	mocks.addReadyPod(t, "podinfo-primary")
+	require.NoError(t, mocks.controller.Initialize(mocks.canary))
+	replicas, _, _ := unstructured.NestedInt64(mocks.getRollout(t, "podinfo").Object, "spec", "replicas")
+	assert.Equal(t, int64(0), replicas)
+
+	label, labelValue, ports, err := mocks.controller.GetMetadata(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, "name", label)
+	assert.Equal(t, "podinfo", labelValue)
+	assert.Nil(t, ports)
+}
+
+func TestScaleSubresourceController_Promote(t *testing.T) {
+	mocks := newScaleSubresourceFixture(t)
+	mocks.addReadyPod(t, "podinfo-primary")
+	require.NoError(t, mocks.controller.Initialize(mocks.canary))
+
+	// the primary replicas are owned by its scale subresource
+	require.NoError(t, mocks.controller.scale(mocks.canary, "podinfo-primary", 3))
+
+	canary := mocks.getRollout(t, "podinfo")
+	containers, _, _ := unstructured.NestedSlice(canary.Object, "spec", "template", "spec", "containers")
+	containers[0].(map[string]interface{})["image"] = "quay.io/stefanprodan/podinfo:1.2.1"
+	require.NoError(t, unstructured.SetNestedSlice(canary.Object, containers, "spec", "template", "spec", "containers"))
+	require.NoError(t, mocks.tracker.Update(rolloutResource, canary, "default"))
+
+	isNew, err := mocks.controller.HasTargetChanged(mocks.canary)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+
+	require.NoError(t, mocks.controller.ScaleFromZero(mocks.canary))
+	replicas, _, _ := unstructured.NestedInt64(mocks.getRollout(t, "podinfo").Object, "spec", "replicas")
+	assert.Equal(t, int64(1), replicas)
+
+	require.NoError(t, mocks.controller.Promote(mocks.canary))
+	primary := mocks.getRollout(t, "podinfo-primary")
+	template, err := podTemplateOf(primary)
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.1", template.Spec.Containers[0].Image)
+	assert.Equal(t, "podinfo-primary", template.Labels["name"])
+	replicas, _, _ = unstructured.NestedInt64(primary.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+
+	// the target is scaled to the primary replicas when the canary is deleted
+	require.NoError(t, mocks.controller.Finalize(mocks.canary))
+	replicas, _, _ = unstructured.NestedInt64(mocks.getRollout(t, "podinfo").Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+}
+
+func TestScaleSubresourceController_NoScaleSubresource(t *testing.T) {
+	mocks := newScaleSubresourceFixture(t)
+	mocks.kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources[0].APIResources = []metav1.APIResource{
+		{Name: "rollouts", Kind: "Rollout", Namespaced: true},
+	}
+
+	err := mocks.controller.Initialize(mocks.canary)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't expose the scale subresource")
+}
+
+func TestCanary_GetTargetKind(t *testing.T) {
+	for apiVersion, kind := range map[string]string{
+		"apps/v1":                 "Deployment",
+		"core/v1":                 "Deployment",
+		"v1":                      "Deployment",
+		"argoproj.io/v1alpha1":    flaggerv1.ScaleSubresourceKind,
+		"apps.kruise.io/v1alpha1": flaggerv1.ScaleSubresourceKind,
+	} {
+		cd := &flaggerv1.Canary{Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{APIVersion: apiVersion, Kind: "Deployment"},
+		}}
+		assert.Equal(t, kind, cd.GetTargetKind(), apiVersion)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// IsPrimaryReady checks the scale subresource of the primary and returns an error
+// if the pods selected by the scale status are not all ready or if the primary is scaled to zero
+func (c *ScaleSubresourceController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	replicas, _, err := c.isScaleReady(cd, primaryName)
+	if err != nil {
+		return fmt.Errorf("primary %s %s.%s not ready: %w", cd.Spec.TargetRef.Kind, primaryName, cd.Namespace, err)
+	}
+
+	if replicas == 0 {
+		return fmt.Errorf("halt %s.%s advancement: primary %s is scaled to zero",
+			cd.Name, cd.Namespace, cd.Spec.TargetRef.Kind)
+	}
+	return nil
+}
+
+// IsCanaryReady checks the scale subresource of the target and returns an error
+// if the pods are not all ready, it will return a non retryable error if the rollout is stuck
+func (c *ScaleSubresourceController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	targetName := cd.Spec.TargetRef.Name
+	_, retryable, err := c.isScaleReady(cd, targetName)
+	if err != nil {
+		return retryable, fmt.Errorf("canary %s %s.%s not ready: %w", cd.Spec.TargetRef.Kind, targetName, cd.Namespace, err)
+	}
+	return true, nil
+}
+
+// isScaleReady determines if a custom resource is ready by comparing the desired replicas of its scale subresource
+// with the ready pods matching the scale status selector, it returns the desired replicas
+func (c *ScaleSubresourceController) isScaleReady(cd *flaggerv1.Canary, name string) (int32, bool, error) {
+	obj, err := c.get(cd, name)
+	if err != nil {
+		return 0, true, err
+	}
+	observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if found && obj.GetGeneration() > observed {
+		return 0, true, fmt.Errorf("waiting for rollout to finish: observed %s generation less then desired generation",
+			cd.Spec.TargetRef.Kind)
+	}
+
+	scale, err := c.getScale(cd, name)
+	if err != nil {
+		return 0, true, err
+	}
+	desired, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	current, _, _ := unstructured.NestedInt64(scale.Object, "status", "replicas")
+	selector, _, _ := unstructured.NestedString(scale.Object, "status", "selector")
+	if desired > 0 && selector == "" {
+		return int32(desired), true, fmt.Errorf("waiting for rollout to finish: the scale status has no selector")
+	}
+
+	ready := int64(0)
+	if desired > 0 {
+		pods, err := c.kubeClient.CoreV1().Pods(cd.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return int32(desired), true, fmt.Errorf("pods %s.%s list query error: %w", selector, cd.Namespace, err)
+		}
+		for _, pod := range pods.Items {
+			if pod.DeletionTimestamp == nil && isPodReady(pod) {
+				ready++
+			}
+		}
+	}
+
+	// calculate conditions
+	readyCond := ready < desired
+	updatedCond := current > desired
+	if !readyCond && !updatedCond {
+		return int32(desired), true, nil
+	}
+
+	// check if deadline exceeded
+	from := cd.Status.LastTransitionTime
+	delta := time.Duration(cd.GetProgressDeadlineSeconds()) * time.Second
+	if from.Add(delta).Before(time.Now()) {
+		return int32(desired), false, fmt.Errorf("exceeded its progressDeadlineSeconds: %d", cd.GetProgressDeadlineSeconds())
+	}
+
+	// retryable
+	if readyCond {
+		return int32(desired), true, fmt.Errorf("waiting for rollout to finish: %d of %d pods are ready", ready, desired)
+	}
+	return int32(desired), true, fmt.Errorf("waiting for rollout to finish: %d old pods are pending termination", current-desired)
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+)
+
+// reconcilePrimaryScaledObject creates or updates the KEDA ScaledObject of the primary,
+// the spec and the triggers are copied from the canary ScaledObject with the primary as scale target
+func (c *DeploymentController) reconcilePrimaryScaledObject(cd *flaggerv1.Canary, init bool) error {
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	so, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.GetTargetNamespace()).Get(context.TODO(), cd.Spec.AutoscalerRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s get query error: %w",
+			cd.Spec.AutoscalerRef.Name, cd.GetTargetNamespace(), err)
+	}
+
+	soSpec := *so.Spec.DeepCopy()
+	soSpec.ScaleTargetRef = &kedav1alpha1.ScaleTarget{Name: primaryName}
+	if so.Spec.ScaleTargetRef != nil {
+		soSpec.ScaleTargetRef.Kind = so.Spec.ScaleTargetRef.Kind
+		soSpec.ScaleTargetRef.APIVersion = so.Spec.ScaleTargetRef.APIVersion
+		soSpec.ScaleTargetRef.EnvSourceContainerName = so.Spec.ScaleTargetRef.EnvSourceContainerName
+	}
+	// the HPA generated by KEDA for the primary must not collide with the canary one
+	if hpaConfig := soSpec.Advanced; hpaConfig != nil && hpaConfig.HorizontalPodAutoscalerConfig != nil &&
+		hpaConfig.HorizontalPodAutoscalerConfig.Name != "" {
+		hpaConfig.HorizontalPodAutoscalerConfig.Name = cd.GetPrimaryName(hpaConfig.HorizontalPodAutoscalerConfig.Name, c.primarySuffix)
+	}
+
+	primarySoName := cd.GetPrimaryName(cd.Spec.AutoscalerRef.Name, c.primarySuffix)
+	primarySo, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.GetTargetNamespace()).Get(context.TODO(), primarySoName, metav1.GetOptions{})
+
+	// create ScaledObject
+	if errors.IsNotFound(err) {
+		annotations := make(map[string]string)
+		for k, v := range so.Annotations {
+			if k != kedav1alpha1.PausedReplicasAnnotation {
+				annotations[k] = v
+			}
+		}
+		primarySo = &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            primarySoName,
+				Namespace:       cd.GetTargetNamespace(),
+				Labels:          so.Labels,
+				Annotations:     cd.GetOwnerAnnotations(annotations),
+				OwnerReferences: cd.GetOwnerReferences(),
+			},
+			Spec: soSpec,
+		}
+
+		_, err = c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.GetTargetNamespace()).Create(context.TODO(), primarySo, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating ScaledObject %s.%s failed: %w",
+				primarySo.Name, primarySo.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof(
+			"ScaledObject %s.%s created", primarySo.GetName(), cd.GetTargetNamespace())
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s get query failed: %w", primarySoName, cd.GetTargetNamespace(), err)
+	}
+
+	// update ScaledObject
+	if !init && cmp.Diff(soSpec, primarySo.Spec) != "" {
+		soClone := primarySo.DeepCopy()
+		soClone.Spec = soSpec
+		_, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.GetTargetNamespace()).Update(context.TODO(), soClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("updating ScaledObject %s.%s failed: %w", soClone.Name, soClone.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("ScaledObject %s.%s updated", primarySo.GetName(), cd.GetTargetNamespace())
+	}
+	return nil
+}
+
+// pauseScaledObject pauses the autoscaling of the canary at zero replicas,
+// otherwise KEDA would scale up the canary as soon as its triggers are active
+func (c *DeploymentController) pauseScaledObject(cd *flaggerv1.Canary, paused bool) error {
+	if cd.Spec.AutoscalerRef == nil || cd.Spec.AutoscalerRef.Kind != "ScaledObject" {
+		return nil
+	}
+
+	name := cd.Spec.AutoscalerRef.Name
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		so, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.GetTargetNamespace()).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if _, ok := so.Annotations[kedav1alpha1.PausedReplicasAnnotation]; ok == paused {
+			return nil
+		}
+
+		soClone := so.DeepCopy()
+		if paused {
+			if soClone.Annotations == nil {
+				soClone.Annotations = make(map[string]string)
+			}
+			soClone.Annotations[kedav1alpha1.PausedReplicasAnnotation] = "0"
+		} else {
+			delete(soClone.Annotations, kedav1alpha1.PausedReplicasAnnotation)
+		}
+		_, err = c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.GetTargetNamespace()).Update(context.TODO(), soClone, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s pause to %v failed: %w", name, cd.GetTargetNamespace(), paused, err)
+	}
+	return nil
+}
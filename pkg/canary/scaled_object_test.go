@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+)
+
+func newScaledObjectFixture(t *testing.T) deploymentControllerFixture {
+	mocks := newDeploymentFixture(deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"})
+	mocks.canary.Spec.AutoscalerRef = &flaggerv1.CrossNamespaceObjectReference{
+		APIVersion: "keda.sh/v1alpha1",
+		Kind:       "ScaledObject",
+		Name:       "podinfo",
+	}
+
+	so := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef:  &kedav1alpha1.ScaleTarget{Name: "podinfo"},
+			MinReplicaCount: int32p(1),
+			MaxReplicaCount: int32p(4),
+			Advanced: &kedav1alpha1.AdvancedConfig{
+				HorizontalPodAutoscalerConfig: &kedav1alpha1.HorizontalPodAutoscalerConfig{Name: "podinfo-hpa"},
+			},
+			Triggers: []kedav1alpha1.ScaleTriggers{{
+				Type:     "prometheus",
+				Metadata: map[string]string{"query": "sum(rate(http_requests_total[1m]))", "threshold": "100"},
+			}},
+		},
+	}
+	_, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Create(context.TODO(), so, metav1.CreateOptions{})
+	require.NoError(t, err)
+	return mocks
+}
+
+func TestDeploymentController_ScaledObject(t *testing.T) {
+	mocks := newScaledObjectFixture(t)
+	mocks.initializeCanary(t)
+
+	primarySo, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", primarySo.Spec.ScaleTargetRef.Name)
+	assert.Equal(t, "podinfo-hpa-primary", primarySo.Spec.Advanced.HorizontalPodAutoscalerConfig.Name)
+	assert.Equal(t, int32(4), *primarySo.Spec.MaxReplicaCount)
+	assert.Len(t, primarySo.Spec.Triggers, 1)
+	assert.NotContains(t, primarySo.Annotations, kedav1alpha1.PausedReplicasAnnotation)
+
+	// the canary is paused while scaled to zero
+	so, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "0", so.Annotations[kedav1alpha1.PausedReplicasAnnotation])
+
+	require.NoError(t, mocks.controller.ScaleFromZero(mocks.canary))
+	so, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, so.Annotations, kedav1alpha1.PausedReplicasAnnotation)
+
+	// the primary follows the canary triggers on promotion
+	soClone := so.DeepCopy()
+	soClone.Spec.MaxReplicaCount = int32p(8)
+	_, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Update(context.TODO(), soClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, mocks.controller.Promote(mocks.canary))
+	primarySo, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(8), *primarySo.Spec.MaxReplicaCount)
+	assert.Equal(t, "podinfo-primary", primarySo.Spec.ScaleTargetRef.Name)
+}
+
+func TestDeploymentController_ScaledObjectFinalize(t *testing.T) {
+	mocks := newScaledObjectFixture(t)
+	mocks.initializeCanary(t)
+
+	require.NoError(t, mocks.controller.Finalize(mocks.canary))
+	so, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, so.Annotations, kedav1alpha1.PausedReplicasAnnotation)
+}
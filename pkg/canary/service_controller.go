@@ -36,6 +36,7 @@ type ServiceController struct {
 	kubeClient    kubernetes.Interface
 	flaggerClient clientset.Interface
 	logger        *zap.SugaredLogger
+	primarySuffix string
 }
 
 // SetStatusFailedChecks updates the canary failed checks counter
@@ -66,7 +67,7 @@ func (c *ServiceController) GetMetadata(_ *flaggerv1.Canary) (string, string, ma
 // Initialize creates or updates the primary and canary services to prepare for the canary release process targeted on the K8s service
 func (c *ServiceController) Initialize(cd *flaggerv1.Canary) (err error) {
 	targetName := cd.Spec.TargetRef.Name
-	primaryName := fmt.Sprintf("%s-primary", targetName)
+	primaryName := cd.GetPrimaryName(targetName, c.primarySuffix)
 	canaryName := fmt.Sprintf("%s-canary", targetName)
 
 	svc, err := c.kubeClient.CoreV1().Services(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
@@ -169,7 +170,7 @@ func buildService(canary *flaggerv1.Canary, name string, src *corev1.Service) *c
 // Promote copies target's spec from canary to primary
 func (c *ServiceController) Promote(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
-	primaryName := fmt.Sprintf("%s-primary", targetName)
+	primaryName := cd.GetPrimaryName(targetName, c.primarySuffix)
 
 	canary, err := c.kubeClient.CoreV1().Services(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
 	if err != nil {
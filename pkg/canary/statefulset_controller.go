@@ -0,0 +1,498 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// StatefulSetController is managing the operations for Kubernetes StatefulSet kind
+type StatefulSetController struct {
+	kubeClient         kubernetes.Interface
+	flaggerClient      clientset.Interface
+	logger             *zap.SugaredLogger
+	configTracker      Tracker
+	labels             []string
+	includeLabelPrefix []string
+	excludePaths       []string
+	hpaVersion         *hpaVersion
+	primarySuffix      string
+}
+
+// Initialize creates the primary statefulset, hpa,
+// scales to zero the canary statefulset and returns the pod selector label and container ports
+func (c *StatefulSetController) Initialize(cd *flaggerv1.Canary) error {
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	if err := c.createPrimaryStatefulSet(cd, c.includeLabelPrefix); err != nil {
+		return fmt.Errorf("createPrimaryStatefulSet failed: %w", err)
+	}
+
+	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
+		if !cd.SkipAnalysis() {
+			if err := c.IsPrimaryReady(cd); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("Scaling down StatefulSet %s.%s", cd.Spec.TargetRef.Name, cd.GetTargetNamespace())
+		if err := c.ScaleToZero(cd); err != nil {
+			return fmt.Errorf("scaling down canary statefulset %s.%s failed: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
+		}
+	}
+
+	if cd.Spec.AutoscalerRef != nil {
+		if cd.Spec.AutoscalerRef.Kind == "HorizontalPodAutoscaler" {
+			if err := c.reconcilePrimaryHpa(cd, true); err != nil {
+				return fmt.Errorf(
+					"initial reconcilePrimaryHpa for %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+			}
+		} else if cd.Spec.AutoscalerRef.Kind == "ScaledObject" {
+			if err := c.reconcilePrimaryScaledObject(cd, true); err != nil {
+				return fmt.Errorf(
+					"initial reconcilePrimaryScaledObject for %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+			}
+		} else {
+			return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
+		}
+	}
+	return nil
+}
+
+// Promote copies the pod spec, secrets and config maps from canary to primary,
+// the volume claim templates of the primary are immutable and are kept
+func (c *StatefulSetController) Promote(cd *flaggerv1.Canary) error {
+	promote, _, err := c.preparePromotion(cd)
+	if err != nil {
+		return err
+	}
+	return promote()
+}
+
+// preparePromotion gets the canary and primary statefulsets and computes the primary spec,
+// the returned functions write the primary configs, the primary statefulset and its autoscaler
+// and restore the previous primary spec
+func (c *StatefulSetController) preparePromotion(cd *flaggerv1.Canary) (func() error, func() error, error) {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := cd.GetPrimaryName(targetName, c.primarySuffix)
+
+	canary, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+
+	label, labelValue, err := c.getSelectorLabel(canary)
+	primaryLabelValue := cd.GetPrimaryName(labelValue, c.primarySuffix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	primary, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("statefulset %s.%s get query error: %w", primaryName, cd.GetTargetNamespace(), err)
+	}
+
+	// promote secrets and config maps
+	configRefs, err := c.configTracker.GetTargetConfigs(cd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetTargetConfigs failed: %w", err)
+	}
+
+	primaryCopy := primary.DeepCopy()
+	primaryCopy.Spec.RevisionHistoryLimit = canary.Spec.RevisionHistoryLimit
+	primaryCopy.Spec.UpdateStrategy = canary.Spec.UpdateStrategy
+
+	// update spec with primary secrets and config maps
+	primaryCopy.Spec.Template.Spec = makePrimaryPodSelectors(c.configTracker.ApplyPrimaryConfigs(canary.Spec.Template.Spec, configRefs, cd.GetPrimarySuffix(c.primarySuffix)), c.labels, labelValue, cd.GetPrimarySuffix(c.primarySuffix))
+
+	// update pod annotations to ensure a rolling update
+	annotations, err := makeAnnotations(canary.Spec.Template.Annotations)
+	if err != nil {
+		return nil, nil, fmt.Errorf("makeAnnotations failed: %w", err)
+	}
+
+	primaryCopy.Spec.Template.Annotations = annotations
+	primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryLabelValue, label)
+
+	return func() error {
+			if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix, cd.GetPrimarySuffix(c.primarySuffix)); err != nil {
+				return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+			}
+
+			// apply update
+			_, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("updating statefulset %s.%s template spec failed: %w",
+					primaryCopy.GetName(), primaryCopy.Namespace, err)
+			}
+
+			if !volumeClaimTemplatesEqual(canary.Spec.VolumeClaimTemplates, primary.Spec.VolumeClaimTemplates) {
+				c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+					Warnf("StatefulSet %s.%s volume claim templates differ from %s, the primary keeps its claims",
+						primaryName, cd.GetTargetNamespace(), targetName)
+			}
+
+			// update HPA
+			if cd.Spec.AutoscalerRef != nil {
+				if cd.Spec.AutoscalerRef.Kind == "HorizontalPodAutoscaler" {
+					if err := c.reconcilePrimaryHpa(cd, false); err != nil {
+						return fmt.Errorf(
+							"reconcilePrimaryHpa for %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+					}
+				} else if cd.Spec.AutoscalerRef.Kind == "ScaledObject" {
+					if err := c.reconcilePrimaryScaledObject(cd, false); err != nil {
+						return fmt.Errorf(
+							"reconcilePrimaryScaledObject for %s.%s failed: %w", primaryName, cd.GetTargetNamespace(), err)
+					}
+				} else {
+					return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
+				}
+			}
+			return nil
+		}, func() error {
+			// restore the primary spec read before the promotion
+			err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+				current, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				current.Spec.RevisionHistoryLimit = primary.Spec.RevisionHistoryLimit
+				current.Spec.UpdateStrategy = primary.Spec.UpdateStrategy
+				current.Spec.Template = primary.Spec.Template
+				_, err = c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Update(context.TODO(), current, metav1.UpdateOptions{})
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("restoring statefulset %s.%s template spec failed: %w", primaryName, cd.GetTargetNamespace(), err)
+			}
+			return nil
+		}, nil
+}
+
+// HasTargetChanged returns true if the canary statefulset pod spec has changed
+func (c *StatefulSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	targetName := cd.Spec.TargetRef.Name
+	canary, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+
+	return hasSpecChanged(cd, excludePaths(canary.Spec.Template, c.excludePaths))
+}
+
+// ScaleToZero sets the canary statefulset replicas to zero
+func (c *StatefulSetController) ScaleToZero(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+
+	if err := c.autoscalerCtrl().pauseScaledObject(cd, true); err != nil {
+		return fmt.Errorf("pauseScaledObject failed: %w", err)
+	}
+
+	// record the replicas so that they can be restored after a controller restart
+	if err := recordOriginalReplicas(c.flaggerClient, cd, int32Default(sts.Spec.Replicas)); err != nil {
+		return fmt.Errorf("recordOriginalReplicas failed: %w", err)
+	}
+	return c.scale(cd, 0)
+}
+
+// ScaleFromZero restores the canary statefulset replicas if scaled to zero,
+// the statefulset is scaled to one when no replicas were recorded
+func (c *StatefulSetController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+
+	if err := c.autoscalerCtrl().pauseScaledObject(cd, false); err != nil {
+		return fmt.Errorf("pauseScaledObject failed: %w", err)
+	}
+
+	replicas := scaleFromZeroReplicas(cd, int32Default(sts.Spec.Replicas))
+	stsCopy := sts.DeepCopy()
+	stsCopy.Spec.Replicas = int32p(replicas)
+
+	_, err = c.kubeClient.AppsV1().StatefulSets(sts.Namespace).Update(context.TODO(), stsCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("scaling up %s.%s to %v failed: %w", stsCopy.GetName(), stsCopy.Namespace, replicas, err)
+	}
+	return nil
+}
+
+// GetMetadata returns the pod label selector and svc ports
+func (c *StatefulSetController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
+	targetName := cd.Spec.TargetRef.Name
+
+	canarySts, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", nil, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+
+	label, labelValue, err := c.getSelectorLabel(canarySts)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	var ports map[string]int32
+	if cd.Spec.Service.PortDiscovery {
+		ports = getPorts(cd, canarySts.Spec.Template.Spec.Containers)
+	}
+
+	return label, labelValue, ports, nil
+}
+
+// createPrimaryStatefulSet creates the primary statefulset with the volume claim templates of the canary,
+// the primary pods get their own persistent volume claims
+func (c *StatefulSetController) createPrimaryStatefulSet(cd *flaggerv1.Canary, includeLabelPrefix []string) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+
+	canarySts, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+
+	// Create the labels map but filter unwanted labels
+	labels := includeLabelsByPrefix(canarySts.Labels, includeLabelPrefix)
+
+	label, labelValue, err := c.getSelectorLabel(canarySts)
+	primaryLabelValue := cd.GetPrimaryName(labelValue, c.primarySuffix)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	_, err = c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		// create primary secrets and config maps
+		configRefs, err := c.configTracker.GetTargetConfigs(cd)
+		if err != nil {
+			return fmt.Errorf("GetTargetConfigs failed: %w", err)
+		}
+		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix, cd.GetPrimarySuffix(c.primarySuffix)); err != nil {
+			return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+		}
+		annotations, err := makeAnnotations(canarySts.Spec.Template.Annotations)
+		if err != nil {
+			return fmt.Errorf("makeAnnotations failed: %w", err)
+		}
+
+		replicas := int32(1)
+		if canarySts.Spec.Replicas != nil && *canarySts.Spec.Replicas > 0 {
+			replicas = *canarySts.Spec.Replicas
+		}
+
+		// the claims are bound to the primary pods by the statefulset name
+		claims := make([]corev1.PersistentVolumeClaim, 0, len(canarySts.Spec.VolumeClaimTemplates))
+		for _, claim := range canarySts.Spec.VolumeClaimTemplates {
+			claims = append(claims, corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        claim.Name,
+					Labels:      claim.Labels,
+					Annotations: claim.Annotations,
+				},
+				Spec: *claim.Spec.DeepCopy(),
+			})
+		}
+
+		// create primary statefulset
+		primarySts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            primaryName,
+				Namespace:       cd.GetTargetNamespace(),
+				Labels:          makePrimaryLabels(labels, primaryLabelValue, label),
+				Annotations:     cd.GetOwnerAnnotations(canarySts.Annotations),
+				OwnerReferences: cd.GetOwnerReferences(),
+			},
+			Spec: appsv1.StatefulSetSpec{
+				ServiceName:          canarySts.Spec.ServiceName,
+				PodManagementPolicy:  canarySts.Spec.PodManagementPolicy,
+				UpdateStrategy:       canarySts.Spec.UpdateStrategy,
+				RevisionHistoryLimit: canarySts.Spec.RevisionHistoryLimit,
+				Replicas:             int32p(replicas),
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						label: primaryLabelValue,
+					},
+				},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels:      makePrimaryLabels(canarySts.Spec.Template.Labels, primaryLabelValue, label),
+						Annotations: annotations,
+					},
+					// update spec with the primary secrets and config maps
+					Spec: makePrimaryPodSelectors(c.configTracker.ApplyPrimaryConfigs(canarySts.Spec.Template.Spec, configRefs, cd.GetPrimarySuffix(c.primarySuffix)), c.labels, labelValue, cd.GetPrimarySuffix(c.primarySuffix)),
+				},
+				VolumeClaimTemplates: claims,
+			},
+		}
+
+		_, err = c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Create(context.TODO(), primarySts, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating statefulset %s.%s failed: %w", primarySts.Name, cd.GetTargetNamespace(), err)
+		}
+
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("StatefulSet %s.%s created", primarySts.GetName(), cd.GetTargetNamespace())
+	} else if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", primaryName, cd.GetTargetNamespace(), err)
+	}
+
+	return nil
+}
+
+// reconcilePrimaryHpa creates or updates the primary HPA the same way as for deployments
+func (c *StatefulSetController) reconcilePrimaryHpa(cd *flaggerv1.Canary, init bool) error {
+	return c.autoscalerCtrl().reconcilePrimaryHpa(cd, init)
+}
+
+// reconcilePrimaryScaledObject creates or updates the primary ScaledObject the same way as for deployments
+func (c *StatefulSetController) reconcilePrimaryScaledObject(cd *flaggerv1.Canary, init bool) error {
+	return c.autoscalerCtrl().reconcilePrimaryScaledObject(cd, init)
+}
+
+// autoscalerCtrl returns a deployment controller sharing the clients, the autoscaler
+// references don't depend on the kind of the scale target
+func (c *StatefulSetController) autoscalerCtrl() *DeploymentController {
+	return &DeploymentController{kubeClient: c.kubeClient, flaggerClient: c.flaggerClient, logger: c.logger, hpaVersion: c.hpaVersion}
+}
+
+// getSelectorLabel returns the selector match label
+func (c *StatefulSetController) getSelectorLabel(statefulSet *appsv1.StatefulSet) (string, string, error) {
+	for _, l := range c.labels {
+		if _, ok := statefulSet.Spec.Selector.MatchLabels[l]; ok {
+			return l, statefulSet.Spec.Selector.MatchLabels[l], nil
+		}
+	}
+
+	return "", "", fmt.Errorf(
+		"statefulset %s.%s spec.selector.matchLabels must contain one of %v",
+		statefulSet.Name, statefulSet.Namespace, c.labels,
+	)
+}
+
+func (c *StatefulSetController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bool, error) {
+	return c.configTracker.HasConfigChanged(cd)
+}
+
+// Finalize sets the replica count from the primary to the reference statefulset,
+// the persistent volume claims of the primary pods are left in place
+func (c *StatefulSetController) Finalize(cd *flaggerv1.Canary) error {
+	// with the Delete policy the target is removed instead of restored
+	if cd.GetRevertPolicy() == flaggerv1.RevertPolicyDelete {
+		err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Delete(context.TODO(), cd.Spec.TargetRef.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("statefulset %s.%s delete error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
+		}
+		return nil
+	}
+
+	// give the autoscaling of the target back to KEDA
+	if err := c.autoscalerCtrl().pauseScaledObject(cd, false); err != nil {
+		return fmt.Errorf("pauseScaledObject failed: %w", err)
+	}
+
+	refSts, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
+	}
+
+	// get primary if possible, if not scale from zero
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	primarySts, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err := c.ScaleFromZero(cd); err != nil {
+				return fmt.Errorf("ScaleFromZero failed: %w", err)
+			}
+			return nil
+		}
+		return fmt.Errorf("statefulset %s.%s get query error: %w", primaryName, cd.GetTargetNamespace(), err)
+	}
+
+	// run the last promoted revision under the original name
+	if cd.GetRevertPolicy() == flaggerv1.RevertPolicyRestorePrimary {
+		label, labelValue, err := c.getSelectorLabel(refSts)
+		if err != nil {
+			return fmt.Errorf("getSelectorLabel failed: %w", err)
+		}
+		configs, err := generatedConfigs(cd, c.configTracker)
+		if err != nil {
+			return err
+		}
+		refCopy := refSts.DeepCopy()
+		if restorePrimaryTemplate(&refCopy.Spec.Template, primarySts.Spec.Template, configs, c.labels, label, labelValue, cd.GetPrimarySuffix(c.primarySuffix)) {
+			refSts, err = c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Update(context.TODO(), refCopy, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("statefulset %s.%s update error: %w", refCopy.Name, cd.GetTargetNamespace(), err)
+			}
+		}
+	}
+
+	// if both ref and primary present update the replicas of the ref to match the primary
+	if int32Default(refSts.Spec.Replicas) != int32Default(primarySts.Spec.Replicas) {
+		if err := c.scale(cd, int32Default(primarySts.Spec.Replicas)); err != nil {
+			return fmt.Errorf("scale failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// scale sets the canary statefulset replicas
+func (c *StatefulSetController) scale(cd *flaggerv1.Canary, replicas int32) error {
+	targetName := cd.Spec.TargetRef.Name
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+
+	stsCopy := sts.DeepCopy()
+	stsCopy.Spec.Replicas = int32p(replicas)
+	_, err = c.kubeClient.AppsV1().StatefulSets(sts.Namespace).Update(context.TODO(), stsCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("scaling %s.%s to %v failed: %w", stsCopy.GetName(), stsCopy.Namespace, replicas, err)
+	}
+	return nil
+}
+
+// volumeClaimTemplatesEqual returns true if the claim templates have the same names and specs
+func volumeClaimTemplatesEqual(a []corev1.PersistentVolumeClaim, b []corev1.PersistentVolumeClaim) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || computeHash(a[i].Spec) != computeHash(b[i].Spec) {
+			return false
+		}
+	}
+	return true
+}
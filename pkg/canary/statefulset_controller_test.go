@@ -0,0 +1,193 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestStatefulSetController_Init(t *testing.T) {
+	sc := statefulsetConfigs{name: "podinfo", label: "app", labelValue: "podinfo"}
+	mocks := newStatefulSetFixture(sc)
+	err := mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	stsPrimary, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", stsPrimary.Spec.Selector.MatchLabels["app"])
+	assert.Equal(t, "podinfo-primary", stsPrimary.Spec.Template.Labels["app"])
+	assert.Equal(t, int32(2), *stsPrimary.Spec.Replicas)
+	assert.Equal(t, "podinfo", stsPrimary.Spec.ServiceName)
+	assert.Equal(t, appsv1.ParallelPodManagement, stsPrimary.Spec.PodManagementPolicy)
+
+	// the primary pods get their own claims from the same templates
+	require.Len(t, stsPrimary.Spec.VolumeClaimTemplates, 1)
+	claim := stsPrimary.Spec.VolumeClaimTemplates[0]
+	assert.Equal(t, "data", claim.Name)
+	assert.Equal(t, resource.MustParse("1Gi"), claim.Spec.Resources.Requests["storage"])
+
+	// the primary uses the copies of the tracked configs
+	assert.Equal(t, "podinfo-config-env-primary",
+		stsPrimary.Spec.Template.Spec.Containers[0].Env[0].ValueFrom.ConfigMapKeyRef.Name)
+	assert.Equal(t, "podinfo-secret-vol-primary",
+		stsPrimary.Spec.Template.Spec.Volumes[0].Secret.SecretName)
+
+	sts, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *sts.Spec.Replicas)
+}
+
+func TestStatefulSetController_Promote(t *testing.T) {
+	sc := statefulsetConfigs{name: "podinfo", label: "app", labelValue: "podinfo"}
+	mocks := newStatefulSetFixture(sc)
+	err := mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	sts, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	stsClone := sts.DeepCopy()
+	stsClone.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+	stsClone.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests["storage"] = resource.MustParse("2Gi")
+	_, err = mocks.kubeClient.AppsV1().StatefulSets("default").Update(context.TODO(), stsClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	stsPrimary, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.1", stsPrimary.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, "podinfo-primary", stsPrimary.Spec.Template.Labels["app"])
+
+	// the claim templates are immutable and are not promoted
+	assert.Equal(t, resource.MustParse("1Gi"), stsPrimary.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests["storage"])
+}
+
+func TestStatefulSetController_HasTargetChanged(t *testing.T) {
+	sc := statefulsetConfigs{name: "podinfo", label: "app", labelValue: "podinfo"}
+	mocks := newStatefulSetFixture(sc)
+	err := mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	err = mocks.controller.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitializing})
+	require.NoError(t, err)
+	canary, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	isNew, err := mocks.controller.HasTargetChanged(canary)
+	require.NoError(t, err)
+	assert.False(t, isNew)
+
+	sts, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	stsClone := sts.DeepCopy()
+	stsClone.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+	_, err = mocks.kubeClient.AppsV1().StatefulSets("default").Update(context.TODO(), stsClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	isNew, err = mocks.controller.HasTargetChanged(canary)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+}
+
+func TestStatefulSetController_Scale(t *testing.T) {
+	sc := statefulsetConfigs{name: "podinfo", label: "app", labelValue: "podinfo"}
+	mocks := newStatefulSetFixture(sc)
+	err := mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	err = mocks.controller.ScaleFromZero(mocks.canary)
+	require.NoError(t, err)
+	sts, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), *sts.Spec.Replicas)
+
+	err = mocks.controller.ScaleToZero(mocks.canary)
+	require.NoError(t, err)
+	sts, err = mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *sts.Spec.Replicas)
+}
+
+func TestStatefulSetController_Finalize(t *testing.T) {
+	sc := statefulsetConfigs{name: "podinfo", label: "app", labelValue: "podinfo"}
+	mocks := newStatefulSetFixture(sc)
+	err := mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	err = mocks.controller.Finalize(mocks.canary)
+	require.NoError(t, err)
+
+	sts, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *sts.Spec.Replicas)
+}
+
+func TestStatefulSetController_isStatefulSetReady(t *testing.T) {
+	sc := statefulsetConfigs{name: "podinfo", label: "app", labelValue: "podinfo"}
+	mocks := newStatefulSetFixture(sc)
+	cd := &flaggerv1.Canary{}
+
+	// observed generation is less than desired generation
+	sts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Replicas: int32p(1)}}
+	sts.Status.ObservedGeneration--
+	retryable, err := mocks.controller.isStatefulSetReady(cd, sts)
+	require.Error(t, err)
+	require.True(t, retryable)
+
+	// succeeded
+	sts = &appsv1.StatefulSet{
+		Spec:   appsv1.StatefulSetSpec{Replicas: int32p(1)},
+		Status: appsv1.StatefulSetStatus{ReadyReplicas: 1, UpdatedReplicas: 1, CurrentRevision: "a", UpdateRevision: "a"},
+	}
+	retryable, err = mocks.controller.isStatefulSetReady(cd, sts)
+	require.NoError(t, err)
+	require.True(t, retryable)
+
+	// the update revision is not rolled out
+	sts.Status.UpdateRevision = "b"
+	cd.Status.LastTransitionTime = metav1.Now()
+	cd.Spec.ProgressDeadlineSeconds = int32p(60)
+	retryable, err = mocks.controller.isStatefulSetReady(cd, sts)
+	require.Error(t, err)
+	require.True(t, retryable)
+
+	// the updated pods above the partition are enough
+	sts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: int32p(1)}
+	sts.Status.UpdatedReplicas = 0
+	retryable, err = mocks.controller.isStatefulSetReady(cd, sts)
+	require.NoError(t, err)
+	require.True(t, retryable)
+
+	// deadline exceeded
+	sts = &appsv1.StatefulSet{
+		Spec:   appsv1.StatefulSetSpec{Replicas: int32p(1)},
+		Status: appsv1.StatefulSetStatus{ReadyReplicas: 0},
+	}
+	cd.Spec.ProgressDeadlineSeconds = int32p(-1e6)
+	retryable, err = mocks.controller.isStatefulSetReady(cd, sts)
+	require.Error(t, err)
+	require.False(t, retryable)
+}
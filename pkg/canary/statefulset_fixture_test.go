@@ -0,0 +1,216 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package canary
+
+import (
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+type statefulSetControllerFixture struct {
+	canary        *flaggerv1.Canary
+	kubeClient    kubernetes.Interface
+	flaggerClient clientset.Interface
+	controller    StatefulSetController
+	logger        *zap.SugaredLogger
+}
+
+type statefulsetConfigs struct {
+	name       string
+	labelValue string
+	label      string
+}
+
+func newStatefulSetFixture(sc statefulsetConfigs) statefulSetControllerFixture {
+	// init canary
+	canary := newStatefulSetControllerTestCanary(sc)
+	flaggerClient := fakeFlagger.NewSimpleClientset(canary)
+
+	// init kube clientset and register mock objects
+	kubeClient := fake.NewSimpleClientset(
+		newStatefulSetControllerTestPodInfo(sc),
+		newDaemonSetControllerTestConfigMap(),
+		newDaemonSetControllerTestSecretVol(),
+	)
+	// the fake clientset doesn't run the statefulset controller,
+	// the pods are reported as ready and updated on every write
+	kubeClient.PrependReactor("create", "statefulsets", statefulSetStatusReactor)
+	kubeClient.PrependReactor("update", "statefulsets", statefulSetStatusReactor)
+
+	logger, _ := logger.NewLogger("debug")
+
+	ctrl := StatefulSetController{
+		flaggerClient: flaggerClient,
+		kubeClient:    kubeClient,
+		logger:        logger,
+		labels:        []string{"app", "name"},
+		configTracker: &ConfigTracker{
+			Logger:        logger,
+			KubeClient:    kubeClient,
+			FlaggerClient: flaggerClient,
+		},
+	}
+
+	return statefulSetControllerFixture{
+		canary:        canary,
+		controller:    ctrl,
+		logger:        logger,
+		flaggerClient: flaggerClient,
+		kubeClient:    kubeClient,
+	}
+}
+
+func statefulSetStatusReactor(action k8stesting.Action) (bool, runtime.Object, error) {
+	if sts, ok := action.(k8stesting.CreateAction).GetObject().(*appsv1.StatefulSet); ok {
+		replicas := int32Default(sts.Spec.Replicas)
+		sts.Status = appsv1.StatefulSetStatus{
+			ObservedGeneration: sts.Generation,
+			Replicas:           replicas,
+			ReadyReplicas:      replicas,
+			UpdatedReplicas:    replicas,
+			CurrentRevision:    "rev",
+			UpdateRevision:     "rev",
+		}
+	}
+	return false, nil, nil
+}
+
+func newStatefulSetControllerTestCanary(sc statefulsetConfigs) *flaggerv1.Canary {
+	cd := &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       sc.name,
+				APIVersion: "apps/v1",
+				Kind:       "StatefulSet",
+			},
+		},
+	}
+	return cd
+}
+
+func newStatefulSetControllerTestPodInfo(sc statefulsetConfigs) *appsv1.StatefulSet {
+	s := &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      sc.name,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            int32p(2),
+			ServiceName:         sc.name,
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					sc.label: sc.labelValue,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						sc.label: sc.labelValue,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "podinfo",
+							Image: "quay.io/stefanprodan/podinfo:1.2.0",
+							Command: []string{
+								"./podinfo",
+								"--port=9898",
+							},
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: 9898,
+									Protocol:      corev1.ProtocolTCP,
+								},
+							},
+							Env: []corev1.EnvVar{
+								{
+									Name: "PODINFO_UI_COLOR",
+									ValueFrom: &corev1.EnvVarSource{
+										ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "podinfo-config-env",
+											},
+											Key: "color",
+										},
+									},
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "secret",
+									MountPath: "/etc/podinfo/secret",
+									ReadOnly:  true,
+								},
+								{
+									Name:      "data",
+									MountPath: "/data",
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "secret",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: "podinfo-secret-vol",
+								},
+							},
+						},
+					},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "data",
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: resource.MustParse("1Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return s
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package canary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// IsPrimaryReady checks the primary statefulset status and returns an error if
+// the statefulset is in the middle of a rolling update or if the pods are unhealthy
+func (c *StatefulSetController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	primaryName := cd.GetPrimaryName(cd.Spec.TargetRef.Name, c.primarySuffix)
+	primary, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", primaryName, cd.GetTargetNamespace(), err)
+	}
+
+	_, err = c.isStatefulSetReady(cd, primary)
+	if err != nil {
+		return fmt.Errorf("primary statefulset %s.%s not ready: %w", primaryName, cd.GetTargetNamespace(), err)
+	}
+
+	if primary.Spec.Replicas != nil && *primary.Spec.Replicas == 0 {
+		return fmt.Errorf("halt %s.%s advancement: primary statefulset is scaled to zero",
+			cd.Name, cd.Namespace)
+	}
+	return nil
+}
+
+// IsCanaryReady checks the canary statefulset status and returns an error if
+// the statefulset is in the middle of a rolling update or if the pods are unhealthy,
+// it will return a non retryable error if the rollout is stuck
+func (c *StatefulSetController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	targetName := cd.Spec.TargetRef.Name
+	canary, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return true, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+
+	retryable, err := c.isStatefulSetReady(cd, canary)
+	if err != nil {
+		return retryable, fmt.Errorf("canary statefulset %s.%s not ready: %w", targetName, cd.GetTargetNamespace(), err)
+	}
+	return true, nil
+}
+
+// isStatefulSetReady determines if a statefulset is ready by checking the ready replicas and the update revision
+// reference: https://github.com/kubernetes/kubectl/blob/v0.20.4/pkg/polymorphichelpers/rollout_status.go#L120
+func (c *StatefulSetController) isStatefulSetReady(cd *flaggerv1.Canary, statefulSet *appsv1.StatefulSet) (bool, error) {
+	if statefulSet.Generation <= statefulSet.Status.ObservedGeneration {
+		replicas := int32Default(statefulSet.Spec.Replicas)
+		partition := int32(0)
+		if ru := statefulSet.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+			partition = *ru.Partition
+		}
+
+		// calculate conditions
+		readyCond := statefulSet.Status.ReadyReplicas < replicas
+		updatedCond := statefulSet.Status.UpdatedReplicas < replicas-partition
+		revisionCond := partition == 0 && replicas > 0 && statefulSet.Status.UpdateRevision != statefulSet.Status.CurrentRevision
+		if !readyCond && !updatedCond && !revisionCond {
+			return true, nil
+		}
+
+		// check if deadline exceeded
+		from := cd.Status.LastTransitionTime
+		delta := time.Duration(cd.GetProgressDeadlineSeconds()) * time.Second
+		if from.Add(delta).Before(time.Now()) {
+			return false, fmt.Errorf("exceeded its progressDeadlineSeconds: %d", cd.GetProgressDeadlineSeconds())
+		}
+
+		// retryable
+		if readyCond {
+			return true, fmt.Errorf("waiting for rollout to finish: %d of %d pods are ready",
+				statefulSet.Status.ReadyReplicas, replicas)
+		} else if updatedCond {
+			return true, fmt.Errorf("waiting for rollout to finish: %d out of %d new pods have been updated",
+				statefulSet.Status.UpdatedReplicas, replicas-partition)
+		}
+		return true, fmt.Errorf("waiting for rollout to finish: update revision %s is not the current revision %s",
+			statefulSet.Status.UpdateRevision, statefulSet.Status.CurrentRevision)
+	}
+	return true, fmt.Errorf("waiting for rollout to finish: observed statefulset generation less then desired generation")
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// SyncStatus encodes the canary pod spec and updates the canary status
+func (c *StatefulSetController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
+	}
+
+	configs, err := c.configTracker.GetConfigRefs(cd)
+	if err != nil {
+		return fmt.Errorf("GetConfigRefs failed: %w", err)
+	}
+
+	return syncCanaryStatus(c.flaggerClient, cd, status, excludePaths(sts.Spec.Template, c.excludePaths), func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.TrackedConfigs = configs
+	})
+}
+
+// targetSpec returns the pod spec and the config refs of a statefulset listed in the canary targetRefs
+func (c *StatefulSetController) targetSpec(cd *flaggerv1.Canary) (interface{}, error) {
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.GetTargetNamespace()).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("statefulset %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.GetTargetNamespace(), err)
+	}
+
+	configs, err := c.configTracker.GetConfigRefs(cd)
+	if err != nil {
+		return nil, fmt.Errorf("GetConfigRefs failed: %w", err)
+	}
+	return []interface{}{excludePaths(sts.Spec.Template, c.excludePaths), configs}, nil
+}
+
+// SetStatusFailedChecks updates the canary failed checks counter
+func (c *StatefulSetController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusFailedChecks(c.flaggerClient, cd, val)
+}
+
+// SetStatusWeight updates the canary status weight value
+func (c *StatefulSetController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
+	return setStatusWeight(c.flaggerClient, cd, val)
+}
+
+// SetStatusIterations updates the canary status iterations value
+func (c *StatefulSetController) SetStatusIterations(cd *flaggerv1.Canary, val int) error {
+	return setStatusIterations(c.flaggerClient, cd, val)
+}
+
+// SetStatusPhase updates the canary status phase
+func (c *StatefulSetController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	return setStatusPhase(c.flaggerClient, cd, phase)
+}
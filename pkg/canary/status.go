@@ -44,11 +44,23 @@ func syncCanaryStatus(flaggerClient clientset.Interface, cd *flaggerv1.Canary, s
 
 		cdCopy := cd.DeepCopy()
 		cdCopy.Status.Phase = status.Phase
+		cdCopy.Status.CompletionTime = MakeCompletionTime(cd, status.Phase)
 		cdCopy.Status.CanaryWeight = status.CanaryWeight
 		cdCopy.Status.FailedChecks = status.FailedChecks
 		cdCopy.Status.Iterations = status.Iterations
 		cdCopy.Status.LastAppliedSpec = hash
 		cdCopy.Status.LastTransitionTime = metav1.Now()
+		if status.RunID != "" {
+			// discard the check results of the previous analysis
+			if status.RunID != cd.Status.RunID {
+				cdCopy.Status.Metrics = nil
+				cdCopy.Status.Webhooks = nil
+				cdCopy.Status.Approvals = nil
+				now := metav1.Now()
+				cdCopy.Status.StartTime = &now
+			}
+			cdCopy.Status.RunID = status.RunID
+		}
 		setAll(cdCopy)
 
 		if ok, conditions := MakeStatusConditions(cd, status.Phase); ok {
@@ -140,6 +152,83 @@ func setStatusIterations(flaggerClient clientset.Interface, cd *flaggerv1.Canary
 	return nil
 }
 
+// setStatusTrackedTargets updates the spec hashes of the canary targetRefs,
+// the canary is read again since its status has just been synced
+func setStatusTrackedTargets(flaggerClient clientset.Interface, cd *flaggerv1.Canary, targets map[string]string) error {
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cd, err := flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.TrackedTargets = &targets
+		return updateStatusWithUpgrade(flaggerClient, cdCopy)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
+// setStatusOriginalTarget updates the recorded state of the canary target,
+// the canary is read again as the state can be recorded after a status sync
+func setStatusOriginalTarget(flaggerClient clientset.Interface, cd *flaggerv1.Canary, update func(state *flaggerv1.CanaryTargetState)) error {
+	name, ns := cd.GetName(), cd.GetNamespace()
+	key := targetKey(cd.Spec.TargetRef)
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cd, err := flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+		}
+
+		cdCopy := cd.DeepCopy()
+		if cdCopy.Status.OriginalTargets == nil {
+			cdCopy.Status.OriginalTargets = make(map[string]flaggerv1.CanaryTargetState)
+		}
+		state := cdCopy.Status.OriginalTargets[key]
+		update(&state)
+		cdCopy.Status.OriginalTargets[key] = state
+		return updateStatusWithUpgrade(flaggerClient, cdCopy)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
+// getOriginalTarget returns the recorded state of the canary target
+func getOriginalTarget(cd *flaggerv1.Canary) flaggerv1.CanaryTargetState {
+	return cd.Status.OriginalTargets[targetKey(cd.Spec.TargetRef)]
+}
+
+// recordOriginalReplicas records the replicas of the target before it's scaled to zero,
+// the replicas set since the last record replace the recorded ones
+func recordOriginalReplicas(flaggerClient clientset.Interface, cd *flaggerv1.Canary, replicas int32) error {
+	recorded := getOriginalTarget(cd).Replicas
+	if replicas <= 0 || (recorded != nil && *recorded == replicas) {
+		return nil
+	}
+	return setStatusOriginalTarget(flaggerClient, cd, func(state *flaggerv1.CanaryTargetState) {
+		state.Replicas = int32p(replicas)
+	})
+}
+
+// scaleFromZeroReplicas returns the replicas of the target when it's scaled from zero,
+// the current replicas are kept if set, otherwise the recorded ones are restored
+func scaleFromZeroReplicas(cd *flaggerv1.Canary, current int32) int32 {
+	if current > 0 {
+		return current
+	}
+	if recorded := getOriginalTarget(cd).Replicas; recorded != nil && *recorded > 0 {
+		return *recorded
+	}
+	return 1
+}
+
 func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
@@ -154,6 +243,7 @@ func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, pha
 		cdCopy := cd.DeepCopy()
 		cdCopy.Status.Phase = phase
 		cdCopy.Status.LastTransitionTime = metav1.Now()
+		cdCopy.Status.CompletionTime = MakeCompletionTime(cd, phase)
 
 		if phase != flaggerv1.CanaryPhaseProgressing && phase != flaggerv1.CanaryPhaseWaiting {
 			cdCopy.Status.CanaryWeight = 0
@@ -236,17 +326,102 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 		Reason:             string(phase),
 	}
 
+	conditions := cd.Status.Conditions
+	changed := false
+	if currentCondition == nil ||
+		currentCondition.Status != newCondition.Status ||
+		currentCondition.Reason != newCondition.Reason {
+		if currentCondition != nil && currentCondition.Status == newCondition.Status {
+			newCondition.LastTransitionTime = currentCondition.LastTransitionTime
+		}
+		conditions = setStatusCondition(conditions, *newCondition)
+		changed = true
+	}
+
+	if ok, completeCondition := makePromotionCompleteCondition(cd, phase); ok {
+		conditions = setStatusCondition(conditions, completeCondition)
+		changed = true
+	}
+
+	if !changed {
+		return false, nil
+	}
+	return true, conditions
+}
+
+// makePromotionCompleteCondition returns the PromotionComplete condition for the canary phase,
+// the condition is true when the primary has been initialized or the canary promoted
+func makePromotionCompleteCondition(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) (bool, flaggerv1.CanaryCondition) {
+	currentCondition := getStatusCondition(cd.Status, flaggerv1.PromotionCompleteType)
+
+	newCondition := flaggerv1.CanaryCondition{
+		Type:               flaggerv1.PromotionCompleteType,
+		Status:             corev1.ConditionFalse,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(phase),
+		Message:            "Canary analysis in progress.",
+	}
+	switch phase {
+	case flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseSucceeded:
+		newCondition.Status = corev1.ConditionTrue
+		newCondition.Message = "Primary runs the latest revision."
+	case flaggerv1.CanaryPhaseFailed:
+		newCondition.Message = "Canary analysis failed, promotion aborted."
+	}
+
 	if currentCondition != nil &&
 		currentCondition.Status == newCondition.Status &&
 		currentCondition.Reason == newCondition.Reason {
-		return false, nil
+		return false, newCondition
 	}
-
 	if currentCondition != nil && currentCondition.Status == newCondition.Status {
 		newCondition.LastTransitionTime = currentCondition.LastTransitionTime
 	}
+	return true, newCondition
+}
+
+// MakeCompletionTime returns the completion time of the analysis for the canary phase,
+// the time is kept when the phase doesn't change and is nil while an analysis runs
+func MakeCompletionTime(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) *metav1.Time {
+	switch phase {
+	case flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseSucceeded, flaggerv1.CanaryPhaseFailed:
+		if cd.Status.CompletionTime != nil && cd.Status.Phase == phase {
+			return cd.Status.CompletionTime
+		}
+		now := metav1.Now()
+		return &now
+	}
+	return nil
+}
+
+// MakeSuspendedCondition updates the canary status conditions based on spec.suspend,
+// the Suspended condition is only added once the canary has been suspended
+func MakeSuspendedCondition(cd *flaggerv1.Canary, suspended bool) (bool, []flaggerv1.CanaryCondition) {
+	currentCondition := getStatusCondition(cd.Status, flaggerv1.SuspendedType)
+
+	newCondition := flaggerv1.CanaryCondition{
+		Type:               flaggerv1.SuspendedType,
+		Status:             corev1.ConditionFalse,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(flaggerv1.ReasonResumed),
+		Message:            "Canary analysis resumed.",
+	}
+	if suspended {
+		newCondition.Status = corev1.ConditionTrue
+		newCondition.Reason = string(flaggerv1.ReasonSuspended)
+		newCondition.Message = "Canary analysis suspended."
+	}
+
+	if currentCondition == nil && !suspended {
+		return false, nil
+	}
+	if currentCondition != nil && currentCondition.Status == newCondition.Status {
+		return false, nil
+	}
 
-	return true, []flaggerv1.CanaryCondition{*newCondition}
+	return true, setStatusCondition(cd.Status.Conditions, newCondition)
 }
 
 // updateStatusWithUpgrade tries to update the status sub-resource
@@ -269,3 +444,21 @@ func updateStatusWithUpgrade(flaggerClient clientset.Interface, cd *flaggerv1.Ca
 	}
 	return err
 }
+
+// setStatusCondition returns a copy of the conditions with the condition
+// of the same type replaced, the condition is appended if the type is not found
+func setStatusCondition(conditions []flaggerv1.CanaryCondition, condition flaggerv1.CanaryCondition) []flaggerv1.CanaryCondition {
+	result := make([]flaggerv1.CanaryCondition, 0, len(conditions)+1)
+	found := false
+	for _, c := range conditions {
+		if c.Type == condition.Type {
+			c = condition
+			found = true
+		}
+		result = append(result, c)
+	}
+	if !found {
+		result = append(result, condition)
+	}
+	return result
+}
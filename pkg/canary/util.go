@@ -20,9 +20,12 @@ import (
 	"crypto/rand"
 	"fmt"
 	"io"
+	"path"
+	"reflect"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -62,6 +65,9 @@ func getPorts(cd *flaggerv1.Canary, cs []corev1.Container) map[string]int32 {
 			if p.Name != "" {
 				name = p.Name
 			}
+			if !isPortDiscovered(cd.Spec.Service.PortDiscoveryRules, name) {
+				continue
+			}
 
 			ports[name] = p.ContainerPort
 		}
@@ -69,6 +75,26 @@ func getPorts(cd *flaggerv1.Canary, cs []corev1.Container) map[string]int32 {
 	return ports
 }
 
+// isPortDiscovered matches the port name against the include and exclude patterns,
+// the excluded ports are left out even if they are included
+func isPortDiscovered(rules *flaggerv1.PortDiscoveryRules, name string) bool {
+	if rules == nil {
+		return true
+	}
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(rules.Include) > 0 && !matches(rules.Include) {
+		return false
+	}
+	return !matches(rules.Exclude)
+}
+
 // makeAnnotations appends an unique ID to annotations map
 func makeAnnotations(annotations map[string]string) (map[string]string, error) {
 	idKey := "flagger-id"
@@ -106,6 +132,77 @@ func includeLabelsByPrefix(labels map[string]string, includeLabelPrefixes []stri
 	return filteredLabels
 }
 
+// makePrimaryPodSelectors returns a copy of the pod spec where the label selectors of the pod affinity,
+// anti-affinity and topology spread constraints that match the target pods select the primary pods,
+// so that the primary pods are spread among themselves instead of among the target pods
+func makePrimaryPodSelectors(spec corev1.PodSpec, labels []string, labelValue string, suffix string) corev1.PodSpec {
+	return replacePodSelectors(spec, labels, labelValue, labelValue+suffix)
+}
+
+// replacePodSelectors returns a copy of the pod spec where the label value is replaced
+// in the label selectors of the pod affinity, anti-affinity and topology spread constraints
+func replacePodSelectors(spec corev1.PodSpec, labels []string, from string, to string) corev1.PodSpec {
+	replaceSelector := func(selector *metav1.LabelSelector) *metav1.LabelSelector {
+		if selector == nil {
+			return nil
+		}
+		res := selector.DeepCopy()
+		for key, value := range res.MatchLabels {
+			if contains(labels, key) && value == from {
+				res.MatchLabels[key] = to
+			}
+		}
+		for i, expr := range res.MatchExpressions {
+			if !contains(labels, expr.Key) {
+				continue
+			}
+			for j, value := range expr.Values {
+				if value == from {
+					res.MatchExpressions[i].Values[j] = to
+				}
+			}
+		}
+		return res
+	}
+
+	if len(spec.TopologySpreadConstraints) > 0 {
+		constraints := make([]corev1.TopologySpreadConstraint, 0, len(spec.TopologySpreadConstraints))
+		for _, constraint := range spec.TopologySpreadConstraints {
+			constraint.LabelSelector = replaceSelector(constraint.LabelSelector)
+			constraints = append(constraints, constraint)
+		}
+		spec.TopologySpreadConstraints = constraints
+	}
+
+	if spec.Affinity != nil {
+		affinity := spec.Affinity.DeepCopy()
+		if pa := affinity.PodAffinity; pa != nil {
+			makePrimaryAffinityTerms(pa.RequiredDuringSchedulingIgnoredDuringExecution, pa.PreferredDuringSchedulingIgnoredDuringExecution, replaceSelector)
+		}
+		if paa := affinity.PodAntiAffinity; paa != nil {
+			makePrimaryAffinityTerms(paa.RequiredDuringSchedulingIgnoredDuringExecution, paa.PreferredDuringSchedulingIgnoredDuringExecution, replaceSelector)
+		}
+		spec.Affinity = affinity
+	}
+	return spec
+}
+
+func makePrimaryAffinityTerms(required []corev1.PodAffinityTerm, preferred []corev1.WeightedPodAffinityTerm,
+	primarySelector func(*metav1.LabelSelector) *metav1.LabelSelector) {
+	for i := range required {
+		required[i].LabelSelector = primarySelector(required[i].LabelSelector)
+	}
+	for i := range preferred {
+		preferred[i].PodAffinityTerm.LabelSelector = primarySelector(preferred[i].PodAffinityTerm.LabelSelector)
+	}
+}
+
+// readyReplicas returns the number of available replicas required for a workload to be ready,
+// the ready threshold is a percentage of the desired replicas rounded up
+func readyReplicas(desired int32, readyThreshold int) int32 {
+	return int32((int64(desired)*int64(readyThreshold) + 99) / 100)
+}
+
 func makePrimaryLabels(labels map[string]string, labelValue string, label string) map[string]string {
 	res := make(map[string]string)
 	for k, v := range labels {
@@ -118,6 +215,22 @@ func makePrimaryLabels(labels map[string]string, labelValue string, label string
 	return res
 }
 
+// restorePrimaryTemplate sets the pod template of the target to the primary pod template,
+// the names of the configs copied for the primary and the primary label value are mapped back to the ones of the target,
+// it returns true if the target pod template changed
+func restorePrimaryTemplate(target *corev1.PodTemplateSpec, primary corev1.PodTemplateSpec, configs map[string]bool,
+	labels []string, label string, labelValue string, suffix string) bool {
+	spec := restoreConfigNames(*primary.Spec.DeepCopy(), configs, suffix)
+	spec = replacePodSelectors(spec, labels, labelValue+suffix, labelValue)
+	templateLabels := makePrimaryLabels(primary.Labels, labelValue, label)
+	if reflect.DeepEqual(target.Spec, spec) && reflect.DeepEqual(target.Labels, templateLabels) {
+		return false
+	}
+	target.Spec = spec
+	target.Labels = templateLabels
+	return true
+}
+
 func int32p(i int32) *int32 {
 	return &i
 }
@@ -20,6 +20,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
 func TestIncludeLabelsByPrefix(t *testing.T) {
@@ -69,3 +73,77 @@ func TestMakePrimaryLabels(t *testing.T) {
 		"foo":   "new-bar", // overriden value for a specific label
 	})
 }
+
+func TestMakePrimaryPodSelectors(t *testing.T) {
+	selector := func() *metav1.LabelSelector {
+		return &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "podinfo", "tier": "podinfo"},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"podinfo", "other"}},
+			},
+		}
+	}
+	spec := corev1.PodSpec{
+		Affinity: &corev1.Affinity{
+			PodAffinity: &corev1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{LabelSelector: selector()}},
+			},
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+					{PodAffinityTerm: corev1.PodAffinityTerm{LabelSelector: selector()}},
+				},
+			},
+		},
+		TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{LabelSelector: selector()}, {}},
+	}
+
+	primary := makePrimaryPodSelectors(spec, []string{"app", "name"}, "podinfo", "-primary")
+	for _, s := range []*metav1.LabelSelector{
+		primary.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector,
+		primary.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm.LabelSelector,
+		primary.TopologySpreadConstraints[0].LabelSelector,
+	} {
+		assert.Equal(t, "podinfo-primary", s.MatchLabels["app"])
+		assert.Equal(t, "podinfo", s.MatchLabels["tier"])
+		assert.Equal(t, []string{"podinfo-primary", "other"}, s.MatchExpressions[0].Values)
+	}
+	assert.Nil(t, primary.TopologySpreadConstraints[1].LabelSelector)
+
+	// the target pod spec is unchanged
+	assert.Equal(t, selector(), spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector)
+	assert.Equal(t, selector(), spec.TopologySpreadConstraints[0].LabelSelector)
+}
+
+func TestGetPortsWithRules(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Service: flaggerv1.CanaryService{
+				Port:          9898,
+				PortDiscovery: true,
+				PortDiscoveryRules: &flaggerv1.PortDiscoveryRules{
+					Include: []string{"http-*", "grpc"},
+					Exclude: []string{"http-admin"},
+				},
+			},
+		},
+	}
+	containers := []corev1.Container{
+		{
+			Name: "app",
+			Ports: []corev1.ContainerPort{
+				{Name: "http", ContainerPort: 9898},
+				{Name: "http-metrics", ContainerPort: 9797},
+				{Name: "http-admin", ContainerPort: 9696},
+				{Name: "grpc", ContainerPort: 9999},
+				{ContainerPort: 8080},
+			},
+		},
+	}
+
+	ports := getPorts(cd, containers)
+
+	assert.Equal(t, map[string]int32{
+		"http-metrics": 9797,
+		"grpc":         9999,
+	}, ports)
+}
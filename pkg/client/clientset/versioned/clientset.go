@@ -24,11 +24,22 @@ import (
 	appmeshv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1"
 	appmeshv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta2"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1"
+	gatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gatewayapi/v1beta1"
 	gatewayv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gloo/v1"
+	helmv2beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/helm/v2beta1"
 	networkingv1alpha3 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
+	karmadav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/karmada/v1alpha1"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1"
+	kruisev1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kruise/v1alpha1"
+	kustomizev1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kustomize/v1beta2"
+	policyv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/linkerd/v1beta2"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/monitoring/v1"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1"
+	secretsstorev1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/secretsstore/v1"
 	splitv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1"
 	splitv1alpha2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha2"
+	splitv1alpha4 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha4"
+	specsv1alpha4 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smispecs/v1alpha4"
 	traefikv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/traefik/v1alpha1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
@@ -40,11 +51,22 @@ type Interface interface {
 	AppmeshV1beta2() appmeshv1beta2.AppmeshV1beta2Interface
 	AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface
 	FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface
+	GatewayAPIV1beta1() gatewayapiv1beta1.GatewayAPIV1beta1Interface
 	GatewayV1() gatewayv1.GatewayV1Interface
+	HelmV2beta1() helmv2beta1.HelmV2beta1Interface
 	NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface
+	KarmadaV1alpha1() karmadav1alpha1.KarmadaV1alpha1Interface
+	KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface
+	KruiseV1alpha1() kruisev1alpha1.KruiseV1alpha1Interface
+	KustomizeV1beta2() kustomizev1beta2.KustomizeV1beta2Interface
+	PolicyV1beta2() policyv1beta2.PolicyV1beta2Interface
+	MonitoringV1() monitoringv1.MonitoringV1Interface
 	ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface
+	SecretsStoreV1() secretsstorev1.SecretsStoreV1Interface
 	SplitV1alpha1() splitv1alpha1.SplitV1alpha1Interface
 	SplitV1alpha2() splitv1alpha2.SplitV1alpha2Interface
+	SplitV1alpha4() splitv1alpha4.SplitV1alpha4Interface
+	SpecsV1alpha4() specsv1alpha4.SpecsV1alpha4Interface
 	TraefikV1alpha1() traefikv1alpha1.TraefikV1alpha1Interface
 }
 
@@ -55,11 +77,22 @@ type Clientset struct {
 	appmeshV1beta2     *appmeshv1beta2.AppmeshV1beta2Client
 	appmeshV1beta1     *appmeshv1beta1.AppmeshV1beta1Client
 	flaggerV1beta1     *flaggerv1beta1.FlaggerV1beta1Client
+	gatewayAPIV1beta1  *gatewayapiv1beta1.GatewayAPIV1beta1Client
 	gatewayV1          *gatewayv1.GatewayV1Client
+	helmV2beta1        *helmv2beta1.HelmV2beta1Client
 	networkingV1alpha3 *networkingv1alpha3.NetworkingV1alpha3Client
+	karmadaV1alpha1    *karmadav1alpha1.KarmadaV1alpha1Client
+	kedaV1alpha1       *kedav1alpha1.KedaV1alpha1Client
+	kruiseV1alpha1     *kruisev1alpha1.KruiseV1alpha1Client
+	kustomizeV1beta2   *kustomizev1beta2.KustomizeV1beta2Client
+	policyV1beta2      *policyv1beta2.PolicyV1beta2Client
+	monitoringV1       *monitoringv1.MonitoringV1Client
 	projectcontourV1   *projectcontourv1.ProjectcontourV1Client
+	secretsStoreV1     *secretsstorev1.SecretsStoreV1Client
 	splitV1alpha1      *splitv1alpha1.SplitV1alpha1Client
 	splitV1alpha2      *splitv1alpha2.SplitV1alpha2Client
+	splitV1alpha4      *splitv1alpha4.SplitV1alpha4Client
+	specsV1alpha4      *specsv1alpha4.SpecsV1alpha4Client
 	traefikV1alpha1    *traefikv1alpha1.TraefikV1alpha1Client
 }
 
@@ -78,21 +111,66 @@ func (c *Clientset) FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface {
 	return c.flaggerV1beta1
 }
 
+// GatewayAPIV1beta1 retrieves the GatewayAPIV1beta1Client
+func (c *Clientset) GatewayAPIV1beta1() gatewayapiv1beta1.GatewayAPIV1beta1Interface {
+	return c.gatewayAPIV1beta1
+}
+
 // GatewayV1 retrieves the GatewayV1Client
 func (c *Clientset) GatewayV1() gatewayv1.GatewayV1Interface {
 	return c.gatewayV1
 }
 
+// HelmV2beta1 retrieves the HelmV2beta1Client
+func (c *Clientset) HelmV2beta1() helmv2beta1.HelmV2beta1Interface {
+	return c.helmV2beta1
+}
+
 // NetworkingV1alpha3 retrieves the NetworkingV1alpha3Client
 func (c *Clientset) NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface {
 	return c.networkingV1alpha3
 }
 
+// KarmadaV1alpha1 retrieves the KarmadaV1alpha1Client
+func (c *Clientset) KarmadaV1alpha1() karmadav1alpha1.KarmadaV1alpha1Interface {
+	return c.karmadaV1alpha1
+}
+
+// KedaV1alpha1 retrieves the KedaV1alpha1Client
+func (c *Clientset) KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface {
+	return c.kedaV1alpha1
+}
+
+// KruiseV1alpha1 retrieves the KruiseV1alpha1Client
+func (c *Clientset) KruiseV1alpha1() kruisev1alpha1.KruiseV1alpha1Interface {
+	return c.kruiseV1alpha1
+}
+
+// KustomizeV1beta2 retrieves the KustomizeV1beta2Client
+func (c *Clientset) KustomizeV1beta2() kustomizev1beta2.KustomizeV1beta2Interface {
+	return c.kustomizeV1beta2
+}
+
+// PolicyV1beta2 retrieves the PolicyV1beta2Client
+func (c *Clientset) PolicyV1beta2() policyv1beta2.PolicyV1beta2Interface {
+	return c.policyV1beta2
+}
+
+// MonitoringV1 retrieves the MonitoringV1Client
+func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
+	return c.monitoringV1
+}
+
 // ProjectcontourV1 retrieves the ProjectcontourV1Client
 func (c *Clientset) ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface {
 	return c.projectcontourV1
 }
 
+// SecretsStoreV1 retrieves the SecretsStoreV1Client
+func (c *Clientset) SecretsStoreV1() secretsstorev1.SecretsStoreV1Interface {
+	return c.secretsStoreV1
+}
+
 // SplitV1alpha1 retrieves the SplitV1alpha1Client
 func (c *Clientset) SplitV1alpha1() splitv1alpha1.SplitV1alpha1Interface {
 	return c.splitV1alpha1
@@ -103,6 +181,16 @@ func (c *Clientset) SplitV1alpha2() splitv1alpha2.SplitV1alpha2Interface {
 	return c.splitV1alpha2
 }
 
+// SplitV1alpha4 retrieves the SplitV1alpha4Client
+func (c *Clientset) SplitV1alpha4() splitv1alpha4.SplitV1alpha4Interface {
+	return c.splitV1alpha4
+}
+
+// SpecsV1alpha4 retrieves the SpecsV1alpha4Client
+func (c *Clientset) SpecsV1alpha4() specsv1alpha4.SpecsV1alpha4Interface {
+	return c.specsV1alpha4
+}
+
 // TraefikV1alpha1 retrieves the TraefikV1alpha1Client
 func (c *Clientset) TraefikV1alpha1() traefikv1alpha1.TraefikV1alpha1Interface {
 	return c.traefikV1alpha1
@@ -141,18 +229,54 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.gatewayAPIV1beta1, err = gatewayapiv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.gatewayV1, err = gatewayv1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
 	}
+	cs.helmV2beta1, err = helmv2beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.networkingV1alpha3, err = networkingv1alpha3.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
 	}
+	cs.karmadaV1alpha1, err = karmadav1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.kedaV1alpha1, err = kedav1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.kruiseV1alpha1, err = kruisev1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.kustomizeV1beta2, err = kustomizev1beta2.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.policyV1beta2, err = policyv1beta2.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.monitoringV1, err = monitoringv1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.projectcontourV1, err = projectcontourv1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
 	}
+	cs.secretsStoreV1, err = secretsstorev1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.splitV1alpha1, err = splitv1alpha1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -161,6 +285,14 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.splitV1alpha4, err = splitv1alpha4.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.specsV1alpha4, err = specsv1alpha4.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.traefikV1alpha1, err = traefikv1alpha1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -180,11 +312,22 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 	cs.appmeshV1beta2 = appmeshv1beta2.NewForConfigOrDie(c)
 	cs.appmeshV1beta1 = appmeshv1beta1.NewForConfigOrDie(c)
 	cs.flaggerV1beta1 = flaggerv1beta1.NewForConfigOrDie(c)
+	cs.gatewayAPIV1beta1 = gatewayapiv1beta1.NewForConfigOrDie(c)
 	cs.gatewayV1 = gatewayv1.NewForConfigOrDie(c)
+	cs.helmV2beta1 = helmv2beta1.NewForConfigOrDie(c)
 	cs.networkingV1alpha3 = networkingv1alpha3.NewForConfigOrDie(c)
+	cs.karmadaV1alpha1 = karmadav1alpha1.NewForConfigOrDie(c)
+	cs.kedaV1alpha1 = kedav1alpha1.NewForConfigOrDie(c)
+	cs.kruiseV1alpha1 = kruisev1alpha1.NewForConfigOrDie(c)
+	cs.kustomizeV1beta2 = kustomizev1beta2.NewForConfigOrDie(c)
+	cs.policyV1beta2 = policyv1beta2.NewForConfigOrDie(c)
+	cs.monitoringV1 = monitoringv1.NewForConfigOrDie(c)
 	cs.projectcontourV1 = projectcontourv1.NewForConfigOrDie(c)
+	cs.secretsStoreV1 = secretsstorev1.NewForConfigOrDie(c)
 	cs.splitV1alpha1 = splitv1alpha1.NewForConfigOrDie(c)
 	cs.splitV1alpha2 = splitv1alpha2.NewForConfigOrDie(c)
+	cs.splitV1alpha4 = splitv1alpha4.NewForConfigOrDie(c)
+	cs.specsV1alpha4 = specsv1alpha4.NewForConfigOrDie(c)
 	cs.traefikV1alpha1 = traefikv1alpha1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
@@ -197,11 +340,22 @@ func New(c rest.Interface) *Clientset {
 	cs.appmeshV1beta2 = appmeshv1beta2.New(c)
 	cs.appmeshV1beta1 = appmeshv1beta1.New(c)
 	cs.flaggerV1beta1 = flaggerv1beta1.New(c)
+	cs.gatewayAPIV1beta1 = gatewayapiv1beta1.New(c)
 	cs.gatewayV1 = gatewayv1.New(c)
+	cs.helmV2beta1 = helmv2beta1.New(c)
 	cs.networkingV1alpha3 = networkingv1alpha3.New(c)
+	cs.karmadaV1alpha1 = karmadav1alpha1.New(c)
+	cs.kedaV1alpha1 = kedav1alpha1.New(c)
+	cs.kruiseV1alpha1 = kruisev1alpha1.New(c)
+	cs.kustomizeV1beta2 = kustomizev1beta2.New(c)
+	cs.policyV1beta2 = policyv1beta2.New(c)
+	cs.monitoringV1 = monitoringv1.New(c)
 	cs.projectcontourV1 = projectcontourv1.New(c)
+	cs.secretsStoreV1 = secretsstorev1.New(c)
 	cs.splitV1alpha1 = splitv1alpha1.New(c)
 	cs.splitV1alpha2 = splitv1alpha2.New(c)
+	cs.splitV1alpha4 = splitv1alpha4.New(c)
+	cs.specsV1alpha4 = specsv1alpha4.New(c)
 	cs.traefikV1alpha1 = traefikv1alpha1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
@@ -26,16 +26,38 @@ import (
 	fakeappmeshv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta2/fake"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1"
 	fakeflaggerv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1/fake"
+	gatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gatewayapi/v1beta1"
+	fakegatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gatewayapi/v1beta1/fake"
 	gatewayv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gloo/v1"
 	fakegatewayv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gloo/v1/fake"
+	helmv2beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/helm/v2beta1"
+	fakehelmv2beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/helm/v2beta1/fake"
 	networkingv1alpha3 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
 	fakenetworkingv1alpha3 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3/fake"
+	karmadav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/karmada/v1alpha1"
+	fakekarmadav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/karmada/v1alpha1/fake"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1"
+	fakekedav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1/fake"
+	kruisev1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kruise/v1alpha1"
+	fakekruisev1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kruise/v1alpha1/fake"
+	kustomizev1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kustomize/v1beta2"
+	fakekustomizev1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kustomize/v1beta2/fake"
+	policyv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/linkerd/v1beta2"
+	fakepolicyv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/linkerd/v1beta2/fake"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/monitoring/v1"
+	fakemonitoringv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/monitoring/v1/fake"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1"
 	fakeprojectcontourv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1/fake"
+	secretsstorev1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/secretsstore/v1"
+	fakesecretsstorev1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/secretsstore/v1/fake"
 	splitv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1"
 	fakesplitv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1/fake"
 	splitv1alpha2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha2"
 	fakesplitv1alpha2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha2/fake"
+	splitv1alpha4 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha4"
+	fakesplitv1alpha4 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha4/fake"
+	specsv1alpha4 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smispecs/v1alpha4"
+	fakespecsv1alpha4 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smispecs/v1alpha4/fake"
 	traefikv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/traefik/v1alpha1"
 	faketraefikv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/traefik/v1alpha1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -107,21 +129,66 @@ func (c *Clientset) FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface {
 	return &fakeflaggerv1beta1.FakeFlaggerV1beta1{Fake: &c.Fake}
 }
 
+// GatewayAPIV1beta1 retrieves the GatewayAPIV1beta1Client
+func (c *Clientset) GatewayAPIV1beta1() gatewayapiv1beta1.GatewayAPIV1beta1Interface {
+	return &fakegatewayapiv1beta1.FakeGatewayAPIV1beta1{Fake: &c.Fake}
+}
+
 // GatewayV1 retrieves the GatewayV1Client
 func (c *Clientset) GatewayV1() gatewayv1.GatewayV1Interface {
 	return &fakegatewayv1.FakeGatewayV1{Fake: &c.Fake}
 }
 
+// HelmV2beta1 retrieves the HelmV2beta1Client
+func (c *Clientset) HelmV2beta1() helmv2beta1.HelmV2beta1Interface {
+	return &fakehelmv2beta1.FakeHelmV2beta1{Fake: &c.Fake}
+}
+
 // NetworkingV1alpha3 retrieves the NetworkingV1alpha3Client
 func (c *Clientset) NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface {
 	return &fakenetworkingv1alpha3.FakeNetworkingV1alpha3{Fake: &c.Fake}
 }
 
+// KarmadaV1alpha1 retrieves the KarmadaV1alpha1Client
+func (c *Clientset) KarmadaV1alpha1() karmadav1alpha1.KarmadaV1alpha1Interface {
+	return &fakekarmadav1alpha1.FakeKarmadaV1alpha1{Fake: &c.Fake}
+}
+
+// KedaV1alpha1 retrieves the KedaV1alpha1Client
+func (c *Clientset) KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface {
+	return &fakekedav1alpha1.FakeKedaV1alpha1{Fake: &c.Fake}
+}
+
+// KruiseV1alpha1 retrieves the KruiseV1alpha1Client
+func (c *Clientset) KruiseV1alpha1() kruisev1alpha1.KruiseV1alpha1Interface {
+	return &fakekruisev1alpha1.FakeKruiseV1alpha1{Fake: &c.Fake}
+}
+
+// KustomizeV1beta2 retrieves the KustomizeV1beta2Client
+func (c *Clientset) KustomizeV1beta2() kustomizev1beta2.KustomizeV1beta2Interface {
+	return &fakekustomizev1beta2.FakeKustomizeV1beta2{Fake: &c.Fake}
+}
+
+// PolicyV1beta2 retrieves the PolicyV1beta2Client
+func (c *Clientset) PolicyV1beta2() policyv1beta2.PolicyV1beta2Interface {
+	return &fakepolicyv1beta2.FakePolicyV1beta2{Fake: &c.Fake}
+}
+
+// MonitoringV1 retrieves the MonitoringV1Client
+func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
+	return &fakemonitoringv1.FakeMonitoringV1{Fake: &c.Fake}
+}
+
 // ProjectcontourV1 retrieves the ProjectcontourV1Client
 func (c *Clientset) ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface {
 	return &fakeprojectcontourv1.FakeProjectcontourV1{Fake: &c.Fake}
 }
 
+// SecretsStoreV1 retrieves the SecretsStoreV1Client
+func (c *Clientset) SecretsStoreV1() secretsstorev1.SecretsStoreV1Interface {
+	return &fakesecretsstorev1.FakeSecretsStoreV1{Fake: &c.Fake}
+}
+
 // SplitV1alpha1 retrieves the SplitV1alpha1Client
 func (c *Clientset) SplitV1alpha1() splitv1alpha1.SplitV1alpha1Interface {
 	return &fakesplitv1alpha1.FakeSplitV1alpha1{Fake: &c.Fake}
@@ -132,6 +199,16 @@ func (c *Clientset) SplitV1alpha2() splitv1alpha2.SplitV1alpha2Interface {
 	return &fakesplitv1alpha2.FakeSplitV1alpha2{Fake: &c.Fake}
 }
 
+// SplitV1alpha4 retrieves the SplitV1alpha4Client
+func (c *Clientset) SplitV1alpha4() splitv1alpha4.SplitV1alpha4Interface {
+	return &fakesplitv1alpha4.FakeSplitV1alpha4{Fake: &c.Fake}
+}
+
+// SpecsV1alpha4 retrieves the SpecsV1alpha4Client
+func (c *Clientset) SpecsV1alpha4() specsv1alpha4.SpecsV1alpha4Interface {
+	return &fakespecsv1alpha4.FakeSpecsV1alpha4{Fake: &c.Fake}
+}
+
 // TraefikV1alpha1 retrieves the TraefikV1alpha1Client
 func (c *Clientset) TraefikV1alpha1() traefikv1alpha1.TraefikV1alpha1Interface {
 	return &faketraefikv1alpha1.FakeTraefikV1alpha1{Fake: &c.Fake}
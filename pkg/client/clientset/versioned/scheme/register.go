@@ -22,11 +22,22 @@ import (
 	appmeshv1beta1 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta1"
 	appmeshv1beta2 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta2"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	gatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1beta1"
 	gatewayv1 "github.com/fluxcd/flagger/pkg/apis/gloo/v1"
+	helmv2beta1 "github.com/fluxcd/flagger/pkg/apis/helm/v2beta1"
 	networkingv1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	karmadav1alpha1 "github.com/fluxcd/flagger/pkg/apis/karmada/v1alpha1"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+	kruisev1alpha1 "github.com/fluxcd/flagger/pkg/apis/kruise/v1alpha1"
+	kustomizev1beta2 "github.com/fluxcd/flagger/pkg/apis/kustomize/v1beta2"
+	policyv1beta2 "github.com/fluxcd/flagger/pkg/apis/linkerd/v1beta2"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/apis/monitoring/v1"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/apis/projectcontour/v1"
+	secretsstorev1 "github.com/fluxcd/flagger/pkg/apis/secretsstore/v1"
 	splitv1alpha1 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha1"
 	splitv1alpha2 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha2"
+	splitv1alpha4 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha4"
+	specsv1alpha4 "github.com/fluxcd/flagger/pkg/apis/smispecs/v1alpha4"
 	traefikv1alpha1 "github.com/fluxcd/flagger/pkg/apis/traefik/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -42,11 +53,22 @@ var localSchemeBuilder = runtime.SchemeBuilder{
 	appmeshv1beta2.AddToScheme,
 	appmeshv1beta1.AddToScheme,
 	flaggerv1beta1.AddToScheme,
+	gatewayapiv1beta1.AddToScheme,
 	gatewayv1.AddToScheme,
+	helmv2beta1.AddToScheme,
 	networkingv1alpha3.AddToScheme,
+	karmadav1alpha1.AddToScheme,
+	kedav1alpha1.AddToScheme,
+	kruisev1alpha1.AddToScheme,
+	kustomizev1beta2.AddToScheme,
+	policyv1beta2.AddToScheme,
+	monitoringv1.AddToScheme,
 	projectcontourv1.AddToScheme,
+	secretsstorev1.AddToScheme,
 	splitv1alpha1.AddToScheme,
 	splitv1alpha2.AddToScheme,
+	splitv1alpha4.AddToScheme,
+	specsv1alpha4.AddToScheme,
 	traefikv1alpha1.AddToScheme,
 }
 
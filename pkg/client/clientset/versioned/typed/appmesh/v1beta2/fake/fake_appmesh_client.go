@@ -28,6 +28,10 @@ type FakeAppmeshV1beta2 struct {
 	*testing.Fake
 }
 
+func (c *FakeAppmeshV1beta2) GatewayRoutes(namespace string) v1beta2.GatewayRouteInterface {
+	return &FakeGatewayRoutes{c, namespace}
+}
+
 func (c *FakeAppmeshV1beta2) VirtualNodes(namespace string) v1beta2.VirtualNodeInterface {
 	return &FakeVirtualNodes{c, namespace}
 }
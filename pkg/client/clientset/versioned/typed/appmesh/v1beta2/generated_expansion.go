@@ -18,6 +18,8 @@ limitations under the License.
 
 package v1beta2
 
+type GatewayRouteExpansion interface{}
+
 type VirtualNodeExpansion interface{}
 
 type VirtualRouterExpansion interface{}
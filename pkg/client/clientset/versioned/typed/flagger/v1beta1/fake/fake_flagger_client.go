@@ -36,6 +36,14 @@ func (c *FakeFlaggerV1beta1) Canaries(namespace string) v1beta1.CanaryInterface
 	return &FakeCanaries{c, namespace}
 }
 
+func (c *FakeFlaggerV1beta1) CanaryClasses() v1beta1.CanaryClassInterface {
+	return &FakeCanaryClasses{c}
+}
+
+func (c *FakeFlaggerV1beta1) CanaryWaves(namespace string) v1beta1.CanaryWaveInterface {
+	return &FakeCanaryWaves{c, namespace}
+}
+
 func (c *FakeFlaggerV1beta1) MetricTemplates(namespace string) v1beta1.MetricTemplateInterface {
 	return &FakeMetricTemplates{c, namespace}
 }
@@ -28,6 +28,8 @@ type FlaggerV1beta1Interface interface {
 	RESTClient() rest.Interface
 	AlertProvidersGetter
 	CanariesGetter
+	CanaryClassesGetter
+	CanaryWavesGetter
 	MetricTemplatesGetter
 }
 
@@ -44,6 +46,14 @@ func (c *FlaggerV1beta1Client) Canaries(namespace string) CanaryInterface {
 	return newCanaries(c, namespace)
 }
 
+func (c *FlaggerV1beta1Client) CanaryClasses() CanaryClassInterface {
+	return newCanaryClasses(c)
+}
+
+func (c *FlaggerV1beta1Client) CanaryWaves(namespace string) CanaryWaveInterface {
+	return newCanaryWaves(c, namespace)
+}
+
 func (c *FlaggerV1beta1Client) MetricTemplates(namespace string) MetricTemplateInterface {
 	return newMetricTemplates(c, namespace)
 }
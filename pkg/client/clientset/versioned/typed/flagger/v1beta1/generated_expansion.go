@@ -22,4 +22,8 @@ type AlertProviderExpansion interface{}
 
 type CanaryExpansion interface{}
 
+type CanaryClassExpansion interface{}
+
+type CanaryWaveExpansion interface{}
+
 type MetricTemplateExpansion interface{}
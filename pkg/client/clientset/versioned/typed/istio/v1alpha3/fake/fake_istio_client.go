@@ -32,6 +32,14 @@ func (c *FakeNetworkingV1alpha3) DestinationRules(namespace string) v1alpha3.Des
 	return &FakeDestinationRules{c, namespace}
 }
 
+func (c *FakeNetworkingV1alpha3) EnvoyFilters(namespace string) v1alpha3.EnvoyFilterInterface {
+	return &FakeEnvoyFilters{c, namespace}
+}
+
+func (c *FakeNetworkingV1alpha3) Sidecars(namespace string) v1alpha3.SidecarInterface {
+	return &FakeSidecars{c, namespace}
+}
+
 func (c *FakeNetworkingV1alpha3) VirtualServices(namespace string) v1alpha3.VirtualServiceInterface {
 	return &FakeVirtualServices{c, namespace}
 }
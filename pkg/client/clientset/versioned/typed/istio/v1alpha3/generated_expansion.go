@@ -20,4 +20,8 @@ package v1alpha3
 
 type DestinationRuleExpansion interface{}
 
+type EnvoyFilterExpansion interface{}
+
+type SidecarExpansion interface{}
+
 type VirtualServiceExpansion interface{}
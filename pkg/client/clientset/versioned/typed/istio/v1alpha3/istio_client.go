@@ -27,6 +27,8 @@ import (
 type NetworkingV1alpha3Interface interface {
 	RESTClient() rest.Interface
 	DestinationRulesGetter
+	EnvoyFiltersGetter
+	SidecarsGetter
 	VirtualServicesGetter
 }
 
@@ -39,6 +41,14 @@ func (c *NetworkingV1alpha3Client) DestinationRules(namespace string) Destinatio
 	return newDestinationRules(c, namespace)
 }
 
+func (c *NetworkingV1alpha3Client) EnvoyFilters(namespace string) EnvoyFilterInterface {
+	return newEnvoyFilters(c, namespace)
+}
+
+func (c *NetworkingV1alpha3Client) Sidecars(namespace string) SidecarInterface {
+	return newSidecars(c, namespace)
+}
+
 func (c *NetworkingV1alpha3Client) VirtualServices(namespace string) VirtualServiceInterface {
 	return newVirtualServices(c, namespace)
 }
@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// GatewayRoutes returns a GatewayRouteInformer.
+	GatewayRoutes() GatewayRouteInformer
 	// VirtualNodes returns a VirtualNodeInformer.
 	VirtualNodes() VirtualNodeInformer
 	// VirtualRouters returns a VirtualRouterInformer.
@@ -43,6 +45,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// GatewayRoutes returns a GatewayRouteInformer.
+func (v *version) GatewayRoutes() GatewayRouteInformer {
+	return &gatewayRouteInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // VirtualNodes returns a VirtualNodeInformer.
 func (v *version) VirtualNodes() VirtualNodeInformer {
 	return &virtualNodeInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
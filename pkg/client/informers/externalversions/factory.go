@@ -26,11 +26,21 @@ import (
 	versioned "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 	appmesh "github.com/fluxcd/flagger/pkg/client/informers/externalversions/appmesh"
 	flagger "github.com/fluxcd/flagger/pkg/client/informers/externalversions/flagger"
+	gatewayapi "github.com/fluxcd/flagger/pkg/client/informers/externalversions/gatewayapi"
 	gloo "github.com/fluxcd/flagger/pkg/client/informers/externalversions/gloo"
+	helm "github.com/fluxcd/flagger/pkg/client/informers/externalversions/helm"
 	internalinterfaces "github.com/fluxcd/flagger/pkg/client/informers/externalversions/internalinterfaces"
 	istio "github.com/fluxcd/flagger/pkg/client/informers/externalversions/istio"
+	karmada "github.com/fluxcd/flagger/pkg/client/informers/externalversions/karmada"
+	keda "github.com/fluxcd/flagger/pkg/client/informers/externalversions/keda"
+	kruise "github.com/fluxcd/flagger/pkg/client/informers/externalversions/kruise"
+	kustomize "github.com/fluxcd/flagger/pkg/client/informers/externalversions/kustomize"
+	linkerd "github.com/fluxcd/flagger/pkg/client/informers/externalversions/linkerd"
+	monitoring "github.com/fluxcd/flagger/pkg/client/informers/externalversions/monitoring"
 	projectcontour "github.com/fluxcd/flagger/pkg/client/informers/externalversions/projectcontour"
+	secretsstore "github.com/fluxcd/flagger/pkg/client/informers/externalversions/secretsstore"
 	smi "github.com/fluxcd/flagger/pkg/client/informers/externalversions/smi"
+	smispecs "github.com/fluxcd/flagger/pkg/client/informers/externalversions/smispecs"
 	traefik "github.com/fluxcd/flagger/pkg/client/informers/externalversions/traefik"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -180,10 +190,20 @@ type SharedInformerFactory interface {
 
 	Appmesh() appmesh.Interface
 	Flagger() flagger.Interface
+	GatewayAPI() gatewayapi.Interface
 	Gateway() gloo.Interface
+	Helm() helm.Interface
 	Networking() istio.Interface
+	Karmada() karmada.Interface
+	Keda() keda.Interface
+	Kruise() kruise.Interface
+	Kustomize() kustomize.Interface
+	Policy() linkerd.Interface
+	Monitoring() monitoring.Interface
 	Projectcontour() projectcontour.Interface
+	SecretsStore() secretsstore.Interface
 	Split() smi.Interface
+	Specs() smispecs.Interface
 	Traefik() traefik.Interface
 }
 
@@ -195,22 +215,62 @@ func (f *sharedInformerFactory) Flagger() flagger.Interface {
 	return flagger.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) GatewayAPI() gatewayapi.Interface {
+	return gatewayapi.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Gateway() gloo.Interface {
 	return gloo.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Helm() helm.Interface {
+	return helm.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Networking() istio.Interface {
 	return istio.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Karmada() karmada.Interface {
+	return karmada.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Keda() keda.Interface {
+	return keda.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Kruise() kruise.Interface {
+	return kruise.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Kustomize() kustomize.Interface {
+	return kustomize.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Policy() linkerd.Interface {
+	return linkerd.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Monitoring() monitoring.Interface {
+	return monitoring.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Projectcontour() projectcontour.Interface {
 	return projectcontour.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) SecretsStore() secretsstore.Interface {
+	return secretsstore.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Split() smi.Interface {
 	return smi.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Specs() smispecs.Interface {
+	return smispecs.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Traefik() traefik.Interface {
 	return traefik.New(f, f.namespace, f.tweakListOptions)
 }
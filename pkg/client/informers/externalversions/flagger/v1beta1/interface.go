@@ -28,6 +28,10 @@ type Interface interface {
 	AlertProviders() AlertProviderInformer
 	// Canaries returns a CanaryInformer.
 	Canaries() CanaryInformer
+	// CanaryClasses returns a CanaryClassInformer.
+	CanaryClasses() CanaryClassInformer
+	// CanaryWaves returns a CanaryWaveInformer.
+	CanaryWaves() CanaryWaveInformer
 	// MetricTemplates returns a MetricTemplateInformer.
 	MetricTemplates() MetricTemplateInformer
 }
@@ -53,6 +57,16 @@ func (v *version) Canaries() CanaryInformer {
 	return &canaryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CanaryClasses returns a CanaryClassInformer.
+func (v *version) CanaryClasses() CanaryClassInformer {
+	return &canaryClassInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// CanaryWaves returns a CanaryWaveInformer.
+func (v *version) CanaryWaves() CanaryWaveInformer {
+	return &canaryWaveInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // MetricTemplates returns a MetricTemplateInformer.
 func (v *version) MetricTemplates() MetricTemplateInformer {
 	return &metricTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
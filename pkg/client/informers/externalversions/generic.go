@@ -24,11 +24,22 @@ import (
 	v1beta1 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta1"
 	v1beta2 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta2"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	gatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1beta1"
 	v1 "github.com/fluxcd/flagger/pkg/apis/gloo/v1"
+	v2beta1 "github.com/fluxcd/flagger/pkg/apis/helm/v2beta1"
 	v1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	karmadav1alpha1 "github.com/fluxcd/flagger/pkg/apis/karmada/v1alpha1"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+	v1alpha1 "github.com/fluxcd/flagger/pkg/apis/kruise/v1alpha1"
+	kustomizev1beta2 "github.com/fluxcd/flagger/pkg/apis/kustomize/v1beta2"
+	linkerdv1beta2 "github.com/fluxcd/flagger/pkg/apis/linkerd/v1beta2"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/apis/monitoring/v1"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/apis/projectcontour/v1"
-	v1alpha1 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha1"
+	secretsstorev1 "github.com/fluxcd/flagger/pkg/apis/secretsstore/v1"
+	smiv1alpha1 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha1"
 	v1alpha2 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha2"
+	smiv1alpha4 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha4"
+	v1alpha4 "github.com/fluxcd/flagger/pkg/apis/smispecs/v1alpha4"
 	traefikv1alpha1 "github.com/fluxcd/flagger/pkg/apis/traefik/v1alpha1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
@@ -69,6 +80,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta1().VirtualServices().Informer()}, nil
 
 		// Group=appmesh.k8s.aws, Version=v1beta2
+	case v1beta2.SchemeGroupVersion.WithResource("gatewayroutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta2().GatewayRoutes().Informer()}, nil
 	case v1beta2.SchemeGroupVersion.WithResource("virtualnodes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta2().VirtualNodes().Informer()}, nil
 	case v1beta2.SchemeGroupVersion.WithResource("virtualrouters"):
@@ -76,36 +89,92 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1beta2.SchemeGroupVersion.WithResource("virtualservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta2().VirtualServices().Informer()}, nil
 
+		// Group=apps.kruise.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("daemonsets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kruise().V1alpha1().DaemonSets().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("sidecarsets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kruise().V1alpha1().SidecarSets().Informer()}, nil
+
 		// Group=flagger.app, Version=v1beta1
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("alertproviders"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().AlertProviders().Informer()}, nil
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("canaries"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().Canaries().Informer()}, nil
+	case flaggerv1beta1.SchemeGroupVersion.WithResource("canaryclasses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().CanaryClasses().Informer()}, nil
+	case flaggerv1beta1.SchemeGroupVersion.WithResource("canarywaves"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().CanaryWaves().Informer()}, nil
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("metrictemplates"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().MetricTemplates().Informer()}, nil
 
+		// Group=gateway.networking.k8s.io, Version=v1beta1
+	case gatewayapiv1beta1.SchemeGroupVersion.WithResource("httproutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.GatewayAPI().V1beta1().HTTPRoutes().Informer()}, nil
+
 		// Group=gateway.solo.io, Version=v1
 	case v1.SchemeGroupVersion.WithResource("routetables"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Gateway().V1().RouteTables().Informer()}, nil
 
+		// Group=helm.toolkit.fluxcd.io, Version=v2beta1
+	case v2beta1.SchemeGroupVersion.WithResource("helmreleases"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Helm().V2beta1().HelmReleases().Informer()}, nil
+
+		// Group=keda.sh, Version=v1alpha1
+	case kedav1alpha1.SchemeGroupVersion.WithResource("scaledobjects"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Keda().V1alpha1().ScaledObjects().Informer()}, nil
+
+		// Group=kustomize.toolkit.fluxcd.io, Version=v1beta2
+	case kustomizev1beta2.SchemeGroupVersion.WithResource("kustomizations"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kustomize().V1beta2().Kustomizations().Informer()}, nil
+
+		// Group=monitoring.coreos.com, Version=v1
+	case monitoringv1.SchemeGroupVersion.WithResource("servicemonitors"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Monitoring().V1().ServiceMonitors().Informer()}, nil
+
 		// Group=networking.istio.io, Version=v1alpha3
 	case v1alpha3.SchemeGroupVersion.WithResource("destinationrules"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha3().DestinationRules().Informer()}, nil
+	case v1alpha3.SchemeGroupVersion.WithResource("envoyfilters"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha3().EnvoyFilters().Informer()}, nil
+	case v1alpha3.SchemeGroupVersion.WithResource("sidecars"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha3().Sidecars().Informer()}, nil
 	case v1alpha3.SchemeGroupVersion.WithResource("virtualservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha3().VirtualServices().Informer()}, nil
 
+		// Group=policy.karmada.io, Version=v1alpha1
+	case karmadav1alpha1.SchemeGroupVersion.WithResource("overridepolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Karmada().V1alpha1().OverridePolicies().Informer()}, nil
+	case karmadav1alpha1.SchemeGroupVersion.WithResource("propagationpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Karmada().V1alpha1().PropagationPolicies().Informer()}, nil
+
+		// Group=policy.linkerd.io, Version=v1beta2
+	case linkerdv1beta2.SchemeGroupVersion.WithResource("httproutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Policy().V1beta2().HTTPRoutes().Informer()}, nil
+
 		// Group=projectcontour.io, Version=v1
 	case projectcontourv1.SchemeGroupVersion.WithResource("httpproxies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Projectcontour().V1().HTTPProxies().Informer()}, nil
 
+		// Group=secrets-store.csi.x-k8s.io, Version=v1
+	case secretsstorev1.SchemeGroupVersion.WithResource("secretproviderclasses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.SecretsStore().V1().SecretProviderClasses().Informer()}, nil
+
+		// Group=specs.smi-spec.io, Version=v1alpha4
+	case v1alpha4.SchemeGroupVersion.WithResource("httproutegroups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Specs().V1alpha4().HTTPRouteGroups().Informer()}, nil
+
 		// Group=split.smi-spec.io, Version=v1alpha1
-	case v1alpha1.SchemeGroupVersion.WithResource("trafficsplits"):
+	case smiv1alpha1.SchemeGroupVersion.WithResource("trafficsplits"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha1().TrafficSplits().Informer()}, nil
 
 		// Group=split.smi-spec.io, Version=v1alpha2
 	case v1alpha2.SchemeGroupVersion.WithResource("trafficsplits"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha2().TrafficSplits().Informer()}, nil
 
+		// Group=split.smi-spec.io, Version=v1alpha4
+	case smiv1alpha4.SchemeGroupVersion.WithResource("trafficsplits"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha4().TrafficSplits().Informer()}, nil
+
 		// Group=traefik.containo.us, Version=v1alpha1
 	case traefikv1alpha1.SchemeGroupVersion.WithResource("traefikservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Traefik().V1alpha1().TraefikServices().Informer()}, nil
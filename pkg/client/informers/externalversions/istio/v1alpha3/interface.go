@@ -26,6 +26,10 @@ import (
 type Interface interface {
 	// DestinationRules returns a DestinationRuleInformer.
 	DestinationRules() DestinationRuleInformer
+	// EnvoyFilters returns a EnvoyFilterInformer.
+	EnvoyFilters() EnvoyFilterInformer
+	// Sidecars returns a SidecarInformer.
+	Sidecars() SidecarInformer
 	// VirtualServices returns a VirtualServiceInformer.
 	VirtualServices() VirtualServiceInformer
 }
@@ -46,6 +50,16 @@ func (v *version) DestinationRules() DestinationRuleInformer {
 	return &destinationRuleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// EnvoyFilters returns a EnvoyFilterInformer.
+func (v *version) EnvoyFilters() EnvoyFilterInformer {
+	return &envoyFilterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// Sidecars returns a SidecarInformer.
+func (v *version) Sidecars() SidecarInformer {
+	return &sidecarInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // VirtualServices returns a VirtualServiceInformer.
 func (v *version) VirtualServices() VirtualServiceInformer {
 	return &virtualServiceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -22,6 +22,7 @@ import (
 	internalinterfaces "github.com/fluxcd/flagger/pkg/client/informers/externalversions/internalinterfaces"
 	v1alpha1 "github.com/fluxcd/flagger/pkg/client/informers/externalversions/smi/v1alpha1"
 	v1alpha2 "github.com/fluxcd/flagger/pkg/client/informers/externalversions/smi/v1alpha2"
+	v1alpha4 "github.com/fluxcd/flagger/pkg/client/informers/externalversions/smi/v1alpha4"
 )
 
 // Interface provides access to each of this group's versions.
@@ -30,6 +31,8 @@ type Interface interface {
 	V1alpha1() v1alpha1.Interface
 	// V1alpha2 provides access to shared informers for resources in V1alpha2.
 	V1alpha2() v1alpha2.Interface
+	// V1alpha4 provides access to shared informers for resources in V1alpha4.
+	V1alpha4() v1alpha4.Interface
 }
 
 type group struct {
@@ -52,3 +55,8 @@ func (g *group) V1alpha1() v1alpha1.Interface {
 func (g *group) V1alpha2() v1alpha2.Interface {
 	return v1alpha2.New(g.factory, g.namespace, g.tweakListOptions)
 }
+
+// V1alpha4 returns a new v1alpha4.Interface.
+func (g *group) V1alpha4() v1alpha4.Interface {
+	return v1alpha4.New(g.factory, g.namespace, g.tweakListOptions)
+}
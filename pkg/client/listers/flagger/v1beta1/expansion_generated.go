@@ -34,6 +34,18 @@ type CanaryListerExpansion interface{}
 // CanaryNamespaceLister.
 type CanaryNamespaceListerExpansion interface{}
 
+// CanaryClassListerExpansion allows custom methods to be added to
+// CanaryClassLister.
+type CanaryClassListerExpansion interface{}
+
+// CanaryWaveListerExpansion allows custom methods to be added to
+// CanaryWaveLister.
+type CanaryWaveListerExpansion interface{}
+
+// CanaryWaveNamespaceListerExpansion allows custom methods to be added to
+// CanaryWaveNamespaceLister.
+type CanaryWaveNamespaceListerExpansion interface{}
+
 // MetricTemplateListerExpansion allows custom methods to be added to
 // MetricTemplateLister.
 type MetricTemplateListerExpansion interface{}
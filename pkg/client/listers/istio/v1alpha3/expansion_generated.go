@@ -26,6 +26,22 @@ type DestinationRuleListerExpansion interface{}
 // DestinationRuleNamespaceLister.
 type DestinationRuleNamespaceListerExpansion interface{}
 
+// EnvoyFilterListerExpansion allows custom methods to be added to
+// EnvoyFilterLister.
+type EnvoyFilterListerExpansion interface{}
+
+// EnvoyFilterNamespaceListerExpansion allows custom methods to be added to
+// EnvoyFilterNamespaceLister.
+type EnvoyFilterNamespaceListerExpansion interface{}
+
+// SidecarListerExpansion allows custom methods to be added to
+// SidecarLister.
+type SidecarListerExpansion interface{}
+
+// SidecarNamespaceListerExpansion allows custom methods to be added to
+// SidecarNamespaceLister.
+type SidecarNamespaceListerExpansion interface{}
+
 // VirtualServiceListerExpansion allows custom methods to be added to
 // VirtualServiceLister.
 type VirtualServiceListerExpansion interface{}
@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// skipAnnotation requests the scheduler to skip the analysis of the current step
+// or to skip the remaining steps and promote the canary
+const skipAnnotation = "flagger.app/skip"
+
+const (
+	skipStep      = "step"
+	skipPromotion = "promotion"
+)
+
+// abortAnnotation requests the scheduler to roll back the canary
+// regardless of the analysis result
+const abortAnnotation = "flagger.app/abort"
+
+// actionAnnotations are the operator actions that trigger the canary analysis
+// without waiting for the next analysis interval
+var actionAnnotations = []string{skipAnnotation, abortAnnotation, rerunAnnotation}
+
+// hasPendingAction returns true if an operator action annotation is set on the canary
+func hasPendingAction(cd *flaggerv1.Canary) bool {
+	for _, annotation := range actionAnnotations {
+		if _, ok := cd.GetAnnotations()[annotation]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsAction returns true if the operator action can be applied in the current phase,
+// the canary can be aborted in the same phases as with a rollback webhook
+// and the analysis can be run again when the canary is idle
+func acceptsAction(cd *flaggerv1.Canary, annotation string) bool {
+	switch annotation {
+	case rerunAnnotation:
+		return cd.Status.Phase == flaggerv1.CanaryPhaseInitialized ||
+			cd.Status.Phase == flaggerv1.CanaryPhaseSucceeded ||
+			cd.Status.Phase == flaggerv1.CanaryPhaseFailed
+	case abortAnnotation:
+		return cd.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
+			cd.Status.Phase == flaggerv1.CanaryPhaseWaiting ||
+			cd.Status.Phase == flaggerv1.CanaryWaitingPromotion
+	}
+	return cd.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
+		cd.Status.Phase == flaggerv1.CanaryWaitingPromotion
+}
+
+// takeAction removes the action annotation from the canary and returns its value,
+// the canary object is updated in place so that the status updates use the latest version
+func (c *Controller) takeAction(cd *flaggerv1.Canary, annotation string) (string, error) {
+	value, ok := cd.GetAnnotations()[annotation]
+	if !ok {
+		return "", nil
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest, err := c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(context.TODO(), cd.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		cdCopy := latest.DeepCopy()
+		delete(cdCopy.Annotations, annotation)
+		updated, err := c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Update(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		cd.ObjectMeta = updated.ObjectMeta
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("removing annotation %s from canary %s.%s failed: %w", annotation, cd.Name, cd.Namespace, err)
+	}
+	return value, nil
+}
+
+// validAction returns true if the value is supported by the action annotation
+func validAction(annotation string, value string) bool {
+	switch annotation {
+	case skipAnnotation:
+		return value == skipStep || value == skipPromotion
+	case abortAnnotation:
+		return value == "true"
+	case rerunAnnotation:
+		return strings.TrimSpace(value) != ""
+	}
+	return false
+}
+
+// discardActions removes the operator actions that are invalid
+// or that can't be applied in the current phase
+func (c *Controller) discardActions(cd *flaggerv1.Canary) {
+	for _, annotation := range actionAnnotations {
+		value, ok := cd.GetAnnotations()[annotation]
+		if !ok || (acceptsAction(cd, annotation) && validAction(annotation, value)) {
+			continue
+		}
+		if _, err := c.takeAction(cd, annotation); err != nil {
+			c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+			continue
+		}
+		c.recordEventWarningf(cd, flaggerv1.ReasonActionIgnored, "Ignoring %s: %s for %s.%s in phase %s",
+			annotation, value, cd.Name, cd.Namespace, cd.Status.Phase)
+	}
+}
+
+// takeSkipAction consumes the skip annotation when it matches the given value
+func (c *Controller) takeSkipAction(cd *flaggerv1.Canary, value string) bool {
+	if cd.GetAnnotations()[skipAnnotation] != value || !acceptsAction(cd, skipAnnotation) {
+		return false
+	}
+	if _, err := c.takeAction(cd, skipAnnotation); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return false
+	}
+	return true
+}
+
+// takeAbortAction consumes the abort annotation
+func (c *Controller) takeAbortAction(cd *flaggerv1.Canary) bool {
+	if cd.GetAnnotations()[abortAnnotation] != "true" || !acceptsAction(cd, abortAnnotation) {
+		return false
+	}
+	if _, err := c.takeAction(cd, abortAnnotation); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// CanaryState is the live state of a canary exposed by the controller API
+type CanaryState struct {
+	Name               string                `json:"name"`
+	Namespace          string                `json:"namespace"`
+	TargetRef          string                `json:"targetRef"`
+	Phase              flaggerv1.CanaryPhase `json:"phase"`
+	CanaryWeight       int                   `json:"canaryWeight"`
+	Iterations         int                   `json:"iterations"`
+	FailedChecks       int                   `json:"failedChecks"`
+	LastTransitionTime metav1.Time           `json:"lastTransitionTime,omitempty"`
+	Metrics            []MetricValue         `json:"metrics,omitempty"`
+}
+
+// MetricValue is the last value returned by a metric query of the canary analysis
+type MetricValue struct {
+	Name      string    `json:"name"`
+	Value     float64   `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// setMetricValue stores the last value of a canary metric,
+// the metrics map is replaced on every update so that it can be read concurrently
+func (c *Controller) setMetricValue(cd *flaggerv1.Canary, metric string, value float64) {
+	key := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+	values := make(map[string]MetricValue)
+	if v, ok := c.metricValues.Load(key); ok {
+		for name, mv := range v.(map[string]MetricValue) {
+			values[name] = mv
+		}
+	}
+	values[metric] = MetricValue{Name: metric, Value: value, Timestamp: time.Now()}
+	c.metricValues.Store(key, values)
+}
+
+// CanaryStates returns the live state of the canaries from the informer cache,
+// when the namespace is empty the canaries from all namespaces are returned
+func (c *Controller) CanaryStates(namespace string) ([]CanaryState, error) {
+	canaries, err := c.flaggerInformers.CanaryInformer.Lister().Canaries(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("listing canaries failed: %w", err)
+	}
+
+	states := make([]CanaryState, 0, len(canaries))
+	for _, cd := range canaries {
+		state := CanaryState{
+			Name:               cd.Name,
+			Namespace:          cd.Namespace,
+			TargetRef:          fmt.Sprintf("%s/%s", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name),
+			Phase:              cd.Status.Phase,
+			CanaryWeight:       cd.Status.CanaryWeight,
+			Iterations:         cd.Status.Iterations,
+			FailedChecks:       cd.Status.FailedChecks,
+			LastTransitionTime: cd.Status.LastTransitionTime,
+		}
+		if v, ok := c.metricValues.Load(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)); ok {
+			for _, mv := range v.(map[string]MetricValue) {
+				state.Metrics = append(state.Metrics, mv)
+			}
+			sort.Slice(state.Metrics, func(i, j int) bool { return state.Metrics[i].Name < state.Metrics[j].Name })
+		}
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Namespace != states[j].Namespace {
+			return states[i].Namespace < states[j].Namespace
+		}
+		return states[i].Name < states[j].Name
+	})
+	return states, nil
+}
+
+// CanariesHandler serves the live state of the canaries as JSON,
+// the results can be filtered with the namespace and name query parameters
+func (c *Controller) CanariesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		states, err := c.CanaryStates(r.URL.Query().Get("namespace"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if name := r.URL.Query().Get("name"); name != "" {
+			filtered := make([]CanaryState, 0, 1)
+			for _, state := range states {
+				if state.Name == name {
+					filtered = append(filtered, state)
+				}
+			}
+			states = filtered
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(states); err != nil {
+			c.logger.Errorf("encoding canaries state failed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_CanariesHandler(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.setMetricValue(mocks.canary, "request-success-rate", 99.5)
+	mocks.ctrl.setMetricValue(mocks.canary, "request-duration", 120)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/canaries?namespace=default", nil)
+	rec := httptest.NewRecorder()
+	mocks.ctrl.CanariesHandler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var states []CanaryState
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &states))
+	require.Len(t, states, 1)
+	assert.Equal(t, mocks.canary.Name, states[0].Name)
+	assert.Equal(t, "Deployment/podinfo", states[0].TargetRef)
+	require.Len(t, states[0].Metrics, 2)
+	assert.Equal(t, "request-duration", states[0].Metrics[0].Name)
+	assert.Equal(t, float64(120), states[0].Metrics[0].Value)
+	assert.Equal(t, "request-success-rate", states[0].Metrics[1].Name)
+	assert.Equal(t, 99.5, states[0].Metrics[1].Value)
+
+	// filter by name
+	req = httptest.NewRequest(http.MethodGet, "/api/canaries?name=unknown", nil)
+	rec = httptest.NewRecorder()
+	mocks.ctrl.CanariesHandler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &states))
+	assert.Len(t, states, 0)
+
+	// read-only
+	req = httptest.NewRequest(http.MethodPost, "/api/canaries", nil)
+	rec = httptest.NewRecorder()
+	mocks.ctrl.CanariesHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestController_CanaryStates_Namespace(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	states, err := mocks.ctrl.CanaryStates("other")
+	require.NoError(t, err)
+	assert.Len(t, states, 0)
+
+	states, err = mocks.ctrl.CanaryStates("")
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, flaggerv1.CanaryPhase(""), states[0].Phase)
+}
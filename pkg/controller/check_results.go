@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// checkResults holds the metric and webhook results of the analysis iteration in progress,
+// the results are written to the canary status when the iteration ends
+type checkResults struct {
+	metrics   []flaggerv1.CanaryMetricStatus
+	webhooks  []flaggerv1.CanaryWebhookStatus
+	approvals []flaggerv1.CanaryApproval
+	history   []flaggerv1.CanaryHistoryEntry
+}
+
+func (c *Controller) pendingResults(cd *flaggerv1.Canary) *checkResults {
+	v, _ := c.checkResults.LoadOrStore(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace), &checkResults{})
+	return v.(*checkResults)
+}
+
+// recordMetricResult adds the result of a metric check, the value is nil when the query failed
+func (c *Controller) recordMetricResult(cd *flaggerv1.Canary, metric flaggerv1.CanaryMetric, value *float64, err error) {
+	results := c.pendingResults(cd)
+	results.metrics = append(results.metrics, metricResult(metric, value, err))
+}
+
+// metricResult checks the metric value against the thresholds, the value is nil when the query failed
+func metricResult(metric flaggerv1.CanaryMetric, value *float64, err error) flaggerv1.CanaryMetricStatus {
+	min, max := metricThresholds(metric)
+	result := flaggerv1.CanaryMetricStatus{
+		Name:          metric.Name,
+		Threshold:     formatThreshold(min, max),
+		LastCheckTime: metav1.Now(),
+	}
+	switch {
+	case err != nil:
+		result.Message = err.Error()
+	case value != nil:
+		result.Value = strconv.FormatFloat(*value, 'f', -1, 64)
+		result.Passed = (min == nil || *value >= *min) && (max == nil || *value <= *max)
+		if !result.Passed {
+			result.Message = fmt.Sprintf("value %s is outside the threshold range %s", result.Value, result.Threshold)
+		}
+	}
+	return result
+}
+
+// recordWebhookResult adds the result of a webhook call and the address of the task output if any
+func (c *Controller) recordWebhookResult(cd *flaggerv1.Canary, w flaggerv1.CanaryWebhook, resp *webhookResponse, err error) {
+	result := flaggerv1.CanaryWebhookStatus{
+		Name:         w.Name,
+		Type:         w.Type,
+		Passed:       err == nil,
+		ArtifactURL:  resp.artifactURL(),
+		LastCallTime: metav1.Now(),
+	}
+	if err != nil {
+		result.Message = err.Error()
+	}
+
+	results := c.pendingResults(cd)
+	results.webhooks = append(results.webhooks, result)
+}
+
+// webhookArtifacts returns the addresses of the task outputs uploaded by the webhooks
+// called in the analysis iteration in progress
+func (c *Controller) webhookArtifacts(cd *flaggerv1.Canary) []string {
+	v, ok := c.checkResults.Load(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace))
+	if !ok {
+		return nil
+	}
+	var artifacts []string
+	for _, result := range v.(*checkResults).webhooks {
+		if result.ArtifactURL != "" {
+			artifacts = append(artifacts, fmt.Sprintf("%s: %s", result.Name, result.ArtifactURL))
+		}
+	}
+	return artifacts
+}
+
+// recordApproval adds the approver of a confirm webhook that passed,
+// the approval is recorded once per run so that the first approver is kept
+func (c *Controller) recordApproval(cd *flaggerv1.Canary, w flaggerv1.CanaryWebhook, resp *webhookResponse) {
+	for _, approval := range cd.Status.Approvals {
+		if approval.Webhook == w.Name && approval.RunID == cd.Status.RunID {
+			return
+		}
+	}
+
+	approval := flaggerv1.CanaryApproval{
+		Webhook:      w.Name,
+		Type:         w.Type,
+		Approver:     resp.approver(),
+		Source:       flaggerv1.ApprovalSourceWebhook,
+		RunID:        cd.Status.RunID,
+		ApprovalTime: metav1.Now(),
+	}
+	if resp != nil {
+		approval.Source = resp.approvalSource()
+	}
+	if approval.Approver == "" {
+		approval.Approver = "unknown"
+	}
+
+	results := c.pendingResults(cd)
+	for _, pending := range results.approvals {
+		if pending.Webhook == w.Name {
+			return
+		}
+	}
+	results.approvals = append(results.approvals, approval)
+	c.recordEventInfof(cd, flaggerv1.ReasonApproved, "%s check %s approved by %s (%s)",
+		w.Type, w.Name, approval.Approver, approval.Source)
+}
+
+// syncCheckResults writes the results of the analysis iteration to the canary status,
+// the results replace those of the metrics and webhooks with the same name
+// and the significant events are appended to the history
+func (c *Controller) syncCheckResults(name string, namespace string) {
+	v, ok := c.checkResults.LoadAndDelete(fmt.Sprintf("%s.%s", name, namespace))
+	if !ok {
+		return
+	}
+	results := v.(*checkResults)
+	if len(results.metrics) == 0 && len(results.webhooks) == 0 && len(results.approvals) == 0 && len(results.history) == 0 {
+		return
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("canary %s.%s get query failed: %w", name, namespace, err)
+		}
+
+		cdCopy := cd.DeepCopy()
+		for _, result := range results.metrics {
+			cdCopy.Status.Metrics = setMetricStatus(cdCopy.Status.Metrics, result)
+		}
+		for _, result := range results.webhooks {
+			cdCopy.Status.Webhooks = setWebhookStatus(cdCopy.Status.Webhooks, result)
+		}
+		for _, approval := range results.approvals {
+			cdCopy.Status.Approvals = setApprovalStatus(cdCopy.Status.Approvals, approval)
+		}
+		if len(results.history) > 0 {
+			cdCopy.Status.History = appendHistory(cdCopy.Status.History, results.history, c.statusHistoryLimit)
+		}
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(namespace).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
+			Errorf("Updating the check results failed: %v", err)
+	}
+}
+
+// metricThresholds returns the accepted range of a metric,
+// the deprecated threshold is the minimum success rate or the maximum value of the other metrics
+func metricThresholds(metric flaggerv1.CanaryMetric) (*float64, *float64) {
+	if metric.ThresholdRange != nil {
+		return metric.ThresholdRange.Min, metric.ThresholdRange.Max
+	}
+	threshold := metric.Threshold
+	if metric.Name == "request-success-rate" && metric.TemplateRef == nil {
+		return &threshold, nil
+	}
+	return nil, &threshold
+}
+
+func formatThreshold(min *float64, max *float64) string {
+	var parts []string
+	if min != nil {
+		parts = append(parts, ">= "+strconv.FormatFloat(*min, 'f', -1, 64))
+	}
+	if max != nil {
+		parts = append(parts, "<= "+strconv.FormatFloat(*max, 'f', -1, 64))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func setMetricStatus(metrics []flaggerv1.CanaryMetricStatus, result flaggerv1.CanaryMetricStatus) []flaggerv1.CanaryMetricStatus {
+	for i := range metrics {
+		if metrics[i].Name == result.Name {
+			metrics[i] = result
+			return metrics
+		}
+	}
+	return append(metrics, result)
+}
+
+func setWebhookStatus(webhooks []flaggerv1.CanaryWebhookStatus, result flaggerv1.CanaryWebhookStatus) []flaggerv1.CanaryWebhookStatus {
+	for i := range webhooks {
+		if webhooks[i].Name == result.Name {
+			webhooks[i] = result
+			return webhooks
+		}
+	}
+	return append(webhooks, result)
+}
+
+// setApprovalStatus adds the approval and removes those of the previous runs,
+// an approval of the same webhook in the same run is kept
+func setApprovalStatus(approvals []flaggerv1.CanaryApproval, approval flaggerv1.CanaryApproval) []flaggerv1.CanaryApproval {
+	current := make([]flaggerv1.CanaryApproval, 0, len(approvals)+1)
+	for _, a := range approvals {
+		if a.RunID != approval.RunID {
+			continue
+		}
+		if a.Webhook == approval.Webhook {
+			approval = a
+			continue
+		}
+		current = append(current, a)
+	}
+	return append(current, approval)
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_recordMetricResult(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	val := float64(98)
+	mocks.ctrl.recordMetricResult(mocks.canary, flaggerv1.CanaryMetric{Name: "request-success-rate", Threshold: 99}, &val, nil)
+	mocks.ctrl.recordMetricResult(mocks.canary, flaggerv1.CanaryMetric{
+		Name:           "latency",
+		ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: toFloatPtr(0), Max: toFloatPtr(500)},
+	}, &val, nil)
+	mocks.ctrl.recordMetricResult(mocks.canary, flaggerv1.CanaryMetric{Name: "errors", Threshold: 5}, nil, errors.New("no values found"))
+	mocks.ctrl.recordWebhookResult(mocks.canary, flaggerv1.CanaryWebhook{Name: "load-test", Type: flaggerv1.RolloutHook}, nil, nil)
+	mocks.ctrl.syncCheckResults("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.Status.Metrics, 3)
+
+	assert.Equal(t, "98", c.Status.Metrics[0].Value)
+	assert.Equal(t, ">= 99", c.Status.Metrics[0].Threshold)
+	assert.False(t, c.Status.Metrics[0].Passed)
+
+	assert.Equal(t, ">= 0, <= 500", c.Status.Metrics[1].Threshold)
+	assert.True(t, c.Status.Metrics[1].Passed)
+
+	assert.Empty(t, c.Status.Metrics[2].Value)
+	assert.Equal(t, "<= 5", c.Status.Metrics[2].Threshold)
+	assert.False(t, c.Status.Metrics[2].Passed)
+	assert.Equal(t, "no values found", c.Status.Metrics[2].Message)
+
+	require.Len(t, c.Status.Webhooks, 1)
+	assert.True(t, c.Status.Webhooks[0].Passed)
+
+	// the next results replace the previous ones
+	val = 99.5
+	mocks.ctrl.recordMetricResult(mocks.canary, flaggerv1.CanaryMetric{Name: "request-success-rate", Threshold: 99}, &val, nil)
+	mocks.ctrl.syncCheckResults("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.Status.Metrics, 3)
+	assert.Equal(t, "99.5", c.Status.Metrics[0].Value)
+	assert.True(t, c.Status.Metrics[0].Passed)
+}
+
+func TestScheduler_DeploymentCheckResults(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// run the checks and advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.Status.Metrics, 3)
+	for _, metric := range c.Status.Metrics {
+		assert.True(t, metric.Passed, metric.Name)
+		assert.NotEmpty(t, metric.Value, metric.Name)
+	}
+	assert.Equal(t, 20, c.Status.CanaryWeight)
+
+	// a new analysis discards the results
+	dep2.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:3.0.0"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, c.Status.Metrics)
+}
+
+func TestController_recordApproval(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(flaggerv1.ApproverHeader, "alice")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	mocks.canary.Status.RunID = "run-1"
+	hook := flaggerv1.CanaryWebhook{Name: "approval", Type: flaggerv1.ConfirmPromotionHook, URL: ts.URL}
+
+	resp, err := mocks.ctrl.callWebhookResponse(mocks.canary, flaggerv1.CanaryPhaseProgressing, hook)
+	require.NoError(t, err)
+	mocks.ctrl.recordApproval(mocks.canary, hook, resp)
+	mocks.ctrl.syncCheckResults("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.Status.Approvals, 1)
+	assert.Equal(t, "alice", c.Status.Approvals[0].Approver)
+	assert.Equal(t, flaggerv1.ApprovalSourceWebhook, c.Status.Approvals[0].Source)
+	assert.Equal(t, "run-1", c.Status.Approvals[0].RunID)
+
+	// the first approver of the run is kept
+	c.Status.RunID = "run-1"
+	mocks.ctrl.recordApproval(c, hook, &webhookResponse{body: []byte(`{"approver": "bob"}`)})
+	mocks.ctrl.syncCheckResults("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.Status.Approvals, 1)
+	assert.Equal(t, "alice", c.Status.Approvals[0].Approver)
+
+	// the approvals of the previous runs are replaced
+	c.Status.RunID = "run-2"
+	mocks.ctrl.recordApproval(c, hook, &webhookResponse{body: []byte(`{"approver": "bob"}`)})
+	mocks.ctrl.syncCheckResults("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.Status.Approvals, 1)
+	assert.Equal(t, "bob", c.Status.Approvals[0].Approver)
+	assert.Equal(t, "run-2", c.Status.Approvals[0].RunID)
+}
+
+func TestController_recordWebhookArtifact(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(flaggerv1.ArtifactURLHeader, "https://reports.example.com/run-1.log")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("error ratio 0.2 above 0.1"))
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	hook := flaggerv1.CanaryWebhook{Name: "load-test", Type: flaggerv1.RolloutHook, URL: ts.URL}
+
+	err := mocks.ctrl.callWebhook(mocks.canary, flaggerv1.CanaryPhaseProgressing, hook)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "https://reports.example.com/run-1.log")
+	assert.Equal(t, []string{"load-test: https://reports.example.com/run-1.log"}, mocks.ctrl.webhookArtifacts(mocks.canary))
+	mocks.ctrl.syncCheckResults("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.Status.Webhooks, 1)
+	assert.False(t, c.Status.Webhooks[0].Passed)
+	assert.Equal(t, "https://reports.example.com/run-1.log", c.Status.Webhooks[0].ArtifactURL)
+}
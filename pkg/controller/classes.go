@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// withClassDefaults returns a copy of the canary with the defaults of its class,
+// the canary is returned as is when it doesn't reference a class
+func (c *Controller) withClassDefaults(cd *flaggerv1.Canary) (*flaggerv1.Canary, error) {
+	if cd.Spec.ClassName == "" {
+		return cd, nil
+	}
+	class, err := c.flaggerInformers.ClassInformer.Lister().Get(cd.Spec.ClassName)
+	if err != nil {
+		return nil, fmt.Errorf("canary class %s of %s.%s not found: %w", cd.Spec.ClassName, cd.Name, cd.Namespace, err)
+	}
+	return class.ApplyTo(cd), nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newTestCanaryClass() *flaggerv1.CanaryClass {
+	return &flaggerv1.CanaryClass{
+		TypeMeta:   metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+		Spec: flaggerv1.CanaryClassSpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Interval:   "1m",
+				Threshold:  5,
+				StepWeight: 20,
+				MaxWeight:  60,
+				Metrics: []flaggerv1.CanaryMetric{
+					{
+						Name:      "request-success-rate",
+						Threshold: 99,
+						Interval:  "1m",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestScheduler_CanaryClass(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.ClassName = "standard"
+	cd.Spec.Analysis = nil
+	mocks := newDeploymentFixture(cd)
+	require.NoError(t, mocks.ctrl.flaggerInformers.ClassInformer.Informer().GetIndexer().Add(newTestCanaryClass()))
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance with the step weight of the class
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, 20, c.Status.CanaryWeight)
+}
+
+func TestScheduler_CanaryClassNotFound(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.ClassName = "standard"
+	cd.Spec.Analysis = nil
+	mocks := newDeploymentFixture(cd)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, c.Status.Phase)
+}
+
+func TestCanaryClass_ApplyReadyThresholds(t *testing.T) {
+	primaryThreshold, canaryThreshold := 80, 50
+	class := newTestCanaryClass()
+	class.Spec.Analysis.PrimaryReadyThreshold = &primaryThreshold
+	class.Spec.Analysis.CanaryReadyThreshold = &canaryThreshold
+
+	cd := newDeploymentTestCanary()
+	overridden := 90
+	cd.Spec.Analysis.CanaryReadyThreshold = &overridden
+
+	res := class.ApplyTo(cd)
+	assert.Equal(t, 80, res.GetAnalysisPrimaryReadyThreshold())
+	assert.Equal(t, 90, res.GetAnalysisCanaryReadyThreshold())
+
+	// the defaults of the class aren't shared with the canary
+	*res.Spec.Analysis.PrimaryReadyThreshold = 70
+	assert.Equal(t, 80, *class.Spec.Analysis.PrimaryReadyThreshold)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+	"github.com/fluxcd/flagger/pkg/router"
+)
+
+// remoteClient is the client of a remote cluster built from a kubeconfig secret
+type remoteClient struct {
+	resourceVersion string
+	client          kubernetes.Interface
+}
+
+// newRemoteClient builds the client of a remote cluster from a kubeconfig
+func newRemoteClient(kubeconfig []byte) (kubernetes.Interface, error) {
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(cfg)
+}
+
+// remoteClusterClient returns the client of a remote cluster,
+// the client is rebuilt when the kubeconfig secret changes
+func (c *Controller) remoteClusterClient(cd *flaggerv1.Canary, cluster flaggerv1.CanaryRemoteCluster) (kubernetes.Interface, error) {
+	secret, err := c.kubeClient.CoreV1().Secrets(cd.Namespace).Get(context.TODO(), cluster.SecretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cluster %s secret %s.%s get query error: %w", cluster.Name, cluster.SecretRef.Name, cd.Namespace, err)
+	}
+
+	key := fmt.Sprintf("%s/%s", cd.Namespace, cluster.SecretRef.Name)
+	if v, ok := c.remoteClients.Load(key); ok && v.(remoteClient).resourceVersion == secret.ResourceVersion {
+		return v.(remoteClient).client, nil
+	}
+
+	kubeconfig, ok := secret.Data["kubeconfig"]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s secret %s does not contain a kubeconfig", cluster.Name, cluster.SecretRef.Name)
+	}
+	build := c.newRemoteClient
+	if build == nil {
+		build = newRemoteClient
+	}
+	client, err := build(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("cluster %s kubeconfig error: %w", cluster.Name, err)
+	}
+	c.remoteClients.Store(key, remoteClient{resourceVersion: secret.ResourceVersion, client: client})
+	return client, nil
+}
+
+// targetController returns the canary controller of the cluster that runs the target workload,
+// the workload is in the local cluster unless the canary has a target cluster
+func (c *Controller) targetController(cd *flaggerv1.Canary) (canary.Controller, error) {
+	if cd.Spec.TargetCluster == nil {
+		return c.canaryFactory.CanaryController(cd), nil
+	}
+
+	client, err := c.remoteClusterClient(cd, *cd.Spec.TargetCluster)
+	if err != nil {
+		return nil, err
+	}
+	return c.canaryFactory.ForCluster(client).CanaryController(cd), nil
+}
+
+// targetRouter returns the Kubernetes router of the cluster that runs the target workload,
+// with a target cluster the ClusterIP services are also generated in the local cluster
+// so that the routing objects can reference them
+func (c *Controller) targetRouter(cd *flaggerv1.Canary,
+	labelSelector string, labelValue string, ports map[string]int32) (router.KubernetesRouter, error) {
+	kubeRouter := c.routerFactory.KubernetesRouter(cd.GetTargetKind(), labelSelector, labelValue, ports)
+	if cd.Spec.TargetCluster == nil {
+		return kubeRouter, nil
+	}
+
+	client, err := c.remoteClusterClient(cd, *cd.Spec.TargetCluster)
+	if err != nil {
+		return nil, err
+	}
+	targetRouter := c.routerFactory.ForCluster(client).KubernetesRouter(cd.GetTargetKind(), labelSelector, labelValue, ports)
+	return router.NewMultiClusterKubernetesRouter(targetRouter, []router.RemoteKubernetesRouter{
+		{Cluster: "local", Router: kubeRouter},
+	}), nil
+}
+
+// multiClusterController wraps the canary controller so that the workloads
+// of the remote clusters are managed together with the local ones
+func (c *Controller) multiClusterController(cd *flaggerv1.Canary, canaryController canary.Controller) (canary.Controller, error) {
+	if len(cd.Spec.RemoteClusters) == 0 {
+		return canaryController, nil
+	}
+
+	remotes := make([]canary.RemoteController, 0, len(cd.Spec.RemoteClusters))
+	for _, cluster := range cd.Spec.RemoteClusters {
+		client, err := c.remoteClusterClient(cd, cluster)
+		if err != nil {
+			return nil, err
+		}
+		remotes = append(remotes, canary.RemoteController{
+			Cluster:    cluster.Name,
+			Controller: c.canaryFactory.ForCluster(client).CanaryController(cd),
+		})
+	}
+	return canary.NewMultiClusterController(canaryController, remotes), nil
+}
+
+// multiClusterRouter wraps the Kubernetes router so that the ClusterIP services
+// of the remote clusters are managed together with the local ones
+func (c *Controller) multiClusterRouter(cd *flaggerv1.Canary, kubeRouter router.KubernetesRouter,
+	labelSelector string, labelValue string, ports map[string]int32) (router.KubernetesRouter, error) {
+	if len(cd.Spec.RemoteClusters) == 0 {
+		return kubeRouter, nil
+	}
+
+	remotes := make([]router.RemoteKubernetesRouter, 0, len(cd.Spec.RemoteClusters))
+	for _, cluster := range cd.Spec.RemoteClusters {
+		client, err := c.remoteClusterClient(cd, cluster)
+		if err != nil {
+			return nil, err
+		}
+		remotes = append(remotes, router.RemoteKubernetesRouter{
+			Cluster: cluster.Name,
+			Router:  c.routerFactory.ForCluster(client).KubernetesRouter(cd.GetTargetKind(), labelSelector, labelValue, ports),
+		})
+	}
+	return router.NewMultiClusterKubernetesRouter(kubeRouter, remotes), nil
+}
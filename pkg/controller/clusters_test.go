@@ -0,0 +1,183 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_DeploymentRemoteClusters(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	canary.Spec.RemoteClusters = []flaggerv1.CanaryRemoteCluster{{
+		Name:      "west",
+		SecretRef: corev1.LocalObjectReference{Name: "west-kubeconfig"},
+	}}
+	mocks := newDeploymentFixture(canary)
+
+	remote := fake.NewSimpleClientset(
+		newDeploymentTestDeployment(),
+		newDeploymentTestService(),
+		newDeploymentTestHPA(),
+		newDeploymentTestConfigMap(),
+		newDeploymentTestConfigMapEnv(),
+		newDeploymentTestConfigMapVol(),
+		newDeploymentTestSecret(),
+		newDeploymentTestSecretEnv(),
+		newDeploymentTestSecretVol(),
+	)
+	builds := 0
+	mocks.ctrl.newRemoteClient = func(kubeconfig []byte) (kubernetes.Interface, error) {
+		builds++
+		assert.Equal(t, "kind: Config", string(kubeconfig))
+		return remote, nil
+	}
+	_, err := mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "west-kubeconfig"},
+		Data:       map[string][]byte{"kubeconfig": []byte("kind: Config")},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the primary workload and the services are created in the remote cluster
+	_, err = remote.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	for _, name := range []string{"podinfo", "podinfo-primary", "podinfo-canary"} {
+		_, err = remote.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+	}
+
+	// the canary waits for the remote primary
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, flaggerv1.CanaryPhaseInitialized, c.Status.Phase)
+
+	p, err := remote.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	p.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1, AvailableReplicas: 1}
+	_, err = remote.AppsV1().Deployments("default").Update(context.TODO(), p, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseInitialized, c.Status.Phase)
+
+	// the remote target is scaled to zero like the local one
+	d, err := remote.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *d.Spec.Replicas)
+
+	// the client is cached until the kubeconfig changes
+	assert.Equal(t, 1, builds)
+}
+
+func TestScheduler_DeploymentTargetCluster(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	canary.Spec.TargetCluster = &flaggerv1.CanaryRemoteCluster{
+		Name:      "west",
+		SecretRef: corev1.LocalObjectReference{Name: "west-kubeconfig"},
+	}
+	mocks := newDeploymentFixture(canary)
+
+	// the target workload only exists in the remote cluster
+	err := mocks.kubeClient.AppsV1().Deployments("default").Delete(context.TODO(), "podinfo", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	remote := fake.NewSimpleClientset(
+		newDeploymentTestDeployment(),
+		newDeploymentTestHPA(),
+		newDeploymentTestConfigMap(),
+		newDeploymentTestConfigMapEnv(),
+		newDeploymentTestConfigMapVol(),
+		newDeploymentTestSecret(),
+		newDeploymentTestSecretEnv(),
+		newDeploymentTestSecretVol(),
+	)
+	mocks.ctrl.newRemoteClient = func(kubeconfig []byte) (kubernetes.Interface, error) {
+		return remote, nil
+	}
+	_, err = mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "west-kubeconfig"},
+		Data:       map[string][]byte{"kubeconfig": []byte("kind: Config")},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the primary workload is created in the remote cluster only
+	_, err = remote.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.Error(t, err)
+
+	// the services are created in both clusters
+	for _, name := range []string{"podinfo-primary", "podinfo-canary"} {
+		_, err = remote.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		_, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+	}
+
+	p, err := remote.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	p.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1, AvailableReplicas: 1}
+	_, err = remote.AppsV1().Deployments("default").Update(context.TODO(), p, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseInitialized, c.Status.Phase)
+
+	// the apex service is generated in both clusters once the primary is ready
+	_, err = remote.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestController_RemoteClusterClient(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	cluster := flaggerv1.CanaryRemoteCluster{Name: "west", SecretRef: corev1.LocalObjectReference{Name: "west-kubeconfig"}}
+
+	_, err := mocks.ctrl.remoteClusterClient(mocks.canary, cluster)
+	require.Error(t, err)
+
+	_, err = mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "west-kubeconfig"},
+		Data:       map[string][]byte{"value": []byte("kind: Config")},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = mocks.ctrl.remoteClusterClient(mocks.canary, cluster)
+	require.Error(t, err)
+}
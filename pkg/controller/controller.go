@@ -42,6 +42,7 @@ import (
 	"github.com/fluxcd/flagger/pkg/metrics"
 	"github.com/fluxcd/flagger/pkg/metrics/observers"
 	"github.com/fluxcd/flagger/pkg/notifier"
+	"github.com/fluxcd/flagger/pkg/report"
 	"github.com/fluxcd/flagger/pkg/router"
 )
 
@@ -49,29 +50,53 @@ const controllerAgentName = "flagger"
 
 // Controller is managing the canary objects and schedules canary deployments
 type Controller struct {
-	kubeClient       kubernetes.Interface
-	flaggerClient    clientset.Interface
-	flaggerInformers Informers
-	flaggerSynced    cache.InformerSynced
-	flaggerWindow    time.Duration
-	workqueue        workqueue.RateLimitingInterface
-	eventRecorder    record.EventRecorder
-	logger           *zap.SugaredLogger
-	canaries         *sync.Map
-	jobs             map[string]CanaryJob
-	recorder         metrics.Recorder
-	notifier         notifier.Interface
-	canaryFactory    *canary.Factory
-	routerFactory    *router.Factory
-	observerFactory  *observers.Factory
-	meshProvider     string
-	eventWebhook     string
+	kubeClient              kubernetes.Interface
+	flaggerClient           clientset.Interface
+	flaggerInformers        Informers
+	flaggerSynced           cache.InformerSynced
+	flaggerWindow           time.Duration
+	workqueue               workqueue.RateLimitingInterface
+	eventSinks              []EventSink
+	logger                  *zap.SugaredLogger
+	canaries                *sync.Map
+	jobs                    map[string]CanaryJob
+	recorder                metrics.Recorder
+	notifier                notifier.Interface
+	canaryFactory           *canary.Factory
+	routerFactory           *router.Factory
+	observerFactory         *observers.Factory
+	meshProvider            string
+	enabledProviders        []string
+	enabledTargetKinds      []string
+	crossNamespaceTargets   bool
+	namespaceFilter         *namespaceFilter
+	tenantSecretNamespaces  []string
+	defaultWebhooks         []flaggerv1.CanaryWebhook
+	finalizerMaxAttempts    int
+	openCostURL             string
+	fluxEventsURL           string
+	gateToken               string
+	primarySuffix           string
+	statusHistoryLimit      int
+	defaultAnalysisInterval time.Duration
+	finalizeAttempts        sync.Map
+	maxDurationAlerts       sync.Map
+	remoteClients           sync.Map
+	newRemoteClient         func(kubeconfig []byte) (kubernetes.Interface, error)
+	traces                  sync.Map
+	metricValues            sync.Map
+	timelines               sync.Map
+	checkResults            sync.Map
+	settingsMu              sync.RWMutex
+	reporter                *report.Exporter
 }
 
 type Informers struct {
 	CanaryInformer flaggerinformers.CanaryInformer
 	MetricInformer flaggerinformers.MetricTemplateInformer
 	AlertInformer  flaggerinformers.AlertProviderInformer
+	WaveInformer   flaggerinformers.CanaryWaveInformer
+	ClassInformer  flaggerinformers.CanaryClassInformer
 }
 
 func NewController(
@@ -87,6 +112,8 @@ func NewController(
 	meshProvider string,
 	version string,
 	eventWebhook string,
+	eventSinks []string,
+	reporter *report.Exporter,
 ) *Controller {
 	logger.Debug("Creating event broadcaster")
 	flaggerscheme.AddToScheme(scheme.Scheme)
@@ -106,7 +133,7 @@ func NewController(
 		flaggerInformers: flaggerInformers,
 		flaggerSynced:    flaggerInformers.CanaryInformer.Informer().HasSynced,
 		workqueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerAgentName),
-		eventRecorder:    eventRecorder,
+		eventSinks:       newEventSinks(eventSinks, eventRecorder, kubeClient, logger, eventWebhook),
 		logger:           logger,
 		canaries:         new(sync.Map),
 		jobs:             map[string]CanaryJob{},
@@ -117,7 +144,7 @@ func NewController(
 		canaryFactory:    canaryFactory,
 		routerFactory:    routerFactory,
 		meshProvider:     meshProvider,
-		eventWebhook:     eventWebhook,
+		reporter:         reporter,
 	}
 
 	flaggerInformers.CanaryInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -144,14 +171,14 @@ func NewController(
 
 				ctrl.enqueue(new)
 			} else if !newCanary.DeletionTimestamp.IsZero() && hasFinalizer(&newCanary) ||
-				!hasFinalizer(&newCanary) && newCanary.Spec.RevertOnDeletion {
+				!hasFinalizer(&newCanary) && needsFinalizer(&newCanary) {
 				// If this was marked for deletion and has finalizers enqueue for finalizing or
 				// if this canary doesn't have finalizers and RevertOnDeletion is true updated speck enqueue
 				ctrl.enqueue(new)
 			}
 
 			// If canary no longer desires reverting, finalizers should be removed
-			if oldCanary.Spec.RevertOnDeletion && !newCanary.Spec.RevertOnDeletion {
+			if needsFinalizer(&oldCanary) && !needsFinalizer(&newCanary) {
 				ctrl.logger.Infof("%s.%s opting out, deleting finalizers", newCanary.Name, newCanary.Namespace)
 				err := ctrl.removeFinalizer(&newCanary)
 				if err != nil {
@@ -165,6 +192,15 @@ func NewController(
 			if ok {
 				ctrl.logger.Infof("Deleting %s.%s from cache", r.Name, r.Namespace)
 				ctrl.canaries.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
+				ctrl.traces.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
+				ctrl.metricValues.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
+				ctrl.timelines.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
+				ctrl.checkResults.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
+				ctrl.maxDurationAlerts.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
+				ctrl.recorder.DeleteCanary(&r)
+				if ctrl.reporter != nil {
+					ctrl.reporter.Delete(&r)
+				}
 			}
 		},
 	})
@@ -172,6 +208,13 @@ func NewController(
 	return ctrl
 }
 
+// SetPrimarySuffix sets the suffix of the primary objects of the canaries that don't set spec.primarySuffix,
+// the canary and router factories must be configured with the same suffix
+func (c *Controller) SetPrimarySuffix(suffix string) {
+	c.primarySuffix = suffix
+	c.recorder.SetPrimarySuffix(suffix)
+}
+
 // Run starts the K8s workers and the canary scheduler
 func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
@@ -247,8 +290,17 @@ func (c *Controller) syncHandler(key string) error {
 		return nil
 	}
 
-	// Finalize if canary has been marked for deletion and revert is desired
-	if cd.Spec.RevertOnDeletion && cd.ObjectMeta.DeletionTimestamp != nil {
+	// ignore the canaries of the excluded namespaces
+	if ok, err := c.namespaceAllowed(namespace); err != nil {
+		return fmt.Errorf("%s namespace filter error: %w", key, err)
+	} else if !ok {
+		c.canaries.Delete(fmt.Sprintf("%s.%s", name, namespace))
+		c.logger.Debugf("Ignoring %s, namespace %s is excluded", key, namespace)
+		return nil
+	}
+
+	// Finalize if canary has been marked for deletion and revert or cleanup is desired
+	if needsFinalizer(cd) && cd.ObjectMeta.DeletionTimestamp != nil {
 		// If finalizers have been previously removed proceed
 		if !hasFinalizer(cd) {
 			c.logger.Infof("Canary %s.%s has been finalized", cd.Name, cd.Namespace)
@@ -259,8 +311,14 @@ func (c *Controller) syncHandler(key string) error {
 			if err := c.finalize(cd); err != nil {
 				c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
 					Errorf("Unable to finalize canary: %v", err)
-				return fmt.Errorf("unable to finalize to canary %s.%s error: %w", cd.Name, cd.Namespace, err)
+				if !c.forceDetach(cd) {
+					return fmt.Errorf("unable to finalize to canary %s.%s error: %w", cd.Name, cd.Namespace, err)
+				}
+				c.recordEventWarningf(cd, flaggerv1.ReasonDetached,
+					"Detaching canary %s.%s after %d failed cleanup attempts, the target and the routing may not be reverted: %v",
+					cd.Name, cd.Namespace, c.finalizerMaxAttempts, err)
 			}
+			c.finalizeAttempts.Delete(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace))
 		}
 
 		// Remove finalizer from Canary
@@ -271,7 +329,7 @@ func (c *Controller) syncHandler(key string) error {
 		}
 
 		// record event
-		c.recordEventInfof(cd, "Terminated canary %s.%s", cd.Name, cd.Namespace)
+		c.recordEventInfof(cd, flaggerv1.ReasonTerminated, "Terminated canary %s.%s", cd.Name, cd.Namespace)
 
 		c.logger.Infof("Canary %s.%s has been successfully processed and marked for deletion", cd.Name, cd.Namespace)
 		return nil
@@ -287,8 +345,8 @@ func (c *Controller) syncHandler(key string) error {
 
 	c.canaries.Store(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace), cd)
 
-	// If opt in for revertOnDeletion add finalizer if not present
-	if cd.Spec.RevertOnDeletion && !hasFinalizer(cd) {
+	// If opt in for revertOnDeletion or the target is in another namespace add finalizer if not present
+	if needsFinalizer(cd) && !hasFinalizer(cd) {
 		if err := c.addFinalizer(cd); err != nil {
 			return fmt.Errorf("unable to add finalizer to canary %s.%s: %w", cd.Name, cd.Namespace, err)
 		}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+const resourceCostMetric = "resource-cost"
+
+// SetOpenCostURL sets the address of the OpenCost or Kubecost API queried by the builtin resource cost metric
+func (c *Controller) SetOpenCostURL(url string) {
+	c.openCostURL = url
+}
+
+// openCostProvider returns a client of the cost allocation API for the metric interval
+func (c *Controller) openCostProvider(interval string) (*providers.OpenCostProvider, error) {
+	if c.openCostURL == "" {
+		return nil, fmt.Errorf("the %s metric requires the -opencost-url flag", resourceCostMetric)
+	}
+	return providers.NewOpenCostProvider(interval, flaggerv1.MetricTemplateProvider{Address: c.openCostURL})
+}
+
+// resourceCost returns the hourly cost per pod of the canary in percentage of the primary one,
+// 100 means the canary costs the same as the primary
+func (c *Controller) resourceCost(canary *flaggerv1.Canary, interval string) (float64, error) {
+	if canary.Spec.TargetRef.Kind == "Service" {
+		return 0, fmt.Errorf("the %s metric is not supported for Service targets", resourceCostMetric)
+	}
+
+	provider, err := c.openCostProvider(interval)
+	if err != nil {
+		return 0, err
+	}
+
+	kind := strings.ToLower(canary.Spec.TargetRef.Kind)
+	name := canary.Spec.TargetRef.Name
+	primaryCost, err := provider.GetPodHourlyCost(canary.GetTargetNamespace(), kind, canary.GetPrimaryName(name, c.primarySuffix))
+	if err != nil {
+		return 0, fmt.Errorf("primary cost query failed: %w", err)
+	}
+	if primaryCost == 0 {
+		return 0, fmt.Errorf("the primary %s.%s has no cost allocated: %w", name, canary.GetTargetNamespace(), providers.ErrNoValuesFound)
+	}
+
+	canaryCost, err := provider.GetPodHourlyCost(canary.GetTargetNamespace(), kind, name)
+	if err != nil {
+		return 0, fmt.Errorf("canary cost query failed: %w", err)
+	}
+
+	return canaryCost / primaryCost * 100, nil
+}
+
+// runResourceCostCheck halts the advancement when the canary costs more per pod than
+// the threshold allows, the deprecated threshold is the maximum percentage of the primary cost
+func (c *Controller) runResourceCostCheck(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) bool {
+	done := c.instrumentMetricQuery(canary, metric, "opencost")
+	val, err := c.resourceCost(canary, metric.Interval)
+	done(val, err)
+	if err != nil {
+		if errors.Is(err, providers.ErrNoValuesFound) {
+			c.recordEventWarningf(canary, flaggerv1.ReasonMetricNoValuesFound,
+				"Halt advancement no values found for metric %s probably %s.%s pods are not running: %v",
+				metric.Name, canary.Spec.TargetRef.Name, canary.GetTargetNamespace(), err)
+		} else {
+			c.recordEventErrorf(canary, flaggerv1.ReasonMetricQueryFailed, "OpenCost query failed: %v", err)
+		}
+		return false
+	}
+
+	min, max := metricThresholds(metric)
+	if min != nil && val < *min {
+		c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement resource cost %.2f%% < %v%%",
+			canary.Name, canary.Namespace, val, *min)
+		return false
+	}
+	if max != nil && val > *max {
+		c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement resource cost %.2f%% > %v%%",
+			canary.Name, canary.Namespace, val, *max)
+		return false
+	}
+	return true
+}
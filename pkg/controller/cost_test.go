@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_ResourceCost(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":200,"data":[{
+"podinfo-primary-a":{"properties":{"namespace":"default","controller":"podinfo-primary","controllerKind":"deployment"},"minutes":60,"totalCost":0.2},
+"podinfo-primary-b":{"properties":{"namespace":"default","controller":"podinfo-primary","controllerKind":"deployment"},"minutes":60,"totalCost":0.2},
+"podinfo-a":{"properties":{"namespace":"default","controller":"podinfo","controllerKind":"deployment"},"minutes":30,"totalCost":0.2}
+}]}`))
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+
+	// the metric requires the OpenCost address
+	_, err := mocks.ctrl.resourceCost(mocks.canary, "1m")
+	require.Error(t, err)
+
+	mocks.ctrl.SetOpenCostURL(ts.URL)
+	val, err := mocks.ctrl.resourceCost(mocks.canary, "1m")
+	require.NoError(t, err)
+	assert.InDelta(t, 200, val, 0.0001)
+
+	metric := flaggerv1.CanaryMetric{Name: resourceCostMetric, Interval: "1m", Threshold: 150}
+	assert.False(t, mocks.ctrl.runResourceCostCheck(mocks.canary, metric))
+
+	max := 250.0
+	metric.ThresholdRange = &flaggerv1.CanaryThresholdRange{Max: &max}
+	assert.True(t, mocks.ctrl.runResourceCostCheck(mocks.canary, metric))
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+	"github.com/fluxcd/flagger/pkg/router"
+)
+
+// analysisStartTime returns when the current analysis started, that's when the Promoted condition
+// became unknown or when a new revision restarted the analysis, whichever is the latest
+func analysisStartTime(cd *flaggerv1.Canary) (time.Time, bool) {
+	for _, condition := range cd.Status.Conditions {
+		if condition.Type == flaggerv1.PromotedType && condition.Status == corev1.ConditionUnknown {
+			start := condition.LastTransitionTime.Time
+			if cd.Status.StartTime != nil && cd.Status.StartTime.After(start) {
+				start = cd.Status.StartTime.Time
+			}
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// checkMaxDuration returns false when the analysis has run for longer than its max duration,
+// the canary is then rolled back or held at the current step with a single alert per run
+func (c *Controller) checkMaxDuration(cd *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) bool {
+	maxDuration := cd.GetAnalysisMaxDuration()
+	if maxDuration == 0 {
+		return true
+	}
+	switch cd.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaiting, flaggerv1.CanaryWaitingPromotion:
+	default:
+		return true
+	}
+
+	start, ok := analysisStartTime(cd)
+	if !ok {
+		return true
+	}
+	elapsed := time.Since(start)
+	if elapsed <= maxDuration {
+		return true
+	}
+
+	if cd.GetAnalysisMaxDurationAction() == flaggerv1.MaxDurationActionPause {
+		key := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+		if runID, ok := c.maxDurationAlerts.Load(key); !ok || runID != cd.Status.RunID {
+			c.maxDurationAlerts.Store(key, cd.Status.RunID)
+			c.recordEventWarningf(cd, flaggerv1.ReasonMaxDurationExceeded,
+				"Pausing %s.%s the analysis is running for %v, longer than the max duration %v",
+				cd.Name, cd.Namespace, elapsed.Round(time.Second), maxDuration)
+			c.alert(cd, flaggerv1.ReasonMaxDurationExceeded,
+				fmt.Sprintf("Analysis paused at %v%% canary weight, max duration %v exceeded", cd.Status.CanaryWeight, maxDuration),
+				false, flaggerv1.SeverityWarn)
+		}
+		return false
+	}
+
+	c.recordEventWarningf(cd, flaggerv1.ReasonMaxDurationExceeded,
+		"Rolling back %s.%s the analysis is running for %v, longer than the max duration %v",
+		cd.Name, cd.Namespace, elapsed.Round(time.Second), maxDuration)
+	c.alert(cd, flaggerv1.ReasonMaxDurationExceeded, fmt.Sprintf("Rolling back max duration %v exceeded", maxDuration),
+		false, flaggerv1.SeverityError)
+	c.rollback(cd, canaryController, meshRouter)
+	return false
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestAnalysisStartTime(t *testing.T) {
+	transition := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	cd := &flaggerv1.Canary{}
+	_, ok := analysisStartTime(cd)
+	assert.False(t, ok)
+
+	cd.Status.Conditions = []flaggerv1.CanaryCondition{
+		{Type: flaggerv1.PromotedType, Status: corev1.ConditionUnknown, LastTransitionTime: transition},
+	}
+	start, ok := analysisStartTime(cd)
+	require.True(t, ok)
+	assert.Equal(t, transition.Time, start)
+
+	// the start time of a previous run is ignored
+	previous := metav1.NewTime(transition.Add(-time.Hour))
+	cd.Status.StartTime = &previous
+	start, _ = analysisStartTime(cd)
+	assert.Equal(t, transition.Time, start)
+
+	// a restarted analysis starts with the new revision
+	restarted := metav1.NewTime(transition.Add(time.Hour))
+	cd.Status.StartTime = &restarted
+	start, _ = analysisStartTime(cd)
+	assert.Equal(t, restarted.Time, start)
+}
+
+// newMaxDurationFixture returns a canary under analysis that started two hours ago
+func newMaxDurationFixture(t *testing.T, action flaggerv1.MaxDurationAction) fixture {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// progressing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, flaggerv1.CanaryPhaseProgressing, cd.Status.Phase)
+	require.NotNil(t, cd.Status.StartTime)
+	cd.Spec.Analysis.MaxDuration = "1h"
+	cd.Spec.Analysis.MaxDurationAction = action
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	started := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	cd.Status.StartTime = &started
+	for i := range cd.Status.Conditions {
+		if cd.Status.Conditions[i].Type == flaggerv1.PromotedType {
+			cd.Status.Conditions[i].LastTransitionTime = started
+		}
+	}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	return mocks
+}
+
+func TestScheduler_MaxDurationRollback(t *testing.T) {
+	mocks := newMaxDurationFixture(t, "")
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+}
+
+func TestScheduler_MaxDurationPause(t *testing.T) {
+	mocks := newMaxDurationFixture(t, flaggerv1.MaxDurationActionPause)
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	weight := cd.Status.CanaryWeight
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, weight, c.Status.CanaryWeight)
+
+	runID, ok := mocks.ctrl.maxDurationAlerts.Load("podinfo.default")
+	require.True(t, ok)
+	assert.Equal(t, c.Status.RunID, runID)
+}
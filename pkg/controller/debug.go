@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// QueueState is the work queue state exposed by the debug endpoint
+type QueueState struct {
+	Length   int           `json:"length"`
+	Canaries []CanaryQueue `json:"canaries"`
+}
+
+// CanaryQueue is the scheduling state of a canary,
+// a canary with requeues greater than zero is being retried with backoff
+type CanaryQueue struct {
+	Key              string                `json:"key"`
+	Phase            flaggerv1.CanaryPhase `json:"phase"`
+	Requeues         int                   `json:"requeues"`
+	AnalysisInterval string                `json:"analysisInterval"`
+}
+
+// QueueState returns the work queue length and the requeues of each canary
+func (c *Controller) QueueState() QueueState {
+	state := QueueState{
+		Length:   c.workqueue.Len(),
+		Canaries: []CanaryQueue{},
+	}
+
+	c.canaries.Range(func(key interface{}, value interface{}) bool {
+		cd := value.(*flaggerv1.Canary)
+		state.Canaries = append(state.Canaries, CanaryQueue{
+			Key:              key.(string),
+			Phase:            cd.Status.Phase,
+			Requeues:         c.workqueue.NumRequeues(fmt.Sprintf("%s/%s", cd.Namespace, cd.Name)),
+			AnalysisInterval: c.analysisInterval(cd).String(),
+		})
+		return true
+	})
+
+	sort.Slice(state.Canaries, func(i, j int) bool { return state.Canaries[i].Key < state.Canaries[j].Key })
+	return state
+}
+
+// QueueHandler serves the work queue state as JSON
+func (c *Controller) QueueHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(c.QueueState()); err != nil {
+			c.logger.Errorf("encoding queue state failed: %v", err)
+		}
+	})
+}
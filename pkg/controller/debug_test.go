@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_QueueHandler(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.canaries.Store("podinfo.default", mocks.canary)
+	mocks.ctrl.workqueue.AddRateLimited("default/podinfo")
+	mocks.ctrl.workqueue.AddRateLimited("default/podinfo")
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/queue", nil)
+	rec := httptest.NewRecorder()
+	mocks.ctrl.QueueHandler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var state QueueState
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &state))
+	require.Len(t, state.Canaries, 1)
+	assert.Equal(t, "podinfo.default", state.Canaries[0].Key)
+	assert.Equal(t, 2, state.Canaries[0].Requeues)
+	assert.Equal(t, mocks.canary.GetAnalysisInterval().String(), state.Canaries[0].AnalysisInterval)
+}
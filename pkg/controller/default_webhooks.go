@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// skipDefaultWebhooksAnnotation opts a canary out of the default webhooks,
+// the value is a comma separated list of webhook names or * for all of them
+const skipDefaultWebhooksAnnotation = "flagger.app/skip-default-webhooks"
+
+// LoadDefaultWebhooks reads the list of webhooks appended to the analysis of every canary
+// from a YAML or JSON file
+func LoadDefaultWebhooks(path string) ([]flaggerv1.CanaryWebhook, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s failed: %w", path, err)
+	}
+	var webhooks []flaggerv1.CanaryWebhook
+	if err := yaml.UnmarshalStrict(data, &webhooks); err != nil {
+		return nil, fmt.Errorf("decoding %s failed: %w", path, err)
+	}
+	names := make(map[string]bool)
+	for _, webhook := range webhooks {
+		if webhook.Name == "" || webhook.URL == "" {
+			return nil, fmt.Errorf("default webhooks in %s must have a name and a url", path)
+		}
+		if names[webhook.Name] {
+			return nil, fmt.Errorf("default webhook %s is defined more than once in %s", webhook.Name, path)
+		}
+		names[webhook.Name] = true
+	}
+	return webhooks, nil
+}
+
+// SetDefaultWebhooks sets the webhooks appended to the analysis of every canary,
+// a canary webhook with the same name is replaced by the default one
+func (c *Controller) SetDefaultWebhooks(webhooks []flaggerv1.CanaryWebhook) {
+	c.defaultWebhooks = webhooks
+}
+
+// withDefaultWebhooks returns a copy of the canary with the default webhooks
+// that the canary didn't opt out of
+func (c *Controller) withDefaultWebhooks(cd *flaggerv1.Canary) *flaggerv1.Canary {
+	if len(c.defaultWebhooks) == 0 || cd.GetAnalysis() == nil {
+		return cd
+	}
+
+	skipped := strings.Split(cd.GetAnnotations()[skipDefaultWebhooksAnnotation], ",")
+	for i := range skipped {
+		skipped[i] = strings.TrimSpace(skipped[i])
+	}
+	if containsString(skipped, "*") {
+		return cd
+	}
+
+	cdCopy := cd.DeepCopy()
+	analysis := cdCopy.GetAnalysis()
+	for _, webhook := range c.defaultWebhooks {
+		if containsString(skipped, webhook.Name) {
+			continue
+		}
+		webhooks := analysis.Webhooks[:0]
+		for _, w := range analysis.Webhooks {
+			if w.Name != webhook.Name {
+				webhooks = append(webhooks, w)
+			}
+		}
+		analysis.Webhooks = append(webhooks, *webhook.DeepCopy())
+	}
+	return cdCopy
+}
+
+// withDefaults returns a copy of the canary with the defaults of its class
+// and the default webhooks of the controller
+func (c *Controller) withDefaults(cd *flaggerv1.Canary) (*flaggerv1.Canary, error) {
+	classified, err := c.withClassDefaults(cd)
+	if err != nil {
+		return nil, err
+	}
+	return c.withDefaultWebhooks(classified), nil
+}
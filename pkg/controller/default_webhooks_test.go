@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestLoadDefaultWebhooks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "webhooks.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+- name: security-scan
+  type: pre-rollout
+  url: http://scanner.security/gate
+- name: events
+  type: event
+  url: http://sink.observability/
+`), 0o644))
+
+	webhooks, err := LoadDefaultWebhooks(path)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+	assert.Equal(t, flaggerv1.PreRolloutHook, webhooks[0].Type)
+	assert.Equal(t, "events", webhooks[1].Name)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+- name: events
+  url: http://sink.observability/
+- name: events
+  url: http://sink.observability/
+`), 0o644))
+	_, err = LoadDefaultWebhooks(path)
+	assert.Error(t, err)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+- name: events
+`), 0o644))
+	_, err = LoadDefaultWebhooks(path)
+	assert.Error(t, err)
+}
+
+func TestController_withDefaultWebhooks(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.SetDefaultWebhooks([]flaggerv1.CanaryWebhook{
+		{Name: "security-scan", Type: flaggerv1.PreRolloutHook, URL: "http://scanner.security/gate"},
+		{Name: "events", Type: flaggerv1.EventHook, URL: "http://sink.observability/"},
+	})
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{
+		{Name: "load-test", Type: flaggerv1.RolloutHook, URL: "http://loadtester.test/"},
+		{Name: "security-scan", Type: flaggerv1.PreRolloutHook, URL: "http://scanner.test/"},
+	}
+
+	result := mocks.ctrl.withDefaultWebhooks(cd)
+	require.Len(t, result.GetAnalysis().Webhooks, 3)
+	assert.Equal(t, "load-test", result.GetAnalysis().Webhooks[0].Name)
+	assert.Equal(t, "http://scanner.security/gate", result.GetAnalysis().Webhooks[1].URL)
+	assert.Equal(t, "events", result.GetAnalysis().Webhooks[2].Name)
+	assert.Len(t, cd.GetAnalysis().Webhooks, 2)
+
+	cd.Annotations = map[string]string{skipDefaultWebhooksAnnotation: "events"}
+	result = mocks.ctrl.withDefaultWebhooks(cd)
+	require.Len(t, result.GetAnalysis().Webhooks, 2)
+	assert.Equal(t, "http://scanner.security/gate", result.GetAnalysis().Webhooks[1].URL)
+
+	cd.Annotations = map[string]string{skipDefaultWebhooksAnnotation: "*"}
+	result = mocks.ctrl.withDefaultWebhooks(cd)
+	assert.Equal(t, cd.GetAnalysis().Webhooks, result.GetAnalysis().Webhooks)
+}
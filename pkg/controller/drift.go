@@ -0,0 +1,373 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Drift is the difference between the primary and the canary pod templates,
+// it lists the changes that a promotion would apply to the primary
+type Drift struct {
+	Name       string           `json:"name"`
+	Namespace  string           `json:"namespace"`
+	TargetRef  string           `json:"targetRef"`
+	Containers []ContainerDrift `json:"containers"`
+	Configs    []ConfigDrift    `json:"configs"`
+}
+
+// ContainerDrift lists the changed fields of a container,
+// a container that exists only in the primary or the canary has an empty field list
+type ContainerDrift struct {
+	Name    string        `json:"name"`
+	Change  string        `json:"change"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// FieldChange is the primary and canary value of a container field
+type FieldChange struct {
+	Field   string `json:"field"`
+	Primary string `json:"primary,omitempty"`
+	Canary  string `json:"canary,omitempty"`
+}
+
+// ConfigDrift lists the keys of a ConfigMap or Secret that differ from the primary copy,
+// the values are not exposed
+type ConfigDrift struct {
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name"`
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+	Modified []string `json:"modified,omitempty"`
+}
+
+const (
+	driftAdded    = "Added"
+	driftRemoved  = "Removed"
+	driftModified = "Modified"
+)
+
+// CanaryDrift compares the pod templates of the canary target and its primary
+func (c *Controller) CanaryDrift(name string, namespace string) (*Drift, error) {
+	cd, err := c.flaggerInformers.CanaryInformer.Lister().Canaries(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := cd.GetPrimaryName(targetName, c.primarySuffix)
+	canary, err := c.podTemplate(cd.GetTargetKind(), targetName, cd.GetTargetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	primary, err := c.podTemplate(cd.GetTargetKind(), primaryName, cd.GetTargetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	canarySpec, primarySpec := canary.Spec, primary.Spec
+
+	drift := &Drift{
+		Name:       cd.Name,
+		Namespace:  cd.Namespace,
+		TargetRef:  fmt.Sprintf("%s/%s", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name),
+		Containers: diffContainers(primarySpec.Containers, canarySpec.Containers, cd.GetPrimarySuffix(c.primarySuffix)),
+		Configs:    []ConfigDrift{},
+	}
+
+	for _, ref := range configRefs(canarySpec) {
+		config, err := c.diffConfig(cd.GetTargetNamespace(), ref, cd.GetPrimarySuffix(c.primarySuffix))
+		if err != nil {
+			return nil, err
+		}
+		if config != nil {
+			drift.Configs = append(drift.Configs, *config)
+		}
+	}
+	return drift, nil
+}
+
+// DriftHandler serves the drift between the canary and the primary as JSON,
+// the canary is selected with the namespace and name query parameters
+func (c *Controller) DriftHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name, namespace := r.URL.Query().Get("name"), r.URL.Query().Get("namespace")
+		if name == "" || namespace == "" {
+			http.Error(w, "name and namespace are required", http.StatusBadRequest)
+			return
+		}
+
+		drift, err := c.CanaryDrift(name, namespace)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(drift); err != nil {
+			c.logger.Errorf("encoding canary drift failed: %v", err)
+		}
+	})
+}
+
+func diffContainers(primary []corev1.Container, canary []corev1.Container, suffix string) []ContainerDrift {
+	result := []ContainerDrift{}
+	primaryByName := make(map[string]corev1.Container)
+	for _, container := range primary {
+		primaryByName[container.Name] = container
+	}
+
+	for _, container := range canary {
+		p, ok := primaryByName[container.Name]
+		if !ok {
+			result = append(result, ContainerDrift{Name: container.Name, Change: driftAdded})
+			continue
+		}
+		delete(primaryByName, container.Name)
+		if changes := diffContainer(p, container, suffix); len(changes) > 0 {
+			result = append(result, ContainerDrift{Name: container.Name, Change: driftModified, Changes: changes})
+		}
+	}
+	for _, container := range primary {
+		if _, ok := primaryByName[container.Name]; ok {
+			result = append(result, ContainerDrift{Name: container.Name, Change: driftRemoved})
+		}
+	}
+	return result
+}
+
+func diffContainer(primary corev1.Container, canary corev1.Container, suffix string) []FieldChange {
+	var changes []FieldChange
+	add := func(field string, p string, c string) {
+		if p != c {
+			changes = append(changes, FieldChange{Field: field, Primary: p, Canary: c})
+		}
+	}
+
+	add("image", primary.Image, canary.Image)
+	add("command", strings.Join(primary.Command, " "), strings.Join(canary.Command, " "))
+	add("args", strings.Join(primary.Args, " "), strings.Join(canary.Args, " "))
+	add("resources.requests", formatResources(primary.Resources.Requests), formatResources(canary.Resources.Requests))
+	add("resources.limits", formatResources(primary.Resources.Limits), formatResources(canary.Resources.Limits))
+	add("envFrom", formatEnvFrom(primary.EnvFrom, suffix), formatEnvFrom(canary.EnvFrom, suffix))
+
+	primaryEnv, canaryEnv := formatEnv(primary.Env, suffix), formatEnv(canary.Env, suffix)
+	names := make([]string, 0, len(primaryEnv)+len(canaryEnv))
+	for name := range primaryEnv {
+		names = append(names, name)
+	}
+	for name := range canaryEnv {
+		if _, ok := primaryEnv[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add("env."+name, primaryEnv[name], canaryEnv[name])
+	}
+	return changes
+}
+
+// formatEnv renders the env vars, the references to the primary configs
+// are shown with the canary config name so that only the actual changes are reported
+func formatEnv(env []corev1.EnvVar, suffix string) map[string]string {
+	result := make(map[string]string, len(env))
+	for _, e := range env {
+		value := e.Value
+		if e.ValueFrom != nil {
+			switch {
+			case e.ValueFrom.ConfigMapKeyRef != nil:
+				value = fmt.Sprintf("configmap:%s/%s", strings.TrimSuffix(e.ValueFrom.ConfigMapKeyRef.Name, suffix), e.ValueFrom.ConfigMapKeyRef.Key)
+			case e.ValueFrom.SecretKeyRef != nil:
+				value = fmt.Sprintf("secret:%s/%s", strings.TrimSuffix(e.ValueFrom.SecretKeyRef.Name, suffix), e.ValueFrom.SecretKeyRef.Key)
+			case e.ValueFrom.FieldRef != nil:
+				value = fmt.Sprintf("field:%s", e.ValueFrom.FieldRef.FieldPath)
+			case e.ValueFrom.ResourceFieldRef != nil:
+				value = fmt.Sprintf("resource:%s", e.ValueFrom.ResourceFieldRef.Resource)
+			}
+		}
+		result[e.Name] = value
+	}
+	return result
+}
+
+func formatEnvFrom(envFrom []corev1.EnvFromSource, suffix string) string {
+	var refs []string
+	for _, source := range envFrom {
+		switch {
+		case source.ConfigMapRef != nil:
+			refs = append(refs, fmt.Sprintf("%sconfigmap:%s", source.Prefix, strings.TrimSuffix(source.ConfigMapRef.Name, suffix)))
+		case source.SecretRef != nil:
+			refs = append(refs, fmt.Sprintf("%ssecret:%s", source.Prefix, strings.TrimSuffix(source.SecretRef.Name, suffix)))
+		}
+	}
+	return strings.Join(refs, ", ")
+}
+
+func formatResources(resources corev1.ResourceList) string {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	var values []string
+	for _, name := range names {
+		quantity := resources[corev1.ResourceName(name)]
+		values = append(values, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	return strings.Join(values, ", ")
+}
+
+type configRef struct {
+	kind string
+	name string
+}
+
+// configRefs returns the ConfigMaps and Secrets referenced by the pod spec
+func configRefs(spec corev1.PodSpec) []configRef {
+	seen := make(map[configRef]bool)
+	var refs []configRef
+	add := func(kind string, name string) {
+		ref := configRef{kind: kind, name: name}
+		if name != "" && !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.ConfigMap != nil {
+			add("ConfigMap", volume.ConfigMap.Name)
+		}
+		if volume.Secret != nil {
+			add("Secret", volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					add("ConfigMap", source.ConfigMap.Name)
+				}
+				if source.Secret != nil {
+					add("Secret", source.Secret.Name)
+				}
+			}
+		}
+	}
+	for _, container := range spec.Containers {
+		for _, source := range container.EnvFrom {
+			if source.ConfigMapRef != nil {
+				add("ConfigMap", source.ConfigMapRef.Name)
+			}
+			if source.SecretRef != nil {
+				add("Secret", source.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
+				add("ConfigMap", env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				add("Secret", env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	return refs
+}
+
+// diffConfig compares the keys of a config with its primary copy,
+// it returns nil when the config isn't tracked or when both copies are equal
+func (c *Controller) diffConfig(namespace string, ref configRef, suffix string) (*ConfigDrift, error) {
+	var canaryData, primaryData map[string]string
+	switch ref.kind {
+	case "ConfigMap":
+		canary, err := c.kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), ref.name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("configmap %s.%s get query error: %w", ref.name, namespace, err)
+		}
+		primary, err := c.kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), ref.name+suffix, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("configmap %s.%s get query error: %w", ref.name+suffix, namespace, err)
+		}
+		canaryData, primaryData = canary.Data, primary.Data
+	case "Secret":
+		canary, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), ref.name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("secret %s.%s get query error: %w", ref.name, namespace, err)
+		}
+		primary, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), ref.name+suffix, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("secret %s.%s get query error: %w", ref.name+suffix, namespace, err)
+		}
+		canaryData, primaryData = secretData(canary.Data), secretData(primary.Data)
+	}
+
+	drift := ConfigDrift{Kind: ref.kind, Name: ref.name}
+	for key, value := range canaryData {
+		primaryValue, ok := primaryData[key]
+		switch {
+		case !ok:
+			drift.Added = append(drift.Added, key)
+		case primaryValue != value:
+			drift.Modified = append(drift.Modified, key)
+		}
+	}
+	for key := range primaryData {
+		if _, ok := canaryData[key]; !ok {
+			drift.Removed = append(drift.Removed, key)
+		}
+	}
+	if len(drift.Added) == 0 && len(drift.Removed) == 0 && len(drift.Modified) == 0 {
+		return nil, nil
+	}
+	sort.Strings(drift.Added)
+	sort.Strings(drift.Removed)
+	sort.Strings(drift.Modified)
+	return &drift, nil
+}
+
+func secretData(data map[string][]byte) map[string]string {
+	result := make(map[string]string, len(data))
+	for key, value := range data {
+		result[key] = string(value)
+	}
+	return result
+}
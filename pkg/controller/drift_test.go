@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_CanaryDrift(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	drift, err := mocks.ctrl.CanaryDrift("podinfo", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment/podinfo", drift.TargetRef)
+	assert.Empty(t, drift.Containers)
+	assert.Empty(t, drift.Configs)
+
+	// update the image and the config
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	config2 := newDeploymentTestConfigMapV2()
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Update(context.TODO(), config2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	drift, err = mocks.ctrl.CanaryDrift("podinfo", "default")
+	require.NoError(t, err)
+	require.Len(t, drift.Containers, 1)
+	assert.Equal(t, driftModified, drift.Containers[0].Change)
+	assert.Contains(t, drift.Containers[0].Changes, FieldChange{
+		Field:   "image",
+		Primary: "quay.io/stefanprodan/podinfo:1.2.0",
+		Canary:  "quay.io/stefanprodan/podinfo:1.2.1",
+	})
+	for _, change := range drift.Containers[0].Changes {
+		assert.NotContains(t, change.Primary, flaggerv1.DefaultPrimarySuffix)
+	}
+
+	require.Len(t, drift.Configs, 1)
+	assert.Equal(t, "podinfo-config-env", drift.Configs[0].Name)
+	assert.Equal(t, []string{"color"}, drift.Configs[0].Modified)
+	assert.Equal(t, []string{"output"}, drift.Configs[0].Added)
+}
+
+func TestController_DriftHandler(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/drift?namespace=default&name=podinfo", nil)
+	rec := httptest.NewRecorder()
+	mocks.ctrl.DriftHandler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var drift Drift
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &drift))
+	assert.Equal(t, "podinfo", drift.Name)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/drift?namespace=default&name=unknown", nil)
+	rec = httptest.NewRecorder()
+	mocks.ctrl.DriftHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/drift", nil)
+	rec = httptest.NewRecorder()
+	mocks.ctrl.DriftHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	// EventSinkKubernetes records the events in the Kubernetes API
+	EventSinkKubernetes = "kubernetes"
+	// EventSinkLog writes the events to the controller logs
+	EventSinkLog = "log"
+	// EventSinkHTTP posts the events to the event webhooks
+	EventSinkHTTP = "http"
+)
+
+// Event is a canary analysis event dispatched to the event sinks
+type Event struct {
+	// Type is the Kubernetes event type, Normal or Warning
+	Type string
+	// Severity distinguishes the errors from the warnings
+	Severity flaggerv1.AlertSeverity
+	Reason   flaggerv1.EventReason
+	Message  string
+}
+
+// EventSink receives the events of the canary analysis
+type EventSink interface {
+	Send(r *flaggerv1.Canary, e Event)
+}
+
+// ValidateEventSinks returns an error if a sink name is not one of the built-in sinks
+func ValidateEventSinks(names []string) error {
+	for _, name := range names {
+		switch name {
+		case EventSinkKubernetes, EventSinkLog, EventSinkHTTP:
+		default:
+			return fmt.Errorf("event sink %s not supported", name)
+		}
+	}
+	return nil
+}
+
+func newEventSinks(names []string, eventRecorder record.EventRecorder, kubeClient kubernetes.Interface, logger *zap.SugaredLogger, eventWebhook string) []EventSink {
+	var sinks []EventSink
+	for _, name := range names {
+		switch name {
+		case EventSinkKubernetes:
+			sinks = append(sinks, NewKubernetesEventSink(eventRecorder))
+		case EventSinkLog:
+			sinks = append(sinks, NewLogEventSink(logger))
+		case EventSinkHTTP:
+			sinks = append(sinks, NewHTTPEventSink(eventWebhook, kubeClient, logger))
+		}
+	}
+	return sinks
+}
+
+// KubernetesEventSink records the events in the Kubernetes API
+// with the analysis run ID annotation
+type KubernetesEventSink struct {
+	recorder record.EventRecorder
+}
+
+// NewKubernetesEventSink returns a sink for the given event recorder
+func NewKubernetesEventSink(recorder record.EventRecorder) *KubernetesEventSink {
+	return &KubernetesEventSink{recorder: recorder}
+}
+
+// Send records the event for the canary object
+func (s *KubernetesEventSink) Send(r *flaggerv1.Canary, e Event) {
+	s.recorder.AnnotatedEventf(r, eventAnnotations(r), e.Type, string(e.Reason), "%s", e.Message)
+}
+
+// LogEventSink writes the events to the controller logs,
+// the warnings are logged at the info level as halting the analysis is expected
+type LogEventSink struct {
+	logger *zap.SugaredLogger
+}
+
+// NewLogEventSink returns a sink for the given logger
+func NewLogEventSink(logger *zap.SugaredLogger) *LogEventSink {
+	return &LogEventSink{logger: logger}
+}
+
+// Send logs the event with the canary, reason and run ID fields
+func (s *LogEventSink) Send(r *flaggerv1.Canary, e Event) {
+	logger := s.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace), "reason", e.Reason)
+	if r.Status.RunID != "" {
+		logger = logger.With("runID", r.Status.RunID)
+	}
+	if e.Severity == flaggerv1.SeverityError {
+		logger.Error(e.Message)
+		return
+	}
+	logger.Info(e.Message)
+}
+
+// HTTPEventSink posts the events to the event webhooks of the canary,
+// the global event webhook is used when the canary has none
+type HTTPEventSink struct {
+	url        string
+	kubeClient kubernetes.Interface
+	logger     *zap.SugaredLogger
+}
+
+// NewHTTPEventSink returns a sink for the global event webhook URL,
+// the Kubernetes client loads the secrets of the canary event webhooks
+func NewHTTPEventSink(url string, kubeClient kubernetes.Interface, logger *zap.SugaredLogger) *HTTPEventSink {
+	return &HTTPEventSink{url: url, kubeClient: kubeClient, logger: logger}
+}
+
+// Send posts the event payload to the webhooks
+func (s *HTTPEventSink) Send(r *flaggerv1.Canary, e Event) {
+	webhookOverride := false
+	for _, canaryWebhook := range r.GetAnalysis().Webhooks {
+		if canaryWebhook.Type == flaggerv1.EventHook {
+			webhookOverride = true
+			secrets, err := loadWebhookSecrets(s.kubeClient, r.Namespace, canaryWebhook)
+			if err == nil {
+				err = callEventWebhook(r, canaryWebhook, secrets, e.Message, e.Type, e.Reason)
+			}
+			if err != nil {
+				s.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Errorf("error sending event to webhook: %s", err)
+			}
+		}
+	}
+
+	if s.url != "" && !webhookOverride {
+		hook := flaggerv1.CanaryWebhook{
+			Name: "events",
+			URL:  s.url,
+		}
+		err := CallEventWebhook(r, hook, e.Message, e.Type, e.Reason)
+		if err != nil {
+			s.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Errorf("error sending event to webhook: %s", err)
+		}
+	}
+}
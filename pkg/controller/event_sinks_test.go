@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestValidateEventSinks(t *testing.T) {
+	require.NoError(t, ValidateEventSinks([]string{EventSinkKubernetes, EventSinkLog, EventSinkHTTP}))
+	require.NoError(t, ValidateEventSinks(nil))
+	require.Error(t, ValidateEventSinks([]string{EventSinkLog, "etcd"}))
+}
+
+func TestNewEventSinks(t *testing.T) {
+	sinks := newEventSinks([]string{EventSinkLog, EventSinkHTTP}, &record.FakeRecorder{}, fake.NewSimpleClientset(), zap.S(), "")
+	require.Len(t, sinks, 2)
+	assert.IsType(t, &LogEventSink{}, sinks[0])
+	assert.IsType(t, &HTTPEventSink{}, sinks[1])
+}
+
+func TestKubernetesEventSink(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	canary := newDeploymentTestCanary()
+	NewKubernetesEventSink(recorder).Send(canary, Event{
+		Type:    corev1.EventTypeWarning,
+		Reason:  flaggerv1.ReasonSyncFailed,
+		Message: "100% failed",
+	})
+	assert.Equal(t, "Warning SyncFailed 100% failed", <-recorder.Events)
+}
+
+func TestHTTPEventSink(t *testing.T) {
+	var global, override []string
+	newServer := func(received *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload flaggerv1.CanaryWebhookPayload
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			*received = append(*received, payload.Metadata["eventReason"])
+			w.WriteHeader(http.StatusAccepted)
+		}))
+	}
+	globalServer := newServer(&global)
+	defer globalServer.Close()
+	overrideServer := newServer(&override)
+	defer overrideServer.Close()
+
+	sink := NewHTTPEventSink(globalServer.URL, fake.NewSimpleClientset(), zap.S())
+	event := Event{Type: corev1.EventTypeNormal, Reason: flaggerv1.ReasonAnalysisStarted, Message: "Starting canary analysis"}
+
+	canary := newDeploymentTestCanary()
+	sink.Send(canary, event)
+	assert.Equal(t, []string{string(flaggerv1.ReasonAnalysisStarted)}, global)
+
+	// the canary event webhook replaces the global one
+	canary.Spec.Analysis.Webhooks = append(canary.Spec.Analysis.Webhooks, flaggerv1.CanaryWebhook{
+		Name: "events",
+		Type: flaggerv1.EventHook,
+		URL:  overrideServer.URL,
+	})
+	sink.Send(canary, event)
+	assert.Len(t, global, 1)
+	assert.Equal(t, []string{string(flaggerv1.ReasonAnalysisStarted)}, override)
+}
@@ -22,64 +22,163 @@ import (
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
 
 	corev1 "k8s.io/api/core/v1"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/fluxcd/flagger/pkg/notifier"
+	"github.com/fluxcd/flagger/pkg/report"
 )
 
-func (c *Controller) recordEventInfof(r *flaggerv1.Canary, template string, args ...interface{}) {
-	c.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Infof(template, args...)
-	c.eventRecorder.Event(r, corev1.EventTypeNormal, "Synced", fmt.Sprintf(template, args...))
-	c.sendEventToWebhook(r, corev1.EventTypeNormal, template, args)
+func (c *Controller) recordEventInfof(r *flaggerv1.Canary, reason flaggerv1.EventReason, template string, args ...interface{}) {
+	c.recordEvent(r, Event{
+		Type:     corev1.EventTypeNormal,
+		Severity: flaggerv1.SeverityInfo,
+		Reason:   reason,
+		Message:  fmt.Sprintf(template, args...),
+	})
 }
 
-func (c *Controller) recordEventErrorf(r *flaggerv1.Canary, template string, args ...interface{}) {
-	c.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Errorf(template, args...)
-	c.eventRecorder.Event(r, corev1.EventTypeWarning, "Synced", fmt.Sprintf(template, args...))
-	c.sendEventToWebhook(r, corev1.EventTypeWarning, template, args)
+func (c *Controller) recordEventErrorf(r *flaggerv1.Canary, reason flaggerv1.EventReason, template string, args ...interface{}) {
+	c.recordEvent(r, Event{
+		Type:     corev1.EventTypeWarning,
+		Severity: flaggerv1.SeverityError,
+		Reason:   reason,
+		Message:  fmt.Sprintf(template, args...),
+	})
 }
 
-func (c *Controller) recordEventWarningf(r *flaggerv1.Canary, template string, args ...interface{}) {
-	c.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Infof(template, args...)
-	c.eventRecorder.Event(r, corev1.EventTypeWarning, "Synced", fmt.Sprintf(template, args...))
-	c.sendEventToWebhook(r, corev1.EventTypeWarning, template, args)
+func (c *Controller) recordEventWarningf(r *flaggerv1.Canary, reason flaggerv1.EventReason, template string, args ...interface{}) {
+	c.recordEvent(r, Event{
+		Type:     corev1.EventTypeWarning,
+		Severity: flaggerv1.SeverityWarn,
+		Reason:   reason,
+		Message:  fmt.Sprintf(template, args...),
+	})
 }
 
-func (c *Controller) sendEventToWebhook(r *flaggerv1.Canary, eventType, template string, args []interface{}) {
-	webhookOverride := false
-	for _, canaryWebhook := range r.GetAnalysis().Webhooks {
-		if canaryWebhook.Type == flaggerv1.EventHook {
-			webhookOverride = true
-			err := CallEventWebhook(r, canaryWebhook, fmt.Sprintf(template, args...), eventType)
-			if err != nil {
-				c.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Errorf("error sending event to webhook: %s", err)
-			}
-		}
+// recordEvent dispatches the event to the enabled sinks
+// and adds it to the analysis report, timeline and status history
+func (c *Controller) recordEvent(r *flaggerv1.Canary, e Event) {
+	for _, sink := range c.eventSinks {
+		sink.Send(r, e)
+	}
+	c.recordReportStep(r, e.Type, e.Reason, e.Message)
+	c.recordTimelineDecision(r, e.Reason, e.Message)
+	c.recordHistory(r, e)
+}
+
+// runIDAnnotation is set on the Kubernetes events with the ID of the analysis run
+const runIDAnnotation = "flagger.app/run-id"
+
+// newRunID generates the identifier of a canary analysis run
+func newRunID() string {
+	return string(uuid.NewUUID())
+}
+
+// gitRevisionAnnotation is set on the canary with the Git commit the target was deployed from,
+// the Git alert providers set the commit status of this revision
+const gitRevisionAnnotation = "flagger.app/git-revision"
+
+func eventAnnotations(r *flaggerv1.Canary) map[string]string {
+	if r.Status.RunID == "" {
+		return nil
 	}
+	return map[string]string{runIDAnnotation: r.Status.RunID}
+}
 
-	if c.eventWebhook != "" && !webhookOverride {
-		hook := flaggerv1.CanaryWebhook{
-			Name: "events",
-			URL:  c.eventWebhook,
+// recordReportStep adds the event to the analysis report and exports the report
+// when the canary has been promoted or rolled back
+func (c *Controller) recordReportStep(r *flaggerv1.Canary, eventType string, reason flaggerv1.EventReason, message string) {
+	if c.reporter == nil {
+		return
+	}
+
+	c.reporter.Record(r, eventType, reason, message)
+	switch reason {
+	case flaggerv1.ReasonPromoted, flaggerv1.ReasonAnalysisSkipped:
+		c.reporter.Export(r, flaggerv1.CanaryPhaseSucceeded, c.reportMetrics(r), c.reportImages(r))
+	case flaggerv1.ReasonFailed:
+		c.reporter.Export(r, flaggerv1.CanaryPhaseFailed, c.reportMetrics(r), c.reportImages(r))
+	}
+}
+
+// reportImages returns the container images of the target pod template,
+// the Service targets have no images
+func (c *Controller) reportImages(r *flaggerv1.Canary) []string {
+	template, err := c.podTemplate(r.GetTargetKind(), r.Spec.TargetRef.Name, r.GetTargetNamespace())
+	if err != nil {
+		return nil
+	}
+	images := make([]string, 0, len(template.Spec.Containers))
+	for _, container := range template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// reportMetrics returns the analysis metrics with their thresholds and last values
+func (c *Controller) reportMetrics(r *flaggerv1.Canary) []report.Metric {
+	values := map[string]MetricValue{}
+	if v, ok := c.metricValues.Load(fmt.Sprintf("%s.%s", r.Name, r.Namespace)); ok {
+		values = v.(map[string]MetricValue)
+	}
+
+	metrics := make([]report.Metric, 0, len(r.GetAnalysis().Metrics))
+	for _, metric := range r.GetAnalysis().Metrics {
+		m := report.Metric{Name: metric.Name}
+		if tr := metric.ThresholdRange; tr != nil {
+			m.Min = tr.Min
+			m.Max = tr.Max
+		} else if metric.Threshold > 0 {
+			max := metric.Threshold
+			if metric.Name == "request-success-rate" {
+				m.Min = &max
+			} else {
+				m.Max = &max
+			}
 		}
-		err := CallEventWebhook(r, hook, fmt.Sprintf(template, args...), eventType)
-		if err != nil {
-			c.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Errorf("error sending event to webhook: %s", err)
+		if mv, ok := values[metric.Name]; ok {
+			val := mv.Value
+			m.LastValue = &val
 		}
+		metrics = append(metrics, m)
 	}
+	return metrics
 }
 
-func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bool, severity flaggerv1.AlertSeverity) {
+func (c *Controller) alert(canary *flaggerv1.Canary, reason flaggerv1.EventReason, message string, metadata bool, severity flaggerv1.AlertSeverity) {
 	var fields []notifier.Field
 	if metadata {
 		fields = alertMetadata(canary)
 	}
+	fields = append(fields, notifier.Field{
+		Name:  "Reason",
+		Value: string(reason),
+	})
+	if canary.Status.RunID != "" {
+		fields = append(fields, notifier.Field{
+			Name:  "Run ID",
+			Value: canary.Status.RunID,
+		})
+	}
+	if revision := canary.GetAnnotations()[gitRevisionAnnotation]; revision != "" {
+		fields = append(fields, notifier.Field{
+			Name:  "Revision",
+			Value: revision,
+		})
+	}
+	if artifacts := c.webhookArtifacts(canary); len(artifacts) > 0 {
+		fields = append(fields, notifier.Field{
+			Name:  "Artifacts",
+			Value: strings.Join(artifacts, "\n"),
+		})
+	}
 
 	// send alert with the global notifier
 	if len(canary.GetAnalysis().Alerts) == 0 {
-		err := c.notifier.Post(canary.Name, canary.Namespace, message, fields, string(severity))
+		err := c.getNotifier().Post(canary.Name, canary.Namespace, message, fields, string(severity))
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Errorf("alert can't be sent: %v", err)
@@ -106,67 +205,170 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 			continue
 		}
 
-		// determine alert provider namespace
-		providerNamespace := canary.GetNamespace()
-		if alert.ProviderRef.Namespace != "" {
-			providerNamespace = alert.ProviderRef.Namespace
+		n, err := c.alertNotifier(canary, alert)
+		if err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Errorf("alert provider %s error: %v", alert.ProviderRef.Name, err)
+			continue
 		}
 
-		// find alert provider
-		provider, err := c.flaggerInformers.AlertInformer.Lister().AlertProviders(providerNamespace).Get(alert.ProviderRef.Name)
+		// send alert
+		err = n.Post(canary.Name, canary.Namespace, message, fields, string(severity))
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-				Errorf("alert provider %s.%s error: %v", alert.ProviderRef.Name, providerNamespace, err)
-			continue
+				Errorf("alert provider %s send error: %v", alert.ProviderRef.Name, err)
 		}
 
-		// set hook URL address
-		url := provider.Spec.Address
+	}
+}
 
-		// extract address from secret
-		if provider.Spec.SecretRef != nil {
-			secret, err := c.kubeClient.CoreV1().Secrets(providerNamespace).Get(context.TODO(), provider.Spec.SecretRef.Name, metav1.GetOptions{})
-			if err != nil {
-				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-					Errorf("alert provider %s.%s secretRef error: %v", alert.ProviderRef.Name, providerNamespace, err)
-				continue
-			}
-			if address, ok := secret.Data["address"]; ok {
-				url = string(address)
-			} else {
-				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-					Errorf("alert provider %s.%s secret does not contain an address", alert.ProviderRef.Name, providerNamespace)
-				continue
-			}
-		}
+// annotate sends the traffic shifting progress to the alert providers that record
+// annotations, the other providers are only notified by alerts
+func (c *Controller) annotate(canary *flaggerv1.Canary, reason flaggerv1.EventReason, message string) {
+	fields := []notifier.Field{{
+		Name:  "Reason",
+		Value: string(reason),
+	}}
 
-		// set defaults
-		username := "flagger"
-		if provider.Spec.Username != "" {
-			username = provider.Spec.Username
+	for _, alert := range canary.GetAnalysis().Alerts {
+		provider, err := c.alertProvider(canary, alert)
+		if err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Errorf("alert provider %s error: %v", alert.ProviderRef.Name, err)
+			continue
 		}
-		channel := "general"
-		if provider.Spec.Channel != "" {
-			channel = provider.Spec.Channel
+		if provider.Spec.Type != "grafana" {
+			continue
 		}
 
-		// create notifier based on provider type
-		f := notifier.NewFactory(url, username, channel)
-		n, err := f.Notifier(provider.Spec.Type)
+		n, err := c.canaryNotifier(canary, alert, provider)
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-				Errorf("alert provider %s.%s error: %v", alert.ProviderRef.Name, providerNamespace, err)
+				Errorf("alert provider %s.%s error: %v", provider.Name, provider.Namespace, err)
 			continue
 		}
 
-		// send alert
-		err = n.Post(canary.Name, canary.Namespace, message, fields, string(severity))
+		err = n.Post(canary.Name, canary.Namespace, message, fields, string(flaggerv1.SeverityInfo))
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-				Errorf("alert provider $s.%s send error: %v", alert.ProviderRef.Name, providerNamespace, err)
+				Errorf("alert provider %s.%s send error: %v", provider.Name, provider.Namespace, err)
 		}
+	}
+}
+
+// alertNotifier finds the provider of a canary alert and creates its notifier
+func (c *Controller) alertNotifier(canary *flaggerv1.Canary, alert flaggerv1.CanaryAlert) (notifier.Interface, error) {
+	provider, err := c.alertProvider(canary, alert)
+	if err != nil {
+		return nil, err
+	}
+	return c.canaryNotifier(canary, alert, provider)
+}
+
+// canaryNotifier creates the notifier of an alert provider,
+// the address and token are read from the alert secret when the canary brings its own credentials
+func (c *Controller) canaryNotifier(canary *flaggerv1.Canary, alert flaggerv1.CanaryAlert,
+	provider *flaggerv1.AlertProvider) (notifier.Interface, error) {
+	if alert.SecretRef == nil {
+		return c.newNotifier(provider)
+	}
+
+	secret, err := c.tenantSecret(canary, alert.SecretRef.Name)
+	if err != nil {
+		return nil, fmt.Errorf("alert %s secretRef error: %w", alert.Name, err)
+	}
+	url := provider.Spec.Address
+	if address, ok := secret.Data["address"]; ok {
+		url = string(address)
+	} else if url == "" {
+		return nil, fmt.Errorf("secret %s does not contain an address", alert.SecretRef.Name)
+	}
+	token := ""
+	if t, ok := secret.Data["token"]; ok {
+		token = string(t)
+	}
+	return buildNotifier(provider, url, token)
+}
+
+// alertProvider finds the provider of a canary alert,
+// the provider namespace defaults to the canary namespace
+func (c *Controller) alertProvider(canary *flaggerv1.Canary, alert flaggerv1.CanaryAlert) (*flaggerv1.AlertProvider, error) {
+	providerNamespace := canary.GetNamespace()
+	if alert.ProviderRef.Namespace != "" {
+		providerNamespace = alert.ProviderRef.Namespace
+	}
+
+	provider, err := c.flaggerInformers.AlertInformer.Lister().AlertProviders(providerNamespace).Get(alert.ProviderRef.Name)
+	if err != nil {
+		return nil, fmt.Errorf("alert provider %s.%s not found: %w", alert.ProviderRef.Name, providerNamespace, err)
+	}
+	return provider, nil
+}
+
+// newNotifier creates the notifier of an alert provider,
+// the address and token are read from the provider secret if specified,
+// the secret key refs take precedence over the secretRef keys
+func (c *Controller) newNotifier(provider *flaggerv1.AlertProvider) (notifier.Interface, error) {
+	// set hook URL address
+	url := provider.Spec.Address
+	token := ""
+
+	// extract address and token from secret
+	if provider.Spec.SecretRef != nil {
+		secret, err := c.kubeClient.CoreV1().Secrets(provider.Namespace).Get(context.TODO(), provider.Spec.SecretRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("secretRef %s error: %w", provider.Spec.SecretRef.Name, err)
+		}
+		if address, ok := secret.Data["address"]; ok {
+			url = string(address)
+		} else if url == "" {
+			return nil, fmt.Errorf("secret %s does not contain an address", provider.Spec.SecretRef.Name)
+		}
+		if t, ok := secret.Data["token"]; ok {
+			token = string(t)
+		}
+	}
+	if ref := provider.Spec.AddressSecretKeyRef; ref != nil {
+		address, ok, err := readSecretKey(c.kubeClient, provider.Namespace, *ref)
+		if err != nil {
+			return nil, fmt.Errorf("addressSecretKeyRef %s error: %w", ref.Name, err)
+		}
+		if ok {
+			url = address
+		}
+	}
+	if ref := provider.Spec.TokenSecretKeyRef; ref != nil {
+		t, ok, err := readSecretKey(c.kubeClient, provider.Namespace, *ref)
+		if err != nil {
+			return nil, fmt.Errorf("tokenSecretKeyRef %s error: %w", ref.Name, err)
+		}
+		if ok {
+			token = t
+		}
+	}
+
+	return buildNotifier(provider, url, token)
+}
+
+// buildNotifier creates the notifier of an alert provider type with the resolved address and token
+func buildNotifier(provider *flaggerv1.AlertProvider, url string, token string) (notifier.Interface, error) {
+	// set defaults
+	username := "flagger"
+	if provider.Spec.Username != "" {
+		username = provider.Spec.Username
+	}
+	channel := "general"
+	if provider.Spec.Channel != "" {
+		channel = provider.Spec.Channel
+	}
 
+	// create notifier based on provider type
+	f := notifier.NewFactory(url, username, channel, token)
+	n, err := f.Notifier(provider.Spec.Type)
+	if err != nil {
+		return nil, err
 	}
+	return n, nil
 }
 
 func alertMetadata(canary *flaggerv1.Canary) []notifier.Field {
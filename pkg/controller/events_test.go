@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/notifier"
+)
+
+func TestController_Annotate(t *testing.T) {
+	var annotations []notifier.GrafanaAnnotation
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a notifier.GrafanaAnnotation
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&a))
+		annotations = append(annotations, a)
+	}))
+	defer ts.Close()
+
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Alerts = []flaggerv1.CanaryAlert{
+		{
+			Name:     "slack",
+			Severity: "info",
+			ProviderRef: flaggerv1.CrossNamespaceObjectReference{
+				Name: "slack",
+			},
+		},
+		{
+			Name:     "grafana",
+			Severity: "info",
+			ProviderRef: flaggerv1.CrossNamespaceObjectReference{
+				Name: "grafana",
+			},
+		},
+	}
+	mocks := newDeploymentFixture(canary)
+	mocks.ctrl.flaggerInformers.AlertInformer.Informer().GetIndexer().Add(&flaggerv1.AlertProvider{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "grafana",
+		},
+		Spec: flaggerv1.AlertProviderSpec{
+			Type:    "grafana",
+			Address: ts.URL,
+		},
+	})
+
+	mocks.ctrl.annotate(canary, flaggerv1.ReasonAdvanced, "Canary weight advanced to 10")
+
+	require.Len(t, annotations, 1)
+	assert.Equal(t, []string{"flagger", "podinfo", "default", "info"}, annotations[0].Tags)
+	assert.Equal(t, "Canary weight advanced to 10\nReason: Advanced", annotations[0].Text)
+}
+
+func TestController_NewNotifier_SecretKeyRefs(t *testing.T) {
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	_, err := mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana-keys", Namespace: "default"},
+		Data: map[string][]byte{
+			"url":    []byte(ts.URL),
+			"apiKey": []byte("s3cr3t"),
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	provider := &flaggerv1.AlertProvider{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "grafana"},
+		Spec: flaggerv1.AlertProviderSpec{
+			Type: "grafana",
+			AddressSecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "grafana-keys"},
+				Key:                  "url",
+			},
+			TokenSecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "grafana-keys"},
+				Key:                  "apiKey",
+			},
+		},
+	}
+	n, err := mocks.ctrl.newNotifier(provider)
+	require.NoError(t, err)
+	require.NoError(t, n.Post("podinfo", "default", "test", nil, string(flaggerv1.SeverityInfo)))
+	assert.Equal(t, "Bearer s3cr3t", authorization)
+
+	provider.Spec.TokenSecretKeyRef.Key = "unknown"
+	_, err = mocks.ctrl.newNotifier(provider)
+	require.Error(t, err)
+}
+
+func TestController_Alert_GitRevision(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	canary := newDeploymentTestCanary()
+	canary.Annotations = map[string]string{gitRevisionAnnotation: "7d8f6b5"}
+	canary.Spec.Analysis.Alerts = []flaggerv1.CanaryAlert{{
+		Name:        "gitlab",
+		Severity:    "info",
+		ProviderRef: flaggerv1.CrossNamespaceObjectReference{Name: "gitlab"},
+	}}
+	mocks := newDeploymentFixture(canary)
+	_, err := mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "gitlab-token", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("s3cr3t")},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.flaggerInformers.AlertInformer.Informer().GetIndexer().Add(&flaggerv1.AlertProvider{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gitlab"},
+		Spec: flaggerv1.AlertProviderSpec{
+			Type:      "gitlab",
+			Address:   ts.URL + "/group/podinfo",
+			SecretRef: &corev1.LocalObjectReference{Name: "gitlab-token"},
+		},
+	})
+
+	mocks.ctrl.alert(canary, flaggerv1.ReasonInitialized, "Initialization done", false, flaggerv1.SeverityInfo)
+	mocks.ctrl.alert(canary, flaggerv1.ReasonPromoted, "Canary analysis completed successfully, promotion finished.", false, flaggerv1.SeverityInfo)
+
+	assert.Equal(t, []string{"/api/v4/projects/group%2Fpodinfo/statuses/7d8f6b5"}, paths)
+}
@@ -19,11 +19,14 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
 )
 
 const finalizer = "finalizer.flagger.app"
@@ -40,7 +43,14 @@ func (c *Controller) finalize(old interface{}) error {
 	}
 
 	// Retrieve a controller
-	canaryController := c.canaryFactory.Controller(canary.Spec.TargetRef.Kind)
+	canaryController, err := c.targetController(canary)
+	if err != nil {
+		return fmt.Errorf("target cluster error: %w", err)
+	}
+	canaryController, err = c.multiClusterController(canary, canaryController)
+	if err != nil {
+		return fmt.Errorf("remote clusters error: %w", err)
+	}
 
 	// Set the status to terminating if not already in that state
 	if canary.Status.Phase != flaggerv1.CanaryPhaseTerminating {
@@ -49,10 +59,31 @@ func (c *Controller) finalize(old interface{}) error {
 		}
 
 		// record event
-		c.recordEventInfof(canary, "Terminating canary %s.%s", canary.Name, canary.Namespace)
+		c.recordEventInfof(canary, flaggerv1.ReasonTerminating, "Terminating canary %s.%s", canary.Name, canary.Namespace)
+	}
+
+	// The objects generated for a cross-namespace target are deleted without reverting the target
+	if !canary.Spec.RevertOnDeletion {
+		return c.deleteGeneratedObjects(canary)
 	}
 
-	// Revert the Kubernetes deployment or daemonset
+	policy := canary.GetRevertPolicy()
+
+	// Resume the Flux owner of the target if the canary is deleted during the analysis
+	if canary.Spec.SuspendFluxDuringAnalysis {
+		if err := c.setFluxSuspended(canary, false); err != nil {
+			return fmt.Errorf("failed to resume Flux: %w", err)
+		}
+	}
+
+	// Give the per-cluster overrides back to the target before scaling it up
+	if policy != flaggerv1.RevertPolicyDelete {
+		if err := c.revertPropagation(canary); err != nil {
+			return fmt.Errorf("failed to revert propagation: %w", err)
+		}
+	}
+
+	// Revert the Kubernetes deployment, daemonset or statefulset
 	err = canaryController.Finalize(canary)
 	if err != nil {
 		return fmt.Errorf("failed to revert target: %w", err)
@@ -60,33 +91,59 @@ func (c *Controller) finalize(old interface{}) error {
 	c.logger.Infof("%s.%s kind %s reverted", canary.Name, canary.Namespace, canary.Spec.TargetRef.Kind)
 
 	// Ensure that targetRef has met a ready state
-	c.logger.Infof("Checking if canary is ready %s.%s", canary.Name, canary.Namespace)
-	_, err = canaryController.IsCanaryReady(canary)
-	if err != nil {
-		return fmt.Errorf("canary not ready during finalizing: %w", err)
-	}
-
-	labelSelector, labelValue, ports, err := canaryController.GetMetadata(canary)
-	if err != nil {
-		return fmt.Errorf("failed to get metadata for router finalizing: %w", err)
+	if policy != flaggerv1.RevertPolicyDelete {
+		c.logger.Infof("Checking if canary is ready %s.%s", canary.Name, canary.Namespace)
+		_, err = canaryController.IsCanaryReady(canary)
+		if err != nil {
+			return fmt.Errorf("canary not ready during finalizing: %w", err)
+		}
 	}
 
 	// Revert the Kubernetes service
-	router := c.routerFactory.KubernetesRouter(canary.Spec.TargetRef.Kind, labelSelector, labelValue, ports)
-	if err := router.Finalize(canary); err != nil {
-		return fmt.Errorf("failed revert router: %w", err)
+	if policy != flaggerv1.RevertPolicyDelete {
+		if err := c.revertRouter(canary, canaryController); err != nil {
+			return err
+		}
 	}
-	c.logger.Infof("%s.%s router reverted", canary.Name, canary.Namespace)
 
 	// Revert the mesh objects
 	if err := c.revertMesh(canary); err != nil {
 		return fmt.Errorf("failed to revert mesh: %w", err)
 	}
 
+	// Delete the objects that aren't garbage collected
+	if err := c.deleteGeneratedObjects(canary); err != nil {
+		return err
+	}
+
+	c.recordEventInfof(canary, flaggerv1.ReasonTeardown, "Teardown of %s.%s with the %s policy: %s",
+		canary.Name, canary.Namespace, policy, strings.Join(c.teardownPlan(canary), ", "))
 	c.logger.Infof("Finalization complete for %s.%s", canary.Name, canary.Namespace)
 	return nil
 }
 
+// revertRouter reverts the Kubernetes services of the target and of the remote clusters
+func (c *Controller) revertRouter(cd *flaggerv1.Canary, canaryController canary.Controller) error {
+	labelSelector, labelValue, ports, err := canaryController.GetMetadata(cd)
+	if err != nil {
+		return fmt.Errorf("failed to get metadata for router finalizing: %w", err)
+	}
+
+	router, err := c.targetRouter(cd, labelSelector, labelValue, ports)
+	if err != nil {
+		return fmt.Errorf("target cluster error: %w", err)
+	}
+	router, err = c.multiClusterRouter(cd, router, labelSelector, labelValue, ports)
+	if err != nil {
+		return fmt.Errorf("remote clusters error: %w", err)
+	}
+	if err := router.Finalize(cd); err != nil {
+		return fmt.Errorf("failed revert router: %w", err)
+	}
+	c.logger.Infof("%s.%s router reverted", cd.Name, cd.Namespace)
+	return nil
+}
+
 // revertMesh reverts defined mesh provider based upon the implementation's respective Finalize method.
 // If the Finalize method encounters and error that is returned, else revert is considered successful.
 func (c *Controller) revertMesh(r *flaggerv1.Canary) error {
@@ -104,6 +161,126 @@ func (c *Controller) revertMesh(r *flaggerv1.Canary) error {
 	return nil
 }
 
+// generatedObject is an object of the target namespace that may have been generated for a canary
+type generatedObject struct {
+	kind   string
+	object metav1.Object
+	delete func(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+// deleteGeneratedObjects deletes the objects generated in the target namespace of a cross-namespace canary,
+// they don't have owner references to the canary and are not garbage collected
+func (c *Controller) deleteGeneratedObjects(cd *flaggerv1.Canary) error {
+	ns := cd.GetTargetNamespace()
+	if ns == cd.Namespace {
+		return nil
+	}
+
+	objects, err := c.listGeneratedObjects(ns)
+	if err != nil {
+		return fmt.Errorf("failed to list the generated objects: %w", err)
+	}
+	for _, obj := range objects {
+		if !cd.IsOwnerOf(obj.object) {
+			continue
+		}
+		err := obj.delete(context.TODO(), obj.object.GetName(), metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("%s %s.%s delete error: %w", obj.kind, obj.object.GetName(), ns, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("%s %s.%s deleted", obj.kind, obj.object.GetName(), ns)
+	}
+	return nil
+}
+
+// listGeneratedObjects lists the objects of the kinds that Flagger generates in a namespace,
+// the custom resources are skipped when their CRD isn't installed
+func (c *Controller) listGeneratedObjects(ns string) ([]generatedObject, error) {
+	ctx, opts := context.TODO(), metav1.ListOptions{}
+	var objects []generatedObject
+
+	deployments, err := c.kubeClient.AppsV1().Deployments(ns).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		objects = append(objects, generatedObject{"Deployment", &deployments.Items[i], c.kubeClient.AppsV1().Deployments(ns).Delete})
+	}
+	daemonSets, err := c.kubeClient.AppsV1().DaemonSets(ns).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		objects = append(objects, generatedObject{"DaemonSet", &daemonSets.Items[i], c.kubeClient.AppsV1().DaemonSets(ns).Delete})
+	}
+	statefulSets, err := c.kubeClient.AppsV1().StatefulSets(ns).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		objects = append(objects, generatedObject{"StatefulSet", &statefulSets.Items[i], c.kubeClient.AppsV1().StatefulSets(ns).Delete})
+	}
+	services, err := c.kubeClient.CoreV1().Services(ns).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range services.Items {
+		objects = append(objects, generatedObject{"Service", &services.Items[i], c.kubeClient.CoreV1().Services(ns).Delete})
+	}
+	configMaps, err := c.kubeClient.CoreV1().ConfigMaps(ns).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range configMaps.Items {
+		objects = append(objects, generatedObject{"ConfigMap", &configMaps.Items[i], c.kubeClient.CoreV1().ConfigMaps(ns).Delete})
+	}
+	secrets, err := c.kubeClient.CoreV1().Secrets(ns).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range secrets.Items {
+		objects = append(objects, generatedObject{"Secret", &secrets.Items[i], c.kubeClient.CoreV1().Secrets(ns).Delete})
+	}
+	pdbs, err := c.kubeClient.PolicyV1beta1().PodDisruptionBudgets(ns).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pdbs.Items {
+		objects = append(objects, generatedObject{"PodDisruptionBudget", &pdbs.Items[i], c.kubeClient.PolicyV1beta1().PodDisruptionBudgets(ns).Delete})
+	}
+	hpas, err := c.kubeClient.AutoscalingV1().HorizontalPodAutoscalers(ns).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hpas.Items {
+		objects = append(objects, generatedObject{"HorizontalPodAutoscaler", &hpas.Items[i], c.kubeClient.AutoscalingV1().HorizontalPodAutoscalers(ns).Delete})
+	}
+	scaledObjects, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(ns).List(ctx, opts)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		for i := range scaledObjects.Items {
+			objects = append(objects, generatedObject{"ScaledObject", &scaledObjects.Items[i], c.flaggerClient.KedaV1alpha1().ScaledObjects(ns).Delete})
+		}
+	}
+	spcs, err := c.flaggerClient.SecretsStoreV1().SecretProviderClasses(ns).List(ctx, opts)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		for i := range spcs.Items {
+			objects = append(objects, generatedObject{"SecretProviderClass", &spcs.Items[i], c.flaggerClient.SecretsStoreV1().SecretProviderClasses(ns).Delete})
+		}
+	}
+	return objects, nil
+}
+
+// needsFinalizer returns true if the canary has to be finalized before its deletion,
+// either to revert the target or to delete the objects generated in another namespace
+func needsFinalizer(canary *flaggerv1.Canary) bool {
+	return canary.Spec.RevertOnDeletion || canary.GetTargetNamespace() != canary.Namespace
+}
+
 // hasFinalizer evaluates the finalizers of a given canary for for existence of a provide finalizer string.
 // It returns a boolean, true if the finalizer is found false otherwise.
 func hasFinalizer(canary *flaggerv1.Canary) bool {
@@ -17,10 +17,15 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8sTesting "k8s.io/client-go/testing"
 
@@ -104,3 +109,34 @@ func TestFinalizer_removeFinalizer(t *testing.T) {
 		}
 	}
 }
+
+func TestFinalizer_deleteGeneratedObjects(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	cd := newDeploymentTestCanary()
+	cd.Namespace = "control"
+	cd.Spec.TargetRef.Namespace = "default"
+	require.True(t, needsFinalizer(cd))
+
+	owner := map[string]string{flaggerv1.CanaryOwnerAnnotation: "control/podinfo"}
+	for _, cm := range []*corev1.ConfigMap{
+		{ObjectMeta: metav1.ObjectMeta{Name: "generated", Namespace: "default", Annotations: owner}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"}},
+	} {
+		_, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Create(context.TODO(), cm, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "podinfo-primary", Namespace: "default", Annotations: owner}}
+	_, err := mocks.kubeClient.CoreV1().Services("default").Create(context.TODO(), svc, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, mocks.ctrl.deleteGeneratedObjects(cd))
+
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "generated", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "unrelated", metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	helmv2beta1 "github.com/fluxcd/flagger/pkg/apis/helm/v2beta1"
+	kustomizev1beta2 "github.com/fluxcd/flagger/pkg/apis/kustomize/v1beta2"
+	"github.com/fluxcd/flagger/pkg/notifier"
+)
+
+const (
+	// fluxSuspendedByAnnotation is set on the Flux objects suspended by Flagger with the canary name,
+	// the objects suspended by their users are never resumed by Flagger
+	fluxSuspendedByAnnotation = "flagger.app/suspended-by"
+
+	kustomizeNameLabel      = "kustomize.toolkit.fluxcd.io/name"
+	kustomizeNamespaceLabel = "kustomize.toolkit.fluxcd.io/namespace"
+	helmNameLabel           = "helm.toolkit.fluxcd.io/name"
+	helmNamespaceLabel      = "helm.toolkit.fluxcd.io/namespace"
+)
+
+// fluxOwner is the Flux Kustomization or HelmRelease that applies the canary target
+type fluxOwner struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// analysisRunning returns true for the phases in which Flagger changes the target and the routing
+func analysisRunning(phase flaggerv1.CanaryPhase) bool {
+	switch phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaiting, flaggerv1.CanaryWaitingPromotion,
+		flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		return true
+	}
+	return false
+}
+
+// syncFluxSuspension suspends the Flux owner of the target while the analysis is running
+// and resumes it once the canary is promoted or rolled back
+func (c *Controller) syncFluxSuspension(cd *flaggerv1.Canary) error {
+	if !cd.Spec.SuspendFluxDuringAnalysis {
+		return nil
+	}
+	return c.setFluxSuspended(cd, analysisRunning(cd.Status.Phase))
+}
+
+// setFluxSuspended suspends or resumes the Flux owner of the target,
+// the targets that are not applied by Flux are ignored
+func (c *Controller) setFluxSuspended(cd *flaggerv1.Canary, suspend bool) error {
+	owner, err := c.fluxOwnerOf(cd)
+	if err != nil || owner == nil {
+		return err
+	}
+
+	suspended, suspendedBy, err := c.fluxSuspension(owner)
+	if err != nil {
+		return err
+	}
+
+	canaryName := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+	var patch map[string]interface{}
+	switch {
+	case suspend && !suspended:
+		patch = map[string]interface{}{
+			"metadata": map[string]interface{}{"annotations": map[string]interface{}{fluxSuspendedByAnnotation: canaryName}},
+			"spec":     map[string]interface{}{"suspend": true},
+		}
+	case !suspend && suspended && suspendedBy == canaryName:
+		patch = map[string]interface{}{
+			"metadata": map[string]interface{}{"annotations": map[string]interface{}{fluxSuspendedByAnnotation: nil}},
+			"spec":     map[string]interface{}{"suspend": false},
+		}
+	default:
+		return nil
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("json.Marshal failed: %w", err)
+	}
+	switch owner.Kind {
+	case "HelmRelease":
+		_, err = c.flaggerClient.HelmV2beta1().HelmReleases(owner.Namespace).
+			Patch(context.TODO(), owner.Name, types.MergePatchType, data, metav1.PatchOptions{})
+	default:
+		_, err = c.flaggerClient.KustomizeV1beta2().Kustomizations(owner.Namespace).
+			Patch(context.TODO(), owner.Name, types.MergePatchType, data, metav1.PatchOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s.%s patch error: %w", owner.Kind, owner.Name, owner.Namespace, err)
+	}
+
+	if suspend {
+		c.recordEventInfof(cd, flaggerv1.ReasonFluxSuspended, "%s %s.%s suspended while the analysis is running",
+			owner.Kind, owner.Name, owner.Namespace)
+	} else {
+		c.recordEventInfof(cd, flaggerv1.ReasonFluxResumed, "%s %s.%s resumed", owner.Kind, owner.Name, owner.Namespace)
+	}
+	return nil
+}
+
+// SetFluxEventsURL sets the address of the Flux notification-controller events API,
+// an event about the Flux owner of the target is posted after each promotion
+func (c *Controller) SetFluxEventsURL(url string) {
+	c.fluxEventsURL = url
+}
+
+// postFluxPromotionEvent notifies the notification-controller that the canary target has been promoted,
+// the event is about the Flux owner so that the alerts of the HelmRelease or Kustomization receive it
+func (c *Controller) postFluxPromotionEvent(cd *flaggerv1.Canary) {
+	if c.fluxEventsURL == "" {
+		return
+	}
+
+	log := c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace))
+	owner, err := c.fluxOwnerOf(cd)
+	if err != nil {
+		log.Errorf("Flux promotion event can't be sent: %v", err)
+		return
+	}
+	if owner == nil {
+		return
+	}
+
+	flux, err := notifier.NewFlux(c.fluxEventsURL, "")
+	if err != nil {
+		log.Errorf("Flux promotion event can't be sent: %v", err)
+		return
+	}
+
+	object := corev1.ObjectReference{
+		APIVersion: owner.apiVersion(),
+		Kind:       owner.Kind,
+		Name:       owner.Name,
+		Namespace:  owner.Namespace,
+	}
+	target := fmt.Sprintf("%s/%s", strings.ToLower(cd.Spec.TargetRef.Kind), cd.Spec.TargetRef.Name)
+	metadata := map[string]string{
+		"canary": fmt.Sprintf("%s.%s", cd.Name, cd.Namespace),
+		"target": target,
+	}
+	if images := c.reportImages(cd); len(images) > 0 {
+		metadata["images"] = strings.Join(images, ",")
+	}
+	message := fmt.Sprintf("Canary analysis completed successfully, %s.%s promoted", target, cd.GetTargetNamespace())
+	if err := flux.PostObjectEvent(object, string(flaggerv1.ReasonPromoted), message, metadata); err != nil {
+		log.Errorf("Flux promotion event can't be sent: %v", err)
+	}
+}
+
+// fluxSuspension returns the suspend field of the Flux owner and the canary that suspended it
+func (c *Controller) fluxSuspension(owner *fluxOwner) (bool, string, error) {
+	switch owner.Kind {
+	case "HelmRelease":
+		hr, err := c.flaggerClient.HelmV2beta1().HelmReleases(owner.Namespace).Get(context.TODO(), owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, "", fmt.Errorf("HelmRelease %s.%s get query error: %w", owner.Name, owner.Namespace, err)
+		}
+		return hr.Spec.Suspend, hr.Annotations[fluxSuspendedByAnnotation], nil
+	default:
+		ks, err := c.flaggerClient.KustomizeV1beta2().Kustomizations(owner.Namespace).Get(context.TODO(), owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, "", fmt.Errorf("Kustomization %s.%s get query error: %w", owner.Name, owner.Namespace, err)
+		}
+		return ks.Spec.Suspend, ks.Annotations[fluxSuspendedByAnnotation], nil
+	}
+}
+
+// fluxOwnerOf finds the Flux owner from the labels set by the Flux controllers on the target,
+// the HelmRelease takes precedence as it can be applied by a Kustomization
+func (c *Controller) fluxOwnerOf(cd *flaggerv1.Canary) (*fluxOwner, error) {
+	var labels map[string]string
+	name, namespace := cd.Spec.TargetRef.Name, cd.GetTargetNamespace()
+	switch cd.GetTargetKind() {
+	case "DaemonSet":
+		ds, err := c.kubeClient.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("daemonset %s.%s get query error: %w", name, namespace, err)
+		}
+		labels = ds.Labels
+	case "StatefulSet":
+		sts, err := c.kubeClient.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("statefulset %s.%s get query error: %w", name, namespace, err)
+		}
+		labels = sts.Labels
+	case flaggerv1.KruiseDaemonSetKind:
+		ds, err := c.flaggerClient.KruiseV1alpha1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("daemonset %s.%s get query error: %w", name, namespace, err)
+		}
+		labels = ds.Labels
+	case "Service":
+		svc, err := c.kubeClient.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("service %s.%s get query error: %w", name, namespace, err)
+		}
+		labels = svc.Labels
+	default:
+		dep, err := c.kubeClient.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("deployment %s.%s get query error: %w", name, namespace, err)
+		}
+		labels = dep.Labels
+	}
+
+	if name := labels[helmNameLabel]; name != "" {
+		return &fluxOwner{Kind: "HelmRelease", Name: name, Namespace: labelOrDefault(labels, helmNamespaceLabel, namespace)}, nil
+	}
+	if name := labels[kustomizeNameLabel]; name != "" {
+		return &fluxOwner{Kind: "Kustomization", Name: name, Namespace: labelOrDefault(labels, kustomizeNamespaceLabel, namespace)}, nil
+	}
+	return nil, nil
+}
+
+func (o *fluxOwner) apiVersion() string {
+	if o.Kind == "HelmRelease" {
+		return helmv2beta1.SchemeGroupVersion.String()
+	}
+	return kustomizev1beta2.SchemeGroupVersion.String()
+}
+
+func labelOrDefault(labels map[string]string, key string, value string) string {
+	if v := labels[key]; v != "" {
+		return v
+	}
+	return value
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	helmv2beta1 "github.com/fluxcd/flagger/pkg/apis/helm/v2beta1"
+	kustomizev1beta2 "github.com/fluxcd/flagger/pkg/apis/kustomize/v1beta2"
+	"github.com/fluxcd/flagger/pkg/notifier"
+)
+
+func TestController_FluxSuspension(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	canary.Spec.SuspendFluxDuringAnalysis = true
+	mocks := newDeploymentFixture(canary)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Labels = map[string]string{kustomizeNameLabel: "apps", kustomizeNamespaceLabel: "flux-system"}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, err = mocks.flaggerClient.KustomizeV1beta2().Kustomizations("flux-system").Create(context.TODO(), &kustomizev1beta2.Kustomization{
+		ObjectMeta: metav1.ObjectMeta{Name: "apps", Namespace: "flux-system"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	getKustomization := func() *kustomizev1beta2.Kustomization {
+		ks, err := mocks.flaggerClient.KustomizeV1beta2().Kustomizations("flux-system").Get(context.TODO(), "apps", metav1.GetOptions{})
+		require.NoError(t, err)
+		return ks
+	}
+
+	// the Kustomization is suspended while the analysis is running
+	canary.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	require.NoError(t, mocks.ctrl.syncFluxSuspension(canary))
+	ks := getKustomization()
+	assert.True(t, ks.Spec.Suspend)
+	assert.Equal(t, "podinfo.default", ks.Annotations[fluxSuspendedByAnnotation])
+
+	// and resumed when the canary is promoted
+	canary.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	require.NoError(t, mocks.ctrl.syncFluxSuspension(canary))
+	ks = getKustomization()
+	assert.False(t, ks.Spec.Suspend)
+	assert.NotContains(t, ks.Annotations, fluxSuspendedByAnnotation)
+
+	// the objects suspended by their users are not resumed
+	ks.Spec.Suspend = true
+	_, err = mocks.flaggerClient.KustomizeV1beta2().Kustomizations("flux-system").Update(context.TODO(), ks, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.syncFluxSuspension(canary))
+	assert.True(t, getKustomization().Spec.Suspend)
+}
+
+func TestController_FluxOwner(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	mocks := newDeploymentFixture(canary)
+
+	owner, err := mocks.ctrl.fluxOwnerOf(canary)
+	require.NoError(t, err)
+	assert.Nil(t, owner)
+
+	// the HelmRelease takes precedence over the Kustomization that applied it
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Labels = map[string]string{kustomizeNameLabel: "apps", helmNameLabel: "podinfo"}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	owner, err = mocks.ctrl.fluxOwnerOf(canary)
+	require.NoError(t, err)
+	assert.Equal(t, &fluxOwner{Kind: "HelmRelease", Name: "podinfo", Namespace: "default"}, owner)
+
+	_, err = mocks.flaggerClient.HelmV2beta1().HelmReleases("default").Create(context.TODO(), &helmv2beta1.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.setFluxSuspended(canary, true))
+	hr, err := mocks.flaggerClient.HelmV2beta1().HelmReleases("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, hr.Spec.Suspend)
+}
+
+func TestController_FluxPromotionEvent(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	mocks := newDeploymentFixture(canary)
+
+	events := make(chan notifier.FluxEvent, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var event notifier.FluxEvent
+		require.NoError(t, json.Unmarshal(b, &event))
+		events <- event
+	}))
+	defer ts.Close()
+	mocks.ctrl.SetFluxEventsURL(ts.URL)
+
+	// the targets that are not applied by Flux are ignored
+	mocks.ctrl.postFluxPromotionEvent(canary)
+	assert.Len(t, events, 0)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Labels = map[string]string{helmNameLabel: "podinfo", helmNamespaceLabel: "flux-system"}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.postFluxPromotionEvent(canary)
+	require.Len(t, events, 1)
+	event := <-events
+	assert.Equal(t, "helm.toolkit.fluxcd.io/v2beta1", event.InvolvedObject.APIVersion)
+	assert.Equal(t, "HelmRelease", event.InvolvedObject.Kind)
+	assert.Equal(t, "podinfo", event.InvolvedObject.Name)
+	assert.Equal(t, "flux-system", event.InvolvedObject.Namespace)
+	assert.Equal(t, string(flaggerv1.ReasonPromoted), event.Reason)
+	assert.Equal(t, "podinfo.default", event.Metadata["canary"])
+	assert.Equal(t, "deployment/podinfo", event.Metadata["target"])
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// gateAnnotation stores the state of the canary gate on the Canary object,
+// so that the gate is shared by the controller replicas and survives restarts
+const gateAnnotation = "flagger.app/gate"
+
+// gateApproverAnnotation stores the identity of the caller that opened the gate with the API
+const gateApproverAnnotation = "flagger.app/gate-approver"
+
+const (
+	gateOpen   = "open"
+	gateClosed = "closed"
+)
+
+// isGateOpen returns true if the gate of the canary has been opened,
+// the approver is the caller of the gate API or the field manager that set the gate annotation
+func (c *Controller) isGateOpen(name string, namespace string) (bool, string, flaggerv1.ApprovalSource, error) {
+	cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return false, "", "", fmt.Errorf("canary %s.%s get query failed: %w", name, namespace, err)
+	}
+	if cd.GetAnnotations()[gateAnnotation] != gateOpen {
+		return false, "", "", nil
+	}
+	if approver := cd.GetAnnotations()[gateApproverAnnotation]; approver != "" {
+		return true, approver, flaggerv1.ApprovalSourceAPI, nil
+	}
+	return true, annotationManager(cd.GetManagedFields(), gateAnnotation), flaggerv1.ApprovalSourceAnnotation, nil
+}
+
+// annotationManager returns the field manager that last set the annotation
+func annotationManager(entries []metav1.ManagedFieldsEntry, annotation string) string {
+	manager := ""
+	var last time.Time
+	for _, entry := range entries {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		var fields struct {
+			Metadata struct {
+				Annotations map[string]interface{} `json:"f:annotations"`
+			} `json:"f:metadata"`
+		}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		if _, ok := fields.Metadata.Annotations["f:"+annotation]; !ok {
+			continue
+		}
+		if entry.Time == nil || manager == "" || entry.Time.Time.After(last) {
+			manager = entry.Manager
+			if entry.Time != nil {
+				last = entry.Time.Time
+			}
+		}
+	}
+	return manager
+}
+
+// apiTokenIdentity is the approver recorded for the callers authenticated with the API token
+const apiTokenIdentity = "api-token"
+
+// gateSecretTokenKey is the key of the canary gate secret that holds the token
+const gateSecretTokenKey = "token"
+
+// SetGateToken sets the token accepted by the gates of every canary
+func (c *Controller) SetGateToken(token string) {
+	c.gateToken = token
+}
+
+// authenticateGate returns the identity of the bearer token presented for the gate of the canary,
+// the API token is accepted for every canary and the token of the canary gate secret only for that canary,
+// the identity is empty when the token isn't accepted
+func (c *Controller) authenticateGate(r *http.Request, payload *flaggerv1.CanaryWebhookPayload) (string, error) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return "", nil
+	}
+	token := []byte(strings.TrimPrefix(auth, "Bearer "))
+	if c.gateToken != "" && subtle.ConstantTimeCompare(token, []byte(c.gateToken)) == 1 {
+		return apiTokenIdentity, nil
+	}
+
+	// the callers without a valid token can't tell which canaries exist
+	cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(payload.Namespace).Get(context.TODO(), payload.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("canary %s.%s get query failed: %w", payload.Name, payload.Namespace, err)
+	}
+	if cd.Spec.GateSecretRef == nil {
+		return "", nil
+	}
+
+	secret, err := c.kubeClient.CoreV1().Secrets(cd.Namespace).Get(context.TODO(), cd.Spec.GateSecretRef.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Warnf("Gate secret %s not found", cd.Spec.GateSecretRef.Name)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("gate secret %s.%s get query failed: %w", cd.Spec.GateSecretRef.Name, cd.Namespace, err)
+	}
+	expected := secret.Data[gateSecretTokenKey]
+	if len(expected) == 0 || subtle.ConstantTimeCompare(token, expected) != 1 {
+		return "", nil
+	}
+	return fmt.Sprintf("secret/%s/%s", cd.Namespace, cd.Spec.GateSecretRef.Name), nil
+}
+
+// gateApprover returns the identity of the credential that authenticated the caller of the gate API,
+// the user names sent by the client can't be verified and are only recorded as a claim
+func gateApprover(identity string, r *http.Request, payload *flaggerv1.CanaryWebhookPayload) string {
+	return flaggerv1.ApproverWithClaim(identity, approverClaim(r, payload))
+}
+
+// approverClaim returns the user name sent in the proxy headers or in the payload metadata
+func approverClaim(r *http.Request, payload *flaggerv1.CanaryWebhookPayload) string {
+	for _, header := range []string{"X-Remote-User", "X-Forwarded-User"} {
+		if user := r.Header.Get(header); user != "" {
+			return user
+		}
+	}
+	return payload.Metadata["approver"]
+}
+
+// setGate opens or closes the gate of the canary,
+// the approver is removed when the gate is closed
+func (c *Controller) setGate(name string, namespace string, open bool, approver string) error {
+	state := gateClosed
+	if open {
+		state = gateOpen
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		cdCopy := cd.DeepCopy()
+		if cdCopy.Annotations == nil {
+			cdCopy.Annotations = make(map[string]string)
+		}
+		cdCopy.Annotations[gateAnnotation] = state
+		if open && approver != "" {
+			cdCopy.Annotations[gateApproverAnnotation] = approver
+		} else {
+			delete(cdCopy.Annotations, gateApproverAnnotation)
+		}
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Update(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("setting gate of canary %s.%s failed: %w", name, namespace, err)
+	}
+	return nil
+}
+
+// GateCheckHandler serves the confirm webhooks of the canaries,
+// it responds with 200 when the gate is open and 403 when the gate is closed
+func (c *Controller) GateCheckHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, ok := c.decodeGatePayload(w, r)
+		if !ok {
+			return
+		}
+
+		approved, approver, source, err := c.isGateOpen(payload.Name, payload.Namespace)
+		if err != nil {
+			c.writeGateError(w, payload, err)
+			return
+		}
+
+		if approved {
+			if approver != "" {
+				w.Header().Set(flaggerv1.ApproverHeader, approver)
+				w.Header().Set(flaggerv1.ApprovalSourceHeader, string(source))
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("Approved"))
+		} else {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Forbidden"))
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", payload.Name, payload.Namespace)).
+			Debugf("Gate check: approved %v", approved)
+	})
+}
+
+// GateOpenHandler opens the gate of the canary specified in the request body
+func (c *Controller) GateOpenHandler() http.Handler {
+	return c.gateHandler(true)
+}
+
+// GateCloseHandler closes the gate of the canary specified in the request body
+func (c *Controller) GateCloseHandler() http.Handler {
+	return c.gateHandler(false)
+}
+
+func (c *Controller) gateHandler(open bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, ok := c.decodeGatePayload(w, r)
+		if !ok {
+			return
+		}
+
+		identity, err := c.authenticateGate(r, payload)
+		if err != nil {
+			c.writeGateError(w, payload, err)
+			return
+		}
+		if identity == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="flagger"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		approver := gateApprover(identity, r, payload)
+		if err := c.setGate(payload.Name, payload.Namespace, open, approver); err != nil {
+			c.writeGateError(w, payload, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		state := gateClosed
+		if open {
+			state = gateOpen
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", payload.Name, payload.Namespace)).
+			Infof("Gate %s by %q", state, approver)
+	})
+}
+
+// decodeGatePayload reads the canary name and namespace from the request body,
+// the body has the same schema as the webhook payload
+func (c *Controller) decodeGatePayload(w http.ResponseWriter, r *http.Request) (*flaggerv1.CanaryWebhookPayload, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	defer r.Body.Close()
+
+	payload := &flaggerv1.CanaryWebhookPayload{}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		http.Error(w, fmt.Sprintf("decoding the request body failed: %v", err), http.StatusBadRequest)
+		return nil, false
+	}
+	if payload.Name == "" || payload.Namespace == "" {
+		http.Error(w, "name and namespace are required", http.StatusBadRequest)
+		return nil, false
+	}
+	return payload, true
+}
+
+func (c *Controller) writeGateError(w http.ResponseWriter, payload *flaggerv1.CanaryWebhookPayload, err error) {
+	if errors.IsNotFound(err) {
+		http.Error(w, fmt.Sprintf("canary %s.%s not found", payload.Name, payload.Namespace), http.StatusNotFound)
+		return
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", payload.Name, payload.Namespace)).Errorf("%v", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const testGateToken = "test-token"
+
+func newGateRequest(t *testing.T, name string, namespace string) *http.Request {
+	b, err := json.Marshal(flaggerv1.CanaryWebhookPayload{Name: name, Namespace: namespace})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/gate", bytes.NewReader(b))
+	req.Header.Set("Authorization", "Bearer "+testGateToken)
+	return req
+}
+
+func TestController_Gates(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.SetGateToken(testGateToken)
+
+	check := func() int {
+		rec := httptest.NewRecorder()
+		mocks.ctrl.GateCheckHandler().ServeHTTP(rec, newGateRequest(t, "podinfo", "default"))
+		return rec.Code
+	}
+
+	// closed by default
+	assert.Equal(t, http.StatusForbidden, check())
+
+	rec := httptest.NewRecorder()
+	mocks.ctrl.GateOpenHandler().ServeHTTP(rec, newGateRequest(t, "podinfo", "default"))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, http.StatusOK, check())
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, gateOpen, cd.Annotations[gateAnnotation])
+
+	rec = httptest.NewRecorder()
+	mocks.ctrl.GateCloseHandler().ServeHTTP(rec, newGateRequest(t, "podinfo", "default"))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, http.StatusForbidden, check())
+}
+
+func TestController_GatesErrors(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.SetGateToken(testGateToken)
+
+	rec := httptest.NewRecorder()
+	mocks.ctrl.GateOpenHandler().ServeHTTP(rec, newGateRequest(t, "unknown", "default"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	mocks.ctrl.GateCheckHandler().ServeHTTP(rec, newGateRequest(t, "podinfo", ""))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	mocks.ctrl.GateOpenHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/gate/open", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestController_GateApprover(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.SetGateToken(testGateToken)
+
+	check := func() http.Header {
+		rec := httptest.NewRecorder()
+		mocks.ctrl.GateCheckHandler().ServeHTTP(rec, newGateRequest(t, "podinfo", "default"))
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Header()
+	}
+
+	// the user name sent by the client is recorded as an unverified claim
+	req := newGateRequest(t, "podinfo", "default")
+	req.Header.Set("X-Remote-User", "alice")
+	rec := httptest.NewRecorder()
+	mocks.ctrl.GateOpenHandler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	header := check()
+	assert.Equal(t, "api-token (unverified: alice)", header.Get(flaggerv1.ApproverHeader))
+	assert.Equal(t, string(flaggerv1.ApprovalSourceAPI), header.Get(flaggerv1.ApprovalSourceHeader))
+
+	// the approver is removed when the gate is closed
+	rec = httptest.NewRecorder()
+	mocks.ctrl.GateCloseHandler().ServeHTTP(rec, newGateRequest(t, "podinfo", "default"))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	// the gate annotation is set with kubectl
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, cd.Annotations, gateApproverAnnotation)
+	cd.Annotations[gateAnnotation] = gateOpen
+	cd.ManagedFields = []metav1.ManagedFieldsEntry{
+		{
+			Manager:  "flagger",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:phase":{}}}`)},
+		},
+		{
+			Manager:  "kubectl-annotate",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:flagger.app/gate":{}}}}`)},
+		},
+	}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	header = check()
+	assert.Equal(t, "kubectl-annotate", header.Get(flaggerv1.ApproverHeader))
+	assert.Equal(t, string(flaggerv1.ApprovalSourceAnnotation), header.Get(flaggerv1.ApprovalSourceHeader))
+}
+
+func TestController_GateSecret(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.SetGateToken(testGateToken)
+
+	_, err := mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo-gate", Namespace: "default"},
+		Data:       map[string][]byte{gateSecretTokenKey: []byte("podinfo-token")},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Spec.GateSecretRef = &corev1.LocalObjectReference{Name: "podinfo-gate"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	other := newDeploymentTestCanary()
+	other.Name = "other"
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), other, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	open := func(name string, token string) *httptest.ResponseRecorder {
+		req := newGateRequest(t, name, "default")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		mocks.ctrl.GateOpenHandler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	// the canary token opens the gate of its canary
+	require.Equal(t, http.StatusAccepted, open("podinfo", "podinfo-token").Code)
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, gateOpen, cd.Annotations[gateAnnotation])
+	assert.Equal(t, "secret/default/podinfo-gate", cd.Annotations[gateApproverAnnotation])
+
+	// the canary token isn't accepted for the other canaries
+	rec := open("other", "podinfo-token")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, http.StatusUnauthorized, open("podinfo", "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, open("unknown", "podinfo-token").Code)
+
+	// the API token is accepted for every canary
+	assert.Equal(t, http.StatusAccepted, open("other", testGateToken).Code)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// historyReasons are the events kept in the canary status history,
+// the phase changes, the rollbacks and the operator actions
+var historyReasons = map[flaggerv1.EventReason]bool{
+	flaggerv1.ReasonInitialized:                  true,
+	flaggerv1.ReasonNewRevisionDetected:          true,
+	flaggerv1.ReasonAnalysisSkipped:              true,
+	flaggerv1.ReasonPromoted:                     true,
+	flaggerv1.ReasonFailed:                       true,
+	flaggerv1.ReasonDetached:                     true,
+	flaggerv1.ReasonSuspended:                    true,
+	flaggerv1.ReasonResumed:                      true,
+	flaggerv1.ReasonProgressDeadlineExceeded:     true,
+	flaggerv1.ReasonFailedChecksThresholdReached: true,
+	flaggerv1.ReasonManualRollback:               true,
+	flaggerv1.ReasonStepSkipped:                  true,
+	flaggerv1.ReasonPromotionForced:              true,
+	flaggerv1.ReasonAborted:                      true,
+	flaggerv1.ReasonRerunRequested:               true,
+	flaggerv1.ReasonApproved:                     true,
+	flaggerv1.ReasonPolicyViolation:              true,
+	flaggerv1.ReasonMaxDurationExceeded:          true,
+}
+
+// SetStatusHistoryLimit sets the number of significant events kept in the canary status
+func (c *Controller) SetStatusHistoryLimit(limit int) {
+	c.statusHistoryLimit = limit
+}
+
+// recordHistory adds a significant event to the results of the analysis iteration in progress,
+// the history is written to the canary status with the check results
+func (c *Controller) recordHistory(cd *flaggerv1.Canary, e Event) {
+	if c.statusHistoryLimit <= 0 || !historyReasons[e.Reason] {
+		return
+	}
+
+	results := c.pendingResults(cd)
+	results.history = append(results.history, flaggerv1.CanaryHistoryEntry{
+		Type:    e.Type,
+		Reason:  e.Reason,
+		Message: e.Message,
+		RunID:   cd.Status.RunID,
+		Time:    metav1.Now(),
+	})
+}
+
+// appendHistory adds the entries to the history and removes the oldest ones over the limit,
+// an entry that repeats the last one is skipped
+func appendHistory(history []flaggerv1.CanaryHistoryEntry, entries []flaggerv1.CanaryHistoryEntry, limit int) []flaggerv1.CanaryHistoryEntry {
+	for _, entry := range entries {
+		if n := len(history); n > 0 && history[n-1].Reason == entry.Reason &&
+			history[n-1].Message == entry.Message && history[n-1].RunID == entry.RunID {
+			continue
+		}
+		history = append(history, entry)
+	}
+	if len(history) > limit {
+		history = append([]flaggerv1.CanaryHistoryEntry(nil), history[len(history)-limit:]...)
+	}
+	return history
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestAppendHistory(t *testing.T) {
+	entry := func(reason flaggerv1.EventReason, message string) flaggerv1.CanaryHistoryEntry {
+		return flaggerv1.CanaryHistoryEntry{Type: corev1.EventTypeNormal, Reason: reason, Message: message, RunID: "1"}
+	}
+
+	history := appendHistory(nil, []flaggerv1.CanaryHistoryEntry{
+		entry(flaggerv1.ReasonInitialized, "initialized"),
+		entry(flaggerv1.ReasonNewRevisionDetected, "new revision"),
+		entry(flaggerv1.ReasonNewRevisionDetected, "new revision"),
+	}, 3)
+	require.Len(t, history, 2)
+
+	history = appendHistory(history, []flaggerv1.CanaryHistoryEntry{
+		entry(flaggerv1.ReasonApproved, "approved"),
+		entry(flaggerv1.ReasonPromoted, "promoted"),
+	}, 3)
+	require.Len(t, history, 3)
+	assert.Equal(t, flaggerv1.ReasonNewRevisionDetected, history[0].Reason)
+	assert.Equal(t, flaggerv1.ReasonPromoted, history[2].Reason)
+}
+
+func TestScheduler_StatusHistory(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.SetStatusHistoryLimit(10)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.Status.History, 2)
+	assert.Equal(t, flaggerv1.ReasonInitialized, c.Status.History[0].Reason)
+	assert.Equal(t, flaggerv1.ReasonNewRevisionDetected, c.Status.History[1].Reason)
+	assert.Equal(t, corev1.EventTypeNormal, c.Status.History[1].Type)
+	assert.Equal(t, c.Status.RunID, c.Status.History[1].RunID)
+	assert.False(t, c.Status.History[1].Time.IsZero())
+}
+
+func TestScheduler_StatusHistoryDisabled(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, c.Status.History)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// skipAnalysisOnceAnnotation requests the scheduler to promote the revision without analysis
+// if one of its images matches the value, an image digest such as sha256:<hex> or a full image reference
+const skipAnalysisOnceAnnotation = "flagger.app/skip-analysis-once"
+
+// matchesImage returns true if the image is the reference or has the digest of the annotation value
+func matchesImage(image string, value string) bool {
+	return image == value || strings.HasSuffix(image, "@"+value)
+}
+
+// takeSkipAnalysisOnce consumes the skip once annotation if the canary revision matches it,
+// it returns the matched image and the field manager that set the annotation,
+// the annotation is kept for a later revision when the images don't match
+func (c *Controller) takeSkipAnalysisOnce(cd *flaggerv1.Canary) (string, string, bool) {
+	value := strings.TrimSpace(cd.GetAnnotations()[skipAnalysisOnceAnnotation])
+	if value == "" || !acceptsAction(cd, skipAnnotation) {
+		return "", "", false
+	}
+
+	matched := ""
+	for _, image := range c.reportImages(cd) {
+		if matchesImage(image, value) {
+			matched = image
+			break
+		}
+	}
+	if matched == "" {
+		return "", "", false
+	}
+
+	manager := annotationManager(cd.GetManagedFields(), skipAnalysisOnceAnnotation)
+	if _, err := c.takeAction(cd, skipAnalysisOnceAnnotation); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return "", "", false
+	}
+	return matched, manager, true
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/router"
+	"github.com/fluxcd/flagger/pkg/tracing"
+)
+
+// iterationTrace holds the context of the analysis iteration in progress
+// and the span context of the last iteration of a canary
+type iterationTrace struct {
+	ctx         context.Context
+	spanContext trace.SpanContext
+}
+
+// startIterationSpan starts the root span of an analysis iteration,
+// the span is linked to the previous iteration of the same canary
+func (c *Controller) startIterationSpan(name string, namespace string) trace.Span {
+	key := fmt.Sprintf("%s.%s", name, namespace)
+	opts := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithAttributes(
+			attribute.String("canary.name", name),
+			attribute.String("canary.namespace", namespace),
+		),
+	}
+	if v, ok := c.traces.Load(key); ok {
+		if prev := v.(iterationTrace).spanContext; prev.IsValid() {
+			opts = append(opts, trace.WithLinks(trace.Link{SpanContext: prev}))
+		}
+	}
+
+	ctx, span := tracing.Tracer().Start(context.Background(), "canary.analysis", opts...)
+	c.traces.Store(key, iterationTrace{ctx: ctx, spanContext: span.SpanContext()})
+	return span
+}
+
+// startSpan starts a child span of the analysis iteration in progress
+func (c *Controller) startSpan(cd *flaggerv1.Canary, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	parent := context.Background()
+	if v, ok := c.traces.Load(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)); ok {
+		parent = v.(iterationTrace).ctx
+	}
+	return tracing.Tracer().Start(parent, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the error if any and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// callWebhook calls the webhook within a span of the analysis iteration in progress
+// and records the call duration and result
+func (c *Controller) callWebhook(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) error {
+	_, err := c.callWebhookResponse(cd, phase, w)
+	return err
+}
+
+// callWebhookResponse calls the webhook like callWebhook and returns its response,
+// the response of a failed call is returned when the webhook replied
+func (c *Controller) callWebhookResponse(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) (*webhookResponse, error) {
+	begin := time.Now()
+	ctx, span := c.startSpan(cd, "webhook",
+		attribute.String("webhook.name", w.Name),
+		attribute.String("webhook.type", string(w.Type)),
+		attribute.String("webhook.url", w.URL),
+	)
+	var resp *webhookResponse
+	secrets, err := loadWebhookSecrets(c.kubeClient, cd.Namespace, w)
+	if err == nil {
+		resp, err = callCanaryWebhook(ctx, cd, phase, w, secrets)
+	}
+	endSpan(span, err)
+	c.recorder.SetWebhookDuration(w, time.Since(begin))
+	c.recordWebhookResult(cd, w, resp, err)
+	return resp, err
+}
+
+// instrumentMetricQuery starts a span for a metric query and returns the function
+// that ends the span, records the query duration, the last metric value and the check result
+func (c *Controller) instrumentMetricQuery(cd *flaggerv1.Canary, metric flaggerv1.CanaryMetric, provider string) func(float64, error) {
+	begin := time.Now()
+	_, span := c.startSpan(cd, "metric.query",
+		attribute.String("metric.name", metric.Name),
+		attribute.String("metric.provider", provider),
+	)
+	return func(val float64, err error) {
+		endSpan(span, err)
+		c.recorder.SetMetricQueryDuration(provider, metric.Name, time.Since(begin))
+		if err != nil {
+			c.recordMetricResult(cd, metric, nil, err)
+			return
+		}
+		c.setMetricValue(cd, metric.Name, val)
+		c.recordMetricResult(cd, metric, &val, nil)
+	}
+}
+
+// instrumentedRouter records a span for each call made to a mesh router,
+// the duration of the reconciliation and the weight changes of the timeline
+type instrumentedRouter struct {
+	router.Interface
+	ctrl     *Controller
+	provider string
+}
+
+func (r *instrumentedRouter) Reconcile(cd *flaggerv1.Canary) error {
+	begin := time.Now()
+	_, span := r.ctrl.startSpan(cd, "router.reconcile", attribute.String("router.provider", r.provider))
+	err := r.Interface.Reconcile(cd)
+	endSpan(span, err)
+	r.ctrl.recorder.SetRouterReconcileDuration(r.provider, time.Since(begin))
+	return err
+}
+
+func (r *instrumentedRouter) SetRoutes(cd *flaggerv1.Canary, primaryWeight int, canaryWeight int, mirrored bool) error {
+	_, span := r.ctrl.startSpan(cd, "router.setRoutes",
+		attribute.String("router.provider", r.provider),
+		attribute.Int("router.primaryWeight", primaryWeight),
+		attribute.Int("router.canaryWeight", canaryWeight),
+		attribute.Bool("router.mirrored", mirrored),
+	)
+	err := r.Interface.SetRoutes(cd, primaryWeight, canaryWeight, mirrored)
+	endSpan(span, err)
+	if err == nil {
+		r.ctrl.recordTimelineWeights(cd, primaryWeight, canaryWeight)
+	}
+	return err
+}
+
+func (r *instrumentedRouter) GetRoutes(cd *flaggerv1.Canary) (int, int, bool, error) {
+	_, span := r.ctrl.startSpan(cd, "router.getRoutes", attribute.String("router.provider", r.provider))
+	primaryWeight, canaryWeight, mirrored, err := r.Interface.GetRoutes(cd)
+	endSpan(span, err)
+	return primaryWeight, canaryWeight, mirrored, err
+}
@@ -24,6 +24,7 @@ type CanaryJob struct {
 	Namespace        string
 	function         func(name string, namespace string)
 	done             chan bool
+	trigger          chan struct{}
 	ticker           *time.Ticker
 	analysisInterval time.Duration
 }
@@ -37,6 +38,8 @@ func (j CanaryJob) Start() {
 			select {
 			case <-j.ticker.C:
 				j.function(j.Name, j.Namespace)
+			case <-j.trigger:
+				j.function(j.Name, j.Namespace)
 			case <-j.done:
 				return
 			}
@@ -44,6 +47,15 @@ func (j CanaryJob) Start() {
 	}()
 }
 
+// Trigger runs the canary analysis without waiting for the next tick,
+// the call doesn't block if a run is already pending
+func (j CanaryJob) Trigger() {
+	select {
+	case j.trigger <- struct{}{}:
+	default:
+	}
+}
+
 // Stop closes the job channel and stops the ticker
 func (j CanaryJob) Stop() {
 	close(j.done)
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// namespaceFilter selects the namespaces of the canaries that Flagger acts on
+type namespaceFilter struct {
+	include  []string
+	exclude  []string
+	selector labels.Selector
+}
+
+// SetNamespaceFilter limits the namespaces of the canaries that Flagger acts on,
+// an excluded namespace is never processed even if it's included or matches the selector,
+// the selector is matched against the namespace labels
+func (c *Controller) SetNamespaceFilter(include []string, exclude []string, selector string) error {
+	filter := &namespaceFilter{include: include, exclude: exclude}
+	if selector != "" {
+		s, err := labels.Parse(selector)
+		if err != nil {
+			return fmt.Errorf("namespace selector %s parse error: %w", selector, err)
+		}
+		filter.selector = s
+	}
+	for _, ns := range include {
+		if containsString(exclude, ns) {
+			return fmt.Errorf("namespace %s can't be both included and excluded", ns)
+		}
+	}
+	c.namespaceFilter = filter
+	return nil
+}
+
+// namespaceAllowed returns false when the namespace is excluded,
+// not included or its labels don't match the selector
+func (c *Controller) namespaceAllowed(namespace string) (bool, error) {
+	f := c.namespaceFilter
+	if f == nil {
+		return true, nil
+	}
+	if containsString(f.exclude, namespace) {
+		return false, nil
+	}
+	if len(f.include) > 0 && !containsString(f.include, namespace) {
+		return false, nil
+	}
+	if f.selector == nil {
+		return true, nil
+	}
+	ns, err := c.kubeClient.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("namespace %s get query error: %w", namespace, err)
+	}
+	return f.selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// SetCrossNamespaceTargets allows the canaries to target the workloads of other namespaces
+func (c *Controller) SetCrossNamespaceTargets(enabled bool) {
+	c.crossNamespaceTargets = enabled
+}
+
+// checkTargetNamespace returns an error when the canary targets a workload of another namespace
+// and the cross-namespace targets are disabled or not supported with the canary spec,
+// the generated objects are in the target namespace so only the Kubernetes provider can route the traffic
+func (c *Controller) checkTargetNamespace(cd *flaggerv1.Canary, provider string) error {
+	namespace := cd.GetTargetNamespace()
+	if namespace == cd.Namespace {
+		return nil
+	}
+	if !c.crossNamespaceTargets {
+		return fmt.Errorf("target namespace %s is not the canary namespace, cross-namespace targets are disabled", namespace)
+	}
+	if allowed, err := c.namespaceAllowed(namespace); err != nil {
+		return err
+	} else if !allowed {
+		return fmt.Errorf("target namespace %s is excluded by the namespace filter", namespace)
+	}
+	if providerName(provider) != flaggerv1.KubernetesProvider {
+		return fmt.Errorf("cross-namespace targets require the %s provider", flaggerv1.KubernetesProvider)
+	}
+	switch cd.GetTargetKind() {
+	case "", "Deployment", "DaemonSet", "StatefulSet":
+	default:
+		return fmt.Errorf("cross-namespace targets can't be of kind %s", cd.Spec.TargetRef.Kind)
+	}
+	if cd.Spec.TargetCluster != nil || len(cd.Spec.RemoteClusters) > 0 || len(cd.Spec.TargetRefs) > 0 || cd.Spec.Propagation != nil {
+		return fmt.Errorf("cross-namespace targets can't be used with target clusters, remote clusters, targetRefs or propagation")
+	}
+	return nil
+}
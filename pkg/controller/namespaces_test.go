@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_SetNamespaceFilter(t *testing.T) {
+	ctrl := &Controller{}
+	require.Error(t, ctrl.SetNamespaceFilter(nil, nil, "env in (dev"))
+	require.Error(t, ctrl.SetNamespaceFilter([]string{"test"}, []string{"test"}, ""))
+
+	require.NoError(t, ctrl.SetNamespaceFilter([]string{"test"}, nil, ""))
+	ok, err := ctrl.namespaceAllowed("test")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = ctrl.namespaceAllowed("default")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, ctrl.SetNamespaceFilter(nil, []string{"kube-system"}, ""))
+	ok, err = ctrl.namespaceAllowed("kube-system")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = ctrl.namespaceAllowed("default")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestController_NamespaceSelector(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	_, err := mocks.kubeClient.CoreV1().Namespaces().Create(context.TODO(), ns, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.SetNamespaceFilter(nil, nil, "flagger.app/enabled=true"))
+
+	// the canary isn't synced when the namespace doesn't match
+	require.NoError(t, mocks.ctrl.syncHandler("default/podinfo"))
+	_, ok := mocks.ctrl.canaries.Load("podinfo.default")
+	assert.False(t, ok)
+
+	// the analysis is held
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, c.Status.Phase)
+
+	ns.Labels = map[string]string{"flagger.app/enabled": "true"}
+	_, err = mocks.kubeClient.CoreV1().Namespaces().Update(context.TODO(), ns, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, mocks.ctrl.syncHandler("default/podinfo"))
+	_, ok = mocks.ctrl.canaries.Load("podinfo.default")
+	assert.True(t, ok)
+}
+
+func TestController_CheckTargetNamespace(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "control"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{Kind: "Deployment", Name: "podinfo"},
+		},
+	}
+	ctrl := &Controller{}
+	require.NoError(t, ctrl.checkTargetNamespace(cd, "istio"))
+
+	// the cross-namespace targets are opt-in
+	cd.Spec.TargetRef.Namespace = "test"
+	require.Error(t, ctrl.checkTargetNamespace(cd, flaggerv1.KubernetesProvider))
+
+	ctrl.SetCrossNamespaceTargets(true)
+	require.NoError(t, ctrl.checkTargetNamespace(cd, flaggerv1.KubernetesProvider))
+	require.Error(t, ctrl.checkTargetNamespace(cd, "istio"))
+
+	require.NoError(t, ctrl.SetNamespaceFilter(nil, []string{"test"}, ""))
+	require.Error(t, ctrl.checkTargetNamespace(cd, flaggerv1.KubernetesProvider))
+	require.NoError(t, ctrl.SetNamespaceFilter(nil, nil, ""))
+
+	cd.Spec.TargetRefs = []flaggerv1.CrossNamespaceObjectReference{{Kind: "Deployment", Name: "sidecar"}}
+	require.Error(t, ctrl.checkTargetNamespace(cd, flaggerv1.KubernetesProvider))
+	cd.Spec.TargetRefs = nil
+
+	cd.Spec.TargetRef.Kind = "Service"
+	require.Error(t, ctrl.checkTargetNamespace(cd, flaggerv1.KubernetesProvider))
+}
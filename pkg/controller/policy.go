@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// PolicyViolation is returned when the canary pod spec is denied by a policy
+type PolicyViolation struct {
+	Policy   string
+	Messages []string
+}
+
+func (v *PolicyViolation) Error() string {
+	return fmt.Sprintf("policy %s violation: %s", v.Policy, strings.Join(v.Messages, "; "))
+}
+
+// regoPackage matches the package declaration of a Rego module
+var regoPackage = regexp.MustCompile(`(?m)^\s*package\s+([A-Za-z0-9_.]+)`)
+
+// runPolicyChecks evaluates the canary pod spec against the analysis policies,
+// a *PolicyViolation is returned when the pod is denied
+func (c *Controller) runPolicyChecks(cd *flaggerv1.Canary) error {
+	policies := cd.GetAnalysis().Policies
+	if len(policies) == 0 {
+		return nil
+	}
+
+	pod, err := c.canaryPod(cd)
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		timeout := 10 * time.Second
+		if policy.Timeout != "" {
+			timeout, err = time.ParseDuration(policy.Timeout)
+			if err != nil {
+				return fmt.Errorf("policy %s timeout error: %w", policy.Name, err)
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		switch policy.Provider {
+		case flaggerv1.AdmissionPolicyProvider, "":
+			err = c.checkAdmissionPolicy(ctx, policy, pod)
+		case flaggerv1.OPAPolicyProvider:
+			err = checkOPAPolicy(ctx, cd, policy, pod)
+		default:
+			err = fmt.Errorf("policy %s provider %s not supported", policy.Name, policy.Provider)
+		}
+		cancel()
+		if err != nil {
+			return err
+		}
+		c.recordEventInfof(cd, flaggerv1.ReasonPolicyPassed, "Policy check %s passed", policy.Name)
+	}
+	return nil
+}
+
+// canaryPod builds the pod of the canary target pod template
+func (c *Controller) canaryPod(cd *flaggerv1.Canary) (*corev1.Pod, error) {
+	template, err := c.podTemplate(cd.GetTargetKind(), cd.Spec.TargetRef.Name, cd.GetTargetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: cd.Spec.TargetRef.Name + "-",
+			Namespace:    cd.GetTargetNamespace(),
+			Labels:       template.Labels,
+			Annotations:  template.Annotations,
+		},
+		Spec: template.Spec,
+	}, nil
+}
+
+// podTemplate returns the pod template of a deployment, daemonset or statefulset
+func (c *Controller) podTemplate(kind string, name string, namespace string) (*corev1.PodTemplateSpec, error) {
+	switch kind {
+	case "Deployment", "":
+		dep, err := c.kubeClient.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("deployment %s.%s get query error: %w", name, namespace, err)
+		}
+		return &dep.Spec.Template, nil
+	case "DaemonSet":
+		ds, err := c.kubeClient.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("daemonset %s.%s get query error: %w", name, namespace, err)
+		}
+		return &ds.Spec.Template, nil
+	case "StatefulSet":
+		sts, err := c.kubeClient.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("statefulset %s.%s get query error: %w", name, namespace, err)
+		}
+		return &sts.Spec.Template, nil
+	case flaggerv1.KruiseDaemonSetKind:
+		ds, err := c.flaggerClient.KruiseV1alpha1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("daemonset %s.%s get query error: %w", name, namespace, err)
+		}
+		return &ds.Spec.Template, nil
+	default:
+		return nil, fmt.Errorf("pod template of %s targets not supported", kind)
+	}
+}
+
+// checkAdmissionPolicy creates the pod with a server-side dry run,
+// the pod goes through the validating admission webhooks of Gatekeeper or Kyverno
+// without being persisted
+func (c *Controller) checkAdmissionPolicy(ctx context.Context, policy flaggerv1.CanaryPolicy, pod *corev1.Pod) error {
+	_, err := c.kubeClient.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{
+		DryRun: []string{metav1.DryRunAll},
+	})
+	if err == nil {
+		return nil
+	}
+	// the RBAC errors are forbidden too, only the admission denials are violations
+	if (kerrors.IsForbidden(err) || kerrors.IsInvalid(err)) && strings.Contains(err.Error(), "denied") {
+		return &PolicyViolation{Policy: policy.Name, Messages: []string{err.Error()}}
+	}
+	return fmt.Errorf("policy %s dry run error: %w", policy.Name, err)
+}
+
+// checkOPAPolicy uploads the Rego module to the OPA server and queries the deny rule
+// with the canary pod as input, each result of the rule is a violation
+func checkOPAPolicy(ctx context.Context, cd *flaggerv1.Canary, policy flaggerv1.CanaryPolicy, pod *corev1.Pod) error {
+	address := strings.TrimSuffix(policy.Address, "/")
+	path := policy.Path
+	if policy.Rego != "" {
+		id := fmt.Sprintf("flagger/%s/%s/%s", cd.Namespace, cd.Name, policy.Name)
+		if _, err := opaRequest(ctx, http.MethodPut, address+"/v1/policies/"+id, "text/plain", []byte(policy.Rego)); err != nil {
+			return fmt.Errorf("policy %s upload error: %w", policy.Name, err)
+		}
+		if path == "" {
+			m := regoPackage.FindStringSubmatch(policy.Rego)
+			if m == nil {
+				return fmt.Errorf("policy %s rego package not found", policy.Name)
+			}
+			path = strings.ReplaceAll(m[1], ".", "/") + "/deny"
+		}
+	}
+	if path == "" {
+		return fmt.Errorf("policy %s requires a rego module or a path", policy.Name)
+	}
+
+	input, err := json.Marshal(map[string]interface{}{"input": pod})
+	if err != nil {
+		return err
+	}
+	body, err := opaRequest(ctx, http.MethodPost, address+"/v1/data/"+strings.TrimPrefix(path, "/"), "application/json", input)
+	if err != nil {
+		return fmt.Errorf("policy %s query error: %w", policy.Name, err)
+	}
+
+	var result struct {
+		Result []interface{} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("policy %s result of %s must be a set of violations: %w", policy.Name, path, err)
+	}
+	if len(result.Result) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(result.Result))
+	for _, r := range result.Result {
+		switch v := r.(type) {
+		case string:
+			messages = append(messages, v)
+		case map[string]interface{}:
+			if msg, ok := v["msg"].(string); ok {
+				messages = append(messages, msg)
+				continue
+			}
+			b, _ := json.Marshal(v)
+			messages = append(messages, string(b))
+		default:
+			messages = append(messages, fmt.Sprintf("%v", v))
+		}
+	}
+	return &PolicyViolation{Policy: policy.Name, Messages: messages}
+}
+
+func opaRequest(ctx context.Context, method string, url string, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+	if r.StatusCode >= 300 {
+		return nil, fmt.Errorf("status %d: %s", r.StatusCode, string(b))
+	}
+	return b, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// denyPods makes the fake API server reject the pods like an admission webhook
+func denyPods(mocks fixture) {
+	mocks.kubeClient.(*fake.Clientset).PrependReactor("create", "pods", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		return true, nil, kerrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "podinfo-x",
+			errors.New(`admission webhook "validation.gatekeeper.sh" denied the request: [must-run-as-nonroot] container podinfo must set runAsNonRoot`))
+	})
+}
+
+func TestController_RunPolicyChecks_Admission(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Policies = []flaggerv1.CanaryPolicy{{Name: "restricted"}}
+	mocks := newDeploymentFixture(canary)
+
+	// the pod is admitted
+	require.NoError(t, mocks.ctrl.runPolicyChecks(canary))
+
+	denyPods(mocks)
+
+	err := mocks.ctrl.runPolicyChecks(canary)
+	var violation *PolicyViolation
+	require.True(t, errors.As(err, &violation))
+	assert.Equal(t, "restricted", violation.Policy)
+	assert.Contains(t, err.Error(), "must set runAsNonRoot")
+}
+
+func TestController_RunPolicyChecks_OPA(t *testing.T) {
+	var module string
+	var input struct {
+		Input corev1.Pod `json:"input"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/policies/flagger/default/podinfo/images":
+			b, _ := ioutil.ReadAll(r.Body)
+			module = string(b)
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/data/kubernetes/images/deny":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+			if input.Input.Spec.Containers[0].Image == "quay.io/fluxcd/podinfo:1.2.0" {
+				w.Write([]byte(`{"result": []}`))
+				return
+			}
+			w.Write([]byte(`{"result": ["latest tag not allowed", {"msg": "registry not allowed"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	rego := "package kubernetes.images\n\ndeny[msg] { msg := \"latest tag not allowed\" }\n"
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Policies = []flaggerv1.CanaryPolicy{{
+		Name:     "images",
+		Provider: flaggerv1.OPAPolicyProvider,
+		Address:  ts.URL,
+		Rego:     rego,
+	}}
+	mocks := newDeploymentFixture(canary)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Template.Spec.Containers[0].Image = "quay.io/fluxcd/podinfo:1.2.0"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, mocks.ctrl.runPolicyChecks(canary))
+	assert.Equal(t, rego, module)
+	assert.Equal(t, "podinfo", input.Input.Labels["app"])
+
+	dep.Spec.Template.Spec.Containers[0].Image = "quay.io/fluxcd/podinfo:latest"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.ctrl.runPolicyChecks(canary)
+	var violation *PolicyViolation
+	require.True(t, errors.As(err, &violation))
+	assert.Equal(t, []string{"latest tag not allowed", "registry not allowed"}, violation.Messages)
+
+	// an unreachable server is a failed check, not a violation
+	canary.Spec.Analysis.Policies[0].Address = "http://127.0.0.1:1"
+	err = mocks.ctrl.runPolicyChecks(canary)
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &violation))
+}
+
+func TestScheduler_DeploymentPolicyViolation(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Policies = []flaggerv1.CanaryPolicy{{Name: "restricted"}}
+	mocks := newDeploymentFixture(canary)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	denyPods(mocks)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// run the policy checks
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+	assert.Zero(t, c.Status.CanaryWeight)
+}
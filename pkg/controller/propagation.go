@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	karmadav1alpha1 "github.com/fluxcd/flagger/pkg/apis/karmada/v1alpha1"
+)
+
+// jsonPatchOp is a JSON patch operation on the resource selectors of a Karmada policy,
+// the policies are patched so that the placement and the overriders are left untouched
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// targetSelector selects the target workload in the Karmada policies
+func targetSelector(cd *flaggerv1.Canary) karmadav1alpha1.ResourceSelector {
+	return karmadav1alpha1.ResourceSelector{
+		APIVersion: "apps/v1",
+		Kind:       cd.Spec.TargetRef.Kind,
+		Namespace:  cd.Namespace,
+		Name:       cd.Spec.TargetRef.Name,
+	}
+}
+
+// primarySelector selects the primary workload in the Karmada policies
+func primarySelector(cd *flaggerv1.Canary, primarySuffix string) karmadav1alpha1.ResourceSelector {
+	selector := targetSelector(cd)
+	selector.Name = cd.GetPrimaryName(cd.Spec.TargetRef.Name, primarySuffix)
+	return selector
+}
+
+// generatedSelectors selects the primary workload and the services generated by Flagger
+func generatedSelectors(cd *flaggerv1.Canary, primarySuffix string) []karmadav1alpha1.ResourceSelector {
+	selectors := []karmadav1alpha1.ResourceSelector{primarySelector(cd, primarySuffix)}
+	apexName, primaryName, canaryName := cd.GetServiceNames(primarySuffix)
+	for _, name := range []string{apexName, primaryName, canaryName} {
+		selectors = append(selectors, karmadav1alpha1.ResourceSelector{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Namespace:  cd.Namespace,
+			Name:       name,
+		})
+	}
+	return selectors
+}
+
+// selects returns true if the selector matches the object by name,
+// the selectors without namespace apply to the namespace of the policy
+func selects(selector karmadav1alpha1.ResourceSelector, object karmadav1alpha1.ResourceSelector) bool {
+	return selector.APIVersion == object.APIVersion && selector.Kind == object.Kind && selector.Name == object.Name &&
+		(selector.Namespace == "" || selector.Namespace == object.Namespace)
+}
+
+// selectorsPatch returns the operations that add the missing selectors and remove the unwanted ones,
+// each removal is preceded by a test operation so that a concurrent edit of the policy fails the patch
+func selectorsPatch(current []karmadav1alpha1.ResourceSelector, add []karmadav1alpha1.ResourceSelector,
+	remove []karmadav1alpha1.ResourceSelector) []jsonPatchOp {
+	var ops []jsonPatchOp
+	for i := len(current) - 1; i >= 0; i-- {
+		for _, object := range remove {
+			if selects(current[i], object) {
+				path := fmt.Sprintf("/spec/resourceSelectors/%d", i)
+				ops = append(ops, jsonPatchOp{Op: "test", Path: path, Value: current[i]},
+					jsonPatchOp{Op: "remove", Path: path})
+				break
+			}
+		}
+	}
+
+	for _, object := range add {
+		found := false
+		for _, selector := range current {
+			if selects(selector, object) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ops = append(ops, jsonPatchOp{Op: "add", Path: "/spec/resourceSelectors/-", Value: object})
+		}
+	}
+	return ops
+}
+
+// syncPropagation adds the generated objects to the Karmada PropagationPolicy of the target
+// and moves the per-cluster overrides of the target to the primary workload,
+// the target is then propagated with the replicas set by Flagger
+func (c *Controller) syncPropagation(cd *flaggerv1.Canary) error {
+	if cd.Spec.Propagation == nil {
+		return nil
+	}
+	return c.patchPropagation(cd, generatedSelectors(cd, c.primarySuffix), nil,
+		[]karmadav1alpha1.ResourceSelector{primarySelector(cd, c.primarySuffix)}, []karmadav1alpha1.ResourceSelector{targetSelector(cd)})
+}
+
+// revertPropagation removes the generated objects from the Karmada policies
+// and gives the per-cluster overrides back to the target
+func (c *Controller) revertPropagation(cd *flaggerv1.Canary) error {
+	if cd.Spec.Propagation == nil {
+		return nil
+	}
+	return c.patchPropagation(cd, nil, generatedSelectors(cd, c.primarySuffix),
+		[]karmadav1alpha1.ResourceSelector{targetSelector(cd)}, []karmadav1alpha1.ResourceSelector{primarySelector(cd, c.primarySuffix)})
+}
+
+// patchPropagation updates the resource selectors of the referenced policies,
+// the policies that already select the expected objects are not patched
+func (c *Controller) patchPropagation(cd *flaggerv1.Canary,
+	addPropagated []karmadav1alpha1.ResourceSelector, removePropagated []karmadav1alpha1.ResourceSelector,
+	addOverridden []karmadav1alpha1.ResourceSelector, removeOverridden []karmadav1alpha1.ResourceSelector) error {
+	if ref := cd.Spec.Propagation.PropagationPolicyRef; ref != nil {
+		client := c.flaggerClient.KarmadaV1alpha1().PropagationPolicies(cd.Namespace)
+		policy, err := client.Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("PropagationPolicy %s.%s get query error: %w", ref.Name, cd.Namespace, err)
+		}
+		if ops := selectorsPatch(policy.Spec.ResourceSelectors, addPropagated, removePropagated); len(ops) > 0 {
+			data, err := json.Marshal(ops)
+			if err != nil {
+				return fmt.Errorf("json.Marshal failed: %w", err)
+			}
+			_, err = client.Patch(context.TODO(), ref.Name, types.JSONPatchType, data, metav1.PatchOptions{})
+			if err != nil {
+				return fmt.Errorf("PropagationPolicy %s.%s patch error: %w", ref.Name, cd.Namespace, err)
+			}
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Infof("PropagationPolicy %s.%s resource selectors updated", ref.Name, cd.Namespace)
+		}
+	}
+
+	if ref := cd.Spec.Propagation.OverridePolicyRef; ref != nil {
+		client := c.flaggerClient.KarmadaV1alpha1().OverridePolicies(cd.Namespace)
+		policy, err := client.Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("OverridePolicy %s.%s get query error: %w", ref.Name, cd.Namespace, err)
+		}
+		if ops := selectorsPatch(policy.Spec.ResourceSelectors, addOverridden, removeOverridden); len(ops) > 0 {
+			data, err := json.Marshal(ops)
+			if err != nil {
+				return fmt.Errorf("json.Marshal failed: %w", err)
+			}
+			_, err = client.Patch(context.TODO(), ref.Name, types.JSONPatchType, data, metav1.PatchOptions{})
+			if err != nil {
+				return fmt.Errorf("OverridePolicy %s.%s patch error: %w", ref.Name, cd.Namespace, err)
+			}
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Infof("OverridePolicy %s.%s resource selectors updated", ref.Name, cd.Namespace)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	karmadav1alpha1 "github.com/fluxcd/flagger/pkg/apis/karmada/v1alpha1"
+)
+
+func TestScheduler_DeploymentPropagation(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	canary.Spec.Propagation = &flaggerv1.CanaryPropagation{
+		PropagationPolicyRef: &corev1.LocalObjectReference{Name: "podinfo"},
+		OverridePolicyRef:    &corev1.LocalObjectReference{Name: "podinfo"},
+	}
+	mocks := newDeploymentFixture(canary)
+
+	target := karmadav1alpha1.ResourceSelector{APIVersion: "apps/v1", Kind: "Deployment", Name: "podinfo"}
+	configMap := karmadav1alpha1.ResourceSelector{APIVersion: "v1", Kind: "ConfigMap", Name: "podinfo-config-env"}
+	_, err := mocks.flaggerClient.KarmadaV1alpha1().PropagationPolicies("default").Create(context.TODO(), &karmadav1alpha1.PropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec:       karmadav1alpha1.PropagationSpec{ResourceSelectors: []karmadav1alpha1.ResourceSelector{target, configMap}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = mocks.flaggerClient.KarmadaV1alpha1().OverridePolicies("default").Create(context.TODO(), &karmadav1alpha1.OverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec:       karmadav1alpha1.OverrideSpec{ResourceSelectors: []karmadav1alpha1.ResourceSelector{configMap, target}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	pp, err := mocks.flaggerClient.KarmadaV1alpha1().PropagationPolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	names := []string{}
+	for _, selector := range pp.Spec.ResourceSelectors {
+		names = append(names, selector.Kind+"/"+selector.Name)
+	}
+	assert.Equal(t, []string{"Deployment/podinfo", "ConfigMap/podinfo-config-env", "Deployment/podinfo-primary",
+		"Service/podinfo", "Service/podinfo-primary", "Service/podinfo-canary"}, names)
+
+	// the overrides are moved from the target to the primary
+	op, err := mocks.flaggerClient.KarmadaV1alpha1().OverridePolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, op.Spec.ResourceSelectors, 2)
+	assert.Equal(t, configMap, op.Spec.ResourceSelectors[0])
+	assert.Equal(t, "podinfo-primary", op.Spec.ResourceSelectors[1].Name)
+
+	// the selectors are added once
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	pp, err = mocks.flaggerClient.KarmadaV1alpha1().PropagationPolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, pp.Spec.ResourceSelectors, 6)
+
+	// the finalizer restores the policies
+	require.NoError(t, mocks.ctrl.revertPropagation(canary))
+	pp, err = mocks.flaggerClient.KarmadaV1alpha1().PropagationPolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []karmadav1alpha1.ResourceSelector{target, configMap}, pp.Spec.ResourceSelectors)
+	op, err = mocks.flaggerClient.KarmadaV1alpha1().OverridePolicies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []karmadav1alpha1.ResourceSelector{configMap, {APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "podinfo"}},
+		op.Spec.ResourceSelectors)
+}
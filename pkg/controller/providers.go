@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+var (
+	// supportedProviders are the routing providers without their API version, e.g. appmesh for appmesh:v1beta2
+	supportedProviders = []string{
+		flaggerv1.KubernetesProvider,
+		flaggerv1.IstioProvider,
+		flaggerv1.LinkerdProvider,
+		flaggerv1.AppMeshProvider,
+		flaggerv1.SMIProvider,
+		flaggerv1.ContourProvider,
+		flaggerv1.GlooProvider,
+		flaggerv1.NGINXProvider,
+		flaggerv1.SkipperProvider,
+		flaggerv1.TraefikProvider,
+		flaggerv1.GatewayAPIProvider,
+		flaggerv1.KnativeProvider,
+	}
+
+	supportedTargetKinds = []string{"Deployment", "DaemonSet", "StatefulSet", "Service", flaggerv1.ScaleSubresourceKind, flaggerv1.KnativeServiceKind}
+)
+
+// SetEnabledProviders limits the routing providers that the canaries can use,
+// so that the RBAC rules of the disabled providers can be removed, all the providers are enabled when the list is empty
+func (c *Controller) SetEnabledProviders(providers []string) error {
+	for _, provider := range providers {
+		if !containsString(supportedProviders, providerName(provider)) {
+			return fmt.Errorf("provider %s not supported, must be one of %s", provider, strings.Join(supportedProviders, ", "))
+		}
+	}
+	if len(providers) > 0 && !containsString(providers, providerName(c.meshProvider)) {
+		return fmt.Errorf("the mesh provider %s must be enabled", c.meshProvider)
+	}
+	c.enabledProviders = providers
+	return nil
+}
+
+// SetEnabledTargetKinds limits the workload kinds that the canaries can target,
+// all the kinds are enabled when the list is empty
+func (c *Controller) SetEnabledTargetKinds(kinds []string) error {
+	for _, kind := range kinds {
+		if !containsString(supportedTargetKinds, kind) {
+			return fmt.Errorf("target kind %s not supported, must be one of %s", kind, strings.Join(supportedTargetKinds, ", "))
+		}
+	}
+	c.enabledTargetKinds = kinds
+	return nil
+}
+
+// checkEnabled returns an error when the canary uses a disabled provider or target kind
+func (c *Controller) checkEnabled(provider string, kind string) error {
+	if len(c.enabledProviders) > 0 && !containsString(c.enabledProviders, providerName(provider)) {
+		return fmt.Errorf("provider %s is disabled, the enabled providers are %s", provider, strings.Join(c.enabledProviders, ", "))
+	}
+	if kind == "" {
+		kind = "Deployment"
+	}
+	if len(c.enabledTargetKinds) > 0 && !containsString(c.enabledTargetKinds, kind) {
+		return fmt.Errorf("target kind %s is disabled, the enabled kinds are %s", kind, strings.Join(c.enabledTargetKinds, ", "))
+	}
+	return nil
+}
+
+func providerName(provider string) string {
+	return strings.SplitN(provider, ":", 2)[0]
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestController_SetEnabledProviders(t *testing.T) {
+	c := &Controller{meshProvider: "appmesh:v1beta2"}
+	assert.NoError(t, c.SetEnabledProviders([]string{"appmesh", "nginx"}))
+	assert.Error(t, c.SetEnabledProviders([]string{"nginx"}))
+	assert.Error(t, c.SetEnabledProviders([]string{"appmesh", "envoy"}))
+
+	assert.NoError(t, c.SetEnabledTargetKinds([]string{"Deployment"}))
+	assert.NoError(t, c.SetEnabledTargetKinds([]string{"StatefulSet"}))
+	assert.Error(t, c.SetEnabledTargetKinds([]string{"ReplicaSet"}))
+}
+
+func TestScheduler_DisabledProvider(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.meshProvider = "kubernetes"
+	require.NoError(t, mocks.ctrl.SetEnabledProviders([]string{"kubernetes"}))
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Provider = "istio"
+	_, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// the primary isn't created for a canary with a disabled provider
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	cd.Spec.Provider = ""
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.SetEnabledTargetKinds([]string{"DaemonSet"}))
+
+	// the primary isn't created for a canary with a disabled target kind
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	require.NoError(t, mocks.ctrl.SetEnabledTargetKinds(nil))
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
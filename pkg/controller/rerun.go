@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// rerunAnnotation requests the scheduler to run the analysis of the current revision again,
+// any value is accepted so that the annotation can be bumped with a timestamp
+const rerunAnnotation = "flagger.app/rerun"
+
+// takeRerunAction consumes the rerun annotation and clears the last applied spec,
+// the analysis is started as for a new revision even if the gates hold it
+func (c *Controller) takeRerunAction(cd *flaggerv1.Canary) bool {
+	value, ok := cd.GetAnnotations()[rerunAnnotation]
+	if !ok || !acceptsAction(cd, rerunAnnotation) || !validAction(rerunAnnotation, value) {
+		return false
+	}
+	if err := c.resetLastAppliedSpec(cd); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return false
+	}
+	if _, err := c.takeAction(cd, rerunAnnotation); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return false
+	}
+	return true
+}
+
+// resetLastAppliedSpec removes the hash of the last analysed revision from the canary status,
+// the canary object is updated in place so that the change detection sees the reset
+func (c *Controller) resetLastAppliedSpec(cd *flaggerv1.Canary) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest, err := c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(context.TODO(), cd.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		cdCopy := latest.DeepCopy()
+		cdCopy.Status.LastAppliedSpec = ""
+		updated, err := c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		cd.ObjectMeta = updated.ObjectMeta
+		cd.Status.LastAppliedSpec = ""
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("resetting the last applied spec of canary %s.%s failed: %w", cd.Name, cd.Namespace, err)
+	}
+	return nil
+}
+
+// requestRerun sets the rerun annotation of the canary to the current time
+func (c *Controller) requestRerun(name string, namespace string) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		cdCopy := cd.DeepCopy()
+		if cdCopy.Annotations == nil {
+			cdCopy.Annotations = make(map[string]string)
+		}
+		cdCopy.Annotations[rerunAnnotation] = time.Now().UTC().Format(time.RFC3339)
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Update(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// RerunHandler requests the analysis of the current revision of the canary
+// selected with the namespace and name query parameters
+func (c *Controller) RerunHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name, namespace := r.URL.Query().Get("name"), r.URL.Query().Get("namespace")
+		if name == "" || namespace == "" {
+			http.Error(w, "name and namespace are required", http.StatusBadRequest)
+			return
+		}
+
+		if err := c.requestRerun(name, namespace); err != nil {
+			if errors.IsNotFound(err) {
+				http.Error(w, fmt.Sprintf("canary %s.%s not found", name, namespace), http.StatusNotFound)
+				return
+			}
+			c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Errorf("requesting rerun failed: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Infof("Analysis rerun requested")
+	})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_RerunAction(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, flaggerv1.CanaryPhaseInitialized, c.Status.Phase)
+	lastAppliedSpec := c.Status.LastAppliedSpec
+
+	// no changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseInitialized, c.Status.Phase)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/rerun?namespace=default&name=podinfo", nil)
+	rec := httptest.NewRecorder()
+	mocks.ctrl.RerunHandler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	// start the analysis without changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.NotEmpty(t, c.Status.RunID)
+	assert.Equal(t, lastAppliedSpec, c.Status.LastAppliedSpec)
+	assert.NotContains(t, c.Annotations, rerunAnnotation)
+
+	// ignore the rerun while progressing
+	c.Annotations = map[string]string{rerunAnnotation: "2021-10-14T10:00:00Z"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	runID := c.Status.RunID
+
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, runID, c.Status.RunID)
+	assert.NotContains(t, c.Annotations, rerunAnnotation)
+}
+
+func TestController_RerunHandler(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/rerun?namespace=default&name=podinfo", nil)
+	rec := httptest.NewRecorder()
+	mocks.ctrl.RerunHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/rerun?namespace=default&name=unknown", nil)
+	rec = httptest.NewRecorder()
+	mocks.ctrl.RerunHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/rerun", nil)
+	rec = httptest.NewRecorder()
+	mocks.ctrl.RerunHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/rerun?namespace=default&name=podinfo", nil)
+	rec = httptest.NewRecorder()
+	mocks.ctrl.RerunHandler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, c.Annotations[rerunAnnotation])
+}
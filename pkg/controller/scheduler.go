@@ -18,10 +18,12 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 
@@ -49,9 +51,17 @@ func (c *Controller) maxWeight(canary *flaggerv1.Canary) int {
 	return c.totalWeight(canary)
 }
 
+// totalWeight returns the total traffic weight of the canary, the weights out of
+// a custom total are only supported by the NGINX ingress and default to 100 for the other providers
 func (c *Controller) totalWeight(canary *flaggerv1.Canary) int {
-	// set total weight default value to 100%
-	return 100
+	provider := c.meshProvider
+	if canary.Spec.Provider != "" {
+		provider = canary.Spec.Provider
+	}
+	if provider != flaggerv1.NGINXProvider {
+		return flaggerv1.TotalWeight
+	}
+	return canary.GetAnalysisTotalWeight()
 }
 
 func (c *Controller) nextStepWeight(canary *flaggerv1.Canary, canaryWeight int) int {
@@ -95,6 +105,9 @@ func (c *Controller) scheduleCanaries() {
 
 	c.canaries.Range(func(key interface{}, value interface{}) bool {
 		cn := value.(*flaggerv1.Canary)
+		if classified, err := c.withDefaults(cn); err == nil {
+			cn = classified
+		}
 
 		// format: <name>.<namespace>
 		name := key.(string)
@@ -102,7 +115,8 @@ func (c *Controller) scheduleCanaries() {
 
 		job, exists := c.jobs[name]
 		// schedule new job for existing job with different analysis interval or non-existing job
-		if (exists && job.GetCanaryAnalysisInterval() != cn.GetAnalysisInterval()) || !exists {
+		interval := c.analysisInterval(cn)
+		if (exists && job.GetCanaryAnalysisInterval() != interval) || !exists {
 			if exists {
 				job.Stop()
 			}
@@ -112,14 +126,22 @@ func (c *Controller) scheduleCanaries() {
 				Namespace:        cn.Namespace,
 				function:         c.advanceCanary,
 				done:             make(chan bool),
-				ticker:           time.NewTicker(cn.GetAnalysisInterval()),
-				analysisInterval: cn.GetAnalysisInterval(),
+				trigger:          make(chan struct{}, 1),
+				ticker:           time.NewTicker(interval),
+				analysisInterval: interval,
 			}
 
 			c.jobs[name] = newJob
 			newJob.Start()
 		}
 
+		// run the analysis when an operator action is pending
+		if exists {
+			if latest, err := c.flaggerInformers.CanaryInformer.Lister().Canaries(cn.Namespace).Get(cn.Name); err == nil && hasPendingAction(latest) {
+				c.jobs[name].Trigger()
+			}
+		}
+
 		// compute canaries per namespace total
 		t, ok := stats[cn.Namespace]
 		if !ok {
@@ -155,7 +177,22 @@ func (c *Controller) scheduleCanaries() {
 }
 
 func (c *Controller) advanceCanary(name string, namespace string) {
+	// hold the analysis while the namespace labels don't match the selector
+	if ok, err := c.namespaceAllowed(namespace); err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Errorf("%v", err)
+		return
+	} else if !ok {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
+			Debugf("Namespace %s is excluded, skipping the analysis", namespace)
+		return
+	}
+
 	begin := time.Now()
+	span := c.startIterationSpan(name, namespace)
+	defer span.End()
+	defer c.syncCheckResults(name, namespace)
+	defer c.syncWaveStatus(name, namespace)
+
 	// check if the canary exists
 	cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if err != nil {
@@ -163,45 +200,134 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			Errorf("Canary %s.%s not found", name, namespace)
 		return
 	}
+	span.SetAttributes(
+		attribute.String("canary.phase", string(cd.Status.Phase)),
+		attribute.Int("canary.weight", cd.Status.CanaryWeight),
+		attribute.Int("canary.iterations", cd.Status.Iterations),
+		attribute.Int("canary.failedChecks", cd.Status.FailedChecks),
+	)
+	c.recorder.SetPhase(cd)
+	c.recordTimelinePhase(cd)
+
+	// hold the analysis at the current step while the canary is suspended
+	if updated, err := c.setSuspendedCondition(cd, cd.Spec.Suspend); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return
+	} else if updated != nil {
+		cd = updated
+		if cd.Spec.Suspend {
+			c.recordEventInfof(cd, flaggerv1.ReasonSuspended, "Canary analysis suspended for %s.%s", cd.Name, cd.Namespace)
+		} else {
+			c.recordEventInfof(cd, flaggerv1.ReasonResumed, "Canary analysis resumed for %s.%s", cd.Name, cd.Namespace)
+		}
+	}
+
+	// report the changes of the finalizer when requested, also for the suspended canaries
+	c.previewTeardown(cd)
+	if cd.Spec.Suspend {
+		c.recorder.SetStatus(cd, cd.Status.Phase)
+		return
+	}
+	c.discardActions(cd)
+
+	// inherit the analysis, provider and alerts of the canary class and the default webhooks
+	classified, err := c.withDefaults(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return
+	}
+	cd = classified
+
+	// keep the Flux owner of the target suspended while the analysis is running
+	if err := c.syncFluxSuspension(cd); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return
+	}
 
 	// override the global provider if one is specified in the canary spec
 	provider := c.meshProvider
 	if cd.Spec.Provider != "" {
 		provider = cd.Spec.Provider
 	}
+	kind := cd.Spec.TargetRef.Kind
+	if k := cd.GetTargetKind(); k == flaggerv1.ScaleSubresourceKind || k == flaggerv1.KnativeServiceKind {
+		kind = k
+	}
+	if err := c.checkEnabled(provider, kind); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return
+	}
+	for _, ref := range cd.Spec.TargetRefs {
+		if err := c.checkEnabled(provider, ref.Kind); err != nil {
+			c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+			return
+		}
+	}
+	if err := c.checkTargetNamespace(cd, provider); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return
+	}
 
 	// init controller based on target kind
-	canaryController := c.canaryFactory.Controller(cd.Spec.TargetRef.Kind)
+	canaryController, err := c.targetController(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return
+	}
 	labelSelector, labelValue, ports, err := canaryController.GetMetadata(cd)
 	if err != nil {
-		c.recordEventWarningf(cd, "%v", err)
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 		return
 	}
 
 	// init Kubernetes router
-	kubeRouter := c.routerFactory.KubernetesRouter(cd.Spec.TargetRef.Kind, labelSelector, labelValue, ports)
+	kubeRouter, err := c.targetRouter(cd, labelSelector, labelValue, ports)
+	if err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return
+	}
+
+	// manage the workloads and services of the remote clusters
+	canaryController, err = c.multiClusterController(cd, canaryController)
+	if err == nil {
+		kubeRouter, err = c.multiClusterRouter(cd, kubeRouter, labelSelector, labelValue, ports)
+	}
+	if err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return
+	}
 
 	// reconcile the canary/primary services
 	if err := kubeRouter.Initialize(cd); err != nil {
-		c.recordEventWarningf(cd, "%v", err)
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return
+	}
+
+	// propagate the primary workload and the services with the Karmada policies of the target
+	if err := c.syncPropagation(cd); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 		return
 	}
 
 	// check metric servers' availability
 	if !cd.SkipAnalysis() && (cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing) {
 		if err := c.checkMetricProviderAvailability(cd); err != nil {
-			c.recordEventErrorf(cd, "Error checking metric providers: %v", err)
+			c.recordEventErrorf(cd, flaggerv1.ReasonMetricProviderUnavailable, "Error checking metric providers: %v", err)
 		}
 	}
 
 	// init mesh router
-	meshRouter := c.routerFactory.MeshRouter(provider, labelSelector)
+	var meshRouter router.Interface = &instrumentedRouter{
+		Interface: c.routerFactory.MeshRouter(provider, labelSelector),
+		ctrl:      c,
+		provider:  provider,
+	}
 
 	// register the AppMesh VirtualNodes before creating the primary deployment
 	// otherwise the pods will not be injected with the Envoy proxy
 	if strings.HasPrefix(provider, flaggerv1.AppMeshProvider) {
 		if err := meshRouter.Reconcile(cd); err != nil {
-			c.recordEventWarningf(cd, "%v", err)
+			c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 	}
@@ -209,13 +335,13 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	// create primary workload
 	err = canaryController.Initialize(cd)
 	if err != nil {
-		c.recordEventWarningf(cd, "%v", err)
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 		return
 	}
 
 	// change the apex service pod selector to primary
 	if err := kubeRouter.Reconcile(cd); err != nil {
-		c.recordEventWarningf(cd, "%v", err)
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 		return
 	}
 
@@ -223,15 +349,21 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	// runs after the primary is ready to ensure zero downtime
 	if !strings.HasPrefix(provider, flaggerv1.AppMeshProvider) {
 		if err := meshRouter.Reconcile(cd); err != nil {
-			c.recordEventWarningf(cd, "%v", err)
+			c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 	}
 
+	// run the analysis of the current revision again if requested by the operator
+	if c.takeRerunAction(cd) {
+		c.recordEventInfof(cd, flaggerv1.ReasonRerunRequested, "Running the analysis of %s.%s again as requested by %s annotation",
+			cd.Name, cd.Namespace, rerunAnnotation)
+	}
+
 	// check for changes
 	shouldAdvance, err := c.shouldAdvance(cd, canaryController)
 	if err != nil {
-		c.recordEventWarningf(cd, "%v", err)
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 		return
 	}
 
@@ -240,6 +372,32 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// roll back regardless of the gates and the analysis result if requested by the operator
+	if c.takeAbortAction(cd) {
+		c.recordEventWarningf(cd, flaggerv1.ReasonAborted, "Rolling back %s.%s abort requested by %s annotation",
+			cd.Name, cd.Namespace, abortAnnotation)
+		c.alert(cd, flaggerv1.ReasonAborted, "Rolling back abort requested by operator", false, flaggerv1.SeverityWarn)
+		c.rollback(cd, canaryController, meshRouter)
+		return
+	}
+	if c.waveAborted(cd) {
+		c.recordEventWarningf(cd, flaggerv1.ReasonAborted, "Rolling back %s.%s abort requested by its canary wave",
+			cd.Name, cd.Namespace)
+		c.alert(cd, flaggerv1.ReasonAborted, "Rolling back abort requested by the canary wave", false, flaggerv1.SeverityWarn)
+		c.rollback(cd, canaryController, meshRouter)
+		return
+	}
+
+	// hold the start of the analysis until the previous canaries of the wave are promoted
+	if !c.runWaveGate(cd, canaryController) {
+		return
+	}
+
+	// roll back or hold the canary when the analysis runs longer than its max duration
+	if ok := c.checkMaxDuration(cd, canaryController, meshRouter); !ok {
+		return
+	}
+
 	// check gates
 	if isApproved := c.runConfirmRolloutHooks(cd, canaryController); !isApproved {
 		return
@@ -250,7 +408,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	// check primary status
 	if !cd.SkipAnalysis() {
 		if err := canaryController.IsPrimaryReady(cd); err != nil {
-			c.recordEventWarningf(cd, "%v", err)
+			c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 	}
@@ -258,7 +416,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	// get the routing settings
 	primaryWeight, canaryWeight, mirrored, err := meshRouter.GetRoutes(cd)
 	if err != nil {
-		c.recordEventWarningf(cd, "%v", err)
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 		return
 	}
 
@@ -271,14 +429,16 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 
 	// check if canary revision changed during analysis
 	if restart := c.hasCanaryRevisionChanged(cd, canaryController); restart {
-		c.recordEventInfof(cd, "New revision detected! Restarting analysis for %s.%s",
+		canaryRestarted := cd.DeepCopy()
+		canaryRestarted.Status.RunID = newRunID()
+		c.recordEventInfof(canaryRestarted, flaggerv1.ReasonNewRevisionDetected, "New revision detected! Restarting analysis for %s.%s",
 			cd.Spec.TargetRef.Name, cd.Namespace)
 
 		// route all traffic back to primary
 		primaryWeight = c.totalWeight(cd)
 		canaryWeight = 0
 		if err := meshRouter.SetRoutes(cd, primaryWeight, canaryWeight, false); err != nil {
-			c.recordEventWarningf(cd, "%v", err)
+			c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 
@@ -288,9 +448,10 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			CanaryWeight: 0,
 			FailedChecks: 0,
 			Iterations:   0,
+			RunID:        canaryRestarted.Status.RunID,
 		}
 		if err := canaryController.SyncStatus(cd, status); err != nil {
-			c.recordEventWarningf(cd, "%v", err)
+			c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 		}
 		return
 	}
@@ -299,7 +460,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	var retriable = true
 	retriable, err = canaryController.IsCanaryReady(cd)
 	if err != nil && retriable {
-		c.recordEventWarningf(cd, "%v", err)
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 		return
 	}
 
@@ -308,13 +469,34 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// skip the remaining steps if requested by the operator
+	if c.takeSkipAction(cd, skipPromotion) {
+		c.recordEventInfof(cd, flaggerv1.ReasonPromotionForced, "Skipping the remaining steps of %s.%s, promotion requested by %s annotation",
+			cd.Name, cd.Namespace, skipAnnotation)
+		c.promoteWithoutAnalysis(cd, canaryController, meshRouter)
+		return
+	}
+
+	// promote the hotfix revision without analysis if its image matches the skip once annotation
+	if image, manager, ok := c.takeSkipAnalysisOnce(cd); ok {
+		if manager == "" {
+			manager = "unknown"
+		}
+		msg := fmt.Sprintf("Skipping the analysis of %s.%s, image %s matches the %s annotation set by %s",
+			cd.Name, cd.Namespace, image, skipAnalysisOnceAnnotation, manager)
+		c.recordEventInfof(cd, flaggerv1.ReasonPromotionForced, "%s", msg)
+		c.alert(cd, flaggerv1.ReasonPromotionForced, msg, true, flaggerv1.SeverityWarn)
+		c.promoteWithoutAnalysis(cd, canaryController, meshRouter)
+		return
+	}
+
 	// check if we should rollback
 	if cd.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
 		cd.Status.Phase == flaggerv1.CanaryPhaseWaiting ||
 		cd.Status.Phase == flaggerv1.CanaryWaitingPromotion {
 		if ok := c.runRollbackHooks(cd, cd.Status.Phase); ok {
-			c.recordEventWarningf(cd, "Rolling back %s.%s manual webhook invoked", cd.Name, cd.Namespace)
-			c.alert(cd, "Rolling back manual webhook invoked", false, flaggerv1.SeverityWarn)
+			c.recordEventWarningf(cd, flaggerv1.ReasonManualRollback, "Rolling back %s.%s manual webhook invoked", cd.Name, cd.Namespace)
+			c.alert(cd, flaggerv1.ReasonManualRollback, "Rolling back manual webhook invoked", false, flaggerv1.SeverityWarn)
 			c.rollback(cd, canaryController, meshRouter)
 			return
 		}
@@ -329,20 +511,21 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	// scale canary to zero if promotion has finished
 	if cd.Status.Phase == flaggerv1.CanaryPhaseFinalising {
 		if err := canaryController.ScaleToZero(cd); err != nil {
-			c.recordEventWarningf(cd, "%v", err)
+			c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 
 		// set status to succeeded
 		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseSucceeded); err != nil {
-			c.recordEventWarningf(cd, "%v", err)
+			c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 		c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseSucceeded)
 		c.runPostRolloutHooks(cd, flaggerv1.CanaryPhaseSucceeded)
-		c.recordEventInfof(cd, "Promotion completed! Scaling down %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
-		c.alert(cd, "Canary analysis completed successfully, promotion finished.",
+		c.recordEventInfof(cd, flaggerv1.ReasonPromoted, "Promotion completed! Scaling down %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+		c.alert(cd, flaggerv1.ReasonPromoted, "Canary analysis completed successfully, promotion finished.",
 			false, flaggerv1.SeverityInfo)
+		c.postFluxPromotionEvent(cd)
 		return
 	}
 
@@ -350,9 +533,9 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	if cd.Status.Phase == flaggerv1.CanaryPhaseProgressing &&
 		(!retriable || cd.Status.FailedChecks >= cd.GetAnalysisThreshold()) {
 		if !retriable {
-			c.recordEventWarningf(cd, "Rolling back %s.%s progress deadline exceeded %v",
+			c.recordEventWarningf(cd, flaggerv1.ReasonProgressDeadlineExceeded, "Rolling back %s.%s progress deadline exceeded %v",
 				cd.Name, cd.Namespace, err)
-			c.alert(cd, fmt.Sprintf("Progress deadline exceeded %v", err),
+			c.alert(cd, flaggerv1.ReasonProgressDeadlineExceeded, fmt.Sprintf("Progress deadline exceeded %v", err),
 				false, flaggerv1.SeverityError)
 		}
 		c.rollback(cd, canaryController, meshRouter)
@@ -368,33 +551,54 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	// skip check if no traffic is routed or mirrored to canary
 	if canaryWeight == 0 && cd.Status.Iterations == 0 &&
 		!(cd.GetAnalysis().Mirror && mirrored) {
-		c.recordEventInfof(cd, "Starting canary analysis for %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+		c.recordEventInfof(cd, flaggerv1.ReasonAnalysisStarted, "Starting canary analysis for %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+
+		// check the canary pod spec against the policies before routing any traffic
+		if err := c.runPolicyChecks(cd); err != nil {
+			var violation *PolicyViolation
+			if errors.As(err, &violation) {
+				c.recordEventWarningf(cd, flaggerv1.ReasonPolicyViolation, "Rolling back %s.%s %v", cd.Name, cd.Namespace, err)
+				c.alert(cd, flaggerv1.ReasonPolicyViolation, err.Error(), false, flaggerv1.SeverityError)
+				c.rollback(cd, canaryController, meshRouter)
+				return
+			}
+			c.recordEventWarningf(cd, flaggerv1.ReasonPolicyCheckFailed, "Halt %s.%s advancement policy check failed %v",
+				cd.Name, cd.Namespace, err)
+			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+				c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+			}
+			return
+		}
 
 		// run pre-rollout web hooks
 		if ok := c.runPreRolloutHooks(cd); !ok {
 			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
-				c.recordEventWarningf(cd, "%v", err)
+				c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 			}
 			return
 		}
+	} else if c.takeSkipAction(cd, skipStep) {
+		c.recordEventInfof(cd, flaggerv1.ReasonStepSkipped, "Skipping the analysis of the current step for %s.%s, requested by %s annotation",
+			cd.Name, cd.Namespace, skipAnnotation)
 	} else {
 		if ok := c.runAnalysis(cd); !ok {
 			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
-				c.recordEventWarningf(cd, "%v", err)
+				c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
 			}
 			return
 		}
 	}
 
-	// use blue/green strategy for kubernetes provider
-	if provider == flaggerv1.KubernetesProvider {
+	// use blue/green strategy for kubernetes provider,
+	// the OpenKruise DaemonSets shift the weight with the partition of the nodes
+	if provider == flaggerv1.KubernetesProvider && cd.GetTargetKind() != flaggerv1.KruiseDaemonSetKind {
 		if len(cd.GetAnalysis().Match) > 0 {
-			c.recordEventWarningf(cd, "A/B testing is not supported when using the kubernetes provider")
+			c.recordEventWarningf(cd, flaggerv1.ReasonUnsupportedAnalysis, "A/B testing is not supported when using the kubernetes provider")
 			cd.GetAnalysis().Match = nil
 		}
 		if cd.GetAnalysis().Iterations < 1 {
-			c.recordEventWarningf(cd, "Progressive traffic is not supported when using the kubernetes provider")
-			c.recordEventWarningf(cd, "Setting canaryAnalysis.iterations: 10")
+			c.recordEventWarningf(cd, flaggerv1.ReasonUnsupportedAnalysis, "Progressive traffic is not supported when using the kubernetes provider")
+			c.recordEventWarningf(cd, flaggerv1.ReasonUnsupportedAnalysis, "Setting canaryAnalysis.iterations: 10")
 			cd.GetAnalysis().Iterations = 10
 		}
 	}
@@ -429,21 +633,21 @@ func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryCo
 	// finalize promotion since no traffic shifting is possible for Kubernetes CNI
 	if provider == flaggerv1.KubernetesProvider {
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseFinalising); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 		}
 		return
 	}
 
 	// route all traffic to primary in one go when promotion step wight is not set
 	if canary.Spec.Analysis.StepWeightPromotion == 0 {
-		c.recordEventInfof(canary, "Routing all traffic to primary")
+		c.recordEventInfof(canary, flaggerv1.ReasonPromoting, "Routing all traffic to primary")
 		if err := meshRouter.SetRoutes(canary, c.totalWeight(canary), 0, false); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 		c.recorder.SetWeight(canary, c.totalWeight(canary), 0)
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseFinalising); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 		}
 		return
 	}
@@ -459,20 +663,21 @@ func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryCo
 			canaryWeight = 0
 		}
 		if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 		c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
-		c.recordEventInfof(canary, "Advance %s.%s primary weight %v", canary.Name, canary.Namespace, primaryWeight)
+		c.recordEventInfof(canary, flaggerv1.ReasonAdvanced, "Advance %s.%s primary weight %v", canary.Name, canary.Namespace, primaryWeight)
+		c.annotate(canary, flaggerv1.ReasonAdvanced, fmt.Sprintf("Primary weight advanced to %v", primaryWeight))
 
 		// finalize promotion
 		if primaryWeight == c.totalWeight(canary) {
 			if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseFinalising); err != nil {
-				c.recordEventWarningf(canary, "%v", err)
+				c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			}
 		} else {
 			if err := canaryController.SetStatusWeight(canary, canaryWeight); err != nil {
-				c.recordEventWarningf(canary, "%v", err)
+				c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			}
 		}
 	}
@@ -483,7 +688,7 @@ func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryCo
 
 func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary.Controller,
 	meshRouter router.Interface, mirrored bool, canaryWeight int, primaryWeight int, maxWeight int) {
-	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
+	primaryName := canary.GetPrimaryName(canary.Spec.TargetRef.Name, c.primarySuffix)
 
 	// increase traffic weight
 	if canaryWeight < maxWeight {
@@ -518,17 +723,29 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 		}
 
 		if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, mirrored); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 
 		if err := canaryController.SetStatusWeight(canary, canaryWeight); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 
 		c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
-		c.recordEventInfof(canary, "Advance %s.%s canary weight %v", canary.Name, canary.Namespace, canaryWeight)
+		c.recordEventInfof(canary, flaggerv1.ReasonAdvanced, "Advance %s.%s canary weight %v", canary.Name, canary.Namespace, canaryWeight)
+		c.annotate(canary, flaggerv1.ReasonAdvanced, fmt.Sprintf("Canary weight advanced to %v", canaryWeight))
+		return
+	}
+
+	// hold the max weight for the soak iterations
+	if canaryWeight >= maxWeight && canary.GetAnalysis().SoakIterations > canary.Status.Iterations {
+		if err := canaryController.SetStatusIterations(canary, canary.Status.Iterations+1); err != nil {
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
+			return
+		}
+		c.recordEventInfof(canary, flaggerv1.ReasonAdvanced, "Soak %s.%s canary weight %v iteration %v/%v",
+			canary.Name, canary.Namespace, canaryWeight, canary.Status.Iterations+1, canary.GetAnalysis().SoakIterations)
 		return
 	}
 
@@ -540,16 +757,16 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 		}
 
 		// update primary spec
-		c.recordEventInfof(canary, "Copying %s.%s template spec to %s.%s",
+		c.recordEventInfof(canary, flaggerv1.ReasonPromoting, "Copying %s.%s template spec to %s.%s",
 			canary.Spec.TargetRef.Name, canary.Namespace, primaryName, canary.Namespace)
 		if err := canaryController.Promote(canary); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 
 		// update status phase
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhasePromoting); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 	}
@@ -557,21 +774,21 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 
 func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Controller,
 	meshRouter router.Interface) {
-	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
+	primaryName := canary.GetPrimaryName(canary.Spec.TargetRef.Name, c.primarySuffix)
 
 	// route traffic to canary and increment iterations
 	if canary.GetAnalysis().Iterations > canary.Status.Iterations {
 		if err := meshRouter.SetRoutes(canary, 0, c.totalWeight(canary), false); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 		c.recorder.SetWeight(canary, 0, c.totalWeight(canary))
 
 		if err := canaryController.SetStatusIterations(canary, canary.Status.Iterations+1); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
-		c.recordEventInfof(canary, "Advance %s.%s canary iteration %v/%v",
+		c.recordEventInfof(canary, flaggerv1.ReasonAdvanced, "Advance %s.%s canary iteration %v/%v",
 			canary.Name, canary.Namespace, canary.Status.Iterations+1, canary.GetAnalysis().Iterations)
 		return
 	}
@@ -583,16 +800,16 @@ func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Con
 
 	// promote canary - max iterations reached
 	if canary.GetAnalysis().Iterations == canary.Status.Iterations {
-		c.recordEventInfof(canary, "Copying %s.%s template spec to %s.%s",
+		c.recordEventInfof(canary, flaggerv1.ReasonPromoting, "Copying %s.%s template spec to %s.%s",
 			canary.Spec.TargetRef.Name, canary.Namespace, primaryName, canary.Namespace)
 		if err := canaryController.Promote(canary); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 
 		// update status phase
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhasePromoting); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 	}
@@ -600,7 +817,7 @@ func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Con
 
 func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController canary.Controller,
 	meshRouter router.Interface, provider string, mirrored bool) {
-	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
+	primaryName := canary.GetPrimaryName(canary.Spec.TargetRef.Name, c.primarySuffix)
 
 	// increment iterations
 	if canary.GetAnalysis().Iterations > canary.Status.Iterations {
@@ -608,16 +825,16 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 		if provider != "kubernetes" &&
 			canary.GetAnalysis().Mirror && !mirrored {
 			if err := meshRouter.SetRoutes(canary, c.totalWeight(canary), 0, true); err != nil {
-				c.recordEventWarningf(canary, "%v", err)
+				c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			}
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("Start traffic mirroring")
 		}
 		if err := canaryController.SetStatusIterations(canary, canary.Status.Iterations+1); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
-		c.recordEventInfof(canary, "Advance %s.%s canary iteration %v/%v",
+		c.recordEventInfof(canary, flaggerv1.ReasonAdvanced, "Advance %s.%s canary iteration %v/%v",
 			canary.Name, canary.Namespace, canary.Status.Iterations+1, canary.GetAnalysis().Iterations)
 		return
 	}
@@ -631,12 +848,12 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 	if canary.GetAnalysis().Iterations == canary.Status.Iterations {
 		if provider != "kubernetes" {
 			if canary.GetAnalysis().Mirror {
-				c.recordEventInfof(canary, "Stop traffic mirroring and route all traffic to canary")
+				c.recordEventInfof(canary, flaggerv1.ReasonPromoting, "Stop traffic mirroring and route all traffic to canary")
 			} else {
-				c.recordEventInfof(canary, "Routing all traffic to canary")
+				c.recordEventInfof(canary, flaggerv1.ReasonPromoting, "Routing all traffic to canary")
 			}
 			if err := meshRouter.SetRoutes(canary, 0, c.totalWeight(canary), false); err != nil {
-				c.recordEventWarningf(canary, "%v", err)
+				c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 				return
 			}
 			c.recorder.SetWeight(canary, 0, c.totalWeight(canary))
@@ -644,7 +861,7 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 
 		// increment iterations
 		if err := canaryController.SetStatusIterations(canary, canary.Status.Iterations+1); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 		return
@@ -652,16 +869,16 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 
 	// promote canary - max iterations reached
 	if canary.GetAnalysis().Iterations < canary.Status.Iterations {
-		c.recordEventInfof(canary, "Copying %s.%s template spec to %s.%s",
+		c.recordEventInfof(canary, flaggerv1.ReasonPromoting, "Copying %s.%s template spec to %s.%s",
 			canary.Spec.TargetRef.Name, canary.Namespace, primaryName, canary.Namespace)
 		if err := canaryController.Promote(canary); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 
 		// update status phase
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhasePromoting); err != nil {
-			c.recordEventWarningf(canary, "%v", err)
+			c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return
 		}
 	}
@@ -672,9 +889,9 @@ func (c *Controller) runAnalysis(canary *flaggerv1.Canary) bool {
 	// run external checks
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == "" || webhook.Type == flaggerv1.RolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			err := c.callWebhook(canary, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement external check %s failed %v",
+				c.recordEventWarningf(canary, webhookFailureReason(err), "Halt %s.%s advancement external check %s failed %v",
 					canary.Name, canary.Namespace, webhook.Name, err)
 				return false
 			}
@@ -701,48 +918,55 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 
 	// regardless if analysis is being skipped, rollback if canary failed to progress
 	if !retriable || canary.Status.FailedChecks >= canary.GetAnalysisThreshold() {
-		c.recordEventWarningf(canary, "Rolling back %s.%s progress deadline exceeded %v", canary.Name, canary.Namespace, err)
-		c.alert(canary, fmt.Sprintf("Progress deadline exceeded %v", err), false, flaggerv1.SeverityError)
+		c.recordEventWarningf(canary, flaggerv1.ReasonProgressDeadlineExceeded, "Rolling back %s.%s progress deadline exceeded %v", canary.Name, canary.Namespace, err)
+		c.alert(canary, flaggerv1.ReasonProgressDeadlineExceeded, fmt.Sprintf("Progress deadline exceeded %v", err), false, flaggerv1.SeverityError)
 		c.rollback(canary, canaryController, meshRouter)
 
 		return true
 	}
 
+	return c.promoteWithoutAnalysis(canary, canaryController, meshRouter)
+}
+
+// promoteWithoutAnalysis routes all traffic to primary, copies the canary spec to primary
+// and scales down the canary, returns false if the promotion has to be retried
+func (c *Controller) promoteWithoutAnalysis(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) bool {
 	// route all traffic to primary
 	primaryWeight := c.totalWeight(canary)
 	canaryWeight := 0
 	if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
-		c.recordEventWarningf(canary, "%v", err)
+		c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 		return false
 	}
 	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
 
 	// copy spec and configs from canary to primary
-	c.recordEventInfof(canary, "Copying %s.%s template spec to %s-primary.%s",
-		canary.Spec.TargetRef.Name, canary.Namespace, canary.Spec.TargetRef.Name, canary.Namespace)
+	c.recordEventInfof(canary, flaggerv1.ReasonPromoting, "Copying %s.%s template spec to %s.%s",
+		canary.Spec.TargetRef.Name, canary.Namespace, canary.GetPrimaryName(canary.Spec.TargetRef.Name, c.primarySuffix), canary.Namespace)
 	if err := canaryController.Promote(canary); err != nil {
-		c.recordEventWarningf(canary, "%v", err)
+		c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 		return false
 	}
 
 	// shutdown canary
 	if err := canaryController.ScaleToZero(canary); err != nil {
-		c.recordEventWarningf(canary, "%v", err)
+		c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 		return false
 	}
 
 	// update status phase
 	if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseSucceeded); err != nil {
-		c.recordEventWarningf(canary, "%v", err)
+		c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 		return false
 	}
 
 	// notify
 	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseSucceeded)
-	c.recordEventInfof(canary, "Promotion completed! Canary analysis was skipped for %s.%s",
+	c.recordEventInfof(canary, flaggerv1.ReasonAnalysisSkipped, "Promotion completed! Canary analysis was skipped for %s.%s",
 		canary.Spec.TargetRef.Name, canary.Namespace)
-	c.alert(canary, "Canary analysis was skipped, promotion finished.",
+	c.alert(canary, flaggerv1.ReasonAnalysisSkipped, "Canary analysis was skipped, promotion finished.",
 		false, flaggerv1.SeverityInfo)
+	c.postFluxPromotionEvent(canary)
 
 	return true
 }
@@ -790,6 +1014,12 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 		return false
 	}
+	classified, err := c.withDefaults(canary)
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
+		return false
+	}
+	canary = classified
 
 	if canary.Status.Phase == "" || canary.Status.Phase == flaggerv1.CanaryPhaseInitializing {
 		if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized}); err != nil {
@@ -797,8 +1027,8 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 			return false
 		}
 		c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseInitialized)
-		c.recordEventInfof(canary, "Initialization done! %s.%s", canary.Name, canary.Namespace)
-		c.alert(canary, fmt.Sprintf("New %s detected, initialization completed.", canary.Spec.TargetRef.Kind),
+		c.recordEventInfof(canary, flaggerv1.ReasonInitialized, "Initialization done! %s.%s", canary.Name, canary.Namespace)
+		c.alert(canary, flaggerv1.ReasonInitialized, fmt.Sprintf("New %s detected, initialization completed.", canary.Spec.TargetRef.Kind),
 			true, flaggerv1.SeverityInfo)
 		return false
 	}
@@ -806,15 +1036,19 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 	if shouldAdvance {
 		canaryPhaseProgressing := canary.DeepCopy()
 		canaryPhaseProgressing.Status.Phase = flaggerv1.CanaryPhaseProgressing
-		c.recordEventInfof(canaryPhaseProgressing, "New revision detected! Scaling up %s.%s", canaryPhaseProgressing.Spec.TargetRef.Name, canaryPhaseProgressing.Namespace)
-		c.alert(canaryPhaseProgressing, "New revision detected, progressing canary analysis.",
+		canaryPhaseProgressing.Status.RunID = newRunID()
+		c.recordEventInfof(canaryPhaseProgressing, flaggerv1.ReasonNewRevisionDetected, "New revision detected! Scaling up %s.%s", canaryPhaseProgressing.Spec.TargetRef.Name, canaryPhaseProgressing.Namespace)
+		c.alert(canaryPhaseProgressing, flaggerv1.ReasonNewRevisionDetected, "New revision detected, progressing canary analysis.",
 			true, flaggerv1.SeverityInfo)
 
 		if err := canaryController.ScaleFromZero(canary); err != nil {
-			c.recordEventErrorf(canary, "%v", err)
+			c.recordEventErrorf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 			return false
 		}
-		if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing}); err != nil {
+		if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{
+			Phase: flaggerv1.CanaryPhaseProgressing,
+			RunID: canaryPhaseProgressing.Status.RunID,
+		}); err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 			return false
 		}
@@ -839,9 +1073,9 @@ func (c *Controller) hasCanaryRevisionChanged(canary *flaggerv1.Canary, canaryCo
 
 func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) {
 	if canary.Status.FailedChecks >= canary.GetAnalysisThreshold() {
-		c.recordEventWarningf(canary, "Rolling back %s.%s failed checks threshold reached %v",
+		c.recordEventWarningf(canary, flaggerv1.ReasonFailedChecksThresholdReached, "Rolling back %s.%s failed checks threshold reached %v",
 			canary.Name, canary.Namespace, canary.Status.FailedChecks)
-		c.alert(canary, fmt.Sprintf("Failed checks threshold reached %v", canary.Status.FailedChecks),
+		c.alert(canary, flaggerv1.ReasonFailedChecksThresholdReached, fmt.Sprintf("Failed checks threshold reached %v", canary.Status.FailedChecks),
 			false, flaggerv1.SeverityError)
 	}
 
@@ -849,20 +1083,20 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 	primaryWeight := c.totalWeight(canary)
 	canaryWeight := 0
 	if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
-		c.recordEventWarningf(canary, "%v", err)
+		c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 		return
 	}
 
 	canaryPhaseFailed := canary.DeepCopy()
 	canaryPhaseFailed.Status.Phase = flaggerv1.CanaryPhaseFailed
-	c.recordEventWarningf(canaryPhaseFailed, "Canary failed! Scaling down %s.%s",
+	c.recordEventWarningf(canaryPhaseFailed, flaggerv1.ReasonFailed, "Canary failed! Scaling down %s.%s",
 		canaryPhaseFailed.Name, canaryPhaseFailed.Namespace)
 
 	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
 
 	// shutdown canary
 	if err := canaryController.ScaleToZero(canary); err != nil {
-		c.recordEventWarningf(canary, "%v", err)
+		c.recordEventWarningf(canary, flaggerv1.ReasonSyncFailed, "%v", err)
 		return
 	}
 
@@ -892,6 +1126,7 @@ func (c *Controller) setPhaseInitializing(cd *flaggerv1.Canary) error {
 			cdCopy := cd.DeepCopy()
 			cdCopy.Status.Conditions = conditions
 			cdCopy.Status.LastTransitionTime = metav1.Now()
+			cdCopy.Status.CompletionTime = canary.MakeCompletionTime(cd, phase)
 			cdCopy.Status.Phase = phase
 			_, err = c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
 		}
@@ -904,3 +1139,33 @@ func (c *Controller) setPhaseInitializing(cd *flaggerv1.Canary) error {
 	}
 	return nil
 }
+
+// setSuspendedCondition updates the Suspended condition of the canary
+// and returns the updated canary if the condition has changed
+func (c *Controller) setSuspendedCondition(cd *flaggerv1.Canary, suspended bool) (*flaggerv1.Canary, error) {
+	var updated *flaggerv1.Canary
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		if ok, conditions := canary.MakeSuspendedCondition(cd, suspended); ok {
+			cdCopy := cd.DeepCopy()
+			cdCopy.Status.Conditions = conditions
+			cdCopy.Status.LastTransitionTime = metav1.Now()
+			updated, err = c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		}
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed after retries: %w", err)
+	}
+	return updated, nil
+}
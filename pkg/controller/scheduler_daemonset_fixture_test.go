@@ -89,6 +89,8 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
 		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
 		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		WaveInformer:   flaggerInformerFactory.Flagger().V1beta1().CanaryWaves(),
+		ClassInformer:  flaggerInformerFactory.Flagger().V1beta1().CanaryClasses(),
 	}
 
 	// init router
@@ -111,7 +113,7 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		flaggerInformers: fi,
 		flaggerSynced:    fi.CanaryInformer.Informer().HasSynced,
 		workqueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerAgentName),
-		eventRecorder:    &record.FakeRecorder{},
+		eventSinks:       []EventSink{NewKubernetesEventSink(&record.FakeRecorder{}), NewLogEventSink(logger)},
 		logger:           logger,
 		canaries:         new(sync.Map),
 		flaggerWindow:    time.Second,
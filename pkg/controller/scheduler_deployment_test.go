@@ -27,6 +27,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -67,6 +68,40 @@ func TestScheduler_DeploymentNewRevision(t *testing.T) {
 	assert.Equal(t, int32(1), *c.Spec.Replicas)
 }
 
+func TestScheduler_DeploymentTotalWeight(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	canary.Spec.Provider = "istio"
+	canary.Spec.Analysis.TotalWeight = 1000
+	mocks := newDeploymentFixture(canary)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the routes of the providers other than NGINX are not set out of the custom total weight
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	primaryWeight, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, primaryWeight)
+	assert.Equal(t, 10, canaryWeight)
+}
+
 func TestScheduler_DeploymentRollback(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 	// initializing
@@ -147,6 +182,119 @@ func TestScheduler_DeploymentSkipAnalysis(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
 }
 
+func TestScheduler_DeploymentSkipActions(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	annotate := func(value string) {
+		cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		cd.Annotations = map[string]string{skipAnnotation: value}
+		_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	// actions are discarded when the canary is not progressing
+	annotate(skipPromotion)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, skipAnnotation)
+	assert.Equal(t, flaggerv1.CanaryPhaseInitialized, c.Status.Phase)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// skip the analysis of the current step
+	annotate(skipStep)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, skipAnnotation)
+	assert.Equal(t, 20, c.Status.CanaryWeight)
+
+	// skip to promotion
+	annotate(skipPromotion)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, skipAnnotation)
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dep2.Spec.Template.Spec.Containers[0].Image, primary.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestScheduler_DeploymentAbortAction(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// abort
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Annotations = map[string]string{abortAnnotation: "true"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, abortAnnotation)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+
+	canary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *canary.Spec.Replicas)
+}
+
 func TestScheduler_DeploymentAnalysisPhases(t *testing.T) {
 	cd := newDeploymentTestCanary()
 	cd.Spec.Analysis = &flaggerv1.CanaryAnalysis{
@@ -276,6 +424,11 @@ func TestScheduler_DeploymentNewRevisionReset(t *testing.T) {
 	assert.Equal(t, 10, canaryWeight)
 	assert.False(t, mirrored)
 
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	firstRunID := c.Status.RunID
+	assert.NotEmpty(t, firstRunID)
+
 	// second update
 	dep2.Spec.Template.Spec.ServiceAccountName = "test"
 	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
@@ -289,6 +442,132 @@ func TestScheduler_DeploymentNewRevisionReset(t *testing.T) {
 	assert.Equal(t, 100, primaryWeight)
 	assert.Equal(t, 0, canaryWeight)
 	assert.False(t, mirrored)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, c.Status.RunID)
+	assert.NotEqual(t, firstRunID, c.Status.RunID)
+}
+
+func TestScheduler_DeploymentSuspend(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// suspend
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Spec.Suspend = true
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// hold
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, primaryWeight)
+	assert.Equal(t, 10, canaryWeight)
+
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, cd.Status.Phase)
+	assert.Equal(t, 10, cd.Status.CanaryWeight)
+	require.Len(t, cd.Status.Conditions, 3)
+	assert.Equal(t, flaggerv1.PromotedType, cd.Status.Conditions[0].Type)
+	assert.Equal(t, flaggerv1.PromotionCompleteType, cd.Status.Conditions[1].Type)
+	assert.Equal(t, corev1.ConditionFalse, cd.Status.Conditions[1].Status)
+	assert.Equal(t, flaggerv1.SuspendedType, cd.Status.Conditions[2].Type)
+	assert.Equal(t, corev1.ConditionTrue, cd.Status.Conditions[2].Status)
+
+	// resume
+	cd.Spec.Suspend = false
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// advance from the same step
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	primaryWeight, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 80, primaryWeight)
+	assert.Equal(t, 20, canaryWeight)
+
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, cd.Status.Conditions, 3)
+	assert.Equal(t, corev1.ConditionFalse, cd.Status.Conditions[2].Status)
+	assert.Equal(t, string(flaggerv1.ReasonResumed), cd.Status.Conditions[2].Reason)
+}
+
+func TestScheduler_DeploymentSoakIterations(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	c.Spec.Analysis.SoakIterations = 2
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// reach the max weight
+	err = mocks.router.SetRoutes(mocks.canary, 50, 50, false)
+	require.NoError(t, err)
+
+	// soak at the max weight
+	for i := 1; i <= 2; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+
+		c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+		assert.Equal(t, i, c.Status.Iterations)
+
+		_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+		require.NoError(t, err)
+		assert.Equal(t, 50, canaryWeight)
+	}
+
+	// promote
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
 }
 
 func TestScheduler_DeploymentPromotion(t *testing.T) {
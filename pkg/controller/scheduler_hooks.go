@@ -26,14 +26,15 @@ import (
 func (c *Controller) runConfirmTrafficIncreaseHooks(canary *flaggerv1.Canary) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.ConfirmTrafficIncreaseHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			resp, err := c.callWebhookResponse(canary, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for traffic increase approval %s",
+				c.recordEventWarningf(canary, flaggerv1.ReasonWaitingForApproval, "Halt %s.%s advancement waiting for traffic increase approval %s",
 					canary.Name, canary.Namespace, webhook.Name)
-				c.alert(canary, "Canary traffic increase is waiting for approval.", false, flaggerv1.SeverityWarn)
+				c.alert(canary, flaggerv1.ReasonWaitingForApproval, "Canary traffic increase is waiting for approval.", false, flaggerv1.SeverityWarn)
 				return false
 			}
-			c.recordEventInfof(canary, "Confirm-traffic-increase check %s passed", webhook.Name)
+			c.recordApproval(canary, webhook, resp)
+			c.recordEventInfof(canary, flaggerv1.ReasonWebhookPassed, "Confirm-traffic-increase check %s passed", webhook.Name)
 		}
 	}
 	return true
@@ -42,24 +43,25 @@ func (c *Controller) runConfirmTrafficIncreaseHooks(canary *flaggerv1.Canary) bo
 func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.ConfirmRolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			resp, err := c.callWebhookResponse(canary, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
 				if canary.Status.Phase != flaggerv1.CanaryPhaseWaiting {
 					if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseWaiting); err != nil {
 						c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 					}
-					c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for approval %s",
+					c.recordEventWarningf(canary, flaggerv1.ReasonWaitingForApproval, "Halt %s.%s advancement waiting for approval %s",
 						canary.Name, canary.Namespace, webhook.Name)
-					c.alert(canary, "Canary is waiting for approval.", false, flaggerv1.SeverityWarn)
+					c.alert(canary, flaggerv1.ReasonWaitingForApproval, "Canary is waiting for approval.", false, flaggerv1.SeverityWarn)
 				}
 				return false
 			} else {
+				c.recordApproval(canary, webhook, resp)
 				if canary.Status.Phase == flaggerv1.CanaryPhaseWaiting {
 					if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseProgressing); err != nil {
 						c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 						return false
 					}
-					c.recordEventInfof(canary, "Confirm-rollout check %s passed", webhook.Name)
+					c.recordEventInfof(canary, flaggerv1.ReasonWebhookPassed, "Confirm-rollout check %s passed", webhook.Name)
 					return false
 				}
 			}
@@ -71,19 +73,20 @@ func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryCont
 func (c *Controller) runConfirmPromotionHooks(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.ConfirmPromotionHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			resp, err := c.callWebhookResponse(canary, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
 				if canary.Status.Phase != flaggerv1.CanaryWaitingPromotion {
 					if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryWaitingPromotion); err != nil {
 						c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 					}
-					c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for promotion approval %s",
+					c.recordEventWarningf(canary, flaggerv1.ReasonWaitingForApproval, "Halt %s.%s advancement waiting for promotion approval %s",
 						canary.Name, canary.Namespace, webhook.Name)
-					c.alert(canary, "Canary promotion is waiting for approval.", false, flaggerv1.SeverityWarn)
+					c.alert(canary, flaggerv1.ReasonWaitingForApproval, "Canary promotion is waiting for approval.", false, flaggerv1.SeverityWarn)
 				}
 				return false
 			} else {
-				c.recordEventInfof(canary, "Confirm-promotion check %s passed", webhook.Name)
+				c.recordApproval(canary, webhook, resp)
+				c.recordEventInfof(canary, flaggerv1.ReasonWebhookPassed, "Confirm-promotion check %s passed", webhook.Name)
 			}
 		}
 	}
@@ -93,13 +96,13 @@ func (c *Controller) runConfirmPromotionHooks(canary *flaggerv1.Canary, canaryCo
 func (c *Controller) runPreRolloutHooks(canary *flaggerv1.Canary) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.PreRolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			err := c.callWebhook(canary, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement pre-rollout check %s failed %v",
+				c.recordEventWarningf(canary, webhookFailureReason(err), "Halt %s.%s advancement pre-rollout check %s failed %v",
 					canary.Name, canary.Namespace, webhook.Name, err)
 				return false
 			} else {
-				c.recordEventInfof(canary, "Pre-rollout check %s passed", webhook.Name)
+				c.recordEventInfof(canary, flaggerv1.ReasonWebhookPassed, "Pre-rollout check %s passed", webhook.Name)
 			}
 		}
 	}
@@ -109,12 +112,12 @@ func (c *Controller) runPreRolloutHooks(canary *flaggerv1.Canary) bool {
 func (c *Controller) runPostRolloutHooks(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.PostRolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, phase, webhook)
+			err := c.callWebhook(canary, phase, webhook)
 			if err != nil {
-				c.recordEventWarningf(canary, "Post-rollout hook %s failed %v", webhook.Name, err)
+				c.recordEventWarningf(canary, webhookFailureReason(err), "Post-rollout hook %s failed %v", webhook.Name, err)
 				return false
 			} else {
-				c.recordEventInfof(canary, "Post-rollout check %s passed", webhook.Name)
+				c.recordEventInfof(canary, flaggerv1.ReasonWebhookPassed, "Post-rollout check %s passed", webhook.Name)
 			}
 		}
 	}
@@ -124,11 +127,11 @@ func (c *Controller) runPostRolloutHooks(canary *flaggerv1.Canary, phase flagger
 func (c *Controller) runRollbackHooks(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.RollbackHook {
-			err := CallWebhook(canary.Name, canary.Namespace, phase, webhook)
+			err := c.callWebhook(canary, phase, webhook)
 			if err != nil {
-				c.recordEventInfof(canary, "Rollback hook %s not signaling a rollback", webhook.Name)
+				c.recordEventInfof(canary, flaggerv1.ReasonWebhookPassed, "Rollback hook %s not signaling a rollback", webhook.Name)
 			} else {
-				c.recordEventWarningf(canary, "Rollback check %s passed", webhook.Name)
+				c.recordEventWarningf(canary, flaggerv1.ReasonManualRollback, "Rollback check %s passed", webhook.Name)
 				return true
 			}
 		}
@@ -38,13 +38,9 @@ const (
 func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) error {
 	for _, metric := range canary.GetAnalysis().Metrics {
 		if metric.Name == "request-success-rate" || metric.Name == "request-duration" {
-			observerFactory := c.observerFactory
-			if canary.Spec.MetricsServer != "" {
-				var err error
-				observerFactory, err = observers.NewFactory(canary.Spec.MetricsServer)
-				if err != nil {
-					return fmt.Errorf("error building Prometheus client for %s %v", canary.Spec.MetricsServer, err)
-				}
+			observerFactory, err := c.metricsObserverFactory(canary)
+			if err != nil {
+				return fmt.Errorf("error building Prometheus client for %s %v", canary.Spec.MetricsServer, err)
 			}
 			if ok, err := observerFactory.Client.IsOnline(); !ok || err != nil {
 				return fmt.Errorf("prometheus not avaiable: %v", err)
@@ -52,6 +48,17 @@ func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) e
 			continue
 		}
 
+		if metric.Name == resourceCostMetric && metric.TemplateRef == nil {
+			provider, err := c.openCostProvider(canary.GetMetricInterval())
+			if err != nil {
+				return err
+			}
+			if ok, err := provider.IsOnline(); !ok || err != nil {
+				return fmt.Errorf("opencost not avaiable: %v", err)
+			}
+			continue
+		}
+
 		if metric.TemplateRef != nil {
 			namespace := canary.Namespace
 			if metric.TemplateRef.Namespace != "" {
@@ -63,12 +70,17 @@ func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) e
 				return fmt.Errorf("metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
 			}
 
+			secretNamespace, secretRef, err := c.metricSecretRef(canary, metric, template)
+			if err != nil {
+				return err
+			}
+
 			var credentials map[string][]byte
-			if template.Spec.Provider.SecretRef != nil {
-				secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
+			if secretRef != nil {
+				secret, err := c.kubeClient.CoreV1().Secrets(secretNamespace).Get(context.TODO(), secretRef.Name, metav1.GetOptions{})
 				if err != nil {
 					return fmt.Errorf("metric template %s.%s secret %s error: %v",
-						metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
+						metric.TemplateRef.Name, namespace, secretRef.Name, err)
 				}
 				credentials = secret.Data
 			}
@@ -86,7 +98,7 @@ func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) e
 			}
 		}
 	}
-	c.recordEventInfof(canary, "all the metrics providers are available!")
+	c.recordEventInfof(canary, flaggerv1.ReasonMetricProvidersAvailable, "all the metrics providers are available!")
 	return nil
 }
 
@@ -115,16 +127,11 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 	}
 
 	// create observer based on the mesh provider
-	observerFactory := c.observerFactory
-
 	// override the global metrics server if one is specified in the canary spec
-	if canary.Spec.MetricsServer != "" {
-		var err error
-		observerFactory, err = observers.NewFactory(canary.Spec.MetricsServer)
-		if err != nil {
-			c.recordEventErrorf(canary, "Error building Prometheus client for %s %v", canary.Spec.MetricsServer, err)
-			return false
-		}
+	observerFactory, err := c.metricsObserverFactory(canary)
+	if err != nil {
+		c.recordEventErrorf(canary, flaggerv1.ReasonMetricProviderUnavailable, "Error building Prometheus client for %s %v", canary.Spec.MetricsServer, err)
+		return false
 	}
 	observer := observerFactory.Observer(metricsProvider)
 
@@ -135,14 +142,16 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 		}
 
 		if metric.Name == "request-success-rate" {
+			done := c.instrumentMetricQuery(canary, metric, metricsProvider)
 			val, err := observer.GetRequestSuccessRate(toMetricModel(canary, metric.Interval))
+			done(val, err)
 			if err != nil {
 				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary,
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricNoValuesFound,
 						"Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic: %v",
 						metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace, err)
 				} else {
-					c.recordEventErrorf(canary, "Prometheus query failed: %v", err)
+					c.recordEventErrorf(canary, flaggerv1.ReasonMetricQueryFailed, "Prometheus query failed: %v", err)
 				}
 				return false
 			}
@@ -150,78 +159,88 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement success rate %.2f%% < %v%%",
 						canary.Name, canary.Namespace, val, *tr.Min)
 					return false
 				}
 				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% > %v%%",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement success rate %.2f%% > %v%%",
 						canary.Name, canary.Namespace, val, *tr.Max)
 					return false
 				}
 			} else if metric.Threshold > val {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
+				c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement success rate %.2f%% < %v%%",
 					canary.Name, canary.Namespace, val, metric.Threshold)
 				return false
 			}
 		}
 
 		if metric.Name == "request-duration" {
+			done := c.instrumentMetricQuery(canary, metric, metricsProvider)
 			val, err := observer.GetRequestDuration(toMetricModel(canary, metric.Interval))
+			done(float64(val)/float64(time.Millisecond), err)
 			if err != nil {
 				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary, "Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricNoValuesFound, "Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic",
 						metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace)
 				} else {
-					c.recordEventErrorf(canary, "Prometheus query failed: %v", err)
+					c.recordEventErrorf(canary, flaggerv1.ReasonMetricQueryFailed, "Prometheus query failed: %v", err)
 				}
 				return false
 			}
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < time.Duration(*tr.Min)*time.Millisecond {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v < %v",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement request duration %v < %v",
 						canary.Name, canary.Namespace, val, time.Duration(*tr.Min)*time.Millisecond)
 					return false
 				}
 				if tr.Max != nil && val > time.Duration(*tr.Max)*time.Millisecond {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v > %v",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement request duration %v > %v",
 						canary.Name, canary.Namespace, val, time.Duration(*tr.Max)*time.Millisecond)
 					return false
 				}
 			} else if val > time.Duration(metric.Threshold)*time.Millisecond {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v > %v",
+				c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement request duration %v > %v",
 					canary.Name, canary.Namespace, val, time.Duration(metric.Threshold)*time.Millisecond)
 				return false
 			}
 		}
 
+		if metric.Name == resourceCostMetric && metric.TemplateRef == nil && metric.Query == "" {
+			if !c.runResourceCostCheck(canary, metric) {
+				return false
+			}
+		}
+
 		// in-line PromQL
 		if metric.Query != "" {
+			done := c.instrumentMetricQuery(canary, metric, "prometheus")
 			val, err := observerFactory.Client.RunQuery(metric.Query)
+			done(val, err)
 			if err != nil {
 				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary, "Halt advancement no values found for metric: %s",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricNoValuesFound, "Halt advancement no values found for metric: %s",
 						metric.Name)
 				} else {
-					c.recordEventErrorf(canary, "Prometheus query failed for %s: %v", metric.Name, err)
+					c.recordEventErrorf(canary, flaggerv1.ReasonMetricQueryFailed, "Prometheus query failed for %s: %v", metric.Name, err)
 				}
 				return false
 			}
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement %s %.2f < %v",
 						canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
 					return false
 				}
 				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement %s %.2f > %v",
 						canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
 					return false
 				}
 			} else if val > metric.Threshold {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
+				c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement %s %.2f > %v",
 					canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
 				return false
 			}
@@ -241,16 +260,26 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 
 			template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(metric.TemplateRef.Name)
 			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
+				c.recordEventErrorf(canary, flaggerv1.ReasonMetricTemplateInvalid, "Metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
+				c.recordMetricResult(canary, metric, nil, fmt.Errorf("metric template %s.%s error: %w", metric.TemplateRef.Name, namespace, err))
+				return false
+			}
+
+			secretNamespace, secretRef, err := c.metricSecretRef(canary, metric, template)
+			if err != nil {
+				c.recordEventErrorf(canary, flaggerv1.ReasonMetricTemplateInvalid, "%v", err)
+				c.recordMetricResult(canary, metric, nil, err)
 				return false
 			}
 
 			var credentials map[string][]byte
-			if template.Spec.Provider.SecretRef != nil {
-				secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
+			if secretRef != nil {
+				secret, err := c.kubeClient.CoreV1().Secrets(secretNamespace).Get(context.TODO(), secretRef.Name, metav1.GetOptions{})
 				if err != nil {
-					c.recordEventErrorf(canary, "Metric template %s.%s secret %s error: %v",
-						metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
+					c.recordEventErrorf(canary, flaggerv1.ReasonMetricTemplateInvalid, "Metric template %s.%s secret %s error: %v",
+						metric.TemplateRef.Name, namespace, secretRef.Name, err)
+					c.recordMetricResult(canary, metric, nil, fmt.Errorf("metric template %s.%s secret %s error: %w",
+						metric.TemplateRef.Name, namespace, secretRef.Name, err))
 					return false
 				}
 				credentials = secret.Data
@@ -259,25 +288,31 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 			factory := providers.Factory{}
 			provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials)
 			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s provider %s error: %v",
+				c.recordEventErrorf(canary, flaggerv1.ReasonMetricProviderUnavailable, "Metric template %s.%s provider %s error: %v",
 					metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
+				c.recordMetricResult(canary, metric, nil, fmt.Errorf("metric template %s.%s provider %s error: %w",
+					metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err))
 				return false
 			}
 
 			query, err := observers.RenderQuery(template.Spec.Query, toMetricModel(canary, metric.Interval))
 			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s query render error: %v",
+				c.recordEventErrorf(canary, flaggerv1.ReasonMetricTemplateInvalid, "Metric template %s.%s query render error: %v",
 					metric.TemplateRef.Name, namespace, err)
+				c.recordMetricResult(canary, metric, nil, fmt.Errorf("metric template %s.%s query render error: %w",
+					metric.TemplateRef.Name, namespace, err))
 				return false
 			}
 
+			done := c.instrumentMetricQuery(canary, metric, template.Spec.Provider.Type)
 			val, err := provider.RunQuery(query)
+			done(val, err)
 			if err != nil {
 				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary, "Halt advancement no values found for custom metric: %s: %v",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricNoValuesFound, "Halt advancement no values found for custom metric: %s: %v",
 						metric.Name, err)
 				} else {
-					c.recordEventErrorf(canary, "Metric query failed for %s: %v", metric.Name, err)
+					c.recordEventErrorf(canary, flaggerv1.ReasonMetricQueryFailed, "Metric query failed for %s: %v", metric.Name, err)
 				}
 				return false
 			}
@@ -285,17 +320,17 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement %s %.2f < %v",
 						canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
 					return false
 				}
 				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
+					c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement %s %.2f > %v",
 						canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
 					return false
 				}
 			} else if val > metric.Threshold {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
+				c.recordEventWarningf(canary, flaggerv1.ReasonMetricThresholdBreached, "Halt %s.%s advancement %s %.2f > %v",
 					canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
 				return false
 			}
@@ -316,7 +351,7 @@ func toMetricModel(r *flaggerv1.Canary, interval string) flaggerv1.MetricTemplat
 	}
 	return flaggerv1.MetricTemplateModel{
 		Name:      r.Name,
-		Namespace: r.Namespace,
+		Namespace: r.GetTargetNamespace(),
 		Target:    r.Spec.TargetRef.Name,
 		Service:   service,
 		Ingress:   ingress,
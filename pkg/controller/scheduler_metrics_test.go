@@ -34,7 +34,7 @@ func TestController_checkMetricProviderAvailability(t *testing.T) {
 		canary := &flaggerv1.Canary{Spec: flaggerv1.CanarySpec{Analysis: analysis}}
 		obs, err := observers.NewFactory(testMetricsServerURL)
 		require.NoError(t, err)
-		ctrl := Controller{observerFactory: obs, logger: zap.S(), eventRecorder: &record.FakeRecorder{}}
+		ctrl := Controller{observerFactory: obs, logger: zap.S(), eventSinks: []EventSink{NewKubernetesEventSink(&record.FakeRecorder{})}}
 		require.NoError(t, ctrl.checkMetricProviderAvailability(canary))
 
 		// error
@@ -0,0 +1,259 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/notifier"
+)
+
+// Settings are the controller options that can be changed without a restart,
+// the ConfigMap keys are the names of the equivalent command-line flags
+type Settings struct {
+	MetricsServer      string
+	IncludeLabelPrefix []string
+	SlackURL           string
+	SlackUser          string
+	SlackChannel       string
+	MSTeamsURL         string
+	AnalysisInterval   time.Duration
+
+	IstioReporter              string
+	IstioLabelMatchers         []string
+	IstioRequestsMetric        string
+	IstioRequestDurationMetric string
+}
+
+const (
+	settingMetricsServer      = "metrics-server"
+	settingIncludeLabelPrefix = "include-label-prefix"
+	settingSlackURL           = "slack-url"
+	settingSlackUser          = "slack-user"
+	settingSlackChannel       = "slack-channel"
+	settingMSTeamsURL         = "msteams-url"
+	settingAnalysisInterval   = "analysis-interval"
+
+	settingIstioReporter              = "istio-reporter"
+	settingIstioLabelMatchers         = "istio-label-matchers"
+	settingIstioRequestsMetric        = "istio-requests-metric"
+	settingIstioRequestDurationMetric = "istio-request-duration-metric"
+)
+
+// ParseSettings overrides the defaults with the values found in the ConfigMap data
+func ParseSettings(data map[string]string, defaults Settings) (Settings, error) {
+	s := defaults
+	var unknown []string
+	for key, value := range data {
+		value = strings.TrimSpace(value)
+		switch key {
+		case settingMetricsServer:
+			s.MetricsServer = value
+		case settingIncludeLabelPrefix:
+			s.IncludeLabelPrefix = strings.Split(value, ",")
+		case settingSlackURL:
+			s.SlackURL = value
+		case settingSlackUser:
+			s.SlackUser = value
+		case settingSlackChannel:
+			s.SlackChannel = value
+		case settingMSTeamsURL:
+			s.MSTeamsURL = value
+		case settingAnalysisInterval:
+			interval, err := time.ParseDuration(value)
+			if err != nil || interval <= 0 {
+				return defaults, fmt.Errorf("%s must be a positive duration, got %q", settingAnalysisInterval, value)
+			}
+			s.AnalysisInterval = interval
+		case settingIstioReporter:
+			s.IstioReporter = value
+		case settingIstioLabelMatchers:
+			s.IstioLabelMatchers = nil
+			if value != "" {
+				s.IstioLabelMatchers = strings.Split(value, ",")
+			}
+		case settingIstioRequestsMetric:
+			s.IstioRequestsMetric = value
+		case settingIstioRequestDurationMetric:
+			s.IstioRequestDurationMetric = value
+		default:
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return defaults, fmt.Errorf("unknown settings %s", strings.Join(unknown, ", "))
+	}
+	if s.MetricsServer == "" {
+		return defaults, fmt.Errorf("%s can't be empty", settingMetricsServer)
+	}
+	if err := s.IstioOptions().Validate(); err != nil {
+		return defaults, fmt.Errorf("istio queries settings are invalid: %w", err)
+	}
+	return s, nil
+}
+
+// IstioOptions returns the options of the builtin Istio queries
+func (s Settings) IstioOptions() observers.IstioOptions {
+	return observers.IstioOptions{
+		Reporter:              s.IstioReporter,
+		LabelMatchers:         s.IstioLabelMatchers,
+		RequestsMetric:        s.IstioRequestsMetric,
+		RequestDurationMetric: s.IstioRequestDurationMetric,
+	}
+}
+
+// Notifier returns the global notifier, MS Teams is used when its URL is set
+func (s Settings) Notifier() (notifier.Interface, error) {
+	provider, url := "slack", s.SlackURL
+	if s.MSTeamsURL != "" {
+		provider, url = "msteams", s.MSTeamsURL
+	}
+	return notifier.NewFactory(url, s.SlackUser, s.SlackChannel, "").Notifier(provider)
+}
+
+// ApplySettings replaces the metrics server client, the builtin queries options,
+// the global notifier, the label prefixes and the default analysis interval,
+// the canaries in progress use the new settings from their next analysis iteration
+func (c *Controller) ApplySettings(s Settings) error {
+	observerFactory, err := observers.NewFactory(s.MetricsServer)
+	if err != nil {
+		return fmt.Errorf("error building prometheus client for %s: %w", s.MetricsServer, err)
+	}
+	observerFactory.Istio = s.IstioOptions()
+	notifierClient, err := s.Notifier()
+	if err != nil {
+		return fmt.Errorf("error building notifier: %w", err)
+	}
+
+	c.settingsMu.Lock()
+	c.observerFactory = observerFactory
+	c.notifier = notifierClient
+	c.settingsMu.Unlock()
+	c.SetDefaultAnalysisInterval(s.AnalysisInterval)
+
+	if c.canaryFactory != nil {
+		c.canaryFactory.SetIncludeLabelPrefix(s.IncludeLabelPrefix)
+	}
+	return nil
+}
+
+// WatchSettings reloads the settings when the ConfigMap changes,
+// the defaults are restored when the ConfigMap is deleted
+func (c *Controller) WatchSettings(namespace string, name string, defaults Settings, stopCh <-chan struct{}) {
+	factory := kubeinformers.NewSharedInformerFactoryWithOptions(c.kubeClient, 5*time.Minute,
+		kubeinformers.WithNamespace(namespace),
+		kubeinformers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+
+	reload := func(obj interface{}) {
+		cm, ok := obj.(*corev1.ConfigMap)
+		if !ok {
+			return
+		}
+		s, err := ParseSettings(cm.Data, defaults)
+		if err != nil {
+			c.logger.Errorf("Settings from ConfigMap %s.%s are invalid: %v", name, namespace, err)
+			return
+		}
+		if err := c.ApplySettings(s); err != nil {
+			c.logger.Errorf("Settings from ConfigMap %s.%s can't be applied: %v", name, namespace, err)
+			return
+		}
+		c.logger.Infof("Settings reloaded from ConfigMap %s.%s", name, namespace)
+	}
+
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: reload,
+		UpdateFunc: func(old, new interface{}) {
+			oldCM, ok := old.(*corev1.ConfigMap)
+			if !ok {
+				return
+			}
+			newCM, ok := new.(*corev1.ConfigMap)
+			if !ok || oldCM.ResourceVersion == newCM.ResourceVersion {
+				return
+			}
+			reload(new)
+		},
+		DeleteFunc: func(old interface{}) {
+			if err := c.ApplySettings(defaults); err != nil {
+				c.logger.Errorf("Default settings can't be applied: %v", err)
+				return
+			}
+			c.logger.Infof("ConfigMap %s.%s deleted, default settings restored", name, namespace)
+		},
+	})
+	go informer.Run(stopCh)
+}
+
+// metricsObserverFactory returns the observer factory of the canary metrics server, or the global one
+// when the canary doesn't override it, the builtin queries options are the global ones
+func (c *Controller) metricsObserverFactory(canary *flaggerv1.Canary) (*observers.Factory, error) {
+	observerFactory := c.getObserverFactory()
+	if canary.Spec.MetricsServer == "" {
+		return observerFactory, nil
+	}
+	factory, err := observers.NewFactory(canary.Spec.MetricsServer)
+	if err != nil {
+		return nil, err
+	}
+	if observerFactory != nil {
+		factory.Istio = observerFactory.Istio
+	}
+	return factory, nil
+}
+
+func (c *Controller) getObserverFactory() *observers.Factory {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.observerFactory
+}
+
+func (c *Controller) getNotifier() notifier.Interface {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.notifier
+}
+
+// SetDefaultAnalysisInterval sets the analysis interval of the canaries that don't set one,
+// the interval defaults to 60s when empty
+func (c *Controller) SetDefaultAnalysisInterval(interval time.Duration) {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+	c.defaultAnalysisInterval = interval
+}
+
+// analysisInterval returns the analysis interval of the canary or the default analysis interval
+func (c *Controller) analysisInterval(cd *flaggerv1.Canary) time.Duration {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return cd.GetAnalysisIntervalOrDefault(c.defaultAnalysisInterval)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/notifier"
+)
+
+func TestParseSettings(t *testing.T) {
+	defaults := Settings{MetricsServer: "http://prometheus:9090", SlackUser: "flagger"}
+
+	s, err := ParseSettings(map[string]string{
+		"metrics-server":       "http://prometheus.monitoring:9090",
+		"include-label-prefix": "app.kubernetes.io,team",
+		"slack-url":            "https://hooks.slack.com/services/test",
+	}, defaults)
+	require.NoError(t, err)
+	assert.Equal(t, "http://prometheus.monitoring:9090", s.MetricsServer)
+	assert.Equal(t, []string{"app.kubernetes.io", "team"}, s.IncludeLabelPrefix)
+	assert.Equal(t, "https://hooks.slack.com/services/test", s.SlackURL)
+	assert.Equal(t, "flagger", s.SlackUser)
+
+	_, err = ParseSettings(map[string]string{"metrics-servers": "http://prometheus:9090"}, defaults)
+	assert.Error(t, err)
+
+	_, err = ParseSettings(map[string]string{"metrics-server": ""}, defaults)
+	assert.Error(t, err)
+
+	s, err = ParseSettings(map[string]string{
+		"istio-reporter":        "source",
+		"istio-label-matchers":  `source_cluster="west",mesh_id="mesh1"`,
+		"istio-requests-metric": "istio_requests_total_1_18",
+	}, defaults)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`source_cluster="west"`, `mesh_id="mesh1"`}, s.IstioOptions().LabelMatchers)
+	assert.Equal(t, "istio_requests_total_1_18", s.IstioOptions().RequestsMetric)
+
+	_, err = ParseSettings(map[string]string{"istio-label-matchers": "source_cluster"}, defaults)
+	assert.Error(t, err)
+
+	s, err = ParseSettings(map[string]string{"analysis-interval": "30s"}, defaults)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, s.AnalysisInterval)
+
+	_, err = ParseSettings(map[string]string{"analysis-interval": "30"}, defaults)
+	assert.Error(t, err)
+}
+
+func TestController_DefaultAnalysisInterval(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	cd := newDeploymentTestCanary()
+	assert.Equal(t, flaggerv1.AnalysisInterval, mocks.ctrl.analysisInterval(cd))
+
+	// the jobs of the canaries without an interval use the default interval
+	mocks.ctrl.SetDefaultAnalysisInterval(30 * time.Second)
+	mocks.ctrl.jobs = map[string]CanaryJob{}
+	mocks.ctrl.canaries.Store("podinfo.default", cd)
+	mocks.ctrl.scheduleCanaries()
+	job, ok := mocks.ctrl.jobs["podinfo.default"]
+	require.True(t, ok)
+	defer job.Stop()
+	assert.Equal(t, 30*time.Second, job.GetCanaryAnalysisInterval())
+
+	// the interval of the canary takes precedence
+	withInterval := cd.DeepCopy()
+	withInterval.Spec.Analysis.Interval = "2m"
+	assert.Equal(t, 2*time.Minute, mocks.ctrl.analysisInterval(withInterval))
+
+	// the default interval is reloaded with the settings
+	require.NoError(t, mocks.ctrl.ApplySettings(Settings{MetricsServer: testMetricsServerURL, AnalysisInterval: 15 * time.Second}))
+	assert.Equal(t, 15*time.Second, mocks.ctrl.analysisInterval(cd))
+}
+
+func TestController_WatchSettings(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	defaults := Settings{MetricsServer: testMetricsServerURL, SlackUser: "flagger"}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	mocks.ctrl.WatchSettings("flagger-system", "flagger-settings", defaults, stopCh)
+	observerFactory := mocks.ctrl.getObserverFactory()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "flagger-system", Name: "flagger-settings"},
+		Data: map[string]string{
+			"metrics-server": "http://prometheus.monitoring:9090",
+			"slack-url":      "https://hooks.slack.com/services/test",
+			"slack-channel":  "releases",
+		},
+	}
+	_, err := mocks.kubeClient.CoreV1().ConfigMaps("flagger-system").Create(context.TODO(), cm, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, ok := mocks.ctrl.getNotifier().(*notifier.Slack)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NotSame(t, observerFactory, mocks.ctrl.getObserverFactory())
+
+	// invalid settings are ignored
+	cm.Data = map[string]string{"metrics-server": ""}
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("flagger-system").Update(context.TODO(), cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	_, ok := mocks.ctrl.getNotifier().(*notifier.Slack)
+	assert.True(t, ok)
+
+	// the defaults are restored when the ConfigMap is deleted
+	err = mocks.kubeClient.CoreV1().ConfigMaps("flagger-system").Delete(context.TODO(), "flagger-settings", metav1.DeleteOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, ok := mocks.ctrl.getNotifier().(*notifier.NopNotifier)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
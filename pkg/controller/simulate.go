@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+// SimulationDataset holds the metric values recorded at each analysis iteration
+type SimulationDataset struct {
+	// Metrics are the values by metric name in the iteration order,
+	// a null value is a query that didn't return any value
+	Metrics map[string][]*float64 `json:"metrics"`
+}
+
+// SimulationStep is the outcome of an analysis iteration
+type SimulationStep struct {
+	// Iteration number starting at 1
+	Iteration int `json:"iteration"`
+
+	// CanaryWeight during the iteration
+	CanaryWeight int `json:"canaryWeight"`
+
+	// Mirrored is true when the traffic is mirrored to the canary
+	Mirrored bool `json:"mirrored,omitempty"`
+
+	// Metrics are the results of the metric checks
+	Metrics []flaggerv1.CanaryMetricStatus `json:"metrics"`
+
+	// FailedChecks at the end of the iteration
+	FailedChecks int `json:"failedChecks"`
+}
+
+// SimulationResult is the outcome of a simulated analysis
+type SimulationResult struct {
+	// Phase is succeeded when the canary would be promoted, failed when it would be rolled back
+	// and progressing when the dataset ended before the analysis did
+	Phase flaggerv1.CanaryPhase `json:"phase"`
+
+	// Message describes the outcome
+	Message string `json:"message"`
+
+	// Steps are the simulated iterations
+	Steps []SimulationStep `json:"steps"`
+}
+
+// Simulate replays the recorded metric values through the analysis of the canary,
+// one value per metric at each iteration, and returns the iteration at which the canary
+// would have been rolled back or promoted, the webhooks are not called
+func Simulate(cd *flaggerv1.Canary, dataset SimulationDataset) (*SimulationResult, error) {
+	if cd.SkipAnalysis() {
+		return nil, fmt.Errorf("canary %s.%s has no analysis to simulate", cd.Name, cd.Namespace)
+	}
+	analysis := cd.GetAnalysis()
+	if len(analysis.Metrics) == 0 {
+		return nil, fmt.Errorf("canary %s.%s has no metrics", cd.Name, cd.Namespace)
+	}
+	for _, metric := range analysis.Metrics {
+		if len(dataset.Metrics[metric.Name]) == 0 {
+			return nil, fmt.Errorf("metric %s has no recorded values", metric.Name)
+		}
+	}
+
+	// the step weights don't depend on the controller state
+	c := &Controller{}
+	maxWeight := c.maxWeight(cd)
+	if analysis.Iterations == 0 && c.nextStepWeight(cd, 0) <= 0 {
+		return nil, fmt.Errorf("canary %s.%s has neither iterations nor step weights", cd.Name, cd.Namespace)
+	}
+
+	result := &SimulationResult{}
+	canaryWeight, iterations, soaked, failedChecks := 0, 0, 0, 0
+	mirrored := false
+	for i := 0; ; i++ {
+		step := SimulationStep{
+			Iteration:    i + 1,
+			CanaryWeight: canaryWeight,
+			Mirrored:     mirrored,
+		}
+		if analysis.Iterations > 0 {
+			step.CanaryWeight = c.totalWeight(cd)
+		}
+
+		passed := true
+		for _, metric := range analysis.Metrics {
+			values := dataset.Metrics[metric.Name]
+			if i >= len(values) {
+				result.Phase = flaggerv1.CanaryPhaseProgressing
+				result.Message = fmt.Sprintf("The dataset ended after %d iterations, the analysis is still progressing at canary weight %d",
+					i, canaryWeight)
+				return result, nil
+			}
+			var err error
+			if values[i] == nil {
+				err = fmt.Errorf("%w for metric %s", providers.ErrNoValuesFound, metric.Name)
+			}
+			status := metricResult(metric, values[i], err)
+			status.LastCheckTime = metav1.Time{}
+			passed = passed && status.Passed
+			step.Metrics = append(step.Metrics, status)
+		}
+
+		if !passed {
+			failedChecks++
+		}
+		step.FailedChecks = failedChecks
+		result.Steps = append(result.Steps, step)
+
+		if failedChecks >= cd.GetAnalysisThreshold() {
+			result.Phase = flaggerv1.CanaryPhaseFailed
+			result.Message = fmt.Sprintf("The canary would be rolled back at iteration %d, canary weight %d: failed checks threshold reached %d (%s)",
+				step.Iteration, step.CanaryWeight, failedChecks, failedMetrics(step.Metrics))
+			return result, nil
+		}
+		if !passed {
+			continue
+		}
+
+		// advance like the scheduler does after a successful analysis
+		if analysis.Iterations > 0 {
+			if iterations < analysis.Iterations {
+				iterations++
+				continue
+			}
+			result.Phase = flaggerv1.CanaryPhaseSucceeded
+			result.Message = fmt.Sprintf("The canary would be promoted at iteration %d after %d/%d successful iterations",
+				step.Iteration, iterations, analysis.Iterations)
+			return result, nil
+		}
+		if canaryWeight < maxWeight {
+			nextStepWeight := c.nextStepWeight(cd, canaryWeight)
+			if analysis.Mirror && canaryWeight == 0 && !mirrored {
+				mirrored = true
+				continue
+			}
+			mirrored = false
+			canaryWeight += nextStepWeight
+			if canaryWeight > c.totalWeight(cd) {
+				canaryWeight = c.totalWeight(cd)
+			}
+			continue
+		}
+		if soaked < analysis.SoakIterations {
+			soaked++
+			continue
+		}
+		result.Phase = flaggerv1.CanaryPhaseSucceeded
+		result.Message = fmt.Sprintf("The canary would be promoted at iteration %d, max weight %d reached",
+			step.Iteration, maxWeight)
+		if analysis.SoakIterations > 0 {
+			result.Message += fmt.Sprintf(" and held for %d soak iterations", analysis.SoakIterations)
+		}
+		return result, nil
+	}
+}
+
+// failedMetrics returns the messages of the failed metric checks
+func failedMetrics(metrics []flaggerv1.CanaryMetricStatus) string {
+	var messages []string
+	for _, m := range metrics {
+		if !m.Passed {
+			messages = append(messages, fmt.Sprintf("%s %s", m.Name, m.Message))
+		}
+	}
+	return strings.Join(messages, ", ")
+}
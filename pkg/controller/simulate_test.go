@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newSimulationCanary() *flaggerv1.Canary {
+	max := float64(500)
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Threshold:  2,
+				StepWeight: 20,
+				MaxWeight:  40,
+				Metrics: []flaggerv1.CanaryMetric{
+					{Name: "request-success-rate", Threshold: 99},
+					{Name: "request-duration", ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: &max}},
+				},
+			},
+		},
+	}
+}
+
+func simulationValues(v ...float64) []*float64 {
+	var list []*float64
+	for i := range v {
+		list = append(list, &v[i])
+	}
+	return list
+}
+
+func TestSimulate_Promoted(t *testing.T) {
+	result, err := Simulate(newSimulationCanary(), SimulationDataset{Metrics: map[string][]*float64{
+		"request-success-rate": simulationValues(99.9, 99.5, 99.8, 99.9),
+		"request-duration":     simulationValues(200, 300, 250, 450),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, result.Phase)
+	require.Len(t, result.Steps, 3)
+	assert.Equal(t, []int{0, 20, 40}, []int{result.Steps[0].CanaryWeight, result.Steps[1].CanaryWeight, result.Steps[2].CanaryWeight})
+}
+
+func TestSimulate_Failed(t *testing.T) {
+	result, err := Simulate(newSimulationCanary(), SimulationDataset{Metrics: map[string][]*float64{
+		"request-success-rate": simulationValues(99.9, 98, 99.9, 97),
+		"request-duration":     simulationValues(200, 300, 250, 450),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, result.Phase)
+	require.Len(t, result.Steps, 4)
+	last := result.Steps[3]
+	assert.Equal(t, 2, last.FailedChecks)
+	assert.Equal(t, 40, last.CanaryWeight)
+	assert.False(t, last.Metrics[0].Passed)
+	assert.Contains(t, result.Message, "request-success-rate")
+
+	// a query without values is a failed check
+	cd := newSimulationCanary()
+	cd.Spec.Analysis.Threshold = 1
+	result, err = Simulate(cd, SimulationDataset{Metrics: map[string][]*float64{
+		"request-success-rate": {nil},
+		"request-duration":     simulationValues(200),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, result.Phase)
+}
+
+func TestSimulate_Iterations(t *testing.T) {
+	cd := newSimulationCanary()
+	cd.Spec.Analysis.Iterations = 2
+	cd.Spec.Analysis.StepWeight = 0
+
+	result, err := Simulate(cd, SimulationDataset{Metrics: map[string][]*float64{
+		"request-success-rate": simulationValues(99.9, 99.9),
+		"request-duration":     simulationValues(200, 200),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, result.Phase)
+	assert.Len(t, result.Steps, 2)
+
+	result, err = Simulate(cd, SimulationDataset{Metrics: map[string][]*float64{
+		"request-success-rate": simulationValues(99.9, 99.9, 99.9),
+		"request-duration":     simulationValues(200, 200, 200),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, result.Phase)
+
+	_, err = Simulate(cd, SimulationDataset{Metrics: map[string][]*float64{
+		"request-success-rate": simulationValues(99.9),
+	}})
+	assert.Error(t, err)
+}
+
+func TestSimulate_SoakIterations(t *testing.T) {
+	cd := newSimulationCanary()
+	cd.Spec.Analysis.SoakIterations = 2
+	result, err := Simulate(cd, SimulationDataset{Metrics: map[string][]*float64{
+		"request-success-rate": simulationValues(99.9, 99.5, 99.8, 99.9, 99.7),
+		"request-duration":     simulationValues(200, 300, 250, 450, 300),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, result.Phase)
+	require.Len(t, result.Steps, 5)
+	assert.Equal(t, 40, result.Steps[3].CanaryWeight)
+	assert.Equal(t, 40, result.Steps[4].CanaryWeight)
+	assert.Contains(t, result.Message, "2 soak iterations")
+}
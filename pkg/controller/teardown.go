@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// previewTeardownAnnotation requests the scheduler to report the changes
+// that the finalizer would make if the canary was deleted
+const previewTeardownAnnotation = "flagger.app/preview-teardown"
+
+// teardownPlan returns the changes made by the finalizer for the revert policy of the canary
+func (c *Controller) teardownPlan(cd *flaggerv1.Canary) []string {
+	policy := cd.GetRevertPolicy()
+	var plan []string
+
+	if cd.Spec.SuspendFluxDuringAnalysis {
+		plan = append(plan, "Flux owner resumed")
+	}
+	if cd.Spec.Propagation != nil && policy != flaggerv1.RevertPolicyDelete {
+		plan = append(plan, "propagation policies reverted")
+	}
+
+	target := fmt.Sprintf("%s %s", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name)
+	switch {
+	case cd.GetTargetKind() == "Service":
+		plan = append(plan, fmt.Sprintf("%s unchanged", target))
+	case policy == flaggerv1.RevertPolicyDelete:
+		plan = append(plan, fmt.Sprintf("%s deleted", target))
+	case policy == flaggerv1.RevertPolicyRestorePrimary:
+		plan = append(plan, fmt.Sprintf("%s restored with the primary pod template", target))
+	default:
+		plan = append(plan, fmt.Sprintf("%s restored", target))
+	}
+
+	apexName, primaryName, canaryName := cd.GetServiceNames(c.primarySuffix)
+	switch policy {
+	case flaggerv1.RevertPolicyDelete:
+		plan = append(plan, "generated services deleted")
+	case flaggerv1.RevertPolicyKeepServices:
+		plan = append(plan, fmt.Sprintf("services %s, %s and %s kept", apexName, primaryName, canaryName))
+	default:
+		plan = append(plan, fmt.Sprintf("service %s routed to the target", apexName))
+	}
+
+	provider := c.meshProvider
+	if cd.Spec.Provider != "" {
+		provider = cd.Spec.Provider
+	}
+	plan = append(plan, fmt.Sprintf("%s routing reverted", provider))
+	if ns := cd.GetTargetNamespace(); ns != cd.Namespace {
+		plan = append(plan, fmt.Sprintf("objects generated in %s deleted", ns))
+	}
+	return plan
+}
+
+// previewTeardown records the teardown plan of the canary when the preview annotation is set,
+// the annotation is removed so that the preview is reported once
+func (c *Controller) previewTeardown(cd *flaggerv1.Canary) {
+	if _, ok := cd.GetAnnotations()[previewTeardownAnnotation]; !ok {
+		return
+	}
+	if _, err := c.takeAction(cd, previewTeardownAnnotation); err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return
+	}
+
+	if ns := cd.GetTargetNamespace(); !cd.Spec.RevertOnDeletion && ns != cd.Namespace {
+		c.recordEventInfof(cd, flaggerv1.ReasonTeardownPreview,
+			"Teardown preview of %s.%s: revertOnDeletion is disabled, the objects generated in %s are deleted and the target isn't restored",
+			cd.Name, cd.Namespace, ns)
+		return
+	}
+	if !cd.Spec.RevertOnDeletion {
+		c.recordEventInfof(cd, flaggerv1.ReasonTeardownPreview,
+			"Teardown preview of %s.%s: revertOnDeletion is disabled, the generated objects are garbage collected and the target isn't restored",
+			cd.Name, cd.Namespace)
+		return
+	}
+	c.recordEventInfof(cd, flaggerv1.ReasonTeardownPreview, "Teardown preview of %s.%s with the %s policy: %s",
+		cd.Name, cd.Namespace, cd.GetRevertPolicy(), strings.Join(c.teardownPlan(cd), ", "))
+}
+
+// SetFinalizerMaxAttempts sets the number of failed cleanup attempts after which
+// the finalizer is removed without reverting the target, zero retries forever
+func (c *Controller) SetFinalizerMaxAttempts(attempts int) {
+	c.finalizerMaxAttempts = attempts
+}
+
+// forceDetach counts the failed cleanup attempts of the canary and returns true
+// when the finalizer has to be removed without completing the cleanup
+func (c *Controller) forceDetach(cd *flaggerv1.Canary) bool {
+	if c.finalizerMaxAttempts <= 0 {
+		return false
+	}
+	key := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+	attempts := 1
+	if v, ok := c.finalizeAttempts.Load(key); ok {
+		attempts = v.(int) + 1
+	}
+	if attempts < c.finalizerMaxAttempts {
+		c.finalizeAttempts.Store(key, attempts)
+		return false
+	}
+	c.finalizeAttempts.Delete(key)
+	return true
+}
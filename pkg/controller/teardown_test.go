@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_teardownPlan(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.meshProvider = "istio"
+	cd := newDeploymentTestCanary()
+
+	assert.Equal(t, []string{
+		"Deployment podinfo restored",
+		"service podinfo routed to the target",
+		"istio routing reverted",
+	}, mocks.ctrl.teardownPlan(cd))
+
+	cd.Spec.RevertPolicy = flaggerv1.RevertPolicyKeepServices
+	cd.Spec.Provider = "linkerd"
+	assert.Equal(t, []string{
+		"Deployment podinfo restored",
+		"services podinfo, podinfo-primary and podinfo-canary kept",
+		"linkerd routing reverted",
+	}, mocks.ctrl.teardownPlan(cd))
+
+	cd.Spec.RevertPolicy = flaggerv1.RevertPolicyDelete
+	cd.Spec.SuspendFluxDuringAnalysis = true
+	assert.Equal(t, []string{
+		"Flux owner resumed",
+		"Deployment podinfo deleted",
+		"generated services deleted",
+		"linkerd routing reverted",
+	}, mocks.ctrl.teardownPlan(cd))
+
+	cd.Spec.TargetRef.Namespace = "apps"
+	assert.Contains(t, mocks.ctrl.teardownPlan(cd), "objects generated in apps deleted")
+}
+
+func TestScheduler_PreviewTeardown(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.RevertOnDeletion = true
+	cd.Annotations = map[string]string{previewTeardownAnnotation: "true"}
+	mocks := newDeploymentFixture(cd)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, previewTeardownAnnotation)
+}
+
+func TestController_forceDetach(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	cd := newDeploymentTestCanary()
+	assert.False(t, mocks.ctrl.forceDetach(cd))
+
+	mocks.ctrl.SetFinalizerMaxAttempts(3)
+	assert.False(t, mocks.ctrl.forceDetach(cd))
+	assert.False(t, mocks.ctrl.forceDetach(cd))
+	assert.True(t, mocks.ctrl.forceDetach(cd))
+
+	// the attempts are counted again after a detach
+	assert.False(t, mocks.ctrl.forceDetach(cd))
+}
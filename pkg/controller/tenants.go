@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// SetTenantSecretNamespaces sets the namespaces whose canaries can bring their own
+// provider credentials instead of the ones of the shared metric templates and alert providers,
+// a wildcard allows all namespaces
+func (c *Controller) SetTenantSecretNamespaces(namespaces []string) {
+	c.tenantSecretNamespaces = namespaces
+}
+
+// tenantSecretsAllowed returns true when the canaries of the namespace can bring their own credentials
+func (c *Controller) tenantSecretsAllowed(namespace string) bool {
+	return containsString(c.tenantSecretNamespaces, "*") || containsString(c.tenantSecretNamespaces, namespace)
+}
+
+// tenantSecret returns a secret of the canary namespace holding the tenant provider credentials
+func (c *Controller) tenantSecret(canary *flaggerv1.Canary, name string) (*corev1.Secret, error) {
+	if !c.tenantSecretsAllowed(canary.Namespace) {
+		return nil, fmt.Errorf("namespace %s is not allowed to override the provider credentials", canary.Namespace)
+	}
+	secret, err := c.kubeClient.CoreV1().Secrets(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("secret %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+	return secret, nil
+}
+
+// metricSecretRef returns the namespace and the reference of the secret holding the metric provider credentials,
+// the canary secret overrides the template secret when the canary namespace is allowed to bring its own
+func (c *Controller) metricSecretRef(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	template *flaggerv1.MetricTemplate) (string, *corev1.LocalObjectReference, error) {
+	if metric.SecretRef == nil {
+		return template.Namespace, template.Spec.Provider.SecretRef, nil
+	}
+	if !c.tenantSecretsAllowed(canary.Namespace) {
+		return "", nil, fmt.Errorf("namespace %s is not allowed to override the credentials of metric template %s.%s",
+			canary.Namespace, template.Name, template.Namespace)
+	}
+	return canary.Namespace, metric.SecretRef, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_MetricSecretRef(t *testing.T) {
+	ctrl := &Controller{}
+	canary := newDeploymentTestCanary()
+	template := newDeploymentTestMetricTemplate()
+	template.Namespace = "flagger"
+	metric := flaggerv1.CanaryMetric{Name: "envoy"}
+
+	ns, ref, err := ctrl.metricSecretRef(canary, metric, template)
+	require.NoError(t, err)
+	assert.Equal(t, "flagger", ns)
+	assert.Equal(t, "podinfo-secret-env", ref.Name)
+
+	// the canary namespace isn't allowed
+	metric.SecretRef = &corev1.LocalObjectReference{Name: "team-credentials"}
+	_, _, err = ctrl.metricSecretRef(canary, metric, template)
+	require.Error(t, err)
+
+	ctrl.SetTenantSecretNamespaces([]string{"default"})
+	ns, ref, err = ctrl.metricSecretRef(canary, metric, template)
+	require.NoError(t, err)
+	assert.Equal(t, "default", ns)
+	assert.Equal(t, "team-credentials", ref.Name)
+
+	ctrl.SetTenantSecretNamespaces([]string{"*"})
+	_, _, err = ctrl.metricSecretRef(canary, metric, template)
+	require.NoError(t, err)
+}
+
+func TestController_CanaryNotifier_TenantSecret(t *testing.T) {
+	posts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	_, err := mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "team-slack"},
+		Data:       map[string][]byte{"address": []byte(ts.URL)},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	alert := flaggerv1.CanaryAlert{
+		Name:        "team",
+		ProviderRef: flaggerv1.CrossNamespaceObjectReference{Name: "slack"},
+		SecretRef:   &corev1.LocalObjectReference{Name: "team-slack"},
+	}
+	_, err = mocks.ctrl.alertNotifier(mocks.canary, alert)
+	require.Error(t, err)
+
+	mocks.ctrl.SetTenantSecretNamespaces([]string{"default"})
+	n, err := mocks.ctrl.alertNotifier(mocks.canary, alert)
+	require.NoError(t, err)
+	require.NoError(t, n.Post("podinfo", "default", "test", nil, string(flaggerv1.SeverityInfo)))
+	assert.Equal(t, 1, posts)
+
+	alert.SecretRef.Name = "unknown"
+	_, err = mocks.ctrl.alertNotifier(mocks.canary, alert)
+	require.Error(t, err)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// maxTimelineEntries caps the entries kept for a canary analysis,
+// the oldest entries are discarded when the limit is reached
+const maxTimelineEntries = 1000
+
+// TimelineEntryType is the kind of change recorded in a canary timeline
+type TimelineEntryType string
+
+const (
+	// TimelinePhase is recorded when the canary phase changes
+	TimelinePhase TimelineEntryType = "Phase"
+	// TimelineWeight is recorded when the traffic weights change
+	TimelineWeight TimelineEntryType = "Weight"
+	// TimelineDecision is recorded for each event of the canary analysis
+	TimelineDecision TimelineEntryType = "Decision"
+)
+
+// Timeline is the sequence of changes of a canary analysis
+type Timeline struct {
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	RunID     string          `json:"runID,omitempty"`
+	StartTime time.Time       `json:"startTime"`
+	Entries   []TimelineEntry `json:"entries"`
+}
+
+// TimelineEntry is a change of the canary phase, of the traffic weights or an analysis decision,
+// every entry contains the phase and weights in effect after the change
+type TimelineEntry struct {
+	Time          time.Time             `json:"time"`
+	Type          TimelineEntryType     `json:"type"`
+	Phase         flaggerv1.CanaryPhase `json:"phase"`
+	PrimaryWeight int                   `json:"primaryWeight"`
+	CanaryWeight  int                   `json:"canaryWeight"`
+	Reason        flaggerv1.EventReason `json:"reason,omitempty"`
+	Message       string                `json:"message,omitempty"`
+}
+
+// canaryTimeline holds the timeline of the current analysis
+// and the phase and weights in effect
+type canaryTimeline struct {
+	mu            sync.Mutex
+	timeline      Timeline
+	phase         flaggerv1.CanaryPhase
+	primaryWeight int
+	canaryWeight  int
+}
+
+// timeline returns the timeline of a canary, when restart is set a new timeline
+// is started with the phase and weights of the previous one,
+// the first timeline of a canary starts with the weights from its status
+func (c *Controller) timeline(cd *flaggerv1.Canary, restart bool) *canaryTimeline {
+	key := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+	v, ok := c.timelines.Load(key)
+	if ok && !restart {
+		return v.(*canaryTimeline)
+	}
+
+	t := &canaryTimeline{
+		timeline: Timeline{
+			Name:      cd.Name,
+			Namespace: cd.Namespace,
+			RunID:     cd.Status.RunID,
+			StartTime: time.Now(),
+			Entries:   []TimelineEntry{},
+		},
+	}
+	if ok {
+		prev := v.(*canaryTimeline)
+		prev.mu.Lock()
+		t.phase, t.primaryWeight, t.canaryWeight = prev.phase, prev.primaryWeight, prev.canaryWeight
+		prev.mu.Unlock()
+	} else {
+		// the routing hasn't been changed since the controller started
+		t.canaryWeight = cd.Status.CanaryWeight
+		t.primaryWeight = c.totalWeight(cd) - cd.Status.CanaryWeight
+	}
+	c.timelines.Store(key, t)
+	return t
+}
+
+// append updates the phase and weights in effect and adds an entry for the change,
+// phase and weight entries are only added when the value has changed
+func (t *canaryTimeline) append(entry TimelineEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch entry.Type {
+	case TimelinePhase:
+		if entry.Phase == "" || entry.Phase == t.phase {
+			return
+		}
+		t.phase = entry.Phase
+	case TimelineWeight:
+		if entry.PrimaryWeight == t.primaryWeight && entry.CanaryWeight == t.canaryWeight {
+			return
+		}
+		t.primaryWeight, t.canaryWeight = entry.PrimaryWeight, entry.CanaryWeight
+	}
+
+	entry.Time = time.Now()
+	entry.Phase = t.phase
+	entry.PrimaryWeight = t.primaryWeight
+	entry.CanaryWeight = t.canaryWeight
+
+	t.timeline.Entries = append(t.timeline.Entries, entry)
+	if len(t.timeline.Entries) > maxTimelineEntries {
+		t.timeline.Entries = t.timeline.Entries[len(t.timeline.Entries)-maxTimelineEntries:]
+	}
+}
+
+// recordTimelinePhase adds a phase entry when the canary phase has changed
+func (c *Controller) recordTimelinePhase(cd *flaggerv1.Canary) {
+	c.timeline(cd, false).append(TimelineEntry{Type: TimelinePhase, Phase: cd.Status.Phase})
+}
+
+// recordTimelineWeights adds a weight entry when the traffic routing has changed
+func (c *Controller) recordTimelineWeights(cd *flaggerv1.Canary, primaryWeight int, canaryWeight int) {
+	c.timeline(cd, false).append(TimelineEntry{Type: TimelineWeight, PrimaryWeight: primaryWeight, CanaryWeight: canaryWeight})
+}
+
+// recordTimelineDecision adds the canary event to the timeline,
+// a new timeline is started when the analysis of a new revision begins
+func (c *Controller) recordTimelineDecision(cd *flaggerv1.Canary, reason flaggerv1.EventReason, message string) {
+	t := c.timeline(cd, reason == flaggerv1.ReasonNewRevisionDetected)
+	t.append(TimelineEntry{Type: TimelinePhase, Phase: cd.Status.Phase})
+	t.append(TimelineEntry{Type: TimelineDecision, Reason: reason, Message: message})
+}
+
+// CanaryTimeline returns a copy of the timeline of a canary
+func (c *Controller) CanaryTimeline(name string, namespace string) (Timeline, bool) {
+	v, ok := c.timelines.Load(fmt.Sprintf("%s.%s", name, namespace))
+	if !ok {
+		return Timeline{}, false
+	}
+
+	t := v.(*canaryTimeline)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timeline := t.timeline
+	timeline.Entries = append([]TimelineEntry{}, t.timeline.Entries...)
+	return timeline, true
+}
+
+// TimelineHandler serves the timeline of a canary as JSON,
+// the canary is selected with the namespace and name query parameters
+func (c *Controller) TimelineHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name, namespace := r.URL.Query().Get("name"), r.URL.Query().Get("namespace")
+		if name == "" || namespace == "" {
+			http.Error(w, "name and namespace are required", http.StatusBadRequest)
+			return
+		}
+
+		timeline, ok := c.CanaryTimeline(name, namespace)
+		if !ok {
+			http.Error(w, fmt.Sprintf("timeline of canary %s.%s not found", name, namespace), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(timeline); err != nil {
+			c.logger.Errorf("encoding canary timeline failed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_Timeline(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	timeline, ok := mocks.ctrl.CanaryTimeline("podinfo", "default")
+	require.True(t, ok)
+	assert.Equal(t, cd.Status.RunID, timeline.RunID)
+	require.NotEmpty(t, timeline.Entries)
+
+	// the timeline starts when the new revision is detected
+	assert.Equal(t, TimelinePhase, timeline.Entries[0].Type)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, timeline.Entries[0].Phase)
+	assert.Equal(t, 100, timeline.Entries[0].PrimaryWeight)
+
+	var decisions []flaggerv1.EventReason
+	var weights []int
+	for _, entry := range timeline.Entries {
+		switch entry.Type {
+		case TimelineDecision:
+			decisions = append(decisions, entry.Reason)
+		case TimelineWeight:
+			weights = append(weights, entry.CanaryWeight)
+		}
+	}
+	assert.Equal(t, flaggerv1.ReasonNewRevisionDetected, decisions[0])
+	assert.Contains(t, decisions, flaggerv1.ReasonAdvanced)
+	assert.Equal(t, []int{10}, weights)
+
+	last := timeline.Entries[len(timeline.Entries)-1]
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, last.Phase)
+	assert.Equal(t, 90, last.PrimaryWeight)
+	assert.Equal(t, 10, last.CanaryWeight)
+}
+
+func TestController_TimelineHandler(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.recordTimelineWeights(mocks.canary, 90, 10)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/timeline?namespace=default&name=podinfo", nil)
+	rec := httptest.NewRecorder()
+	mocks.ctrl.TimelineHandler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var timeline Timeline
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &timeline))
+	assert.Equal(t, "podinfo", timeline.Name)
+	require.Len(t, timeline.Entries, 1)
+	assert.Equal(t, 90, timeline.Entries[0].PrimaryWeight)
+	assert.Equal(t, 10, timeline.Entries[0].CanaryWeight)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/timeline?namespace=default&name=unknown", nil)
+	rec = httptest.NewRecorder()
+	mocks.ctrl.TimelineHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/timeline", nil)
+	rec = httptest.NewRecorder()
+	mocks.ctrl.TimelineHandler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+)
+
+// canaryWave returns the wave that contains the canary and the position of the canary in the wave,
+// the wave is nil when the canary is not part of a wave
+func (c *Controller) canaryWave(name string, namespace string) (*flaggerv1.CanaryWave, int, error) {
+	waves, err := c.flaggerInformers.WaveInformer.Lister().CanaryWaves(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, 0, fmt.Errorf("canary waves list query failed: %w", err)
+	}
+	for _, wave := range waves {
+		for i, ref := range wave.Spec.Canaries {
+			if ref.Name == name {
+				return wave, i, nil
+			}
+		}
+	}
+	return nil, 0, nil
+}
+
+// analysisStarted returns true once the canary received traffic or ran an iteration
+func analysisStarted(cd *flaggerv1.Canary) bool {
+	return cd.Status.CanaryWeight > 0 || cd.Status.Iterations > 0
+}
+
+// waveGated returns true when the next step of the canary is the start of a new analysis
+func waveGated(cd *flaggerv1.Canary) bool {
+	switch cd.Status.Phase {
+	case flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseSucceeded, flaggerv1.CanaryPhaseFailed:
+		return true
+	case flaggerv1.CanaryPhaseWaiting:
+		return !analysisStarted(cd)
+	}
+	return false
+}
+
+// waveAborted returns true when the wave of the canary is aborted while the canary is under analysis
+func (c *Controller) waveAborted(cd *flaggerv1.Canary) bool {
+	running := cd.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
+		cd.Status.Phase == flaggerv1.CanaryWaitingPromotion ||
+		(cd.Status.Phase == flaggerv1.CanaryPhaseWaiting && analysisStarted(cd))
+	if !running {
+		return false
+	}
+
+	wave, _, err := c.canaryWave(cd.Name, cd.Namespace)
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Errorf("%v", err)
+		return false
+	}
+	return wave != nil && wave.Spec.Abort
+}
+
+// runWaveGate holds the start of the analysis until all the previous canaries of the wave
+// have promoted their revision, an aborted wave holds all its canaries
+func (c *Controller) runWaveGate(cd *flaggerv1.Canary, canaryController canary.Controller) bool {
+	if !waveGated(cd) {
+		return true
+	}
+
+	wave, index, err := c.canaryWave(cd.Name, cd.Namespace)
+	if err != nil {
+		c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+		return false
+	}
+	if wave == nil {
+		return true
+	}
+
+	reason := ""
+	if wave.Spec.Abort {
+		reason = fmt.Sprintf("wave %s.%s is aborted", wave.Name, wave.Namespace)
+	} else {
+		pending, err := c.pendingWaveCanary(wave, index)
+		if err != nil {
+			c.recordEventWarningf(cd, flaggerv1.ReasonSyncFailed, "%v", err)
+			return false
+		}
+		if pending == "" {
+			return true
+		}
+		reason = fmt.Sprintf("waiting for %s.%s to be promoted", pending, wave.Namespace)
+	}
+
+	if cd.Status.Phase != flaggerv1.CanaryPhaseWaiting {
+		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseWaiting); err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Errorf("%v", err)
+		}
+		c.recordEventInfof(cd, flaggerv1.ReasonWaitingForWave, "Halt %s.%s advancement %s", cd.Name, cd.Namespace, reason)
+		c.alert(cd, flaggerv1.ReasonWaitingForWave, fmt.Sprintf("Canary is %s.", reason), false, flaggerv1.SeverityInfo)
+	}
+	return false
+}
+
+// pendingWaveCanary returns the name of the first canary before the index that is under analysis,
+// failed or has a revision that was not analysed yet
+func (c *Controller) pendingWaveCanary(wave *flaggerv1.CanaryWave, index int) (string, error) {
+	for _, ref := range wave.Spec.Canaries[:index] {
+		cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(wave.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("canary %s.%s of wave %s get query failed: %w", ref.Name, wave.Namespace, wave.Name, err)
+		}
+		if cd.Status.Phase != flaggerv1.CanaryPhaseInitialized && cd.Status.Phase != flaggerv1.CanaryPhaseSucceeded {
+			return cd.Name, nil
+		}
+
+		canaryController, err := c.targetController(cd)
+		if err != nil {
+			return "", err
+		}
+		changed, err := c.shouldAdvance(cd, canaryController)
+		if err != nil {
+			return "", fmt.Errorf("canary %s.%s of wave %s: %w", ref.Name, wave.Namespace, wave.Name, err)
+		}
+		if changed {
+			return cd.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// syncWaveStatus aggregates the statuses of the canaries in the wave of the canary
+func (c *Controller) syncWaveStatus(name string, namespace string) {
+	wave, _, err := c.canaryWave(name, namespace)
+	if err != nil || wave == nil {
+		return
+	}
+
+	status := flaggerv1.CanaryWaveStatus{Phase: flaggerv1.CanaryPhaseInitialized}
+	succeeded := 0
+	for _, ref := range wave.Spec.Canaries {
+		member := flaggerv1.CanaryWaveMemberStatus{Name: ref.Name}
+		cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err == nil {
+			member.Phase = cd.Status.Phase
+			member.CanaryWeight = cd.Status.CanaryWeight
+			if cd.Spec.TargetCluster != nil {
+				member.Cluster = cd.Spec.TargetCluster.Name
+			}
+		}
+		status.Canaries = append(status.Canaries, member)
+
+		switch {
+		case analysisRunning(member.Phase):
+			if status.Current == "" {
+				status.Current = member.Name
+			}
+			status.Phase = flaggerv1.CanaryPhaseProgressing
+		case member.Phase == flaggerv1.CanaryPhaseFailed && status.Phase != flaggerv1.CanaryPhaseProgressing:
+			status.Phase = flaggerv1.CanaryPhaseFailed
+		case member.Phase == flaggerv1.CanaryPhaseSucceeded:
+			succeeded++
+		}
+	}
+	if succeeded == len(wave.Spec.Canaries) {
+		status.Phase = flaggerv1.CanaryPhaseSucceeded
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest, err := c.flaggerClient.FlaggerV1beta1().CanaryWaves(namespace).Get(context.TODO(), wave.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("canary wave %s.%s get query failed: %w", wave.Name, namespace, err)
+		}
+
+		status.LastTransitionTime = latest.Status.LastTransitionTime
+		if latest.Status.Phase != status.Phase {
+			status.LastTransitionTime = metav1.Now()
+		}
+		if reflect.DeepEqual(latest.Status, status) {
+			return nil
+		}
+
+		waveCopy := latest.DeepCopy()
+		waveCopy.Status = status
+		_, err = c.flaggerClient.FlaggerV1beta1().CanaryWaves(namespace).UpdateStatus(context.TODO(), waveCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
+			Errorf("Updating the wave status failed: %v", err)
+	}
+}
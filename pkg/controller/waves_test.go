@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newTestCanaryWave(canaries ...string) *flaggerv1.CanaryWave {
+	wave := &flaggerv1.CanaryWave{
+		TypeMeta:   metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+	}
+	for _, name := range canaries {
+		wave.Spec.Canaries = append(wave.Spec.Canaries, corev1.LocalObjectReference{Name: name})
+	}
+	return wave
+}
+
+func TestScheduler_CanaryWave(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	first := newDeploymentTestCanary()
+	first.Name = "podinfo-first"
+	_, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), first, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	wave := newTestCanaryWave("podinfo-first", "podinfo")
+	_, err = mocks.flaggerClient.FlaggerV1beta1().CanaryWaves("default").Create(context.TODO(), wave, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.WaveInformer.Informer().GetIndexer().Add(wave))
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// the first canary of the wave is under analysis
+	first, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo-first", metav1.GetOptions{})
+	require.NoError(t, err)
+	first.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	first, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), first, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseWaiting, c.Status.Phase)
+
+	w, err := mocks.flaggerClient.FlaggerV1beta1().CanaryWaves("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, w.Status.Phase)
+	assert.Equal(t, "podinfo-first", w.Status.Current)
+	require.Len(t, w.Status.Canaries, 2)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, w.Status.Canaries[0].Phase)
+	assert.Equal(t, flaggerv1.CanaryPhaseWaiting, w.Status.Canaries[1].Phase)
+
+	// the analysis starts once the first canary has promoted the revision
+	err = mocks.ctrl.canaryFactory.Controller("Deployment").SyncStatus(first, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseSucceeded})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+
+	w, err = mocks.flaggerClient.FlaggerV1beta1().CanaryWaves("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", w.Status.Current)
+}
+
+func TestScheduler_CanaryWaveAbort(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	wave := newTestCanaryWave("podinfo")
+	_, err := mocks.flaggerClient.FlaggerV1beta1().CanaryWaves("default").Create(context.TODO(), wave, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.WaveInformer.Informer().GetIndexer().Add(wave))
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+
+	// abort the wave
+	wave.Spec.Abort = true
+	require.NoError(t, mocks.ctrl.flaggerInformers.WaveInformer.Informer().GetIndexer().Update(wave))
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+
+	w, err := mocks.flaggerClient.FlaggerV1beta1().CanaryWaves("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, w.Status.Phase)
+}
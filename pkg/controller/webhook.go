@@ -19,35 +19,205 @@ package controller
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/mtls"
 )
 
-func callWebhook(webhook string, payload interface{}, timeout string) error {
+// webhookTLS is the client certificate presented to the webhooks
+var webhookTLS mtls.Config
+
+// webhookClients holds the HTTP clients by accepted SANs
+var webhookClients sync.Map
+
+// SetWebhookTLS sets the client certificate presented to the webhook servers
+// that request one, it's used for all the webhook calls made by the controller
+func SetWebhookTLS(config mtls.Config) {
+	webhookTLS = config
+	webhookClients.Range(func(key, _ interface{}) bool {
+		webhookClients.Delete(key)
+		return true
+	})
+}
+
+// webhookClient returns the HTTP client that verifies the webhook server identity
+func webhookClient(tlsOpts *flaggerv1.CanaryWebhookTLS) *http.Client {
+	var sans []string
+	if tlsOpts != nil {
+		sans = tlsOpts.SubjectAltNames
+	}
+	if !webhookTLS.Enabled() && tlsOpts == nil {
+		return http.DefaultClient
+	}
+
+	key := strings.Join(sans, ",")
+	if client, ok := webhookClients.Load(key); ok {
+		return client.(*http.Client)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = webhookTLS.ClientConfig(sans)
+	client, _ := webhookClients.LoadOrStore(key, &http.Client{Transport: transport})
+	return client.(*http.Client)
+}
+
+// SignatureHeader carries the HMAC-SHA256 of the request body as sha256=<hex>,
+// it's set for the webhooks with a signing secret
+const SignatureHeader = "X-Flagger-Signature"
+
+// signingKeyName is the key of the HMAC key in the webhook signing secret
+const signingKeyName = "signingKey"
+
+// webhookSecrets holds the webhook settings loaded from secrets at call time
+type webhookSecrets struct {
+	signingKey []byte
+	metadata   map[string]string
+}
+
+// loadWebhookSecrets reads the secrets referenced by the webhook from the canary namespace
+func loadWebhookSecrets(kubeClient kubernetes.Interface, namespace string, w flaggerv1.CanaryWebhook) (*webhookSecrets, error) {
+	secrets := &webhookSecrets{}
+	if w.SigningSecretRef != nil {
+		secret, err := kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), w.SigningSecretRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("signingSecretRef %s error: %w", w.SigningSecretRef.Name, err)
+		}
+		key, ok := secret.Data[signingKeyName]
+		if !ok || len(key) == 0 {
+			return nil, fmt.Errorf("secret %s does not contain a %s", w.SigningSecretRef.Name, signingKeyName)
+		}
+		secrets.signingKey = key
+	}
+	if len(w.MetadataFrom) > 0 {
+		secrets.metadata = make(map[string]string, len(w.MetadataFrom))
+		for _, m := range w.MetadataFrom {
+			value, ok, err := readSecretKey(kubeClient, namespace, m.SecretKeyRef)
+			if err != nil {
+				return nil, fmt.Errorf("metadataFrom %s error: %w", m.Name, err)
+			}
+			if ok {
+				secrets.metadata[m.Name] = value
+			}
+		}
+	}
+	return secrets, nil
+}
+
+// readSecretKey returns the value of a secret key, a missing secret or key
+// is an error unless the selector is optional
+func readSecretKey(kubeClient kubernetes.Interface, namespace string, ref corev1.SecretKeySelector) (string, bool, error) {
+	optional := ref.Optional != nil && *ref.Optional
+	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		if optional && kerrors.IsNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("secret %s.%s get query error: %w", ref.Name, namespace, err)
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		if optional {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("secret %s.%s does not contain the key %s", ref.Name, namespace, ref.Key)
+	}
+	return string(value), true, nil
+}
+
+func signPayload(key []byte, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookResponse holds the reply of a webhook
+type webhookResponse struct {
+	header http.Header
+	body   []byte
+}
+
+// approver returns the identity of who opened a confirm webhook gate,
+// read from the approver header or the approver field of a JSON body
+func (r *webhookResponse) approver() string {
+	if r == nil {
+		return ""
+	}
+	if approver := r.header.Get(flaggerv1.ApproverHeader); approver != "" {
+		return approver
+	}
+	var body struct {
+		Approver string `json:"approver"`
+	}
+	if err := json.Unmarshal(r.body, &body); err == nil {
+		return body.Approver
+	}
+	return ""
+}
+
+// artifactURL returns the address of the task output uploaded by the webhook
+func (r *webhookResponse) artifactURL() string {
+	if r == nil {
+		return ""
+	}
+	return r.header.Get(flaggerv1.ArtifactURLHeader)
+}
+
+// approvalSource returns the source of the approver identity, defaults to webhook
+func (r *webhookResponse) approvalSource() flaggerv1.ApprovalSource {
+	switch source := flaggerv1.ApprovalSource(r.header.Get(flaggerv1.ApprovalSourceHeader)); source {
+	case flaggerv1.ApprovalSourceAnnotation, flaggerv1.ApprovalSourceAPI:
+		return source
+	}
+	return flaggerv1.ApprovalSourceWebhook
+}
+
+func callWebhook(ctx context.Context, webhook string, payload interface{}, timeout string, tlsOpts *flaggerv1.CanaryWebhookTLS, signingKey []byte) (*webhookResponse, error) {
 	payloadBin, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	hook, err := url.Parse(webhook)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if tlsOpts != nil && hook.Scheme != "https" {
+		return nil, fmt.Errorf("webhook %s must use https when tls is set", webhook)
 	}
 
 	req, err := http.NewRequest("POST", hook.String(), bytes.NewBuffer(payloadBin))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if signingKey != nil {
+		req.Header.Set(SignatureHeader, signPayload(signingKey, payloadBin))
+	}
+
+	// propagate the trace context so that the receiver can join the analysis trace
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	if timeout == "" {
 		timeout = "10s"
@@ -55,60 +225,135 @@ func callWebhook(webhook string, payload interface{}, timeout string) error {
 
 	t, err := time.ParseDuration(timeout)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), t)
+	ctx, cancel := context.WithTimeout(ctx, t)
 	defer cancel()
 
-	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	r, err := webhookClient(tlsOpts).Do(req.WithContext(ctx))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer r.Body.Close()
 
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		return fmt.Errorf("error reading body: %s", err.Error())
+		return nil, fmt.Errorf("error reading body: %s", err.Error())
 	}
 
+	resp := &webhookResponse{header: r.Header, body: b}
 	if r.StatusCode > 202 {
-		return errors.New(string(b))
+		// keep the response of the failed calls to link the task output
+		if artifact := resp.artifactURL(); artifact != "" {
+			return resp, fmt.Errorf("%s, artifact %s", string(b), artifact)
+		}
+		return resp, errors.New(string(b))
 	}
 
-	return nil
+	return resp, nil
 }
 
 // CallWebhook does a HTTP POST to an external service and
 // returns an error if the response status code is non-2xx
 func CallWebhook(name string, namespace string, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) error {
+	return CallWebhookWithContext(context.Background(), name, namespace, phase, w)
+}
+
+// CallWebhookWithContext does a HTTP POST to an external service within the given context,
+// the trace context is propagated to the service with the W3C trace headers
+func CallWebhookWithContext(ctx context.Context, name string, namespace string, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) error {
 	payload := flaggerv1.CanaryWebhookPayload{
 		Name:      name,
 		Namespace: namespace,
 		Phase:     phase,
 	}
+	_, err := callWebhookWithPayload(ctx, payload, w, nil)
+	return err
+}
+
+// callCanaryWebhook calls the webhook with the analysis run ID of the canary
+func callCanaryWebhook(ctx context.Context, cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook, secrets *webhookSecrets) (*webhookResponse, error) {
+	payload := flaggerv1.CanaryWebhookPayload{
+		Name:      cd.Name,
+		Namespace: cd.Namespace,
+		Phase:     phase,
+		RunID:     cd.Status.RunID,
+	}
+	return callWebhookWithPayload(ctx, payload, w, secrets)
+}
 
+func callWebhookWithPayload(ctx context.Context, payload flaggerv1.CanaryWebhookPayload, w flaggerv1.CanaryWebhook, secrets *webhookSecrets) (*webhookResponse, error) {
 	if w.Metadata != nil {
-		payload.Metadata = *w.Metadata
+		payload.Metadata = make(map[string]string, len(*w.Metadata))
+		for key, value := range *w.Metadata {
+			payload.Metadata[key] = value
+		}
+	}
+
+	metadata, err := secrets.metadataFor(w)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range metadata {
+		if payload.Metadata == nil {
+			payload.Metadata = make(map[string]string, len(metadata))
+		}
+		payload.Metadata[key] = value
 	}
 
 	if len(w.Timeout) < 2 {
 		w.Timeout = "10s"
 	}
 
-	return callWebhook(w.URL, payload, w.Timeout)
+	signingKey, err := secrets.signingKeyFor(w)
+	if err != nil {
+		return nil, err
+	}
+	return callWebhook(ctx, w.URL, payload, w.Timeout, w.TLS, signingKey)
+}
+
+// signingKeyFor returns the signing key of the webhook,
+// a webhook with a signing secret is never called without a signature
+func (s *webhookSecrets) signingKeyFor(w flaggerv1.CanaryWebhook) ([]byte, error) {
+	if w.SigningSecretRef == nil {
+		return nil, nil
+	}
+	if s == nil || s.signingKey == nil {
+		return nil, fmt.Errorf("webhook %s signing key not loaded", w.Name)
+	}
+	return s.signingKey, nil
+}
+
+// metadataFor returns the webhook metadata values read from secrets,
+// a webhook with metadataFrom is never called without them
+func (s *webhookSecrets) metadataFor(w flaggerv1.CanaryWebhook) (map[string]string, error) {
+	if len(w.MetadataFrom) == 0 {
+		return nil, nil
+	}
+	if s == nil || s.metadata == nil {
+		return nil, fmt.Errorf("webhook %s metadata secrets not loaded", w.Name)
+	}
+	return s.metadata, nil
+}
+
+// CallEventWebhook posts the event to the webhook, the webhook secrets aren't loaded
+func CallEventWebhook(r *flaggerv1.Canary, w flaggerv1.CanaryWebhook, message, eventtype string, reason flaggerv1.EventReason) error {
+	return callEventWebhook(r, w, nil, message, eventtype, reason)
 }
 
-func CallEventWebhook(r *flaggerv1.Canary, w flaggerv1.CanaryWebhook, message, eventtype string) error {
+func callEventWebhook(r *flaggerv1.Canary, w flaggerv1.CanaryWebhook, secrets *webhookSecrets, message, eventtype string, reason flaggerv1.EventReason) error {
 	t := time.Now()
 
 	payload := flaggerv1.CanaryWebhookPayload{
 		Name:      r.Name,
 		Namespace: r.Namespace,
 		Phase:     r.Status.Phase,
+		RunID:     r.Status.RunID,
 		Metadata: map[string]string{
 			"eventMessage": message,
 			"eventType":    eventtype,
+			"eventReason":  string(reason),
 			"timestamp":    strconv.FormatInt(t.UnixNano()/1000000, 10),
 		},
 	}
@@ -121,5 +366,29 @@ func CallEventWebhook(r *flaggerv1.Canary, w flaggerv1.CanaryWebhook, message, e
 			payload.Metadata[key] = value
 		}
 	}
-	return callWebhook(w.URL, payload, "5s")
+	metadata, err := secrets.metadataFor(w)
+	if err != nil {
+		return err
+	}
+	for key, value := range metadata {
+		if _, ok := payload.Metadata[key]; ok {
+			continue
+		}
+		payload.Metadata[key] = value
+	}
+	signingKey, err := secrets.signingKeyFor(w)
+	if err != nil {
+		return err
+	}
+	_, err = callWebhook(context.Background(), w.URL, payload, "5s", w.TLS, signingKey)
+	return err
+}
+
+// webhookFailureReason returns the event reason of a failed webhook call
+func webhookFailureReason(err error) flaggerv1.EventReason {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return flaggerv1.ReasonWebhookTimeout
+	}
+	return flaggerv1.ReasonWebhookFailed
 }
@@ -17,14 +17,23 @@ limitations under the License.
 package controller
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -61,6 +70,168 @@ func TestCallWebhook_StatusCode(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestCallWebhook_TraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	var traceparent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+	hook := flaggerv1.CanaryWebhook{
+		Name: "validation",
+		URL:  ts.URL,
+	}
+
+	err := CallWebhookWithContext(ctx, "podinfo", v1.NamespaceDefault, flaggerv1.CanaryPhaseProgressing, hook)
+	require.NoError(t, err)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", traceparent)
+}
+
+func TestCallWebhook_Timeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+	hook := flaggerv1.CanaryWebhook{
+		Name:    "validation",
+		URL:     ts.URL,
+		Timeout: "10ms",
+	}
+
+	err := CallWebhook("podinfo", v1.NamespaceDefault, flaggerv1.CanaryPhaseProgressing, hook)
+	require.Error(t, err)
+	assert.Equal(t, flaggerv1.ReasonWebhookTimeout, webhookFailureReason(err))
+	assert.Equal(t, flaggerv1.ReasonWebhookFailed, webhookFailureReason(fmt.Errorf("internal error")))
+}
+
+func TestCallWebhook_TLS(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+	hook := flaggerv1.CanaryWebhook{
+		Name: "validation",
+		URL:  ts.URL,
+		TLS: &flaggerv1.CanaryWebhookTLS{
+			SubjectAltNames: []string{"spiffe://cluster.local/ns/test/sa/flagger-loadtester"},
+		},
+	}
+
+	// the server identity can't be verified over plain HTTP
+	err := CallWebhook("podinfo", v1.NamespaceDefault, flaggerv1.CanaryPhaseProgressing, hook)
+	require.Error(t, err)
+
+	// the test server certificate has no SPIFFE ID
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer tlsServer.Close()
+	hook.URL = tlsServer.URL
+	err = CallWebhook("podinfo", v1.NamespaceDefault, flaggerv1.CanaryPhaseProgressing, hook)
+	require.Error(t, err)
+}
+
+func TestCallWebhook_Signature(t *testing.T) {
+	var signature string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(SignatureHeader)
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	secret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "webhook-signing", Namespace: "default"},
+		Data:       map[string][]byte{"signingKey": []byte("secret")},
+	}
+	_, err := mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), secret, v1.CreateOptions{})
+	require.NoError(t, err)
+
+	hook := flaggerv1.CanaryWebhook{
+		Name:             "signed",
+		URL:              ts.URL,
+		SigningSecretRef: &corev1.LocalObjectReference{Name: "webhook-signing"},
+	}
+	err = mocks.ctrl.callWebhook(mocks.canary, flaggerv1.CanaryPhaseProgressing, hook)
+	require.NoError(t, err)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+
+	// the webhook isn't called without a signature
+	err = CallWebhook("podinfo", v1.NamespaceDefault, flaggerv1.CanaryPhaseProgressing, hook)
+	require.Error(t, err)
+
+	hook.SigningSecretRef.Name = "unknown"
+	err = mocks.ctrl.callWebhook(mocks.canary, flaggerv1.CanaryPhaseProgressing, hook)
+	require.Error(t, err)
+}
+
+func TestCallWebhook_MetadataFrom(t *testing.T) {
+	var payload flaggerv1.CanaryWebhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	secret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "webhook-keys", Namespace: "default"},
+		Data:       map[string][]byte{"apiKey": []byte("s3cr3t")},
+	}
+	_, err := mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), secret, v1.CreateOptions{})
+	require.NoError(t, err)
+
+	optional := true
+	hook := flaggerv1.CanaryWebhook{
+		Name:     "keys",
+		URL:      ts.URL,
+		Metadata: &map[string]string{"cmd": "test", "apiKey": "placeholder"},
+		MetadataFrom: []flaggerv1.CanaryWebhookMetadataSource{
+			{
+				Name: "apiKey",
+				SecretKeyRef: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "webhook-keys"},
+					Key:                  "apiKey",
+				},
+			},
+			{
+				Name: "token",
+				SecretKeyRef: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "webhook-keys"},
+					Key:                  "token",
+					Optional:             &optional,
+				},
+			},
+		},
+	}
+	err = mocks.ctrl.callWebhook(mocks.canary, flaggerv1.CanaryPhaseProgressing, hook)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cmd": "test", "apiKey": "s3cr3t"}, payload.Metadata)
+	assert.Equal(t, "placeholder", (*hook.Metadata)["apiKey"])
+
+	// the webhook isn't called without the secret values
+	err = CallWebhook("podinfo", v1.NamespaceDefault, flaggerv1.CanaryPhaseProgressing, hook)
+	require.Error(t, err)
+
+	hook.MetadataFrom[0].SecretKeyRef.Key = "unknown"
+	err = mocks.ctrl.callWebhook(mocks.canary, flaggerv1.CanaryPhaseProgressing, hook)
+	require.Error(t, err)
+}
+
 func TestCallEventWebhook(t *testing.T) {
 	canaryName := "podinfo"
 	canaryNamespace := v1.NamespaceDefault
@@ -88,6 +259,16 @@ func TestCallEventWebhook(t *testing.T) {
 			return
 		}
 
+		if payload.Metadata["eventReason"] != string(flaggerv1.ReasonAnalysisStarted) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if payload.RunID != "run-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
 		if payload.Name != canaryName {
 			w.WriteHeader(http.StatusBadRequest)
 			return
@@ -112,10 +293,11 @@ func TestCallEventWebhook(t *testing.T) {
 		},
 		Status: flaggerv1.CanaryStatus{
 			Phase: flaggerv1.CanaryPhaseProgressing,
+			RunID: "run-1",
 		},
 	}
 
-	err := CallEventWebhook(canary, hook, canaryMessage, canaryEventType)
+	err := CallEventWebhook(canary, hook, canaryMessage, canaryEventType, flaggerv1.ReasonAnalysisStarted)
 	require.NoError(t, err)
 }
 
@@ -143,6 +325,6 @@ func TestCallEventWebhookStatusCode(t *testing.T) {
 		},
 	}
 
-	err := CallEventWebhook(canary, hook, canaryMessage, canaryEventType)
+	err := CallEventWebhook(canary, hook, canaryMessage, canaryEventType, flaggerv1.ReasonAnalysisStarted)
 	assert.Error(t, err)
 }
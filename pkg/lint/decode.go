@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// Manifests holds the Flagger objects decoded from the manifest files
+type Manifests struct {
+	Canaries        []*flaggerv1.Canary
+	MetricTemplates []*flaggerv1.MetricTemplate
+	AlertProviders  []*flaggerv1.AlertProvider
+	CanaryWaves     []*flaggerv1.CanaryWave
+	CanaryClasses   []*flaggerv1.CanaryClass
+
+	// sources maps the objects to the files they were decoded from
+	sources map[metav1.Object]string
+}
+
+// Decode reads the YAML or JSON documents from r and adds the Flagger objects to the manifests,
+// the documents of other API groups are skipped and the fields unknown to the schema are reported as issues
+func (m *Manifests) Decode(source string, r io.Reader) ([]Issue, error) {
+	var issues []Issue
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return issues, fmt.Errorf("reading %s failed: %w", source, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+			return issues, fmt.Errorf("decoding %s failed: %w", source, err)
+		}
+		if !strings.HasPrefix(typeMeta.APIVersion, flaggerv1.SchemeGroupVersion.Group+"/") {
+			continue
+		}
+
+		var obj metav1.Object
+		switch typeMeta.Kind {
+		case flaggerv1.CanaryKind:
+			cd := &flaggerv1.Canary{}
+			m.Canaries = append(m.Canaries, cd)
+			obj = cd
+		case flaggerv1.MetricTemplateKind:
+			template := &flaggerv1.MetricTemplate{}
+			m.MetricTemplates = append(m.MetricTemplates, template)
+			obj = template
+		case flaggerv1.AlertProviderKind:
+			provider := &flaggerv1.AlertProvider{}
+			m.AlertProviders = append(m.AlertProviders, provider)
+			obj = provider
+		case flaggerv1.CanaryWaveKind:
+			wave := &flaggerv1.CanaryWave{}
+			m.CanaryWaves = append(m.CanaryWaves, wave)
+			obj = wave
+		case flaggerv1.CanaryClassKind:
+			class := &flaggerv1.CanaryClass{}
+			m.CanaryClasses = append(m.CanaryClasses, class)
+			obj = class
+		default:
+			issues = append(issues, Issue{Source: source, Kind: typeMeta.Kind, Message: "kind not supported"})
+			continue
+		}
+
+		if m.sources == nil {
+			m.sources = make(map[metav1.Object]string)
+		}
+		m.sources[obj] = source
+
+		// decode leniently after a schema error so that the semantic rules still run
+		if err := yaml.UnmarshalStrict(doc, obj); err != nil {
+			if err := yaml.Unmarshal(doc, obj); err != nil {
+				return issues, fmt.Errorf("decoding %s %s failed: %w", typeMeta.Kind, source, err)
+			}
+			issues = append(issues, Issue{
+				Source:    source,
+				Kind:      typeMeta.Kind,
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				Message:   fmt.Sprintf("schema error: %v", err),
+			})
+		}
+		if typeMeta.APIVersion != flaggerv1.SchemeGroupVersion.String() {
+			issues = append(issues, Issue{
+				Source:    source,
+				Kind:      typeMeta.Kind,
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				Field:     "apiVersion",
+				Message:   fmt.Sprintf("%s not supported, use %s", typeMeta.APIVersion, flaggerv1.SchemeGroupVersion.String()),
+			})
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,751 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/observers"
+)
+
+// Issue is a problem found in a manifest
+type Issue struct {
+	// Source is the file the object was decoded from
+	Source    string
+	Kind      string
+	Namespace string
+	Name      string
+	// Field is the path of the invalid field, empty when the issue concerns the whole object
+	Field   string
+	Message string
+}
+
+// String formats the issue as source: kind/namespace/name: field: message
+func (i Issue) String() string {
+	object := i.Kind
+	if i.Namespace != "" {
+		object = fmt.Sprintf("%s/%s", object, i.Namespace)
+	}
+	if i.Name != "" {
+		object = fmt.Sprintf("%s/%s", object, i.Name)
+	}
+	msg := fmt.Sprintf("%s: %s", object, i.Message)
+	if i.Field != "" {
+		msg = fmt.Sprintf("%s: %s: %s", object, i.Field, i.Message)
+	}
+	if i.Source != "" {
+		msg = fmt.Sprintf("%s: %s", i.Source, msg)
+	}
+	return msg
+}
+
+var (
+	targetKinds       = []string{"Deployment", "DaemonSet", "StatefulSet", "Service"}
+	targetRefKinds    = []string{"Deployment", "DaemonSet", "StatefulSet"}
+	builtinMetrics    = []string{"request-success-rate", "request-duration", "resource-cost"}
+	metricProviders   = []string{"prometheus", "datadog", "cloudwatch", "newrelic", "opencost", "plugin"}
+	alertProviders    = []string{"slack", "discord", "rocket", "msteams", "grafana", "flux", "github", "gitlab"}
+	alertSeverities   = []string{string(flaggerv1.SeverityInfo), string(flaggerv1.SeverityWarn), string(flaggerv1.SeverityError)}
+	meshProviderNames = []string{
+		flaggerv1.AppMeshProvider,
+		flaggerv1.LinkerdProvider,
+		flaggerv1.IstioProvider,
+		flaggerv1.ContourProvider,
+		flaggerv1.NGINXProvider,
+		flaggerv1.KubernetesProvider,
+		flaggerv1.SkipperProvider,
+		flaggerv1.TraefikProvider,
+		flaggerv1.KnativeProvider,
+	}
+	webhookTypes = []string{
+		string(flaggerv1.RolloutHook),
+		string(flaggerv1.PreRolloutHook),
+		string(flaggerv1.PostRolloutHook),
+		string(flaggerv1.ConfirmRolloutHook),
+		string(flaggerv1.ConfirmPromotionHook),
+		string(flaggerv1.EventHook),
+		string(flaggerv1.RollbackHook),
+		flaggerv1.ConfirmTrafficIncreaseHook,
+	}
+)
+
+// Lint checks the semantic rules of the manifests,
+// the references between the objects must be resolved within the manifests
+func (m *Manifests) Lint() []Issue {
+	var issues []Issue
+	for _, cd := range m.Canaries {
+		issues = append(issues, m.lintCanary(cd)...)
+	}
+	for _, template := range m.MetricTemplates {
+		issues = append(issues, m.lintMetricTemplate(template)...)
+	}
+	for _, provider := range m.AlertProviders {
+		issues = append(issues, m.lintAlertProvider(provider)...)
+	}
+	for _, wave := range m.CanaryWaves {
+		issues = append(issues, m.lintCanaryWave(wave)...)
+	}
+	for _, class := range m.CanaryClasses {
+		issues = append(issues, m.lintCanaryClass(class)...)
+	}
+	return issues
+}
+
+// reporter returns a function that adds the issues of an object
+func (m *Manifests) reporter(kind string, obj metav1.Object, issues *[]Issue) func(field string, format string, a ...interface{}) {
+	return func(field string, format string, a ...interface{}) {
+		*issues = append(*issues, Issue{
+			Source:    m.sources[obj],
+			Kind:      kind,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Field:     field,
+			Message:   fmt.Sprintf(format, a...),
+		})
+	}
+}
+
+func (m *Manifests) lintCanary(cd *flaggerv1.Canary) []Issue {
+	var issues []Issue
+	report := m.reporter(flaggerv1.CanaryKind, cd, &issues)
+
+	// check the canary with the defaults of its class
+	if cd.Spec.ClassName != "" {
+		if class := m.canaryClass(cd.Spec.ClassName); class != nil {
+			cd = class.ApplyTo(cd)
+		} else {
+			report("spec.className", "canary class %s not found", cd.Spec.ClassName)
+		}
+	}
+
+	if cd.Spec.TargetRef.Name == "" {
+		report("spec.targetRef.name", "is required")
+	}
+	if suffix := cd.Spec.PrimarySuffix; suffix != "" {
+		if errs := validation.IsDNS1123Label(strings.TrimPrefix(suffix, "-")); len(errs) > 0 {
+			report("spec.primarySuffix", "%q is invalid: %s", suffix, strings.Join(errs, ", "))
+		} else if name := cd.GetPrimaryName(cd.Spec.TargetRef.Name, suffix); len(name) > validation.DNS1123LabelMaxLength {
+			report("spec.primarySuffix", "the primary name %s is longer than %d characters", name, validation.DNS1123LabelMaxLength)
+		}
+	}
+	if cd.GetTargetKind() == flaggerv1.ScaleSubresourceKind {
+		if cd.Spec.TargetRef.Kind == "" {
+			report("spec.targetRef.kind", "is required for custom resource targets")
+		}
+		if cd.Spec.TargetCluster != nil || len(cd.Spec.RemoteClusters) > 0 {
+			report("spec.targetCluster", "can't be set for custom resource targets")
+		}
+	} else if cd.Spec.TargetRef.Kind != "" && !contains(targetKinds, cd.Spec.TargetRef.Kind) {
+		report("spec.targetRef.kind", "%q not supported, must be one of %s", cd.Spec.TargetRef.Kind, strings.Join(targetKinds, ", "))
+	}
+	targetNames := map[string]bool{cd.Spec.TargetRef.Kind + "/" + cd.Spec.TargetRef.Name: true}
+	for i, ref := range cd.Spec.TargetRefs {
+		field := fmt.Sprintf("spec.targetRefs[%d]", i)
+		if ref.Name == "" {
+			report(field+".name", "is required")
+		}
+		if !contains(targetRefKinds, ref.Kind) || flaggerv1.TargetKind(ref) != ref.Kind {
+			report(field+".kind", "%q not supported, must be one of %s", ref.Kind, strings.Join(targetRefKinds, ", "))
+		}
+		if targetNames[ref.Kind+"/"+ref.Name] {
+			report(field, "duplicate target %s %s", ref.Kind, ref.Name)
+		}
+		targetNames[ref.Kind+"/"+ref.Name] = true
+	}
+	if len(cd.Spec.TargetRefs) > 0 && cd.Spec.TargetRef.Kind == "Service" {
+		report("spec.targetRefs", "can't be set for Service targets")
+	}
+	if cd.GetTargetNamespace() != cd.Namespace {
+		switch {
+		case cd.Spec.Provider != "" && cd.Spec.Provider != flaggerv1.KubernetesProvider:
+			report("spec.targetRef.namespace", "is only supported by the %s provider", flaggerv1.KubernetesProvider)
+		case !contains(targetRefKinds, cd.GetTargetKind()):
+			report("spec.targetRef.namespace", "is only supported for %s targets", strings.Join(targetRefKinds, ", "))
+		case cd.Spec.TargetCluster != nil || len(cd.Spec.RemoteClusters) > 0 || len(cd.Spec.TargetRefs) > 0 || cd.Spec.Propagation != nil:
+			report("spec.targetRef.namespace", "can't be set with spec.targetCluster, spec.remoteClusters, spec.targetRefs or spec.propagation")
+		}
+	}
+	if cd.GetTargetKind() == flaggerv1.KruiseDaemonSetKind {
+		if cd.Spec.Provider != "" && cd.Spec.Provider != flaggerv1.KubernetesProvider {
+			report("spec.provider", "must be %s for OpenKruise DaemonSet targets, the traffic follows the updated nodes", flaggerv1.KubernetesProvider)
+		}
+		if cd.Spec.TargetCluster != nil || len(cd.Spec.RemoteClusters) > 0 {
+			report("spec.targetCluster", "can't be set for OpenKruise DaemonSet targets")
+		}
+		if a := cd.GetAnalysis(); a != nil && (a.Iterations > 0 || len(a.Match) > 0) {
+			report("spec.analysis.iterations", "is not supported for OpenKruise DaemonSet targets, the pods are rolled out with the step weights")
+		}
+	}
+	if cd.GetTargetKind() == flaggerv1.KnativeServiceKind {
+		if cd.Spec.Provider != flaggerv1.KnativeProvider {
+			report("spec.provider", "must be %s for Knative Service targets, the traffic is split with the Knative route", flaggerv1.KnativeProvider)
+		}
+		if cd.Spec.TargetCluster != nil || len(cd.Spec.RemoteClusters) > 0 {
+			report("spec.targetCluster", "can't be set for Knative Service targets")
+		}
+		if a := cd.GetAnalysis(); a != nil && (a.Iterations > 0 || len(a.Match) > 0 || a.Mirror) {
+			report("spec.analysis.iterations", "is not supported for Knative Service targets, the traffic is shifted with the step weights")
+		}
+	} else if cd.Spec.Provider == flaggerv1.KnativeProvider {
+		report("spec.provider", "%s is only supported for Knative Service targets", flaggerv1.KnativeProvider)
+	}
+	if cd.Spec.Service.Port <= 0 {
+		report("spec.service.port", "must be greater than zero")
+	}
+	if !isMeshProvider(cd.Spec.Provider) {
+		report("spec.provider", "%q not supported", cd.Spec.Provider)
+	}
+	if vs := cd.Spec.Service.VirtualService; vs != nil {
+		if vs.Name == "" {
+			report("spec.service.virtualService.name", "is required")
+		}
+		if vs.Route == "" {
+			report("spec.service.virtualService.route", "is required")
+		}
+		if cd.Spec.Service.Delegation {
+			report("spec.service.virtualService", "can't be set when delegation is enabled")
+		}
+	}
+	if rules := cd.Spec.Service.PortDiscoveryRules; rules != nil {
+		if !cd.Spec.Service.PortDiscovery {
+			report("spec.service.portDiscoveryRules", "requires portDiscovery to be enabled")
+		}
+		checkPatterns := func(field string, patterns []string) {
+			for i, pattern := range patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					report(fmt.Sprintf("spec.service.portDiscoveryRules.%s[%d]", field, i), "%q is not a valid pattern", pattern)
+				}
+			}
+		}
+		checkPatterns("include", rules.Include)
+		checkPatterns("exclude", rules.Exclude)
+		for i, m := range rules.Mappings {
+			field := fmt.Sprintf("spec.service.portDiscoveryRules.mappings[%d]", i)
+			if m.ContainerPort.String() == "0" || m.ContainerPort.String() == "" {
+				report(field+".containerPort", "is required")
+			}
+			if m.Port < 0 {
+				report(field+".port", "must not be negative")
+			} else if m.Port == cd.Spec.Service.Port {
+				report(field+".port", "is already used by spec.service.port")
+			}
+		}
+	}
+	switch cd.Spec.Service.ApexMode {
+	case "", flaggerv1.ApexModeManaged, flaggerv1.ApexModeUnmanaged:
+		if cd.Spec.Service.ExternalName != "" {
+			report("spec.service.externalName", "requires the ExternalName apex mode")
+		}
+	case flaggerv1.ApexModeExternalName:
+		if cd.Spec.Service.ExternalName == "" {
+			report("spec.service.externalName", "is required")
+		}
+	default:
+		report("spec.service.apexMode", "%q not supported, must be one of Managed, Unmanaged or ExternalName", cd.Spec.Service.ApexMode)
+	}
+	if np := cd.Spec.Service.NetworkPolicy; np != nil && len(np.From) == 0 {
+		report("spec.service.networkPolicy.from", "is required, an empty list allows all the sources")
+	}
+	for i, ref := range cd.Spec.Service.GatewayRefs {
+		if len(ref.Gateways) == 0 {
+			report(fmt.Sprintf("spec.service.gatewayRefs[%d].gateways", i), "is required")
+		}
+	}
+	if len(cd.Spec.Service.GatewayRefs) > 0 {
+		if cd.Spec.Provider != "" && cd.Spec.Provider != flaggerv1.IstioProvider {
+			report("spec.service.gatewayRefs", "is only supported by the istio provider")
+		}
+		if cd.Spec.Service.Delegation || cd.Spec.Service.VirtualService != nil {
+			report("spec.service.gatewayRefs", "can't be set when delegation or virtualService is enabled")
+		}
+	}
+	for i, pw := range cd.Spec.Service.PortWeights {
+		field := fmt.Sprintf("spec.service.portWeights[%d]", i)
+		if pw.Port <= 0 {
+			report(field+".port", "is required")
+		} else if pw.Port == cd.Spec.Service.Port {
+			report(field+".port", "is the spec.service.port, use the analysis weights instead")
+		}
+		if pw.WeightPercent < 0 || pw.WeightPercent > 100 {
+			report(field+".weightPercent", "must be in the range [0, 100]")
+		}
+		if pw.MaxWeight < 0 {
+			report(field+".maxWeight", "must not be negative")
+		}
+	}
+	if len(cd.Spec.Service.PortWeights) > 0 {
+		if cd.Spec.Provider != "" && cd.Spec.Provider != flaggerv1.IstioProvider {
+			report("spec.service.portWeights", "is only supported by the istio provider")
+		}
+		if len(cd.Spec.Service.GatewayRefs) > 0 {
+			report("spec.service.portWeights", "can't be set with gatewayRefs")
+		}
+	}
+	if rt := cd.Spec.Service.RouteTable; rt != nil {
+		if rt.Name == "" {
+			report("spec.service.routeTable.name", "is required")
+		}
+		if rt.Route == "" {
+			report("spec.service.routeTable.route", "is required")
+		}
+	}
+	clusterNames := make(map[string]bool)
+	for i, cluster := range cd.Spec.RemoteClusters {
+		field := fmt.Sprintf("spec.remoteClusters[%d]", i)
+		if cluster.Name == "" {
+			report(field+".name", "is required")
+		} else if clusterNames[cluster.Name] {
+			report(field+".name", "duplicate cluster %s", cluster.Name)
+		}
+		clusterNames[cluster.Name] = true
+		if cluster.SecretRef.Name == "" {
+			report(field+".secretRef.name", "is required")
+		}
+	}
+	if len(cd.Spec.RemoteClusters) > 0 && cd.Spec.TargetRef.Kind == "Service" {
+		report("spec.remoteClusters", "can't be set for Service targets")
+	}
+	if tc := cd.Spec.TargetCluster; tc != nil {
+		if tc.Name == "" {
+			report("spec.targetCluster.name", "is required")
+		} else if clusterNames[tc.Name] {
+			report("spec.targetCluster.name", "is also listed in spec.remoteClusters")
+		}
+		if tc.SecretRef.Name == "" {
+			report("spec.targetCluster.secretRef.name", "is required")
+		}
+		if cd.Spec.TargetRef.Kind == "Service" {
+			report("spec.targetCluster", "can't be set for Service targets")
+		}
+	}
+	if p := cd.Spec.Propagation; p != nil {
+		if p.PropagationPolicyRef == nil && p.OverridePolicyRef == nil {
+			report("spec.propagation", "must reference a PropagationPolicy or an OverridePolicy")
+		}
+		if cd.Spec.TargetRef.Kind == "Service" {
+			report("spec.propagation", "can't be set for Service targets")
+		}
+	}
+	if tp := cd.Spec.Service.TrafficPolicy; tp != nil && tp.LoadBalancer != nil && tp.LoadBalancer.LocalityLbSetting != nil {
+		locality := tp.LoadBalancer.LocalityLbSetting
+		set := 0
+		for _, ok := range []bool{len(locality.Distribute) > 0, len(locality.Failover) > 0, len(locality.FailoverPriority) > 0} {
+			if ok {
+				set++
+			}
+		}
+		if set > 1 {
+			report("spec.service.trafficPolicy.loadBalancer.localityLbSetting", "only one of distribute, failover or failoverPriority can be set")
+		}
+		if (len(locality.Failover) > 0 || len(locality.FailoverPriority) > 0) && tp.OutlierDetection == nil {
+			report("spec.service.trafficPolicy.outlierDetection", "is required for the locality failover")
+		}
+	}
+
+	analysis := cd.GetAnalysis()
+	if analysis == nil {
+		report("spec.analysis", "is required")
+		return issues
+	}
+
+	if analysis.Interval != "" {
+		if _, err := time.ParseDuration(analysis.Interval); err != nil {
+			report("spec.analysis.interval", "%v", err)
+		}
+	}
+	if analysis.MaxDuration != "" {
+		if d, err := time.ParseDuration(analysis.MaxDuration); err != nil {
+			report("spec.analysis.maxDuration", "%v", err)
+		} else if d < cd.GetAnalysisInterval() {
+			report("spec.analysis.maxDuration", "must be longer than the analysis interval")
+		}
+	}
+	switch analysis.MaxDurationAction {
+	case "", flaggerv1.MaxDurationActionRollback, flaggerv1.MaxDurationActionPause:
+	default:
+		report("spec.analysis.maxDurationAction", "%q not supported, must be one of Rollback or Pause", analysis.MaxDurationAction)
+	}
+	if analysis.Threshold < 0 {
+		report("spec.analysis.threshold", "must not be negative")
+	}
+	if analysis.TotalWeight < 0 {
+		report("spec.analysis.totalWeight", "must not be negative")
+	} else if analysis.TotalWeight > 0 && analysis.TotalWeight != flaggerv1.TotalWeight && cd.Spec.Provider != flaggerv1.NGINXProvider {
+		report("spec.analysis.totalWeight", "is only supported by the nginx provider")
+	}
+	totalWeight := cd.GetAnalysisTotalWeight()
+	if analysis.MaxWeight < 0 || analysis.MaxWeight > totalWeight {
+		report("spec.analysis.maxWeight", "must be in the range [0, %d]", totalWeight)
+	}
+	if analysis.StepWeight < 0 || analysis.StepWeight > totalWeight {
+		report("spec.analysis.stepWeight", "must be in the range [0, %d]", totalWeight)
+	}
+	if analysis.MirrorWeight < 0 || analysis.MirrorWeight > 100 {
+		report("spec.analysis.mirrorWeight", "must be in the range [0, 100]")
+	}
+	if t := analysis.PrimaryReadyThreshold; t != nil && (*t < 0 || *t > 100) {
+		report("spec.analysis.primaryReadyThreshold", "must be in the range [0, 100]")
+	}
+	if t := analysis.CanaryReadyThreshold; t != nil && (*t < 0 || *t > 100) {
+		report("spec.analysis.canaryReadyThreshold", "must be in the range [0, 100]")
+	}
+	for i, weight := range analysis.StepWeights {
+		if weight <= 0 || weight > totalWeight {
+			report(fmt.Sprintf("spec.analysis.stepWeights[%d]", i), "must be in the range (0, %d]", totalWeight)
+		} else if i > 0 && weight <= analysis.StepWeights[i-1] {
+			report(fmt.Sprintf("spec.analysis.stepWeights[%d]", i), "must be greater than the previous step")
+		}
+	}
+	if affinity := analysis.SessionAffinity; affinity != nil {
+		if affinity.CookieName == "" {
+			report("spec.analysis.sessionAffinity.cookieName", "is required")
+		}
+		if affinity.MaxAge < 0 {
+			report("spec.analysis.sessionAffinity.maxAge", "must not be negative")
+		}
+		if cd.Spec.Provider != flaggerv1.TraefikProvider && !strings.HasPrefix(cd.Spec.Provider, flaggerv1.GatewayAPIProvider) {
+			report("spec.analysis.sessionAffinity", "is only supported by the traefik and gatewayapi providers")
+		}
+	}
+	if !cd.SkipAnalysis() && analysis.Iterations == 0 && analysis.StepWeight == 0 && len(analysis.StepWeights) == 0 {
+		report("spec.analysis", "one of iterations, stepWeight or stepWeights is required")
+	}
+	if analysis.SoakIterations < 0 {
+		report("spec.analysis.soakIterations", "must not be negative")
+	} else if analysis.SoakIterations > 0 && analysis.Iterations > 0 {
+		report("spec.analysis.soakIterations", "is only supported with the step weights")
+	}
+
+	// the kubernetes provider can only run Blue/Green deployments
+	if cd.Spec.Provider == flaggerv1.KubernetesProvider && cd.GetTargetKind() != flaggerv1.KruiseDaemonSetKind {
+		if len(analysis.Match) > 0 {
+			report("spec.analysis.match", "A/B testing is not supported by the kubernetes provider")
+		}
+		if analysis.Iterations < 1 {
+			report("spec.analysis.iterations", "progressive traffic is not supported by the kubernetes provider, iterations are required")
+		}
+	}
+
+	// the Linkerd and Gateway API HTTPRoutes match the headers, the query params, the path and the method
+	if cd.Spec.Provider == flaggerv1.LinkerdProvider || strings.HasPrefix(cd.Spec.Provider, flaggerv1.GatewayAPIProvider) {
+		for i, match := range analysis.Match {
+			field := fmt.Sprintf("spec.analysis.match[%d]", i)
+			if len(match.WithoutHeaders) > 0 || len(match.SourceLabels) > 0 || match.SourceNamespace != "" ||
+				len(match.Gateways) > 0 || match.Scheme != nil || match.Authority != nil || match.Port != 0 {
+				report(field, "only headers, queryParams, uri and method are supported by the %s provider", cd.Spec.Provider)
+			}
+			if match.Method != nil && match.Method.Exact == "" {
+				report(field+".method", "only the exact method match is supported by the %s provider", cd.Spec.Provider)
+			}
+		}
+	}
+
+	// the SMI HTTPRouteGroup matches the headers, the path and the method
+	if strings.HasPrefix(cd.Spec.Provider, flaggerv1.SMIProvider+":v1alpha4") {
+		for i, match := range analysis.Match {
+			field := fmt.Sprintf("spec.analysis.match[%d]", i)
+			if len(match.QueryParams) > 0 || len(match.WithoutHeaders) > 0 || len(match.SourceLabels) > 0 ||
+				match.SourceNamespace != "" || len(match.Gateways) > 0 || match.Scheme != nil || match.Authority != nil || match.Port != 0 {
+				report(field, "only headers, uri and method are supported by the smi:v1alpha4 provider")
+			}
+			if match.Method != nil && match.Method.Exact == "" {
+				report(field+".method", "only the exact method match is supported by the smi:v1alpha4 provider")
+			}
+		}
+	}
+
+	metricNames := make(map[string]bool)
+	for i, metric := range analysis.Metrics {
+		field := fmt.Sprintf("spec.analysis.metrics[%d]", i)
+		if metric.Name == "" {
+			report(field+".name", "is required")
+		} else if metricNames[metric.Name] {
+			report(field+".name", "duplicate metric %s", metric.Name)
+		}
+		metricNames[metric.Name] = true
+
+		if metric.Interval != "" {
+			if _, err := time.ParseDuration(metric.Interval); err != nil {
+				report(field+".interval", "%v", err)
+			}
+		}
+		if tr := metric.ThresholdRange; tr != nil && tr.Min != nil && tr.Max != nil && *tr.Min > *tr.Max {
+			report(field+".thresholdRange", "min %v is greater than max %v", *tr.Min, *tr.Max)
+		}
+
+		switch {
+		case metric.TemplateRef != nil:
+			namespace := cd.Namespace
+			if metric.TemplateRef.Namespace != "" {
+				namespace = metric.TemplateRef.Namespace
+			}
+			if m.metricTemplate(metric.TemplateRef.Name, namespace) == nil {
+				report(field+".templateRef", "metric template %s.%s not found", metric.TemplateRef.Name, namespace)
+			}
+		case metric.Query != "":
+		case !contains(builtinMetrics, metric.Name):
+			report(field, "metric %s is not a builtin metric, a templateRef is required", metric.Name)
+		}
+	}
+
+	for i, webhook := range analysis.Webhooks {
+		field := fmt.Sprintf("spec.analysis.webhooks[%d]", i)
+		if webhook.Name == "" {
+			report(field+".name", "is required")
+		}
+		if webhook.Type != "" && !contains(webhookTypes, string(webhook.Type)) {
+			report(field+".type", "%q not supported, must be one of %s", webhook.Type, strings.Join(webhookTypes, ", "))
+		}
+		if u, err := url.Parse(webhook.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			report(field+".url", "%q is not a valid URL", webhook.URL)
+		} else if webhook.TLS != nil && u.Scheme != "https" {
+			report(field+".url", "%q must use https when tls is set", webhook.URL)
+		}
+		if webhook.Timeout != "" {
+			if _, err := time.ParseDuration(webhook.Timeout); err != nil {
+				report(field+".timeout", "%v", err)
+			}
+		}
+	}
+
+	for i, policy := range analysis.Policies {
+		field := fmt.Sprintf("spec.analysis.policies[%d]", i)
+		if policy.Name == "" {
+			report(field+".name", "is required")
+		}
+		if cd.Spec.TargetRef.Kind == "Service" {
+			report(field, "policy checks require a Deployment or DaemonSet target")
+		}
+		switch policy.Provider {
+		case flaggerv1.AdmissionPolicyProvider, "":
+			if policy.Address != "" || policy.Rego != "" || policy.Path != "" {
+				report(field, "address, rego and path are only supported by the opa provider")
+			}
+		case flaggerv1.OPAPolicyProvider:
+			if u, err := url.Parse(policy.Address); err != nil || u.Scheme == "" || u.Host == "" {
+				report(field+".address", "%q is not a valid URL", policy.Address)
+			}
+			if policy.Rego == "" && policy.Path == "" {
+				report(field, "one of rego or path is required")
+			}
+		default:
+			report(field+".provider", "%q not supported, must be one of %s, %s",
+				policy.Provider, flaggerv1.AdmissionPolicyProvider, flaggerv1.OPAPolicyProvider)
+		}
+		if policy.Timeout != "" {
+			if _, err := time.ParseDuration(policy.Timeout); err != nil {
+				report(field+".timeout", "%v", err)
+			}
+		}
+	}
+
+	for i, alert := range analysis.Alerts {
+		field := fmt.Sprintf("spec.analysis.alerts[%d]", i)
+		if alert.Severity != "" && !contains(alertSeverities, string(alert.Severity)) {
+			report(field+".severity", "%q not supported, must be one of %s", alert.Severity, strings.Join(alertSeverities, ", "))
+		}
+		namespace := cd.Namespace
+		if alert.ProviderRef.Namespace != "" {
+			namespace = alert.ProviderRef.Namespace
+		}
+		if m.alertProvider(alert.ProviderRef.Name, namespace) == nil {
+			report(field+".providerRef", "alert provider %s.%s not found", alert.ProviderRef.Name, namespace)
+		}
+	}
+
+	return issues
+}
+
+func (m *Manifests) lintMetricTemplate(template *flaggerv1.MetricTemplate) []Issue {
+	var issues []Issue
+	report := m.reporter(flaggerv1.MetricTemplateKind, template, &issues)
+
+	// the metric providers factory defaults to Prometheus
+	provider := template.Spec.Provider
+	if provider.Type != "" && !contains(metricProviders, provider.Type) {
+		report("spec.provider.type", "%q not supported, must be one of %s", provider.Type, strings.Join(metricProviders, ", "))
+	}
+
+	switch provider.Type {
+	case "", "prometheus", "plugin":
+		if u, err := url.Parse(provider.Address); err != nil || u.Scheme == "" || u.Host == "" {
+			report("spec.provider.address", "%q is not a valid URL", provider.Address)
+		}
+	case "cloudwatch":
+		if provider.Region == "" {
+			report("spec.provider.region", "is required by the cloudwatch provider")
+		}
+	case "datadog", "newrelic":
+		if provider.SecretRef == nil {
+			report("spec.provider.secretRef", "is required by the %s provider", provider.Type)
+		}
+	}
+
+	if template.Spec.Query == "" {
+		report("spec.query", "is required")
+		return issues
+	}
+
+	// render the query with placeholder values to find the template errors
+	query, err := observers.RenderQuery(template.Spec.Query, flaggerv1.MetricTemplateModel{
+		Name:      "name",
+		Namespace: "namespace",
+		Target:    "target",
+		Service:   "service",
+		Ingress:   "ingress",
+		Interval:  "1m",
+	})
+	if err != nil {
+		report("spec.query", "%v", err)
+		return issues
+	}
+	if provider.Type == "cloudwatch" {
+		var queries []map[string]interface{}
+		if err := json.Unmarshal([]byte(query), &queries); err != nil {
+			report("spec.query", "the cloudwatch query must be a JSON array of metric data queries: %v", err)
+		}
+	}
+
+	return issues
+}
+
+func (m *Manifests) lintAlertProvider(provider *flaggerv1.AlertProvider) []Issue {
+	var issues []Issue
+	report := m.reporter(flaggerv1.AlertProviderKind, provider, &issues)
+
+	if !contains(alertProviders, provider.Spec.Type) {
+		report("spec.type", "%q not supported, must be one of %s", provider.Spec.Type, strings.Join(alertProviders, ", "))
+	}
+	if provider.Spec.Address == "" && provider.Spec.SecretRef == nil && provider.Spec.AddressSecretKeyRef == nil {
+		report("spec", "one of address, secretRef or addressSecretKeyRef is required")
+	}
+	if provider.Spec.Address != "" {
+		if u, err := url.Parse(provider.Spec.Address); err != nil || u.Scheme == "" || u.Host == "" {
+			report("spec.address", "%q is not a valid URL", provider.Spec.Address)
+		}
+	}
+
+	return issues
+}
+
+func (m *Manifests) lintCanaryWave(wave *flaggerv1.CanaryWave) []Issue {
+	var issues []Issue
+	report := m.reporter(flaggerv1.CanaryWaveKind, wave, &issues)
+
+	if len(wave.Spec.Canaries) == 0 {
+		report("spec.canaries", "at least one canary is required")
+	}
+	canaryNames := make(map[string]bool)
+	for i, ref := range wave.Spec.Canaries {
+		field := fmt.Sprintf("spec.canaries[%d]", i)
+		switch {
+		case ref.Name == "":
+			report(field+".name", "is required")
+		case canaryNames[ref.Name]:
+			report(field+".name", "duplicate canary %s", ref.Name)
+		case m.canary(ref.Name, wave.Namespace) == nil:
+			report(field+".name", "canary %s.%s not found", ref.Name, wave.Namespace)
+		}
+		canaryNames[ref.Name] = true
+	}
+
+	return issues
+}
+
+func (m *Manifests) lintCanaryClass(class *flaggerv1.CanaryClass) []Issue {
+	var issues []Issue
+	report := m.reporter(flaggerv1.CanaryClassKind, class, &issues)
+
+	if !isMeshProvider(class.Spec.Provider) {
+		report("spec.provider", "%q not supported", class.Spec.Provider)
+	}
+	if analysis := class.Spec.Analysis; analysis != nil {
+		if analysis.Interval != "" {
+			if _, err := time.ParseDuration(analysis.Interval); err != nil {
+				report("spec.analysis.interval", "%v", err)
+			}
+		}
+		if analysis.Iterations > 0 && (analysis.StepWeight > 0 || len(analysis.StepWeights) > 0) {
+			report("spec.analysis.iterations", "can't be set together with the step weights")
+		}
+	}
+	return issues
+}
+
+func (m *Manifests) canaryClass(name string) *flaggerv1.CanaryClass {
+	for _, class := range m.CanaryClasses {
+		if class.Name == name {
+			return class
+		}
+	}
+	return nil
+}
+
+func (m *Manifests) canary(name string, namespace string) *flaggerv1.Canary {
+	for _, cd := range m.Canaries {
+		if cd.Name == name && cd.Namespace == namespace {
+			return cd
+		}
+	}
+	return nil
+}
+
+func (m *Manifests) metricTemplate(name string, namespace string) *flaggerv1.MetricTemplate {
+	for _, template := range m.MetricTemplates {
+		if template.Name == name && template.Namespace == namespace {
+			return template
+		}
+	}
+	return nil
+}
+
+func (m *Manifests) alertProvider(name string, namespace string) *flaggerv1.AlertProvider {
+	for _, provider := range m.AlertProviders {
+		if provider.Name == name && provider.Namespace == namespace {
+			return provider
+		}
+	}
+	return nil
+}
+
+// isMeshProvider matches the provider names supported by the router factory
+func isMeshProvider(provider string) bool {
+	return provider == "" ||
+		contains(meshProviderNames, provider) ||
+		strings.HasPrefix(provider, flaggerv1.AppMeshProvider+":v1beta2") ||
+		strings.HasPrefix(provider, flaggerv1.SMIProvider) ||
+		strings.HasPrefix(provider, flaggerv1.GlooProvider) ||
+		strings.HasPrefix(provider, flaggerv1.GatewayAPIProvider)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
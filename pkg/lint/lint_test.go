@@ -0,0 +1,629 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validManifests = `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: podinfo
+  service:
+    port: 9898
+  analysis:
+    interval: 1m
+    threshold: 5
+    stepWeight: 10
+    metrics:
+      - name: request-success-rate
+        thresholdRange:
+          min: 99
+      - name: latency
+        templateRef:
+          name: latency
+          namespace: flagger
+    webhooks:
+      - name: load-test
+        url: http://flagger-loadtester.test/
+        timeout: 5s
+    policies:
+      - name: restricted
+      - name: images
+        provider: opa
+        address: http://opa.opa-system:8181
+        path: kubernetes/images/deny
+    alerts:
+      - name: on-call
+        severity: error
+        providerRef:
+          name: on-call
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: podinfo
+---
+apiVersion: flagger.app/v1beta1
+kind: MetricTemplate
+metadata:
+  name: latency
+  namespace: flagger
+spec:
+  provider:
+    type: prometheus
+    address: http://prometheus.istio-system:9090
+  query: |
+    histogram_quantile(0.99, sum(rate(request_duration_bucket{namespace="{{ namespace }}"}[{{ interval }}])) by (le))
+---
+apiVersion: flagger.app/v1beta1
+kind: AlertProvider
+metadata:
+  name: on-call
+  namespace: test
+spec:
+  type: slack
+  secretRef:
+    name: on-call-url
+`
+
+func TestManifests_Valid(t *testing.T) {
+	m := &Manifests{}
+	issues, err := m.Decode("valid.yaml", strings.NewReader(validManifests))
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	assert.Len(t, m.Canaries, 1)
+	assert.Len(t, m.MetricTemplates, 1)
+	assert.Len(t, m.AlertProviders, 1)
+	assert.Empty(t, m.Lint())
+}
+
+func TestManifests_Issues(t *testing.T) {
+	manifests := `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  provider: kubernetes
+  targetRef:
+    kind: ReplicaSet
+    name: podinfo
+  targetCluster:
+    name: west
+    secretRef:
+      name: ""
+  propagation: {}
+  remoteClusters:
+    - name: west
+      secretRef:
+        name: west-kubeconfig
+    - name: west
+      secretRef:
+        name: ""
+  service:
+    port: 9898
+    unknown: true
+    delegation: true
+    virtualService:
+      name: podinfo
+    routeTable:
+      name: podinfo
+    gatewayRefs:
+      - hosts: ["app.example.com"]
+    portWeights:
+      - port: 9898
+        weightPercent: 150
+    networkPolicy:
+      from: []
+    apexMode: External
+    portDiscoveryRules:
+      include: ["[http"]
+      mappings:
+        - containerPort: 0
+          port: 9898
+    trafficPolicy:
+      loadBalancer:
+        localityLbSetting:
+          failover:
+            - from: us-east
+              to: us-west
+  analysis:
+    interval: 1x
+    maxDuration: 2d
+    maxDurationAction: Wait
+    primaryReadyThreshold: 150
+    canaryReadyThreshold: -1
+    stepWeights: [10, 5]
+    metrics:
+      - name: latency
+        templateRef:
+          name: latency
+      - name: errors
+    policies:
+      - name: images
+        provider: opa
+      - name: restricted
+        provider: gatekeeper
+    alerts:
+      - name: on-call
+        providerRef:
+          name: on-call
+---
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: abtest
+  namespace: test
+spec:
+  provider: linkerd
+  targetRef:
+    kind: Deployment
+    name: abtest
+  service:
+    port: 9898
+  analysis:
+    interval: 1m
+    iterations: 10
+    soakIterations: 3
+    totalWeight: 1000
+    sessionAffinity:
+      cookieName: ""
+    match:
+      - headers:
+          x-canary:
+            exact: "insider"
+        withoutHeaders:
+          x-bot:
+            exact: "true"
+      - method:
+          prefix: "P"
+---
+apiVersion: flagger.app/v1beta1
+kind: MetricTemplate
+metadata:
+  name: cpu
+  namespace: test
+spec:
+  provider:
+    type: cloudwatch
+    region: us-east-1
+  query: "{{ cpu }}"
+---
+apiVersion: flagger.app/v1beta1
+kind: MetricTemplate
+metadata:
+  name: errors
+  namespace: test
+spec:
+  provider:
+    type: plugin
+  query: "errors{app={{ target }}}"
+---
+apiVersion: flagger.app/v1beta1
+kind: AlertProvider
+metadata:
+  name: pager
+  namespace: test
+spec:
+  type: pager
+---
+apiVersion: flagger.app/v1beta1
+kind: CanaryWave
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  canaries:
+    - name: podinfo-eu
+    - name: podinfo-eu
+---
+apiVersion: flagger.app/v1beta1
+kind: CanaryClass
+metadata:
+  name: standard
+spec:
+  provider: mesh
+  analysis:
+    interval: 1m
+    threshold: 5
+    iterations: 10
+    stepWeight: 10
+`
+	m := &Manifests{}
+	issues, err := m.Decode("issues.yaml", strings.NewReader(manifests))
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Contains(t, issues[0].Message, `unknown field "unknown"`)
+	assert.Equal(t, "issues.yaml", issues[0].Source)
+
+	fields := make(map[string]bool)
+	for _, issue := range m.Lint() {
+		assert.Equal(t, "issues.yaml", issue.Source)
+		fields[issue.Kind+":"+issue.Field] = true
+	}
+	for _, field := range []string{
+		"Canary:spec.targetRef.kind",
+		"Canary:spec.service.virtualService.route",
+		"Canary:spec.service.virtualService",
+		"Canary:spec.service.routeTable.route",
+		"Canary:spec.service.gatewayRefs[0].gateways",
+		"Canary:spec.service.gatewayRefs",
+		"Canary:spec.service.portWeights[0].port",
+		"Canary:spec.service.portWeights[0].weightPercent",
+		"Canary:spec.service.portWeights",
+		"Canary:spec.service.portDiscoveryRules",
+		"Canary:spec.service.networkPolicy.from",
+		"Canary:spec.service.apexMode",
+		"Canary:spec.service.portDiscoveryRules.include[0]",
+		"Canary:spec.service.portDiscoveryRules.mappings[0].containerPort",
+		"Canary:spec.service.portDiscoveryRules.mappings[0].port",
+		"Canary:spec.remoteClusters[1].name",
+		"Canary:spec.remoteClusters[1].secretRef.name",
+		"Canary:spec.targetCluster.name",
+		"Canary:spec.targetCluster.secretRef.name",
+		"Canary:spec.propagation",
+		"Canary:spec.service.trafficPolicy.outlierDetection",
+		"Canary:spec.analysis.interval",
+		"Canary:spec.analysis.maxDuration",
+		"Canary:spec.analysis.maxDurationAction",
+		"Canary:spec.analysis.primaryReadyThreshold",
+		"Canary:spec.analysis.canaryReadyThreshold",
+		"Canary:spec.analysis.stepWeights[1]",
+		"Canary:spec.analysis.iterations",
+		"Canary:spec.analysis.soakIterations",
+		"Canary:spec.analysis.totalWeight",
+		"Canary:spec.analysis.sessionAffinity.cookieName",
+		"Canary:spec.analysis.sessionAffinity",
+		"Canary:spec.analysis.metrics[0].templateRef",
+		"Canary:spec.analysis.metrics[1]",
+		"Canary:spec.analysis.policies[0].address",
+		"Canary:spec.analysis.policies[0]",
+		"Canary:spec.analysis.policies[1].provider",
+		"Canary:spec.analysis.alerts[0].providerRef",
+		"Canary:spec.analysis.match[0]",
+		"Canary:spec.analysis.match[1].method",
+		"MetricTemplate:spec.query",
+		"MetricTemplate:spec.provider.address",
+		"AlertProvider:spec.type",
+		"AlertProvider:spec",
+		"CanaryWave:spec.canaries[0].name",
+		"CanaryWave:spec.canaries[1].name",
+		"CanaryClass:spec.provider",
+		"CanaryClass:spec.analysis.iterations",
+	} {
+		assert.True(t, fields[field], "missing issue for %s", field)
+	}
+}
+
+func TestManifests_CanaryClass(t *testing.T) {
+	canary := `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  className: standard
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: podinfo
+  service:
+    port: 9898
+`
+	class := `
+---
+apiVersion: flagger.app/v1beta1
+kind: CanaryClass
+metadata:
+  name: standard
+spec:
+  provider: istio
+  analysis:
+    interval: 1m
+    threshold: 5
+    stepWeight: 10
+    metrics:
+      - name: request-success-rate
+        thresholdRange:
+          min: 99
+`
+	// the canary inherits the analysis of the class
+	m := &Manifests{}
+	issues, err := m.Decode("class.yaml", strings.NewReader(canary+class))
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	assert.Len(t, m.CanaryClasses, 1)
+	assert.Empty(t, m.Lint())
+
+	m = &Manifests{}
+	_, err = m.Decode("canary.yaml", strings.NewReader(canary))
+	require.NoError(t, err)
+	fields := make(map[string]bool)
+	for _, issue := range m.Lint() {
+		fields[issue.Field] = true
+	}
+	assert.True(t, fields["spec.className"])
+	assert.True(t, fields["spec.analysis"])
+}
+
+func TestManifests_KruiseDaemonSet(t *testing.T) {
+	canary := `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: node-agent
+  namespace: test
+spec:
+  provider: kubernetes
+  targetRef:
+    apiVersion: apps.kruise.io/v1alpha1
+    kind: DaemonSet
+    name: node-agent
+  service:
+    port: 9898
+  analysis:
+    interval: 1m
+    threshold: 5
+    stepWeight: 10
+    maxWeight: 50
+`
+	// the partition rolls out the pods with the step weights of the kubernetes provider
+	m := &Manifests{}
+	_, err := m.Decode("kruise.yaml", strings.NewReader(canary))
+	require.NoError(t, err)
+	assert.Empty(t, m.Lint())
+
+	m = &Manifests{}
+	_, err = m.Decode("kruise.yaml", strings.NewReader(strings.NewReplacer(
+		"provider: kubernetes", "provider: istio",
+		"stepWeight: 10", "iterations: 10",
+	).Replace(canary)))
+	require.NoError(t, err)
+	fields := make(map[string]bool)
+	for _, issue := range m.Lint() {
+		fields[issue.Field] = true
+	}
+	assert.True(t, fields["spec.provider"])
+	assert.True(t, fields["spec.analysis.iterations"])
+}
+
+func TestManifests_CustomResourceTarget(t *testing.T) {
+	canary := `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  targetRef:
+    apiVersion: argoproj.io/v1alpha1
+    kind: Rollout
+    name: podinfo
+  service:
+    port: 9898
+  analysis:
+    interval: 1m
+    threshold: 5
+    stepWeight: 10
+    maxWeight: 50
+`
+	// any kind of custom resource can be targeted through its scale subresource
+	m := &Manifests{}
+	_, err := m.Decode("rollout.yaml", strings.NewReader(canary))
+	require.NoError(t, err)
+	assert.Empty(t, m.Lint())
+
+	m = &Manifests{}
+	_, err = m.Decode("rollout.yaml", strings.NewReader(strings.Replace(canary,
+		"  service:", "  targetCluster:\n    name: prod\n  service:", 1)))
+	require.NoError(t, err)
+	issues := m.Lint()
+	require.NotEmpty(t, issues)
+	assert.Equal(t, "spec.targetCluster", issues[0].Field)
+}
+
+func TestManifests_KnativeServiceTarget(t *testing.T) {
+	canary := `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  provider: knative
+  targetRef:
+    apiVersion: serving.knative.dev/v1
+    kind: Service
+    name: podinfo
+  service:
+    port: 80
+  analysis:
+    interval: 1m
+    threshold: 5
+    stepWeight: 10
+    maxWeight: 50
+`
+	m := &Manifests{}
+	_, err := m.Decode("canary.yaml", strings.NewReader(canary))
+	require.NoError(t, err)
+	assert.Empty(t, m.Lint())
+
+	// the traffic of the Knative Services is split by the knative provider only
+	m = &Manifests{}
+	_, err = m.Decode("canary.yaml", strings.NewReader(strings.Replace(canary, "provider: knative", "provider: istio", 1)))
+	require.NoError(t, err)
+	issues := m.Lint()
+	require.Len(t, issues, 1)
+	assert.Equal(t, "spec.provider", issues[0].Field)
+
+	m = &Manifests{}
+	_, err = m.Decode("canary.yaml", strings.NewReader(strings.Replace(canary, "serving.knative.dev/v1", "apps/v1", 1)))
+	require.NoError(t, err)
+	issues = m.Lint()
+	require.Len(t, issues, 1)
+	assert.Equal(t, "spec.provider", issues[0].Field)
+}
+
+func TestManifests_TargetRefs(t *testing.T) {
+	canary := `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: frontend
+  targetRefs:
+    - apiVersion: apps/v1
+      kind: Deployment
+      name: frontend-sidecar
+  service:
+    port: 9898
+  analysis:
+    interval: 1m
+    threshold: 5
+    stepWeight: 10
+    maxWeight: 50
+`
+	m := &Manifests{}
+	_, err := m.Decode("canary.yaml", strings.NewReader(canary))
+	require.NoError(t, err)
+	assert.Empty(t, m.Lint())
+
+	for _, c := range []struct {
+		old, new, field string
+	}{
+		{old: "name: frontend-sidecar", new: "name: frontend", field: "spec.targetRefs[0]"},
+		{old: "kind: Deployment\n      name", new: "kind: Service\n      name", field: "spec.targetRefs[0].kind"},
+		{old: "apiVersion: apps/v1\n      kind", new: "apiVersion: apps.kruise.io/v1alpha1\n      kind", field: "spec.targetRefs[0].kind"},
+	} {
+		m = &Manifests{}
+		_, err = m.Decode("canary.yaml", strings.NewReader(strings.Replace(canary, c.old, c.new, 1)))
+		require.NoError(t, err)
+		issues := m.Lint()
+		require.NotEmpty(t, issues, c.new)
+		assert.Equal(t, c.field, issues[0].Field)
+	}
+}
+
+func TestManifests_CrossNamespaceTarget(t *testing.T) {
+	canary := `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: control
+spec:
+  provider: kubernetes
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: podinfo
+    namespace: test
+  service:
+    port: 9898
+  analysis:
+    interval: 1m
+    threshold: 5
+    iterations: 10
+`
+	m := &Manifests{}
+	_, err := m.Decode("canary.yaml", strings.NewReader(canary))
+	require.NoError(t, err)
+	assert.Empty(t, m.Lint())
+
+	for _, c := range []struct {
+		old, new string
+	}{
+		{old: "provider: kubernetes", new: "provider: istio"},
+		{old: "kind: Deployment", new: "kind: Service"},
+		{old: "  service:", new: "  targetCluster:\n    name: prod\n    secretRef:\n      name: prod-kubeconfig\n  service:"},
+	} {
+		m = &Manifests{}
+		_, err = m.Decode("canary.yaml", strings.NewReader(strings.Replace(canary, c.old, c.new, 1)))
+		require.NoError(t, err)
+		issues := m.Lint()
+		require.NotEmpty(t, issues, c.new)
+		assert.Equal(t, "spec.targetRef.namespace", issues[0].Field, c.new)
+	}
+}
+
+func TestManifests_PrimarySuffix(t *testing.T) {
+	canary := `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  primarySuffix: -stable
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: podinfo
+  service:
+    port: 9898
+  analysis:
+    interval: 1m
+    threshold: 5
+    stepWeight: 10
+    maxWeight: 50
+`
+	m := &Manifests{}
+	_, err := m.Decode("canary.yaml", strings.NewReader(canary))
+	require.NoError(t, err)
+	assert.Empty(t, m.Lint())
+
+	for _, suffix := range []string{"-Stable", "_stable", "stable-", "-" + strings.Repeat("a", 60)} {
+		m = &Manifests{}
+		_, err = m.Decode("canary.yaml", strings.NewReader(strings.Replace(canary, "-stable", suffix, 1)))
+		require.NoError(t, err)
+		issues := m.Lint()
+		require.Len(t, issues, 1, suffix)
+		assert.Equal(t, "spec.primarySuffix", issues[0].Field)
+	}
+}
+
+func TestIssue_String(t *testing.T) {
+	issue := Issue{
+		Source:    "canary.yaml",
+		Kind:      "Canary",
+		Namespace: "test",
+		Name:      "podinfo",
+		Field:     "spec.service.port",
+		Message:   "must be greater than zero",
+	}
+	assert.Equal(t, "canary.yaml: Canary/test/podinfo: spec.service.port: must be greater than zero", issue.String())
+}
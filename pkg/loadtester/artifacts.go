@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+)
+
+// gcsEndpoint is the S3 compatible endpoint of Google Cloud Storage, used with HMAC keys
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// ArtifactStore saves the raw output of the tasks
+type ArtifactStore interface {
+	// Upload saves the data under the key
+	Upload(ctx context.Context, key string, data []byte) error
+	// URL returns the address of the artifact saved under the key
+	URL(key string) string
+}
+
+// S3ArtifactStore saves the artifacts in a S3 or GCS bucket
+type S3ArtifactStore struct {
+	client *s3.S3
+	bucket string
+	prefix string
+}
+
+// NewArtifactStore returns the store of a s3://<bucket>/<prefix> or gs://<bucket>/<prefix> address,
+// the credentials are read from the AWS environment variables, the shared config or the instance role,
+// GCS buckets are accessed with HMAC keys through the S3 compatible API
+func NewArtifactStore(address string, endpoint string, region string) (*S3ArtifactStore, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifacts address %s: %w", address, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid artifacts address %s: the bucket is required", address)
+	}
+
+	config := aws.NewConfig()
+	switch u.Scheme {
+	case "s3":
+	case "gs":
+		if endpoint == "" {
+			endpoint = gcsEndpoint
+		}
+		if region == "" {
+			region = "auto"
+		}
+	default:
+		return nil, fmt.Errorf("invalid artifacts address %s: the scheme must be s3 or gs", address)
+	}
+	if endpoint != "" {
+		config = config.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+	if region != "" {
+		config = config.WithRegion(region)
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating aws session: %w", err)
+	}
+	return &S3ArtifactStore{
+		client: s3.New(sess),
+		bucket: u.Host,
+		prefix: strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+func (s *S3ArtifactStore) objectKey(key string) string {
+	return path.Join(s.prefix, key)
+}
+
+func (s *S3ArtifactStore) Upload(ctx context.Context, key string, data []byte) error {
+	_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.objectKey(key)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("text/plain; charset=utf-8"),
+	})
+	if err != nil {
+		return fmt.Errorf("uploading artifact %s to %s failed: %w", key, s.bucket, err)
+	}
+	return nil
+}
+
+func (s *S3ArtifactStore) URL(key string) string {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err := req.Build(); err != nil {
+		return fmt.Sprintf("s3://%s/%s", s.bucket, s.objectKey(key))
+	}
+	return req.HTTPRequest.URL.String()
+}
+
+// artifactKey returns the key of the output of a task in the analysis run,
+// the calls of the same task in a run share the key so that the latest output is kept
+func artifactKey(name string, namespace string, runID string, taskHash string) string {
+	if runID == "" {
+		runID = time.Now().UTC().Format("20060102T150405Z")
+	}
+	return path.Join(namespace, name, runID, taskHash+".log")
+}
+
+// uploadArtifact saves the output of a task and returns its URL, the empty outputs are not saved
+func uploadArtifact(store ArtifactStore, key string, out []byte, logger *zap.SugaredLogger) string {
+	if len(out) == 0 {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := store.Upload(ctx, key, out); err != nil {
+		logger.Errorf("%v", err)
+		return ""
+	}
+	return store.URL(key)
+}
+
+// artifactTask uploads the output of a background task once it's done
+type artifactTask struct {
+	Task
+	store  ArtifactStore
+	key    string
+	logger *zap.SugaredLogger
+}
+
+func (t *artifactTask) Run(ctx context.Context) *TaskRunResult {
+	result := t.Task.Run(ctx)
+	if result != nil {
+		uploadArtifact(t.store, t.key, result.out, t.logger.With("canary", t.Canary()))
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+type mockArtifactStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *mockArtifactStore) Upload(ctx context.Context, key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	return nil
+}
+
+func (m *mockArtifactStore) URL(key string) string {
+	return "https://artifacts.example.com/" + key
+}
+
+func TestS3ArtifactStore_Upload(t *testing.T) {
+	var path string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	sess, err := session.NewSession(aws.NewConfig().
+		WithEndpoint(ts.URL).
+		WithRegion("us-east-1").
+		WithS3ForcePathStyle(true).
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", "")))
+	require.NoError(t, err)
+	store := &S3ArtifactStore{client: s3.New(sess), bucket: "reports", prefix: "loadtester"}
+
+	key := artifactKey("podinfo", "test", "run-1", "abc")
+	assert.Equal(t, "test/podinfo/run-1/abc.log", key)
+
+	err = store.Upload(context.TODO(), key, []byte("report"))
+	require.NoError(t, err)
+	assert.Equal(t, "/reports/loadtester/test/podinfo/run-1/abc.log", path)
+	assert.Equal(t, "report", string(body))
+	assert.Equal(t, ts.URL+"/reports/loadtester/test/podinfo/run-1/abc.log", store.URL(key))
+}
+
+func TestNewArtifactStore(t *testing.T) {
+	store, err := NewArtifactStore("gs://reports/loadtester/", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "reports", store.bucket)
+	assert.Equal(t, "loadtester", store.prefix)
+	assert.Equal(t, "https://storage.googleapis.com/reports/loadtester/test/podinfo.log", store.URL("test/podinfo.log"))
+
+	_, err = NewArtifactStore("https://reports", "", "")
+	assert.Error(t, err)
+}
+
+func TestServer_HandleNewBashTaskUploadArtifact(t *testing.T) {
+	mocks := newServerFixture()
+	store := &mockArtifactStore{objects: make(map[string][]byte)}
+	mocks.taskRunner.artifacts = store
+
+	req := newJsonRequest("POST", "/", &flaggerv1.CanaryWebhookPayload{
+		Name:      "podinfo",
+		Namespace: "test",
+		RunID:     "run-1",
+		Metadata: map[string]string{
+			"type":           TaskTypeBash,
+			"cmd":            "echo report",
+			"uploadArtifact": "true",
+		},
+	})
+	HandleNewTask(mocks.logger, mocks.taskRunner)(mocks.resp, req)
+
+	assert.Equal(t, http.StatusOK, mocks.resp.Code)
+	url := mocks.resp.Header().Get(flaggerv1.ArtifactURLHeader)
+	assert.Contains(t, url, "https://artifacts.example.com/test/podinfo/run-1/")
+	require.Len(t, store.objects, 1)
+	for _, data := range store.objects {
+		assert.Equal(t, "report\n", string(data))
+	}
+
+	// the output is not uploaded unless requested
+	mocks = newServerFixture()
+	mocks.taskRunner.artifacts = store
+	req = newJsonRequest("POST", "/", &flaggerv1.CanaryWebhookPayload{
+		Metadata: map[string]string{
+			"type": TaskTypeBash,
+			"cmd":  "echo report",
+		},
+	})
+	HandleNewTask(mocks.logger, mocks.taskRunner)(mocks.resp, req)
+	assert.Equal(t, http.StatusOK, mocks.resp.Code)
+	assert.Empty(t, mocks.resp.Header().Get(flaggerv1.ArtifactURLHeader))
+}
@@ -19,23 +19,44 @@ package loadtester
 import "sync"
 
 type GateStorage struct {
-	backend string
-	data    *sync.Map
+	backend   string
+	data      *sync.Map
+	approvers *sync.Map
 }
 
 func NewGateStorage(backend string) *GateStorage {
 	return &GateStorage{
-		backend: backend,
-		data:    new(sync.Map),
+		backend:   backend,
+		data:      new(sync.Map),
+		approvers: new(sync.Map),
 	}
 }
 
 func (gs *GateStorage) open(key string) {
+	gs.openBy(key, "")
+}
+
+// openBy opens the gate and records the identity of the approver
+func (gs *GateStorage) openBy(key string, approver string) {
 	gs.data.Store(key, true)
+	if approver != "" {
+		gs.approvers.Store(key, approver)
+	} else {
+		gs.approvers.Delete(key)
+	}
 }
 
 func (gs *GateStorage) close(key string) {
 	gs.data.Store(key, false)
+	gs.approvers.Delete(key)
+}
+
+// approver returns the identity of who opened the gate, empty if unknown
+func (gs *GateStorage) approver(key string) string {
+	if val, ok := gs.approvers.Load(key); ok {
+		return val.(string)
+	}
+	return ""
 }
 
 func (gs *GateStorage) isOpen(key string) (locked bool) {
@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TaskTypeLocust represents the locust type as string
+const TaskTypeLocust = "locust"
+
+// default locust values
+const defaultLocustUsers = 10
+const defaultLocustDuration = "1m"
+
+// LocustTask runs a locustfile in headless mode and fails when the ratio
+// of failed requests is greater than the max error ratio
+type LocustTask struct {
+	TaskBase
+	Locustfile    string
+	Host          string
+	Users         int
+	SpawnRate     int
+	Duration      string
+	MaxErrorRatio float64
+	logCmdOutput  bool
+	binary        string
+}
+
+// NewLocustTask instantiates a new Locust Task
+func NewLocustTask(metadata map[string]string, canary string, logger *zap.SugaredLogger) (*LocustTask, error) {
+	if metadata["locustfile"] == "" {
+		return nil, errors.New("`locustfile` is required with type locust")
+	}
+	if _, err := os.Stat(metadata["locustfile"]); os.IsNotExist(err) {
+		return nil, fmt.Errorf("`locustfile` file doesn't exist %s", metadata["locustfile"])
+	}
+	if metadata["host"] == "" {
+		return nil, errors.New("`host` is required with type locust")
+	}
+
+	task := &LocustTask{
+		TaskBase:   TaskBase{canary: canary, logger: logger},
+		Locustfile: metadata["locustfile"],
+		Host:       metadata["host"],
+		Users:      defaultLocustUsers,
+		Duration:   defaultLocustDuration,
+		binary:     "locust",
+	}
+
+	var err error
+	if v, ok := metadata["users"]; ok {
+		if task.Users, err = strconv.Atoi(v); err != nil || task.Users < 1 {
+			return nil, errors.New("`users` must be a positive integer")
+		}
+	}
+	task.SpawnRate = task.Users
+	if v, ok := metadata["spawnRate"]; ok {
+		if task.SpawnRate, err = strconv.Atoi(v); err != nil || task.SpawnRate < 1 {
+			return nil, errors.New("`spawnRate` must be a positive integer")
+		}
+	}
+	if v, ok := metadata["duration"]; ok {
+		if _, err := time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("unable to parse `duration` %s: %w", v, err)
+		}
+		task.Duration = v
+	}
+	if v, ok := metadata["maxErrorRatio"]; ok {
+		if task.MaxErrorRatio, err = strconv.ParseFloat(v, 64); err != nil || task.MaxErrorRatio < 0 || task.MaxErrorRatio > 1 {
+			return nil, errors.New("`maxErrorRatio` must be a number between 0 and 1")
+		}
+	}
+	task.logCmdOutput, _ = strconv.ParseBool(metadata["logCmdOutput"])
+
+	return task, nil
+}
+
+func (task *LocustTask) Hash() string {
+	return hash(task.canary + task.Locustfile + task.Host)
+}
+
+func (task *LocustTask) String() string {
+	return fmt.Sprintf("%s %s", task.Locustfile, task.Host)
+}
+
+// Run starts the locust users, waits for the duration to elapse and checks the error ratio
+func (task *LocustTask) Run(ctx context.Context) (*TaskRunResult, error) {
+	dir, err := ioutil.TempDir("", "locust")
+	if err != nil {
+		return &TaskRunResult{false, nil}, fmt.Errorf("creating the stats dir failed: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	csvPrefix := filepath.Join(dir, "locust")
+	cmd := exec.CommandContext(ctx, task.binary, task.args(csvPrefix)...)
+	out, err := cmd.CombinedOutput()
+	if task.logCmdOutput {
+		fmt.Printf("%s\n", out)
+	}
+	if err != nil {
+		task.logger.With("canary", task.canary).Errorf("locust failed %s %v %s", task.Locustfile, err, out)
+		return &TaskRunResult{false, out}, fmt.Errorf("locust failed: %w %s", err, out)
+	}
+
+	requests, failures, err := readLocustStats(csvPrefix + "_stats.csv")
+	if err != nil {
+		return &TaskRunResult{false, out}, err
+	}
+	if requests == 0 {
+		return &TaskRunResult{false, out}, fmt.Errorf("locust %s didn't send any request to %s", task.Locustfile, task.Host)
+	}
+
+	ratio := float64(failures) / float64(requests)
+	if ratio > task.MaxErrorRatio {
+		return &TaskRunResult{false, out}, fmt.Errorf("locust error ratio %.4f (%d/%d requests) is greater than %v",
+			ratio, failures, requests, task.MaxErrorRatio)
+	}
+
+	task.logger.With("canary", task.canary).Infof("locust finished %s error ratio %.4f (%d/%d requests)",
+		task.Locustfile, ratio, failures, requests)
+	return &TaskRunResult{true, out}, nil
+}
+
+// args returns the arguments of a headless run, the exit code on error is disabled
+// so that the error ratio is checked against the threshold
+func (task *LocustTask) args(csvPrefix string) []string {
+	return []string{
+		"-f", task.Locustfile,
+		"--headless",
+		"--host", task.Host,
+		"--users", strconv.Itoa(task.Users),
+		"--spawn-rate", strconv.Itoa(task.SpawnRate),
+		"--run-time", task.Duration,
+		"--csv", csvPrefix,
+		"--exit-code-on-error", "0",
+		"--only-summary",
+	}
+}
+
+// readLocustStats returns the request and failure counts of the aggregated row of the stats file
+func readLocustStats(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading the locust stats failed: %w", err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return 0, 0, fmt.Errorf("parsing the locust stats failed: %w", err)
+	}
+	if len(records) < 2 {
+		return 0, 0, errors.New("the locust stats are empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	nameCol, ok1 := columns["Name"]
+	requestsCol, ok2 := columns["Request Count"]
+	failuresCol, ok3 := columns["Failure Count"]
+	if !ok1 || !ok2 || !ok3 {
+		return 0, 0, errors.New("the locust stats don't have the Name, Request Count and Failure Count columns")
+	}
+
+	for _, record := range records[1:] {
+		if len(record) <= requestsCol || len(record) <= failuresCol || record[nameCol] != "Aggregated" {
+			continue
+		}
+		requests, err := strconv.Atoi(record[requestsCol])
+		if err != nil {
+			return 0, 0, fmt.Errorf("parsing the locust request count failed: %w", err)
+		}
+		failures, err := strconv.Atoi(record[failuresCol])
+		if err != nil {
+			return 0, 0, fmt.Errorf("parsing the locust failure count failed: %w", err)
+		}
+		return requests, failures, nil
+	}
+	return 0, 0, errors.New("the locust stats don't have an Aggregated row")
+}
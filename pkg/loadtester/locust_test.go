@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testLocustStats = `Type,Name,Request Count,Failure Count,Median Response Time
+GET,/,180,2,12
+POST,/echo,20,0,15
+,Aggregated,200,2,12
+`
+
+// fakeLocust writes a script that saves the stats at the --csv prefix
+func fakeLocust(t *testing.T, stats string) string {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "stats.csv"), []byte(stats), 0644))
+	script := filepath.Join(dir, "locust")
+	require.NoError(t, ioutil.WriteFile(script, []byte(`#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "--csv" ]; then cp `+filepath.Join(dir, "stats.csv")+` "$2_stats.csv"; fi
+  shift
+done
+`), 0755))
+	return script
+}
+
+func newTestLocustTask(t *testing.T, metadata map[string]string) *LocustTask {
+	locustfile := filepath.Join(t.TempDir(), "locustfile.py")
+	require.NoError(t, ioutil.WriteFile(locustfile, []byte("from locust import HttpUser\n"), 0644))
+	metadata["locustfile"] = locustfile
+	metadata["host"] = "http://podinfo-canary.test:9898"
+	task, err := NewLocustTask(metadata, "podinfo.test", zap.NewExample().Sugar())
+	require.NoError(t, err)
+	return task
+}
+
+func TestNewLocustTask(t *testing.T) {
+	task := newTestLocustTask(t, map[string]string{"users": "50", "duration": "2m"})
+	assert.Equal(t, 50, task.Users)
+	assert.Equal(t, 50, task.SpawnRate)
+	assert.Equal(t, "2m", task.Duration)
+	assert.Contains(t, task.args("/tmp/locust"), "--headless")
+
+	_, err := NewLocustTask(map[string]string{"host": "http://podinfo"}, "podinfo.test", zap.NewExample().Sugar())
+	assert.Error(t, err)
+	_, err = NewLocustTask(map[string]string{"locustfile": "/missing.py", "host": "http://podinfo"}, "podinfo.test", zap.NewExample().Sugar())
+	assert.Error(t, err)
+}
+
+func TestLocustTask_Run(t *testing.T) {
+	task := newTestLocustTask(t, map[string]string{"maxErrorRatio": "0.05"})
+	task.binary = fakeLocust(t, testLocustStats)
+	result, err := task.Run(context.TODO())
+	require.NoError(t, err)
+	assert.True(t, result.ok)
+
+	// 1% of the requests failed
+	task = newTestLocustTask(t, map[string]string{"maxErrorRatio": "0.001"})
+	task.binary = fakeLocust(t, testLocustStats)
+	result, err = task.Run(context.TODO())
+	assert.Error(t, err)
+	assert.False(t, result.ok)
+
+	task = newTestLocustTask(t, map[string]string{})
+	task.binary = fakeLocust(t, "Type,Name,Request Count,Failure Count\n,Aggregated,0,0\n")
+	result, err = task.Run(context.TODO())
+	assert.Error(t, err)
+	assert.False(t, result.ok)
+}
@@ -18,6 +18,9 @@ package loadtester
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -25,11 +28,27 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrQueueFull is returned when a task can't be queued because the queue reached its max size
+var ErrQueueFull = errors.New("the load tester queue is full")
+
 type TaskRunnerInterface interface {
-	Add(task Task)
+	Add(task Task) error
+	Acquire(canary string) bool
+	Release(canary string)
 	GetTotalExecs() uint64
 	Start(interval time.Duration, stopCh <-chan struct{})
 	Timeout() time.Duration
+	Artifacts() ArtifactStore
+}
+
+// Limits of the tasks running at the same time, zero means unlimited
+type Limits struct {
+	// MaxConcurrent is the max number of tasks running at the same time
+	MaxConcurrent int
+	// MaxPerNamespace is the max number of tasks running at the same time for the canaries of a namespace
+	MaxPerNamespace int
+	// MaxQueue is the max number of tasks waiting for a slot
+	MaxQueue int
 }
 
 type TaskRunner struct {
@@ -38,6 +57,19 @@ type TaskRunner struct {
 	todoTasks    *sync.Map
 	runningTasks *sync.Map
 	totalExecs   uint64
+
+	limits  Limits
+	mu      sync.Mutex
+	running int
+	perNS   map[string]int
+
+	artifacts ArtifactStore
+}
+
+// queuedTask is a task waiting in the to do list, the tasks are started in the order they were added
+type queuedTask struct {
+	task  Task
+	added time.Time
 }
 
 func NewTaskRunner(logger *zap.SugaredLogger, timeout time.Duration) *TaskRunner {
@@ -46,11 +78,84 @@ func NewTaskRunner(logger *zap.SugaredLogger, timeout time.Duration) *TaskRunner
 		todoTasks:    new(sync.Map),
 		runningTasks: new(sync.Map),
 		timeout:      timeout,
+		perNS:        make(map[string]int),
 	}
 }
 
-func (tr *TaskRunner) Add(task Task) {
-	tr.todoTasks.Store(task.Hash(), task)
+// SetLimits sets the max number of tasks running at the same time
+func (tr *TaskRunner) SetLimits(limits Limits) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.limits = limits
+}
+
+// SetArtifactStore sets the store of the tasks output
+func (tr *TaskRunner) SetArtifactStore(store ArtifactStore) {
+	tr.artifacts = store
+}
+
+// Add queues the task, the tasks already queued keep their position
+func (tr *TaskRunner) Add(task Task) error {
+	if _, exists := tr.todoTasks.Load(task.Hash()); exists {
+		return nil
+	}
+
+	tr.mu.Lock()
+	maxQueue := tr.limits.MaxQueue
+	tr.mu.Unlock()
+	if maxQueue > 0 && tr.queued() >= maxQueue {
+		return ErrQueueFull
+	}
+
+	tr.todoTasks.LoadOrStore(task.Hash(), &queuedTask{task: task, added: time.Now()})
+	return nil
+}
+
+func (tr *TaskRunner) queued() int {
+	n := 0
+	tr.todoTasks.Range(func(key interface{}, value interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Acquire reserves a slot for a blocking task of the canary, it returns false right away when no slot is free
+func (tr *TaskRunner) Acquire(canary string) bool {
+	return tr.tryAcquire(canary)
+}
+
+func (tr *TaskRunner) tryAcquire(canary string) bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	namespace := canaryNamespace(canary)
+	if tr.limits.MaxConcurrent > 0 && tr.running >= tr.limits.MaxConcurrent {
+		return false
+	}
+	if tr.limits.MaxPerNamespace > 0 && tr.perNS[namespace] >= tr.limits.MaxPerNamespace {
+		return false
+	}
+	tr.running++
+	tr.perNS[namespace]++
+	return true
+}
+
+// Release frees the slot of a task of the canary
+func (tr *TaskRunner) Release(canary string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	namespace := canaryNamespace(canary)
+	tr.running--
+	if tr.perNS[namespace]--; tr.perNS[namespace] <= 0 {
+		delete(tr.perNS, namespace)
+	}
+}
+
+// canaryNamespace returns the namespace of a canary formatted as <name>.<namespace>
+func canaryNamespace(canary string) string {
+	return canary[strings.LastIndex(canary, ".")+1:]
 }
 
 func (tr *TaskRunner) GetTotalExecs() uint64 {
@@ -58,37 +163,53 @@ func (tr *TaskRunner) GetTotalExecs() uint64 {
 }
 
 func (tr *TaskRunner) runAll() {
+	var todo []*queuedTask
 	tr.todoTasks.Range(func(key interface{}, value interface{}) bool {
-		task := value.(Task)
-		go func(t Task) {
-			// remove task from the to do list
+		todo = append(todo, value.(*queuedTask))
+		return true
+	})
+	sort.SliceStable(todo, func(i, j int) bool {
+		return todo[i].added.Before(todo[j].added)
+	})
+
+	for _, item := range todo {
+		t := item.task
+
+		// check if task is already running, if not run the task's command
+		if _, exists := tr.runningTasks.Load(t.Hash()); exists {
 			tr.todoTasks.Delete(t.Hash())
+			tr.logger.With("canary", t.Canary()).Infof("command skipped %s is already running", t)
+			continue
+		}
 
-			// check if task is already running, if not run the task's command
-			if _, exists := tr.runningTasks.Load(t.Hash()); !exists {
-				// save the task in the running list
-				tr.runningTasks.Store(t.Hash(), t)
+		// keep the task in the queue until a slot is free
+		if !tr.tryAcquire(t.Canary()) {
+			continue
+		}
 
-				// create timeout context
-				ctx, cancel := context.WithTimeout(context.Background(), tr.timeout)
-				defer cancel()
+		// move the task from the to do list to the running list
+		tr.todoTasks.Delete(t.Hash())
+		tr.runningTasks.Store(t.Hash(), t)
 
-				// increment the total exec counter
-				atomic.AddUint64(&tr.totalExecs, 1)
+		go func(t Task) {
+			defer tr.Release(t.Canary())
 
-				tr.logger.With("canary", t.Canary()).Infof("task starting %s", t)
+			// create timeout context
+			ctx, cancel := context.WithTimeout(context.Background(), tr.timeout)
+			defer cancel()
 
-				// run task with the timeout context
-				t.Run(ctx)
+			// increment the total exec counter
+			atomic.AddUint64(&tr.totalExecs, 1)
 
-				// remove task from the running list
-				tr.runningTasks.Delete(t.Hash())
-			} else {
-				tr.logger.With("canary", t.Canary()).Infof("command skipped %s is already running", t)
-			}
-		}(task)
-		return true
-	})
+			tr.logger.With("canary", t.Canary()).Infof("task starting %s", t)
+
+			// run task with the timeout context
+			t.Run(ctx)
+
+			// remove task from the running list
+			tr.runningTasks.Delete(t.Hash())
+		}(t)
+	}
 }
 
 func (tr *TaskRunner) Start(interval time.Duration, stopCh <-chan struct{}) {
@@ -107,3 +228,8 @@ func (tr *TaskRunner) Start(interval time.Duration, stopCh <-chan struct{}) {
 func (tr *TaskRunner) Timeout() time.Duration {
 	return tr.timeout
 }
+
+// Artifacts returns the store of the tasks output, nil when the uploads are disabled
+func (tr *TaskRunner) Artifacts() ArtifactStore {
+	return tr.artifacts
+}
@@ -59,3 +59,44 @@ func TestTaskRunner_Start(t *testing.T) {
 	time.Sleep(time.Second)
 	assert.Equal(t, uint64(4), tr.GetTotalExecs())
 }
+
+func TestTaskRunner_Limits(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	logger, _ := logger.NewLogger("debug")
+	tr := NewTaskRunner(logger, time.Hour)
+	tr.SetLimits(Limits{MaxConcurrent: 2, MaxPerNamespace: 1, MaxQueue: 3})
+
+	go tr.Start(10*time.Millisecond, stop)
+
+	taskFactory, _ := GetTaskFactory(TaskTypeShell)
+	task1, _ := taskFactory(map[string]string{"cmd": "sleep 0.5"}, "podinfo.test1", logger)
+	task2, _ := taskFactory(map[string]string{"cmd": "sleep 0.6"}, "podinfo.test1", logger)
+	task3, _ := taskFactory(map[string]string{"cmd": "sleep 0.5"}, "podinfo.test2", logger)
+	task4, _ := taskFactory(map[string]string{"cmd": "sleep 0.5"}, "podinfo.test3", logger)
+
+	// one task per namespace and two in total
+	assert.NoError(t, tr.Add(task1))
+	assert.NoError(t, tr.Add(task2))
+	assert.NoError(t, tr.Add(task3))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, uint64(2), tr.GetTotalExecs())
+
+	// the queue is full
+	task5, _ := taskFactory(map[string]string{"cmd": "sleep 0.1"}, "podinfo.test4", logger)
+	task6, _ := taskFactory(map[string]string{"cmd": "sleep 0.1"}, "podinfo.test5", logger)
+	assert.NoError(t, tr.Add(task4))
+	assert.NoError(t, tr.Add(task5))
+	assert.Equal(t, ErrQueueFull, tr.Add(task6))
+
+	// the queued tasks start once the running ones are done
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, uint64(5), tr.GetTotalExecs())
+
+	// the slots of the blocking tasks are shared with the queued tasks
+	assert.True(t, tr.Acquire("podinfo.test6"))
+	assert.True(t, tr.Acquire("podinfo.test7"))
+	assert.False(t, tr.Acquire("podinfo.test8"))
+	tr.Release("podinfo.test6")
+	tr.Release("podinfo.test7")
+}
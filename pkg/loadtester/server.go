@@ -18,6 +18,7 @@ package loadtester
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -30,8 +31,27 @@ import (
 	"go.uber.org/zap"
 )
 
-// ListenAndServe starts a web server and waits for SIGTERM
-func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogger, taskRunner *TaskRunner, gate *GateStorage, stopCh <-chan struct{}) {
+// gateApprover returns the identity of who opens a gate, the common name of the verified client certificate,
+// the user names sent by the client can't be verified and are only recorded as a claim
+func gateApprover(r *http.Request, payload *flaggerv1.CanaryWebhookPayload) string {
+	identity := ""
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		identity = r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+
+	claim := payload.Metadata["approver"]
+	for _, header := range []string{"X-Remote-User", "X-Forwarded-User"} {
+		if user := r.Header.Get(header); user != "" {
+			claim = user
+			break
+		}
+	}
+	return flaggerv1.ApproverWithClaim(identity, claim)
+}
+
+// ListenAndServe starts a web server and waits for SIGTERM,
+// the clients must present a certificate when the TLS config is set
+func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogger, taskRunner *TaskRunner, gate *GateStorage, tlsConfig *tls.Config, stopCh <-chan struct{}) {
 	mux := http.DefaultServeMux
 	mux.Handle("/metrics", promhttp.Handler())
 	mux.HandleFunc("/healthz", HandleHealthz)
@@ -63,6 +83,10 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 		canaryName := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
 		approved := gate.isOpen(canaryName)
 		if approved {
+			if approver := gate.approver(canaryName); approver != "" {
+				w.Header().Set(flaggerv1.ApproverHeader, approver)
+				w.Header().Set(flaggerv1.ApprovalSourceHeader, string(flaggerv1.ApprovalSourceAPI))
+			}
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte("Approved"))
 		} else {
@@ -91,11 +115,12 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 		}
 
 		canaryName := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
-		gate.open(canaryName)
+		approver := gateApprover(r, canary)
+		gate.openBy(canaryName, approver)
 
 		w.WriteHeader(http.StatusAccepted)
 
-		logger.Infof("%s gate opened", canaryName)
+		logger.Infof("%s gate opened by %q", canaryName, approver)
 	})
 
 	mux.HandleFunc("/gate/close", func(w http.ResponseWriter, r *http.Request) {
@@ -203,13 +228,21 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 
 	mux.HandleFunc("/", HandleNewTask(logger, taskRunner))
 	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: mux,
+		Addr:      ":" + port,
+		Handler:   mux,
+		TLSConfig: tlsConfig,
 	}
 
 	// run server in background
 	go func() {
-		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		var err error
+		if tlsConfig != nil {
+			// the certificate is loaded from the TLS config
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != http.ErrServerClosed {
 			logger.Fatalf("HTTP server crashed %v", err)
 		}
 	}()
@@ -251,6 +284,12 @@ func HandleNewTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface) fu
 			return
 		}
 
+		// correlate the task logs with the canary analysis run
+		logger := logger
+		if payload.RunID != "" {
+			logger = logger.With("runID", payload.RunID)
+		}
+
 		if len(payload.Metadata) > 0 {
 			metadata := payload.Metadata
 			var typ, ok = metadata["type"]
@@ -263,6 +302,33 @@ func HandleNewTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface) fu
 				rtnCmdOutput, err = strconv.ParseBool(rtn)
 			}
 
+			// upload the raw output of the task to the artifacts store
+			store := taskRunner.Artifacts()
+			if upload, _ := strconv.ParseBool(metadata["uploadArtifact"]); !upload {
+				store = nil
+			}
+			saveOutput := func(taskHash string, out []byte) {
+				if store == nil {
+					return
+				}
+				key := artifactKey(payload.Name, payload.Namespace, payload.RunID, taskHash)
+				if u := uploadArtifact(store, key, out, logger.With("canary", payload.Name)); u != "" {
+					w.Header().Set(flaggerv1.ArtifactURLHeader, u)
+				}
+			}
+
+			// the blocking tasks share the slots of the queued tasks,
+			// the caller gets a 429 right away when the concurrency limit is reached
+			canary := fmt.Sprintf("%s.%s", payload.Name, payload.Namespace)
+			acquire := func() bool {
+				if taskRunner.Acquire(canary) {
+					return true
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte("the load tester concurrency limit is reached"))
+				return false
+			}
+
 			// run bash command (blocking task)
 			if typ == TaskTypeBash {
 				logger.With("canary", payload.Name).Infof("bash command %s", payload.Metadata["cmd"])
@@ -271,15 +337,21 @@ func HandleNewTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface) fu
 					command:      payload.Metadata["cmd"],
 					logCmdOutput: true,
 					TaskBase: TaskBase{
-						canary: fmt.Sprintf("%s.%s", payload.Name, payload.Namespace),
+						canary: canary,
 						logger: logger,
 					},
 				}
 
+				if !acquire() {
+					return
+				}
+				defer taskRunner.Release(canary)
+
 				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
 				defer cancel()
 
 				result, err := bashTask.Run(ctx)
+				saveOutput(bashTask.Hash(), result.out)
 				if !result.ok {
 					w.WriteHeader(http.StatusInternalServerError)
 					w.Write([]byte(err.Error()))
@@ -299,15 +371,21 @@ func HandleNewTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface) fu
 					command:      payload.Metadata["cmd"],
 					logCmdOutput: true,
 					TaskBase: TaskBase{
-						canary: fmt.Sprintf("%s.%s", payload.Name, payload.Namespace),
+						canary: canary,
 						logger: logger,
 					},
 				}
 
+				if !acquire() {
+					return
+				}
+				defer taskRunner.Release(canary)
+
 				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
 				defer cancel()
 
 				result, err := helm.Run(ctx)
+				saveOutput(helm.Hash(), result.out)
 				if !result.ok {
 					w.WriteHeader(http.StatusInternalServerError)
 					w.Write([]byte(err.Error()))
@@ -327,15 +405,21 @@ func HandleNewTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface) fu
 					command:      payload.Metadata["cmd"],
 					logCmdOutput: true,
 					TaskBase: TaskBase{
-						canary: fmt.Sprintf("%s.%s", payload.Name, payload.Namespace),
+						canary: canary,
 						logger: logger,
 					},
 				}
 
+				if !acquire() {
+					return
+				}
+				defer taskRunner.Release(canary)
+
 				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
 				defer cancel()
 
 				result, err := helm.Run(ctx)
+				saveOutput(helm.Hash(), result.out)
 				if !result.ok {
 					w.WriteHeader(http.StatusInternalServerError)
 					w.Write([]byte(err.Error()))
@@ -351,7 +435,7 @@ func HandleNewTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface) fu
 
 			// run concord job (blocking task)
 			if typ == TaskTypeConcord {
-				concord, err := NewConcordTask(payload.Metadata, fmt.Sprintf("%s.%s", payload.Name, payload.Namespace), logger)
+				concord, err := NewConcordTask(payload.Metadata, canary, logger)
 
 				if err != nil {
 					logger.With("canary", payload.Name).Errorf("concord task init error: %s", err)
@@ -360,10 +444,16 @@ func HandleNewTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface) fu
 					return
 				}
 
+				if !acquire() {
+					return
+				}
+				defer taskRunner.Release(canary)
+
 				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
 				defer cancel()
 
 				result, err := concord.Run(ctx)
+				saveOutput(concord.Hash(), result.out)
 				if !result.ok {
 					if err != nil {
 						logger.With("canary", payload.Name).Errorf("concord task error: %s", err)
@@ -380,20 +470,100 @@ func HandleNewTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface) fu
 				return
 			}
 
+			// run locust (blocking task)
+			if typ == TaskTypeLocust {
+				locust, err := NewLocustTask(payload.Metadata, canary, logger)
+				if err != nil {
+					logger.With("canary", payload.Name).Errorf("locust task init error: %s", err)
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				if !acquire() {
+					return
+				}
+				defer taskRunner.Release(canary)
+
+				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
+				defer cancel()
+
+				result, err := locust.Run(ctx)
+				saveOutput(locust.Hash(), result.out)
+				if !result.ok {
+					logger.With("canary", payload.Name).Errorf("locust task error: %s", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				if rtnCmdOutput {
+					w.Write(result.out)
+				}
+				return
+			}
+
+			// run vegeta (blocking task)
+			if typ == TaskTypeVegeta {
+				vegeta, err := NewVegetaTask(payload.Metadata, canary, logger)
+				if err != nil {
+					logger.With("canary", payload.Name).Errorf("vegeta task init error: %s", err)
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				if !acquire() {
+					return
+				}
+				defer taskRunner.Release(canary)
+
+				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
+				defer cancel()
+
+				result, err := vegeta.Run(ctx)
+				saveOutput(vegeta.Hash(), result.out)
+				if !result.ok {
+					logger.With("canary", payload.Name).Errorf("vegeta task error: %s", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				if rtnCmdOutput {
+					w.Write(result.out)
+				}
+				return
+			}
+
 			taskFactory, ok := GetTaskFactory(typ)
 			if !ok {
 				w.WriteHeader(http.StatusBadRequest)
 				w.Write([]byte(fmt.Sprintf("unknown task type %s", typ)))
 				return
 			}
-			canary := fmt.Sprintf("%s.%s", payload.Name, payload.Namespace)
 			task, err := taskFactory(metadata, canary, logger)
 			if err != nil {
 				w.WriteHeader(http.StatusBadRequest)
 				w.Write([]byte(err.Error()))
 				return
 			}
-			taskRunner.Add(task)
+			key := artifactKey(payload.Name, payload.Namespace, payload.RunID, task.Hash())
+			if store != nil {
+				task = &artifactTask{Task: task, store: store, key: key, logger: logger}
+			}
+			if err := taskRunner.Add(task); err != nil {
+				logger.With("canary", payload.Name).Warnf("task %s rejected: %v", task, err)
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(err.Error()))
+				return
+			}
+			// the output of the background tasks is uploaded once they are done
+			if store != nil {
+				w.Header().Set(flaggerv1.ArtifactURLHeader, store.URL(key))
+			}
 		} else {
 			w.WriteHeader(http.StatusBadRequest)
 			w.Write([]byte("metadata not found in payload"))
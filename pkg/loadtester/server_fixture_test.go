@@ -43,9 +43,18 @@ func newServerFixture() serverFixture {
 }
 
 type MockTaskRunner struct {
+	artifacts ArtifactStore
 }
 
-func (m *MockTaskRunner) Add(task Task) {
+func (m *MockTaskRunner) Add(task Task) error {
+	return nil
+}
+
+func (m *MockTaskRunner) Acquire(canary string) bool {
+	return true
+}
+
+func (m *MockTaskRunner) Release(canary string) {
 
 }
 
@@ -60,3 +69,7 @@ func (m *MockTaskRunner) Start(interval time.Duration, stopCh <-chan struct{}) {
 func (m *MockTaskRunner) Timeout() time.Duration {
 	return time.Hour
 }
+
+func (m *MockTaskRunner) Artifacts() ArtifactStore {
+	return m.artifacts
+}
@@ -18,14 +18,19 @@ package loadtester
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestServer_HandleHealthz(t *testing.T) {
@@ -84,8 +89,63 @@ func TestServer_HandleNewBashTaskCmdExitNonZero(t *testing.T) {
 	assert.Equal(t, "command false failed: : exit status 1", resp.Body.String())
 }
 
+func TestServer_HandleNewBashTaskConcurrencyLimit(t *testing.T) {
+	mocks := newServerFixture()
+	tr := NewTaskRunner(mocks.logger, time.Minute)
+	tr.SetLimits(Limits{MaxPerNamespace: 1})
+	require.True(t, tr.Acquire("podinfo.test"))
+
+	payload := &flaggerv1.CanaryWebhookPayload{
+		Name:      "podinfo",
+		Namespace: "test",
+		Metadata: map[string]string{
+			"type": TaskTypeBash,
+			"cmd":  "echo ok",
+		},
+	}
+	HandleNewTask(mocks.logger, tr)(mocks.resp, newJsonRequest("POST", "/", payload))
+	assert.Equal(t, http.StatusTooManyRequests, mocks.resp.Code)
+
+	// the slot is released once the task is done
+	tr.Release("podinfo.test")
+	resp := httptest.NewRecorder()
+	HandleNewTask(mocks.logger, tr)(resp, newJsonRequest("POST", "/", payload))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, tr.Acquire("podinfo.test"))
+}
+
 func newJsonRequest(method string, url string, v interface{}) *http.Request {
 	payload, _ := json.Marshal(v)
 	req, _ := http.NewRequest(method, url, bytes.NewReader(payload))
 	return req
 }
+
+func TestGateStorage_Approver(t *testing.T) {
+	gate := NewGateStorage("in-memory")
+	payload := &flaggerv1.CanaryWebhookPayload{
+		Name:      "podinfo",
+		Namespace: "test",
+		Metadata:  map[string]string{"approver": "bob"},
+	}
+	req := newJsonRequest("POST", "/gate/open", payload)
+	assert.Equal(t, "unverified: bob", gateApprover(req, payload))
+	req.Header.Set("X-Forwarded-User", "alice")
+	assert.Equal(t, "unverified: alice", gateApprover(req, payload))
+
+	// the identity comes from the verified client certificate
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "ci"}}}},
+	}
+	assert.Equal(t, "ci (unverified: alice)", gateApprover(req, payload))
+	req.Header.Del("X-Forwarded-User")
+	payload.Metadata = nil
+	assert.Equal(t, "ci", gateApprover(req, payload))
+
+	gate.openBy("podinfo.test", "ci")
+	assert.True(t, gate.isOpen("podinfo.test"))
+	assert.Equal(t, "ci", gate.approver("podinfo.test"))
+
+	gate.close("podinfo.test")
+	assert.False(t, gate.isOpen("podinfo.test"))
+	assert.Empty(t, gate.approver("podinfo.test"))
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TaskTypeVegeta represents the vegeta type as string
+const TaskTypeVegeta = "vegeta"
+
+// default vegeta values
+const defaultVegetaRate = "50/1s"
+const defaultVegetaDuration = "1m"
+
+// VegetaTask attacks an URL at a constant rate and fails when the p99 latency
+// or the success ratio of the report breach the SLA
+type VegetaTask struct {
+	TaskBase
+	URL             string
+	Method          string
+	Rate            string
+	Duration        string
+	MaxP99          time.Duration
+	MinSuccessRatio float64
+	logCmdOutput    bool
+	binary          string
+}
+
+// vegetaReport holds the fields of the JSON report checked against the SLA
+type vegetaReport struct {
+	Requests  int     `json:"requests"`
+	Success   float64 `json:"success"`
+	Latencies struct {
+		P99 time.Duration `json:"99th"`
+	} `json:"latencies"`
+}
+
+// NewVegetaTask instantiates a new Vegeta Task
+func NewVegetaTask(metadata map[string]string, canary string, logger *zap.SugaredLogger) (*VegetaTask, error) {
+	if metadata["url"] == "" {
+		return nil, errors.New("`url` is required with type vegeta")
+	}
+
+	task := &VegetaTask{
+		TaskBase:        TaskBase{canary: canary, logger: logger},
+		URL:             metadata["url"],
+		Method:          "GET",
+		Rate:            defaultVegetaRate,
+		Duration:        defaultVegetaDuration,
+		MinSuccessRatio: 1,
+		binary:          "vegeta",
+	}
+
+	var err error
+	if v, ok := metadata["method"]; ok {
+		task.Method = strings.ToUpper(v)
+	}
+	if v, ok := metadata["rate"]; ok {
+		freq := strings.SplitN(v, "/", 2)[0]
+		if n, err := strconv.Atoi(freq); err != nil || n < 1 {
+			return nil, errors.New("`rate` must be a positive number of requests per time unit, e.g. 50/1s")
+		}
+		task.Rate = v
+	}
+	if v, ok := metadata["duration"]; ok {
+		if _, err := time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("unable to parse `duration` %s: %w", v, err)
+		}
+		task.Duration = v
+	}
+	if v, ok := metadata["maxP99"]; ok {
+		if task.MaxP99, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("unable to parse `maxP99` %s: %w", v, err)
+		}
+	}
+	if v, ok := metadata["minSuccessRatio"]; ok {
+		if task.MinSuccessRatio, err = strconv.ParseFloat(v, 64); err != nil || task.MinSuccessRatio < 0 || task.MinSuccessRatio > 1 {
+			return nil, errors.New("`minSuccessRatio` must be a number between 0 and 1")
+		}
+	}
+	task.logCmdOutput, _ = strconv.ParseBool(metadata["logCmdOutput"])
+
+	return task, nil
+}
+
+func (task *VegetaTask) Hash() string {
+	return hash(task.canary + task.Method + task.URL)
+}
+
+func (task *VegetaTask) String() string {
+	return fmt.Sprintf("%s %s", task.Method, task.URL)
+}
+
+// Run attacks the URL for the duration and checks the report against the SLA
+func (task *VegetaTask) Run(ctx context.Context) (*TaskRunResult, error) {
+	dir, err := ioutil.TempDir("", "vegeta")
+	if err != nil {
+		return &TaskRunResult{false, nil}, fmt.Errorf("creating the results dir failed: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	results := filepath.Join(dir, "results.bin")
+	attack := exec.CommandContext(ctx, task.binary, "attack",
+		"-rate", task.Rate,
+		"-duration", task.Duration,
+		"-output", results)
+	attack.Stdin = strings.NewReader(fmt.Sprintf("%s %s\n", task.Method, task.URL))
+	if out, err := attack.CombinedOutput(); err != nil {
+		task.logger.With("canary", task.canary).Errorf("vegeta attack failed %s %v %s", task.URL, err, out)
+		return &TaskRunResult{false, out}, fmt.Errorf("vegeta attack failed: %w %s", err, out)
+	}
+
+	out, err := exec.CommandContext(ctx, task.binary, "report", "-type", "json", results).Output()
+	if task.logCmdOutput {
+		fmt.Printf("%s\n", out)
+	}
+	if err != nil {
+		return &TaskRunResult{false, out}, fmt.Errorf("vegeta report failed: %w", err)
+	}
+
+	var report vegetaReport
+	if err := json.Unmarshal(out, &report); err != nil {
+		return &TaskRunResult{false, out}, fmt.Errorf("parsing the vegeta report failed: %w", err)
+	}
+	if err := task.checkSLA(report); err != nil {
+		return &TaskRunResult{false, out}, err
+	}
+
+	task.logger.With("canary", task.canary).Infof("vegeta finished %s success ratio %.4f p99 %s (%d requests)",
+		task.URL, report.Success, report.Latencies.P99, report.Requests)
+	return &TaskRunResult{true, out}, nil
+}
+
+// checkSLA returns an error when the report breaches the p99 latency or the success ratio
+func (task *VegetaTask) checkSLA(report vegetaReport) error {
+	if report.Requests == 0 {
+		return fmt.Errorf("vegeta didn't send any request to %s", task.URL)
+	}
+	if report.Success < task.MinSuccessRatio {
+		return fmt.Errorf("vegeta success ratio %.4f (%d requests) is lower than %v",
+			report.Success, report.Requests, task.MinSuccessRatio)
+	}
+	if task.MaxP99 > 0 && report.Latencies.P99 > task.MaxP99 {
+		return fmt.Errorf("vegeta p99 latency %s is greater than %s", report.Latencies.P99, task.MaxP99)
+	}
+	return nil
+}
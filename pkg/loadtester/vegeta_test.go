@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// p99 of 120ms and 99.5% of successful requests
+const testVegetaReport = `{"latencies":{"total":1200000000,"mean":40000000,"50th":30000000,"90th":90000000,"95th":100000000,"99th":120000000,"max":150000000,"min":10000000},"requests":3000,"rate":50,"success":0.995,"status_codes":{"200":2985,"500":15},"errors":[]}`
+
+// fakeVegeta writes a script that prints the report on vegeta report
+func fakeVegeta(t *testing.T, report string) string {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "report.json"), []byte(report), 0644))
+	script := filepath.Join(dir, "vegeta")
+	require.NoError(t, ioutil.WriteFile(script, []byte(`#!/bin/sh
+if [ "$1" = "report" ]; then cat `+filepath.Join(dir, "report.json")+`; fi
+`), 0755))
+	return script
+}
+
+func TestNewVegetaTask(t *testing.T) {
+	task, err := NewVegetaTask(map[string]string{
+		"url":      "http://podinfo-canary.test:9898/",
+		"method":   "post",
+		"rate":     "100/1s",
+		"duration": "2m",
+		"maxP99":   "500ms",
+	}, "podinfo.test", zap.NewExample().Sugar())
+	require.NoError(t, err)
+	assert.Equal(t, "POST", task.Method)
+	assert.Equal(t, "100/1s", task.Rate)
+	assert.Equal(t, 500*time.Millisecond, task.MaxP99)
+	assert.Equal(t, float64(1), task.MinSuccessRatio)
+
+	_, err = NewVegetaTask(map[string]string{}, "podinfo.test", zap.NewExample().Sugar())
+	assert.Error(t, err)
+	_, err = NewVegetaTask(map[string]string{"url": "http://podinfo", "rate": "fast"}, "podinfo.test", zap.NewExample().Sugar())
+	assert.Error(t, err)
+}
+
+func TestVegetaTask_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		ok       bool
+	}{
+		{"within SLA", map[string]string{"maxP99": "200ms", "minSuccessRatio": "0.99"}, true},
+		{"p99 breached", map[string]string{"maxP99": "100ms", "minSuccessRatio": "0.99"}, false},
+		{"success ratio breached", map[string]string{"maxP99": "200ms"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.metadata["url"] = "http://podinfo-canary.test:9898/"
+			task, err := NewVegetaTask(tt.metadata, "podinfo.test", zap.NewExample().Sugar())
+			require.NoError(t, err)
+			task.binary = fakeVegeta(t, testVegetaReport)
+
+			result, err := task.Run(context.TODO())
+			assert.Equal(t, tt.ok, result.ok)
+			assert.Equal(t, tt.ok, err == nil, err)
+		})
+	}
+}
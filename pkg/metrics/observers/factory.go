@@ -25,6 +25,9 @@ import (
 
 type Factory struct {
 	Client providers.Interface
+
+	// Istio customizes the builtin Istio queries
+	Istio IstioOptions
 }
 
 func NewFactory(metricsServer string) (*Factory, error) {
@@ -54,7 +57,8 @@ func (factory Factory) Observer(provider string) Interface {
 		}
 	case provider == flaggerv1.IstioProvider:
 		return &IstioObserver{
-			client: factory.Client,
+			client:  factory.Client,
+			options: factory.Istio,
 		}
 	case provider == flaggerv1.ContourProvider:
 		return &ContourObserver{
@@ -82,7 +86,8 @@ func (factory Factory) Observer(provider string) Interface {
 		}
 	default:
 		return &IstioObserver{
-			client: factory.Client,
+			client:  factory.Client,
+			options: factory.Istio,
 		}
 	}
 }
@@ -18,6 +18,9 @@ package observers
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
 	"time"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -28,10 +31,10 @@ var istioQueries = map[string]string{
 	"request-success-rate": `
 	sum(
 		rate(
-			istio_requests_total{
-				reporter="destination",
+			{{ requests_metric }}{
+				reporter="{{ reporter }}",
 				destination_workload_namespace="{{ namespace }}",
-				destination_workload=~"{{ target }}",
+				destination_workload=~"{{ target }}"{{ label_matchers }},
 				response_code!~"5.*"
 			}[{{ interval }}]
 		)
@@ -39,10 +42,10 @@ var istioQueries = map[string]string{
 	/ 
 	sum(
 		rate(
-			istio_requests_total{
-				reporter="destination",
+			{{ requests_metric }}{
+				reporter="{{ reporter }}",
 				destination_workload_namespace="{{ namespace }}",
-				destination_workload=~"{{ target }}"
+				destination_workload=~"{{ target }}"{{ label_matchers }}
 			}[{{ interval }}]
 		)
 	) 
@@ -52,22 +55,85 @@ var istioQueries = map[string]string{
 		0.99,
 		sum(
 			rate(
-				istio_request_duration_milliseconds_bucket{
-					reporter="destination",
+				{{ request_duration_metric }}_bucket{
+					reporter="{{ reporter }}",
 					destination_workload_namespace="{{ namespace }}",
-					destination_workload=~"{{ target }}"
+					destination_workload=~"{{ target }}"{{ label_matchers }}
 				}[{{ interval }}]
 			)
 		) by (le)
 	)`,
 }
 
+// labelMatcherRegexp matches the PromQL label matchers, e.g. source_cluster="west" or mesh_id=~"mesh.*"
+var labelMatcherRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*\s*(=|!=|=~|!~)\s*".*"$`)
+
+// metricNameRegexp matches the Prometheus metric names
+var metricNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// IstioOptions customize the builtin Istio queries for the telemetry setups
+// that differ from the Istio defaults
+type IstioOptions struct {
+	// Reporter is the reporter label value, defaults to destination
+	Reporter string
+
+	// LabelMatchers are added to the label matchers of the queries
+	LabelMatchers []string
+
+	// RequestsMetric is the name of the requests counter, defaults to istio_requests_total
+	RequestsMetric string
+
+	// RequestDurationMetric is the name of the request duration histogram
+	// without the _bucket suffix, defaults to istio_request_duration_milliseconds
+	RequestDurationMetric string
+}
+
+// Validate returns an error if a label matcher or a metric name is invalid
+func (o IstioOptions) Validate() error {
+	for _, m := range o.LabelMatchers {
+		if !labelMatcherRegexp.MatchString(strings.TrimSpace(m)) {
+			return fmt.Errorf("label matcher %s is invalid", m)
+		}
+	}
+	for _, name := range []string{o.RequestsMetric, o.RequestDurationMetric} {
+		if name != "" && !metricNameRegexp.MatchString(name) {
+			return fmt.Errorf("metric name %s is invalid", name)
+		}
+	}
+	return nil
+}
+
+func (o IstioOptions) templateFunctions(m flaggerv1.MetricTemplateModel) template.FuncMap {
+	funcs := m.TemplateFunctions()
+	funcs["reporter"] = func() string { return defaultString(o.Reporter, "destination") }
+	funcs["requests_metric"] = func() string { return defaultString(o.RequestsMetric, "istio_requests_total") }
+	funcs["request_duration_metric"] = func() string {
+		return defaultString(o.RequestDurationMetric, "istio_request_duration_milliseconds")
+	}
+	funcs["label_matchers"] = func() string {
+		var matchers string
+		for _, m := range o.LabelMatchers {
+			matchers += ", " + strings.TrimSpace(m)
+		}
+		return matchers
+	}
+	return funcs
+}
+
+func defaultString(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
 type IstioObserver struct {
-	client providers.Interface
+	client  providers.Interface
+	options IstioOptions
 }
 
 func (ob *IstioObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
-	query, err := RenderQuery(istioQueries["request-success-rate"], model)
+	query, err := renderQuery(istioQueries["request-success-rate"], ob.options.templateFunctions(model))
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
@@ -81,7 +147,7 @@ func (ob *IstioObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateMod
 }
 
 func (ob *IstioObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
-	query, err := RenderQuery(istioQueries["request-duration"], model)
+	query, err := renderQuery(istioQueries["request-duration"], ob.options.templateFunctions(model))
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
@@ -98,3 +98,47 @@ func TestIstioObserver_GetRequestDuration(t *testing.T) {
 
 	assert.Equal(t, 100*time.Millisecond, val)
 }
+
+func TestIstioObserver_Options(t *testing.T) {
+	expected := ` sum( rate( istio_requests_total_1_18{ reporter="source", destination_workload_namespace="default", destination_workload=~"podinfo", source_cluster="west", mesh_id=~"mesh.*", response_code!~"5.*" }[1m] ) ) / sum( rate( istio_requests_total_1_18{ reporter="source", destination_workload_namespace="default", destination_workload=~"podinfo", source_cluster="west", mesh_id=~"mesh.*" }[1m] ) ) * 100`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		promql := r.URL.Query()["query"][0]
+		assert.Equal(t, expected, promql)
+
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	client, err := providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: nil,
+	}, nil)
+	require.NoError(t, err)
+
+	factory := Factory{
+		Client: client,
+		Istio: IstioOptions{
+			Reporter:       "source",
+			LabelMatchers:  []string{`source_cluster="west"`, ` mesh_id=~"mesh.*"`},
+			RequestsMetric: "istio_requests_total_1_18",
+		},
+	}
+	require.NoError(t, factory.Istio.Validate())
+	observer := factory.Observer(flaggerv1.IstioProvider)
+
+	val, err := observer.GetRequestSuccessRate(flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: "default",
+		Target:    "podinfo",
+		Service:   "podinfo",
+		Interval:  "1m",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), val)
+
+	assert.Error(t, IstioOptions{LabelMatchers: []string{"source_cluster"}}.Validate())
+	assert.Error(t, IstioOptions{RequestDurationMetric: "istio-duration"}.Validate())
+}
@@ -26,7 +26,11 @@ import (
 )
 
 func RenderQuery(queryTemplate string, model flaggerv1.MetricTemplateModel) (string, error) {
-	t, err := template.New("tmpl").Funcs(model.TemplateFunctions()).Parse(queryTemplate)
+	return renderQuery(queryTemplate, model.TemplateFunctions())
+}
+
+func renderQuery(queryTemplate string, funcs template.FuncMap) (string, error) {
+	t, err := template.New("tmpl").Funcs(funcs).Parse(queryTemplate)
 	if err != nil {
 		return "", fmt.Errorf("template parsing failed: %w", err)
 	}
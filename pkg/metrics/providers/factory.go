@@ -36,6 +36,10 @@ func (factory Factory) Provider(
 		return NewCloudWatchProvider(metricInterval, provider)
 	case "newrelic":
 		return NewNewRelicProvider(metricInterval, provider, credentials)
+	case "opencost":
+		return NewOpenCostProvider(metricInterval, provider)
+	case "plugin":
+		return NewPluginProvider(metricInterval, provider, credentials)
 	default:
 		return NewPrometheusProvider(provider, credentials)
 	}
@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	opencostDefaultAddress = "http://opencost.opencost:9003"
+	opencostAllocationPath = "/allocation/compute"
+	opencostHealthPath     = "/healthz"
+)
+
+// OpenCostProvider executes the cost allocation queries of OpenCost or Kubecost
+type OpenCostProvider struct {
+	timeout time.Duration
+	address string
+	window  string
+	client  *http.Client
+}
+
+type openCostResponse struct {
+	Code    int                             `json:"code"`
+	Message string                          `json:"message"`
+	Data    []map[string]openCostAllocation `json:"data"`
+}
+
+type openCostAllocation struct {
+	Properties struct {
+		Namespace      string `json:"namespace"`
+		Controller     string `json:"controller"`
+		ControllerKind string `json:"controllerKind"`
+	} `json:"properties"`
+	Minutes   float64 `json:"minutes"`
+	TotalCost float64 `json:"totalCost"`
+}
+
+// NewOpenCostProvider takes a metric interval and a provider spec, and returns
+// a client ready to query the allocation API of OpenCost or of the Kubecost cost model
+func NewOpenCostProvider(metricInterval string, provider flaggerv1.MetricTemplateProvider) (*OpenCostProvider, error) {
+	address := provider.Address
+	if address == "" {
+		address = opencostDefaultAddress
+	}
+
+	if _, err := time.ParseDuration(metricInterval); err != nil {
+		return nil, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+
+	return &OpenCostProvider{
+		timeout: 5 * time.Second,
+		address: strings.TrimSuffix(address, "/"),
+		window:  metricInterval,
+		client:  http.DefaultClient,
+	}, nil
+}
+
+// RunQuery returns the hourly cost per pod of a workload over the metric interval,
+// the query is the workload in the namespace/kind/name format, e.g. default/deployment/podinfo-primary
+func (p *OpenCostProvider) RunQuery(query string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(query), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return 0, fmt.Errorf("invalid query %q, the workload must be in the namespace/kind/name format", query)
+	}
+	return p.GetPodHourlyCost(parts[0], parts[1], parts[2])
+}
+
+// GetPodHourlyCost returns the total cost of the pods of a workload divided by their running hours,
+// the result doesn't depend on the number of replicas so it can be compared between the canary and the primary
+func (p *OpenCostProvider) GetPodHourlyCost(namespace, kind, name string) (float64, error) {
+	req, err := http.NewRequest("GET", p.address+opencostAllocationPath, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("window", p.window)
+	q.Add("aggregate", "pod")
+	q.Add("accumulate", "true")
+	q.Add("filter", fmt.Sprintf(`namespace:"%s"`, namespace))
+	req.URL.RawQuery = q.Encode()
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error response: %s", string(b))
+	}
+
+	var res openCostResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	if res.Code != 0 && res.Code != http.StatusOK {
+		return 0, fmt.Errorf("error response: %d %s", res.Code, res.Message)
+	}
+
+	var cost, minutes float64
+	for _, set := range res.Data {
+		for _, allocation := range set {
+			if allocation.Properties.Namespace == namespace &&
+				strings.EqualFold(allocation.Properties.ControllerKind, kind) &&
+				allocation.Properties.Controller == name {
+				cost += allocation.TotalCost
+				minutes += allocation.Minutes
+			}
+		}
+	}
+
+	if minutes == 0 {
+		return 0, fmt.Errorf("no allocations found for %s %s.%s: %w", kind, name, namespace, ErrNoValuesFound)
+	}
+
+	return cost / (minutes / 60), nil
+}
+
+// IsOnline calls the health endpoint of the cost model
+// and returns an error if the request is rejected
+func (p *OpenCostProvider) IsOnline() (bool, error) {
+	req, err := http.NewRequest("GET", p.address+opencostHealthPath, nil)
+	if err != nil {
+		return false, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return false, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return true, nil
+}
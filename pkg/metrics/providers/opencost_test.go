@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const openCostAllocations = `{"code":200,"data":[{
+"podinfo-primary-a":{"properties":{"namespace":"default","controller":"podinfo-primary","controllerKind":"deployment"},"minutes":60,"totalCost":0.2},
+"podinfo-primary-b":{"properties":{"namespace":"default","controller":"podinfo-primary","controllerKind":"deployment"},"minutes":30,"totalCost":0.1},
+"podinfo-a":{"properties":{"namespace":"default","controller":"podinfo","controllerKind":"deployment"},"minutes":60,"totalCost":0.4},
+"other-a":{"properties":{"namespace":"test","controller":"podinfo","controllerKind":"deployment"},"minutes":60,"totalCost":1}
+}]}`
+
+func TestOpenCostProvider_RunQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, opencostAllocationPath, r.URL.Path)
+		assert.Equal(t, "5m", r.URL.Query().Get("window"))
+		assert.Equal(t, "pod", r.URL.Query().Get("aggregate"))
+		assert.Equal(t, `namespace:"default"`, r.URL.Query().Get("filter"))
+		w.Write([]byte(openCostAllocations))
+	}))
+	defer ts.Close()
+
+	oc, err := NewOpenCostProvider("5m", flaggerv1.MetricTemplateProvider{Address: ts.URL})
+	require.NoError(t, err)
+
+	t.Run("ok", func(t *testing.T) {
+		f, err := oc.RunQuery("default/deployment/podinfo-primary")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.2, f, 0.0001)
+
+		f, err = oc.RunQuery("default/Deployment/podinfo")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.4, f, 0.0001)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		_, err := oc.RunQuery("default/daemonset/podinfo")
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := oc.RunQuery("podinfo")
+		require.Error(t, err)
+	})
+}
+
+func TestOpenCostProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusServiceUnavailable, errExpected: true},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, opencostHealthPath, r.URL.Path)
+			w.WriteHeader(c.code)
+		}))
+
+		oc, err := NewOpenCostProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL})
+		require.NoError(t, err)
+
+		_, err = oc.IsOnline()
+		if c.errExpected {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+		ts.Close()
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	pluginQueryPath  = "/query"
+	pluginHealthPath = "/healthz"
+
+	pluginTokenSecretKey = "token"
+)
+
+// PluginProvider executes the queries of an external metrics service
+// that implements the plugin contract
+type PluginProvider struct {
+	timeout time.Duration
+	address string
+	window  string
+	token   string
+	client  *http.Client
+}
+
+// PluginQuery is the request body sent by Flagger to the plugin query endpoint
+type PluginQuery struct {
+	// Query is the rendered query of the metric template
+	Query string `json:"query"`
+	// Window is the metric interval, e.g. 1m
+	Window string `json:"window"`
+}
+
+// PluginResult is the response body expected from the plugin query endpoint,
+// a null value means that the backend has no data points for the query
+type PluginResult struct {
+	Value *float64 `json:"value"`
+}
+
+// NewPluginProvider takes a metric interval, a provider spec and the credentials map, and
+// returns a client ready to query the plugin service
+func NewPluginProvider(metricInterval string, provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*PluginProvider, error) {
+	if provider.Address == "" {
+		return nil, fmt.Errorf("the plugin provider requires an address")
+	}
+
+	if _, err := time.ParseDuration(metricInterval); err != nil {
+		return nil, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+
+	p := PluginProvider{
+		timeout: 5 * time.Second,
+		address: strings.TrimSuffix(provider.Address, "/"),
+		window:  metricInterval,
+		client:  http.DefaultClient,
+	}
+
+	if b, ok := credentials[pluginTokenSecretKey]; ok {
+		p.token = string(b)
+	}
+
+	return &p, nil
+}
+
+// RunQuery posts the query and the metric interval to the plugin service
+// and returns the value of the response
+func (p *PluginProvider) RunQuery(query string) (float64, error) {
+	body, err := json.Marshal(PluginQuery{Query: query, Window: p.window})
+	if err != nil {
+		return 0, fmt.Errorf("error marshaling query: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", p.address+pluginQueryPath, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	b, err := p.do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	var res PluginResult
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	if res.Value == nil {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	return *res.Value, nil
+}
+
+// IsOnline calls the health endpoint of the plugin service
+// and returns an error if the request is rejected
+func (p *PluginProvider) IsOnline() (bool, error) {
+	req, err := http.NewRequest("GET", p.address+pluginHealthPath, nil)
+	if err != nil {
+		return false, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	if _, err := p.do(req); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p *PluginProvider) do(req *http.Request) ([]byte, error) {
+	if p.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.token))
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
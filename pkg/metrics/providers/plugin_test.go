@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewPluginProvider(t *testing.T) {
+	p, err := NewPluginProvider("1m",
+		flaggerv1.MetricTemplateProvider{Address: "http://metrics-plugin.flagger:8080/"},
+		map[string][]byte{"token": []byte("secret")},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "http://metrics-plugin.flagger:8080", p.address)
+	assert.Equal(t, "1m", p.window)
+	assert.Equal(t, "secret", p.token)
+
+	_, err = NewPluginProvider("1m", flaggerv1.MetricTemplateProvider{}, nil)
+	require.Error(t, err)
+}
+
+func TestPluginProvider_RunQuery(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, pluginQueryPath, r.URL.Path)
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+			var q PluginQuery
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&q))
+			assert.Equal(t, PluginQuery{Query: "errors{app=podinfo}", Window: "2m"}, q)
+			w.Write([]byte(`{"value": 1.5}`))
+		}))
+		defer ts.Close()
+
+		p, err := NewPluginProvider("2m", flaggerv1.MetricTemplateProvider{Address: ts.URL},
+			map[string][]byte{"token": []byte("secret")})
+		require.NoError(t, err)
+
+		f, err := p.RunQuery("errors{app=podinfo}")
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, f)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"value": null}`))
+		}))
+		defer ts.Close()
+
+		p, err := NewPluginProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL}, nil)
+		require.NoError(t, err)
+		_, err = p.RunQuery("errors")
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`unknown metric`))
+		}))
+		defer ts.Close()
+
+		p, err := NewPluginProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL}, nil)
+		require.NoError(t, err)
+		_, err = p.RunQuery("errors")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown metric")
+	})
+}
+
+func TestPluginProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusServiceUnavailable, errExpected: true},
+	} {
+		t.Run(fmt.Sprintf("%d", c.code), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, pluginHealthPath, r.URL.Path)
+				w.WriteHeader(c.code)
+			}))
+			defer ts.Close()
+
+			p, err := NewPluginProvider("1m", flaggerv1.MetricTemplateProvider{Address: ts.URL}, nil)
+			require.NoError(t, err)
+
+			_, err = p.IsOnline()
+			if c.errExpected {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package metrics
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -31,6 +32,19 @@ type Recorder struct {
 	total    *prometheus.GaugeVec
 	status   *prometheus.GaugeVec
 	weight   *prometheus.GaugeVec
+
+	metricQueryDuration     *prometheus.HistogramVec
+	webhookDuration         *prometheus.HistogramVec
+	routerReconcileDuration *prometheus.HistogramVec
+	phaseDuration           *prometheus.HistogramVec
+	phases                  *sync.Map
+	primarySuffix           string
+}
+
+// phaseTransition holds the last observed phase of a canary and when it was observed
+type phaseTransition struct {
+	phase flaggerv1.CanaryPhase
+	since time.Time
 }
 
 // NewRecorder creates a new recorder and registers the Prometheus metrics
@@ -67,20 +81,58 @@ func NewRecorder(controller string, register bool) Recorder {
 		Help:      "The virtual service destination weight current value",
 	}, []string{"workload", "namespace"})
 
+	metricQueryDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: controller,
+		Name:      "metric_query_duration_seconds",
+		Help:      "Seconds spent querying the metrics provider.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "metric"})
+
+	webhookDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: controller,
+		Name:      "webhook_duration_seconds",
+		Help:      "Seconds spent calling webhooks.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"name", "type"})
+
+	routerReconcileDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: controller,
+		Name:      "router_reconcile_duration_seconds",
+		Help:      "Seconds spent reconciling the mesh or ingress routing objects.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider"})
+
+	// from 10 seconds up to about 5.5 hours
+	phaseDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: controller,
+		Name:      "canary_phase_duration_seconds",
+		Help:      "Seconds spent by canaries in a phase.",
+		Buckets:   prometheus.ExponentialBuckets(10, 2, 12),
+	}, []string{"name", "namespace", "phase"})
+
 	if register {
 		prometheus.MustRegister(info)
 		prometheus.MustRegister(duration)
 		prometheus.MustRegister(total)
 		prometheus.MustRegister(status)
 		prometheus.MustRegister(weight)
+		prometheus.MustRegister(metricQueryDuration)
+		prometheus.MustRegister(webhookDuration)
+		prometheus.MustRegister(routerReconcileDuration)
+		prometheus.MustRegister(phaseDuration)
 	}
 
 	return Recorder{
-		info:     info,
-		duration: duration,
-		total:    total,
-		status:   status,
-		weight:   weight,
+		info:                    info,
+		duration:                duration,
+		total:                   total,
+		status:                  status,
+		weight:                  weight,
+		metricQueryDuration:     metricQueryDuration,
+		webhookDuration:         webhookDuration,
+		routerReconcileDuration: routerReconcileDuration,
+		phaseDuration:           phaseDuration,
+		phases:                  new(sync.Map),
 	}
 }
 
@@ -113,8 +165,58 @@ func (cr *Recorder) SetStatus(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase)
 	cr.status.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Set(float64(status))
 }
 
+// SetPrimarySuffix sets the suffix of the primary workloads of the canaries that don't set spec.primarySuffix
+func (cr *Recorder) SetPrimarySuffix(suffix string) {
+	cr.primarySuffix = suffix
+}
+
 // SetWeight sets the weight values for primary and canary destinations
 func (cr *Recorder) SetWeight(cd *flaggerv1.Canary, primary int, canary int) {
-	cr.weight.WithLabelValues(fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name), cd.Namespace).Set(float64(primary))
+	cr.weight.WithLabelValues(cd.GetPrimaryName(cd.Spec.TargetRef.Name, cr.primarySuffix), cd.Namespace).Set(float64(primary))
 	cr.weight.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Set(float64(canary))
 }
+
+// SetMetricQueryDuration sets the time spent in seconds querying a metrics provider
+func (cr *Recorder) SetMetricQueryDuration(provider string, metric string, duration time.Duration) {
+	cr.metricQueryDuration.WithLabelValues(provider, metric).Observe(duration.Seconds())
+}
+
+// SetWebhookDuration sets the time spent in seconds calling a webhook
+func (cr *Recorder) SetWebhookDuration(w flaggerv1.CanaryWebhook, duration time.Duration) {
+	cr.webhookDuration.WithLabelValues(w.Name, string(w.Type)).Observe(duration.Seconds())
+}
+
+// SetRouterReconcileDuration sets the time spent in seconds reconciling the routing objects
+func (cr *Recorder) SetRouterReconcileDuration(provider string, duration time.Duration) {
+	cr.routerReconcileDuration.WithLabelValues(provider).Observe(duration.Seconds())
+}
+
+// SetPhase tracks the canary phase and, when the phase changes,
+// sets the time spent in seconds by the canary in the previous phase
+func (cr *Recorder) SetPhase(cd *flaggerv1.Canary) {
+	cr.setPhase(cd, time.Now())
+}
+
+func (cr *Recorder) setPhase(cd *flaggerv1.Canary, now time.Time) {
+	key := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+	current := phaseTransition{phase: cd.Status.Phase, since: now}
+
+	v, loaded := cr.phases.LoadOrStore(key, current)
+	if !loaded {
+		return
+	}
+
+	last := v.(phaseTransition)
+	if last.phase == current.phase {
+		return
+	}
+
+	cr.phaseDuration.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace, string(last.phase)).
+		Observe(now.Sub(last.since).Seconds())
+	cr.phases.Store(key, current)
+}
+
+// DeleteCanary removes the phase tracked for a deleted canary
+func (cr *Recorder) DeleteCanary(cd *flaggerv1.Canary) {
+	cr.phases.Delete(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace))
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestRecorder_SetPhase(t *testing.T) {
+	cr := NewRecorder("flagger", false)
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{Name: "podinfo"},
+		},
+		Status: flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized},
+	}
+
+	now := time.Now()
+	cr.setPhase(cd, now)
+	cr.setPhase(cd, now.Add(10*time.Second))
+	assert.Equal(t, 0, testutil.CollectAndCount(cr.phaseDuration))
+
+	cd.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	cr.setPhase(cd, now.Add(30*time.Second))
+	cd.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	cr.setPhase(cd, now.Add(90*time.Second))
+	assert.Equal(t, 2, testutil.CollectAndCount(cr.phaseDuration))
+
+	h := readHistogram(t, cr.phaseDuration.WithLabelValues("podinfo", "default", string(flaggerv1.CanaryPhaseInitialized)))
+	assert.Equal(t, uint64(1), h.GetSampleCount())
+	assert.Equal(t, float64(30), h.GetSampleSum())
+
+	h = readHistogram(t, cr.phaseDuration.WithLabelValues("podinfo", "default", string(flaggerv1.CanaryPhaseProgressing)))
+	assert.Equal(t, uint64(1), h.GetSampleCount())
+	assert.Equal(t, float64(60), h.GetSampleSum())
+
+	// the phase of a deleted canary isn't tracked
+	cr.DeleteCanary(cd)
+	_, ok := cr.phases.Load("podinfo.default")
+	assert.False(t, ok)
+}
+
+func TestRecorder_SetWebhookDuration(t *testing.T) {
+	cr := NewRecorder("flagger", false)
+	w := flaggerv1.CanaryWebhook{Name: "load-test", Type: flaggerv1.RolloutHook}
+
+	cr.SetWebhookDuration(w, 2*time.Second)
+	cr.SetWebhookDuration(w, time.Second)
+
+	h := readHistogram(t, cr.webhookDuration.WithLabelValues("load-test", string(flaggerv1.RolloutHook)))
+	assert.Equal(t, uint64(2), h.GetSampleCount())
+	assert.Equal(t, float64(3), h.GetSampleSum())
+}
+
+func readHistogram(t *testing.T, o prometheus.Observer) *dto.Histogram {
+	m := &dto.Metric{}
+	require.NoError(t, o.(prometheus.Metric).Write(m))
+	return m.GetHistogram()
+}
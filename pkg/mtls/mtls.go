@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// Config holds the paths of the PEM encoded certificate, key and CA bundle,
+// the files are read on each TLS handshake so that the certificates
+// rotated by SPIRE or cert-manager are used without a restart
+type Config struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Enabled returns true when a certificate is set
+func (c Config) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+// Validate returns an error when the files can't be loaded
+func (c Config) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("both the certificate and the key files are required")
+	}
+	if c.Enabled() {
+		if _, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile); err != nil {
+			return fmt.Errorf("loading the key pair failed: %w", err)
+		}
+	}
+	if c.CAFile != "" {
+		if _, err := c.caPool(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientConfig returns the TLS settings of a client that presents the certificate,
+// the server certificate must contain one of the SANs or, when no SAN is given, match the server name
+func (c Config) ClientConfig(sans []string) *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// the server certificate is verified against the CA bundle by VerifyConnection
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return c.verify(cs.PeerCertificates, x509.ExtKeyUsageServerAuth, cs.ServerName, sans)
+		},
+	}
+	if c.Enabled() {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.loadKeyPair()
+		}
+	}
+	return config
+}
+
+// ServerConfig returns the TLS settings of a server that requires a client certificate
+// signed by the CA bundle, the client certificate must contain one of the SANs when SANs are given
+func (c Config) ServerConfig(sans []string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAnyClientCert,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return c.loadKeyPair()
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return c.verify(cs.PeerCertificates, x509.ExtKeyUsageClientAuth, "", sans)
+		},
+	}
+}
+
+func (c Config) loadKeyPair() (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading the key pair failed: %w", err)
+	}
+	return &cert, nil
+}
+
+// caPool returns the CA bundle, the system roots are used when no CA file is set
+func (c Config) caPool() (*x509.CertPool, error) {
+	if c.CAFile == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading the CA bundle failed: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in the CA bundle %s", c.CAFile)
+	}
+	return pool, nil
+}
+
+func (c Config) verify(certs []*x509.Certificate, usage x509.ExtKeyUsage, serverName string, sans []string) error {
+	if len(certs) == 0 {
+		return fmt.Errorf("no peer certificate")
+	}
+	roots, err := c.caPool()
+	if err != nil {
+		return err
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	}
+	if _, err := certs[0].Verify(opts); err != nil {
+		return fmt.Errorf("peer certificate verification failed: %w", err)
+	}
+
+	if len(sans) > 0 {
+		return VerifySANs(certs[0], sans)
+	}
+	if serverName != "" {
+		return certs[0].VerifyHostname(serverName)
+	}
+	return nil
+}
+
+// VerifySANs returns an error when the certificate contains none of the DNS names or URIs,
+// the URIs can be SPIFFE IDs, e.g. spiffe://cluster.local/ns/test/sa/flagger-loadtester
+func VerifySANs(cert *x509.Certificate, sans []string) error {
+	var names []string
+	names = append(names, cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	for _, name := range names {
+		for _, san := range sans {
+			if name == san {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("peer certificate SANs [%s] don't match any of %s",
+		strings.Join(names, ", "), strings.Join(sans, ", "))
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	writePEM(t, filepath.Join(ca.dir, "ca.crt"), "CERTIFICATE", der)
+	return ca
+}
+
+// issue writes a key pair with the SPIFFE ID and returns its config
+func (ca *testCA) issue(t *testing.T, name string, spiffeID string, usage x509.ExtKeyUsage) Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	id, err := url.Parse(spiffeID)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		URIs:         []*url.URL{id},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	config := Config{
+		CertFile: filepath.Join(ca.dir, name+".crt"),
+		KeyFile:  filepath.Join(ca.dir, name+".key"),
+		CAFile:   filepath.Join(ca.dir, "ca.crt"),
+	}
+	writePEM(t, config.CertFile, "CERTIFICATE", der)
+	writePEM(t, config.KeyFile, "EC PRIVATE KEY", keyDer)
+	return config
+}
+
+func writePEM(t *testing.T, file string, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, ioutil.WriteFile(file, data, 0600))
+}
+
+func TestConfig_MutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverID := "spiffe://cluster.local/ns/test/sa/flagger-loadtester"
+	clientID := "spiffe://cluster.local/ns/flagger-system/sa/flagger"
+	serverConfig := ca.issue(t, "server", serverID, x509.ExtKeyUsageServerAuth)
+	clientConfig := ca.issue(t, "client", clientID, x509.ExtKeyUsageClientAuth)
+	require.NoError(t, serverConfig.Validate())
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	// httptest.StartTLS would serve its own certificate to the clients without SNI
+	ts.Listener = tls.NewListener(ts.Listener, serverConfig.ServerConfig([]string{clientID}))
+	ts.Start()
+	defer ts.Close()
+	serverURL := strings.Replace(ts.URL, "http://", "https://", 1)
+
+	get := func(config Config, sans []string) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config.ClientConfig(sans)}}
+		resp, err := client.Get(serverURL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// the server SPIFFE ID matches
+	assert.NoError(t, get(clientConfig, []string{serverID}))
+
+	// the server IP is verified when no SAN is given
+	assert.NoError(t, get(clientConfig, nil))
+
+	// the server SPIFFE ID doesn't match
+	assert.Error(t, get(clientConfig, []string{"spiffe://cluster.local/ns/test/sa/other"}))
+
+	// the client doesn't present a certificate
+	assert.Error(t, get(Config{CAFile: clientConfig.CAFile}, []string{serverID}))
+
+	// the client certificate has another SPIFFE ID
+	otherConfig := ca.issue(t, "other", "spiffe://cluster.local/ns/test/sa/other", x509.ExtKeyUsageClientAuth)
+	assert.Error(t, get(otherConfig, []string{serverID}))
+
+	// the server is signed by an unknown CA
+	assert.Error(t, get(Config{CertFile: clientConfig.CertFile, KeyFile: clientConfig.KeyFile, CAFile: newTestCA(t).dir + "/ca.crt"}, nil))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.Error(t, Config{CertFile: "tls.crt"}.Validate())
+	assert.Error(t, Config{CertFile: "tls.crt", KeyFile: "tls.key"}.Validate())
+}
@@ -27,6 +27,10 @@ import (
 )
 
 func postMessage(address string, payload interface{}) error {
+	return postMessageWithHeaders(address, nil, payload)
+}
+
+func postMessageWithHeaders(address string, headers map[string]string, payload interface{}) error {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshalling notification payload failed: %w", err)
@@ -39,6 +43,9 @@ func postMessage(address string, payload interface{}) error {
 		return fmt.Errorf("http.NewRequest failed: %w", err)
 	}
 	req.Header.Set("Content-type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 
 	ctx, cancel := context.WithTimeout(req.Context(), 5*time.Second)
 	defer cancel()
@@ -50,7 +57,7 @@ func postMessage(address string, payload interface{}) error {
 
 	defer res.Body.Close()
 	statusCode := res.StatusCode
-	if statusCode != 200 {
+	if statusCode < 200 || statusCode >= 300 {
 		body, _ := ioutil.ReadAll(res.Body)
 		return fmt.Errorf("sending notification failed: %s", string(body))
 	}
@@ -24,13 +24,15 @@ type Factory struct {
 	URL      string
 	Username string
 	Channel  string
+	Token    string
 }
 
-func NewFactory(url string, username string, channel string) *Factory {
+func NewFactory(url string, username string, channel string, token string) *Factory {
 	return &Factory{
 		URL:      url,
 		Channel:  channel,
 		Username: username,
+		Token:    token,
 	}
 }
 
@@ -50,6 +52,14 @@ func (f Factory) Notifier(provider string) (Interface, error) {
 		n, err = NewRocket(f.URL, f.Username, f.Channel)
 	case "msteams":
 		n, err = NewMSTeams(f.URL)
+	case "grafana":
+		n, err = NewGrafana(f.URL, f.Token)
+	case "flux":
+		n, err = NewFlux(f.URL, f.Token)
+	case "github":
+		n, err = NewGitHub(f.URL, f.Token)
+	case "gitlab":
+		n, err = NewGitLab(f.URL, f.Token)
 	default:
 		err = fmt.Errorf("provider %s not supported", provider)
 	}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Flux holds the notification-controller events API address and token
+type Flux struct {
+	URL   string
+	Token string
+}
+
+// FluxEvent holds the event payload of the Flux notification-controller
+// https://fluxcd.io/docs/components/notification/event/
+type FluxEvent struct {
+	InvolvedObject      corev1.ObjectReference `json:"involvedObject"`
+	Severity            string                 `json:"severity"`
+	Timestamp           string                 `json:"timestamp"`
+	Message             string                 `json:"message"`
+	Reason              string                 `json:"reason"`
+	Metadata            map[string]string      `json:"metadata,omitempty"`
+	ReportingController string                 `json:"reportingController"`
+	ReportingInstance   string                 `json:"reportingInstance,omitempty"`
+}
+
+// NewFlux validates the notification-controller URL and returns a Flux object
+func NewFlux(address string, token string) (*Flux, error) {
+	_, err := url.ParseRequestURI(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Flux notification-controller URL %s", address)
+	}
+
+	return &Flux{
+		URL:   address,
+		Token: token,
+	}, nil
+}
+
+// Post a Flux event about the canary, the fields are sent as the event metadata
+func (f *Flux) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	event := FluxEvent{
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "flagger.app/v1beta1",
+			Kind:       "Canary",
+			Name:       workload,
+			Namespace:  namespace,
+		},
+		// the notification-controller only accepts the info and error severities
+		Severity:            "info",
+		Timestamp:           time.Now().UTC().Format(time.RFC3339),
+		Message:             message,
+		Reason:              "Synchronized",
+		Metadata:            map[string]string{"severity": severity},
+		ReportingController: "flagger",
+	}
+	if severity == "error" {
+		event.Severity = "error"
+	}
+
+	for _, field := range fields {
+		if field.Name == "Reason" {
+			event.Reason = field.Value
+			continue
+		}
+		key := strings.ToLower(strings.ReplaceAll(field.Name, " ", "_"))
+		event.Metadata[key] = field.Value
+	}
+
+	return f.send(event)
+}
+
+// PostObjectEvent posts an info event about a Flux object, e.g. the HelmRelease or Kustomization
+// that applies the canary target, so that the notification-controller alerts of the object receive it
+func (f *Flux) PostObjectEvent(object corev1.ObjectReference, reason string, message string, metadata map[string]string) error {
+	return f.send(FluxEvent{
+		InvolvedObject:      object,
+		Severity:            "info",
+		Timestamp:           time.Now().UTC().Format(time.RFC3339),
+		Message:             message,
+		Reason:              reason,
+		Metadata:            metadata,
+		ReportingController: "flagger",
+	})
+}
+
+func (f *Flux) send(event FluxEvent) error {
+	var headers map[string]string
+	if f.Token != "" {
+		headers = map[string]string{"Authorization": fmt.Sprintf("Bearer %s", f.Token)}
+	}
+
+	err := postMessageWithHeaders(f.URL, headers, event)
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+
+	return nil
+}
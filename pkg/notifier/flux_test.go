@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestFlux_Post(t *testing.T) {
+	fields := []Field{
+		{Name: "Reason", Value: "Synced"},
+		{Name: "Run ID", Value: "1"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload = FluxEvent{}
+		err = json.Unmarshal(b, &payload)
+		require.NoError(t, err)
+
+		require.Equal(t, "Canary", payload.InvolvedObject.Kind)
+		require.Equal(t, "podinfo", payload.InvolvedObject.Name)
+		require.Equal(t, "test", payload.InvolvedObject.Namespace)
+		require.Equal(t, "error", payload.Severity)
+		require.Equal(t, "Synced", payload.Reason)
+		require.Equal(t, "test", payload.Message)
+		require.Equal(t, "flagger", payload.ReportingController)
+		require.Equal(t, map[string]string{"severity": "error", "run_id": "1"}, payload.Metadata)
+		require.NotEmpty(t, payload.Timestamp)
+	}))
+	defer ts.Close()
+
+	flux, err := NewFlux(ts.URL, "")
+	require.NoError(t, err)
+
+	err = flux.Post("podinfo", "test", "test", fields, "error")
+	require.NoError(t, err)
+}
+
+func TestFlux_PostObjectEvent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload = FluxEvent{}
+		err = json.Unmarshal(b, &payload)
+		require.NoError(t, err)
+
+		require.Equal(t, "HelmRelease", payload.InvolvedObject.Kind)
+		require.Equal(t, "podinfo", payload.InvolvedObject.Name)
+		require.Equal(t, "info", payload.Severity)
+		require.Equal(t, "Promoted", payload.Reason)
+		require.Equal(t, map[string]string{"canary": "podinfo.test"}, payload.Metadata)
+	}))
+	defer ts.Close()
+
+	flux, err := NewFlux(ts.URL, "")
+	require.NoError(t, err)
+
+	object := corev1.ObjectReference{Kind: "HelmRelease", Name: "podinfo", Namespace: "test"}
+	err = flux.PostObjectEvent(object, "Promoted", "test", map[string]string{"canary": "podinfo.test"})
+	require.NoError(t, err)
+}
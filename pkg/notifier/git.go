@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// commit status states shared by the Git providers, each provider maps them to its own values
+const (
+	commitStatusPending = "pending"
+	commitStatusSuccess = "success"
+	commitStatusFailure = "failure"
+)
+
+// commitStatus returns the revision and the commit status of an alert,
+// the promotions are reported as success, the rollbacks and errors as failure
+// and the start of the analysis and the approval gates as pending,
+// ok is false for the alerts that don't change the result of the analysis
+func commitStatus(fields []Field, severity string) (revision string, state string, ok bool) {
+	reason := ""
+	for _, field := range fields {
+		switch field.Name {
+		case "Revision":
+			revision = field.Value
+		case "Reason":
+			reason = field.Value
+		}
+	}
+
+	switch {
+	case reason == "Promoted" || reason == "AnalysisSkipped":
+		state = commitStatusSuccess
+	case reason == "Aborted" || reason == "ManualRollback" || severity == "error":
+		state = commitStatusFailure
+	case reason == "NewRevisionDetected" || reason == "WaitingForApproval" || reason == "WaitingForWave":
+		state = commitStatusPending
+	default:
+		return revision, "", false
+	}
+	return revision, state, true
+}
+
+// parseGitRepository splits a repository URL such as https://github.com/org/repo.git
+// into the server URL and the repository path
+func parseGitRepository(address string) (*url.URL, string, error) {
+	u, err := url.ParseRequestURI(address)
+	if err != nil {
+		return nil, "", err
+	}
+	repository := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if strings.Count(repository, "/") < 1 {
+		return nil, "", fmt.Errorf("the repository path must contain the owner and the name")
+	}
+	return &url.URL{Scheme: u.Scheme, Host: u.Host}, repository, nil
+}
+
+// commitStatusContext identifies the canary in the commit statuses
+func commitStatusContext(workload string, namespace string) string {
+	return fmt.Sprintf("flagger/%s.%s", workload, namespace)
+}
+
+// truncate shortens the status description to the length accepted by the Git providers
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-3] + "..."
+}
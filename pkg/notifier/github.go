@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GitHub holds the commit statuses API address and token of a repository
+type GitHub struct {
+	URL   string
+	Token string
+}
+
+// GitHubStatus holds the commit status payload
+// https://docs.github.com/en/rest/commits/statuses
+type GitHubStatus struct {
+	State       string `json:"state"`
+	Description string `json:"description"`
+	Context     string `json:"context"`
+}
+
+// NewGitHub validates the repository URL and returns a GitHub object,
+// the GitHub Enterprise repositories are served by the /api/v3 path of their host
+func NewGitHub(address string, token string) (*GitHub, error) {
+	server, repository, err := parseGitRepository(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub repository URL %s: %w", address, err)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("the GitHub token is required")
+	}
+
+	api := fmt.Sprintf("%s/api/v3", server.String())
+	if server.Host == "github.com" {
+		api = "https://api.github.com"
+	}
+
+	return &GitHub{
+		URL:   fmt.Sprintf("%s/repos/%s", api, repository),
+		Token: token,
+	}, nil
+}
+
+// Post sets the commit status of the canary revision,
+// the alerts that don't change the result of the analysis are ignored
+func (g *GitHub) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	revision, state, ok := commitStatus(fields, severity)
+	if !ok {
+		return nil
+	}
+	if revision == "" {
+		return fmt.Errorf("the canary doesn't have a Git revision annotation")
+	}
+
+	payload := GitHubStatus{
+		State:       state,
+		Description: truncate(strings.TrimSpace(message), 140),
+		Context:     commitStatusContext(workload, namespace),
+	}
+	headers := map[string]string{
+		"Accept":        "application/vnd.github+json",
+		"Authorization": fmt.Sprintf("Bearer %s", g.Token),
+	}
+
+	err := postMessageWithHeaders(fmt.Sprintf("%s/statuses/%s", g.URL, url.PathEscape(revision)), headers, payload)
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHub_Post(t *testing.T) {
+	fields := []Field{
+		{Name: "Reason", Value: "Promoted"},
+		{Name: "Revision", Value: "7d8f6b5"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/org/podinfo/statuses/7d8f6b5", r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload = GitHubStatus{}
+		err = json.Unmarshal(b, &payload)
+		require.NoError(t, err)
+
+		require.Equal(t, "success", payload.State)
+		require.Equal(t, "flagger/podinfo.test", payload.Context)
+		require.Equal(t, "test", payload.Description)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	github, err := NewGitHub(ts.URL+"/org/podinfo.git", "token")
+	require.NoError(t, err)
+
+	err = github.Post("podinfo", "test", "test", fields, "info")
+	require.NoError(t, err)
+
+	// the alerts that don't change the result aren't posted
+	err = github.Post("podinfo", "test", "test", []Field{{Name: "Reason", Value: "Initialized"}}, "info")
+	require.NoError(t, err)
+
+	err = github.Post("podinfo", "test", "test", []Field{{Name: "Reason", Value: "Promoted"}}, "info")
+	require.Error(t, err)
+}
+
+func TestGitHub_Address(t *testing.T) {
+	github, err := NewGitHub("https://github.com/org/podinfo", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.github.com/repos/org/podinfo", github.URL)
+
+	_, err = NewGitHub("https://github.com/podinfo", "token")
+	require.Error(t, err)
+
+	_, err = NewGitHub("https://github.com/org/podinfo", "")
+	require.Error(t, err)
+}
+
+func TestCommitStatus(t *testing.T) {
+	tests := []struct {
+		reason   string
+		severity string
+		state    string
+	}{
+		{reason: "NewRevisionDetected", severity: "info", state: "pending"},
+		{reason: "WaitingForApproval", severity: "warn", state: "pending"},
+		{reason: "Promoted", severity: "info", state: "success"},
+		{reason: "AnalysisSkipped", severity: "info", state: "success"},
+		{reason: "Aborted", severity: "warn", state: "failure"},
+		{reason: "FailedChecksThresholdReached", severity: "error", state: "failure"},
+		{reason: "Initialized", severity: "info", state: ""},
+	}
+	for _, tt := range tests {
+		_, state, ok := commitStatus([]Field{{Name: "Reason", Value: tt.reason}}, tt.severity)
+		assert.Equal(t, tt.state != "", ok, tt.reason)
+		assert.Equal(t, tt.state, state, tt.reason)
+	}
+
+	assert.Equal(t, 140, len(truncate(strings.Repeat("a", 200), 140)))
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GitLab holds the commit statuses API address and token of a project
+type GitLab struct {
+	URL   string
+	Token string
+}
+
+// GitLabStatus holds the commit status payload
+// https://docs.gitlab.com/ee/api/commits.html#set-the-pipeline-status-of-a-commit
+type GitLabStatus struct {
+	State       string `json:"state"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// NewGitLab validates the project URL and returns a GitLab object
+func NewGitLab(address string, token string) (*GitLab, error) {
+	server, project, err := parseGitRepository(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitLab project URL %s: %w", address, err)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("the GitLab token is required")
+	}
+
+	return &GitLab{
+		URL:   fmt.Sprintf("%s/api/v4/projects/%s", server.String(), url.PathEscape(project)),
+		Token: token,
+	}, nil
+}
+
+// Post sets the commit status of the canary revision,
+// the alerts that don't change the result of the analysis are ignored
+func (g *GitLab) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	revision, state, ok := commitStatus(fields, severity)
+	if !ok {
+		return nil
+	}
+	if revision == "" {
+		return fmt.Errorf("the canary doesn't have a Git revision annotation")
+	}
+	if state == commitStatusFailure {
+		state = "failed"
+	}
+
+	payload := GitLabStatus{
+		State:       state,
+		Name:        commitStatusContext(workload, namespace),
+		Description: truncate(strings.TrimSpace(message), 255),
+	}
+	headers := map[string]string{"PRIVATE-TOKEN": g.Token}
+
+	err := postMessageWithHeaders(fmt.Sprintf("%s/statuses/%s", g.URL, url.PathEscape(revision)), headers, payload)
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitLab_Post(t *testing.T) {
+	fields := []Field{
+		{Name: "Reason", Value: "FailedChecksThresholdReached"},
+		{Name: "Revision", Value: "7d8f6b5"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v4/projects/group%2Fpodinfo/statuses/7d8f6b5", r.URL.EscapedPath())
+		require.Equal(t, "token", r.Header.Get("PRIVATE-TOKEN"))
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload = GitLabStatus{}
+		err = json.Unmarshal(b, &payload)
+		require.NoError(t, err)
+
+		require.Equal(t, "failed", payload.State)
+		require.Equal(t, "flagger/podinfo.test", payload.Name)
+		require.Equal(t, "test", payload.Description)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	gitlab, err := NewGitLab(ts.URL+"/group/podinfo", "token")
+	require.NoError(t, err)
+
+	err = gitlab.Post("podinfo", "test", "test", fields, "error")
+	require.NoError(t, err)
+}
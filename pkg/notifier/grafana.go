@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Grafana holds the annotations API address and token
+type Grafana struct {
+	URL   string
+	Token string
+}
+
+// GrafanaAnnotation holds the annotation payload
+// https://grafana.com/docs/grafana/latest/http_api/annotations/
+type GrafanaAnnotation struct {
+	Time int64    `json:"time"`
+	Tags []string `json:"tags"`
+	Text string   `json:"text"`
+}
+
+// NewGrafana validates the Grafana URL and returns a Grafana object
+func NewGrafana(address string, token string) (*Grafana, error) {
+	u, err := url.ParseRequestURI(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Grafana URL %s", address)
+	}
+
+	if !strings.HasSuffix(u.Path, "/api/annotations") {
+		u.Path = path.Join(u.Path, "api", "annotations")
+	}
+
+	return &Grafana{
+		URL:   u.String(),
+		Token: token,
+	}, nil
+}
+
+// Post Grafana annotation tagged with the workload name and namespace
+func (g *Grafana) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	text := message
+	for _, f := range fields {
+		text += fmt.Sprintf("\n%s: %s", f.Name, f.Value)
+	}
+
+	payload := GrafanaAnnotation{
+		Time: time.Now().UnixNano() / int64(time.Millisecond),
+		Tags: []string{"flagger", workload, namespace, severity},
+		Text: text,
+	}
+
+	var headers map[string]string
+	if g.Token != "" {
+		headers = map[string]string{"Authorization": fmt.Sprintf("Bearer %s", g.Token)}
+	}
+
+	err := postMessageWithHeaders(g.URL, headers, payload)
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrafana_Post(t *testing.T) {
+	fields := []Field{
+		{Name: "name1", Value: "value1"},
+		{Name: "name2", Value: "value2"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/grafana/api/annotations", r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload = GrafanaAnnotation{}
+		err = json.Unmarshal(b, &payload)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"flagger", "podinfo", "test", "info"}, payload.Tags)
+		require.Equal(t, "test\nname1: value1\nname2: value2", payload.Text)
+		require.NotZero(t, payload.Time)
+	}))
+	defer ts.Close()
+
+	grafana, err := NewGrafana(ts.URL+"/grafana", "token")
+	require.NoError(t, err)
+
+	err = grafana.Post("podinfo", "test", "test", fields, "info")
+	require.NoError(t, err)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const uploadTimeout = time.Minute
+
+// Exporter builds the analysis reports from the canary events
+// and uploads them to the store when the analysis completes,
+// the reports are also pushed to the registry when one is set
+type Exporter struct {
+	store    Store
+	registry *Registry
+	format   string
+	logger   *zap.SugaredLogger
+	mu       sync.Mutex
+	reports  map[string]*Report
+}
+
+// NewExporter validates the report format and returns an Exporter
+func NewExporter(store Store, format string, logger *zap.SugaredLogger) (*Exporter, error) {
+	if format != FormatJSON && format != FormatHTML {
+		return nil, fmt.Errorf("report format %s not supported", format)
+	}
+	return &Exporter{
+		store:   store,
+		format:  format,
+		logger:  logger,
+		reports: make(map[string]*Report),
+	}, nil
+}
+
+// WithRegistry sets the registry the reports are pushed to as OCI artifacts,
+// the store can be nil when the reports are only pushed to the registry
+func (e *Exporter) WithRegistry(registry *Registry) *Exporter {
+	e.registry = registry
+	return e
+}
+
+// Record appends an event to the report of the canary analysis in progress,
+// a new report is started when the analysis of a new revision begins
+func (e *Exporter) Record(cd *flaggerv1.Canary, eventType string, reason flaggerv1.EventReason, message string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	key := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+	now := time.Now()
+	r, ok := e.reports[key]
+	if !ok || reason == flaggerv1.ReasonNewRevisionDetected {
+		r = &Report{
+			Name:      cd.Name,
+			Namespace: cd.Namespace,
+			TargetRef: fmt.Sprintf("%s/%s", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name),
+			StartTime: now,
+		}
+		e.reports[key] = r
+	}
+	r.Steps = append(r.Steps, Step{
+		Time:    now,
+		Type:    eventType,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// Export completes the report of the canary analysis and uploads it in the background,
+// the images are the container images of the target that was analysed
+func (e *Exporter) Export(cd *flaggerv1.Canary, outcome flaggerv1.CanaryPhase, metrics []Metric, images []string) {
+	e.mu.Lock()
+	key := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+	r, ok := e.reports[key]
+	delete(e.reports, key)
+	e.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	r.Outcome = outcome
+	r.EndTime = time.Now()
+	r.Metrics = metrics
+	r.Images = images
+
+	data, contentType, err := r.Render(e.format)
+	if err != nil {
+		e.logger.With("canary", key).Errorf("Report rendering failed: %v", err)
+		return
+	}
+
+	if e.store != nil {
+		objectKey := fmt.Sprintf("%s/%s/%s.%s", r.Namespace, r.Name, r.EndTime.UTC().Format("20060102T150405Z"), e.format)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+			defer cancel()
+			if err := e.store.Upload(ctx, objectKey, contentType, data); err != nil {
+				e.logger.With("canary", key).Errorf("Report upload failed: %v", err)
+				return
+			}
+			e.logger.With("canary", key).Infof("Report %s uploaded", objectKey)
+		}()
+	}
+
+	if e.registry != nil {
+		e.push(key, r, data)
+	}
+}
+
+// push tags the report artifact with the digest of the target image in the background,
+// the reports of the targets without an image pinned by digest are not pushed
+func (e *Exporter) push(key string, r *Report, data []byte) {
+	tag, ok := ImageTag(r.Images)
+	if !ok {
+		e.logger.With("canary", key).Infof("Report not pushed, the target images are not pinned by digest")
+		return
+	}
+
+	mediaType := ArtifactType + "+json"
+	if e.format == FormatHTML {
+		mediaType = "text/html"
+	}
+	annotations := map[string]string{
+		"org.opencontainers.image.created": r.EndTime.UTC().Format(time.RFC3339),
+		"app.flagger.canary":               key,
+		"app.flagger.outcome":              string(r.Outcome),
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+		defer cancel()
+		err := e.registry.Push(ctx, tag, fmt.Sprintf("report.%s", e.format), mediaType, data, annotations)
+		if err != nil {
+			e.logger.With("canary", key).Errorf("Report push failed: %v", err)
+			return
+		}
+		e.logger.With("canary", key).Infof("Report pushed to %s/%s:%s", e.registry.URL, e.registry.Repository, tag)
+	}()
+}
+
+// Delete discards the report of a canary analysis in progress
+func (e *Exporter) Delete(cd *flaggerv1.Canary) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.reports, fmt.Sprintf("%s.%s", cd.Name, cd.Namespace))
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+type upload struct {
+	key         string
+	contentType string
+	data        []byte
+}
+
+type fakeStore struct {
+	uploads chan upload
+}
+
+func (s *fakeStore) Upload(ctx context.Context, key string, contentType string, data []byte) error {
+	s.uploads <- upload{key: key, contentType: contentType, data: data}
+	return nil
+}
+
+func newTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{Kind: "Deployment", Name: "podinfo"},
+		},
+	}
+}
+
+func TestExporter_Export(t *testing.T) {
+	store := &fakeStore{uploads: make(chan upload, 1)}
+	exporter, err := NewExporter(store, FormatJSON, zap.NewNop().Sugar())
+	require.NoError(t, err)
+
+	cd := newTestCanary()
+	exporter.Record(cd, "Normal", flaggerv1.ReasonNewRevisionDetected, "New revision detected")
+	exporter.Record(cd, "Normal", flaggerv1.ReasonAdvanced, "Advance podinfo.default canary weight 10")
+	exporter.Record(cd, "Normal", flaggerv1.ReasonPromoted, "Promotion completed")
+
+	max := 500.0
+	val := 120.0
+	exporter.Export(cd, flaggerv1.CanaryPhaseSucceeded, []Metric{{Name: "request-duration", Max: &max, LastValue: &val}}, nil)
+
+	select {
+	case u := <-store.uploads:
+		assert.True(t, strings.HasPrefix(u.key, "default/podinfo/"))
+		assert.True(t, strings.HasSuffix(u.key, ".json"))
+		assert.Equal(t, "application/json", u.contentType)
+
+		var r Report
+		require.NoError(t, json.Unmarshal(u.data, &r))
+		assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, r.Outcome)
+		assert.Equal(t, "Deployment/podinfo", r.TargetRef)
+		require.Len(t, r.Steps, 3)
+		assert.Equal(t, flaggerv1.ReasonAdvanced, r.Steps[1].Reason)
+		require.Len(t, r.Metrics, 1)
+		assert.Equal(t, 120.0, *r.Metrics[0].LastValue)
+	case <-time.After(5 * time.Second):
+		t.Fatal("report was not uploaded")
+	}
+
+	// the report is discarded after the export
+	exporter.Export(cd, flaggerv1.CanaryPhaseSucceeded, nil, nil)
+	select {
+	case <-store.uploads:
+		t.Fatal("report uploaded twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestExporter_NewRevision(t *testing.T) {
+	exporter, err := NewExporter(&fakeStore{}, FormatJSON, zap.NewNop().Sugar())
+	require.NoError(t, err)
+
+	cd := newTestCanary()
+	exporter.Record(cd, "Normal", flaggerv1.ReasonAdvanced, "Advance podinfo.default canary weight 10")
+	exporter.Record(cd, "Normal", flaggerv1.ReasonNewRevisionDetected, "New revision detected")
+
+	require.Len(t, exporter.reports["podinfo.default"].Steps, 1)
+	assert.Equal(t, flaggerv1.ReasonNewRevisionDetected, exporter.reports["podinfo.default"].Steps[0].Reason)
+}
+
+func TestReport_RenderHTML(t *testing.T) {
+	min := 99.0
+	r := &Report{
+		Name:      "podinfo",
+		Namespace: "default",
+		Outcome:   flaggerv1.CanaryPhaseFailed,
+		Steps: []Step{
+			{Type: "Warning", Reason: flaggerv1.ReasonMetricThresholdBreached, Message: "Halt advancement success rate 90.00% < 99%"},
+		},
+		Metrics: []Metric{{Name: "request-success-rate", Min: &min}},
+	}
+
+	data, contentType, err := r.Render(FormatHTML)
+	require.NoError(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", contentType)
+	assert.Contains(t, string(data), "<td>MetricThresholdBreached</td>")
+	assert.Contains(t, string(data), "<td>request-success-rate</td><td>99.00</td><td>-</td><td>-</td>")
+
+	_, _, err = r.Render("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// ArtifactType is the OCI artifact type of the analysis reports
+	ArtifactType = "application/vnd.flagger.report.v1"
+
+	manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	emptyMediaType    = "application/vnd.oci.empty.v1+json"
+)
+
+// Registry pushes the reports as OCI artifacts to a container registry repository,
+// the artifacts are tagged with the digest of the target image
+type Registry struct {
+	// URL is the registry address e.g. https://ghcr.io
+	URL string
+	// Repository is the repository path e.g. org/flagger-reports
+	Repository string
+	Username   string
+	Password   string
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewRegistry parses an oci://registry/repository address,
+// the credentials are read from the REPORT_REGISTRY_USERNAME and REPORT_REGISTRY_PASSWORD env vars
+func NewRegistry(address string) (*Registry, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report registry address %s: %w", address, err)
+	}
+	if u.Scheme != "oci" {
+		return nil, fmt.Errorf("invalid report registry address %s: scheme must be oci", address)
+	}
+	repository := strings.Trim(u.Path, "/")
+	if u.Host == "" || repository == "" {
+		return nil, fmt.Errorf("invalid report registry address %s: repository not specified", address)
+	}
+	return &Registry{
+		URL:        fmt.Sprintf("https://%s", u.Host),
+		Repository: repository,
+		Username:   os.Getenv("REPORT_REGISTRY_USERNAME"),
+		Password:   os.Getenv("REPORT_REGISTRY_PASSWORD"),
+	}, nil
+}
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int               `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Config        descriptor        `json:"config"`
+	Layers        []descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+var (
+	digestRegexp    = regexp.MustCompile(`@sha256:([a-f0-9]{64})$`)
+	challengeRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// ImageTag returns the artifact tag of the first image pinned by digest,
+// the sha256:<hex> digest is tagged as sha256-<hex>
+func ImageTag(images []string) (string, bool) {
+	for _, image := range images {
+		if m := digestRegexp.FindStringSubmatch(image); m != nil {
+			return fmt.Sprintf("sha256-%s", m[1]), true
+		}
+	}
+	return "", false
+}
+
+// Push uploads the report as the layer of an OCI artifact and tags the artifact manifest,
+// the annotations are set on the manifest
+func (r *Registry) Push(ctx context.Context, tag string, title string, mediaType string,
+	data []byte, annotations map[string]string) error {
+	config := []byte("{}")
+	configDesc := descriptor{MediaType: emptyMediaType, Digest: digestOf(config), Size: len(config)}
+	layerDesc := descriptor{
+		MediaType:   mediaType,
+		Digest:      digestOf(data),
+		Size:        len(data),
+		Annotations: map[string]string{"org.opencontainers.image.title": title},
+	}
+
+	if err := r.pushBlob(ctx, configDesc.Digest, config); err != nil {
+		return err
+	}
+	if err := r.pushBlob(ctx, layerDesc.Digest, data); err != nil {
+		return err
+	}
+
+	m, err := json.Marshal(manifest{
+		SchemaVersion: 2,
+		MediaType:     manifestMediaType,
+		ArtifactType:  ArtifactType,
+		Config:        configDesc,
+		Layers:        []descriptor{layerDesc},
+		Annotations:   annotations,
+	})
+	if err != nil {
+		return fmt.Errorf("json.Marshal failed: %w", err)
+	}
+	res, err := r.do(ctx, http.MethodPut, r.endpoint("manifests/"+tag), manifestMediaType, m)
+	if err != nil {
+		return fmt.Errorf("pushing manifest %s failed: %w", tag, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return fmt.Errorf("pushing manifest %s failed: %s", tag, responseError(res))
+	}
+	return nil
+}
+
+// pushBlob uploads a blob in a single request unless it exists in the repository
+func (r *Registry) pushBlob(ctx context.Context, digest string, data []byte) error {
+	res, err := r.do(ctx, http.MethodHead, r.endpoint("blobs/"+digest), "", nil)
+	if err != nil {
+		return fmt.Errorf("checking blob %s failed: %w", digest, err)
+	}
+	res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	res, err = r.do(ctx, http.MethodPost, r.endpoint("blobs/uploads/"), "", nil)
+	if err != nil {
+		return fmt.Errorf("starting blob upload failed: %w", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("starting blob upload failed: %s", responseError(res))
+	}
+	location, err := res.Location()
+	if err != nil {
+		return fmt.Errorf("starting blob upload failed: %w", err)
+	}
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+
+	res, err = r.do(ctx, http.MethodPut, location.String(), "application/octet-stream", data)
+	if err != nil {
+		return fmt.Errorf("uploading blob %s failed: %w", digest, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return fmt.Errorf("uploading blob %s failed: %s", digest, responseError(res))
+	}
+	return nil
+}
+
+func (r *Registry) endpoint(path string) string {
+	return fmt.Sprintf("%s/v2/%s/%s", r.URL, r.Repository, path)
+}
+
+// do sends a registry request, the request is retried once with the credentials
+// requested by the registry authentication challenge
+func (r *Registry) do(ctx context.Context, method string, address string, contentType string, data []byte) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequest(method, address, bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if token := r.getToken(); token != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		} else if r.Username != "" {
+			req.SetBasicAuth(r.Username, r.Password)
+		}
+		return http.DefaultClient.Do(req.WithContext(ctx))
+	}
+
+	res, err := send()
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	challenge := res.Header.Get("WWW-Authenticate")
+	res.Body.Close()
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return nil, fmt.Errorf("registry authentication failed for %s", address)
+	}
+	if err := r.login(ctx, challenge); err != nil {
+		return nil, err
+	}
+	return send()
+}
+
+// login requests a push token from the realm of a bearer challenge
+// e.g. Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:org/reports:push,pull"
+func (r *Registry) login(ctx context.Context, challenge string) error {
+	params := map[string]string{}
+	for _, m := range challengeRegexp.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("invalid registry authentication challenge %s", challenge)
+	}
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:push,pull", r.Repository))
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+	if r.Username != "" {
+		req.SetBasicAuth(r.Username, r.Password)
+	}
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("registry token request failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry token request failed: %s", responseError(res))
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return fmt.Errorf("decoding registry token failed: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.token = token.Token
+	if r.token == "" {
+		r.token = token.AccessToken
+	}
+	return nil
+}
+
+func (r *Registry) getToken() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.token
+}
+
+func digestOf(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+func responseError(res *http.Response) string {
+	body, _ := ioutil.ReadAll(res.Body)
+	return fmt.Sprintf("status %d: %s", res.StatusCode, string(body))
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// fakeRegistry implements the push endpoints of the OCI distribution API,
+// the requests are authorized with a bearer token issued by the /token endpoint
+type fakeRegistry struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *httptest.Server) {
+	reg := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reg.mu.Lock()
+		defer reg.mu.Unlock()
+
+		if r.URL.Path == "/token" {
+			user, pass, ok := r.BasicAuth()
+			require.True(t, ok)
+			assert.Equal(t, "flagger", user)
+			assert.Equal(t, "s3cr3t", pass)
+			assert.Equal(t, "repository:org/reports:push,pull", r.URL.Query().Get("scope"))
+			w.Write([]byte(`{"token":"t0ken"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+ts.URL+`/token",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		path := strings.TrimPrefix(r.URL.Path, "/v2/org/reports/")
+		switch {
+		case r.Method == http.MethodHead && strings.HasPrefix(path, "blobs/"):
+			if _, ok := reg.blobs[strings.TrimPrefix(path, "blobs/")]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case r.Method == http.MethodPost && path == "blobs/uploads/":
+			w.Header().Set("Location", "/v2/org/reports/blobs/uploads/1?state=a")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && path == "blobs/uploads/1":
+			assert.Equal(t, "a", r.URL.Query().Get("state"))
+			reg.blobs[r.URL.Query().Get("digest")] = body
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && strings.HasPrefix(path, "manifests/"):
+			assert.Equal(t, manifestMediaType, r.Header.Get("Content-Type"))
+			reg.manifests[strings.TrimPrefix(path, "manifests/")] = body
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return reg, ts
+}
+
+func TestRegistry_Push(t *testing.T) {
+	reg, ts := newFakeRegistry(t)
+	defer ts.Close()
+
+	registry := &Registry{URL: ts.URL, Repository: "org/reports", Username: "flagger", Password: "s3cr3t"}
+	err := registry.Push(context.TODO(), "sha256-abc", "report.json", ArtifactType+"+json",
+		[]byte(`{"name":"podinfo"}`), map[string]string{"app.flagger.outcome": "Succeeded"})
+	require.NoError(t, err)
+
+	require.Contains(t, reg.manifests, "sha256-abc")
+	var m manifest
+	require.NoError(t, json.Unmarshal(reg.manifests["sha256-abc"], &m))
+	assert.Equal(t, ArtifactType, m.ArtifactType)
+	assert.Equal(t, emptyMediaType, m.Config.MediaType)
+	assert.Equal(t, "Succeeded", m.Annotations["app.flagger.outcome"])
+	require.Len(t, m.Layers, 1)
+	assert.Equal(t, "report.json", m.Layers[0].Annotations["org.opencontainers.image.title"])
+	assert.Equal(t, `{"name":"podinfo"}`, string(reg.blobs[m.Layers[0].Digest]))
+	assert.Equal(t, "{}", string(reg.blobs[m.Config.Digest]))
+}
+
+func TestNewRegistry(t *testing.T) {
+	registry, err := NewRegistry("oci://ghcr.io/org/reports")
+	require.NoError(t, err)
+	assert.Equal(t, "https://ghcr.io", registry.URL)
+	assert.Equal(t, "org/reports", registry.Repository)
+
+	_, err = NewRegistry("https://ghcr.io/org/reports")
+	require.Error(t, err)
+	_, err = NewRegistry("oci://ghcr.io")
+	require.Error(t, err)
+}
+
+func TestImageTag(t *testing.T) {
+	digest := strings.Repeat("a", 64)
+	tag, ok := ImageTag([]string{"ghcr.io/stefanprodan/podinfo:6.0.0", "ghcr.io/org/app:v1@sha256:" + digest})
+	require.True(t, ok)
+	assert.Equal(t, "sha256-"+digest, tag)
+
+	_, ok = ImageTag([]string{"ghcr.io/stefanprodan/podinfo:6.0.0"})
+	assert.False(t, ok)
+}
+
+func TestExporter_Push(t *testing.T) {
+	reg, ts := newFakeRegistry(t)
+	defer ts.Close()
+
+	exporter, err := NewExporter(nil, FormatJSON, zap.NewNop().Sugar())
+	require.NoError(t, err)
+	exporter.WithRegistry(&Registry{URL: ts.URL, Repository: "org/reports", Username: "flagger", Password: "s3cr3t"})
+
+	cd := newTestCanary()
+	digest := strings.Repeat("b", 64)
+	exporter.Record(cd, "Normal", flaggerv1.ReasonNewRevisionDetected, "New revision detected")
+	exporter.Export(cd, flaggerv1.CanaryPhaseFailed, nil, []string{"ghcr.io/org/podinfo@sha256:" + digest})
+
+	require.Eventually(t, func() bool {
+		reg.mu.Lock()
+		defer reg.mu.Unlock()
+		_, ok := reg.manifests["sha256-"+digest]
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
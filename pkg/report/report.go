@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// Report is the summary of a canary analysis
+type Report struct {
+	Name      string                `json:"name"`
+	Namespace string                `json:"namespace"`
+	TargetRef string                `json:"targetRef"`
+	Images    []string              `json:"images,omitempty"`
+	Outcome   flaggerv1.CanaryPhase `json:"outcome"`
+	StartTime time.Time             `json:"startTime"`
+	EndTime   time.Time             `json:"endTime"`
+	Steps     []Step                `json:"steps"`
+	Metrics   []Metric              `json:"metrics,omitempty"`
+}
+
+// Step is an event recorded during the canary analysis
+type Step struct {
+	Time    time.Time             `json:"time"`
+	Type    string                `json:"type"`
+	Reason  flaggerv1.EventReason `json:"reason"`
+	Message string                `json:"message"`
+}
+
+// Metric is a check of the canary analysis with its threshold and last value
+type Metric struct {
+	Name      string   `json:"name"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	LastValue *float64 `json:"lastValue,omitempty"`
+}
+
+const (
+	FormatJSON = "json"
+	FormatHTML = "html"
+)
+
+// Render encodes the report in the JSON or HTML format
+// and returns the content with its MIME type
+func (r *Report) Render(format string) ([]byte, string, error) {
+	switch format {
+	case FormatJSON:
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("json.MarshalIndent failed: %w", err)
+		}
+		return b, "application/json", nil
+	case FormatHTML:
+		var buf bytes.Buffer
+		if err := htmlTemplate.Execute(&buf, r); err != nil {
+			return nil, "", fmt.Errorf("rendering html report failed: %w", err)
+		}
+		return buf.Bytes(), "text/html; charset=utf-8", nil
+	default:
+		return nil, "", fmt.Errorf("report format %s not supported", format)
+	}
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"value": func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", *v)
+	},
+	"time": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Name }}.{{ .Namespace }} canary analysis</title>
+</head>
+<body>
+<h1>{{ .Name }}.{{ .Namespace }}</h1>
+<table>
+<tr><th>Target</th><td>{{ .TargetRef }}</td></tr>
+{{- range .Images }}
+<tr><th>Image</th><td>{{ . }}</td></tr>
+{{- end }}
+<tr><th>Outcome</th><td>{{ .Outcome }}</td></tr>
+<tr><th>Start</th><td>{{ time .StartTime }}</td></tr>
+<tr><th>End</th><td>{{ time .EndTime }}</td></tr>
+</table>
+{{- if .Metrics }}
+<h2>Metrics</h2>
+<table>
+<tr><th>Name</th><th>Min</th><th>Max</th><th>Last value</th></tr>
+{{- range .Metrics }}
+<tr><td>{{ .Name }}</td><td>{{ value .Min }}</td><td>{{ value .Max }}</td><td>{{ value .LastValue }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+<h2>Steps</h2>
+<table>
+<tr><th>Time</th><th>Type</th><th>Reason</th><th>Message</th></tr>
+{{- range .Steps }}
+<tr><td>{{ time .Time }}</td><td>{{ .Type }}</td><td>{{ .Reason }}</td><td>{{ .Message }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Store uploads the reports to an object storage bucket
+type Store interface {
+	Upload(ctx context.Context, key string, contentType string, data []byte) error
+}
+
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// NewStore creates a store based on the address scheme:
+// s3://bucket/prefix for Amazon S3,
+// gs://bucket/prefix for Google Cloud Storage using the S3 interoperability API and HMAC keys,
+// azblob://account/container/prefix for Azure Blob Storage using the AZURE_STORAGE_SAS_TOKEN env var
+func NewStore(address string) (Store, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report store address %s: %w", address, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid report store address %s: bucket not specified", address)
+	}
+	prefix := strings.Trim(u.Path, "/")
+
+	switch u.Scheme {
+	case "s3":
+		return newS3Store(u.Host, prefix, aws.NewConfig())
+	case "gs":
+		return newS3Store(u.Host, prefix, aws.NewConfig().
+			WithEndpoint(gcsEndpoint).WithRegion("auto"))
+	case "azblob":
+		parts := strings.SplitN(prefix, "/", 2)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("invalid report store address %s: container not specified", address)
+		}
+		blobPrefix := ""
+		if len(parts) == 2 {
+			blobPrefix = parts[1]
+		}
+		return &AzureBlobStore{
+			URL:      fmt.Sprintf("https://%s.blob.core.windows.net/%s", u.Host, parts[0]),
+			Prefix:   blobPrefix,
+			SASToken: strings.TrimPrefix(os.Getenv("AZURE_STORAGE_SAS_TOKEN"), "?"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("report store scheme %s not supported", u.Scheme)
+	}
+}
+
+// S3Store uploads the reports to an S3 compatible bucket
+type S3Store struct {
+	client *s3.S3
+	bucket string
+	prefix string
+}
+
+func newS3Store(bucket string, prefix string, cfg *aws.Config) (*S3Store, error) {
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating aws session: %w", err)
+	}
+	return &S3Store{
+		client: s3.New(sess),
+		bucket: bucket,
+		prefix: prefix,
+	}, nil
+}
+
+// Upload puts the report object in the bucket
+func (s *S3Store) Upload(ctx context.Context, key string, contentType string, data []byte) error {
+	_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(path.Join(s.prefix, key)),
+		ContentType: aws.String(contentType),
+		Body:        bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("uploading %s to bucket %s failed: %w", key, s.bucket, err)
+	}
+	return nil
+}
+
+// AzureBlobStore uploads the reports to an Azure Blob Storage container
+type AzureBlobStore struct {
+	URL      string
+	Prefix   string
+	SASToken string
+}
+
+// Upload puts the report as a block blob in the container
+func (s *AzureBlobStore) Upload(ctx context.Context, key string, contentType string, data []byte) error {
+	address := fmt.Sprintf("%s/%s", s.URL, path.Join(s.Prefix, key))
+	if s.SASToken != "" {
+		address = fmt.Sprintf("%s?%s", address, s.SASToken)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, address, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("uploading %s failed: %w", key, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		body, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("uploading %s failed with status %d: %s", key, res.StatusCode, string(body))
+	}
+	return nil
+}
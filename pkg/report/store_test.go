@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStore(t *testing.T) {
+	s, err := NewStore("s3://reports/flagger")
+	require.NoError(t, err)
+	assert.Equal(t, "reports", s.(*S3Store).bucket)
+	assert.Equal(t, "flagger", s.(*S3Store).prefix)
+
+	s, err = NewStore("gs://reports")
+	require.NoError(t, err)
+	assert.Equal(t, gcsEndpoint, s.(*S3Store).client.Endpoint)
+
+	s, err = NewStore("azblob://account/reports/flagger")
+	require.NoError(t, err)
+	assert.Equal(t, "https://account.blob.core.windows.net/reports", s.(*AzureBlobStore).URL)
+	assert.Equal(t, "flagger", s.(*AzureBlobStore).Prefix)
+
+	_, err = NewStore("azblob://account")
+	assert.Error(t, err)
+
+	_, err = NewStore("ftp://reports")
+	assert.Error(t, err)
+}
+
+func TestAzureBlobStore_Upload(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/reports/flagger/default/podinfo.json", r.URL.Path)
+		assert.Equal(t, "sig=token", r.URL.RawQuery)
+		assert.Equal(t, "BlockBlob", r.Header.Get("x-ms-blob-type"))
+		b, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "{}", string(b))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	s := &AzureBlobStore{URL: ts.URL + "/reports", Prefix: "flagger", SASToken: "sig=token"}
+	err := s.Upload(context.TODO(), "default/podinfo.json", "application/json", []byte("{}"))
+	require.NoError(t, err)
+}
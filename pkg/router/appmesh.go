@@ -42,6 +42,7 @@ type AppMeshRouter struct {
 	appmeshClient clientset.Interface
 	flaggerClient clientset.Interface
 	logger        *zap.SugaredLogger
+	primarySuffix string
 }
 
 // Reconcile creates or updates App Mesh virtual nodes and virtual services
@@ -50,7 +51,7 @@ func (ar *AppMeshRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return fmt.Errorf("mesh name cannot be empty")
 	}
 
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(ar.primarySuffix)
 	targetHost := fmt.Sprintf("%s.%s", apexName, canary.Namespace)
 	primaryHost := fmt.Sprintf("%s.%s", primaryName, canary.Namespace)
 	canaryHost := fmt.Sprintf("%s.%s", canaryName, canary.Namespace)
@@ -175,9 +176,9 @@ func (ar *AppMeshRouter) reconcileVirtualNode(canary *flaggerv1.Canary, name str
 
 // reconcileVirtualService creates or updates a virtual service
 func (ar *AppMeshRouter) reconcileVirtualService(canary *flaggerv1.Canary, name string, canaryWeight int64) error {
-	apexName, _, _ := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames(ar.primarySuffix)
 	canaryVirtualNode := fmt.Sprintf("%s-canary", apexName)
-	primaryVirtualNode := fmt.Sprintf("%s-primary", apexName)
+	primaryVirtualNode := canary.GetPrimaryName(apexName, ar.primarySuffix)
 	protocol := ar.getProtocol(canary)
 
 	routerName := apexName
@@ -352,7 +353,7 @@ func (ar *AppMeshRouter) GetRoutes(canary *flaggerv1.Canary) (
 	mirrored bool,
 	err error,
 ) {
-	apexName, _, _ := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames(ar.primarySuffix)
 	vsName := fmt.Sprintf("%s.%s", apexName, canary.Namespace)
 	vs, err := ar.appmeshClient.AppmeshV1beta1().VirtualServices(canary.Namespace).Get(context.TODO(), vsName, metav1.GetOptions{})
 	if err != nil {
@@ -370,14 +371,14 @@ func (ar *AppMeshRouter) GetRoutes(canary *flaggerv1.Canary) (
 		if t.VirtualNodeName == fmt.Sprintf("%s-canary", apexName) {
 			canaryWeight = int(t.Weight)
 		}
-		if t.VirtualNodeName == fmt.Sprintf("%s-primary", apexName) {
+		if t.VirtualNodeName == canary.GetPrimaryName(apexName, ar.primarySuffix) {
 			primaryWeight = int(t.Weight)
 		}
 	}
 
 	if primaryWeight == 0 && canaryWeight == 0 {
-		err = fmt.Errorf("VirtualService %s does not contain routes for %s-primary and %s-canary",
-			vsName, apexName, apexName)
+		err = fmt.Errorf("VirtualService %s does not contain routes for %s and %s-canary",
+			vsName, canary.GetPrimaryName(apexName, ar.primarySuffix), apexName)
 	}
 
 	mirrored = false
@@ -392,7 +393,7 @@ func (ar *AppMeshRouter) SetRoutes(
 	canaryWeight int,
 	_ bool,
 ) error {
-	apexName, _, _ := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames(ar.primarySuffix)
 	vsName := fmt.Sprintf("%s.%s", apexName, canary.Namespace)
 	vs, err := ar.appmeshClient.AppmeshV1beta1().VirtualServices(canary.Namespace).Get(context.TODO(), vsName, metav1.GetOptions{})
 	if err != nil {
@@ -407,7 +408,7 @@ func (ar *AppMeshRouter) SetRoutes(
 				Weight:          int64(canaryWeight),
 			},
 			{
-				VirtualNodeName: fmt.Sprintf("%s-primary", apexName),
+				VirtualNodeName: canary.GetPrimaryName(apexName, ar.primarySuffix),
 				Weight:          int64(primaryWeight),
 			},
 		},
@@ -475,7 +476,7 @@ func (ar *AppMeshRouter) makeHeaders(canary *flaggerv1.Canary) []appmeshv1.HttpR
 }
 
 func (ar *AppMeshRouter) getProtocol(canary *flaggerv1.Canary) string {
-	if strings.Contains(canary.Spec.Service.PortName, "grpc") {
+	if strings.Contains(canary.Spec.Service.PortName, "grpc") || canary.Spec.Service.AppProtocol == "grpc" {
 		return "grpc"
 	}
 	return "http"
@@ -43,12 +43,13 @@ type AppMeshv1beta2Router struct {
 	flaggerClient clientset.Interface
 	logger        *zap.SugaredLogger
 	labelSelector string
+	primarySuffix string
 }
 
 // Reconcile creates or updates App Mesh virtual nodes and virtual services
 func (ar *AppMeshv1beta2Router) Reconcile(canary *flaggerv1.Canary) error {
 	svcSuffix := "svc.cluster.local."
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(ar.primarySuffix)
 	primaryHost := fmt.Sprintf("%s.%s.%s", primaryName, canary.Namespace, svcSuffix)
 	canaryHost := fmt.Sprintf("%s.%s.%s", canaryName, canary.Namespace, svcSuffix)
 
@@ -61,7 +62,7 @@ func (ar *AppMeshv1beta2Router) Reconcile(canary *flaggerv1.Canary) error {
 
 	// sync virtual node e.g. app-primary-namespace
 	// DNS app-primary.namespace
-	err := ar.reconcileVirtualNode(canary, primaryName, fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name), primaryHost)
+	err := ar.reconcileVirtualNode(canary, primaryName, canary.GetPrimaryName(canary.Spec.TargetRef.Name, ar.primarySuffix), primaryHost)
 	if err != nil {
 		return fmt.Errorf("reconcileVirtualNode failed: %w", err)
 	}
@@ -87,6 +88,12 @@ func (ar *AppMeshv1beta2Router) Reconcile(canary *flaggerv1.Canary) error {
 		return fmt.Errorf("reconcileVirtualRouter failed: %w", err)
 	}
 
+	// sync virtual gateway route to the main virtual service
+	err = ar.reconcileGatewayRoute(canary)
+	if err != nil {
+		return fmt.Errorf("reconcileGatewayRoute failed: %w", err)
+	}
+
 	return nil
 }
 
@@ -192,9 +199,9 @@ func (ar *AppMeshv1beta2Router) reconcileVirtualNode(canary *flaggerv1.Canary, n
 
 // reconcileVirtualRouter creates or updates a virtual router
 func (ar *AppMeshv1beta2Router) reconcileVirtualRouter(canary *flaggerv1.Canary, name string, canaryWeight int64) error {
-	apexName, _, _ := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames(ar.primarySuffix)
 	canaryVirtualNode := fmt.Sprintf("%s-canary", apexName)
-	primaryVirtualNode := fmt.Sprintf("%s-primary", apexName)
+	primaryVirtualNode := canary.GetPrimaryName(apexName, ar.primarySuffix)
 	protocol := ar.getProtocol(canary)
 	timeout := ar.makeRouteTimeout(canary)
 
@@ -398,6 +405,108 @@ func (ar *AppMeshv1beta2Router) reconcileVirtualRouter(canary *flaggerv1.Canary,
 	return nil
 }
 
+// reconcileGatewayRoute creates or updates the virtual gateway route to the main virtual service,
+// the gateway traffic is routed by the main virtual router and follows the canary weights and matches
+func (ar *AppMeshv1beta2Router) reconcileGatewayRoute(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames(ar.primarySuffix)
+	spec := canary.Spec.Service.GatewayRoute
+	gatewayRoute, err := ar.appmeshClient.AppmeshV1beta2().GatewayRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("GatewayRoute %s get query error: %w", apexName, err)
+	}
+
+	// remove the gateway route when it's no longer part of the canary spec
+	if spec == nil {
+		if err == nil && controlledByCanary(gatewayRoute.ObjectMeta, canary) {
+			err = ar.appmeshClient.AppmeshV1beta2().GatewayRoutes(canary.Namespace).Delete(context.TODO(), apexName, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("GatewayRoute %s delete error: %w", apexName, err)
+			}
+			ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Infof("GatewayRoute %s deleted", apexName)
+		}
+		return nil
+	}
+
+	prefix := spec.Prefix
+	if prefix == "" {
+		prefix = "/"
+		if len(canary.Spec.Service.Match) > 0 &&
+			canary.Spec.Service.Match[0].Uri != nil &&
+			canary.Spec.Service.Match[0].Uri.Prefix != "" {
+			prefix = canary.Spec.Service.Match[0].Uri.Prefix
+		}
+	}
+	route := &appmeshv1.HTTPGatewayRoute{
+		Match: appmeshv1.HTTPGatewayRouteMatch{
+			Prefix: &prefix,
+		},
+		Action: appmeshv1.HTTPGatewayRouteAction{
+			Target: appmeshv1.GatewayRouteTarget{
+				VirtualService: appmeshv1.GatewayRouteVirtualService{
+					VirtualServiceRef: &appmeshv1.VirtualServiceReference{
+						Name: apexName,
+					},
+				},
+			},
+		},
+	}
+	if spec.Hostname != "" {
+		hostname := spec.Hostname
+		route.Match.Hostname = &appmeshv1.GatewayRouteHostnameMatch{Exact: &hostname}
+	}
+	grSpec := appmeshv1.GatewayRouteSpec{
+		Priority:  spec.Priority,
+		HTTPRoute: route,
+	}
+
+	// create gateway route
+	if errors.IsNotFound(err) {
+		gatewayRoute = &appmeshv1.GatewayRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				Labels:    spec.Labels,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: grSpec,
+		}
+		_, err = ar.appmeshClient.AppmeshV1beta2().GatewayRoutes(canary.Namespace).Create(context.TODO(), gatewayRoute, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("GatewayRoute %s create error %w", apexName, err)
+		}
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("GatewayRoute %s created", apexName)
+		return nil
+	}
+
+	// update gateway route but keep the fields populated by the App Mesh controller
+	specDiff := cmp.Diff(grSpec, gatewayRoute.Spec,
+		cmpopts.IgnoreFields(appmeshv1.GatewayRouteSpec{}, "AWSName", "MeshRef", "VirtualGatewayRef"))
+	labelsDiff := cmp.Diff(spec.Labels, gatewayRoute.Labels, cmpopts.EquateEmpty())
+	if specDiff != "" || labelsDiff != "" {
+		grClone := gatewayRoute.DeepCopy()
+		grClone.Labels = spec.Labels
+		grClone.Spec = grSpec
+		grClone.Spec.AWSName = gatewayRoute.Spec.AWSName
+		grClone.Spec.MeshRef = gatewayRoute.Spec.MeshRef
+		grClone.Spec.VirtualGatewayRef = gatewayRoute.Spec.VirtualGatewayRef
+		_, err = ar.appmeshClient.AppmeshV1beta2().GatewayRoutes(canary.Namespace).Update(context.TODO(), grClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("GatewayRoute %s update error: %w", apexName, err)
+		}
+		ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("GatewayRoute %s updated", apexName)
+	}
+	return nil
+}
+
 // GetRoutes returns the destinations weight for primary and canary
 func (ar *AppMeshv1beta2Router) GetRoutes(canary *flaggerv1.Canary) (
 	primaryWeight int,
@@ -405,7 +514,7 @@ func (ar *AppMeshv1beta2Router) GetRoutes(canary *flaggerv1.Canary) (
 	mirrored bool,
 	err error,
 ) {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(ar.primarySuffix)
 	virtualRouter, err := ar.appmeshClient.AppmeshV1beta2().VirtualRouters(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
 		err = fmt.Errorf("VirtualRouter %s get query error: %w", apexName, err)
@@ -428,8 +537,8 @@ func (ar *AppMeshv1beta2Router) GetRoutes(canary *flaggerv1.Canary) (
 	}
 
 	if primaryWeight == 0 && canaryWeight == 0 {
-		err = fmt.Errorf("VirtualRouter %s does not contain routes for %s-primary and %s-canary",
-			apexName, apexName, apexName)
+		err = fmt.Errorf("VirtualRouter %s does not contain routes for %s and %s",
+			apexName, primaryName, canaryName)
 	}
 
 	mirrored = false
@@ -444,7 +553,7 @@ func (ar *AppMeshv1beta2Router) SetRoutes(
 	canaryWeight int,
 	_ bool,
 ) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(ar.primarySuffix)
 	virtualRouter, err := ar.appmeshClient.AppmeshV1beta2().VirtualRouters(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("VirtualRouter %s get query error: %w", apexName, err)
@@ -565,7 +674,7 @@ func (ar *AppMeshv1beta2Router) makeHeaders(canary *flaggerv1.Canary) []appmeshv
 }
 
 func (ar *AppMeshv1beta2Router) getProtocol(canary *flaggerv1.Canary) appmeshv1.PortProtocol {
-	if strings.Contains(canary.Spec.Service.PortName, "grpc") {
+	if strings.Contains(canary.Spec.Service.PortName, "grpc") || canary.Spec.Service.AppProtocol == "grpc" {
 		return appmeshv1.PortProtocolGRPC
 	}
 	return appmeshv1.PortProtocolHTTP
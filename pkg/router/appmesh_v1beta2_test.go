@@ -25,8 +25,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appmeshv1 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta2"
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
 func TestAppmeshv1beta2Router_Reconcile(t *testing.T) {
@@ -38,7 +41,7 @@ func TestAppmeshv1beta2Router_Reconcile(t *testing.T) {
 		kubeClient:    mocks.kubeClient,
 	}
 
-	apexName, primaryName, canaryName := mocks.appmeshCanary.GetServiceNames()
+	apexName, primaryName, canaryName := mocks.appmeshCanary.GetServiceNames("")
 	err := router.Reconcile(mocks.appmeshCanary)
 	require.NoError(t, err)
 
@@ -141,7 +144,7 @@ func TestAppmesv1beta2hRouter_ABTest(t *testing.T) {
 		kubeClient:    mocks.kubeClient,
 	}
 
-	apexName, _, _ := mocks.abtest.GetServiceNames()
+	apexName, _, _ := mocks.abtest.GetServiceNames("")
 	err := router.Reconcile(mocks.abtest)
 	require.NoError(t, err)
 
@@ -166,7 +169,7 @@ func TestAppmeshv1beta2Router_Gateway(t *testing.T) {
 		kubeClient:    mocks.kubeClient,
 	}
 
-	apexName, _, _ := mocks.appmeshCanary.GetServiceNames()
+	apexName, _, _ := mocks.appmeshCanary.GetServiceNames("")
 	err := router.Reconcile(mocks.appmeshCanary)
 	require.NoError(t, err)
 
@@ -181,3 +184,50 @@ func TestAppmeshv1beta2Router_Gateway(t *testing.T) {
 	retries := vs.Annotations["gateway.appmesh.k8s.aws/retries"]
 	assert.Equal(t, strconv.Itoa(mocks.appmeshCanary.Spec.Service.Retries.Attempts), retries)
 }
+
+func TestAppmeshv1beta2Router_GatewayRoute(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &AppMeshv1beta2Router{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		appmeshClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	canary := mocks.appmeshCanary.DeepCopy()
+	canary.Spec.Service.GatewayRoute = &flaggerv1.AppMeshGatewayRoute{
+		Labels:   map[string]string{"gateway": "ingress-gw"},
+		Hostname: "app.example.com",
+	}
+	apexName, _, _ := canary.GetServiceNames("")
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	gr, err := router.appmeshClient.AppmeshV1beta2().GatewayRoutes("default").Get(context.TODO(), apexName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "ingress-gw", gr.Labels["gateway"])
+	require.NotNil(t, gr.Spec.HTTPRoute)
+	assert.Equal(t, "/", *gr.Spec.HTTPRoute.Match.Prefix)
+	assert.Equal(t, "app.example.com", *gr.Spec.HTTPRoute.Match.Hostname.Exact)
+	assert.Equal(t, apexName, gr.Spec.HTTPRoute.Action.Target.VirtualService.VirtualServiceRef.Name)
+
+	// the fields populated by the App Mesh controller are kept on update
+	gr.Spec.MeshRef = &appmeshv1.MeshReference{Name: "global"}
+	_, err = router.appmeshClient.AppmeshV1beta2().GatewayRoutes("default").Update(context.TODO(), gr, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	canary.Spec.Service.GatewayRoute.Prefix = "/api"
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+	gr, err = router.appmeshClient.AppmeshV1beta2().GatewayRoutes("default").Get(context.TODO(), apexName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "/api", *gr.Spec.HTTPRoute.Match.Prefix)
+	assert.Equal(t, "global", gr.Spec.MeshRef.Name)
+
+	// the gateway route is removed with the canary spec
+	canary.Spec.Service.GatewayRoute = nil
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+	_, err = router.appmeshClient.AppmeshV1beta2().GatewayRoutes("default").Get(context.TODO(), apexName, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
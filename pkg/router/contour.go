@@ -40,13 +40,14 @@ type ContourRouter struct {
 	flaggerClient clientset.Interface
 	logger        *zap.SugaredLogger
 	ingressClass  string
+	primarySuffix string
 }
 
 // Reconcile creates or updates the HTTP proxy
 func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 	const annotation = "projectcontour.io/ingress.class"
 
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(cr.primarySuffix)
 
 	newSpec := contourv1.HTTPProxySpec{
 		Routes: []contourv1.Route{
@@ -60,9 +61,10 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 				RetryPolicy:   cr.makeRetryPolicy(canary),
 				Services: []contourv1.Service{
 					{
-						Name:   primaryName,
-						Port:   int(canary.Spec.Service.Port),
-						Weight: uint32(100),
+						Name:     primaryName,
+						Port:     int(canary.Spec.Service.Port),
+						Protocol: cr.makeProtocol(canary),
+						Weight:   uint32(100),
 						RequestHeadersPolicy: &contourv1.HeadersPolicy{
 							Set: []contourv1.HeaderValue{
 								cr.makeLinkerdHeaderValue(canary, primaryName),
@@ -70,9 +72,10 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 						},
 					},
 					{
-						Name:   canaryName,
-						Port:   int(canary.Spec.Service.Port),
-						Weight: uint32(0),
+						Name:     canaryName,
+						Port:     int(canary.Spec.Service.Port),
+						Protocol: cr.makeProtocol(canary),
+						Weight:   uint32(0),
 						RequestHeadersPolicy: &contourv1.HeadersPolicy{
 							Set: []contourv1.HeaderValue{
 								cr.makeLinkerdHeaderValue(canary, canaryName),
@@ -93,9 +96,10 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 					RetryPolicy:   cr.makeRetryPolicy(canary),
 					Services: []contourv1.Service{
 						{
-							Name:   primaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(100),
+							Name:     primaryName,
+							Port:     int(canary.Spec.Service.Port),
+							Protocol: cr.makeProtocol(canary),
+							Weight:   uint32(100),
 							RequestHeadersPolicy: &contourv1.HeadersPolicy{
 								Set: []contourv1.HeaderValue{
 									cr.makeLinkerdHeaderValue(canary, primaryName),
@@ -103,9 +107,10 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 							},
 						},
 						{
-							Name:   canaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(0),
+							Name:     canaryName,
+							Port:     int(canary.Spec.Service.Port),
+							Protocol: cr.makeProtocol(canary),
+							Weight:   uint32(0),
 							RequestHeadersPolicy: &contourv1.HeadersPolicy{
 								Set: []contourv1.HeaderValue{
 									cr.makeLinkerdHeaderValue(canary, canaryName),
@@ -124,9 +129,10 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 					RetryPolicy:   cr.makeRetryPolicy(canary),
 					Services: []contourv1.Service{
 						{
-							Name:   primaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(100),
+							Name:     primaryName,
+							Port:     int(canary.Spec.Service.Port),
+							Protocol: cr.makeProtocol(canary),
+							Weight:   uint32(100),
 							RequestHeadersPolicy: &contourv1.HeadersPolicy{
 								Set: []contourv1.HeaderValue{
 									cr.makeLinkerdHeaderValue(canary, primaryName),
@@ -134,9 +140,10 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 							},
 						},
 						{
-							Name:   canaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(0),
+							Name:     canaryName,
+							Port:     int(canary.Spec.Service.Port),
+							Protocol: cr.makeProtocol(canary),
+							Weight:   uint32(0),
 							RequestHeadersPolicy: &contourv1.HeadersPolicy{
 								Set: []contourv1.HeaderValue{
 									cr.makeLinkerdHeaderValue(canary, canaryName),
@@ -187,12 +194,12 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return fmt.Errorf("HTTPProxy %s.%s get query error: %w", apexName, canary.Namespace, err)
 	}
 
-	// update HTTPProxy but keep the original destination weights
+	// update HTTPProxy but keep the original destination weights and mirroring
 	if proxy != nil {
 		if diff := cmp.Diff(
 			newSpec,
 			proxy.Spec,
-			cmpopts.IgnoreFields(contourv1.Service{}, "Weight"),
+			cmpopts.IgnoreFields(contourv1.Service{}, "Weight", "Mirror"),
 		); diff != "" {
 			clone := proxy.DeepCopy()
 			clone.Spec = newSpec
@@ -216,7 +223,7 @@ func (cr *ContourRouter) GetRoutes(canary *flaggerv1.Canary) (
 	mirrored bool,
 	err error,
 ) {
-	apexName, primaryName, _ := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(cr.primarySuffix)
 
 	proxy, err := cr.contourClient.ProjectcontourV1().HTTPProxies(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
@@ -229,6 +236,12 @@ func (cr *ContourRouter) GetRoutes(canary *flaggerv1.Canary) (
 		return
 	}
 
+	for _, dst := range proxy.Spec.Routes[0].Services {
+		if dst.Name == canaryName && dst.Mirror {
+			mirrored = true
+		}
+	}
+
 	for _, dst := range proxy.Spec.Routes[0].Services {
 		if dst.Name == primaryName {
 			primaryWeight = int(dst.Weight)
@@ -240,14 +253,15 @@ func (cr *ContourRouter) GetRoutes(canary *flaggerv1.Canary) (
 	return
 }
 
-// SetRoutes updates the service weight for primary and canary
+// SetRoutes updates the service weight for primary and canary,
+// when mirroring is enabled the canary service receives a copy of the primary traffic
 func (cr *ContourRouter) SetRoutes(
 	canary *flaggerv1.Canary,
 	primaryWeight int,
 	canaryWeight int,
-	_ bool,
+	mirrored bool,
 ) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(cr.primarySuffix)
 
 	if primaryWeight == 0 && canaryWeight == 0 {
 		return fmt.Errorf("HTTPProxy %s.%s update failed: no valid weights", apexName, canary.Namespace)
@@ -270,9 +284,10 @@ func (cr *ContourRouter) SetRoutes(
 				RetryPolicy:   cr.makeRetryPolicy(canary),
 				Services: []contourv1.Service{
 					{
-						Name:   primaryName,
-						Port:   int(canary.Spec.Service.Port),
-						Weight: uint32(primaryWeight),
+						Name:     primaryName,
+						Port:     int(canary.Spec.Service.Port),
+						Protocol: cr.makeProtocol(canary),
+						Weight:   uint32(primaryWeight),
 						RequestHeadersPolicy: &contourv1.HeadersPolicy{
 							Set: []contourv1.HeaderValue{
 								cr.makeLinkerdHeaderValue(canary, primaryName),
@@ -280,9 +295,11 @@ func (cr *ContourRouter) SetRoutes(
 						},
 					},
 					{
-						Name:   canaryName,
-						Port:   int(canary.Spec.Service.Port),
-						Weight: uint32(canaryWeight),
+						Name:     canaryName,
+						Port:     int(canary.Spec.Service.Port),
+						Protocol: cr.makeProtocol(canary),
+						Weight:   uint32(canaryWeight),
+						Mirror:   mirrored,
 						RequestHeadersPolicy: &contourv1.HeadersPolicy{
 							Set: []contourv1.HeaderValue{
 								cr.makeLinkerdHeaderValue(canary, canaryName),
@@ -302,9 +319,10 @@ func (cr *ContourRouter) SetRoutes(
 					RetryPolicy:   cr.makeRetryPolicy(canary),
 					Services: []contourv1.Service{
 						{
-							Name:   primaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(primaryWeight),
+							Name:     primaryName,
+							Port:     int(canary.Spec.Service.Port),
+							Protocol: cr.makeProtocol(canary),
+							Weight:   uint32(primaryWeight),
 							RequestHeadersPolicy: &contourv1.HeadersPolicy{
 								Set: []contourv1.HeaderValue{
 									cr.makeLinkerdHeaderValue(canary, primaryName),
@@ -312,9 +330,10 @@ func (cr *ContourRouter) SetRoutes(
 							},
 						},
 						{
-							Name:   canaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(canaryWeight),
+							Name:     canaryName,
+							Port:     int(canary.Spec.Service.Port),
+							Protocol: cr.makeProtocol(canary),
+							Weight:   uint32(canaryWeight),
 							RequestHeadersPolicy: &contourv1.HeadersPolicy{
 								Set: []contourv1.HeaderValue{
 									cr.makeLinkerdHeaderValue(canary, canaryName),
@@ -333,9 +352,10 @@ func (cr *ContourRouter) SetRoutes(
 					RetryPolicy:   cr.makeRetryPolicy(canary),
 					Services: []contourv1.Service{
 						{
-							Name:   primaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(100),
+							Name:     primaryName,
+							Port:     int(canary.Spec.Service.Port),
+							Protocol: cr.makeProtocol(canary),
+							Weight:   uint32(100),
 							RequestHeadersPolicy: &contourv1.HeadersPolicy{
 								Set: []contourv1.HeaderValue{
 									cr.makeLinkerdHeaderValue(canary, primaryName),
@@ -343,9 +363,10 @@ func (cr *ContourRouter) SetRoutes(
 							},
 						},
 						{
-							Name:   canaryName,
-							Port:   int(canary.Spec.Service.Port),
-							Weight: uint32(0),
+							Name:     canaryName,
+							Port:     int(canary.Spec.Service.Port),
+							Protocol: cr.makeProtocol(canary),
+							Weight:   uint32(0),
 							RequestHeadersPolicy: &contourv1.HeadersPolicy{
 								Set: []contourv1.HeaderValue{
 									cr.makeLinkerdHeaderValue(canary, canaryName),
@@ -436,6 +457,19 @@ func (cr *ContourRouter) makeRetryPolicy(canary *flaggerv1.Canary) *contourv1.Re
 	return nil
 }
 
+// makeProtocol maps the app protocol of the service port to the Contour upstream protocol
+func (cr *ContourRouter) makeProtocol(canary *flaggerv1.Canary) *string {
+	switch canary.Spec.Service.AppProtocol {
+	case "grpc", "h2c", "kubernetes.io/h2c":
+		return stringp("h2c")
+	case "h2", "http2":
+		return stringp("h2")
+	case "https", "tls":
+		return stringp("tls")
+	}
+	return nil
+}
+
 func (cr *ContourRouter) makeLinkerdHeaderValue(canary *flaggerv1.Canary, serviceName string) contourv1.HeaderValue {
 	return contourv1.HeaderValue{
 		Name:  "l5d-dst-override",
@@ -90,6 +90,36 @@ func TestContourRouter_Reconcile(t *testing.T) {
 	assert.Equal(t, "test", proxy.Spec.Routes[0].Conditions[0].Header.Exact)
 }
 
+func TestContourRouter_AppProtocol(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ContourRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		contourClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.AppProtocol = "grpc"
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	proxy, err := router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	for _, svc := range proxy.Spec.Routes[0].Services {
+		require.NotNil(t, svc.Protocol)
+		assert.Equal(t, "h2c", *svc.Protocol)
+	}
+
+	// the upstream protocol is kept when shifting traffic
+	err = router.SetRoutes(canary, 50, 50, false)
+	require.NoError(t, err)
+
+	proxy, err = router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "h2c", *proxy.Spec.Routes[0].Services[1].Protocol)
+}
+
 func TestContourRouter_Routes(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &ContourRouter{
@@ -153,3 +183,48 @@ func TestContourRouter_Routes(t *testing.T) {
 	primary = proxy.Spec.Routes[1].Services[0]
 	assert.Equal(t, uint32(100), primary.Weight)
 }
+
+func TestContourRouter_Mirror(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ContourRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		contourClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	// mirror the primary traffic to the canary
+	err = router.SetRoutes(mocks.canary, 100, 0, true)
+	require.NoError(t, err)
+
+	proxy, err := router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	services := proxy.Spec.Routes[0].Services
+	require.Len(t, services, 2)
+	assert.Equal(t, uint32(100), services[0].Weight)
+	assert.False(t, services[0].Mirror)
+	assert.Equal(t, "podinfo-canary", services[1].Name)
+	assert.True(t, services[1].Mirror)
+
+	// the mirror policy is kept on reconciliation
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+	assert.True(t, m)
+
+	// stop mirroring when shifting traffic
+	err = router.SetRoutes(mocks.canary, 90, 10, false)
+	require.NoError(t, err)
+
+	_, c, m, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 10, c)
+	assert.False(t, m)
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 
@@ -30,10 +31,12 @@ import (
 type Factory struct {
 	kubeConfig               *restclient.Config
 	kubeClient               kubernetes.Interface
+	dynamicClient            dynamic.Interface
 	meshClient               clientset.Interface
 	flaggerClient            clientset.Interface
 	ingressAnnotationsPrefix string
 	ingressClass             string
+	primarySuffix            string
 	logger                   *zap.SugaredLogger
 }
 
@@ -54,10 +57,20 @@ func NewFactory(kubeConfig *restclient.Config, kubeClient kubernetes.Interface,
 	}
 }
 
+// SetDynamicClient sets the client of the Knative Services read by the Knative router
+func (factory *Factory) SetDynamicClient(dynamicClient dynamic.Interface) {
+	factory.dynamicClient = dynamicClient
+}
+
+// SetPrimarySuffix sets the suffix of the primary services of the canaries that don't set spec.primarySuffix
+func (factory *Factory) SetPrimarySuffix(suffix string) {
+	factory.primarySuffix = suffix
+}
+
 // KubernetesRouter returns a KubernetesRouter interface implementation
 func (factory *Factory) KubernetesRouter(kind string, labelSelector string, labelValue string, ports map[string]int32) KubernetesRouter {
 	switch kind {
-	case "Service":
+	case "Service", flaggerv1.KruiseDaemonSetKind, flaggerv1.KnativeServiceKind:
 		return &KubernetesNoopRouter{}
 	default: // Daemonset or Deployment
 		return &KubernetesDefaultRouter{
@@ -67,6 +80,7 @@ func (factory *Factory) KubernetesRouter(kind string, labelSelector string, labe
 			labelSelector: labelSelector,
 			labelValue:    labelValue,
 			ports:         ports,
+			primarySuffix: factory.primarySuffix,
 		}
 	}
 }
@@ -81,6 +95,7 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			kubeClient:    factory.kubeClient,
 			appmeshClient: factory.meshClient,
 			labelSelector: labelSelector,
+			primarySuffix: factory.primarySuffix,
 		}
 	case provider == flaggerv1.AppMeshProvider:
 		return &AppMeshRouter{
@@ -88,14 +103,19 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			flaggerClient: factory.flaggerClient,
 			kubeClient:    factory.kubeClient,
 			appmeshClient: factory.meshClient,
+			primarySuffix: factory.primarySuffix,
 		}
 	case provider == flaggerv1.LinkerdProvider:
-		return &SmiRouter{
-			logger:        factory.logger,
-			flaggerClient: factory.flaggerClient,
-			kubeClient:    factory.kubeClient,
-			smiClient:     factory.meshClient,
-			targetMesh:    flaggerv1.LinkerdProvider,
+		return &LinkerdRouter{
+			SmiRouter: SmiRouter{
+				logger:        factory.logger,
+				flaggerClient: factory.flaggerClient,
+				kubeClient:    factory.kubeClient,
+				smiClient:     factory.meshClient,
+				targetMesh:    flaggerv1.LinkerdProvider,
+				primarySuffix: factory.primarySuffix,
+			},
+			linkerdClient: factory.meshClient,
 		}
 	case provider == flaggerv1.IstioProvider:
 		return &IstioRouter{
@@ -103,6 +123,18 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			flaggerClient: factory.flaggerClient,
 			kubeClient:    factory.kubeClient,
 			istioClient:   factory.meshClient,
+			labelSelector: labelSelector,
+			primarySuffix: factory.primarySuffix,
+		}
+	case strings.HasPrefix(provider, flaggerv1.SMIProvider+":v1alpha4"):
+		mesh := strings.TrimPrefix(strings.TrimPrefix(provider, flaggerv1.SMIProvider+":v1alpha4"), ":")
+		return &Smiv1alpha4Router{
+			logger:        factory.logger,
+			flaggerClient: factory.flaggerClient,
+			kubeClient:    factory.kubeClient,
+			smiClient:     factory.meshClient,
+			targetMesh:    mesh,
+			primarySuffix: factory.primarySuffix,
 		}
 	case strings.HasPrefix(provider, flaggerv1.SMIProvider):
 		mesh := strings.TrimPrefix(provider, flaggerv1.SMIProvider+":")
@@ -112,6 +144,7 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			kubeClient:    factory.kubeClient,
 			smiClient:     factory.meshClient,
 			targetMesh:    mesh,
+			primarySuffix: factory.primarySuffix,
 		}
 	case provider == flaggerv1.ContourProvider:
 		return &ContourRouter{
@@ -120,6 +153,7 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			kubeClient:    factory.kubeClient,
 			contourClient: factory.meshClient,
 			ingressClass:  factory.ingressClass,
+			primarySuffix: factory.primarySuffix,
 		}
 	case strings.HasPrefix(provider, flaggerv1.GlooProvider):
 		upstreamDiscoveryNs := flaggerv1.GlooProvider + "-system"
@@ -132,31 +166,50 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			kubeClient:          factory.kubeClient,
 			glooClient:          factory.meshClient,
 			upstreamDiscoveryNs: upstreamDiscoveryNs,
+			primarySuffix:       factory.primarySuffix,
 		}
 	case provider == flaggerv1.NGINXProvider:
 		return &IngressRouter{
 			logger:            factory.logger,
 			kubeClient:        factory.kubeClient,
 			annotationsPrefix: factory.ingressAnnotationsPrefix,
+			primarySuffix:     factory.primarySuffix,
 		}
 	case provider == flaggerv1.SkipperProvider:
 		return &SkipperRouter{
-			logger:     factory.logger,
-			kubeClient: factory.kubeClient,
+			logger:        factory.logger,
+			kubeClient:    factory.kubeClient,
+			primarySuffix: factory.primarySuffix,
 		}
 	case provider == flaggerv1.TraefikProvider:
 		return &TraefikRouter{
 			logger:        factory.logger,
 			traefikClient: factory.meshClient,
+			primarySuffix: factory.primarySuffix,
+		}
+	case strings.HasPrefix(provider, flaggerv1.GatewayAPIProvider):
+		return &GatewayAPIRouter{
+			logger:           factory.logger,
+			flaggerClient:    factory.flaggerClient,
+			kubeClient:       factory.kubeClient,
+			gatewayAPIClient: factory.meshClient,
+			primarySuffix:    factory.primarySuffix,
 		}
 	case provider == flaggerv1.KubernetesProvider:
 		return &NopRouter{}
+	case provider == flaggerv1.KnativeProvider:
+		return &KnativeRouter{
+			logger:        factory.logger,
+			dynamicClient: factory.dynamicClient,
+		}
 	default:
 		return &IstioRouter{
 			logger:        factory.logger,
 			flaggerClient: factory.flaggerClient,
 			kubeClient:    factory.kubeClient,
 			istioClient:   factory.meshClient,
+			labelSelector: labelSelector,
+			primarySuffix: factory.primarySuffix,
 		}
 	}
 }
@@ -0,0 +1,319 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	gatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// GatewayAPIRouter routes the canaries with a Gateway API HTTPRoute attached to the apex service,
+// the meshes that implement GAMMA apply the route to the clients of the service
+type GatewayAPIRouter struct {
+	kubeClient       kubernetes.Interface
+	flaggerClient    clientset.Interface
+	gatewayAPIClient clientset.Interface
+	logger           *zap.SugaredLogger
+	primarySuffix    string
+}
+
+// Reconcile creates or updates the HTTPRoute of the apex service but keeps the backend weights
+func (gr *GatewayAPIRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames(gr.primarySuffix)
+	route, err := gr.gatewayAPIClient.GatewayAPIV1beta1().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		route = &gatewayapiv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: gr.makeHTTPRouteSpec(canary, 100, 0),
+		}
+		_, err = gr.gatewayAPIClient.GatewayAPIV1beta1().HTTPRoutes(canary.Namespace).Create(context.TODO(), route, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("HTTPRoute %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+		gr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("HTTPRoute %s.%s created", apexName, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	primaryWeight, canaryWeight := 100, 0
+	if p, c, ok := gatewayAPIWeights(route, primaryName, canaryName); ok {
+		primaryWeight, canaryWeight = p, c
+	}
+	spec := gr.makeHTTPRouteSpec(canary, primaryWeight, canaryWeight)
+	if diff := cmp.Diff(spec, route.Spec); diff != "" {
+		routeClone := route.DeepCopy()
+		routeClone.Spec = spec
+		_, err = gr.gatewayAPIClient.GatewayAPIV1beta1().HTTPRoutes(canary.Namespace).Update(context.TODO(), routeClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("HTTPRoute %s.%s update error: %w", apexName, canary.Namespace, err)
+		}
+		gr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("HTTPRoute %s.%s updated", apexName, canary.Namespace)
+	}
+	return nil
+}
+
+// GetRoutes returns the destinations weight for primary and canary
+func (gr *GatewayAPIRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames(gr.primarySuffix)
+	route, err := gr.gatewayAPIClient.GatewayAPIV1beta1().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("HTTPRoute %s.%s get query error: %w", apexName, canary.Namespace, err)
+		return
+	}
+
+	var ok bool
+	primaryWeight, canaryWeight, ok = gatewayAPIWeights(route, primaryName, canaryName)
+	if !ok {
+		err = fmt.Errorf("HTTPRoute %s.%s does not contain routes for %s and %s",
+			apexName, canary.Namespace, primaryName, canaryName)
+	}
+	return
+}
+
+// SetRoutes updates the destinations weight for primary and canary,
+// the requests that don't match the A/B testing conditions are always routed to the primary
+func (gr *GatewayAPIRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	apexName, _, _ := canary.GetServiceNames(gr.primarySuffix)
+	route, err := gr.gatewayAPIClient.GatewayAPIV1beta1().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	routeClone := route.DeepCopy()
+	routeClone.Spec = gr.makeHTTPRouteSpec(canary, primaryWeight, canaryWeight)
+	_, err = gr.gatewayAPIClient.GatewayAPIV1beta1().HTTPRoutes(canary.Namespace).Update(context.TODO(), routeClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s update error: %w", apexName, canary.Namespace, err)
+	}
+	return nil
+}
+
+// Finalize leaves the HTTPRoute to be garbage collected with the canary
+func (gr *GatewayAPIRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// makeHTTPRouteSpec attaches the route to the apex service and routes the requests to the weighted
+// primary and canary backends, with A/B testing only the matched requests are routed to the canary
+func (gr *GatewayAPIRouter) makeHTTPRouteSpec(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) gatewayapiv1beta1.HTTPRouteSpec {
+	apexName, primaryName, canaryName := canary.GetServiceNames(gr.primarySuffix)
+	port := canary.Spec.Service.Port
+	// the empty group is the core group of the services
+	coreGroup := ""
+
+	backend := func(name string, weight int) gatewayapiv1beta1.HTTPBackendRef {
+		return gatewayapiv1beta1.HTTPBackendRef{
+			Group:  &coreGroup,
+			Kind:   stringp("Service"),
+			Name:   name,
+			Port:   int32p(port),
+			Weight: int32p(int32(weight)),
+		}
+	}
+
+	// the empty matches are set to the defaults of the API server to avoid updates on every reconciliation
+	pathPrefix := gatewayapiv1beta1.PathMatchPathPrefix
+	defaultMatches := []gatewayapiv1beta1.HTTPRouteMatch{
+		{
+			Path: &gatewayapiv1beta1.HTTPPathMatch{
+				Type:  &pathPrefix,
+				Value: stringp("/"),
+			},
+		},
+	}
+
+	spec := gatewayapiv1beta1.HTTPRouteSpec{
+		ParentRefs: []gatewayapiv1beta1.ParentReference{
+			{
+				Group: &coreGroup,
+				Kind:  stringp("Service"),
+				Name:  apexName,
+				Port:  int32p(port),
+			},
+		},
+		Rules: []gatewayapiv1beta1.HTTPRouteRule{
+			{
+				Matches: defaultMatches,
+				BackendRefs: []gatewayapiv1beta1.HTTPBackendRef{
+					backend(primaryName, primaryWeight),
+					backend(canaryName, canaryWeight),
+				},
+			},
+		},
+	}
+
+	if len(canary.GetAnalysis().Match) > 0 {
+		spec.Rules[0].Matches = makeGatewayAPIMatches(canary.GetAnalysis().Match)
+		spec.Rules = append(spec.Rules, gatewayapiv1beta1.HTTPRouteRule{
+			Matches: defaultMatches,
+			BackendRefs: []gatewayapiv1beta1.HTTPBackendRef{
+				backend(primaryName, 100),
+			},
+		})
+	}
+	spec.Rules[0].SessionPersistence = makeGatewayAPISessionPersistence(canary.GetAnalysis().SessionAffinity)
+	return spec
+}
+
+// makeGatewayAPISessionPersistence returns the cookie session persistence of the weighted rule,
+// so that the users stay on the version they were first routed to
+func makeGatewayAPISessionPersistence(affinity *flaggerv1.SessionAffinity) *gatewayapiv1beta1.SessionPersistence {
+	if affinity == nil {
+		return nil
+	}
+	persistenceType := gatewayapiv1beta1.CookieBasedSessionPersistence
+	lifetime := gatewayapiv1beta1.SessionCookieLifetimeType
+	persistence := &gatewayapiv1beta1.SessionPersistence{
+		SessionName: stringp(affinity.CookieName),
+		Type:        &persistenceType,
+	}
+	if affinity.MaxAge > 0 {
+		lifetime = gatewayapiv1beta1.PermanentCookieLifetimeType
+		timeout := gatewayAPIDuration(affinity.MaxAge)
+		persistence.AbsoluteTimeout = &timeout
+	}
+	persistence.CookieConfig = &gatewayapiv1beta1.CookieConfig{LifetimeType: &lifetime}
+	return persistence
+}
+
+// gatewayAPIDuration formats the seconds in hours, minutes and seconds,
+// the Gateway API durations are limited to five digits per unit
+func gatewayAPIDuration(seconds int) gatewayapiv1beta1.Duration {
+	var d strings.Builder
+	if h := seconds / 3600; h > 0 {
+		fmt.Fprintf(&d, "%dh", h)
+	}
+	if m := seconds % 3600 / 60; m > 0 {
+		fmt.Fprintf(&d, "%dm", m)
+	}
+	if s := seconds % 60; s > 0 || d.Len() == 0 {
+		fmt.Fprintf(&d, "%ds", s)
+	}
+	return gatewayapiv1beta1.Duration(d.String())
+}
+
+// gatewayAPIWeights returns the backend weights of the first rule,
+// false is returned when the rule doesn't route to the primary and the canary
+func gatewayAPIWeights(route *gatewayapiv1beta1.HTTPRoute, primaryName string, canaryName string) (int, int, bool) {
+	primaryWeight, canaryWeight := -1, -1
+	if len(route.Spec.Rules) > 0 {
+		for _, backend := range route.Spec.Rules[0].BackendRefs {
+			if backend.Weight == nil {
+				continue
+			}
+			if backend.Name == primaryName {
+				primaryWeight = int(*backend.Weight)
+			}
+			if backend.Name == canaryName {
+				canaryWeight = int(*backend.Weight)
+			}
+		}
+	}
+	if primaryWeight == -1 || canaryWeight == -1 {
+		return 0, 0, false
+	}
+	return primaryWeight, canaryWeight, true
+}
+
+// makeGatewayAPIMatches converts the analysis matches to HTTPRoute matches,
+// the prefix and suffix matches are converted to regular expressions
+func makeGatewayAPIMatches(matches []istiov1alpha3.HTTPMatchRequest) []gatewayapiv1beta1.HTTPRouteMatch {
+	var result []gatewayapiv1beta1.HTTPRouteMatch
+	for _, match := range matches {
+		var m gatewayapiv1beta1.HTTPRouteMatch
+		for _, name := range sortedKeys(match.Headers) {
+			matchType, value := makeGatewayAPIMatchValue(match.Headers[name])
+			m.Headers = append(m.Headers, gatewayapiv1beta1.HTTPHeaderMatch{Type: &matchType, Name: name, Value: value})
+		}
+		for _, name := range sortedKeys(match.QueryParams) {
+			matchType, value := makeGatewayAPIMatchValue(match.QueryParams[name])
+			m.QueryParams = append(m.QueryParams, gatewayapiv1beta1.HTTPQueryParamMatch{Type: &matchType, Name: name, Value: value})
+		}
+		if match.Uri != nil {
+			m.Path = makeGatewayAPIPathMatch(*match.Uri)
+		}
+		if match.Method != nil && match.Method.Exact != "" {
+			m.Method = stringp(match.Method.Exact)
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+func makeGatewayAPIMatchValue(sm istiov1alpha1.StringMatch) (gatewayapiv1beta1.MatchType, string) {
+	switch {
+	case sm.Prefix != "":
+		return gatewayapiv1beta1.MatchRegularExpression, "^" + regexp.QuoteMeta(sm.Prefix) + ".*"
+	case sm.Suffix != "":
+		return gatewayapiv1beta1.MatchRegularExpression, ".*" + regexp.QuoteMeta(sm.Suffix) + "$"
+	case sm.Regex != "":
+		return gatewayapiv1beta1.MatchRegularExpression, sm.Regex
+	default:
+		return gatewayapiv1beta1.MatchExact, sm.Exact
+	}
+}
+
+func makeGatewayAPIPathMatch(sm istiov1alpha1.StringMatch) *gatewayapiv1beta1.HTTPPathMatch {
+	matchType, value := gatewayapiv1beta1.PathMatchExact, sm.Exact
+	switch {
+	case sm.Prefix != "":
+		matchType, value = gatewayapiv1beta1.PathMatchPathPrefix, sm.Prefix
+	case sm.Suffix != "":
+		matchType, value = gatewayapiv1beta1.PathMatchRegularExpression, ".*"+regexp.QuoteMeta(sm.Suffix)+"$"
+	case sm.Regex != "":
+		matchType, value = gatewayapiv1beta1.PathMatchRegularExpression, sm.Regex
+	}
+	return &gatewayapiv1beta1.HTTPPathMatch{Type: &matchType, Value: &value}
+}
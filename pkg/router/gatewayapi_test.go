@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	gatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1beta1"
+)
+
+func TestGatewayAPIRouter_Routes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &GatewayAPIRouter{
+		logger:           mocks.logger,
+		flaggerClient:    mocks.flaggerClient,
+		gatewayAPIClient: mocks.meshClient,
+		kubeClient:       mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	route, err := mocks.meshClient.GatewayAPIV1beta1().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, route.Spec.ParentRefs, 1)
+	assert.Equal(t, "", *route.Spec.ParentRefs[0].Group)
+	assert.Equal(t, "Service", *route.Spec.ParentRefs[0].Kind)
+	assert.Equal(t, "podinfo", route.Spec.ParentRefs[0].Name)
+	require.Len(t, route.Spec.Rules, 1)
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+
+	// the weights are kept on reconciliation
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+	p, c, _, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+}
+
+func TestGatewayAPIRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &GatewayAPIRouter{
+		logger:           mocks.logger,
+		flaggerClient:    mocks.flaggerClient,
+		gatewayAPIClient: mocks.meshClient,
+		kubeClient:       mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.abtest)
+	require.NoError(t, err)
+
+	route, err := mocks.meshClient.GatewayAPIV1beta1().HTTPRoutes("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, route.Spec.Rules, 2)
+	require.Len(t, route.Spec.Rules[0].Matches, 1)
+	require.Len(t, route.Spec.Rules[0].Matches[0].Headers, 1)
+	header := route.Spec.Rules[0].Matches[0].Headers[0]
+	assert.Equal(t, gatewayapiv1beta1.MatchExact, *header.Type)
+	assert.Equal(t, "x-user-type", header.Name)
+	assert.Equal(t, "test", header.Value)
+	require.Len(t, route.Spec.Rules[1].BackendRefs, 1)
+	assert.Equal(t, "abtest-primary", route.Spec.Rules[1].BackendRefs[0].Name)
+
+	// the matched requests are routed to the canary, the others stay on the primary
+	err = router.SetRoutes(mocks.abtest, 0, 100, false)
+	require.NoError(t, err)
+	p, c, _, err := router.GetRoutes(mocks.abtest)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	route, err = mocks.meshClient.GatewayAPIV1beta1().HTTPRoutes("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(100), *route.Spec.Rules[1].BackendRefs[0].Weight)
+}
+
+func TestGatewayAPIRouter_SessionAffinity(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &GatewayAPIRouter{
+		logger:           mocks.logger,
+		flaggerClient:    mocks.flaggerClient,
+		gatewayAPIClient: mocks.meshClient,
+		kubeClient:       mocks.kubeClient,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Analysis.SessionAffinity = &flaggerv1.SessionAffinity{CookieName: "podinfo-version", MaxAge: 5430}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(canary, 90, 10, false)
+	require.NoError(t, err)
+
+	route, err := mocks.meshClient.GatewayAPIV1beta1().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	persistence := route.Spec.Rules[0].SessionPersistence
+	require.NotNil(t, persistence)
+	assert.Equal(t, "podinfo-version", *persistence.SessionName)
+	assert.Equal(t, gatewayapiv1beta1.CookieBasedSessionPersistence, *persistence.Type)
+	assert.Equal(t, gatewayapiv1beta1.Duration("1h30m30s"), *persistence.AbsoluteTimeout)
+	assert.Equal(t, gatewayapiv1beta1.PermanentCookieLifetimeType, *persistence.CookieConfig.LifetimeType)
+
+	// a session cookie is used without max age
+	canary.Spec.Analysis.SessionAffinity.MaxAge = 0
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	route, err = mocks.meshClient.GatewayAPIV1beta1().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	persistence = route.Spec.Rules[0].SessionPersistence
+	require.NotNil(t, persistence)
+	assert.Nil(t, persistence.AbsoluteTimeout)
+	assert.Equal(t, gatewayapiv1beta1.SessionCookieLifetimeType, *persistence.CookieConfig.LifetimeType)
+
+	// the session persistence is removed along with the session affinity
+	canary.Spec.Analysis.SessionAffinity = nil
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	route, err = mocks.meshClient.GatewayAPIV1beta1().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, route.Spec.Rules[0].SessionPersistence)
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, p)
+	assert.Equal(t, 10, c)
+}
@@ -18,6 +18,7 @@ package router
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	gloov1 "github.com/fluxcd/flagger/pkg/apis/gloo/v1"
@@ -27,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -40,43 +42,23 @@ type GlooRouter struct {
 	flaggerClient       clientset.Interface
 	logger              *zap.SugaredLogger
 	upstreamDiscoveryNs string
+	primarySuffix       string
 }
 
 // Reconcile creates or updates the Gloo Edge route table
 func (gr *GlooRouter) Reconcile(canary *flaggerv1.Canary) error {
-	apexName, _, _ := canary.GetServiceNames()
-	canaryName := fmt.Sprintf("%s-%s-canary-%v", canary.Namespace, apexName, canary.Spec.Service.Port)
-	primaryName := fmt.Sprintf("%s-%s-primary-%v", canary.Namespace, apexName, canary.Spec.Service.Port)
+	if canary.Spec.Service.RouteTable != nil {
+		return gr.reconcileRouteTableRoutes(canary)
+	}
+
+	apexName, _, _ := canary.GetServiceNames(gr.primarySuffix)
 
 	newSpec := gloov1.RouteTableSpec{
 		Routes: []gloov1.Route{
 			{
 				InheritablePathMatchers: true,
 				Matchers:                getMatchers(canary),
-				Action: gloov1.RouteAction{
-					Destination: gloov1.MultiDestination{
-						Destinations: []gloov1.WeightedDestination{
-							{
-								Destination: gloov1.Destination{
-									Upstream: gloov1.ResourceRef{
-										Name:      primaryName,
-										Namespace: gr.upstreamDiscoveryNs,
-									},
-								},
-								Weight: 100,
-							},
-							{
-								Destination: gloov1.Destination{
-									Upstream: gloov1.ResourceRef{
-										Name:      canaryName,
-										Namespace: gr.upstreamDiscoveryNs,
-									},
-								},
-								Weight: 0,
-							},
-						},
-					},
-				},
+				Action:                  gr.makeRouteAction(canary, 100, 0),
 			},
 		},
 	}
@@ -139,8 +121,21 @@ func (gr *GlooRouter) GetRoutes(canary *flaggerv1.Canary) (
 	mirrored bool,
 	err error,
 ) {
+	if ref := canary.Spec.Service.RouteTable; ref != nil {
+		var route *gloov1.Route
+		route, _, err = gr.getRouteTableRoute(canary)
+		if err != nil {
+			return
+		}
+		primaryWeight, canaryWeight = routeWeights(route, gr.upstreamName(canary, "primary"))
+		if primaryWeight == 0 && canaryWeight == 0 {
+			err = fmt.Errorf("RouteTable %s.%s route %s destinations not found", ref.Name, routeTableNamespace(canary), route.Name)
+		}
+		return
+	}
+
 	apexName := canary.Spec.TargetRef.Name
-	primaryName := fmt.Sprintf("%s-%s-primary-%v", canary.Namespace, canary.Spec.TargetRef.Name, canary.Spec.Service.Port)
+	primaryName := fmt.Sprintf("%s-%s-%v", canary.Namespace, canary.GetPrimaryName(canary.Spec.TargetRef.Name, gr.primarySuffix), canary.Spec.Service.Port)
 
 	routeTable, err := gr.glooClient.GatewayV1().RouteTables(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
@@ -171,14 +166,23 @@ func (gr *GlooRouter) SetRoutes(
 	canaryWeight int,
 	_ bool,
 ) error {
-	apexName, _, _ := canary.GetServiceNames()
-	canaryName := fmt.Sprintf("%s-%s-canary-%v", canary.Namespace, apexName, canary.Spec.Service.Port)
-	primaryName := fmt.Sprintf("%s-%s-primary-%v", canary.Namespace, apexName, canary.Spec.Service.Port)
+	apexName, _, _ := canary.GetServiceNames(gr.primarySuffix)
 
 	if primaryWeight == 0 && canaryWeight == 0 {
 		return fmt.Errorf("RoutingRule %s.%s update failed: no valid weights", apexName, canary.Namespace)
 	}
 
+	if canary.Spec.Service.RouteTable != nil {
+		route, index, err := gr.getRouteTableRoute(canary)
+		if err != nil {
+			return err
+		}
+		return gr.patchRouteTable(canary, []jsonPatch{
+			{Op: "test", Path: fmt.Sprintf("/spec/routes/%d/name", index), Value: route.Name},
+			{Op: "add", Path: fmt.Sprintf("/spec/routes/%d/routeAction", index), Value: gr.makeRouteAction(canary, primaryWeight, canaryWeight)},
+		})
+	}
+
 	routeTable, err := gr.glooClient.GatewayV1().RouteTables(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("RouteTable %s.%s query error: %w", apexName, canary.Namespace, err)
@@ -189,30 +193,7 @@ func (gr *GlooRouter) SetRoutes(
 			{
 				InheritablePathMatchers: true,
 				Matchers:                getMatchers(canary),
-				Action: gloov1.RouteAction{
-					Destination: gloov1.MultiDestination{
-						Destinations: []gloov1.WeightedDestination{
-							{
-								Destination: gloov1.Destination{
-									Upstream: gloov1.ResourceRef{
-										Name:      primaryName,
-										Namespace: gr.upstreamDiscoveryNs,
-									},
-								},
-								Weight: uint32(primaryWeight),
-							},
-							{
-								Destination: gloov1.Destination{
-									Upstream: gloov1.ResourceRef{
-										Name:      canaryName,
-										Namespace: gr.upstreamDiscoveryNs,
-									},
-								},
-								Weight: uint32(canaryWeight),
-							},
-						},
-					},
-				},
+				Action:                  gr.makeRouteAction(canary, primaryWeight, canaryWeight),
 			},
 		},
 	}
@@ -224,10 +205,255 @@ func (gr *GlooRouter) SetRoutes(
 	return nil
 }
 
-func (gr *GlooRouter) Finalize(_ *flaggerv1.Canary) error {
+// Finalize routes the named route of an existing route table to the apex upstream
+// and removes the A/B testing route
+func (gr *GlooRouter) Finalize(canary *flaggerv1.Canary) error {
+	ref := canary.Spec.Service.RouteTable
+	if ref == nil {
+		return nil
+	}
+
+	namespace := routeTableNamespace(canary)
+	routeTable, err := gr.glooClient.GatewayV1().RouteTables(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("RouteTable %s.%s get query error: %w", ref.Name, namespace, err)
+	}
+
+	index := routeIndex(routeTable.Spec.Routes, ref.Route)
+	if index < 0 {
+		gr.logger.Warnf("RouteTable %s.%s route %s not found, unable to revert", ref.Name, namespace, ref.Route)
+		return nil
+	}
+
+	ops := []jsonPatch{
+		{Op: "test", Path: fmt.Sprintf("/spec/routes/%d/name", index), Value: ref.Route},
+		{Op: "add", Path: fmt.Sprintf("/spec/routes/%d/routeAction", index), Value: gloov1.RouteAction{
+			Destination: gloov1.MultiDestination{
+				Destinations: []gloov1.WeightedDestination{
+					gr.makeWeightedDestination(gr.upstreamName(canary, ""), 100),
+				},
+			},
+		}},
+	}
+	if abIndex := routeIndex(routeTable.Spec.Routes, ref.Route+"-canary"); abIndex >= 0 {
+		ops = append(ops,
+			jsonPatch{Op: "test", Path: fmt.Sprintf("/spec/routes/%d/name", abIndex), Value: ref.Route + "-canary"},
+			jsonPatch{Op: "remove", Path: fmt.Sprintf("/spec/routes/%d", abIndex)},
+		)
+	}
+	return gr.patchRouteTable(canary, ops)
+}
+
+// reconcileRouteTableRoutes merges the canary routes into an existing route table delegated by a
+// virtual service, the named route gets the primary and canary destinations and the A/B testing
+// route is inserted before it, the routes are only patched when the upstreams or the matchers change
+// so that the weights are kept
+func (gr *GlooRouter) reconcileRouteTableRoutes(canary *flaggerv1.Canary) error {
+	ref := canary.Spec.Service.RouteTable
+	namespace := routeTableNamespace(canary)
+	primaryName, canaryName := gr.upstreamName(canary, "primary"), gr.upstreamName(canary, "canary")
+
+	routeTable, err := gr.glooClient.GatewayV1().RouteTables(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("RouteTable %s.%s get query error: %w", ref.Name, namespace, err)
+	}
+
+	routes := routeTable.Spec.Routes
+	index := routeIndex(routes, ref.Route)
+	if index < 0 {
+		return fmt.Errorf("RouteTable %s.%s does not contain the route %s", ref.Name, namespace, ref.Route)
+	}
+	abIndex := routeIndex(routes, ref.Route+"-canary")
+
+	var ops []jsonPatch
+	if !hasDestinations(routes[index], primaryName, canaryName) {
+		ops = append(ops,
+			jsonPatch{Op: "test", Path: fmt.Sprintf("/spec/routes/%d/name", index), Value: ref.Route},
+			jsonPatch{Op: "add", Path: fmt.Sprintf("/spec/routes/%d/routeAction", index), Value: gr.makeRouteAction(canary, 100, 0)},
+		)
+	}
+
+	// the structural change of the routes list is applied last so that the indexes above stay valid
+	if len(canary.GetAnalysis().Match) > 0 {
+		abRoute := gloov1.Route{
+			Name:     ref.Route + "-canary",
+			Matchers: mergeMatchers(routes[index].Matchers, getMatchers(canary)),
+			Action:   gr.makeRouteAction(canary, 100, 0),
+		}
+		if abIndex < 0 {
+			ops = append(ops,
+				jsonPatch{Op: "test", Path: fmt.Sprintf("/spec/routes/%d/name", index), Value: ref.Route},
+				jsonPatch{Op: "add", Path: fmt.Sprintf("/spec/routes/%d", index), Value: abRoute},
+			)
+		} else if diff := cmp.Diff(
+			abRoute,
+			routes[abIndex],
+			cmpopts.IgnoreFields(gloov1.WeightedDestination{}, "Weight"),
+		); diff != "" {
+			if hasDestinations(routes[abIndex], primaryName, canaryName) {
+				abRoute.Action = routes[abIndex].Action
+			}
+			ops = append(ops,
+				jsonPatch{Op: "test", Path: fmt.Sprintf("/spec/routes/%d/name", abIndex), Value: abRoute.Name},
+				jsonPatch{Op: "replace", Path: fmt.Sprintf("/spec/routes/%d", abIndex), Value: abRoute},
+			)
+		}
+	} else if abIndex >= 0 {
+		ops = append(ops,
+			jsonPatch{Op: "test", Path: fmt.Sprintf("/spec/routes/%d/name", abIndex), Value: ref.Route + "-canary"},
+			jsonPatch{Op: "remove", Path: fmt.Sprintf("/spec/routes/%d", abIndex)},
+		)
+	}
+
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := gr.patchRouteTable(canary, ops); err != nil {
+		return err
+	}
+	gr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("RouteTable %s.%s route %s updated", ref.Name, namespace, ref.Route)
+	return nil
+}
+
+// getRouteTableRoute returns the route of an existing route table that holds the canary weights,
+// the A/B testing route when the analysis has match conditions or the named route otherwise
+func (gr *GlooRouter) getRouteTableRoute(canary *flaggerv1.Canary) (*gloov1.Route, int, error) {
+	ref := canary.Spec.Service.RouteTable
+	namespace := routeTableNamespace(canary)
+
+	routeTable, err := gr.glooClient.GatewayV1().RouteTables(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("RouteTable %s.%s get query error: %w", ref.Name, namespace, err)
+	}
+
+	name := ref.Route
+	if len(canary.GetAnalysis().Match) > 0 {
+		name = ref.Route + "-canary"
+	}
+	index := routeIndex(routeTable.Spec.Routes, name)
+	if index < 0 {
+		return nil, 0, fmt.Errorf("RouteTable %s.%s does not contain the route %s", ref.Name, namespace, name)
+	}
+	return &routeTable.Spec.Routes[index], index, nil
+}
+
+// patchRouteTable applies JSON patch operations to an existing route table,
+// so that the route fields that Flagger doesn't manage are kept as is
+func (gr *GlooRouter) patchRouteTable(canary *flaggerv1.Canary, ops []jsonPatch) error {
+	ref := canary.Spec.Service.RouteTable
+	namespace := routeTableNamespace(canary)
+
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return fmt.Errorf("RouteTable %s.%s patch marshal error: %w", ref.Name, namespace, err)
+	}
+	_, err = gr.glooClient.GatewayV1().RouteTables(namespace).Patch(context.TODO(), ref.Name, types.JSONPatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("RouteTable %s.%s patch error: %w", ref.Name, namespace, err)
+	}
 	return nil
 }
 
+// makeRouteAction returns the weighted destinations of the primary and canary upstreams
+func (gr *GlooRouter) makeRouteAction(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) gloov1.RouteAction {
+	return gloov1.RouteAction{
+		Destination: gloov1.MultiDestination{
+			Destinations: []gloov1.WeightedDestination{
+				gr.makeWeightedDestination(gr.upstreamName(canary, "primary"), primaryWeight),
+				gr.makeWeightedDestination(gr.upstreamName(canary, "canary"), canaryWeight),
+			},
+		},
+	}
+}
+
+func (gr *GlooRouter) makeWeightedDestination(upstream string, weight int) gloov1.WeightedDestination {
+	return gloov1.WeightedDestination{
+		Destination: gloov1.Destination{
+			Upstream: gloov1.ResourceRef{
+				Name:      upstream,
+				Namespace: gr.upstreamDiscoveryNs,
+			},
+		},
+		Weight: uint32(weight),
+	}
+}
+
+// upstreamName returns the name of the upstream discovered by Gloo for the apex,
+// primary or canary service
+func (gr *GlooRouter) upstreamName(canary *flaggerv1.Canary, suffix string) string {
+	apexName, primaryName, canaryName := canary.GetServiceNames(gr.primarySuffix)
+	name := apexName
+	switch suffix {
+	case "primary":
+		name = primaryName
+	case "canary":
+		name = canaryName
+	}
+	return fmt.Sprintf("%s-%s-%v", canary.Namespace, name, canary.Spec.Service.Port)
+}
+
+// jsonPatch is a JSON patch operation
+type jsonPatch struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+func routeTableNamespace(canary *flaggerv1.Canary) string {
+	if ns := canary.Spec.Service.RouteTable.Namespace; ns != "" {
+		return ns
+	}
+	return canary.Namespace
+}
+
+func routeIndex(routes []gloov1.Route, name string) int {
+	for i, r := range routes {
+		if r.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasDestinations returns true if the route action targets only the primary and canary upstreams
+func hasDestinations(route gloov1.Route, primaryName string, canaryName string) bool {
+	dests := route.Action.Destination.Destinations
+	return len(dests) == 2 &&
+		dests[0].Destination.Upstream.Name == primaryName &&
+		dests[1].Destination.Upstream.Name == canaryName
+}
+
+func routeWeights(route *gloov1.Route, primaryName string) (primaryWeight int, canaryWeight int) {
+	for _, dst := range route.Action.Destination.Destinations {
+		if dst.Destination.Upstream.Name == primaryName {
+			primaryWeight = int(dst.Weight)
+			canaryWeight = 100 - primaryWeight
+		}
+	}
+	return
+}
+
+// mergeMatchers appends the A/B testing headers and methods to the path matchers of a route
+func mergeMatchers(defaults, canary []gloov1.Matcher) []gloov1.Matcher {
+	if len(defaults) == 0 {
+		return canary
+	}
+
+	var merged []gloov1.Matcher
+	for _, d := range defaults {
+		for _, c := range canary {
+			m := *d.DeepCopy()
+			m.Headers = append(m.Headers, c.Headers...)
+			if len(c.Methods) > 0 {
+				m.Methods = c.Methods
+			}
+			merged = append(merged, m)
+		}
+	}
+	return merged
+}
+
 func getMatchers(canary *flaggerv1.Canary) []gloov1.Matcher {
 
 	headerMatchers := getHeaderMatchers(canary)
@@ -248,7 +474,8 @@ func getMatchers(canary *flaggerv1.Canary) []gloov1.Matcher {
 func getHeaderMatchers(canary *flaggerv1.Canary) []gloov1.HeaderMatcher {
 	var headerMatchers []gloov1.HeaderMatcher
 	for _, match := range canary.GetAnalysis().Match {
-		for s, stringMatch := range match.Headers {
+		for _, s := range sortedKeys(match.Headers) {
+			stringMatch := match.Headers[s]
 			h := gloov1.HeaderMatcher{
 				Name:  s,
 				Value: stringMatch.Exact,
@@ -23,8 +23,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	gloov1 "github.com/fluxcd/flagger/pkg/apis/gloo/v1"
 )
 
@@ -150,3 +152,105 @@ func TestGlooRouter_GetRoutes(t *testing.T) {
 	assert.Equal(t, 0, c)
 	assert.False(t, m)
 }
+
+func TestGlooRouter_RouteTableDelegation(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &GlooRouter{
+		logger:              mocks.logger,
+		flaggerClient:       mocks.flaggerClient,
+		glooClient:          mocks.meshClient,
+		kubeClient:          mocks.kubeClient,
+		upstreamDiscoveryNs: "gloo-system",
+	}
+
+	// route table delegated by a virtual service and owned by the user
+	_, err := mocks.meshClient.GatewayV1().RouteTables("routes").Create(context.TODO(), &gloov1.RouteTable{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "routes"},
+		Spec: gloov1.RouteTableSpec{
+			Routes: []gloov1.Route{
+				{
+					Name:     "static",
+					Matchers: []gloov1.Matcher{{Prefix: "/static"}},
+					Action: gloov1.RouteAction{Destination: gloov1.MultiDestination{Destinations: []gloov1.WeightedDestination{
+						{Destination: gloov1.Destination{Upstream: gloov1.ResourceRef{Name: "default-static-80", Namespace: "gloo-system"}}, Weight: 1},
+					}}},
+				},
+				{
+					Name:     "podinfo",
+					Matchers: []gloov1.Matcher{{Prefix: "/api"}},
+					Action: gloov1.RouteAction{Destination: gloov1.MultiDestination{Destinations: []gloov1.WeightedDestination{
+						{Destination: gloov1.Destination{Upstream: gloov1.ResourceRef{Name: "default-podinfo-9898", Namespace: "gloo-system"}}, Weight: 1},
+					}}},
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.RouteTable = &flaggerv1.GlooRouteTableRoute{Name: "app", Namespace: "routes", Route: "podinfo"}
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	rt, err := mocks.meshClient.GatewayV1().RouteTables("routes").Get(context.TODO(), "app", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, rt.Spec.Routes, 2)
+	assert.Equal(t, "default-static-80", rt.Spec.Routes[0].Action.Destination.Destinations[0].Destination.Upstream.Name)
+	assert.Equal(t, "/api", rt.Spec.Routes[1].Matchers[0].Prefix)
+	dests := rt.Spec.Routes[1].Action.Destination.Destinations
+	require.Len(t, dests, 2)
+	assert.Equal(t, "default-podinfo-primary-9898", dests[0].Destination.Upstream.Name)
+	assert.Equal(t, "default-podinfo-canary-9898", dests[1].Destination.Upstream.Name)
+
+	// no route table is generated for the canary
+	_, err = mocks.meshClient.GatewayV1().RouteTables("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	err = router.SetRoutes(canary, 60, 40, false)
+	require.NoError(t, err)
+
+	// the weights are kept on reconciliation
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+
+	// the A/B testing route is inserted before the named route with its path matchers
+	canary.Spec.Analysis.Match = newTestABTest().Spec.Analysis.Match
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+	err = router.SetRoutes(canary, 0, 100, false)
+	require.NoError(t, err)
+
+	rt, err = mocks.meshClient.GatewayV1().RouteTables("routes").Get(context.TODO(), "app", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, rt.Spec.Routes, 3)
+	abRoute := rt.Spec.Routes[1]
+	assert.Equal(t, "podinfo-canary", abRoute.Name)
+	require.Len(t, abRoute.Matchers, 1)
+	assert.Equal(t, "/api", abRoute.Matchers[0].Prefix)
+	assert.Equal(t, "x-user-type", abRoute.Matchers[0].Headers[0].Name)
+	assert.Equal(t, uint32(100), abRoute.Action.Destination.Destinations[1].Weight)
+	assert.Equal(t, "podinfo", rt.Spec.Routes[2].Name)
+
+	p, c, _, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	// finalizing routes the named route to the apex upstream and removes the A/B testing route
+	err = router.Finalize(canary)
+	require.NoError(t, err)
+
+	rt, err = mocks.meshClient.GatewayV1().RouteTables("routes").Get(context.TODO(), "app", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, rt.Spec.Routes, 2)
+	assert.Equal(t, "static", rt.Spec.Routes[0].Name)
+	assert.Equal(t, "podinfo", rt.Spec.Routes[1].Name)
+	dests = rt.Spec.Routes[1].Action.Destination.Destinations
+	require.Len(t, dests, 1)
+	assert.Equal(t, "default-podinfo-9898", dests[0].Destination.Upstream.Name)
+}
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"go.uber.org/zap"
 	"k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -37,6 +38,7 @@ type IngressRouter struct {
 	kubeClient        kubernetes.Interface
 	annotationsPrefix string
 	logger            *zap.SugaredLogger
+	primarySuffix     string
 }
 
 func (i *IngressRouter) Reconcile(canary *flaggerv1.Canary) error {
@@ -44,7 +46,7 @@ func (i *IngressRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return fmt.Errorf("ingress selector is empty")
 	}
 
-	apexName, _, _ := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames(i.primarySuffix)
 	canaryName := fmt.Sprintf("%s-canary", apexName)
 	canaryIngressName := fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name)
 
@@ -102,9 +104,15 @@ func (i *IngressRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return fmt.Errorf("ingress %s.%s query error: %w", canaryIngressName, canary.Namespace, err)
 	}
 
-	if diff := cmp.Diff(ingressClone.Spec, canaryIngress.Spec); diff != "" {
+	// sync the spec, labels and annotations of the apex ingress, the canary annotations are managed by SetRoutes
+	annotations := i.syncAnnotations(ingressClone.Annotations, canaryIngress.Annotations)
+	if cmp.Diff(ingressClone.Spec, canaryIngress.Spec) != "" ||
+		cmp.Diff(annotations, canaryIngress.Annotations, cmpopts.EquateEmpty()) != "" ||
+		cmp.Diff(ingressClone.Labels, canaryIngress.Labels, cmpopts.EquateEmpty()) != "" {
 		iClone := canaryIngress.DeepCopy()
 		iClone.Spec = ingressClone.Spec
+		iClone.Annotations = annotations
+		iClone.Labels = ingressClone.Labels
 
 		_, err := i.kubeClient.NetworkingV1beta1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
 		if err != nil {
@@ -154,7 +162,7 @@ func (i *IngressRouter) GetRoutes(canary *flaggerv1.Canary) (
 		}
 	}
 
-	primaryWeight = 100 - canaryWeight
+	primaryWeight = canary.GetAnalysisTotalWeight() - canaryWeight
 	mirrored = false
 	return
 }
@@ -192,6 +200,13 @@ func (i *IngressRouter) SetRoutes(
 	} else {
 		// canary
 		iClone.Annotations[i.GetAnnotationWithPrefix("canary-weight")] = fmt.Sprintf("%v", canaryWeight)
+
+		// the weight total allows steps smaller than one percent
+		if totalWeight := canary.GetAnalysisTotalWeight(); totalWeight != flaggerv1.TotalWeight {
+			iClone.Annotations[i.GetAnnotationWithPrefix("canary-weight-total")] = fmt.Sprintf("%v", totalWeight)
+		} else {
+			delete(iClone.Annotations, i.GetAnnotationWithPrefix("canary-weight-total"))
+		}
 	}
 
 	// toggle canary
@@ -223,6 +238,17 @@ func (i *IngressRouter) makeAnnotations(annotations map[string]string) map[strin
 	return res
 }
 
+// syncAnnotations returns the annotations of the apex ingress merged with the canary annotations of the canary ingress
+func (i *IngressRouter) syncAnnotations(apex map[string]string, current map[string]string) map[string]string {
+	res := i.makeAnnotations(apex)
+	for k, v := range current {
+		if strings.Contains(k, i.GetAnnotationWithPrefix("canary")) {
+			res[k] = v
+		}
+	}
+	return res
+}
+
 func (i *IngressRouter) makeHeaderAnnotations(annotations map[string]string,
 	header string, headerValue string, headerRegex string, cookie string) map[string]string {
 	res := make(map[string]string)
@@ -257,6 +283,28 @@ func (i *IngressRouter) GetAnnotationWithPrefix(suffix string) string {
 	return fmt.Sprintf("%v/%v", i.annotationsPrefix, suffix)
 }
 
-func (i *IngressRouter) Finalize(_ *flaggerv1.Canary) error {
+// Finalize deletes the canary ingress if it was created by Flagger
+func (i *IngressRouter) Finalize(canary *flaggerv1.Canary) error {
+	if canary.Spec.IngressRef == nil || canary.Spec.IngressRef.Name == "" {
+		return nil
+	}
+
+	canaryIngressName := fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name)
+	canaryIngress, err := i.kubeClient.NetworkingV1beta1().Ingresses(canary.Namespace).Get(context.TODO(), canaryIngressName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ingress %s.%s get query error: %w", canaryIngressName, canary.Namespace, err)
+	}
+
+	if ownerRef := metav1.GetControllerOf(canaryIngress); ownerRef == nil ||
+		ownerRef.Kind != flaggerv1.CanaryKind || ownerRef.Name != canary.Name {
+		return nil
+	}
+
+	err = i.kubeClient.NetworkingV1beta1().Ingresses(canary.Namespace).Delete(context.TODO(), canaryIngressName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("ingress %s.%s delete error: %w", canaryIngressName, canary.Namespace, err)
+	}
 	return nil
 }
@@ -23,6 +23,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -51,6 +52,45 @@ func TestIngressRouter_Reconcile(t *testing.T) {
 	assert.Equal(t, "false", inCanary.Annotations[canaryAn])
 }
 
+func TestIngressRouter_SyncAndFinalize(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IngressRouter{
+		logger:            mocks.logger,
+		kubeClient:        mocks.kubeClient,
+		annotationsPrefix: "nginx.ingress.kubernetes.io",
+	}
+
+	err := router.Reconcile(mocks.ingressCanary)
+	require.NoError(t, err)
+	err = router.SetRoutes(mocks.ingressCanary, 90, 10, false)
+	require.NoError(t, err)
+
+	// the labels and annotations of the apex ingress are copied to the canary ingress
+	apex, err := mocks.kubeClient.NetworkingV1beta1().Ingresses("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	apex.Labels = map[string]string{"team": "podinfo"}
+	apex.Annotations["nginx.ingress.kubernetes.io/proxy-body-size"] = "8m"
+	_, err = mocks.kubeClient.NetworkingV1beta1().Ingresses("default").Update(context.TODO(), apex, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	inCanary, err := mocks.kubeClient.NetworkingV1beta1().Ingresses("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", inCanary.Labels["team"])
+	assert.Equal(t, "8m", inCanary.Annotations["nginx.ingress.kubernetes.io/proxy-body-size"])
+	assert.Equal(t, "true", inCanary.Annotations["nginx.ingress.kubernetes.io/canary"])
+	assert.Equal(t, "10", inCanary.Annotations["nginx.ingress.kubernetes.io/canary-weight"])
+
+	// the canary ingress is removed on finalize
+	err = router.Finalize(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	_, err = mocks.kubeClient.NetworkingV1beta1().Ingresses("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
 func TestIngressRouter_GetSetRoutes(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &IngressRouter{
@@ -97,6 +137,46 @@ func TestIngressRouter_GetSetRoutes(t *testing.T) {
 	assert.Equal(t, "false", inCanary.Annotations[canaryAn])
 }
 
+func TestIngressRouter_TotalWeight(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IngressRouter{
+		logger:            mocks.logger,
+		kubeClient:        mocks.kubeClient,
+		annotationsPrefix: "nginx.ingress.kubernetes.io",
+	}
+
+	canary := mocks.ingressCanary.DeepCopy()
+	canary.Spec.Analysis.TotalWeight = 1000
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(canary, 995, 5, false)
+	require.NoError(t, err)
+
+	canaryName := fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name)
+	inCanary, err := router.kubeClient.NetworkingV1beta1().Ingresses("default").Get(context.TODO(), canaryName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "true", inCanary.Annotations["nginx.ingress.kubernetes.io/canary"])
+	assert.Equal(t, "5", inCanary.Annotations["nginx.ingress.kubernetes.io/canary-weight"])
+	assert.Equal(t, "1000", inCanary.Annotations["nginx.ingress.kubernetes.io/canary-weight-total"])
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 995, p)
+	assert.Equal(t, 5, c)
+
+	// the default total weight removes the annotation
+	canary.Spec.Analysis.TotalWeight = 0
+	err = router.SetRoutes(canary, 50, 50, false)
+	require.NoError(t, err)
+
+	inCanary, err = router.kubeClient.NetworkingV1beta1().Ingresses("default").Get(context.TODO(), canaryName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "50", inCanary.Annotations["nginx.ingress.kubernetes.io/canary-weight"])
+	assert.NotContains(t, inCanary.Annotations, "nginx.ingress.kubernetes.io/canary-weight-total")
+}
+
 func TestIngressRouter_ABTest(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &IngressRouter{
@@ -40,11 +40,13 @@ type IstioRouter struct {
 	istioClient   clientset.Interface
 	flaggerClient clientset.Interface
 	logger        *zap.SugaredLogger
+	labelSelector string
+	primarySuffix string
 }
 
 // Reconcile creates or updates the Istio virtual service and destination rules
 func (ir *IstioRouter) Reconcile(canary *flaggerv1.Canary) error {
-	_, primaryName, canaryName := canary.GetServiceNames()
+	_, primaryName, canaryName := canary.GetServiceNames(ir.primarySuffix)
 
 	if err := ir.reconcileDestinationRule(canary, canaryName); err != nil {
 		return fmt.Errorf("reconcileDestinationRule failed: %w", err)
@@ -57,6 +59,10 @@ func (ir *IstioRouter) Reconcile(canary *flaggerv1.Canary) error {
 	if err := ir.reconcileVirtualService(canary); err != nil {
 		return fmt.Errorf("reconcileVirtualService failed: %w", err)
 	}
+
+	if err := ir.reconcileProxyConfig(canary); err != nil {
+		return fmt.Errorf("reconcileProxyConfig failed: %w", err)
+	}
 	return nil
 }
 
@@ -112,17 +118,32 @@ func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name s
 }
 
 func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(ir.primarySuffix)
 
 	if canary.Spec.Service.Delegation {
-		if len(canary.Spec.Service.Hosts) > 0 || len(canary.Spec.Service.Gateways) > 0 {
+		if len(canary.Spec.Service.Hosts) > 0 || len(canary.Spec.Service.Gateways) > 0 || len(canary.Spec.Service.GatewayRefs) > 0 {
 			// delegate VirtualService cannot have hosts and gateways.
 			return fmt.Errorf("VirtualService %s.%s cannot have hosts and gateways when delegation enabled", apexName, canary.Namespace)
 		}
+		if canary.Spec.Service.VirtualService != nil {
+			return fmt.Errorf("VirtualService %s.%s cannot be merged into %s when delegation enabled",
+				apexName, canary.Namespace, canary.Spec.Service.VirtualService.Name)
+		}
+	}
+	if canary.Spec.Service.VirtualService != nil && len(canary.Spec.Service.GatewayRefs) > 0 {
+		return fmt.Errorf("VirtualService %s.%s cannot have gateway refs when merged into %s",
+			apexName, canary.Namespace, canary.Spec.Service.VirtualService.Name)
 	}
 
-	// set hosts and add the ClusterIP service host if it doesn't exists
-	hosts := canary.Spec.Service.Hosts
+	// set hosts and gateways from the service and the gateway refs
+	hosts := appendUnique(nil, canary.Spec.Service.Hosts...)
+	gateways := appendUnique(nil, canary.Spec.Service.Gateways...)
+	for _, ref := range canary.Spec.Service.GatewayRefs {
+		hosts = appendUnique(hosts, ref.Hosts...)
+		gateways = appendUnique(gateways, ref.Gateways...)
+	}
+
+	// add the ClusterIP service host if it doesn't exists
 	var hasServiceHost bool
 	for _, h := range hosts {
 		if h == apexName || h == "*" {
@@ -134,8 +155,7 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 		hosts = append(hosts, apexName)
 	}
 
-	// set gateways and add the mesh gateway if it doesn't exists
-	gateways := canary.Spec.Service.Gateways
+	// add the mesh gateway if it doesn't exists
 	var hasMeshGateway bool
 	for _, g := range gateways {
 		if g == "mesh" {
@@ -145,7 +165,7 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 	}
 
 	// set default mesh gateway if no gateway is specified
-	if !hasMeshGateway && len(canary.Spec.Service.Gateways) == 0 {
+	if !hasMeshGateway && len(gateways) == 0 {
 		gateways = append(gateways, "mesh")
 	}
 
@@ -177,6 +197,10 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 		},
 	}
 
+	if len(canary.Spec.Service.PortWeights) > 0 {
+		newSpec.Http = append(ir.makePortRoutes(canary, 100, 0, false), newSpec.Http...)
+	}
+
 	if len(canary.GetAnalysis().Match) > 0 {
 		canaryMatch := mergeMatchConditions(canary.GetAnalysis().Match, canary.Spec.Service.Match)
 		newSpec.Http = []istiov1alpha3.HTTPRoute{
@@ -203,6 +227,14 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 		}
 	}
 
+	if len(canary.Spec.Service.GatewayRefs) > 0 {
+		newSpec.Http = ir.makeGatewayRoutes(canary, 100, 0, false)
+	}
+
+	if canary.Spec.Service.VirtualService != nil {
+		return ir.reconcileVirtualServiceRoutes(canary, newSpec.Http)
+	}
+
 	virtualService, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	// insert
 	if errors.IsNotFound(err) {
@@ -276,6 +308,40 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 	return nil
 }
 
+// reconcileVirtualServiceRoutes merges the canary routes into an existing virtual service,
+// the routes are only updated when the canary service spec changes so that the weights are kept
+func (ir *IstioRouter) reconcileVirtualServiceRoutes(canary *flaggerv1.Canary, routes []istiov1alpha3.HTTPRoute) error {
+	ref := canary.Spec.Service.VirtualService
+	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("VirtualService %s.%s get query error: %w", ref.Name, canary.Namespace, err)
+	}
+
+	routes = namedRoutes(ref.Route, routes)
+	if diff := cmp.Diff(
+		routes,
+		managedRoutes(vs.Spec.Http, ref.Route),
+		cmpopts.IgnoreFields(istiov1alpha3.DestinationWeight{}, "Weight"),
+		cmpopts.IgnoreFields(istiov1alpha3.HTTPRoute{}, "Mirror", "MirrorPercentage"),
+	); diff == "" {
+		return nil
+	}
+
+	merged, ok := mergeRoutes(vs.Spec.Http, ref.Route, routes)
+	if !ok {
+		return fmt.Errorf("VirtualService %s.%s does not contain the route %s", ref.Name, canary.Namespace, ref.Route)
+	}
+	clone := vs.DeepCopy()
+	clone.Spec.Http = merged
+	_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("VirtualService %s.%s update error: %w", ref.Name, canary.Namespace, err)
+	}
+	ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("VirtualService %s.%s route %s updated", ref.Name, canary.Namespace, ref.Route)
+	return nil
+}
+
 // GetRoutes returns the destinations weight for primary and canary
 func (ir *IstioRouter) GetRoutes(canary *flaggerv1.Canary) (
 	primaryWeight int,
@@ -283,16 +349,22 @@ func (ir *IstioRouter) GetRoutes(canary *flaggerv1.Canary) (
 	mirrored bool,
 	err error,
 ) {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(ir.primarySuffix)
+	vsName := ir.virtualServiceName(canary)
 	vs := &istiov1alpha3.VirtualService{}
-	vs, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	vs, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), vsName, metav1.GetOptions{})
 	if err != nil {
-		err = fmt.Errorf("VirtualService %s.%s get query error %v", apexName, canary.Namespace, err)
+		err = fmt.Errorf("VirtualService %s.%s get query error %v", vsName, canary.Namespace, err)
 		return
 	}
 
+	httpRoutes := vs.Spec.Http
+	if ref := canary.Spec.Service.VirtualService; ref != nil {
+		httpRoutes = managedRoutes(vs.Spec.Http, ref.Route)
+	}
+
 	var httpRoute istiov1alpha3.HTTPRoute
-	for _, http := range vs.Spec.Http {
+	for _, http := range httpRoutes {
 		for _, r := range http.Route {
 			if r.Destination.Host == canaryName {
 				httpRoute = http
@@ -314,8 +386,8 @@ func (ir *IstioRouter) GetRoutes(canary *flaggerv1.Canary) (
 	}
 
 	if primaryWeight == 0 && canaryWeight == 0 {
-		err = fmt.Errorf("VirtualService %s.%s does not contain routes for %s-primary and %s-canary",
-			apexName, canary.Namespace, apexName, apexName)
+		err = fmt.Errorf("VirtualService %s.%s does not contain routes for %s and %s-canary",
+			vsName, canary.Namespace, canary.GetPrimaryName(apexName, ir.primarySuffix), apexName)
 	}
 
 	return
@@ -328,11 +400,12 @@ func (ir *IstioRouter) SetRoutes(
 	canaryWeight int,
 	mirrored bool,
 ) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	_, primaryName, canaryName := canary.GetServiceNames(ir.primarySuffix)
+	vsName := ir.virtualServiceName(canary)
 
-	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), vsName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("VirtualService %s.%s get query error %v", apexName, canary.Namespace, err)
+		return fmt.Errorf("VirtualService %s.%s get query error %v", vsName, canary.Namespace, err)
 	}
 
 	vsCopy := vs.DeepCopy()
@@ -363,6 +436,11 @@ func (ir *IstioRouter) SetRoutes(
 		}
 	}
 
+	// shift the requests to the ports with their own weights
+	if len(canary.Spec.Service.PortWeights) > 0 {
+		vsCopy.Spec.Http = append(ir.makePortRoutes(canary, primaryWeight, canaryWeight, mirrored), vsCopy.Spec.Http...)
+	}
+
 	// fix routing (A/B testing)
 	if len(canary.GetAnalysis().Match) > 0 {
 		// merge the common routes with the canary ones
@@ -394,16 +472,34 @@ func (ir *IstioRouter) SetRoutes(
 		}
 	}
 
+	// route each host and gateway pair separately
+	if len(canary.Spec.Service.GatewayRefs) > 0 {
+		vsCopy.Spec.Http = ir.makeGatewayRoutes(canary, primaryWeight, canaryWeight, mirrored)
+	}
+
+	// keep the routes of the virtual service that aren't managed by Flagger
+	if ref := canary.Spec.Service.VirtualService; ref != nil {
+		merged, ok := mergeRoutes(vs.Spec.Http, ref.Route, namedRoutes(ref.Route, vsCopy.Spec.Http))
+		if !ok {
+			return fmt.Errorf("VirtualService %s.%s does not contain the route %s", vsName, canary.Namespace, ref.Route)
+		}
+		vsCopy.Spec.Http = merged
+	}
+
 	vs, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), vsCopy, metav1.UpdateOptions{})
 	if err != nil {
-		return fmt.Errorf("VirtualService %s.%s update failed: %w", apexName, canary.Namespace, err)
+		return fmt.Errorf("VirtualService %s.%s update failed: %w", vsName, canary.Namespace, err)
 	}
 	return nil
 }
 
 func (ir *IstioRouter) Finalize(canary *flaggerv1.Canary) error {
+	if canary.Spec.Service.VirtualService != nil {
+		return ir.finalizeVirtualServiceRoutes(canary)
+	}
+
 	// Need to see if I can get the annotation orig-configuration
-	apexName, _, _ := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames(ir.primarySuffix)
 
 	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
@@ -438,6 +534,222 @@ func (ir *IstioRouter) Finalize(canary *flaggerv1.Canary) error {
 	return nil
 }
 
+// finalizeVirtualServiceRoutes replaces the routes managed by Flagger in an existing virtual service
+// with a route to the apex service
+func (ir *IstioRouter) finalizeVirtualServiceRoutes(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames(ir.primarySuffix)
+	ref := canary.Spec.Service.VirtualService
+
+	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("VirtualService %s.%s get query error: %w", ref.Name, canary.Namespace, err)
+	}
+
+	route := istiov1alpha3.HTTPRoute{
+		Name:       ref.Route,
+		Match:      canary.Spec.Service.Match,
+		Rewrite:    canary.Spec.Service.Rewrite,
+		Timeout:    canary.Spec.Service.Timeout,
+		Retries:    canary.Spec.Service.Retries,
+		CorsPolicy: canary.Spec.Service.CorsPolicy,
+		Headers:    canary.Spec.Service.Headers,
+		Route: []istiov1alpha3.DestinationWeight{
+			makeDestination(canary, apexName, 100),
+		},
+	}
+	merged, ok := mergeRoutes(vs.Spec.Http, ref.Route, []istiov1alpha3.HTTPRoute{route})
+	if !ok {
+		ir.logger.Warnf("VirtualService %s.%s route %s not found, unable to revert", ref.Name, canary.Namespace, ref.Route)
+		return nil
+	}
+
+	clone := vs.DeepCopy()
+	clone.Spec.Http = merged
+	_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("VirtualService %s.%s update error: %w", ref.Name, canary.Namespace, err)
+	}
+	return nil
+}
+
+// virtualServiceName returns the name of the virtual service that holds the canary routes
+func (ir *IstioRouter) virtualServiceName(canary *flaggerv1.Canary) string {
+	if ref := canary.Spec.Service.VirtualService; ref != nil {
+		return ref.Name
+	}
+	apexName, _, _ := canary.GetServiceNames(ir.primarySuffix)
+	return apexName
+}
+
+// namedRoutes sets the names of the canary routes merged in an existing virtual service,
+// the A/B testing route is named after the managed route with the canary suffix
+func namedRoutes(name string, routes []istiov1alpha3.HTTPRoute) []istiov1alpha3.HTTPRoute {
+	named := make([]istiov1alpha3.HTTPRoute, len(routes))
+	for i, r := range routes {
+		named[i] = *r.DeepCopy()
+		named[i].Name = name
+		if i < len(routes)-1 {
+			named[i].Name = name + "-canary"
+		}
+	}
+	return named
+}
+
+// managedRoutes returns the routes of a virtual service that are managed by Flagger
+func managedRoutes(http []istiov1alpha3.HTTPRoute, name string) []istiov1alpha3.HTTPRoute {
+	var routes []istiov1alpha3.HTTPRoute
+	for _, r := range http {
+		if r.Name == name || r.Name == name+"-canary" {
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// mergeRoutes replaces the routes managed by Flagger with the canary routes,
+// the canary routes are inserted at the position of the first managed route
+// and false is returned when the virtual service doesn't contain the named route
+func mergeRoutes(http []istiov1alpha3.HTTPRoute, name string, routes []istiov1alpha3.HTTPRoute) ([]istiov1alpha3.HTTPRoute, bool) {
+	var merged []istiov1alpha3.HTTPRoute
+	found := false
+	for _, r := range http {
+		if r.Name == name || r.Name == name+"-canary" {
+			if !found {
+				merged = append(merged, routes...)
+				found = true
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged, found
+}
+
+// makeGatewayRoutes returns the canary routes of each gateway ref,
+// the match conditions of the routes are scoped to the gateways and the port of the ref
+func (ir *IstioRouter) makeGatewayRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int, mirrored bool) []istiov1alpha3.HTTPRoute {
+	_, primaryName, canaryName := canary.GetServiceNames(ir.primarySuffix)
+	var routes []istiov1alpha3.HTTPRoute
+	for _, ref := range canary.Spec.Service.GatewayRefs {
+		match := ref.Match
+		if len(match) == 0 {
+			match = canary.Spec.Service.Match
+		}
+		match = scopeMatchConditions(match, ref.Gateways, ref.Port)
+
+		route := istiov1alpha3.HTTPRoute{
+			Match:      match,
+			Rewrite:    canary.Spec.Service.Rewrite,
+			Timeout:    canary.Spec.Service.Timeout,
+			Retries:    canary.Spec.Service.Retries,
+			CorsPolicy: canary.Spec.Service.CorsPolicy,
+			Headers:    canary.Spec.Service.Headers,
+			Route: []istiov1alpha3.DestinationWeight{
+				makeDestination(canary, primaryName, primaryWeight),
+				makeDestination(canary, canaryName, canaryWeight),
+			},
+		}
+
+		if len(canary.GetAnalysis().Match) > 0 {
+			primaryRoute := *route.DeepCopy()
+			primaryRoute.Route = []istiov1alpha3.DestinationWeight{
+				makeDestination(canary, primaryName, primaryWeight),
+			}
+			route.Match = mergeMatchConditions(canary.GetAnalysis().Match, match)
+			routes = append(routes, route, primaryRoute)
+			continue
+		}
+
+		if mirrored {
+			route.Mirror = &istiov1alpha3.Destination{
+				Host: canaryName,
+			}
+			if mw := canary.GetAnalysis().MirrorWeight; mw > 0 {
+				route.MirrorPercentage = &istiov1alpha3.Percent{Value: float64(mw)}
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// makePortRoutes returns the weighted routes of the service ports with their own canary weights,
+// the routes are placed before the default route so that they match the requests to their ports first
+func (ir *IstioRouter) makePortRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int, mirrored bool) []istiov1alpha3.HTTPRoute {
+	_, primaryName, canaryName := canary.GetServiceNames(ir.primarySuffix)
+	totalWeight := primaryWeight + canaryWeight
+	var routes []istiov1alpha3.HTTPRoute
+	for _, pw := range canary.Spec.Service.PortWeights {
+		weight := pw.Weight(canaryWeight)
+		match := canary.Spec.Service.Match
+		if len(match) == 0 {
+			match = []istiov1alpha3.HTTPMatchRequest{{}}
+		}
+		portMatch := make([]istiov1alpha3.HTTPMatchRequest, len(match))
+		for i, m := range match {
+			portMatch[i] = *m.DeepCopy()
+			portMatch[i].Port = uint32(pw.Port)
+		}
+
+		route := istiov1alpha3.HTTPRoute{
+			Match:      portMatch,
+			Rewrite:    canary.Spec.Service.Rewrite,
+			Timeout:    canary.Spec.Service.Timeout,
+			Retries:    canary.Spec.Service.Retries,
+			CorsPolicy: canary.Spec.Service.CorsPolicy,
+			Headers:    canary.Spec.Service.Headers,
+			Route: []istiov1alpha3.DestinationWeight{
+				makePortDestination(canary, primaryName, totalWeight-weight, pw.Port),
+				makePortDestination(canary, canaryName, weight, pw.Port),
+			},
+		}
+		if mirrored {
+			route.Mirror = &istiov1alpha3.Destination{
+				Host: canaryName,
+			}
+			if mw := canary.GetAnalysis().MirrorWeight; mw > 0 {
+				route.MirrorPercentage = &istiov1alpha3.Percent{Value: float64(mw)}
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// scopeMatchConditions restricts the match conditions to the gateways and the port,
+// a single match condition is returned when there are no conditions to restrict
+func scopeMatchConditions(match []istiov1alpha3.HTTPMatchRequest, gateways []string, port uint32) []istiov1alpha3.HTTPMatchRequest {
+	if len(match) == 0 {
+		match = []istiov1alpha3.HTTPMatchRequest{{}}
+	}
+	scoped := make([]istiov1alpha3.HTTPMatchRequest, len(match))
+	for i, m := range match {
+		scoped[i] = *m.DeepCopy()
+		scoped[i].Gateways = gateways
+		if port > 0 {
+			scoped[i].Port = port
+		}
+	}
+	return scoped
+}
+
+// appendUnique appends the values that the list doesn't contain
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, item := range list {
+			if item == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 // mergeMatchConditions appends the URI match rules to canary conditions
 func mergeMatchConditions(canary, defaults []istiov1alpha3.HTTPMatchRequest) []istiov1alpha3.HTTPMatchRequest {
 	if len(defaults) == 0 {
@@ -488,3 +800,15 @@ func makeDestination(canary *flaggerv1.Canary, host string, weight int) istiov1a
 
 	return dest
 }
+
+// makePortDestination returns a destination weight for the specified host,
+// the port replaces the service port when the destinations select it for an ingress gateway
+func makePortDestination(canary *flaggerv1.Canary, host string, weight int, port int32) istiov1alpha3.DestinationWeight {
+	dest := makeDestination(canary, host, weight)
+	if dest.Destination.Port != nil {
+		dest.Destination.Port = &istiov1alpha3.PortSelector{
+			Number: uint32(port),
+		}
+	}
+	return dest
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+)
+
+// proxyConfigSourceLabel marks the proxy config copies with the name of the copied object
+const proxyConfigSourceLabel = "flagger.app/copied-from"
+
+// reconcileProxyConfig copies the EnvoyFilters and Sidecars that select the target pods
+// with a workload selector that matches the primary pods, the copies of the objects
+// that no longer select the target pods are deleted
+func (ir *IstioRouter) reconcileProxyConfig(canary *flaggerv1.Canary) error {
+	if !canary.Spec.Service.CopyProxyConfig || ir.labelSelector == "" {
+		return nil
+	}
+	labelValue, err := ir.targetLabelValue(canary)
+	if err != nil || labelValue == "" {
+		return err
+	}
+
+	filters, err := ir.istioClient.NetworkingV1alpha3().EnvoyFilters(canary.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("EnvoyFilter %s list query error: %w", canary.Namespace, err)
+	}
+	copies := make(map[string]bool)
+	for _, filter := range filters.Items {
+		if controlledByCanary(filter.ObjectMeta, canary) || !ir.selectsTarget(filter.Spec.WorkloadSelector, labelValue) {
+			continue
+		}
+		spec := *filter.Spec.DeepCopy()
+		spec.WorkloadSelector = ir.primarySelector(spec.WorkloadSelector, canary.GetPrimaryName(labelValue, ir.primarySuffix))
+		name := canary.GetPrimaryName(filter.Name, ir.primarySuffix)
+		copies[name] = true
+		if err := ir.reconcileEnvoyFilter(canary, name, filter.Name, spec); err != nil {
+			return err
+		}
+	}
+	for _, filter := range filters.Items {
+		if _, ok := filter.Labels[proxyConfigSourceLabel]; ok && controlledByCanary(filter.ObjectMeta, canary) && !copies[filter.Name] {
+			err := ir.istioClient.NetworkingV1alpha3().EnvoyFilters(canary.Namespace).Delete(context.TODO(), filter.Name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("EnvoyFilter %s.%s delete error: %w", filter.Name, canary.Namespace, err)
+			}
+		}
+	}
+
+	sidecars, err := ir.istioClient.NetworkingV1alpha3().Sidecars(canary.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("Sidecar %s list query error: %w", canary.Namespace, err)
+	}
+	copies = make(map[string]bool)
+	for _, sidecar := range sidecars.Items {
+		if controlledByCanary(sidecar.ObjectMeta, canary) || !ir.selectsTarget(sidecar.Spec.WorkloadSelector, labelValue) {
+			continue
+		}
+		spec := *sidecar.Spec.DeepCopy()
+		spec.WorkloadSelector = ir.primarySelector(spec.WorkloadSelector, canary.GetPrimaryName(labelValue, ir.primarySuffix))
+		name := canary.GetPrimaryName(sidecar.Name, ir.primarySuffix)
+		copies[name] = true
+		if err := ir.reconcileSidecar(canary, name, sidecar.Name, spec); err != nil {
+			return err
+		}
+	}
+	for _, sidecar := range sidecars.Items {
+		if _, ok := sidecar.Labels[proxyConfigSourceLabel]; ok && controlledByCanary(sidecar.ObjectMeta, canary) && !copies[sidecar.Name] {
+			err := ir.istioClient.NetworkingV1alpha3().Sidecars(canary.Namespace).Delete(context.TODO(), sidecar.Name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("Sidecar %s.%s delete error: %w", sidecar.Name, canary.Namespace, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (ir *IstioRouter) reconcileEnvoyFilter(canary *flaggerv1.Canary, name string, source string, spec istiov1alpha3.EnvoyFilterSpec) error {
+	filter, err := ir.istioClient.NetworkingV1alpha3().EnvoyFilters(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		filter = &istiov1alpha3.EnvoyFilter{
+			ObjectMeta: proxyConfigMeta(canary, name, source),
+			Spec:       spec,
+		}
+		_, err = ir.istioClient.NetworkingV1alpha3().EnvoyFilters(canary.Namespace).Create(context.TODO(), filter, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("EnvoyFilter %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("EnvoyFilter %s.%s created", name, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("EnvoyFilter %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	if diff := cmp.Diff(spec, filter.Spec); diff != "" {
+		clone := filter.DeepCopy()
+		clone.Spec = spec
+		_, err = ir.istioClient.NetworkingV1alpha3().EnvoyFilters(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("EnvoyFilter %s.%s update error: %w", name, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("EnvoyFilter %s.%s updated", name, canary.Namespace)
+	}
+	return nil
+}
+
+func (ir *IstioRouter) reconcileSidecar(canary *flaggerv1.Canary, name string, source string, spec istiov1alpha3.SidecarSpec) error {
+	sidecar, err := ir.istioClient.NetworkingV1alpha3().Sidecars(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		sidecar = &istiov1alpha3.Sidecar{
+			ObjectMeta: proxyConfigMeta(canary, name, source),
+			Spec:       spec,
+		}
+		_, err = ir.istioClient.NetworkingV1alpha3().Sidecars(canary.Namespace).Create(context.TODO(), sidecar, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("Sidecar %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Sidecar %s.%s created", name, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Sidecar %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	if diff := cmp.Diff(spec, sidecar.Spec); diff != "" {
+		clone := sidecar.DeepCopy()
+		clone.Spec = spec
+		_, err = ir.istioClient.NetworkingV1alpha3().Sidecars(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("Sidecar %s.%s update error: %w", name, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Sidecar %s.%s updated", name, canary.Namespace)
+	}
+	return nil
+}
+
+// targetLabelValue returns the value of the selector label of the target pods
+func (ir *IstioRouter) targetLabelValue(canary *flaggerv1.Canary) (string, error) {
+	targetName := canary.Spec.TargetRef.Name
+	switch canary.Spec.TargetRef.Kind {
+	case "Deployment", "":
+		dep, err := ir.kubeClient.AppsV1().Deployments(canary.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("deployment %s.%s get query error: %w", targetName, canary.Namespace, err)
+		}
+		return dep.Spec.Template.Labels[ir.labelSelector], nil
+	case "DaemonSet":
+		ds, err := ir.kubeClient.AppsV1().DaemonSets(canary.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("daemonset %s.%s get query error: %w", targetName, canary.Namespace, err)
+		}
+		return ds.Spec.Template.Labels[ir.labelSelector], nil
+	case "StatefulSet":
+		sts, err := ir.kubeClient.AppsV1().StatefulSets(canary.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("statefulset %s.%s get query error: %w", targetName, canary.Namespace, err)
+		}
+		return sts.Spec.Template.Labels[ir.labelSelector], nil
+	default:
+		return "", nil
+	}
+}
+
+// selectsTarget returns true when the workload selector matches the selector label of the target pods
+func (ir *IstioRouter) selectsTarget(selector *istiov1alpha3.WorkloadSelector, labelValue string) bool {
+	return selector != nil && selector.Labels[ir.labelSelector] == labelValue
+}
+
+// primarySelector returns the workload selector with the selector label of the primary pods
+func (ir *IstioRouter) primarySelector(selector *istiov1alpha3.WorkloadSelector, primaryLabelValue string) *istiov1alpha3.WorkloadSelector {
+	primary := selector.DeepCopy()
+	primary.Labels[ir.labelSelector] = primaryLabelValue
+	return primary
+}
+
+func proxyConfigMeta(canary *flaggerv1.Canary, name string, source string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: canary.Namespace,
+		Labels:    map[string]string{proxyConfigSourceLabel: source},
+		OwnerReferences: []metav1.OwnerReference{
+			*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+				Group:   flaggerv1.SchemeGroupVersion.Group,
+				Version: flaggerv1.SchemeGroupVersion.Version,
+				Kind:    flaggerv1.CanaryKind,
+			}),
+		},
+	}
+}
+
+// controlledByCanary returns true when the object is controlled by the canary
+func controlledByCanary(meta metav1.ObjectMeta, canary *flaggerv1.Canary) bool {
+	ref := metav1.GetControllerOf(&meta)
+	return ref != nil && ref.Kind == flaggerv1.CanaryKind && ref.Name == canary.Name
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
@@ -350,6 +351,138 @@ func TestIstioRouter_GatewayPort(t *testing.T) {
 	assert.Equal(t, uint32(mocks.canary.Spec.Service.Port), port)
 }
 
+func TestIstioRouter_GatewayRefs(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.Gateways = nil
+	canary.Spec.Service.GatewayRefs = []v1beta1.GatewayRef{
+		{
+			Gateways: []string{"public-gateway.istio"},
+			Hosts:    []string{"app.example.com"},
+			Port:     443,
+		},
+		{
+			Gateways: []string{"mesh"},
+			Match: []istiov1alpha3.HTTPMatchRequest{
+				{Uri: &istiov1alpha1.StringMatch{Prefix: "/internal"}},
+			},
+		},
+	}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app.example.com", "podinfo"}, vs.Spec.Hosts)
+	assert.Equal(t, []string{"public-gateway.istio", "mesh"}, vs.Spec.Gateways)
+	require.Len(t, vs.Spec.Http, 2)
+
+	// the external route keeps the service match conditions
+	require.Len(t, vs.Spec.Http[0].Match, 1)
+	assert.Equal(t, "/podinfo", vs.Spec.Http[0].Match[0].Uri.Prefix)
+	assert.Equal(t, []string{"public-gateway.istio"}, vs.Spec.Http[0].Match[0].Gateways)
+	assert.Equal(t, uint32(443), vs.Spec.Http[0].Match[0].Port)
+
+	// the internal route has its own match conditions
+	require.Len(t, vs.Spec.Http[1].Match, 1)
+	assert.Equal(t, "/internal", vs.Spec.Http[1].Match[0].Uri.Prefix)
+	assert.Equal(t, []string{"mesh"}, vs.Spec.Http[1].Match[0].Gateways)
+	assert.Equal(t, uint32(0), vs.Spec.Http[1].Match[0].Port)
+
+	err = router.SetRoutes(canary, 70, 30, false)
+	require.NoError(t, err)
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+	for _, http := range vs.Spec.Http {
+		assert.Equal(t, 70, http.Route[0].Weight)
+		assert.Equal(t, 30, http.Route[1].Weight)
+	}
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, p)
+	assert.Equal(t, 30, c)
+
+	// each gateway ref gets an A/B testing route and a primary route
+	canary.Spec.Analysis.Iterations = 10
+	canary.Spec.Analysis.Match = newTestABTest().Spec.Analysis.Match
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 4)
+	assert.Equal(t, "test", vs.Spec.Http[0].Match[0].Headers["x-user-type"].Exact)
+	assert.Equal(t, []string{"public-gateway.istio"}, vs.Spec.Http[0].Match[0].Gateways)
+	assert.Len(t, vs.Spec.Http[1].Route, 1)
+	assert.Equal(t, "test", vs.Spec.Http[2].Match[0].Headers["x-user-type"].Exact)
+	assert.Equal(t, []string{"mesh"}, vs.Spec.Http[2].Match[0].Gateways)
+	assert.Len(t, vs.Spec.Http[3].Route, 1)
+}
+
+func TestIstioRouter_PortWeights(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.PortWeights = []v1beta1.CanaryPortWeight{
+		{Port: 9999, WeightPercent: 50, MaxWeight: 20},
+	}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+
+	// the port route keeps the service match conditions
+	require.Len(t, vs.Spec.Http[0].Match, 1)
+	assert.Equal(t, "/podinfo", vs.Spec.Http[0].Match[0].Uri.Prefix)
+	assert.Equal(t, uint32(9999), vs.Spec.Http[0].Match[0].Port)
+	assert.Equal(t, uint32(9999), vs.Spec.Http[0].Route[0].Destination.Port.Number)
+	assert.Equal(t, 100, vs.Spec.Http[0].Route[0].Weight)
+	assert.Equal(t, uint32(0), vs.Spec.Http[1].Match[0].Port)
+
+	err = router.SetRoutes(canary, 70, 30, false)
+	require.NoError(t, err)
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+	assert.Equal(t, 85, vs.Spec.Http[0].Route[0].Weight)
+	assert.Equal(t, 15, vs.Spec.Http[0].Route[1].Weight)
+	assert.Equal(t, 70, vs.Spec.Http[1].Route[0].Weight)
+	assert.Equal(t, 30, vs.Spec.Http[1].Route[1].Weight)
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, p)
+	assert.Equal(t, 30, c)
+
+	// the port weight is capped
+	err = router.SetRoutes(canary, 20, 80, false)
+	require.NoError(t, err)
+
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 80, vs.Spec.Http[0].Route[0].Weight)
+	assert.Equal(t, 20, vs.Spec.Http[0].Route[1].Weight)
+}
+
 func TestIstioRouter_Delegate(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		mocks := newFixture(nil)
@@ -397,6 +530,176 @@ func TestIstioRouter_Delegate(t *testing.T) {
 	})
 }
 
+func TestIstioRouter_VirtualServiceRoute(t *testing.T) {
+	mocks := newFixture(nil)
+	mocks.canary.Spec.Service.VirtualService = &v1beta1.VirtualServiceRoute{Name: "public", Route: "podinfo"}
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	docs := istiov1alpha3.HTTPRoute{
+		Name:  "docs",
+		Route: []istiov1alpha3.DestinationWeight{{Destination: istiov1alpha3.Destination{Host: "docs"}}},
+	}
+	public := &istiov1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: "public", Namespace: "default"},
+		Spec: istiov1alpha3.VirtualServiceSpec{
+			Hosts:    []string{"app.example.com"},
+			Gateways: []string{"public-gateway"},
+			Http: []istiov1alpha3.HTTPRoute{docs, {
+				Name:  "podinfo",
+				Route: []istiov1alpha3.DestinationWeight{{Destination: istiov1alpha3.Destination{Host: "podinfo"}}},
+			}},
+		},
+	}
+
+	// the route must exist
+	require.Error(t, router.Reconcile(mocks.canary))
+
+	_, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Create(context.TODO(), public, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	// the canary virtual service isn't generated
+	_, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.Error(t, err)
+
+	require.NoError(t, router.SetRoutes(mocks.canary, 60, 40, false))
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "public", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+	assert.Equal(t, docs, vs.Spec.Http[0])
+	assert.Equal(t, "podinfo", vs.Spec.Http[1].Name)
+	assert.Equal(t, []string{"app.example.com"}, vs.Spec.Hosts)
+
+	// A/B testing inserts the canary route before the managed one
+	mocks.abtest.Spec.Service.VirtualService = mocks.canary.Spec.Service.VirtualService
+	require.NoError(t, router.Reconcile(mocks.abtest))
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "public", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 3)
+	assert.Equal(t, "podinfo-canary", vs.Spec.Http[1].Name)
+	assert.Equal(t, "podinfo", vs.Spec.Http[2].Name)
+
+	require.NoError(t, router.Finalize(mocks.canary))
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "public", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+	assert.Equal(t, docs, vs.Spec.Http[0])
+	require.Len(t, vs.Spec.Http[1].Route, 1)
+	assert.Equal(t, "podinfo", vs.Spec.Http[1].Route[0].Destination.Host)
+}
+
+func TestIstioRouter_LocalityLbSetting(t *testing.T) {
+	mocks := newFixture(nil)
+	enabled := true
+	mocks.canary.Spec.Service.TrafficPolicy = &istiov1alpha3.TrafficPolicy{
+		LoadBalancer: &istiov1alpha3.LoadBalancerSettings{
+			Simple: istiov1alpha3.SimpleLBRoundRobin,
+			LocalityLbSetting: &istiov1alpha3.LocalityLoadBalancerSetting{
+				Enabled:  &enabled,
+				Failover: []istiov1alpha3.LocalityLoadBalancerSettingFailover{{From: "us-east", To: "us-west"}},
+			},
+		},
+		OutlierDetection: &istiov1alpha3.OutlierDetection{BaseEjectionTime: "1m", Interval: "10s"},
+	}
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	for _, name := range []string{"podinfo-primary", "podinfo-canary"} {
+		dr, err := mocks.meshClient.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NotNil(t, dr.Spec.TrafficPolicy.LoadBalancer.LocalityLbSetting)
+		assert.Equal(t, "us-west", dr.Spec.TrafficPolicy.LoadBalancer.LocalityLbSetting.Failover[0].To)
+	}
+
+	// the canary spec round trips the locality settings
+	b, err := json.Marshal(mocks.canary.Spec.Service)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"localityLbSetting":{"failover":[{"from":"us-east","to":"us-west"}],"enabled":true}`)
+}
+
+func TestIstioRouter_CopyProxyConfig(t *testing.T) {
+	mocks := newFixture(nil)
+	mocks.canary.Spec.Service.CopyProxyConfig = true
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+		labelSelector: "app",
+	}
+
+	filter := &istiov1alpha3.EnvoyFilter{
+		ObjectMeta: metav1.ObjectMeta{Name: "lua", Namespace: "default"},
+		Spec: istiov1alpha3.EnvoyFilterSpec{
+			WorkloadSelector: &istiov1alpha3.WorkloadSelector{Labels: map[string]string{"app": "podinfo", "tier": "web"}},
+			ConfigPatches:    []runtime.RawExtension{{Raw: []byte(`{"applyTo":"HTTP_FILTER"}`)}},
+		},
+	}
+	_, err := mocks.meshClient.NetworkingV1alpha3().EnvoyFilters("default").Create(context.TODO(), filter, metav1.CreateOptions{})
+	require.NoError(t, err)
+	sidecar := &istiov1alpha3.Sidecar{
+		ObjectMeta: metav1.ObjectMeta{Name: "egress", Namespace: "default"},
+		Spec: istiov1alpha3.SidecarSpec{
+			WorkloadSelector: &istiov1alpha3.WorkloadSelector{Labels: map[string]string{"app": "podinfo"}},
+			Egress:           []runtime.RawExtension{{Raw: []byte(`{"hosts":["./*"]}`)}},
+		},
+	}
+	_, err = mocks.meshClient.NetworkingV1alpha3().Sidecars("default").Create(context.TODO(), sidecar, metav1.CreateOptions{})
+	require.NoError(t, err)
+	other := &istiov1alpha3.Sidecar{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Spec: istiov1alpha3.SidecarSpec{
+			WorkloadSelector: &istiov1alpha3.WorkloadSelector{Labels: map[string]string{"app": "other"}},
+		},
+	}
+	_, err = mocks.meshClient.NetworkingV1alpha3().Sidecars("default").Create(context.TODO(), other, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	filterCopy, err := mocks.meshClient.NetworkingV1alpha3().EnvoyFilters("default").Get(context.TODO(), "lua-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "podinfo-primary", "tier": "web"}, filterCopy.Spec.WorkloadSelector.Labels)
+	assert.Equal(t, filter.Spec.ConfigPatches, filterCopy.Spec.ConfigPatches)
+	assert.Equal(t, "podinfo", filter.Spec.WorkloadSelector.Labels["app"])
+
+	sidecarCopy, err := mocks.meshClient.NetworkingV1alpha3().Sidecars("default").Get(context.TODO(), "egress-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", sidecarCopy.Spec.WorkloadSelector.Labels["app"])
+	_, err = mocks.meshClient.NetworkingV1alpha3().Sidecars("default").Get(context.TODO(), "other-primary", metav1.GetOptions{})
+	require.Error(t, err)
+
+	// the copy follows the source object
+	filter.Spec.Priority = 10
+	_, err = mocks.meshClient.NetworkingV1alpha3().EnvoyFilters("default").Update(context.TODO(), filter, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.meshClient.NetworkingV1alpha3().Sidecars("default").Delete(context.TODO(), "egress", metav1.DeleteOptions{}))
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	filterCopy, err = mocks.meshClient.NetworkingV1alpha3().EnvoyFilters("default").Get(context.TODO(), "lua-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), filterCopy.Spec.Priority)
+	_, err = mocks.meshClient.NetworkingV1alpha3().Sidecars("default").Get(context.TODO(), "egress-primary", metav1.GetOptions{})
+	require.Error(t, err)
+}
+
 func TestIstioRouter_Finalize(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &IstioRouter{
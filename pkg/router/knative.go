@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+var knativeServiceResource = schema.GroupVersionResource{Group: flaggerv1.KnativeServingGroup, Version: "v1", Resource: "services"}
+
+// knativeCanaryTag is the tag of the traffic target of the latest revision
+const knativeCanaryTag = "canary"
+
+// KnativeRouter reads the traffic split of the Knative Services,
+// the traffic is set by the Knative Service controller with the canary status weight
+type KnativeRouter struct {
+	logger        *zap.SugaredLogger
+	dynamicClient dynamic.Interface
+}
+
+// Reconcile is a no-op as the route is managed by Knative
+func (kr *KnativeRouter) Reconcile(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// SetRoutes is a no-op as the traffic follows the canary status weight
+func (kr *KnativeRouter) SetRoutes(_ *flaggerv1.Canary, _ int, _ int, _ bool) error {
+	return nil
+}
+
+// GetRoutes returns the percent of the primary and canary traffic targets of the Service,
+// all the traffic is routed to the primary until the targets are tagged
+func (kr *KnativeRouter) GetRoutes(canary *flaggerv1.Canary) (primaryWeight int, canaryWeight int, mirrored bool, err error) {
+	if kr.dynamicClient == nil {
+		err = fmt.Errorf("the Knative router requires a dynamic client")
+		return
+	}
+
+	targetName := canary.Spec.TargetRef.Name
+	svc, err := kr.dynamicClient.Resource(knativeServiceResource).Namespace(canary.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("service %s.%s get query error: %w", targetName, canary.Namespace, err)
+		return
+	}
+
+	traffic, _, _ := unstructured.NestedSlice(svc.Object, "spec", "traffic")
+	for _, t := range traffic {
+		target, ok := t.(map[string]interface{})
+		if !ok || target["tag"] != knativeCanaryTag {
+			continue
+		}
+		if percent, ok := target["percent"].(int64); ok {
+			canaryWeight = int(percent)
+		}
+	}
+	primaryWeight = 100 - canaryWeight
+	return
+}
+
+// Finalize is a no-op as the traffic of the Service is restored by the Knative Service controller
+func (kr *KnativeRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
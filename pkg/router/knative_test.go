@@ -0,0 +1,64 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestKnativeRouter_GetRoutes(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podinfo"},
+		Spec: flaggerv1.CanarySpec{
+			Provider:  flaggerv1.KnativeProvider,
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{Name: "podinfo", APIVersion: "serving.knative.dev/v1", Kind: "Service"},
+		},
+	}
+	svc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "serving.knative.dev/v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": "podinfo", "namespace": "default"},
+	}}
+	router := &KnativeRouter{dynamicClient: fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), svc)}
+
+	// all the traffic goes to the primary until the route is pinned
+	primaryWeight, canaryWeight, mirrored, err := router.GetRoutes(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+	assert.False(t, mirrored)
+
+	require.NoError(t, unstructured.SetNestedSlice(svc.Object, []interface{}{
+		map[string]interface{}{"tag": "primary", "revisionName": "podinfo-00001", "percent": int64(70)},
+		map[string]interface{}{"tag": "canary", "latestRevision": true, "percent": int64(30)},
+	}, "spec", "traffic"))
+	router.dynamicClient = fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), svc)
+
+	primaryWeight, canaryWeight, _, err = router.GetRoutes(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 70, primaryWeight)
+	assert.Equal(t, 30, canaryWeight)
+}
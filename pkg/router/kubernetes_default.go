@@ -25,13 +25,15 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/apis/monitoring/v1"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 )
 
@@ -43,11 +45,12 @@ type KubernetesDefaultRouter struct {
 	labelSelector string
 	labelValue    string
 	ports         map[string]int32
+	primarySuffix string
 }
 
 // Initialize creates the primary and canary services
 func (c *KubernetesDefaultRouter) Initialize(canary *flaggerv1.Canary) error {
-	_, primaryName, canaryName := canary.GetServiceNames()
+	_, primaryName, canaryName := canary.GetServiceNames(c.primarySuffix)
 
 	// canary svc
 	err := c.reconcileService(canary, canaryName, c.labelValue, canary.Spec.Service.Canary)
@@ -56,20 +59,47 @@ func (c *KubernetesDefaultRouter) Initialize(canary *flaggerv1.Canary) error {
 	}
 
 	// primary svc
-	err = c.reconcileService(canary, primaryName, fmt.Sprintf("%s-primary", c.labelValue), canary.Spec.Service.Primary)
+	err = c.reconcileService(canary, primaryName, canary.GetPrimaryName(c.labelValue, c.primarySuffix), canary.Spec.Service.Primary)
 	if err != nil {
 		return fmt.Errorf("reconcileService failed: %w", err)
 	}
 
+	// service monitors
+	err = c.reconcileServiceMonitor(canary, canaryName, "canary")
+	if err != nil {
+		return fmt.Errorf("reconcileServiceMonitor failed: %w", err)
+	}
+	err = c.reconcileServiceMonitor(canary, primaryName, "primary")
+	if err != nil {
+		return fmt.Errorf("reconcileServiceMonitor failed: %w", err)
+	}
+
+	// canary network policy
+	err = c.reconcileNetworkPolicy(canary)
+	if err != nil {
+		return fmt.Errorf("reconcileNetworkPolicy failed: %w", err)
+	}
+
 	return nil
 }
 
 // Reconcile creates or updates the main service
 func (c *KubernetesDefaultRouter) Reconcile(canary *flaggerv1.Canary) error {
-	apexName, _, _ := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames(c.primarySuffix)
+
+	switch canary.Spec.Service.ApexMode {
+	case flaggerv1.ApexModeUnmanaged:
+		return nil
+	case flaggerv1.ApexModeExternalName:
+		err := c.reconcileExternalNameService(canary, apexName)
+		if err != nil {
+			return fmt.Errorf("reconcileExternalNameService failed: %w", err)
+		}
+		return nil
+	}
 
 	// main svc
-	err := c.reconcileService(canary, apexName, fmt.Sprintf("%s-primary", c.labelValue), canary.Spec.Service.Apex)
+	err := c.reconcileService(canary, apexName, canary.GetPrimaryName(c.labelValue, c.primarySuffix), canary.Spec.Service.Apex)
 	if err != nil {
 		return fmt.Errorf("reconcileService failed: %w", err)
 	}
@@ -106,14 +136,19 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 		Selector: map[string]string{c.labelSelector: podSelector},
 		Ports: []corev1.ServicePort{
 			{
-				Name:       portName,
-				Protocol:   corev1.ProtocolTCP,
-				Port:       canary.Spec.Service.Port,
-				TargetPort: targetPort,
+				Name:        portName,
+				Protocol:    corev1.ProtocolTCP,
+				AppProtocol: stringp(canary.Spec.Service.AppProtocol),
+				Port:        canary.Spec.Service.Port,
+				TargetPort:  targetPort,
 			},
 		},
 	}
 
+	if canary.Spec.Service.Headless {
+		svcSpec.ClusterIP = corev1.ClusterIPNone
+	}
+
 	// set additional ports
 	for n, p := range c.ports {
 		cp := corev1.ServicePort{
@@ -125,12 +160,24 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 				IntVal: p,
 			},
 		}
+		if m := portMapping(canary.Spec.Service.PortDiscoveryRules, n, p); m != nil {
+			if m.Name != "" {
+				cp.Name = m.Name
+			}
+			if m.Port > 0 {
+				cp.Port = m.Port
+			}
+			cp.AppProtocol = stringp(m.AppProtocol)
+		}
 
 		svcSpec.Ports = append(svcSpec.Ports, cp)
 	}
 
+	// copy the metadata of the service role, the canary spec must not be changed
 	if metadata == nil {
 		metadata = &flaggerv1.CustomMetadata{}
+	} else {
+		metadata = metadata.DeepCopy()
 	}
 
 	if metadata.Labels == nil {
@@ -141,35 +188,48 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 	if metadata.Annotations == nil {
 		metadata.Annotations = make(map[string]string)
 	}
+	if mode := canary.Spec.Service.TopologyMode; mode != "" {
+		metadata.Annotations[topologyModeAnnotation] = mode
+	}
+	metadata.Annotations = canary.GetOwnerAnnotations(metadata.Annotations)
 
 	// create service if it doesn't exists
-	svc, err := c.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	svc, err := c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Get(context.TODO(), name, metav1.GetOptions{})
+	if err == nil && (svc.Spec.ClusterIP == corev1.ClusterIPNone) != canary.Spec.Service.Headless {
+		// the cluster IP is immutable, the services created by Flagger are replaced
+		if _, owned := c.isOwnedByCanary(svc, canary); owned {
+			err = c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Delete(context.TODO(), name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("service %s.%s delete error: %w", name, canary.GetTargetNamespace(), err)
+			}
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Infof("Service %s.%s deleted, the headless setting changed", name, canary.GetTargetNamespace())
+			err = errors.NewNotFound(corev1.Resource("services"), name)
+		} else {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Warnf("Service %s.%s is not owned by the canary, unable to change the headless setting", name, canary.GetTargetNamespace())
+		}
+	}
 	if errors.IsNotFound(err) {
 		svc = &corev1.Service{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:        name,
-				Namespace:   canary.Namespace,
-				Labels:      metadata.Labels,
-				Annotations: metadata.Annotations,
-				OwnerReferences: []metav1.OwnerReference{
-					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
-						Group:   flaggerv1.SchemeGroupVersion.Group,
-						Version: flaggerv1.SchemeGroupVersion.Version,
-						Kind:    flaggerv1.CanaryKind,
-					}),
-				},
+				Name:            name,
+				Namespace:       canary.GetTargetNamespace(),
+				Labels:          metadata.Labels,
+				Annotations:     metadata.Annotations,
+				OwnerReferences: canary.GetOwnerReferences(),
 			},
 			Spec: svcSpec,
 		}
 
-		_, err := c.kubeClient.CoreV1().Services(canary.Namespace).Create(context.TODO(), svc, metav1.CreateOptions{})
+		_, err := c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Create(context.TODO(), svc, metav1.CreateOptions{})
 		if err != nil {
-			return fmt.Errorf("service %s.%s create error: %w", svc.Name, canary.Namespace, err)
+			return fmt.Errorf("service %s.%s create error: %w", svc.Name, canary.GetTargetNamespace(), err)
 		}
 
 		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-			Infof("Service %s.%s created", svc.GetName(), canary.Namespace)
-		return nil
+			Infof("Service %s.%s created", svc.GetName(), canary.GetTargetNamespace())
+		return c.patchTrafficPolicies(canary, name)
 	} else if err != nil {
 		return fmt.Errorf("service %s get query error: %w", name, err)
 	}
@@ -202,7 +262,7 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 		}
 
 		// update annotations and labels only if the service has been created by Flagger
-		if _, owned := c.isOwnedByCanary(svc, canary.Name); owned {
+		if _, owned := c.isOwnedByCanary(svc, canary); owned {
 			if svc.ObjectMeta.Annotations == nil {
 				svc.ObjectMeta.Annotations = make(map[string]string)
 			}
@@ -217,7 +277,7 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 		}
 
 		if updateService {
-			_, err = c.kubeClient.CoreV1().Services(canary.Namespace).Update(context.TODO(), svcClone, metav1.UpdateOptions{})
+			_, err = c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Update(context.TODO(), svcClone, metav1.UpdateOptions{})
 			if err != nil {
 				return fmt.Errorf("service %s update error: %w", name, err)
 			}
@@ -226,20 +286,305 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 		}
 	}
 
+	return c.patchTrafficPolicies(canary, name)
+}
+
+// reconcileServiceMonitor creates or updates the Prometheus Operator service monitor of a service,
+// the monitor is removed when the canary no longer enables it
+func (c *KubernetesDefaultRouter) reconcileServiceMonitor(canary *flaggerv1.Canary, name string, version string) error {
+	monitor := canary.Spec.Service.ServiceMonitor
+	current, err := c.flaggerClient.MonitoringV1().ServiceMonitors(canary.GetTargetNamespace()).Get(context.TODO(), name, metav1.GetOptions{})
+
+	if monitor == nil {
+		// the Prometheus Operator CRDs may not be installed, nothing to clean up
+		if err != nil {
+			return nil
+		}
+		if _, owned := c.isOwnedByCanary(current, canary); owned {
+			err = c.flaggerClient.MonitoringV1().ServiceMonitors(canary.GetTargetNamespace()).Delete(context.TODO(), name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("ServiceMonitor %s.%s delete error: %w", name, canary.GetTargetNamespace(), err)
+			}
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Infof("ServiceMonitor %s.%s deleted", name, canary.GetTargetNamespace())
+		}
+		return nil
+	}
+
+	port := monitor.Port
+	if port == "" {
+		port = canary.Spec.Service.PortName
+	}
+	if port == "" {
+		port = "http"
+	}
+	versionLabel := monitor.VersionLabel
+	if versionLabel == "" {
+		versionLabel = "version"
+	}
+
+	spec := monitoringv1.ServiceMonitorSpec{
+		Selector: metav1.LabelSelector{
+			MatchLabels: map[string]string{c.labelSelector: name},
+		},
+		NamespaceSelector: monitoringv1.NamespaceSelector{
+			MatchNames: []string{canary.GetTargetNamespace()},
+		},
+		Endpoints: []monitoringv1.Endpoint{
+			{
+				Port:     port,
+				Path:     monitor.Path,
+				Interval: monitor.Interval,
+				RelabelConfigs: []monitoringv1.RelabelConfig{
+					{
+						TargetLabel: versionLabel,
+						Replacement: version,
+					},
+				},
+			},
+		},
+	}
+
+	if errors.IsNotFound(err) {
+		sm := &monitoringv1.ServiceMonitor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       canary.GetTargetNamespace(),
+				Labels:          monitor.Labels,
+				Annotations:     canary.GetOwnerAnnotations(nil),
+				OwnerReferences: canary.GetOwnerReferences(),
+			},
+			Spec: spec,
+		}
+		_, err = c.flaggerClient.MonitoringV1().ServiceMonitors(canary.GetTargetNamespace()).Create(context.TODO(), sm, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceMonitor %s.%s create error: %w", name, canary.GetTargetNamespace(), err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceMonitor %s.%s created", name, canary.GetTargetNamespace())
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ServiceMonitor %s.%s get query error: %w", name, canary.GetTargetNamespace(), err)
+	}
+
+	if _, owned := c.isOwnedByCanary(current, canary); !owned {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Warnf("ServiceMonitor %s.%s is not owned by the canary, skipping update", name, canary.GetTargetNamespace())
+		return nil
+	}
+
+	if cmp.Diff(spec, current.Spec) != "" || cmp.Diff(monitor.Labels, current.Labels, cmpopts.EquateEmpty()) != "" {
+		clone := current.DeepCopy()
+		clone.Spec = spec
+		clone.Labels = monitor.Labels
+		_, err = c.flaggerClient.MonitoringV1().ServiceMonitors(canary.GetTargetNamespace()).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceMonitor %s.%s update error: %w", name, canary.GetTargetNamespace(), err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceMonitor %s.%s updated", name, canary.GetTargetNamespace())
+	}
+	return nil
+}
+
+// reconcileNetworkPolicy creates or updates the network policy that restricts the ingress traffic
+// of the canary pods, the policy is removed when the canary no longer enables it
+func (c *KubernetesDefaultRouter) reconcileNetworkPolicy(canary *flaggerv1.Canary) error {
+	name := fmt.Sprintf("%s-canary", canary.Spec.TargetRef.Name)
+	policy := canary.Spec.Service.NetworkPolicy
+	current, err := c.kubeClient.NetworkingV1().NetworkPolicies(canary.GetTargetNamespace()).Get(context.TODO(), name, metav1.GetOptions{})
+
+	if policy == nil {
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("NetworkPolicy %s.%s get query error: %w", name, canary.GetTargetNamespace(), err)
+		}
+		if _, owned := c.isOwnedByCanary(current, canary); owned {
+			err = c.kubeClient.NetworkingV1().NetworkPolicies(canary.GetTargetNamespace()).Delete(context.TODO(), name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("NetworkPolicy %s.%s delete error: %w", name, canary.GetTargetNamespace(), err)
+			}
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Infof("NetworkPolicy %s.%s deleted", name, canary.GetTargetNamespace())
+		}
+		return nil
+	}
+
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: map[string]string{c.labelSelector: c.labelValue},
+		},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{
+			{
+				From: policy.From,
+			},
+		},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+	}
+
+	if errors.IsNotFound(err) {
+		np := &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       canary.GetTargetNamespace(),
+				Annotations:     canary.GetOwnerAnnotations(nil),
+				OwnerReferences: canary.GetOwnerReferences(),
+			},
+			Spec: spec,
+		}
+		_, err = c.kubeClient.NetworkingV1().NetworkPolicies(canary.GetTargetNamespace()).Create(context.TODO(), np, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("NetworkPolicy %s.%s create error: %w", name, canary.GetTargetNamespace(), err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("NetworkPolicy %s.%s created", name, canary.GetTargetNamespace())
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("NetworkPolicy %s.%s get query error: %w", name, canary.GetTargetNamespace(), err)
+	}
+
+	if _, owned := c.isOwnedByCanary(current, canary); !owned {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Warnf("NetworkPolicy %s.%s is not owned by the canary, skipping update", name, canary.GetTargetNamespace())
+		return nil
+	}
+
+	if diff := cmp.Diff(spec, current.Spec, cmpopts.EquateEmpty()); diff != "" {
+		clone := current.DeepCopy()
+		clone.Spec = spec
+		_, err = c.kubeClient.NetworkingV1().NetworkPolicies(canary.GetTargetNamespace()).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("NetworkPolicy %s.%s update error: %w", name, canary.GetTargetNamespace(), err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("NetworkPolicy %s.%s updated", name, canary.GetTargetNamespace())
+	}
+	return nil
+}
+
+// patchTrafficPolicies sets the internal traffic policy and the traffic distribution of a service
+// with a merge patch on every reconciliation, the fields are missing from the Kubernetes API
+// version vendored by Flagger and are dropped when the service is updated
+func (c *KubernetesDefaultRouter) patchTrafficPolicies(canary *flaggerv1.Canary, name string) error {
+	spec := make(map[string]string)
+	if policy := canary.Spec.Service.InternalTrafficPolicy; policy != "" {
+		spec["internalTrafficPolicy"] = policy
+	}
+	if distribution := canary.Spec.Service.TrafficDistribution; distribution != "" {
+		spec["trafficDistribution"] = distribution
+	}
+	if len(spec) == 0 {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return fmt.Errorf("service %s.%s patch marshal error: %w", name, canary.GetTargetNamespace(), err)
+	}
+	_, err = c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("service %s.%s patch error: %w", name, canary.GetTargetNamespace(), err)
+	}
+	return nil
+}
+
+// reconcileExternalNameService creates or updates the apex service as an ExternalName service
+func (c *KubernetesDefaultRouter) reconcileExternalNameService(canary *flaggerv1.Canary, name string) error {
+	metadata := canary.Spec.Service.Apex.DeepCopy()
+	if metadata == nil {
+		metadata = &flaggerv1.CustomMetadata{}
+	}
+	if metadata.Labels == nil {
+		metadata.Labels = make(map[string]string)
+	}
+	metadata.Labels[c.labelSelector] = name
+	metadata.Annotations = canary.GetOwnerAnnotations(metadata.Annotations)
+
+	svc, err := c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Get(context.TODO(), name, metav1.GetOptions{})
+	if err == nil && svc.Spec.Type != corev1.ServiceTypeExternalName {
+		// the type can't be changed in place, the services created by Flagger are replaced
+		if _, owned := c.isOwnedByCanary(svc, canary); !owned {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Warnf("Service %s.%s is not owned by the canary, unable to change it to ExternalName", name, canary.GetTargetNamespace())
+			return nil
+		}
+		err = c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("service %s.%s delete error: %w", name, canary.GetTargetNamespace(), err)
+		}
+		err = errors.NewNotFound(corev1.Resource("services"), name)
+	}
+
+	if errors.IsNotFound(err) {
+		svc = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       canary.GetTargetNamespace(),
+				Labels:          metadata.Labels,
+				Annotations:     metadata.Annotations,
+				OwnerReferences: canary.GetOwnerReferences(),
+			},
+			Spec: corev1.ServiceSpec{
+				Type:         corev1.ServiceTypeExternalName,
+				ExternalName: canary.Spec.Service.ExternalName,
+			},
+		}
+
+		_, err := c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Create(context.TODO(), svc, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("service %s.%s create error: %w", name, canary.GetTargetNamespace(), err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Service %s.%s created", name, canary.GetTargetNamespace())
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("service %s get query error: %w", name, err)
+	}
+
+	if _, owned := c.isOwnedByCanary(svc, canary); !owned {
+		return nil
+	}
+	if svc.Spec.ExternalName != canary.Spec.Service.ExternalName ||
+		cmp.Diff(metadata.Labels, svc.Labels, cmpopts.EquateEmpty()) != "" ||
+		cmp.Diff(metadata.Annotations, svc.Annotations, cmpopts.EquateEmpty()) != "" {
+		svcClone := svc.DeepCopy()
+		svcClone.Spec.ExternalName = canary.Spec.Service.ExternalName
+		svcClone.Labels = metadata.Labels
+		svcClone.Annotations = metadata.Annotations
+		_, err = c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Update(context.TODO(), svcClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("service %s update error: %w", name, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Service %s updated", name)
+	}
 	return nil
 }
 
 // Finalize reverts the apex router if not owned by the Flagger controller.
 func (c *KubernetesDefaultRouter) Finalize(canary *flaggerv1.Canary) error {
-	apexName, _, _ := canary.GetServiceNames()
+	apexName, _, _ := canary.GetServiceNames(c.primarySuffix)
+
+	if canary.GetRevertPolicy() == flaggerv1.RevertPolicyKeepServices {
+		if err := c.keepServices(canary); err != nil {
+			return err
+		}
+	}
+
+	// the unmanaged and ExternalName apex services are never changed to select the primary pods
+	if mode := canary.Spec.Service.ApexMode; mode == flaggerv1.ApexModeUnmanaged || mode == flaggerv1.ApexModeExternalName {
+		return nil
+	}
 
-	svc, err := c.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	svc, err := c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("service %s.%s get query error: %w", apexName, canary.Namespace, err)
+		return fmt.Errorf("service %s.%s get query error: %w", apexName, canary.GetTargetNamespace(), err)
 	}
 
 	// No need to do any reconciliation if the router is owned by the controller
-	if hasCanaryOwnerRef, isOwned := c.isOwnedByCanary(svc, canary.Name); !hasCanaryOwnerRef && !isOwned {
+	if hasCanaryOwnerRef, isOwned := c.isOwnedByCanary(svc, canary); !hasCanaryOwnerRef && !isOwned {
 		// If kubectl annotation is present that will be utilized, else reconcile
 		if a, ok := svc.Annotations[kubectlAnnotation]; ok {
 			var storedSvc corev1.Service
@@ -250,7 +595,7 @@ func (c *KubernetesDefaultRouter) Finalize(canary *flaggerv1.Canary) error {
 			clone := svc.DeepCopy()
 			clone.Spec.Selector = storedSvc.Spec.Selector
 
-			if _, err := c.kubeClient.CoreV1().Services(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+			if _, err := c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
 				return fmt.Errorf("service %s update error: %w", clone.Name, err)
 			}
 		} else {
@@ -263,15 +608,68 @@ func (c *KubernetesDefaultRouter) Finalize(canary *flaggerv1.Canary) error {
 	return nil
 }
 
+// keepServices removes the canary owner from the generated services so that they aren't garbage collected,
+// the orphaned apex service is then reverted to select the target pods like a service created by the user
+func (c *KubernetesDefaultRouter) keepServices(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames(c.primarySuffix)
+	for _, name := range []string{apexName, primaryName, canaryName} {
+		svc, err := c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Get(context.TODO(), name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("service %s.%s get query error: %w", name, canary.GetTargetNamespace(), err)
+		}
+		if _, owned := c.isOwnedByCanary(svc, canary); !owned {
+			continue
+		}
+
+		clone := svc.DeepCopy()
+		refs := make([]metav1.OwnerReference, 0, len(clone.OwnerReferences))
+		for _, ref := range clone.OwnerReferences {
+			if ref.Kind != flaggerv1.CanaryKind || ref.Name != canary.Name {
+				refs = append(refs, ref)
+			}
+		}
+		clone.OwnerReferences = refs
+		delete(clone.Annotations, flaggerv1.CanaryOwnerAnnotation)
+		if _, err := c.kubeClient.CoreV1().Services(canary.GetTargetNamespace()).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("service %s update error: %w", clone.Name, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Service %s.%s kept after the canary deletion", name, canary.GetTargetNamespace())
+	}
+	return nil
+}
+
+// portMapping returns the mapping of a discovered container port matched by number or name
+func portMapping(rules *flaggerv1.PortDiscoveryRules, name string, port int32) *flaggerv1.PortMapping {
+	if rules == nil {
+		return nil
+	}
+	for i, m := range rules.Mappings {
+		if (m.ContainerPort.Type == intstr.Int && m.ContainerPort.IntVal == port) ||
+			(m.ContainerPort.Type == intstr.String && m.ContainerPort.StrVal == name) {
+			return &rules.Mappings[i]
+		}
+	}
+	return nil
+}
+
 // isOwnedByCanary evaluates if an object contains an OwnerReference declaration, that is of kind Canary and
 // has the same ref name as the Canary under evaluation.  It returns two bool the first returns true if
-// an OwnerReference is present and the second returns true if it is owned by the supplied name.
-func (c KubernetesDefaultRouter) isOwnedByCanary(obj interface{}, name string) (bool, bool) {
+// an OwnerReference is present and the second returns true if it is owned by the supplied canary.
+// The objects generated in the target namespace of a cross-namespace canary have the owner annotation instead.
+func (c KubernetesDefaultRouter) isOwnedByCanary(obj interface{}, canary *flaggerv1.Canary) (bool, bool) {
 	object, ok := obj.(metav1.Object)
 	if !ok {
 		return false, false
 	}
 
+	if _, ok := object.GetAnnotations()[flaggerv1.CanaryOwnerAnnotation]; ok {
+		return true, canary.IsOwnerOf(object)
+	}
+
 	ownerRef := metav1.GetControllerOf(object)
 	if ownerRef == nil {
 		return false, false
@@ -281,5 +679,5 @@ func (c KubernetesDefaultRouter) isOwnedByCanary(obj interface{}, name string) (
 		return false, false
 	}
 
-	return true, ownerRef.Name == name
+	return true, ownerRef.Name == canary.Name
 }
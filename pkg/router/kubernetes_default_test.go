@@ -22,8 +22,11 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -58,6 +61,389 @@ func TestServiceRouter_Create(t *testing.T) {
 	assert.Equal(t, int32(9898), primarySvc.Spec.Ports[0].Port)
 }
 
+func TestServiceRouter_PrimarySuffix(t *testing.T) {
+	mocks := newFixture(nil)
+	mocks.canary.Spec.PrimarySuffix = "-stable"
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+
+	require.NoError(t, router.Initialize(mocks.canary))
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	primarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-stable", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-stable", primarySvc.Spec.Selector["app"])
+
+	apexSvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-stable", apexSvc.Spec.Selector["app"])
+
+	_, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestServiceRouter_DefaultPrimarySuffix(t *testing.T) {
+	mocks := newFixture(nil)
+	factory := NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, "", "", mocks.logger, mocks.flaggerClient)
+	factory.SetPrimarySuffix("-p")
+	router := factory.KubernetesRouter("Deployment", "app", "podinfo", nil)
+
+	require.NoError(t, router.Initialize(mocks.canary))
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	primarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-p", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-p", primarySvc.Spec.Selector["app"])
+
+	apexSvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-p", apexSvc.Spec.Selector["app"])
+}
+
+func TestServiceRouter_Headless(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+	}
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.Headless = true
+
+	err := router.Initialize(canary)
+	require.NoError(t, err)
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	for _, name := range []string{"podinfo", "podinfo-canary", "podinfo-primary"} {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, corev1.ClusterIPNone, svc.Spec.ClusterIP)
+	}
+
+	// the services are replaced when the headless setting changes
+	canary.Spec.Service.Headless = false
+	err = router.Initialize(canary)
+	require.NoError(t, err)
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	for _, name := range []string{"podinfo", "podinfo-canary", "podinfo-primary"} {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, svc.Spec.ClusterIP)
+	}
+}
+
+func TestServiceRouter_TrafficPolicies(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+	}
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.InternalTrafficPolicy = "Local"
+	canary.Spec.Service.TrafficDistribution = "PreferClose"
+	canary.Spec.Service.TopologyMode = "Auto"
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Auto", svc.Annotations["service.kubernetes.io/topology-mode"])
+
+	// the policies are patched on every reconciliation
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	var patches []string
+	for _, action := range mocks.kubeClient.(*fake.Clientset).Actions() {
+		if patch, ok := action.(k8sTesting.PatchAction); ok && patch.GetName() == "podinfo" {
+			patches = append(patches, string(patch.GetPatch()))
+		}
+	}
+	require.Len(t, patches, 2)
+	assert.JSONEq(t, `{"spec":{"internalTrafficPolicy":"Local","trafficDistribution":"PreferClose"}}`, patches[1])
+}
+
+func TestServiceRouter_PortMappings(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		ports: map[string]int32{
+			"http-metrics": 9797,
+			"tcp-app-2":    8080,
+		},
+	}
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.PortDiscoveryRules = &flaggerv1.PortDiscoveryRules{
+		Mappings: []flaggerv1.PortMapping{
+			{ContainerPort: intstr.FromString("http-metrics"), Port: 80},
+			{ContainerPort: intstr.FromInt(8080), Name: "http-legacy"},
+		},
+	}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	ports := make(map[string]corev1.ServicePort)
+	for _, p := range svc.Spec.Ports {
+		ports[p.Name] = p
+	}
+	require.Len(t, ports, 3)
+	assert.Equal(t, int32(80), ports["http-metrics"].Port)
+	assert.Equal(t, int32(9797), ports["http-metrics"].TargetPort.IntVal)
+	assert.Equal(t, int32(8080), ports["http-legacy"].Port)
+	assert.Equal(t, int32(8080), ports["http-legacy"].TargetPort.IntVal)
+}
+
+func TestServiceRouter_AppProtocol(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		ports: map[string]int32{
+			"http-metrics": 9797,
+		},
+	}
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.AppProtocol = "grpc"
+	canary.Spec.Service.PortDiscoveryRules = &flaggerv1.PortDiscoveryRules{
+		Mappings: []flaggerv1.PortMapping{
+			{ContainerPort: intstr.FromString("http-metrics"), AppProtocol: "http"},
+		},
+	}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, svc.Spec.Ports, 2)
+	assert.Equal(t, "grpc", *svc.Spec.Ports[0].AppProtocol)
+	assert.Equal(t, "http", *svc.Spec.Ports[1].AppProtocol)
+
+	// the app protocol is removed from the existing service
+	canary.Spec.Service.AppProtocol = ""
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	svc, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, svc.Spec.Ports[0].AppProtocol)
+}
+
+func TestServiceRouter_ServiceMonitors(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.ServiceMonitor = &flaggerv1.ServiceMonitor{
+		Interval: "15s",
+		Labels:   map[string]string{"release": "prometheus"},
+	}
+
+	err := router.Initialize(canary)
+	require.NoError(t, err)
+
+	for name, version := range map[string]string{"podinfo-canary": "canary", "podinfo-primary": "primary"} {
+		sm, err := mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "prometheus", sm.Labels["release"])
+		assert.Equal(t, name, sm.Spec.Selector.MatchLabels["app"])
+		require.Len(t, sm.Spec.Endpoints, 1)
+		assert.Equal(t, "http", sm.Spec.Endpoints[0].Port)
+		assert.Equal(t, "15s", sm.Spec.Endpoints[0].Interval)
+		require.Len(t, sm.Spec.Endpoints[0].RelabelConfigs, 1)
+		assert.Equal(t, "version", sm.Spec.Endpoints[0].RelabelConfigs[0].TargetLabel)
+		assert.Equal(t, version, sm.Spec.Endpoints[0].RelabelConfigs[0].Replacement)
+	}
+
+	// update the scrape endpoint
+	canary.Spec.Service.ServiceMonitor.Path = "/stats"
+	err = router.Initialize(canary)
+	require.NoError(t, err)
+
+	sm, err := mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "/stats", sm.Spec.Endpoints[0].Path)
+
+	// the service monitors are removed when disabled
+	canary.Spec.Service.ServiceMonitor = nil
+	err = router.Initialize(canary)
+	require.NoError(t, err)
+
+	_, err = mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestServiceRouter_NetworkPolicy(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.NetworkPolicy = &flaggerv1.CanaryNetworkPolicy{
+		From: []networkingv1.NetworkPolicyPeer{
+			{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"name": "ingress-nginx"},
+				},
+			},
+		},
+	}
+
+	err := router.Initialize(canary)
+	require.NoError(t, err)
+
+	np, err := mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", np.Spec.PodSelector.MatchLabels["app"])
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, np.Spec.PolicyTypes)
+	require.Len(t, np.Spec.Ingress, 1)
+	require.Len(t, np.Spec.Ingress[0].From, 1)
+	assert.Equal(t, "ingress-nginx", np.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels["name"])
+
+	// add the load tester to the allowed sources
+	canary.Spec.Service.NetworkPolicy.From = append(canary.Spec.Service.NetworkPolicy.From, networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "flagger-loadtester"},
+		},
+	})
+	err = router.Initialize(canary)
+	require.NoError(t, err)
+
+	np, err = mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, np.Spec.Ingress[0].From, 2)
+
+	// the network policy is removed when disabled
+	canary.Spec.Service.NetworkPolicy = nil
+	err = router.Initialize(canary)
+	require.NoError(t, err)
+
+	_, err = mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestServiceRouter_RoleMetadata(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Service.Apex = &flaggerv1.CustomMetadata{
+		Annotations: map[string]string{"external-dns.alpha.kubernetes.io/hostname": "podinfo.example.com"},
+	}
+	canary.Spec.Service.Canary = &flaggerv1.CustomMetadata{
+		Annotations: map[string]string{"external-dns.alpha.kubernetes.io/hostname": "canary.podinfo.example.com"},
+	}
+	canary.Spec.Service.Primary = &flaggerv1.CustomMetadata{
+		Labels: map[string]string{"role": "primary"},
+	}
+
+	err := router.Initialize(canary)
+	require.NoError(t, err)
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	apexSvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo.example.com", apexSvc.Annotations["external-dns.alpha.kubernetes.io/hostname"])
+	assert.Empty(t, apexSvc.Labels["role"])
+
+	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "canary.podinfo.example.com", canarySvc.Annotations["external-dns.alpha.kubernetes.io/hostname"])
+
+	primarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", primarySvc.Labels["role"])
+	assert.Empty(t, primarySvc.Annotations["external-dns.alpha.kubernetes.io/hostname"])
+
+	// the selector labels are not written to the canary spec
+	assert.Empty(t, canary.Spec.Service.Apex.Labels)
+	assert.Equal(t, map[string]string{"role": "primary"}, canary.Spec.Service.Primary.Labels)
+}
+
+func TestServiceRouter_ApexMode(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+	canary := mocks.canary.DeepCopy()
+
+	// the unmanaged apex service is not created
+	canary.Spec.Service.ApexMode = flaggerv1.ApexModeUnmanaged
+	err := router.Initialize(canary)
+	require.NoError(t, err)
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	_, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// the unmanaged apex service is not reverted on finalize
+	err = router.Finalize(canary)
+	require.NoError(t, err)
+
+	// the ClusterIP apex service is replaced with an ExternalName service
+	canary.Spec.Service.ApexMode = flaggerv1.ApexModeManaged
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	canary.Spec.Service.ApexMode = flaggerv1.ApexModeExternalName
+	canary.Spec.Service.ExternalName = "podinfo.legacy.example.com"
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeExternalName, svc.Spec.Type)
+	assert.Equal(t, "podinfo.legacy.example.com", svc.Spec.ExternalName)
+	assert.Empty(t, svc.Spec.Selector)
+
+	canary.Spec.Service.ExternalName = "podinfo.example.com"
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	svc, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo.example.com", svc.Spec.ExternalName)
+}
+
 func TestServiceRouter_Update(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &KubernetesDefaultRouter{
@@ -239,7 +625,7 @@ func TestServiceRouter_isOwnedByCanary(t *testing.T) {
 	}
 
 	for _, table := range tables {
-		hasOwnerRef, wasOwned := router.isOwnedByCanary(table.svc, mocks.canary.Name)
+		hasOwnerRef, wasOwned := router.isOwnedByCanary(table.svc, mocks.canary)
 		if table.isOwned && !wasOwned {
 			t.Error("Expected to be owned, but was not")
 		} else if !table.isOwned && wasOwned {
@@ -395,6 +781,33 @@ func TestServiceRouter_InitializeMetadata(t *testing.T) {
 	assert.Equal(t, "podinfo-primary", primarySvc.Labels["app"])
 }
 
+func TestServiceRouter_FinalizeKeepServices(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+
+	require.NoError(t, router.Initialize(mocks.canary))
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	mocks.canary.Spec.RevertPolicy = flaggerv1.RevertPolicyKeepServices
+	require.NoError(t, router.Finalize(mocks.canary))
+
+	for _, name := range []string{"podinfo", "podinfo-primary", "podinfo-canary"} {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, metav1.GetControllerOf(svc), name)
+	}
+
+	apexSvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", apexSvc.Spec.Selector["app"])
+}
+
 func TestServiceRouter_ReconcileMetadata(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &KubernetesDefaultRouter{
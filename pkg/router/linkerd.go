@@ -0,0 +1,339 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	linkerdv1beta2 "github.com/fluxcd/flagger/pkg/apis/linkerd/v1beta2"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// LinkerdRouter routes the A/B testing canaries with a Linkerd policy HTTPRoute
+// that matches the analysis headers, cookies and query params,
+// the weighted canaries are routed with a SMI traffic split
+type LinkerdRouter struct {
+	SmiRouter
+	linkerdClient clientset.Interface
+}
+
+// Reconcile creates or updates the HTTPRoute of the A/B testing canaries or the traffic split,
+// the route that is no longer used by the canary is removed
+func (lr *LinkerdRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if len(canary.GetAnalysis().Match) == 0 {
+		if err := lr.SmiRouter.Reconcile(canary); err != nil {
+			return err
+		}
+		return lr.deleteHTTPRoute(canary)
+	}
+
+	if err := lr.reconcileHTTPRoute(canary); err != nil {
+		return err
+	}
+	return lr.deleteTrafficSplit(canary)
+}
+
+// GetRoutes returns the destinations weight of the A/B testing route or of the traffic split
+func (lr *LinkerdRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	if len(canary.GetAnalysis().Match) == 0 {
+		return lr.SmiRouter.GetRoutes(canary)
+	}
+
+	apexName, primaryName, canaryName := canary.GetServiceNames(lr.primarySuffix)
+	route, err := lr.linkerdClient.PolicyV1beta2().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("HTTPRoute %s.%s get query error: %w", apexName, canary.Namespace, err)
+		return
+	}
+
+	primaryWeight, canaryWeight = -1, -1
+	if len(route.Spec.Rules) > 0 {
+		for _, backend := range route.Spec.Rules[0].BackendRefs {
+			if backend.Weight == nil {
+				continue
+			}
+			if backend.Name == primaryName {
+				primaryWeight = int(*backend.Weight)
+			}
+			if backend.Name == canaryName {
+				canaryWeight = int(*backend.Weight)
+			}
+		}
+	}
+	if primaryWeight == -1 || canaryWeight == -1 {
+		err = fmt.Errorf("HTTPRoute %s.%s does not contain routes for %s and %s",
+			apexName, canary.Namespace, primaryName, canaryName)
+	}
+	return
+}
+
+// SetRoutes updates the destinations weight of the A/B testing route or of the traffic split,
+// the requests that don't match the analysis are always routed to the primary
+func (lr *LinkerdRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+) error {
+	if len(canary.GetAnalysis().Match) == 0 {
+		return lr.SmiRouter.SetRoutes(canary, primaryWeight, canaryWeight, mirrored)
+	}
+
+	apexName, _, _ := canary.GetServiceNames(lr.primarySuffix)
+	route, err := lr.linkerdClient.PolicyV1beta2().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	routeClone := route.DeepCopy()
+	routeClone.Spec = lr.makeHTTPRouteSpec(canary, primaryWeight, canaryWeight)
+	_, err = lr.linkerdClient.PolicyV1beta2().HTTPRoutes(canary.Namespace).Update(context.TODO(), routeClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s update error: %w", apexName, canary.Namespace, err)
+	}
+	return nil
+}
+
+// Finalize leaves the HTTPRoute and the traffic split to be garbage collected with the canary
+func (lr *LinkerdRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (lr *LinkerdRouter) reconcileHTTPRoute(canary *flaggerv1.Canary) error {
+	apexName, _, canaryName := canary.GetServiceNames(lr.primarySuffix)
+	route, err := lr.linkerdClient.PolicyV1beta2().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		route = &linkerdv1beta2.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: lr.makeHTTPRouteSpec(canary, 100, 0),
+		}
+		_, err = lr.linkerdClient.PolicyV1beta2().HTTPRoutes(canary.Namespace).Create(context.TODO(), route, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("HTTPRoute %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+		lr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("HTTPRoute %s.%s created", apexName, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	// keep the current weights and update the matches
+	primaryWeight, canaryWeight := 100, 0
+	if len(route.Spec.Rules) > 0 {
+		for _, backend := range route.Spec.Rules[0].BackendRefs {
+			if backend.Weight != nil && backend.Name == canaryName {
+				canaryWeight = int(*backend.Weight)
+				primaryWeight = 100 - canaryWeight
+			}
+		}
+	}
+	spec := lr.makeHTTPRouteSpec(canary, primaryWeight, canaryWeight)
+	if diff := cmp.Diff(spec, route.Spec); diff != "" {
+		routeClone := route.DeepCopy()
+		routeClone.Spec = spec
+		_, err = lr.linkerdClient.PolicyV1beta2().HTTPRoutes(canary.Namespace).Update(context.TODO(), routeClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("HTTPRoute %s.%s update error: %w", apexName, canary.Namespace, err)
+		}
+		lr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("HTTPRoute %s.%s updated", apexName, canary.Namespace)
+	}
+	return nil
+}
+
+// makeHTTPRouteSpec routes the requests that match the analysis to the weighted
+// primary and canary backends, and all the other requests to the primary
+func (lr *LinkerdRouter) makeHTTPRouteSpec(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) linkerdv1beta2.HTTPRouteSpec {
+	apexName, primaryName, canaryName := canary.GetServiceNames(lr.primarySuffix)
+	port := canary.Spec.Service.Port
+
+	backend := func(name string, weight int) linkerdv1beta2.HTTPBackendRef {
+		return linkerdv1beta2.HTTPBackendRef{
+			Kind:   "Service",
+			Name:   name,
+			Port:   int32p(port),
+			Weight: int32p(int32(weight)),
+		}
+	}
+
+	return linkerdv1beta2.HTTPRouteSpec{
+		ParentRefs: []linkerdv1beta2.ParentReference{
+			{
+				Group: "core",
+				Kind:  "Service",
+				Name:  apexName,
+				Port:  int32p(port),
+			},
+		},
+		Rules: []linkerdv1beta2.HTTPRouteRule{
+			{
+				Matches: makeLinkerdMatches(canary.GetAnalysis().Match),
+				BackendRefs: []linkerdv1beta2.HTTPBackendRef{
+					backend(primaryName, primaryWeight),
+					backend(canaryName, canaryWeight),
+				},
+			},
+			{
+				Matches: []linkerdv1beta2.HTTPRouteMatch{
+					{
+						Path: &linkerdv1beta2.HTTPPathMatch{
+							Type:  linkerdv1beta2.PathMatchPathPrefix,
+							Value: "/",
+						},
+					},
+				},
+				BackendRefs: []linkerdv1beta2.HTTPBackendRef{
+					backend(primaryName, 100),
+				},
+			},
+		},
+	}
+}
+
+// deleteHTTPRoute removes the HTTPRoute of a canary that no longer runs A/B testing
+func (lr *LinkerdRouter) deleteHTTPRoute(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames(lr.primarySuffix)
+	route, err := lr.linkerdClient.PolicyV1beta2().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	if !controlledByCanary(route.ObjectMeta, canary) {
+		return nil
+	}
+	err = lr.linkerdClient.PolicyV1beta2().HTTPRoutes(canary.Namespace).Delete(context.TODO(), apexName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("HTTPRoute %s.%s delete error: %w", apexName, canary.Namespace, err)
+	}
+	lr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("HTTPRoute %s.%s deleted", apexName, canary.Namespace)
+	return nil
+}
+
+// deleteTrafficSplit removes the traffic split of a canary that switched to A/B testing,
+// otherwise the traffic split would keep routing the clients of the apex service
+func (lr *LinkerdRouter) deleteTrafficSplit(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames(lr.primarySuffix)
+	ts, err := lr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("TrafficSplit %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	if !controlledByCanary(ts.ObjectMeta, canary) {
+		return nil
+	}
+	err = lr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Delete(context.TODO(), apexName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("TrafficSplit %s.%s delete error: %w", apexName, canary.Namespace, err)
+	}
+	lr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("TrafficSplit %s.%s deleted", apexName, canary.Namespace)
+	return nil
+}
+
+// makeLinkerdMatches converts the analysis matches to HTTPRoute matches,
+// the prefix and suffix matches are converted to regular expressions
+func makeLinkerdMatches(matches []istiov1alpha3.HTTPMatchRequest) []linkerdv1beta2.HTTPRouteMatch {
+	var result []linkerdv1beta2.HTTPRouteMatch
+	for _, match := range matches {
+		var m linkerdv1beta2.HTTPRouteMatch
+		for _, name := range sortedKeys(match.Headers) {
+			matchType, value := makeLinkerdMatchValue(match.Headers[name])
+			m.Headers = append(m.Headers, linkerdv1beta2.HTTPHeaderMatch{Type: matchType, Name: name, Value: value})
+		}
+		for _, name := range sortedKeys(match.QueryParams) {
+			matchType, value := makeLinkerdMatchValue(match.QueryParams[name])
+			m.QueryParams = append(m.QueryParams, linkerdv1beta2.HTTPQueryParamMatch{Type: matchType, Name: name, Value: value})
+		}
+		if match.Uri != nil {
+			m.Path = makeLinkerdPathMatch(*match.Uri)
+		}
+		if match.Method != nil && match.Method.Exact != "" {
+			m.Method = match.Method.Exact
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+func makeLinkerdMatchValue(sm istiov1alpha1.StringMatch) (linkerdv1beta2.MatchType, string) {
+	switch {
+	case sm.Prefix != "":
+		return linkerdv1beta2.MatchRegularExpression, "^" + regexp.QuoteMeta(sm.Prefix) + ".*"
+	case sm.Suffix != "":
+		return linkerdv1beta2.MatchRegularExpression, ".*" + regexp.QuoteMeta(sm.Suffix) + "$"
+	case sm.Regex != "":
+		return linkerdv1beta2.MatchRegularExpression, sm.Regex
+	default:
+		return linkerdv1beta2.MatchExact, sm.Exact
+	}
+}
+
+func makeLinkerdPathMatch(sm istiov1alpha1.StringMatch) *linkerdv1beta2.HTTPPathMatch {
+	switch {
+	case sm.Prefix != "":
+		return &linkerdv1beta2.HTTPPathMatch{Type: linkerdv1beta2.PathMatchPathPrefix, Value: sm.Prefix}
+	case sm.Suffix != "":
+		return &linkerdv1beta2.HTTPPathMatch{Type: linkerdv1beta2.PathMatchRegularExpression, Value: ".*" + regexp.QuoteMeta(sm.Suffix) + "$"}
+	case sm.Regex != "":
+		return &linkerdv1beta2.HTTPPathMatch{Type: linkerdv1beta2.PathMatchRegularExpression, Value: sm.Regex}
+	default:
+		return &linkerdv1beta2.HTTPPathMatch{Type: linkerdv1beta2.PathMatchExact, Value: sm.Exact}
+	}
+}
+
+func sortedKeys(m map[string]istiov1alpha1.StringMatch) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func int32p(i int32) *int32 {
+	return &i
+}
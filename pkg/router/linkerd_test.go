@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	linkerdv1beta2 "github.com/fluxcd/flagger/pkg/apis/linkerd/v1beta2"
+)
+
+func TestLinkerdRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &LinkerdRouter{
+		SmiRouter: SmiRouter{
+			logger:        mocks.logger,
+			flaggerClient: mocks.flaggerClient,
+			smiClient:     mocks.meshClient,
+			kubeClient:    mocks.kubeClient,
+			targetMesh:    flaggerv1.LinkerdProvider,
+		},
+		linkerdClient: mocks.meshClient,
+	}
+	canary := mocks.abtest.DeepCopy()
+	canary.Spec.Analysis.Match[0].Headers["cookie"] = istiov1alpha1.StringMatch{Regex: "^(.*?;)?(canary=always)(;.*)?$"}
+	canary.Spec.Analysis.Match[0].Headers["x-region"] = istiov1alpha1.StringMatch{Prefix: "us."}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	route, err := mocks.meshClient.PolicyV1beta2().HTTPRoutes("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, route.Spec.ParentRefs, 1)
+	assert.Equal(t, "Service", route.Spec.ParentRefs[0].Kind)
+	assert.Equal(t, "abtest", route.Spec.ParentRefs[0].Name)
+	require.Len(t, route.Spec.Rules, 2)
+	require.Len(t, route.Spec.Rules[0].Matches, 1)
+	assert.Equal(t, []linkerdv1beta2.HTTPHeaderMatch{
+		{Type: linkerdv1beta2.MatchRegularExpression, Name: "cookie", Value: "^(.*?;)?(canary=always)(;.*)?$"},
+		{Type: linkerdv1beta2.MatchRegularExpression, Name: "x-region", Value: `^us\..*`},
+		{Type: linkerdv1beta2.MatchExact, Name: "x-user-type", Value: "test"},
+	}, route.Spec.Rules[0].Matches[0].Headers)
+	require.Len(t, route.Spec.Rules[1].BackendRefs, 1)
+	assert.Equal(t, "abtest-primary", route.Spec.Rules[1].BackendRefs[0].Name)
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+
+	// the matched requests are routed to the canary, the others stay on the primary
+	err = router.SetRoutes(canary, 0, 100, false)
+	require.NoError(t, err)
+	p, c, _, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	// the weights are kept when the matches change
+	canary.Spec.Analysis.Match[0].Headers["x-user-type"] = istiov1alpha1.StringMatch{Exact: "beta"}
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+	route, err = mocks.meshClient.PolicyV1beta2().HTTPRoutes("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "beta", route.Spec.Rules[0].Matches[0].Headers[2].Value)
+	assert.Equal(t, int32(100), *route.Spec.Rules[0].BackendRefs[1].Weight)
+	assert.Equal(t, int32(100), *route.Spec.Rules[1].BackendRefs[0].Weight)
+
+	// the route is removed when the canary switches to progressive traffic shifting
+	canary.Spec.Analysis.Match = nil
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+	_, err = mocks.meshClient.PolicyV1beta2().HTTPRoutes("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = mocks.meshClient.SplitV1alpha1().TrafficSplits("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// ForCluster returns a factory of routers that manage the services of another cluster
+func (factory *Factory) ForCluster(kubeClient kubernetes.Interface) *Factory {
+	clone := *factory
+	clone.kubeClient = kubeClient
+	return &clone
+}
+
+// RemoteKubernetesRouter is the router of the canary services in a remote cluster
+type RemoteKubernetesRouter struct {
+	Cluster string
+	Router  KubernetesRouter
+}
+
+// MultiClusterKubernetesRouter manages the canary services of the local and the remote clusters,
+// so that the service mesh discovers the primary and canary endpoints of all clusters
+type MultiClusterKubernetesRouter struct {
+	local   KubernetesRouter
+	remotes []RemoteKubernetesRouter
+}
+
+// NewMultiClusterKubernetesRouter returns a router that applies the service operations
+// of the local router to the remote clusters
+func NewMultiClusterKubernetesRouter(local KubernetesRouter, remotes []RemoteKubernetesRouter) *MultiClusterKubernetesRouter {
+	return &MultiClusterKubernetesRouter{local: local, remotes: remotes}
+}
+
+// Initialize creates or updates the primary and canary services in all clusters
+func (r *MultiClusterKubernetesRouter) Initialize(canary *flaggerv1.Canary) error {
+	return r.each(canary, KubernetesRouter.Initialize)
+}
+
+// Reconcile creates or updates the main service in all clusters
+func (r *MultiClusterKubernetesRouter) Reconcile(canary *flaggerv1.Canary) error {
+	return r.each(canary, KubernetesRouter.Reconcile)
+}
+
+// Finalize reverts the services in all clusters
+func (r *MultiClusterKubernetesRouter) Finalize(canary *flaggerv1.Canary) error {
+	return r.each(canary, KubernetesRouter.Finalize)
+}
+
+// each applies the operation to all clusters and returns the first error
+func (r *MultiClusterKubernetesRouter) each(canary *flaggerv1.Canary, fn func(KubernetesRouter, *flaggerv1.Canary) error) error {
+	err := fn(r.local, canary)
+	for _, remote := range r.remotes {
+		if rerr := fn(remote.Router, canary); rerr != nil && err == nil {
+			err = fmt.Errorf("cluster %s: %w", remote.Cluster, rerr)
+		}
+	}
+	return err
+}
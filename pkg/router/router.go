@@ -20,6 +20,7 @@ import flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 
 const configAnnotation = "flagger.kubernetes.io/original-configuration"
 const kubectlAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+const topologyModeAnnotation = "service.kubernetes.io/topology-mode"
 
 type Interface interface {
 	Reconcile(canary *flaggerv1.Canary) error
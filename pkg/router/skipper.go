@@ -55,8 +55,9 @@ const (
 )
 
 type SkipperRouter struct {
-	kubeClient kubernetes.Interface
-	logger     *zap.SugaredLogger
+	kubeClient    kubernetes.Interface
+	logger        *zap.SugaredLogger
+	primarySuffix string
 }
 
 // Reconcile creates or updates the ingresses
@@ -65,7 +66,7 @@ func (skp *SkipperRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return fmt.Errorf("ingress selector is empty")
 	}
 
-	apexSvcName, primarySvcName, canarySvcName := canary.GetServiceNames()
+	apexSvcName, primarySvcName, canarySvcName := canary.GetServiceNames(skp.primarySuffix)
 	apexIngressName, canaryIngressName := skp.getIngressNames(canary.Spec.IngressRef.Name)
 
 	// retrieving apex ingress
@@ -142,7 +143,7 @@ func (skp *SkipperRouter) Reconcile(canary *flaggerv1.Canary) error {
 }
 
 func (skp *SkipperRouter) GetRoutes(canary *flaggerv1.Canary) (primaryWeight, canaryWeight int, mirrored bool, err error) {
-	_, primarySvcName, canarySvcName := canary.GetServiceNames()
+	_, primarySvcName, canarySvcName := canary.GetServiceNames(skp.primarySuffix)
 
 	_, canaryIngressName := skp.getIngressNames(canary.Spec.IngressRef.Name)
 	canaryIngress, err := skp.kubeClient.NetworkingV1beta1().Ingresses(canary.Namespace).Get(context.TODO(), canaryIngressName, metav1.GetOptions{})
@@ -174,7 +175,7 @@ func (skp *SkipperRouter) GetRoutes(canary *flaggerv1.Canary) (primaryWeight, ca
 }
 
 func (skp *SkipperRouter) SetRoutes(canary *flaggerv1.Canary, primaryWeight, canaryWeight int, _ bool) (err error) {
-	_, primarySvcName, canarySvcName := canary.GetServiceNames()
+	_, primarySvcName, canarySvcName := canary.GetServiceNames(skp.primarySuffix)
 	_, canaryIngressName := skp.getIngressNames(canary.Spec.IngressRef.Name)
 	canaryIngress, err := skp.kubeClient.NetworkingV1beta1().Ingresses(canary.Namespace).Get(context.TODO(), canaryIngressName, metav1.GetOptions{})
 	if err != nil {
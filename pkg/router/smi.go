@@ -42,11 +42,12 @@ type SmiRouter struct {
 	smiClient     clientset.Interface
 	logger        *zap.SugaredLogger
 	targetMesh    string
+	primarySuffix string
 }
 
 // Reconcile creates or updates the SMI traffic split
 func (sr *SmiRouter) Reconcile(canary *flaggerv1.Canary) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(sr.primarySuffix)
 
 	var host string
 	if len(canary.Spec.Service.Hosts) > 0 {
@@ -125,7 +126,7 @@ func (sr *SmiRouter) GetRoutes(canary *flaggerv1.Canary) (
 	mirrored bool,
 	err error,
 ) {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(sr.primarySuffix)
 	ts, err := sr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
 		err = fmt.Errorf("TrafficSplit %s.%s get query error %v", apexName, canary.Namespace, err)
@@ -159,7 +160,7 @@ func (sr *SmiRouter) SetRoutes(
 	canaryWeight int,
 	_ bool,
 ) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(sr.primarySuffix)
 	ts, err := sr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("TrafficSplit %s.%s get query error %v", apexName, canary.Namespace, err)
@@ -188,8 +189,13 @@ func (sr *SmiRouter) SetRoutes(
 }
 
 func (sr *SmiRouter) makeAnnotations(gateways []string) map[string]string {
+	return makeSmiAnnotations(sr.targetMesh, gateways)
+}
+
+// makeSmiAnnotations returns the traffic split annotations of the target mesh
+func makeSmiAnnotations(targetMesh string, gateways []string) map[string]string {
 	res := make(map[string]string)
-	if sr.targetMesh == "istio" && len(gateways) > 0 {
+	if targetMesh == "istio" && len(gateways) > 0 {
 		g, _ := json.Marshal(gateways)
 		res["VirtualService.v1alpha3.networking.istio.io/spec.gateways"] = string(g)
 	}
@@ -198,7 +204,7 @@ func (sr *SmiRouter) makeAnnotations(gateways []string) map[string]string {
 
 // getWithConvert overrides invalid traffic split and sets weight based on the canary status
 func (sr *SmiRouter) getWithConvert(canary *flaggerv1.Canary, host string) (*smiv1alpha2.TrafficSplit, error) {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(sr.primarySuffix)
 	ts, err := sr.smiClient.SplitV1alpha2().TrafficSplits(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if errors.IsInvalid(err) {
 		t := &smiv1alpha2.TrafficSplit{
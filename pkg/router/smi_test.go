@@ -47,7 +47,7 @@ func TestSmiRouter_Sync(t *testing.T) {
 	dests := ts.Spec.Backends
 	assert.Len(t, dests, 2)
 
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames("")
 	assert.Equal(t, ts.Spec.Service, apexName)
 
 	var pRoute smiv1.TrafficSplitBackend
@@ -104,7 +104,7 @@ func TestSmiRouter_SetRoutes(t *testing.T) {
 
 	var pRoute smiv1.TrafficSplitBackend
 	var cRoute smiv1.TrafficSplitBackend
-	_, primaryName, canaryName := canary.GetServiceNames()
+	_, primaryName, canaryName := canary.GetServiceNames("")
 
 	for _, dest := range ts.Spec.Backends {
 		if dest.Service == primaryName {
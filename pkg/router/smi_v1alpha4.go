@@ -0,0 +1,321 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	smiv1alpha4 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha4"
+	specsv1alpha4 "github.com/fluxcd/flagger/pkg/apis/smispecs/v1alpha4"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// Smiv1alpha4Router is managing SMI v1alpha4 traffic splits,
+// the A/B testing splits are scoped to the traffic matched by a HTTP route group
+type Smiv1alpha4Router struct {
+	kubeClient    kubernetes.Interface
+	flaggerClient clientset.Interface
+	smiClient     clientset.Interface
+	logger        *zap.SugaredLogger
+	targetMesh    string
+	primarySuffix string
+}
+
+// Reconcile creates or updates the SMI traffic split and the HTTP route group of the A/B testing matches
+func (sr *Smiv1alpha4Router) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames(sr.primarySuffix)
+
+	var host string
+	if len(canary.Spec.Service.Hosts) > 0 {
+		host = canary.Spec.Service.Hosts[0]
+	} else {
+		host = apexName
+	}
+
+	tsSpec := smiv1alpha4.TrafficSplitSpec{
+		Service: host,
+		Backends: []smiv1alpha4.TrafficSplitBackend{
+			{
+				Service: canaryName,
+				Weight:  0,
+			},
+			{
+				Service: primaryName,
+				Weight:  100,
+			},
+		},
+	}
+
+	if len(canary.GetAnalysis().Match) > 0 {
+		if err := sr.reconcileHTTPRouteGroup(canary); err != nil {
+			return fmt.Errorf("reconcileHTTPRouteGroup failed: %w", err)
+		}
+		apiGroup := specsv1alpha4.SchemeGroupVersion.Group
+		tsSpec.Matches = []corev1.TypedLocalObjectReference{
+			{
+				APIGroup: &apiGroup,
+				Kind:     "HTTPRouteGroup",
+				Name:     apexName,
+			},
+		}
+	} else if err := sr.deleteHTTPRouteGroup(canary); err != nil {
+		return err
+	}
+
+	ts, err := sr.smiClient.SplitV1alpha4().TrafficSplits(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	// create traffic split
+	if errors.IsNotFound(err) {
+		t := &smiv1alpha4.TrafficSplit{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+				Annotations: makeSmiAnnotations(sr.targetMesh, canary.Spec.Service.Gateways),
+			},
+			Spec: tsSpec,
+		}
+
+		_, err := sr.smiClient.SplitV1alpha4().TrafficSplits(canary.Namespace).Create(context.TODO(), t, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("TrafficSplit %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficSplit %s.%s created", t.GetName(), canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("TrafficSplit %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	// update traffic split but keep the backend weights
+	if diff := cmp.Diff(tsSpec, ts.Spec, cmpopts.IgnoreFields(smiv1alpha4.TrafficSplitBackend{}, "Weight")); diff != "" {
+		tsClone := ts.DeepCopy()
+		tsClone.Spec = tsSpec
+
+		_, err := sr.smiClient.SplitV1alpha4().TrafficSplits(canary.Namespace).Update(context.TODO(), tsClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("TrafficSplit %s.%s update error: %w", apexName, canary.Namespace, err)
+		}
+
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("TrafficSplit %s.%s updated", apexName, canary.Namespace)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the destinations weight for primary and canary
+func (sr *Smiv1alpha4Router) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames(sr.primarySuffix)
+	ts, err := sr.smiClient.SplitV1alpha4().TrafficSplits(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("TrafficSplit %s.%s get query error %w", apexName, canary.Namespace, err)
+		return
+	}
+
+	for _, r := range ts.Spec.Backends {
+		if r.Service == primaryName {
+			primaryWeight = r.Weight
+		}
+		if r.Service == canaryName {
+			canaryWeight = r.Weight
+		}
+	}
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		err = fmt.Errorf("TrafficSplit %s.%s does not contain routes for %s and %s",
+			apexName, canary.Namespace, primaryName, canaryName)
+	}
+
+	mirrored = false
+
+	return
+}
+
+// SetRoutes updates the destinations weight for primary and canary
+func (sr *Smiv1alpha4Router) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames(sr.primarySuffix)
+	ts, err := sr.smiClient.SplitV1alpha4().TrafficSplits(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("TrafficSplit %s.%s get query error %w", apexName, canary.Namespace, err)
+	}
+
+	tsClone := ts.DeepCopy()
+	tsClone.Spec.Backends = []smiv1alpha4.TrafficSplitBackend{
+		{
+			Service: canaryName,
+			Weight:  canaryWeight,
+		},
+		{
+			Service: primaryName,
+			Weight:  primaryWeight,
+		},
+	}
+
+	_, err = sr.smiClient.SplitV1alpha4().TrafficSplits(canary.Namespace).Update(context.TODO(), tsClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("TrafficSplit %s.%s update error %w", apexName, canary.Namespace, err)
+	}
+
+	return nil
+}
+
+func (sr *Smiv1alpha4Router) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// reconcileHTTPRouteGroup creates or updates the HTTP route group that holds the A/B testing matches
+func (sr *Smiv1alpha4Router) reconcileHTTPRouteGroup(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames(sr.primarySuffix)
+	spec := specsv1alpha4.HTTPRouteGroupSpec{
+		Matches: makeSmiMatches(apexName, canary.GetAnalysis().Match),
+	}
+
+	routeGroup, err := sr.smiClient.SpecsV1alpha4().HTTPRouteGroups(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		routeGroup = &specsv1alpha4.HTTPRouteGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      apexName,
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: spec,
+		}
+
+		_, err := sr.smiClient.SpecsV1alpha4().HTTPRouteGroups(canary.Namespace).Create(context.TODO(), routeGroup, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("HTTPRouteGroup %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("HTTPRouteGroup %s.%s created", apexName, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("HTTPRouteGroup %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	if diff := cmp.Diff(spec, routeGroup.Spec, cmpopts.EquateEmpty()); diff != "" {
+		clone := routeGroup.DeepCopy()
+		clone.Spec = spec
+
+		_, err := sr.smiClient.SpecsV1alpha4().HTTPRouteGroups(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("HTTPRouteGroup %s.%s update error: %w", apexName, canary.Namespace, err)
+		}
+
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("HTTPRouteGroup %s.%s updated", apexName, canary.Namespace)
+	}
+
+	return nil
+}
+
+// deleteHTTPRouteGroup removes the HTTP route group generated for the A/B testing matches
+func (sr *Smiv1alpha4Router) deleteHTTPRouteGroup(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames(sr.primarySuffix)
+	routeGroup, err := sr.smiClient.SpecsV1alpha4().HTTPRouteGroups(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("HTTPRouteGroup %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	if !controlledByCanary(routeGroup.ObjectMeta, canary) {
+		return nil
+	}
+
+	err = sr.smiClient.SpecsV1alpha4().HTTPRouteGroups(canary.Namespace).Delete(context.TODO(), apexName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("HTTPRouteGroup %s.%s delete error: %w", apexName, canary.Namespace, err)
+	}
+	sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("HTTPRouteGroup %s.%s deleted", apexName, canary.Namespace)
+	return nil
+}
+
+// makeSmiMatches converts the A/B testing conditions to HTTP route group matches,
+// the header and path values are converted to regular expressions
+func makeSmiMatches(apexName string, matches []istiov1alpha3.HTTPMatchRequest) []specsv1alpha4.HTTPMatch {
+	var result []specsv1alpha4.HTTPMatch
+	for i, match := range matches {
+		m := specsv1alpha4.HTTPMatch{
+			Name: fmt.Sprintf("%s-%d", apexName, i),
+		}
+		if len(match.Headers) > 0 {
+			m.Headers = make(map[string]string, len(match.Headers))
+			for name, value := range match.Headers {
+				m.Headers[name] = makeSmiRegex(value)
+			}
+		}
+		if match.Uri != nil {
+			m.PathRegex = makeSmiRegex(*match.Uri)
+		}
+		if match.Method != nil && match.Method.Exact != "" {
+			m.Methods = []string{match.Method.Exact}
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+func makeSmiRegex(match istiov1alpha1.StringMatch) string {
+	switch {
+	case match.Regex != "":
+		return match.Regex
+	case match.Prefix != "":
+		return "^" + regexp.QuoteMeta(match.Prefix) + ".*"
+	case match.Suffix != "":
+		return ".*" + regexp.QuoteMeta(match.Suffix) + "$"
+	default:
+		return "^" + regexp.QuoteMeta(match.Exact) + "$"
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+)
+
+func TestSmiv1alpha4Router_Sync(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &Smiv1alpha4Router{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		smiClient:     mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	ts, err := mocks.meshClient.SplitV1alpha4().TrafficSplits("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", ts.Spec.Service)
+	assert.Empty(t, ts.Spec.Matches)
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+
+	// the weights are kept on reconciliation
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+	p, c, _, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+}
+
+func TestSmiv1alpha4Router_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &Smiv1alpha4Router{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		smiClient:     mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+	canary := mocks.abtest.DeepCopy()
+	canary.Spec.Analysis.Match[0].Headers["x-region"] = istiov1alpha1.StringMatch{Prefix: "us."}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	routeGroup, err := mocks.meshClient.SpecsV1alpha4().HTTPRouteGroups("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, routeGroup.Spec.Matches, 1)
+	assert.Equal(t, "abtest-0", routeGroup.Spec.Matches[0].Name)
+	assert.Equal(t, map[string]string{
+		"x-user-type": "^test$",
+		"x-region":    `^us\..*`,
+	}, routeGroup.Spec.Matches[0].Headers)
+
+	ts, err := mocks.meshClient.SplitV1alpha4().TrafficSplits("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, ts.Spec.Matches, 1)
+	assert.Equal(t, "specs.smi-spec.io", *ts.Spec.Matches[0].APIGroup)
+	assert.Equal(t, "HTTPRouteGroup", ts.Spec.Matches[0].Kind)
+	assert.Equal(t, "abtest", ts.Spec.Matches[0].Name)
+
+	// the matched requests are routed to the canary
+	err = router.SetRoutes(canary, 0, 100, false)
+	require.NoError(t, err)
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	// the route group is removed when the canary switches to progressive traffic shifting
+	canary.Spec.Analysis.Match = nil
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+	_, err = mocks.meshClient.SpecsV1alpha4().HTTPRouteGroups("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	ts, err = mocks.meshClient.SplitV1alpha4().TrafficSplits("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, ts.Spec.Matches)
+}
@@ -35,11 +35,12 @@ import (
 type TraefikRouter struct {
 	traefikClient clientset.Interface
 	logger        *zap.SugaredLogger
+	primarySuffix string
 }
 
 // Reconcile creates or updates the Traefik service
 func (tr *TraefikRouter) Reconcile(canary *flaggerv1.Canary) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(tr.primarySuffix)
 
 	newSpec := traefikv1alpha1.ServiceSpec{
 		Weighted: &traefikv1alpha1.WeightedRoundRobin{
@@ -51,12 +52,13 @@ func (tr *TraefikRouter) Reconcile(canary *flaggerv1.Canary) error {
 					Weight:    100,
 				},
 			},
+			Sticky: tr.makeSticky(canary),
 		},
 	}
 
 	traefikService, err := tr.traefikClient.TraefikV1alpha1().TraefikServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if errors.IsNotFound(err) {
-		tsMetadata := canary.Spec.Service.Apex
+		tsMetadata := canary.Spec.Service.Apex.DeepCopy()
 		if tsMetadata == nil {
 			tsMetadata = &flaggerv1.CustomMetadata{}
 		}
@@ -114,6 +116,13 @@ func (tr *TraefikRouter) Reconcile(canary *flaggerv1.Canary) error {
 			traefikService.Spec,
 			cmpopts.IgnoreFields(traefikv1alpha1.Service{}, "Weight"),
 		); diff != "" {
+			for i, s := range newSpec.Weighted.Services {
+				for _, current := range traefikService.Spec.Weighted.Services {
+					if current.Name == s.Name {
+						newSpec.Weighted.Services[i].Weight = current.Weight
+					}
+				}
+			}
 
 			clone := traefikService.DeepCopy()
 			clone.Spec = newSpec
@@ -137,7 +146,7 @@ func (tr *TraefikRouter) GetRoutes(canary *flaggerv1.Canary) (
 	mirrored bool,
 	err error,
 ) {
-	apexName, primaryName, _ := canary.GetServiceNames()
+	apexName, primaryName, _ := canary.GetServiceNames(tr.primarySuffix)
 
 	traefikService, err := tr.traefikClient.TraefikV1alpha1().TraefikServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
@@ -169,7 +178,7 @@ func (tr *TraefikRouter) SetRoutes(
 	canaryWeight int,
 	_ bool,
 ) error {
-	apexName, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames(tr.primarySuffix)
 
 	if primaryWeight == 0 && canaryWeight == 0 {
 		return fmt.Errorf("RoutingRule %s.%s update failed: no valid weights", apexName, canary.Namespace)
@@ -197,6 +206,7 @@ func (tr *TraefikRouter) SetRoutes(
 	}
 
 	traefikService.Spec.Weighted.Services = services
+	traefikService.Spec.Weighted.Sticky = tr.makeSticky(canary)
 
 	_, err = tr.traefikClient.TraefikV1alpha1().TraefikServices(canary.Namespace).Update(context.TODO(), traefikService, metav1.UpdateOptions{})
 	if err != nil {
@@ -208,3 +218,19 @@ func (tr *TraefikRouter) SetRoutes(
 func (tr *TraefikRouter) Finalize(_ *flaggerv1.Canary) error {
 	return nil
 }
+
+// makeSticky returns the sticky cookie of the weighted services,
+// so that the users stay on the version they were first routed to
+func (tr *TraefikRouter) makeSticky(canary *flaggerv1.Canary) *traefikv1alpha1.Sticky {
+	affinity := canary.GetAnalysis().SessionAffinity
+	if affinity == nil {
+		return nil
+	}
+	return &traefikv1alpha1.Sticky{
+		Cookie: &traefikv1alpha1.Cookie{
+			Name:     affinity.CookieName,
+			HTTPOnly: true,
+			MaxAge:   affinity.MaxAge,
+		},
+	}
+}
@@ -156,3 +156,37 @@ func TestTraefikRouter_GetRoutes(t *testing.T) {
 	assert.Equal(t, 0, c)
 	assert.False(t, m)
 }
+
+func TestTraefikRouter_SessionAffinity(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &TraefikRouter{
+		traefikClient: mocks.meshClient,
+		logger:        mocks.logger,
+	}
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Analysis.SessionAffinity = &flaggerv1.SessionAffinity{CookieName: "podinfo-version", MaxAge: 3600}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(canary, 90, 10, false)
+	require.NoError(t, err)
+
+	ts, err := router.traefikClient.TraefikV1alpha1().TraefikServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, ts.Spec.Weighted.Sticky)
+	assert.Equal(t, "podinfo-version", ts.Spec.Weighted.Sticky.Cookie.Name)
+	assert.Equal(t, 3600, ts.Spec.Weighted.Sticky.Cookie.MaxAge)
+	assert.Len(t, ts.Spec.Weighted.Services, 2)
+
+	// the sticky cookie is removed along with the session affinity
+	canary.Spec.Analysis.SessionAffinity = nil
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	ts, err = router.traefikClient.TraefikV1alpha1().TraefikServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, ts.Spec.Weighted.Sticky)
+	assert.Equal(t, uint(10), ts.Spec.Weighted.Services[1].Weight)
+}
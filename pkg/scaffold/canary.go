@@ -0,0 +1,253 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffold
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// Options selects the target workload and the provider of the generated canary
+type Options struct {
+	// Target is the workload reference in the kind/name format, e.g. deploy/podinfo
+	Target    string
+	Namespace string
+	Provider  string
+	// SelectorLabels are the pod labels that Flagger uses to create pod selectors
+	SelectorLabels []string
+}
+
+// GenerateCanary inspects the target workload, its service and autoscaler
+// and returns a canary with the default analysis for the provider,
+// the warnings describe the settings that must be reviewed before applying the canary
+func GenerateCanary(ctx context.Context, kubeClient kubernetes.Interface, opts Options) (*flaggerv1.Canary, []string, error) {
+	kind, name, err := parseTarget(opts.Target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var podSpec corev1.PodSpec
+	var podLabels map[string]string
+	switch kind {
+	case "Deployment":
+		dep, err := kubeClient.AppsV1().Deployments(opts.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("deployment %s.%s get query error: %w", name, opts.Namespace, err)
+		}
+		podSpec, podLabels = dep.Spec.Template.Spec, dep.Spec.Template.Labels
+	case "DaemonSet":
+		ds, err := kubeClient.AppsV1().DaemonSets(opts.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("daemonset %s.%s get query error: %w", name, opts.Namespace, err)
+		}
+		podSpec, podLabels = ds.Spec.Template.Spec, ds.Spec.Template.Labels
+	case "StatefulSet":
+		sts, err := kubeClient.AppsV1().StatefulSets(opts.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("statefulset %s.%s get query error: %w", name, opts.Namespace, err)
+		}
+		podSpec, podLabels = sts.Spec.Template.Spec, sts.Spec.Template.Labels
+	}
+
+	var warnings []string
+	if !hasSelectorLabel(podLabels, opts.SelectorLabels) {
+		warnings = append(warnings, fmt.Sprintf("%s %s.%s pod template has none of the selector labels %s",
+			kind, name, opts.Namespace, strings.Join(opts.SelectorLabels, ", ")))
+	}
+
+	cd := &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: flaggerv1.SchemeGroupVersion.String(),
+			Kind:       flaggerv1.CanaryKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: opts.Namespace,
+		},
+		Spec: flaggerv1.CanarySpec{
+			Provider: opts.Provider,
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       kind,
+				Name:       name,
+			},
+			Analysis: defaultAnalysis(opts.Provider),
+		},
+	}
+
+	service, err := findService(ctx, kubeClient, opts.Namespace, name, podSpec)
+	if err != nil {
+		return nil, nil, err
+	}
+	if service == nil {
+		warnings = append(warnings, fmt.Sprintf("no ports found for %s %s.%s, set spec.service.port", kind, name, opts.Namespace))
+	} else {
+		cd.Spec.Service = *service
+	}
+
+	if kind == "Deployment" {
+		hpas, err := kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(opts.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("HorizontalPodAutoscaler list query error: %w", err)
+		}
+		for _, hpa := range hpas.Items {
+			if hpa.Spec.ScaleTargetRef.Kind == kind && hpa.Spec.ScaleTargetRef.Name == name {
+				cd.Spec.AutoscalerRef = &flaggerv1.CrossNamespaceObjectReference{
+					APIVersion: "autoscaling/v2beta2",
+					Kind:       "HorizontalPodAutoscaler",
+					Name:       hpa.Name,
+				}
+				break
+			}
+		}
+	}
+
+	return cd, warnings, nil
+}
+
+// Marshal renders the canary as YAML without the empty status
+func Marshal(cd *flaggerv1.Canary) ([]byte, error) {
+	data, err := yaml.Marshal(cd)
+	if err != nil {
+		return nil, fmt.Errorf("canary marshal failed: %w", err)
+	}
+
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("canary unmarshal failed: %w", err)
+	}
+	delete(obj, "status")
+	if meta, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(meta, "creationTimestamp")
+	}
+
+	// drop the fields without omitempty that are unset
+	spec, _ := obj["spec"].(map[string]interface{})
+	if service, ok := spec["service"].(map[string]interface{}); ok && service["portDiscovery"] == false {
+		delete(service, "portDiscovery")
+	}
+	if analysis, ok := spec["analysis"].(map[string]interface{}); ok {
+		metrics, _ := analysis["metrics"].([]interface{})
+		for _, metric := range metrics {
+			if m, ok := metric.(map[string]interface{}); ok && m["threshold"] == float64(0) {
+				delete(m, "threshold")
+			}
+		}
+	}
+	return yaml.Marshal(obj)
+}
+
+func parseTarget(target string) (string, string, error) {
+	parts := strings.Split(target, "/")
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("target %q must be in the kind/name format", target)
+	}
+
+	switch strings.ToLower(parts[0]) {
+	case "deploy", "deployment", "deployments":
+		return "Deployment", parts[1], nil
+	case "ds", "daemonset", "daemonsets":
+		return "DaemonSet", parts[1], nil
+	case "sts", "statefulset", "statefulsets":
+		return "StatefulSet", parts[1], nil
+	default:
+		return "", "", fmt.Errorf("target kind %s not supported, must be a deployment, a daemonset or a statefulset", parts[0])
+	}
+}
+
+func hasSelectorLabel(labels map[string]string, selectorLabels []string) bool {
+	for _, label := range selectorLabels {
+		if _, ok := labels[label]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findService returns the service settings from the existing service of the workload,
+// the first container port is used when the workload has no service
+func findService(ctx context.Context, kubeClient kubernetes.Interface, namespace string, name string, podSpec corev1.PodSpec) (*flaggerv1.CanaryService, error) {
+	svc, err := kubeClient.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("service %s.%s get query error: %w", name, namespace, err)
+	}
+	if err == nil && len(svc.Spec.Ports) > 0 {
+		port := svc.Spec.Ports[0]
+		service := &flaggerv1.CanaryService{
+			Port:       port.Port,
+			TargetPort: port.TargetPort,
+		}
+		if port.Name != "" && port.Name != "http" {
+			service.PortName = port.Name
+		}
+		return service, nil
+	}
+
+	for _, container := range podSpec.Containers {
+		for _, port := range container.Ports {
+			service := &flaggerv1.CanaryService{
+				Port:       port.ContainerPort,
+				TargetPort: intstr.FromInt(int(port.ContainerPort)),
+			}
+			if port.Name != "" {
+				service.TargetPort = intstr.FromString(port.Name)
+			}
+			return service, nil
+		}
+	}
+	return nil, nil
+}
+
+// defaultAnalysis returns a canary analysis with the builtin metrics,
+// the kubernetes provider can only run Blue/Green deployments
+func defaultAnalysis(provider string) *flaggerv1.CanaryAnalysis {
+	successRate, duration := float64(99), float64(500)
+	analysis := &flaggerv1.CanaryAnalysis{
+		Interval:  "1m",
+		Threshold: 5,
+		Metrics: []flaggerv1.CanaryMetric{
+			{
+				Name:           "request-success-rate",
+				Interval:       "1m",
+				ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: &successRate},
+			},
+			{
+				Name:           "request-duration",
+				Interval:       "1m",
+				ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: &duration},
+			},
+		},
+	}
+
+	if provider == flaggerv1.KubernetesProvider {
+		analysis.Iterations = 10
+	} else {
+		analysis.MaxWeight = 50
+		analysis.StepWeight = 10
+	}
+	return analysis
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffold
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	hpav2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newTestDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "test"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "podinfo"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "podinfo",
+							Image: "quay.io/stefanprodan/podinfo:1.2.0",
+							Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 9898}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateCanary(t *testing.T) {
+	hpa := &hpav2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo-hpa", Namespace: "test"},
+		Spec: hpav2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: hpav2.CrossVersionObjectReference{Kind: "Deployment", Name: "podinfo"},
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(newTestDeployment(), hpa)
+
+	cd, warnings, err := GenerateCanary(context.TODO(), kubeClient, Options{
+		Target:         "deploy/podinfo",
+		Namespace:      "test",
+		Provider:       flaggerv1.IstioProvider,
+		SelectorLabels: []string{"app"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "Deployment", cd.Spec.TargetRef.Kind)
+	assert.Equal(t, int32(9898), cd.Spec.Service.Port)
+	assert.Equal(t, intstr.FromString("http"), cd.Spec.Service.TargetPort)
+	require.NotNil(t, cd.Spec.AutoscalerRef)
+	assert.Equal(t, "podinfo-hpa", cd.Spec.AutoscalerRef.Name)
+	assert.Equal(t, 10, cd.Spec.Analysis.StepWeight)
+
+	data, err := Marshal(cd)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "status")
+	assert.NotContains(t, string(data), "threshold: 0")
+}
+
+func TestGenerateCanary_Service(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "test"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "grpc", Port: 9999, TargetPort: intstr.FromInt(9898)}},
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(newTestDeployment(), svc)
+
+	cd, warnings, err := GenerateCanary(context.TODO(), kubeClient, Options{
+		Target:         "deployment/podinfo",
+		Namespace:      "test",
+		Provider:       flaggerv1.KubernetesProvider,
+		SelectorLabels: []string{"app.kubernetes.io/name"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, int32(9999), cd.Spec.Service.Port)
+	assert.Equal(t, "grpc", cd.Spec.Service.PortName)
+	assert.Nil(t, cd.Spec.AutoscalerRef)
+	assert.Equal(t, 10, cd.Spec.Analysis.Iterations)
+	assert.Zero(t, cd.Spec.Analysis.StepWeight)
+}
+
+func TestGenerateCanary_InvalidTarget(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	_, _, err := GenerateCanary(context.TODO(), kubeClient, Options{Target: "statefulset/podinfo", Namespace: "test"})
+	require.Error(t, err)
+
+	_, _, err = GenerateCanary(context.TODO(), kubeClient, Options{Target: "deploy/podinfo", Namespace: "test"})
+	require.Error(t, err)
+}
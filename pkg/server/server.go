@@ -18,21 +18,27 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
-// ListenAndServe starts a web server and waits for SIGTERM
-func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogger, stopCh <-chan struct{}) {
-	mux := http.DefaultServeMux
+// ListenAndServe starts a web server and waits for SIGTERM,
+// the handlers are served next to the metrics and health endpoints
+func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogger, handlers map[string]http.Handler, stopCh <-chan struct{}) {
+	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
+	for pattern, handler := range handlers {
+		mux.Handle(pattern, handler)
+	}
 
 	srv := &http.Server{
 		Addr:         ":" + port,
@@ -62,3 +68,18 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 		logger.Info("HTTP server stopped")
 	}
 }
+
+// BearerAuth rejects the requests that don't present the token
+// in the Authorization header using the Bearer scheme
+func BearerAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="flagger"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// ValidatePath is the path of the admission endpoint of the canaries
+const ValidatePath = "/validate-canary"
+
+// ServeHTTP handles the admission reviews of the Canary objects,
+// the deletions and the updates that keep the target are always allowed
+func (v *Validator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "reading the request body failed", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	review := admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "decoding the admission review failed", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = v.review(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		v.logger.Errorf("writing the admission review failed: %v", err)
+	}
+}
+
+func (v *Validator) review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation == admissionv1.Delete {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	cd := &flaggerv1.Canary{}
+	if err := json.Unmarshal(req.Object.Raw, cd); err != nil {
+		return deny(fmt.Sprintf("decoding the canary failed: %v", err))
+	}
+	if cd.Namespace == "" {
+		cd.Namespace = req.Namespace
+	}
+
+	// the canaries being deleted must stay writable for Flagger to remove its finalizer
+	if cd.DeletionTimestamp != nil {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	// the updates made by Flagger to the status, the finalizers and the annotations
+	// keep the target, only a change of the target is validated again
+	if req.Operation == admissionv1.Update {
+		old := &flaggerv1.Canary{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return deny(fmt.Sprintf("decoding the previous canary failed: %v", err))
+		}
+		if old.Namespace == "" {
+			old.Namespace = req.Namespace
+		}
+		if !targetChanged(old, cd) {
+			return &admissionv1.AdmissionResponse{Allowed: true}
+		}
+	}
+
+	if err := v.Validate(cd); err != nil {
+		v.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("Canary rejected: %v", err)
+		return deny(err.Error())
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+// targetChanged returns true if the namespace of the canary target or the kind or the name
+// of the target or of one of the targetRefs has changed
+func targetChanged(old *flaggerv1.Canary, cd *flaggerv1.Canary) bool {
+	if old.GetTargetNamespace() != cd.GetTargetNamespace() {
+		return true
+	}
+	oldTargets, targets := canaryTargets(old), canaryTargets(cd)
+	if len(oldTargets) != len(targets) {
+		return true
+	}
+	for i := range targets {
+		if flaggerv1.TargetKind(oldTargets[i]) != flaggerv1.TargetKind(targets[i]) || oldTargets[i].Name != targets[i].Name {
+			return true
+		}
+	}
+	return false
+}
+
+func deny(message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonForbidden,
+			Code:    http.StatusForbidden,
+			Message: message,
+		},
+	}
+}
+
+// ListenAndServe starts the TLS server of the admission webhook and waits for SIGTERM
+func ListenAndServe(port string, certFile string, keyFile string, validator *Validator, logger *zap.SugaredLogger, stopCh <-chan struct{}) {
+	mux := http.NewServeMux()
+	mux.Handle(ValidatePath, validator)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+	srv := &http.Server{
+		Addr:    ":" + port,
+		Handler: mux,
+	}
+
+	// run server in background
+	go func() {
+		if err := srv.ListenAndServeTLS(certFile, keyFile); err != http.ErrServerClosed {
+			logger.Fatalf("HTTPS server crashed %v", err)
+		}
+	}()
+
+	// wait for SIGTERM or SIGINT
+	<-stopCh
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Errorf("HTTPS server graceful shutdown failed %v", err)
+	} else {
+		logger.Info("HTTPS server stopped")
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// Validator rejects the canaries whose target selector overlaps the pods of a primary,
+// of the target of another canary or of the pods and replica sets not managed by a controller
+type Validator struct {
+	kubeClient    kubernetes.Interface
+	flaggerClient clientset.Interface
+	primarySuffix string
+	logger        *zap.SugaredLogger
+}
+
+// NewValidator returns a Validator that looks up the workloads with the given clients,
+// the primaries of the canaries that don't set spec.primarySuffix are named with the primary suffix
+func NewValidator(kubeClient kubernetes.Interface, flaggerClient clientset.Interface, primarySuffix string, logger *zap.SugaredLogger) *Validator {
+	return &Validator{
+		kubeClient:    kubeClient,
+		flaggerClient: flaggerClient,
+		primarySuffix: primarySuffix,
+		logger:        logger,
+	}
+}
+
+// workload holds the selector and the pod template labels of a canary target or primary
+type workload struct {
+	name     string
+	selector labels.Selector
+	labels   labels.Set
+}
+
+// overlaps returns true if one of the workloads selects the pods of the other
+func (w *workload) overlaps(other *workload) bool {
+	return w.selector.Matches(other.labels) || other.selector.Matches(w.labels)
+}
+
+// Validate returns an error if the selector of the canary target or of one of the targetRefs overlaps
+// another workload, the targets that don't exist yet and the kinds without a pod selector are not validated
+func (v *Validator) Validate(cd *flaggerv1.Canary) error {
+	canaries, err := v.flaggerClient.FlaggerV1beta1().Canaries(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("canaries list query error: %w", err)
+	}
+	for _, ref := range canaryTargets(cd) {
+		if err := v.validateTarget(cd, ref, canaries.Items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTarget returns an error if the selector of the target overlaps its primary,
+// the targets and primaries of the other canaries or the pods that aren't managed by a controller
+func (v *Validator) validateTarget(cd *flaggerv1.Canary, ref flaggerv1.CrossNamespaceObjectReference, canaries []flaggerv1.Canary) error {
+	namespace := cd.GetTargetNamespace()
+	kind := flaggerv1.TargetKind(ref)
+	target, err := v.workload(kind, ref.Name, namespace)
+	if err != nil || target == nil {
+		return err
+	}
+
+	primary, err := v.workload(kind, cd.GetPrimaryName(ref.Name, v.primarySuffix), namespace)
+	if err != nil {
+		return err
+	}
+	if primary != nil && target.overlaps(primary) {
+		return fmt.Errorf("the selector of %s.%s overlaps the pods of its primary %s",
+			target.name, namespace, primary.name)
+	}
+
+	// the workloads released together must not select the pods of each other
+	for _, otherRef := range canaryTargets(cd) {
+		otherKind := flaggerv1.TargetKind(otherRef)
+		if otherKind == kind && otherRef.Name == ref.Name {
+			continue
+		}
+		for _, name := range []string{otherRef.Name, cd.GetPrimaryName(otherRef.Name, v.primarySuffix)} {
+			w, err := v.workload(otherKind, name, namespace)
+			if err != nil {
+				return err
+			}
+			if w != nil && target.overlaps(w) {
+				return fmt.Errorf("the selector of %s.%s overlaps the pods of %s of the same canary",
+					target.name, namespace, w.name)
+			}
+		}
+	}
+
+	for _, other := range canaries {
+		if (other.Name == cd.Name && other.Namespace == cd.Namespace) || other.GetTargetNamespace() != namespace {
+			continue
+		}
+		for _, otherRef := range canaryTargets(&other) {
+			otherKind := flaggerv1.TargetKind(otherRef)
+			if otherKind == kind && otherRef.Name == ref.Name {
+				return fmt.Errorf("%s.%s is already the target of canary %s.%s", target.name, namespace, other.Name, other.Namespace)
+			}
+			for _, name := range []string{otherRef.Name, other.GetPrimaryName(otherRef.Name, v.primarySuffix)} {
+				w, err := v.workload(otherKind, name, namespace)
+				if err != nil {
+					return err
+				}
+				if w != nil && target.overlaps(w) {
+					return fmt.Errorf("the selector of %s.%s overlaps the pods of %s managed by canary %s.%s",
+						target.name, namespace, w.name, other.Name, other.Namespace)
+				}
+			}
+		}
+	}
+
+	return v.validateUnmanaged(target, namespace)
+}
+
+// canaryTargets returns the target of the canary followed by the workloads of the targetRefs
+func canaryTargets(cd *flaggerv1.Canary) []flaggerv1.CrossNamespaceObjectReference {
+	return append([]flaggerv1.CrossNamespaceObjectReference{cd.Spec.TargetRef}, cd.Spec.TargetRefs...)
+}
+
+// validateUnmanaged returns an error if the target selects bare pods or replica sets
+// that are not managed by a controller, Flagger would count them as canary pods
+func (v *Validator) validateUnmanaged(target *workload, namespace string) error {
+	opts := metav1.ListOptions{LabelSelector: target.selector.String()}
+	pods, err := v.kubeClient.CoreV1().Pods(namespace).List(context.TODO(), opts)
+	if err != nil {
+		return fmt.Errorf("pods list query error: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if metav1.GetControllerOf(&pod) == nil {
+			return fmt.Errorf("the selector of %s.%s matches the pod %s that isn't managed by a controller",
+				target.name, namespace, pod.Name)
+		}
+	}
+
+	replicaSets, err := v.kubeClient.AppsV1().ReplicaSets(namespace).List(context.TODO(), opts)
+	if err != nil {
+		return fmt.Errorf("replicasets list query error: %w", err)
+	}
+	for _, rs := range replicaSets.Items {
+		if metav1.GetControllerOf(&rs) == nil {
+			return fmt.Errorf("the selector of %s.%s matches the replicaset %s that isn't managed by a deployment",
+				target.name, namespace, rs.Name)
+		}
+	}
+	return nil
+}
+
+// workload returns the selector and the pod template labels of a deployment, daemonset or statefulset,
+// nil is returned for the other kinds and for the workloads that don't exist
+func (v *Validator) workload(kind string, name string, namespace string) (*workload, error) {
+	selector, templateLabels, err := v.podSelector(kind, name, namespace)
+	if errors.IsNotFound(err) || (err == nil && selector == nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s %s.%s get query error: %w", strings.ToLower(kind), name, namespace, err)
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s.%s invalid selector: %w", strings.ToLower(kind), name, namespace, err)
+	}
+	return &workload{
+		name:     fmt.Sprintf("%s/%s", strings.ToLower(kind), name),
+		selector: s,
+		labels:   templateLabels,
+	}, nil
+}
+
+func (v *Validator) podSelector(kind string, name string, namespace string) (*metav1.LabelSelector, map[string]string, error) {
+	switch kind {
+	case "Deployment":
+		dep, err := v.kubeClient.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return dep.Spec.Selector, dep.Spec.Template.Labels, nil
+	case "DaemonSet":
+		ds, err := v.kubeClient.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return ds.Spec.Selector, ds.Spec.Template.Labels, nil
+	case "StatefulSet":
+		sts, err := v.kubeClient.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return sts.Spec.Selector, sts.Spec.Template.Labels, nil
+	}
+	return nil, nil, nil
+}
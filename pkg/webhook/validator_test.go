@@ -0,0 +1,199 @@
+/*
+Copyright 2021 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+)
+
+func newTestDeployment(name string, selector map[string]string, templateLabels map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: templateLabels}},
+		},
+	}
+}
+
+func newTestCanary(name string, target string) *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: target},
+		},
+	}
+}
+
+func newTestValidator(kubeObjects []runtime.Object, canaries ...runtime.Object) *Validator {
+	return NewValidator(fake.NewSimpleClientset(kubeObjects...), fakeFlagger.NewSimpleClientset(canaries...), flaggerv1.DefaultPrimarySuffix, zap.NewNop().Sugar())
+}
+
+func TestValidator_Validate(t *testing.T) {
+	podinfo := newTestDeployment("podinfo", map[string]string{"app": "podinfo"}, map[string]string{"app": "podinfo"})
+	primary := newTestDeployment("podinfo-primary", map[string]string{"app": "podinfo-primary"}, map[string]string{"app": "podinfo-primary", "team": "a"})
+
+	t.Run("no overlap", func(t *testing.T) {
+		v := newTestValidator([]runtime.Object{podinfo, primary})
+		assert.NoError(t, v.Validate(newTestCanary("podinfo", "podinfo")))
+	})
+
+	t.Run("target not found", func(t *testing.T) {
+		v := newTestValidator(nil)
+		assert.NoError(t, v.Validate(newTestCanary("podinfo", "podinfo")))
+	})
+
+	t.Run("primary overlap", func(t *testing.T) {
+		team := newTestDeployment("podinfo", map[string]string{"team": "a"}, map[string]string{"app": "podinfo", "team": "a"})
+		v := newTestValidator([]runtime.Object{team, primary})
+		err := v.Validate(newTestCanary("podinfo", "podinfo"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "its primary deployment/podinfo-primary")
+	})
+
+	t.Run("another canary overlap", func(t *testing.T) {
+		backend := newTestDeployment("backend", map[string]string{"app": "podinfo"}, map[string]string{"app": "podinfo", "tier": "backend"})
+		v := newTestValidator([]runtime.Object{podinfo, backend}, newTestCanary("backend", "backend"))
+		err := v.Validate(newTestCanary("podinfo", "podinfo"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "deployment/backend managed by canary backend.default")
+	})
+
+	t.Run("same target", func(t *testing.T) {
+		v := newTestValidator([]runtime.Object{podinfo}, newTestCanary("other", "podinfo"))
+		err := v.Validate(newTestCanary("podinfo", "podinfo"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already the target of canary other.default")
+	})
+
+	t.Run("bare pod", func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "default", Labels: map[string]string{"app": "podinfo"}}}
+		v := newTestValidator([]runtime.Object{podinfo, pod})
+		err := v.Validate(newTestCanary("podinfo", "podinfo"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pod debug that isn't managed by a controller")
+	})
+
+	t.Run("targetRefs overlap", func(t *testing.T) {
+		cache := newTestDeployment("cache", map[string]string{"team": "a"}, map[string]string{"app": "cache", "team": "a"})
+		v := newTestValidator([]runtime.Object{podinfo, cache, primary})
+		cd := newTestCanary("podinfo", "podinfo")
+		cd.Spec.TargetRefs = []flaggerv1.CrossNamespaceObjectReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "cache"}}
+		err := v.Validate(cd)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the selector of deployment/cache.default overlaps the pods of deployment/podinfo-primary of the same canary")
+	})
+
+	t.Run("targetRefs of another canary", func(t *testing.T) {
+		cache := newTestDeployment("cache", map[string]string{"app": "cache"}, map[string]string{"app": "cache"})
+		other := newTestCanary("other", "other")
+		other.Spec.TargetRefs = []flaggerv1.CrossNamespaceObjectReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "cache"}}
+		v := newTestValidator([]runtime.Object{podinfo, cache}, other)
+		cd := newTestCanary("podinfo", "podinfo")
+		cd.Spec.TargetRefs = []flaggerv1.CrossNamespaceObjectReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "cache"}}
+		err := v.Validate(cd)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "deployment/cache.default is already the target of canary other.default")
+	})
+}
+
+func TestValidator_ServeHTTP(t *testing.T) {
+	team := newTestDeployment("podinfo", map[string]string{"team": "a"}, map[string]string{"app": "podinfo", "team": "a"})
+	primary := newTestDeployment("podinfo-primary", map[string]string{"app": "podinfo-primary"}, map[string]string{"app": "podinfo-primary", "team": "a"})
+	v := newTestValidator([]runtime.Object{team, primary})
+
+	post := func(operation admissionv1.Operation, cd *flaggerv1.Canary, old *flaggerv1.Canary) *admissionv1.AdmissionResponse {
+		raw, err := json.Marshal(cd)
+		require.NoError(t, err)
+		req := &admissionv1.AdmissionRequest{
+			UID:       "1",
+			Operation: operation,
+			Namespace: "default",
+			Object:    runtime.RawExtension{Raw: raw},
+		}
+		if old != nil {
+			oldRaw, err := json.Marshal(old)
+			require.NoError(t, err)
+			req.OldObject = runtime.RawExtension{Raw: oldRaw}
+		}
+		body, err := json.Marshal(admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request:  req,
+		})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		v.ServeHTTP(w, httptest.NewRequest(http.MethodPost, ValidatePath, bytes.NewReader(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		review := admissionv1.AdmissionReview{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &review))
+		require.NotNil(t, review.Response)
+		assert.Equal(t, "1", string(review.Response.UID))
+		return review.Response
+	}
+
+	cd := newTestCanary("podinfo", "podinfo")
+	resp := post(admissionv1.Create, cd, nil)
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "overlaps the pods of its primary")
+
+	assert.True(t, post(admissionv1.Delete, cd, nil).Allowed)
+
+	// the updates that keep the target are allowed, e.g. the finalizer removal or the annotations
+	updated := cd.DeepCopy()
+	updated.Annotations = map[string]string{"flagger.app/rerun": "1"}
+	assert.True(t, post(admissionv1.Update, updated, cd).Allowed)
+
+	// a change of the target is validated again
+	retargeted := cd.DeepCopy()
+	retargeted.Spec.TargetRef.Name = "other"
+	resp = post(admissionv1.Update, cd, retargeted)
+	assert.False(t, resp.Allowed)
+
+	// a change of the targetRefs is validated again
+	extended := cd.DeepCopy()
+	extended.Spec.TargetRefs = []flaggerv1.CrossNamespaceObjectReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "cache"}}
+	assert.True(t, targetChanged(cd, extended))
+	assert.False(t, targetChanged(extended, extended.DeepCopy()))
+	resp = post(admissionv1.Update, extended, cd)
+	assert.False(t, resp.Allowed)
+
+	// the canaries being deleted are always allowed
+	deleting := retargeted.DeepCopy()
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	deleting.Spec.TargetRef.Name = "podinfo"
+	assert.True(t, post(admissionv1.Update, deleting, retargeted).Allowed)
+}